@@ -23,6 +23,10 @@ import (
 type Agent struct {
 	comm client.Communicator
 	opts Options
+
+	// taskGroup is the task group of the last task the agent ran, if it
+	// was in one.
+	taskGroup *taskGroupContext
 }
 
 // Options contains startup options for the Agent.
@@ -44,8 +48,10 @@ type taskContext struct {
 	task           client.TaskData
 	taskConfig     *model.TaskConfig
 	taskDirectory  string
+	taskGroup      *taskGroupContext
 	timeout        time.Duration
 	timedOut       bool
+	commandFailed  bool
 	sync.RWMutex
 }
 
@@ -104,6 +110,9 @@ func (a *Agent) loop(ctx context.Context) error {
 			if err != nil {
 				return errors.Wrap(err, "error getting next task")
 			}
+			if a.taskGroup != nil && !a.taskGroup.includes(nextTask) {
+				a.endTaskGroup(lgrCtx)
+			}
 			if nextTask.TaskId != "" {
 				if nextTask.TaskSecret == "" {
 					return errors.New("task response missing secret")
@@ -113,6 +122,7 @@ func (a *Agent) loop(ctx context.Context) error {
 						ID:     nextTask.TaskId,
 						Secret: nextTask.TaskSecret,
 					},
+					taskGroup: a.taskGroup,
 				}
 				if err := a.resetLogging(lgrCtx, &tc); err != nil {
 					return errors.WithStack(err)
@@ -120,6 +130,7 @@ func (a *Agent) loop(ctx context.Context) error {
 				if err := a.runTask(tskCtx, &tc); err != nil {
 					return errors.WithStack(err)
 				}
+				a.updateTaskGroup(lgrCtx, &tc)
 				timer.Reset(0)
 				continue
 			}
@@ -130,6 +141,17 @@ func (a *Agent) loop(ctx context.Context) error {
 	}
 }
 
+// RunTask runs a single task to completion outside of the agent loop,
+// fetching all task data from and reporting all results to the
+// agent's communicator. This supports running a task with a local
+// communicator, without a host or an API server.
+func (a *Agent) RunTask(ctx context.Context, td client.TaskData) error {
+	tc := &taskContext{task: td}
+	tc.logger = a.comm.GetLoggerProducer(ctx, tc.task)
+
+	return errors.WithStack(a.runTask(ctx, tc))
+}
+
 func (a *Agent) resetLogging(ctx context.Context, tc *taskContext) error {
 	tc.logger = a.comm.GetLoggerProducer(ctx, tc.task)
 
@@ -164,8 +186,14 @@ func (a *Agent) runTask(ctx context.Context, tc *taskContext) (err error) {
 	}
 
 	// Defers are LIFO. We cancel all agent task threads, then any procs started by the agent, then remove the task directory.
-	defer a.removeTaskDirectory(tc)
-	defer a.killProcs(tc)
+	// Tasks in a task group leave their processes and directory to the
+	// group's teardown.
+	defer func() {
+		if !tc.inTaskGroup() {
+			a.killProcs(tc)
+			a.removeTaskDirectory(tc)
+		}
+	}()
 	defer cancel()
 
 	// If the heartbeat aborts the task immediately, we should report that
@@ -276,23 +304,39 @@ func (a *Agent) endTaskResponse(tc *taskContext, status string) *apimodels.TaskE
 }
 
 func (a *Agent) runPostTaskCommands(ctx context.Context, tc *taskContext) {
-	if tc.taskConfig != nil && tc.taskConfig.Project.Post != nil {
-		a.killProcs(tc)
-		tc.logger.Task().Info("Running post-task commands.")
-		start := time.Now()
-		var cancel context.CancelFunc
-		ctx, cancel = a.withCallbackTimeout(ctx, tc)
-		defer cancel()
-		err := a.runCommands(ctx, tc, tc.taskConfig.Project.Post.List(), false)
-		if err != nil {
-			tc.logger.Execution().Errorf("Error running post-task command: %v", err)
-		} else {
-			tc.logger.Task().Infof("Finished running post-task commands in %v.", time.Since(start).String())
+	if tc.taskConfig == nil {
+		return
+	}
+	if tg := tc.taskConfig.Project.FindTaskGroup(tc.taskGroupName()); tg != nil {
+		// processes started by the group's setup must outlive the task
+		if tg.TeardownTask != nil {
+			a.runCallbackCommands(ctx, tc, "teardown-task", tg.TeardownTask)
 		}
+		return
+	}
+	if tc.taskConfig.Project.Post != nil {
+		a.killProcs(tc)
+		a.runCallbackCommands(ctx, tc, "post-task", tc.taskConfig.Project.Post)
 		a.killProcs(tc)
 	}
 }
 
+// runCallbackCommands runs the commands of one of the project's callbacks,
+// such as its post-task commands, logging but otherwise ignoring failures.
+func (a *Agent) runCallbackCommands(ctx context.Context, tc *taskContext, name string, commands *model.YAMLCommandSet) {
+	tc.logger.Task().Infof("Running %s commands.", name)
+	start := time.Now()
+	var cancel context.CancelFunc
+	ctx, cancel = a.withCallbackTimeout(ctx, tc)
+	defer cancel()
+	err := a.runCommands(ctx, tc, commands.List(), false)
+	if err != nil {
+		tc.logger.Execution().Errorf("Error running %s command: %v", name, err)
+	} else {
+		tc.logger.Task().Infof("Finished running %s commands in %v.", name, time.Since(start).String())
+	}
+}
+
 func (a *Agent) killProcs(tc *taskContext) {
 	if a.opts.Cleanup {
 		grip.Infof("cleaning up processes for task: %s", tc.task.ID)
@@ -12,6 +12,7 @@ import (
 	"github.com/evergreen-ci/evergreen/model/task"
 	"github.com/evergreen-ci/evergreen/rest/client"
 	"github.com/evergreen-ci/evergreen/testutil"
+	"github.com/evergreen-ci/evergreen/util"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
@@ -368,3 +369,264 @@ func TestAgentConstructorSetsHostData(t *testing.T) {
 	assert.Equal("host_id", agent.comm.GetHostID())
 	assert.Equal("host_secret", agent.comm.GetHostSecret())
 }
+
+func (s *AgentSuite) TestRunCommandsWithConditions() {
+	s.tc.taskConfig = &model.TaskConfig{
+		BuildVariant: &model.BuildVariant{
+			Name: "buildvariant_id",
+		},
+		Task: &task.Task{
+			Id: "task_id",
+		},
+		Expansions: util.NewExpansions(map[string]string{
+			"is_patch": "true",
+			"os":       "windows-64",
+		}),
+		Project: &model.Project{
+			Functions: map[string]*model.YAMLCommandSet{
+				"windows setup": {
+					SingleCommand: &model.PluginCommandConf{
+						Command: "shell.exec",
+						Params: map[string]interface{}{
+							"working_dir": testutil.GetDirectoryOfFile(),
+							"script":      "echo windows",
+						},
+					},
+				},
+			},
+		},
+	}
+	shell := func(script, ifCondition, unless string) model.PluginCommandConf {
+		return model.PluginCommandConf{
+			Command: "shell.exec",
+			If:      ifCondition,
+			Unless:  unless,
+			Params: map[string]interface{}{
+				"working_dir": testutil.GetDirectoryOfFile(),
+				"script":      script,
+			},
+		}
+	}
+	cmds := []model.PluginCommandConf{
+		shell("echo patch", "${is_patch} == true", ""),
+		shell("echo not patch", "", "${is_patch}"),
+		shell("echo after failure", "failed", ""),
+		shell("exit 1", "", ""),
+		shell("echo after failure", "failed", ""),
+		{Function: "windows setup", If: "${os} matches windows.*"},
+		{Function: "windows setup", Unless: "${os} matches 'windows.*'"},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the block continues past the failed command, and its result is
+	// that of the last command, which was skipped
+	s.NoError(s.a.runCommands(ctx, s.tc, cmds, false))
+	_ = s.tc.logger.Close()
+
+	messages := []string{}
+	for _, msg := range s.mockCommunicator.GetMockMessages()["task_id"] {
+		messages = append(messages, msg.Message)
+	}
+	s.Contains(messages, "Running command 'shell.exec' (step 1 of 7)")
+	s.Contains(messages, "Skipping command 'shell.exec' because its conditions are not met (step 2 of 7)")
+	s.Contains(messages, "Skipping command 'shell.exec' because its conditions are not met (step 3 of 7)")
+	s.Contains(messages, "Running command 'shell.exec' (step 4 of 7)")
+	s.Contains(messages, "Running command 'shell.exec' (step 5 of 7)")
+	s.Contains(messages, "Running command 'shell.exec' in \"windows setup\" (step 6 of 7)")
+	s.Contains(messages, "Skipping function 'windows setup' because its conditions are not met (step 7 of 7)")
+}
+
+func (s *AgentSuite) TestConditionsSeeEarlierBlockFailures() {
+	s.tc.taskConfig = &model.TaskConfig{
+		BuildVariant: &model.BuildVariant{
+			Name: "buildvariant_id",
+		},
+		Task: &task.Task{
+			Id: "task_id",
+		},
+		Expansions: util.NewExpansions(map[string]string{}),
+		Project:    &model.Project{},
+	}
+	shell := func(script, ifCondition string) model.PluginCommandConf {
+		return model.PluginCommandConf{
+			Command: "shell.exec",
+			If:      ifCondition,
+			Params: map[string]interface{}{
+				"working_dir": testutil.GetDirectoryOfFile(),
+				"script":      script,
+			},
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// a failed task command is seen by the conditions of the post commands
+	s.Error(s.a.runCommands(ctx, s.tc, []model.PluginCommandConf{shell("exit 1", "")}, true))
+	s.NoError(s.a.runCommands(ctx, s.tc, []model.PluginCommandConf{
+		shell("echo post after failure", "failed"),
+		shell("echo post after success", "succeeded"),
+	}, false))
+	_ = s.tc.logger.Close()
+
+	messages := []string{}
+	for _, msg := range s.mockCommunicator.GetMockMessages()["task_id"] {
+		messages = append(messages, msg.Message)
+	}
+	s.Contains(messages, "Running command 'shell.exec' (step 1 of 2)")
+	s.Contains(messages, "Skipping command 'shell.exec' because its conditions are not met (step 2 of 2)")
+}
+
+func (s *AgentSuite) TestRunTaskCommandsWithInvalidCondition() {
+	s.tc.taskConfig = &model.TaskConfig{
+		BuildVariant: &model.BuildVariant{
+			Name: "buildvariant_id",
+		},
+		Task: &task.Task{
+			Id: "task_id",
+		},
+		Expansions: util.NewExpansions(map[string]string{}),
+		Project:    &model.Project{},
+	}
+	cmds := []model.PluginCommandConf{
+		{
+			Command: "shell.exec",
+			If:      "${is_patch} ==",
+			Params: map[string]interface{}{
+				"script": "echo hi",
+			},
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.Error(s.a.runCommands(ctx, s.tc, cmds, true))
+}
+
+func (s *AgentSuite) setupParallelTaskConfig() func(string, int) model.PluginCommandConf {
+	s.tc.taskConfig = &model.TaskConfig{
+		BuildVariant: &model.BuildVariant{
+			Name: "buildvariant_id",
+		},
+		Task: &task.Task{
+			Id: "task_id",
+		},
+		Expansions: util.NewExpansions(map[string]string{}),
+		Project:    &model.Project{},
+	}
+	return func(script string, timeout int) model.PluginCommandConf {
+		return model.PluginCommandConf{
+			Command:     "shell.exec",
+			TimeoutSecs: timeout,
+			Params: map[string]interface{}{
+				"working_dir": testutil.GetDirectoryOfFile(),
+				"script":      script,
+			},
+		}
+	}
+}
+
+func (s *AgentSuite) TestRunParallelCommandsWaitsForAll() {
+	shell := s.setupParallelTaskConfig()
+	cmds := []model.PluginCommandConf{
+		{
+			Parallel: &model.ParallelCommandBlock{
+				Commands: []model.PluginCommandConf{
+					shell("exit 1", 0),
+					shell("sleep 1 && echo second", 0),
+				},
+			},
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.Error(s.a.runCommands(ctx, s.tc, cmds, true))
+	_ = s.tc.logger.Close()
+
+	messages := []string{}
+	for _, msg := range s.mockCommunicator.GetMockMessages()["task_id"] {
+		messages = append(messages, msg.Message)
+	}
+	s.Contains(messages, "Running parallel block (step 1 of 1)")
+	s.Contains(messages, "[parallel 1: 'shell.exec'] Running command 'shell.exec' (1 of 1)")
+	s.Contains(messages, "[parallel 2: 'shell.exec'] Running command 'shell.exec' (1 of 1)")
+	s.Contains(messages, "[parallel 2: 'shell.exec'] second")
+}
+
+func (s *AgentSuite) TestRunParallelCommandsFailFast() {
+	shell := s.setupParallelTaskConfig()
+	cmds := []model.PluginCommandConf{
+		{
+			Parallel: &model.ParallelCommandBlock{
+				FailFast: true,
+				Commands: []model.PluginCommandConf{
+					shell("exit 1", 0),
+					shell("exec sleep 30", 0),
+				},
+			},
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	start := time.Now()
+	s.Error(s.a.runCommands(ctx, s.tc, cmds, true))
+	s.True(time.Since(start) < 20*time.Second)
+}
+
+func (s *AgentSuite) TestRunParallelCommandsEnforcesTimeouts() {
+	shell := s.setupParallelTaskConfig()
+	cmds := []model.PluginCommandConf{
+		{
+			Parallel: &model.ParallelCommandBlock{
+				Commands: []model.PluginCommandConf{
+					shell("exec sleep 30", 1),
+					shell("echo done", 0),
+				},
+			},
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// without an idle timeout watcher, the command's own timeout stops it
+	start := time.Now()
+	err := s.a.runCommands(ctx, s.tc, cmds, true)
+	s.Require().Error(err)
+	s.Contains(err.Error(), "timed out")
+	s.True(time.Since(start) < 20*time.Second)
+	s.True(s.tc.hadTimedOut())
+}
+
+func (s *AgentSuite) TestRunParallelCommandsCopiesExpansions() {
+	s.setupParallelTaskConfig()
+	s.tc.taskConfig.Expansions.Put("shared", "before")
+	entry := func(key, val string) model.PluginCommandConf {
+		return model.PluginCommandConf{
+			Command: "expansions.update",
+			Params: map[string]interface{}{
+				"updates": []map[string]interface{}{{"key": key, "value": val}},
+			},
+		}
+	}
+	cmds := []model.PluginCommandConf{
+		{
+			Parallel: &model.ParallelCommandBlock{
+				Commands: []model.PluginCommandConf{
+					entry("first", "1"),
+					entry("shared", "after"),
+					entry("second", "${shared}"),
+				},
+			},
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.NoError(s.a.runCommands(ctx, s.tc, cmds, true))
+	s.Equal("1", s.tc.taskConfig.Expansions.Get("first"))
+	s.Equal("after", s.tc.taskConfig.Expansions.Get("shared"))
+	// each entry expands against its own copy of the expansions
+	s.Equal("before", s.tc.taskConfig.Expansions.Get("second"))
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/evergreen-ci/evergreen/command"
 	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/util"
 	"github.com/mongodb/grip"
 	"github.com/mongodb/grip/recovery"
 	"github.com/pkg/errors"
@@ -22,6 +23,44 @@ func (a *Agent) runCommands(ctx context.Context, tc *taskContext, commands []mod
 			return errors.New("runCommands canceled")
 		}
 
+		if commandInfo.IsParallel() {
+			if !commandInfo.RunOnVariant(tc.taskConfig.BuildVariant.Name) {
+				tc.logger.Task().Infof("Skipping parallel block on variant %s (step %d of %d)",
+					tc.taskConfig.BuildVariant.Name, i+1, len(commands))
+				continue
+			}
+
+			var shouldRun bool
+			shouldRun, err = commandInfo.ShouldRun(&util.ConditionContext{
+				Expansions:     tc.taskConfig.Expansions,
+				PreviousFailed: tc.hasCommandFailed(),
+			})
+			if err != nil {
+				tc.logger.Task().Errorf("Couldn't evaluate conditions of parallel block: %v", err)
+				if isTaskCommands {
+					return errors.Wrap(err, "problem evaluating command conditions")
+				}
+				err = nil
+				continue
+			}
+			if !shouldRun {
+				tc.logger.Task().Infof("Skipping parallel block because its conditions are not met (step %d of %d)",
+					i+1, len(commands))
+				continue
+			}
+
+			tc.logger.Task().Infof("Running parallel block (step %d of %d)", i+1, len(commands))
+			err = a.runParallelCommands(ctx, tc, commandInfo.Parallel, tc.hasCommandFailed(), isTaskCommands)
+			if err != nil {
+				tc.setCommandFailed()
+				tc.logger.Task().Errorf("Parallel block failed: %v", err)
+				if isTaskCommands {
+					return errors.Wrap(err, "parallel block failed")
+				}
+			}
+			continue
+		}
+
 		cmds, err = command.Render(commandInfo, tc.taskConfig.Project.Functions)
 		if err != nil {
 			tc.logger.Task().Errorf("Couldn't parse plugin command '%v': %v", commandInfo.Command, err)
@@ -32,6 +71,29 @@ func (a *Agent) runCommands(ctx context.Context, tc *taskContext, commands []mod
 			continue
 		}
 
+		// the conditions on a function call apply to all of the
+		// function's commands; other commands carry their own
+		if commandInfo.Function != "" {
+			var shouldRun bool
+			shouldRun, err = commandInfo.ShouldRun(&util.ConditionContext{
+				Expansions:     tc.taskConfig.Expansions,
+				PreviousFailed: tc.hasCommandFailed(),
+			})
+			if err != nil {
+				tc.logger.Task().Errorf("Couldn't evaluate conditions of function '%s': %v", commandInfo.Function, err)
+				if isTaskCommands {
+					return errors.Wrap(err, "problem evaluating command conditions")
+				}
+				err = nil
+				continue
+			}
+			if !shouldRun {
+				tc.logger.Task().Infof("Skipping function '%s' because its conditions are not met (step %d of %d)",
+					commandInfo.Function, i+1, len(commands))
+				continue
+			}
+		}
+
 		for idx, cmd := range cmds {
 			if ctx.Err() != nil {
 				grip.Error("runCommands canceled")
@@ -51,6 +113,26 @@ func (a *Agent) runCommands(ctx context.Context, tc *taskContext, commands []mod
 				continue
 			}
 
+			var shouldRun bool
+			ifCondition, unless := cmd.Conditions()
+			shouldRun, err = util.EvaluateConditions(ifCondition, unless, &util.ConditionContext{
+				Expansions:     tc.taskConfig.Expansions,
+				PreviousFailed: tc.hasCommandFailed(),
+			})
+			if err != nil {
+				tc.logger.Task().Errorf("Couldn't evaluate conditions of command %s: %v", fullCommandName, err)
+				if isTaskCommands {
+					return errors.Wrap(err, "problem evaluating command conditions")
+				}
+				err = nil
+				continue
+			}
+			if !shouldRun {
+				tc.logger.Task().Infof("Skipping command %s because its conditions are not met (step %d of %d)",
+					fullCommandName, i+1, len(commands))
+				continue
+			}
+
 			if len(cmds) == 1 {
 				tc.logger.Task().Infof("Running command %s (step %d of %d)", fullCommandName, i+1, len(commands))
 			} else {
@@ -80,6 +162,7 @@ func (a *Agent) runCommands(ctx context.Context, tc *taskContext, commands []mod
 
 			tc.logger.Execution().Infof("Finished %v in %v", fullCommandName, time.Since(start).String())
 			if err != nil {
+				tc.setCommandFailed()
 				tc.logger.Task().Errorf("Command failed: %v", err)
 				if isTaskCommands {
 					return errors.Wrap(err, "command failed")
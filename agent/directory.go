@@ -17,7 +17,14 @@ import (
 // createTaskDirectory makes a directory for the agent to execute
 // the current task within. It changes the necessary variables
 // so that all of the agent's operations will use this folder.
+// Tasks that continue a task group reuse the group's directory.
 func (a *Agent) createTaskDirectory(tc *taskContext) (string, error) {
+	if tc.taskGroup != nil {
+		tc.logger.Execution().Infof("Using task group directory for task execution: %v", tc.taskGroup.directory)
+		tc.taskConfig.WorkDir = tc.taskGroup.directory
+		return tc.taskGroup.directory, nil
+	}
+
 	h := md5.New()
 
 	_, err := h.Write([]byte(
@@ -0,0 +1,103 @@
+package agent
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/rest/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const localTestProject = `
+pre:
+  - command: shell.exec
+    params:
+      script: echo "pre ran"
+
+post:
+  - command: shell.exec
+    params:
+      script: echo "post ran"
+
+functions:
+  "say":
+    - command: shell.exec
+      params:
+        script: echo "saying ${message}"
+
+tasks:
+  - name: passes
+    commands:
+      - func: "say"
+  - name: fails
+    commands:
+      - command: shell.exec
+        params:
+          script: exit 1
+
+buildvariants:
+  - name: local_variant
+    tasks:
+      - name: passes
+      - name: fails
+`
+
+func runLocalTask(t *testing.T, taskName string) (*client.Local, string) {
+	dir, err := ioutil.TempDir("", "agent-local")
+	require.NoError(t, err)
+
+	comm, err := client.NewLocal(client.LocalOptions{
+		ProjectConfig: []byte(localTestProject),
+		TaskName:      taskName,
+		BuildVariant:  "local_variant",
+		WorkDir:       filepath.Join(dir, "work"),
+		OutputDir:     filepath.Join(dir, "output"),
+		Expansions:    map[string]string{"message": "hello"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "work"), 0777))
+
+	agt := New(Options{HostID: "localhost"}, comm)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	require.NoError(t, agt.RunTask(ctx, comm.TaskData()))
+
+	return comm, dir
+}
+
+func TestRunLocalTaskSucceeds(t *testing.T) {
+	assert := assert.New(t)
+	comm, dir := runLocalTask(t, "passes")
+	defer os.RemoveAll(dir)
+
+	detail := comm.GetEndTaskDetail()
+	require.NotNil(t, detail)
+	assert.Equal(evergreen.TaskSucceeded, detail.Status)
+
+	taskLog, err := ioutil.ReadFile(filepath.Join(dir, "output", "logs", "task.log"))
+	require.NoError(t, err)
+	assert.Contains(string(taskLog), "pre ran")
+	assert.Contains(string(taskLog), "saying hello")
+	assert.Contains(string(taskLog), "post ran")
+
+	_, err = os.Stat(filepath.Join(dir, "output", "end_task.json"))
+	assert.NoError(err)
+}
+
+func TestRunLocalTaskFails(t *testing.T) {
+	assert := assert.New(t)
+	comm, dir := runLocalTask(t, "fails")
+	defer os.RemoveAll(dir)
+
+	detail := comm.GetEndTaskDetail()
+	require.NotNil(t, detail)
+	assert.Equal(evergreen.TaskFailed, detail.Status)
+}
@@ -0,0 +1,213 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/evergreen-ci/evergreen/command"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/rest/client"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mongodb/grip"
+	"github.com/mongodb/grip/recovery"
+	"github.com/pkg/errors"
+)
+
+// parallelEntry is one command configuration of a parallel block,
+// rendered into the commands that run, in order, in its own goroutine.
+// Each entry has its own copy of the task config's expansions, so that
+// entries can't modify the expansions while other entries read them.
+type parallelEntry struct {
+	info     model.PluginCommandConf
+	name     string
+	cmds     []command.Command
+	logger   client.LoggerProducer
+	conf     *model.TaskConfig
+	original util.Expansions
+}
+
+// runParallelCommands runs the entries of a parallel block
+// concurrently, each with its own prefixed logger. Every command is
+// subject to its own timeout, and the block as a whole to the idle
+// timeout of its longest running command. If the block is
+// fail fast, the first failing command cancels the rest of the block;
+// otherwise the block waits for all entries to finish. Once the block
+// finishes, the expansions that each entry set are merged back into the
+// task's expansions, in the order of the entries. The previousFailed
+// argument is the state that the commands' conditions are evaluated
+// against.
+func (a *Agent) runParallelCommands(ctx context.Context, tc *taskContext, block *model.ParallelCommandBlock,
+	previousFailed, isTaskCommands bool) error {
+
+	entries := []*parallelEntry{}
+	maxTimeout := time.Duration(0)
+
+	for idx, commandInfo := range block.Commands {
+		if commandInfo.IsParallel() {
+			return errors.New("parallel blocks can not be nested")
+		}
+
+		cmds, err := command.Render(commandInfo, tc.taskConfig.Project.Functions)
+		if err != nil {
+			tc.logger.Task().Errorf("Couldn't parse plugin command '%v' in parallel block: %v", commandInfo.Command, err)
+			if isTaskCommands {
+				return err
+			}
+			continue
+		}
+		if len(cmds) == 0 {
+			continue
+		}
+
+		entry := &parallelEntry{
+			info:     commandInfo,
+			cmds:     cmds,
+			original: *util.NewExpansions(*tc.taskConfig.Expansions),
+		}
+		conf := *tc.taskConfig
+		conf.Expansions = util.NewExpansions(entry.original)
+		entry.conf = &conf
+
+		for key, val := range commandInfo.Vars {
+			newVal, err := entry.conf.Expansions.ExpandString(val)
+			if err != nil {
+				return errors.Wrapf(err, "Can't expand '%v'", val)
+			}
+			entry.conf.Expansions.Put(key, newVal)
+		}
+		entry.name = fmt.Sprintf("parallel %d: %s", idx+1, a.getCommandName(commandInfo, cmds[0]))
+		entry.logger = client.NewPrefixedLogHarness(tc.logger, entry.name)
+
+		for _, cmd := range cmds {
+			cmd.SetType(tc.taskConfig.Project.CommandType)
+			if timeout := a.getTimeout(cmd); timeout > maxTimeout {
+				maxTimeout = timeout
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	// the idle timeout watcher covers the whole block, while each
+	// command's own timeout is enforced by its context
+	if isTaskCommands {
+		tc.setCurrentCommand(entries[0].cmds[0])
+		tc.setCurrentTimeout(maxTimeout)
+		a.comm.UpdateLastMessageTime()
+	} else {
+		tc.setCurrentTimeout(defaultIdleTimeout)
+	}
+
+	blockCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	catcher := grip.NewCatcher()
+	wg := &sync.WaitGroup{}
+	tc.logger.Task().Infof("Running %d commands in parallel (fail fast: %t)", len(entries), block.FailFast)
+
+	for _, entry := range entries {
+		wg.Add(1)
+		go func(entry *parallelEntry) {
+			defer wg.Done()
+			defer recovery.LogStackTraceAndContinue("parallel command")
+			defer func() { grip.Warning(entry.logger.Close()) }()
+
+			err := a.runParallelEntry(blockCtx, tc, entry, previousFailed, isTaskCommands)
+			if err != nil {
+				catcher.Add(errors.Wrap(err, entry.name))
+				if block.FailFast {
+					cancel()
+				}
+			}
+		}(entry)
+	}
+
+	wg.Wait()
+
+	tc.mergeParallelExpansions(entries)
+
+	return catcher.Resolve()
+}
+
+// mergeParallelExpansions sets the expansions that the entries of a
+// parallel block added or changed in the task's expansions.
+func (tc *taskContext) mergeParallelExpansions(entries []*parallelEntry) {
+	tc.Lock()
+	defer tc.Unlock()
+
+	for _, entry := range entries {
+		for key, val := range *entry.conf.Expansions {
+			if prev, ok := entry.original[key]; !ok || prev != val {
+				tc.taskConfig.Expansions.Put(key, val)
+			}
+		}
+	}
+}
+
+// runParallelEntry runs the commands of one entry of a parallel block
+// in order, stopping at the first failure.
+func (a *Agent) runParallelEntry(ctx context.Context, tc *taskContext, entry *parallelEntry, previousFailed, isTaskCommands bool) error {
+	logger := entry.logger
+
+	for idx, cmd := range entry.cmds {
+		if ctx.Err() != nil {
+			return errors.New("parallel block canceled")
+		}
+
+		fullCommandName := a.getCommandName(entry.info, cmd)
+
+		if !entry.info.RunOnVariant(entry.conf.BuildVariant.Name) {
+			logger.Task().Infof("Skipping command %s on variant %s", fullCommandName, entry.conf.BuildVariant.Name)
+			continue
+		}
+
+		ifCondition, unless := cmd.Conditions()
+		shouldRun, err := util.EvaluateConditions(ifCondition, unless, &util.ConditionContext{
+			Expansions:     entry.conf.Expansions,
+			PreviousFailed: previousFailed,
+		})
+		if err != nil {
+			logger.Task().Errorf("Couldn't evaluate conditions of command %s: %v", fullCommandName, err)
+			if isTaskCommands {
+				return errors.Wrap(err, "problem evaluating command conditions")
+			}
+			continue
+		}
+		if !shouldRun {
+			logger.Task().Infof("Skipping command %s because its conditions are not met", fullCommandName)
+			continue
+		}
+
+		logger.Task().Infof("Running command %s (%d of %d)", fullCommandName, idx+1, len(entry.cmds))
+
+		timeout := a.getTimeout(cmd)
+		cmdCtx, cancel := context.WithTimeout(ctx, timeout)
+		start := time.Now()
+		err = cmd.Execute(cmdCtx, a.comm, logger, entry.conf)
+		timedOut := cmdCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil
+		cancel()
+
+		logger.Execution().Infof("Finished %v in %v", fullCommandName, time.Since(start).String())
+
+		if timedOut {
+			logger.Task().Errorf("Command %s timed out after %s", fullCommandName, timeout)
+			if isTaskCommands {
+				tc.setCurrentCommand(cmd)
+				tc.reachTimeOut()
+			}
+			return errors.Errorf("command %s timed out after %s", fullCommandName, timeout)
+		}
+		if err != nil {
+			logger.Task().Errorf("Command failed: %v", err)
+			return errors.Wrap(err, "command failed")
+		}
+	}
+
+	return nil
+}
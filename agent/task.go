@@ -134,17 +134,31 @@ func (a *Agent) startTask(ctx context.Context, tc *taskContext, complete chan<-
 }
 
 func (a *Agent) runPreTaskCommands(ctx context.Context, tc *taskContext) {
-	if tc.taskConfig.Project.Pre != nil {
-		tc.logger.Execution().Info("Running pre-task commands.")
-		var cancel context.CancelFunc
-		ctx, cancel = a.withCallbackTimeout(ctx, tc)
-		defer cancel()
-		err := a.runCommands(ctx, tc, tc.taskConfig.Project.Pre.List(), false)
-		if err != nil {
-			tc.logger.Execution().Errorf("Running pre-task script failed: %v", err)
+	if tg := tc.taskConfig.Project.FindTaskGroup(tc.taskGroupName()); tg != nil {
+		// the group's setup only runs before its first task on the host
+		if tc.taskGroup == nil && tg.SetupGroup != nil {
+			a.runSetupCommands(ctx, tc, "setup-group", tg.SetupGroup)
+		}
+		if tg.SetupTask != nil {
+			a.runSetupCommands(ctx, tc, "setup-task", tg.SetupTask)
 		}
-		tc.logger.Execution().Info("Finished running pre-task commands.")
+		return
+	}
+	if tc.taskConfig.Project.Pre != nil {
+		a.runSetupCommands(ctx, tc, "pre-task", tc.taskConfig.Project.Pre)
+	}
+}
+
+func (a *Agent) runSetupCommands(ctx context.Context, tc *taskContext, name string, commands *model.YAMLCommandSet) {
+	tc.logger.Execution().Infof("Running %s commands.", name)
+	var cancel context.CancelFunc
+	ctx, cancel = a.withCallbackTimeout(ctx, tc)
+	defer cancel()
+	err := a.runCommands(ctx, tc, commands.List(), false)
+	if err != nil {
+		tc.logger.Execution().Errorf("Running %s script failed: %v", name, err)
 	}
+	tc.logger.Execution().Infof("Finished running %s commands.", name)
 }
 
 func (tc *taskContext) setCurrentCommand(command command.Command) {
@@ -186,6 +200,23 @@ func (tc *taskContext) reachTimeOut() {
 	tc.timedOut = true
 }
 
+// setCommandFailed records that one of the task's commands failed, which
+// the conditions of the task's later commands, including its post and
+// task group teardown commands, can check.
+func (tc *taskContext) setCommandFailed() {
+	tc.Lock()
+	defer tc.Unlock()
+
+	tc.commandFailed = true
+}
+
+func (tc *taskContext) hasCommandFailed() bool {
+	tc.RLock()
+	defer tc.RUnlock()
+
+	return tc.commandFailed
+}
+
 func (tc *taskContext) hadTimedOut() bool {
 	tc.RLock()
 	defer tc.RUnlock()
@@ -0,0 +1,107 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/evergreen-ci/evergreen/apimodels"
+	"github.com/evergreen-ci/evergreen/subprocess"
+	"github.com/mongodb/grip"
+)
+
+// taskGroupContext tracks the task group that the agent is running tasks
+// from. The tasks of a group share a task directory, and the agent only
+// cleans up after the group once the API server hands it a task that is
+// not in the group.
+type taskGroupContext struct {
+	name      string
+	build     string
+	directory string
+	taskIds   []string
+
+	// lastTask is the most recently run task in the group, which the
+	// group's teardown commands run as.
+	lastTask *taskContext
+
+	// failed is true if a command of any of the group's tasks failed.
+	failed bool
+}
+
+// includes returns true if the next task belongs to the same run of the
+// task group.
+func (g *taskGroupContext) includes(next *apimodels.NextTaskResponse) bool {
+	return next.TaskId != "" && next.TaskGroup == g.name && next.Build == g.build
+}
+
+// taskGroupName returns the name of the task group the task is in, or an
+// empty string if it isn't in one.
+func (tc *taskContext) taskGroupName() string {
+	if tc.taskConfig == nil || tc.taskConfig.Task == nil {
+		return ""
+	}
+	return tc.taskConfig.Task.TaskGroup
+}
+
+// inTaskGroup returns true if the task ran as part of a task group, in
+// which case its directory and processes outlive the task.
+func (tc *taskContext) inTaskGroup() bool {
+	return tc.taskGroupName() != "" && tc.taskDirectory != ""
+}
+
+// updateTaskGroup records the task that just finished running in the agent's
+// task group state, ending the previous group if the task did not continue it.
+func (a *Agent) updateTaskGroup(ctx context.Context, tc *taskContext) {
+	if !tc.inTaskGroup() {
+		if a.taskGroup != nil {
+			a.endTaskGroup(ctx)
+		}
+		return
+	}
+
+	group := a.taskGroup
+	if group == nil {
+		group = &taskGroupContext{
+			name:      tc.taskGroupName(),
+			build:     tc.taskConfig.Task.BuildId,
+			directory: tc.taskDirectory,
+		}
+	}
+	group.taskIds = append(group.taskIds, tc.task.ID)
+	group.lastTask = tc
+	group.failed = group.failed || tc.hasCommandFailed()
+	a.taskGroup = group
+}
+
+// endTaskGroup runs the teardown_group commands of the agent's current task
+// group, then kills the processes that the group's tasks started and removes
+// the group's directory.
+func (a *Agent) endTaskGroup(ctx context.Context) {
+	group := a.taskGroup
+	a.taskGroup = nil
+	tc := group.lastTask
+
+	tc.logger = a.comm.GetLoggerProducer(ctx, tc.task)
+	defer func() {
+		if err := tc.logger.Close(); err != nil {
+			grip.Errorf("Error closing logger: %v", err)
+		}
+	}()
+
+	if group.failed {
+		tc.setCommandFailed()
+	}
+
+	tg := tc.taskConfig.Project.FindTaskGroup(group.name)
+	if tg != nil && tg.TeardownGroup != nil {
+		a.runCallbackCommands(ctx, tc, "teardown-group", tg.TeardownGroup)
+	}
+
+	if a.opts.Cleanup {
+		for _, id := range group.taskIds {
+			grip.Infof("cleaning up processes for task: %s", id)
+			if err := subprocess.KillSpawnedProcs(id, tc.logger.Task()); err != nil {
+				grip.Criticalf("Error cleaning up spawned processes (task group): %v", err)
+			}
+		}
+	}
+	a.removeTaskDirectory(tc)
+}
@@ -28,10 +28,11 @@ import (
 )
 
 const (
-	EmailProvider = "email"
-	JiraProvider  = "jira"
-	SlackProvider = "slack"
-	RunnerName    = "alerter"
+	EmailProvider   = "email"
+	JiraProvider    = "jira"
+	SlackProvider   = "slack"
+	WebhookProvider = "webhook"
+	RunnerName      = "alerter"
 )
 
 // QueueProcessor handles looping over any unprocessed alerts in the queue and delivers them.
@@ -275,6 +276,8 @@ func (qp *QueueProcessor) getDeliverer(alertConf model.AlertConfig) (Deliverer,
 		return qp.newSlackProvider(alertConf)
 	case JiraProvider:
 		return qp.newJIRAProvider(alertConf)
+	case WebhookProvider:
+		return qp.newWebhookProvider(alertConf)
 	case EmailProvider:
 		return &EmailDeliverer{
 			SMTPSettings{
@@ -0,0 +1,87 @@
+package alerts
+
+import (
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/webhook"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"github.com/pkg/errors"
+)
+
+type webhookSender interface {
+	Send(*webhook.Payload) error
+}
+
+// webhookDeliverer posts alerts as JSON to a webhook.
+type webhookDeliverer struct {
+	sender webhookSender
+	uiRoot string
+}
+
+func (qp *QueueProcessor) newWebhookProvider(alertConf model.AlertConfig) (Deliverer, error) {
+	settings := alertConf.GetSettingsMap()
+	url := settings["url"]
+	if url == "" {
+		return nil, errors.New("must specify a webhook url")
+	}
+
+	// webhooks without a secret of their own are signed with the site's
+	// webhook secret
+	secret := settings["secret"]
+	if secret == "" {
+		secret = qp.config.Alerts.WebhookSecret
+	}
+
+	return &webhookDeliverer{
+		sender: thirdparty.NewWebhookSender(url, secret),
+		uiRoot: qp.config.Ui.Url,
+	}, nil
+}
+
+func (wd *webhookDeliverer) Deliver(ctx AlertContext, _ model.AlertConfig) error {
+	return errors.WithStack(wd.sender.Send(wd.payload(ctx)))
+}
+
+// payload returns the webhook payload for the alert, including the
+// details of whichever documents the alert is about.
+func (wd *webhookDeliverer) payload(ctx AlertContext) *webhook.Payload {
+	payload := webhook.NewPayload(webhook.SourceAlerts, ctx.AlertRequest.Trigger)
+	payload.Subject = ctx.AlertRequest.Display
+
+	if ctx.ProjectRef != nil {
+		payload.Project = &webhook.ProjectInfo{
+			Identifier:  ctx.ProjectRef.Identifier,
+			DisplayName: ctx.ProjectRef.DisplayName,
+			Branch:      ctx.ProjectRef.Branch,
+		}
+	}
+	if ctx.Version != nil {
+		payload.Version = webhook.NewVersionInfo(ctx.Version)
+		payload.URL = wd.uiRoot + "/version/" + ctx.Version.Id
+	}
+	if ctx.Build != nil {
+		payload.Build = webhook.NewBuildInfo(ctx.Build)
+		payload.URL = wd.uiRoot + "/build/" + ctx.Build.Id
+	}
+	if ctx.Task != nil {
+		payload.Task = webhook.NewTaskInfo(ctx.Task)
+		payload.URL = wd.uiRoot + "/task/" + ctx.Task.Id
+		for _, test := range ctx.FailedTests {
+			payload.FailedTests = append(payload.FailedTests, cleanTestName(test.TestFile))
+		}
+	}
+	if ctx.Patch != nil {
+		payload.Patch = webhook.NewPatchInfo(ctx.Patch)
+	}
+	if ctx.Host != nil {
+		payload.Host = webhook.NewHostInfo(ctx.Host)
+		payload.URL = wd.uiRoot + "/host/" + ctx.Host.Id
+	}
+
+	// the subject lines of task alerts need the task's whole context
+	if ctx.Task != nil && ctx.Build != nil && ctx.Version != nil && ctx.ProjectRef != nil &&
+		len(ctx.Version.Revision) >= 8 {
+		payload.Subject = getSubject(ctx)
+	}
+
+	return payload
+}
@@ -0,0 +1,102 @@
+package alerts
+
+import (
+	"testing"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/alert"
+	"github.com/evergreen-ci/evergreen/model/alertrecord"
+	"github.com/evergreen-ci/evergreen/model/build"
+	"github.com/evergreen-ci/evergreen/model/host"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/model/version"
+	"github.com/evergreen-ci/evergreen/model/webhook"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockWebhookSender struct {
+	payloads []*webhook.Payload
+}
+
+func (s *mockWebhookSender) Send(p *webhook.Payload) error {
+	s.payloads = append(s.payloads, p)
+	return nil
+}
+
+func TestWebhookDelivererTaskAlert(t *testing.T) {
+	assert := assert.New(t)
+	sender := &mockWebhookSender{}
+	deliverer := &webhookDeliverer{sender: sender, uiRoot: "http://evergreen.example.com"}
+
+	ctx := AlertContext{
+		AlertRequest: &alert.AlertRequest{Trigger: alertrecord.TaskFailedId, Display: "task failed"},
+		ProjectRef:   &model.ProjectRef{Identifier: "mci", DisplayName: "Evergreen", Branch: "master"},
+		Task: &task.Task{
+			Id:           "t1",
+			DisplayName:  "compile",
+			BuildVariant: "linux",
+			Status:       evergreen.TaskFailed,
+		},
+		Build:       &build.Build{Id: "b1", DisplayName: "Linux", BuildVariant: "linux"},
+		Version:     &version.Version{Id: "v1", Revision: "0123456789abcdef", Author: "somebody"},
+		FailedTests: []task.TestResult{{TestFile: "jstests/core/big_test.js", Status: evergreen.TestFailedStatus}},
+	}
+	assert.NoError(deliverer.Deliver(ctx, model.AlertConfig{Provider: WebhookProvider}))
+	assert.Len(sender.payloads, 1)
+
+	p := sender.payloads[0]
+	assert.Equal(webhook.PayloadVersion, p.SchemaVersion)
+	assert.Equal(webhook.SourceAlerts, p.Source)
+	assert.Equal(alertrecord.TaskFailedId, p.Trigger)
+	assert.Equal("http://evergreen.example.com/task/t1", p.URL)
+	assert.Equal("mci", p.Project.Identifier)
+	assert.Equal("t1", p.Task.Id)
+	assert.Equal("b1", p.Build.Id)
+	assert.Equal("0123456789abcdef", p.Version.Revision)
+	assert.Equal([]string{"big_test.js"}, p.FailedTests)
+	assert.Contains(p.Subject, "compile")
+	assert.Nil(p.Host)
+}
+
+func TestWebhookDelivererHostAlert(t *testing.T) {
+	assert := assert.New(t)
+	sender := &mockWebhookSender{}
+	deliverer := &webhookDeliverer{sender: sender, uiRoot: "http://evergreen.example.com"}
+
+	ctx := AlertContext{
+		AlertRequest: &alert.AlertRequest{Trigger: alertrecord.SpawnHostTwoHourWarning, Display: "expiring"},
+		Host:         &host.Host{Id: "h1", Host: "h1.example.com"},
+	}
+	assert.NoError(deliverer.Deliver(ctx, model.AlertConfig{Provider: WebhookProvider}))
+
+	p := sender.payloads[0]
+	assert.Equal("h1", p.Host.Id)
+	assert.Equal("http://evergreen.example.com/host/h1", p.URL)
+	assert.Equal("expiring", p.Subject)
+	assert.Nil(p.Task)
+}
+
+func TestNewWebhookProvider(t *testing.T) {
+	assert := assert.New(t)
+	qp := &QueueProcessor{config: &evergreen.Settings{}}
+	qp.config.Alerts.WebhookSecret = "site"
+
+	_, err := qp.getDeliverer(model.AlertConfig{Provider: WebhookProvider})
+	assert.Error(err)
+
+	deliverer, err := qp.getDeliverer(model.AlertConfig{
+		Provider: WebhookProvider,
+		Settings: map[string]interface{}{"url": "http://example.com/hook"},
+	})
+	assert.NoError(err)
+	assert.Equal("site", deliverer.(*webhookDeliverer).sender.(*thirdparty.WebhookSender).Secret)
+
+	deliverer, err = qp.getDeliverer(model.AlertConfig{
+		Provider: WebhookProvider,
+		Settings: map[string]interface{}{"url": "http://example.com/hook", "secret": "own"},
+	})
+	assert.NoError(err)
+	assert.Equal("own", deliverer.(*webhookDeliverer).sender.(*thirdparty.WebhookSender).Secret)
+}
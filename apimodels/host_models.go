@@ -0,0 +1,30 @@
+package apimodels
+
+// HostRegistrationRequest is sent by a host that bootstraps itself from its
+// user data to exchange its one-time token for a host secret.
+type HostRegistrationRequest struct {
+	Token string `json:"token"`
+}
+
+// HostRegistrationResponse contains what a registered host needs to run its
+// distro's setup script and start the agent.
+type HostRegistrationResponse struct {
+	HostId           string            `json:"host_id"`
+	Secret           string            `json:"secret"`
+	Setup            string            `json:"setup,omitempty"`
+	SetupAsSudo      bool              `json:"setup_as_sudo,omitempty"`
+	WorkingDirectory string            `json:"working_directory"`
+	AgentURL         string            `json:"agent_url"`
+	AgentEnvironment map[string]string `json:"agent_env,omitempty"`
+}
+
+// HostSetupLog contains output from the setup script a host is running.
+type HostSetupLog struct {
+	Logs string `json:"logs"`
+}
+
+// HostSetupResult is sent by a host once its setup script has finished.
+type HostSetupResult struct {
+	Success bool   `json:"success"`
+	Logs    string `json:"logs,omitempty"`
+}
@@ -11,4 +11,11 @@ type S3CopyRequest struct {
 	S3DestinationBucket string `json:"s3_destination_bucket"`
 	S3DestinationPath   string `json:"s3_destination_path"`
 	S3DisplayName       string `json:"display_name"`
+
+	// Provider, Endpoint and PathStyle are the storage service that the
+	// task copies within. The API server only copies within the service
+	// that the admin configured, and rejects requests for another.
+	Provider  string `json:"provider,omitempty"`
+	Endpoint  string `json:"endpoint,omitempty"`
+	PathStyle bool   `json:"path_style,omitempty"`
 }
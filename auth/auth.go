@@ -39,6 +39,15 @@ func LoadUserManager(authConfig evergreen.AuthConfig) (UserManager, error) {
 		// the mean time.
 		grip.Warning(errors.WithStack(err))
 	}
+	if authConfig.OIDC != nil {
+		if manager != nil {
+			return nil, errors.New("Cannot have multiple forms of authentication in configuration")
+		}
+		manager, err = NewOIDCUserManager(authConfig.OIDC)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	if manager != nil {
 		return manager, nil
@@ -59,9 +68,23 @@ func setLoginToken(token string, w http.ResponseWriter) {
 	http.SetCookie(w, authTokenCookie)
 }
 
+// GroupUser is a User whose groups, as reported by an identity provider,
+// may grant them permissions beyond those configured for their username.
+type GroupUser interface {
+	GetGroups() []string
+	IsGrantedSuperUser() bool
+	IsGrantedProjectAdmin(project string) bool
+}
+
+// GithubUser is implemented by users whose identity provider has verified
+// their GitHub login.
+type GithubUser interface {
+	GetGithubLogin() string
+}
+
 // IsSuperUser verifies that a given user has super user permissions.
-// A user has these permission if they are in the super users list or if the list is empty,
-// in which case all users are super users.
+// A user has these permission if they are in the super users list, if their groups
+// make them a super user or if the list is empty, in which case all users are super users.
 func IsSuperUser(superUsers []string, u User) bool {
 	if u == nil || u.IsNil() {
 		return false
@@ -70,6 +93,25 @@ func IsSuperUser(superUsers []string, u User) bool {
 		len(superUsers) == 0 {
 		return true
 	}
+	if g, ok := u.(GroupUser); ok && g.IsGrantedSuperUser() {
+		return true
+	}
 	return false
 
 }
+
+// IsProjectAdmin verifies that a given user is an admin of the project,
+// either because they are in its list of admins or because their groups
+// make them one.
+func IsProjectAdmin(project string, admins []string, u User) bool {
+	if u == nil || u.IsNil() {
+		return false
+	}
+	if util.StringSliceContains(admins, u.Username()) {
+		return true
+	}
+	if g, ok := u.(GroupUser); ok && g.IsGrantedProjectAdmin(project) {
+		return true
+	}
+	return false
+}
@@ -68,3 +68,37 @@ func TestSuperUserValidation(t *testing.T) {
 	assert.False(IsSuperUser(superUsers, ru))
 	assert.False(IsSuperUser(superUsers, nil))
 }
+
+type groupUser struct {
+	simpleUser
+	groups    []string
+	superUser bool
+	projects  []string
+}
+
+func (u *groupUser) GetGroups() []string      { return u.groups }
+func (u *groupUser) IsGrantedSuperUser() bool { return u.superUser }
+func (u *groupUser) IsGrantedProjectAdmin(project string) bool {
+	for _, p := range u.projects {
+		if p == project {
+			return true
+		}
+	}
+	return false
+}
+
+func TestGroupGrants(t *testing.T) {
+	assert := assert.New(t)
+	superUsers := []string{"super"}
+	granted := &groupUser{simpleUser: simpleUser{UserId: "granted"}, superUser: true, projects: []string{"mci"}}
+	regular := &groupUser{simpleUser: simpleUser{UserId: "regular"}}
+
+	assert.True(IsSuperUser(superUsers, granted))
+	assert.False(IsSuperUser(superUsers, regular))
+
+	assert.True(IsProjectAdmin("mci", nil, granted))
+	assert.False(IsProjectAdmin("other", nil, granted))
+	assert.False(IsProjectAdmin("mci", nil, regular))
+	assert.True(IsProjectAdmin("mci", []string{"regular"}, regular))
+	assert.False(IsProjectAdmin("mci", []string{"regular"}, nil))
+}
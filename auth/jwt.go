@@ -0,0 +1,227 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256" // register the hashes used by the supported algorithms
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// jwtClockSkew is how far the clocks of Evergreen and the identity
+// provider may disagree when checking a token's expiry.
+const jwtClockSkew = time.Minute
+
+// jwtAlgorithms maps the signing algorithms of ID tokens that are
+// accepted to the hash they use.
+var jwtAlgorithms = map[string]crypto.Hash{
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+	"ES256": crypto.SHA256,
+	"ES384": crypto.SHA384,
+	"ES512": crypto.SHA512,
+}
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	KeyId     string `json:"kid"`
+}
+
+// jwtClaims are the claims of a token. The standard claims are read by
+// the accessors, the others by name, since identity providers differ in
+// which claims they use for usernames and groups.
+type jwtClaims map[string]interface{}
+
+type signedJWT struct {
+	header    jwtHeader
+	claims    jwtClaims
+	signed    []byte
+	signature []byte
+}
+
+// parseJWT splits a compact serialized token into its parts without
+// verifying it.
+func parseJWT(token string) (*signedJWT, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	jwt := &signedJWT{signed: []byte(parts[0] + "." + parts[1])}
+	header, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed token header")
+	}
+	if err = json.Unmarshal(header, &jwt.header); err != nil {
+		return nil, errors.Wrap(err, "malformed token header")
+	}
+	claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed token claims")
+	}
+	if err = json.Unmarshal(claims, &jwt.claims); err != nil {
+		return nil, errors.Wrap(err, "malformed token claims")
+	}
+	if jwt.signature, err = base64.RawURLEncoding.DecodeString(parts[2]); err != nil {
+		return nil, errors.Wrap(err, "malformed token signature")
+	}
+	return jwt, nil
+}
+
+// verify checks the token's signature with the key.
+func (t *signedJWT) verify(key crypto.PublicKey) error {
+	hash, ok := jwtAlgorithms[t.header.Algorithm]
+	if !ok {
+		return errors.Errorf("unsupported signing algorithm '%s'", t.header.Algorithm)
+	}
+	h := hash.New()
+	_, _ = h.Write(t.signed)
+	digest := h.Sum(nil)
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(t.header.Algorithm, "RS") {
+			return errors.Errorf("RSA key can't verify %s signature", t.header.Algorithm)
+		}
+		return errors.Wrap(rsa.VerifyPKCS1v15(k, hash, digest, t.signature), "invalid token signature")
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(t.header.Algorithm, "ES") {
+			return errors.Errorf("EC key can't verify %s signature", t.header.Algorithm)
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(t.signature) != 2*size {
+			return errors.New("invalid token signature")
+		}
+		r := new(big.Int).SetBytes(t.signature[:size])
+		s := new(big.Int).SetBytes(t.signature[size:])
+		if !ecdsa.Verify(k, digest, r, s) {
+			return errors.New("invalid token signature")
+		}
+		return nil
+	default:
+		return errors.Errorf("unsupported key type %T", key)
+	}
+}
+
+func (c jwtClaims) String(name string) string {
+	s, _ := c[name].(string)
+	return s
+}
+
+// Strings returns a claim that may be either a single string or a list
+// of strings, such as the audience or groups.
+func (c jwtClaims) Strings(name string) []string {
+	switch v := c[name].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		out := []string{}
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+func (c jwtClaims) Time(name string) time.Time {
+	if v, ok := c[name].(float64); ok {
+		return time.Unix(int64(v), 0)
+	}
+	return time.Time{}
+}
+
+// jsonWebKey is a public key from a JSON Web Key Set.
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyId   string `json:"kid"`
+	Use     string `json:"use"`
+
+	// RSA keys
+	N string `json:"n"`
+	E string `json:"e"`
+
+	// EC keys
+	Curve string `json:"crv"`
+	X     string `json:"x"`
+	Y     string `json:"y"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// publicKeys returns the signing keys of the set by key id, skipping
+// keys that are meant for encryption or of types that aren't supported.
+func (s jsonWebKeySet) publicKeys() map[string]crypto.PublicKey {
+	keys := map[string]crypto.PublicKey{}
+	for _, k := range s.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[k.KeyId] = key
+	}
+	return keys
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	decode := func(s string) (*big.Int, error) {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return new(big.Int).SetBytes(b), nil
+	}
+
+	switch k.KeyType {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.Errorf("unsupported curve '%s'", k.Curve)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, errors.Errorf("unsupported key type '%s'", k.KeyType)
+	}
+}
@@ -0,0 +1,544 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mongodb/grip"
+	"github.com/mongodb/grip/message"
+	"github.com/pkg/errors"
+)
+
+const (
+	oidcDefaultUsernameClaim = "preferred_username"
+	oidcDefaultGroupsClaim   = "groups"
+
+	// oidcStateTTL is how long a user has to log in with the identity
+	// provider once they have been redirected to it.
+	oidcStateTTL = 10 * time.Minute
+	// oidcStateCookie holds a random value that the state is bound to,
+	// so that only the browser that started a login can complete it.
+	oidcStateCookie = "evergreen-oidc-state"
+	// oidcKeyRefreshInterval limits how often the provider's keys are
+	// fetched again when a token is signed with an unknown key.
+	oidcKeyRefreshInterval = time.Minute
+	oidcRequestTimeout     = 10 * time.Second
+)
+
+// OIDCUserManager implements the UserManager with OpenID Connect
+// authorization code login. The user is redirected to the identity
+// provider's authorization endpoint with a state string that carries the
+// page to return to, signed with the manager's Salt together with a random
+// value stored in a cookie in the user's browser, and a nonce derived
+// from the state. When the provider redirects the user back with a code,
+// the code is exchanged for an ID token, which is verified against the
+// provider's published keys and stored in the session cookie.
+// Whenever GetUserByToken is called, the ID token is verified again, so
+// the session ends when the ID token expires.
+//
+// The groups claim of the ID token is recorded on the user at login, and
+// makes them a super user or project admin if the configuration maps
+// their groups to those permissions.
+type OIDCUserManager struct {
+	conf        *evergreen.OIDCAuthConfig
+	Salt        string
+	callbackURL string
+	client      *http.Client
+
+	mu            sync.Mutex
+	provider      *oidcProvider
+	keys          map[string]crypto.PublicKey
+	keysFetchedAt time.Time
+
+	now      func() time.Time
+	syncUser func(*oidcUser) error
+}
+
+// oidcProvider is the part of the provider's discovery document that
+// login needs.
+type oidcProvider struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type oidcTokenResponse struct {
+	IdToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// oidcUser is the User described by an ID token.
+type oidcUser struct {
+	simpleUser
+	groups      []string
+	githubLogin string
+}
+
+// GetGithubLogin returns the GitHub login the identity provider
+// reported for the user, if it's configured to.
+func (u *oidcUser) GetGithubLogin() string {
+	return u.githubLogin
+}
+
+// NewOIDCUserManager initializes an OIDCUserManager with a Salt as a
+// randomly generated string used to sign login state. The provider's
+// discovery document is fetched on first use.
+func NewOIDCUserManager(conf *evergreen.OIDCAuthConfig) (*OIDCUserManager, error) {
+	if conf.Issuer == "" {
+		return nil, errors.New("no issuer for OIDC config")
+	}
+	if conf.ClientId == "" {
+		return nil, errors.New("no client id for OIDC config")
+	}
+	if conf.ClientSecret == "" {
+		return nil, errors.New("no client secret for OIDC config")
+	}
+
+	m := &OIDCUserManager{
+		conf:   conf,
+		Salt:   util.RandomString(),
+		client: &http.Client{Timeout: oidcRequestTimeout},
+		now:    time.Now,
+	}
+	m.syncUser = m.syncDBUser
+	return m, nil
+}
+
+// GetUserByToken verifies the ID token and returns the user it
+// describes.
+func (m *OIDCUserManager) GetUserByToken(token string) (User, error) {
+	claims, err := m.verifyIDToken(token, "")
+	if err != nil {
+		return nil, err
+	}
+	return m.userFromClaims(claims)
+}
+
+// CreateUserToken is not implemented in OIDCUserManager
+func (*OIDCUserManager) CreateUserToken(string, string) (string, error) {
+	return "", errors.New("OIDCUserManager does not create tokens via username/password")
+}
+
+// GetLoginHandler returns the function that starts login by redirecting
+// the user to the identity provider.
+func (m *OIDCUserManager) GetLoginHandler(callbackUri string) func(http.ResponseWriter, *http.Request) {
+	m.callbackURL = callbackUri + "/login/redirect/callback"
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		provider, err := m.discover()
+		if err != nil {
+			grip.Error(errors.Wrap(err, "problem starting OIDC login"))
+			http.Error(w, "identity provider is unavailable", http.StatusBadGateway)
+			return
+		}
+
+		binding := util.RandomString()
+		http.SetCookie(w, &http.Cookie{
+			Name:     oidcStateCookie,
+			Value:    binding,
+			HttpOnly: true,
+			Path:     "/",
+			MaxAge:   int(oidcStateTTL / time.Second),
+		})
+		state := m.newState(r.FormValue("redirect"), binding)
+		scopes := append([]string{"openid"}, m.conf.Scopes...)
+		if len(m.conf.Scopes) == 0 {
+			scopes = append(scopes, "profile", "email")
+		}
+
+		parameters := url.Values{}
+		parameters.Set("response_type", "code")
+		parameters.Set("client_id", m.conf.ClientId)
+		parameters.Set("redirect_uri", m.callbackURL)
+		parameters.Set("scope", strings.Join(scopes, " "))
+		parameters.Set("state", state)
+		parameters.Set("nonce", m.nonce(state))
+		http.Redirect(w, r, fmt.Sprintf("%v?%v", provider.AuthorizationEndpoint, parameters.Encode()), http.StatusFound)
+	}
+}
+
+// GetLoginCallbackHandler returns the function that is called when the
+// identity provider redirects the user back to Evergreen.
+func (m *OIDCUserManager) GetLoginCallbackHandler() func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if providerErr := r.FormValue("error"); providerErr != "" {
+			grip.Errorf("Identity provider rejected login: %s: %s", providerErr, r.FormValue("error_description"))
+			http.Error(w, "login was rejected by the identity provider", http.StatusUnauthorized)
+			return
+		}
+		code := r.FormValue("code")
+		if code == "" {
+			grip.Error("Error getting code from identity provider for authentication")
+			http.Error(w, "missing authorization code", http.StatusBadRequest)
+			return
+		}
+
+		// if the state doesn't match, or wasn't issued to this browser,
+		// log the error and redirect back to the login page
+		state := r.FormValue("state")
+		binding := ""
+		if cookie, err := r.Cookie(oidcStateCookie); err == nil {
+			binding = cookie.Value
+		}
+		redirect, err := m.checkState(state, binding)
+		if err != nil {
+			grip.Errorf("Error checking state when authenticating with identity provider: %+v", err)
+			http.Redirect(w, r, "/login", http.StatusFound)
+			return
+		}
+
+		idToken, err := m.exchangeCode(code)
+		if err != nil {
+			grip.Errorf("Error exchanging code with identity provider: %+v", err)
+			http.Error(w, "problem completing login with the identity provider", http.StatusBadGateway)
+			return
+		}
+		claims, err := m.verifyIDToken(idToken, m.nonce(state))
+		if err != nil {
+			grip.Errorf("Error verifying ID token from identity provider: %+v", err)
+			http.Error(w, "invalid ID token", http.StatusUnauthorized)
+			return
+		}
+		user, err := m.userFromClaims(claims)
+		if err != nil {
+			grip.Errorf("Error reading user from ID token: %+v", err)
+			http.Error(w, "invalid ID token", http.StatusUnauthorized)
+			return
+		}
+		if err = m.syncUser(user); err != nil {
+			grip.Error(message.WrapError(err, message.Fields{
+				"message": "problem recording user's groups",
+				"user":    user.Username(),
+			}))
+			http.Error(w, "problem recording user", http.StatusInternalServerError)
+			return
+		}
+
+		http.SetCookie(w, &http.Cookie{Name: oidcStateCookie, Path: "/", MaxAge: -1})
+		setLoginToken(idToken, w)
+		http.Redirect(w, r, redirect, http.StatusFound)
+	}
+}
+
+func (*OIDCUserManager) IsRedirect() bool {
+	return true
+}
+
+// grants returns whether the groups make their member a super user, and
+// the projects they make their member an admin of.
+func (m *OIDCUserManager) grants(groups []string) (bool, []string) {
+	superUser := false
+	for _, g := range m.conf.SuperUserGroups {
+		if util.StringSliceContains(groups, g) {
+			superUser = true
+			break
+		}
+	}
+
+	projects := []string{}
+	for project, projectGroups := range m.conf.ProjectAdminGroups {
+		for _, g := range projectGroups {
+			if util.StringSliceContains(groups, g) {
+				projects = append(projects, project)
+				break
+			}
+		}
+	}
+	sort.Strings(projects)
+
+	return superUser, projects
+}
+
+// syncDBUser records the user and the permissions their groups grant.
+func (m *OIDCUserManager) syncDBUser(u *oidcUser) error {
+	dbUser, err := model.GetOrCreateUser(u.Username(), u.DisplayName(), u.Email())
+	if err != nil {
+		return errors.Wrapf(err, "problem finding user '%s'", u.Username())
+	}
+	if u.githubLogin != "" && u.githubLogin != dbUser.GithubLogin {
+		if err = dbUser.SetGithubLogin(u.githubLogin); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	superUser, projects := m.grants(u.groups)
+	return errors.WithStack(dbUser.UpdateGroups(u.groups, superUser, projects))
+}
+
+func (m *OIDCUserManager) userFromClaims(claims jwtClaims) (*oidcUser, error) {
+	usernameClaim := m.conf.UsernameClaim
+	if usernameClaim == "" {
+		usernameClaim = oidcDefaultUsernameClaim
+	}
+	groupsClaim := m.conf.GroupsClaim
+	if groupsClaim == "" {
+		groupsClaim = oidcDefaultGroupsClaim
+	}
+
+	username := claims.String(usernameClaim)
+	if username == "" {
+		return nil, errors.Errorf("ID token has no '%s' claim", usernameClaim)
+	}
+	name := claims.String("name")
+	if name == "" {
+		name = username
+	}
+
+	u := &oidcUser{
+		simpleUser: simpleUser{
+			UserId:       username,
+			Name:         name,
+			EmailAddress: claims.String("email"),
+		},
+		groups: claims.Strings(groupsClaim),
+	}
+	if m.conf.GithubLoginClaim != "" {
+		u.githubLogin = claims.String(m.conf.GithubLoginClaim)
+	}
+	return u, nil
+}
+
+// verifyIDToken checks the token's signature, issuer, audience and
+// expiry, and its nonce if one is given, and returns its claims.
+func (m *OIDCUserManager) verifyIDToken(token, nonce string) (jwtClaims, error) {
+	jwt, err := parseJWT(token)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := m.discover()
+	if err != nil {
+		return nil, err
+	}
+	key, err := m.key(provider, jwt.header.KeyId)
+	if err != nil {
+		return nil, err
+	}
+	if err = jwt.verify(key); err != nil {
+		return nil, err
+	}
+
+	claims := jwt.claims
+	if claims.String("iss") != provider.Issuer {
+		return nil, errors.Errorf("ID token was issued by '%s', not '%s'", claims.String("iss"), provider.Issuer)
+	}
+	if !util.StringSliceContains(claims.Strings("aud"), m.conf.ClientId) {
+		return nil, errors.New("ID token was not issued for this client")
+	}
+	expires := claims.Time("exp")
+	if expires.IsZero() || m.now().After(expires.Add(jwtClockSkew)) {
+		return nil, errors.New("ID token has expired")
+	}
+	if nonce != "" && !hmac.Equal([]byte(claims.String("nonce")), []byte(nonce)) {
+		return nil, errors.New("ID token nonce does not match")
+	}
+	return claims, nil
+}
+
+// discover returns the provider's discovery document, fetching it on
+// first use.
+func (m *OIDCUserManager) discover() (*oidcProvider, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.provider != nil {
+		return m.provider, nil
+	}
+
+	provider := &oidcProvider{}
+	discoveryURL := strings.TrimSuffix(m.conf.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := m.getJSON(discoveryURL, provider); err != nil {
+		return nil, errors.Wrap(err, "problem fetching OIDC discovery document")
+	}
+	if provider.Issuer != m.conf.Issuer {
+		return nil, errors.Errorf("identity provider reports issuer '%s', not '%s'", provider.Issuer, m.conf.Issuer)
+	}
+	if provider.AuthorizationEndpoint == "" || provider.TokenEndpoint == "" || provider.JWKSURI == "" {
+		return nil, errors.New("OIDC discovery document is missing endpoints")
+	}
+	m.provider = provider
+	return provider, nil
+}
+
+// key returns the provider's signing key with the given id. The keys are
+// fetched again when a token is signed with an unknown key, since
+// providers rotate their keys.
+func (m *OIDCUserManager) key(provider *oidcProvider, kid string) (crypto.PublicKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if key, ok := m.lookupKey(kid); ok {
+		return key, nil
+	}
+	if m.keys != nil && m.now().Sub(m.keysFetchedAt) < oidcKeyRefreshInterval {
+		return nil, errors.Errorf("unknown signing key '%s'", kid)
+	}
+
+	keySet := jsonWebKeySet{}
+	if err := m.getJSON(provider.JWKSURI, &keySet); err != nil {
+		return nil, errors.Wrap(err, "problem fetching OIDC signing keys")
+	}
+	m.keys = keySet.publicKeys()
+	m.keysFetchedAt = m.now()
+
+	if key, ok := m.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, errors.Errorf("unknown signing key '%s'", kid)
+}
+
+// lookupKey finds a key by id. Tokens without a key id may only be used
+// with providers that have a single key.
+func (m *OIDCUserManager) lookupKey(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(m.keys) == 1 {
+		for _, key := range m.keys {
+			return key, true
+		}
+	}
+	key, ok := m.keys[kid]
+	return key, ok
+}
+
+// exchangeCode exchanges an authorization code for an ID token at the
+// provider's token endpoint.
+func (m *OIDCUserManager) exchangeCode(code string) (string, error) {
+	provider, err := m.discover()
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", m.callbackURL)
+	req, err := http.NewRequest(http.MethodPost, provider.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(m.conf.ClientId), url.QueryEscape(m.conf.ClientSecret))
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, "problem requesting ID token")
+	}
+	defer resp.Body.Close()
+
+	tokens := oidcTokenResponse{}
+	if err = json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return "", errors.Wrapf(err, "problem reading token response with status %d", resp.StatusCode)
+	}
+	if tokens.Error != "" {
+		return "", errors.Errorf("token request failed: %s: %s", tokens.Error, tokens.ErrorDescription)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("token request failed with status %d", resp.StatusCode)
+	}
+	if tokens.IdToken == "" {
+		return "", errors.New("token response has no ID token")
+	}
+	return tokens.IdToken, nil
+}
+
+func (m *OIDCUserManager) getJSON(url string, out interface{}) error {
+	resp, err := m.client.Get(url)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("%s responded with status %d", url, resp.StatusCode)
+	}
+	return errors.Wrapf(json.Unmarshal(body, out), "problem parsing response from %s", url)
+}
+
+// newState returns a state string holding the time and the page to
+// return to after login, signed with the manager's salt and the value
+// stored in the browser's state cookie.
+func (m *OIDCUserManager) newState(redirect, binding string) string {
+	payload := strconv.FormatInt(m.now().Unix(), 10) + "." + base64.RawURLEncoding.EncodeToString([]byte(redirect))
+	return payload + "." + m.sign("state:"+payload+":"+binding)
+}
+
+// checkState verifies a state string against the browser's state cookie
+// and returns the page to return to.
+func (m *OIDCUserManager) checkState(state, binding string) (string, error) {
+	if binding == "" {
+		return "", errors.New("browser has no state cookie")
+	}
+	parts := strings.Split(state, ".")
+	if len(parts) != 3 {
+		return "", errors.New("malformed state")
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(m.sign("state:"+payload+":"+binding))) {
+		return "", errors.New("state signature does not match")
+	}
+
+	issued, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return "", errors.Wrap(err, "malformed state")
+	}
+	if m.now().Sub(time.Unix(issued, 0)) > oidcStateTTL {
+		return "", errors.New("state has expired")
+	}
+
+	redirect, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", errors.Wrap(err, "malformed state")
+	}
+	if !isLocalRedirect(string(redirect)) {
+		return "/", nil
+	}
+	return string(redirect), nil
+}
+
+// isLocalRedirect returns true if the redirect is a path on this site.
+// Browsers treat backslashes like slashes and drop tabs and newlines, so
+// redirects with those are refused rather than risk them leaving the site.
+func isLocalRedirect(redirect string) bool {
+	if !strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") {
+		return false
+	}
+	for _, c := range redirect {
+		if c == '\\' || c < ' ' || c == 0x7f {
+			return false
+		}
+	}
+	parsed, err := url.Parse(redirect)
+	if err != nil {
+		return false
+	}
+	return parsed.Scheme == "" && parsed.Host == "" && parsed.User == nil
+}
+
+func (m *OIDCUserManager) nonce(state string) string {
+	return m.sign("nonce:" + state)
+}
+
+func (m *OIDCUserManager) sign(data string) string {
+	mac := hmac.New(sha256.New, []byte(m.Salt))
+	_, _ = mac.Write([]byte(data))
+	return hex.EncodeToString(mac.Sum(nil))
+}
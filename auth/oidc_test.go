@@ -0,0 +1,406 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeIdentityProvider is an OpenID Connect provider that issues ID
+// tokens for whatever claims the test sets.
+type fakeIdentityProvider struct {
+	server *httptest.Server
+	rsaKey *rsa.PrivateKey
+	ecKey  *ecdsa.PrivateKey
+
+	mu        sync.Mutex
+	codes     map[string]string
+	jwksCalls int
+}
+
+func newFakeIdentityProvider() (*fakeIdentityProvider, error) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	idp := &fakeIdentityProvider{rsaKey: rsaKey, ecKey: ecKey, codes: map[string]string{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", idp.discovery)
+	mux.HandleFunc("/keys", idp.jwks)
+	mux.HandleFunc("/token", idp.token)
+	idp.server = httptest.NewServer(mux)
+	return idp, nil
+}
+
+func (idp *fakeIdentityProvider) discovery(w http.ResponseWriter, r *http.Request) {
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"issuer":                 idp.server.URL,
+		"authorization_endpoint": idp.server.URL + "/authorize",
+		"token_endpoint":         idp.server.URL + "/token",
+		"jwks_uri":               idp.server.URL + "/keys",
+	})
+}
+
+func (idp *fakeIdentityProvider) jwks(w http.ResponseWriter, r *http.Request) {
+	idp.mu.Lock()
+	idp.jwksCalls++
+	idp.mu.Unlock()
+
+	enc := base64.RawURLEncoding.EncodeToString
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA", "kid": "rsa", "use": "sig",
+				"n": enc(idp.rsaKey.N.Bytes()),
+				"e": enc(big.NewInt(int64(idp.rsaKey.E)).Bytes()),
+			},
+			{
+				"kty": "EC", "kid": "ec", "crv": "P-256",
+				"x": enc(idp.ecKey.X.Bytes()),
+				"y": enc(idp.ecKey.Y.Bytes()),
+			},
+		},
+	})
+}
+
+// token exchanges the codes handed out by issueCode, checking the
+// client's credentials.
+func (idp *fakeIdentityProvider) token(w http.ResponseWriter, r *http.Request) {
+	id, secret, ok := r.BasicAuth()
+	if !ok || id != "evergreen" || secret != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error": "invalid_client"}`))
+		return
+	}
+	idp.mu.Lock()
+	token, ok := idp.codes[r.FormValue("code")]
+	idp.mu.Unlock()
+	if !ok || r.FormValue("grant_type") != "authorization_code" {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error": "invalid_grant"}`))
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]string{"id_token": token, "token_type": "Bearer"})
+}
+
+func (idp *fakeIdentityProvider) issueCode(code, token string) {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	idp.codes[code] = token
+}
+
+// sign returns a token with the claims, signed by the key with the id.
+func (idp *fakeIdentityProvider) sign(kid string, claims map[string]interface{}) string {
+	alg := "RS256"
+	if kid == "ec" {
+		alg = "ES256"
+	}
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	if kid == "ec" {
+		r, s, _ := ecdsa.Sign(rand.Reader, idp.ecKey, digest[:])
+		signature = make([]byte, 64)
+		rBytes, sBytes := r.Bytes(), s.Bytes()
+		copy(signature[32-len(rBytes):32], rBytes)
+		copy(signature[64-len(sBytes):], sBytes)
+	} else {
+		signature, _ = rsa.SignPKCS1v15(rand.Reader, idp.rsaKey, crypto.SHA256, digest[:])
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+type OIDCSuite struct {
+	idp     *fakeIdentityProvider
+	manager *OIDCUserManager
+	synced  []*oidcUser
+	suite.Suite
+}
+
+func TestOIDCSuite(t *testing.T) {
+	suite.Run(t, new(OIDCSuite))
+}
+
+func (s *OIDCSuite) SetupSuite() {
+	var err error
+	s.idp, err = newFakeIdentityProvider()
+	s.Require().NoError(err)
+}
+
+func (s *OIDCSuite) TearDownSuite() {
+	s.idp.server.Close()
+}
+
+func (s *OIDCSuite) SetupTest() {
+	var err error
+	s.manager, err = NewOIDCUserManager(&evergreen.OIDCAuthConfig{
+		Issuer:             s.idp.server.URL,
+		ClientId:           "evergreen",
+		ClientSecret:       "secret",
+		SuperUserGroups:    []string{"evg-admins"},
+		ProjectAdminGroups: map[string][]string{"mci": {"mci-team", "evg-admins"}, "other": {"other-team"}},
+	})
+	s.Require().NoError(err)
+	s.synced = nil
+	s.manager.syncUser = func(u *oidcUser) error {
+		s.synced = append(s.synced, u)
+		return nil
+	}
+}
+
+func (s *OIDCSuite) claims() map[string]interface{} {
+	return map[string]interface{}{
+		"iss":                s.idp.server.URL,
+		"aud":                "evergreen",
+		"sub":                "1234",
+		"exp":                time.Now().Add(time.Hour).Unix(),
+		"preferred_username": "alice",
+		"name":               "Alice Smith",
+		"email":              "alice@example.com",
+		"groups":             []string{"mci-team"},
+	}
+}
+
+func (s *OIDCSuite) TestNewOIDCUserManagerValidatesConfig() {
+	_, err := NewOIDCUserManager(&evergreen.OIDCAuthConfig{ClientId: "id", ClientSecret: "secret"})
+	s.Error(err)
+	_, err = NewOIDCUserManager(&evergreen.OIDCAuthConfig{Issuer: "https://idp", ClientSecret: "secret"})
+	s.Error(err)
+	_, err = NewOIDCUserManager(&evergreen.OIDCAuthConfig{Issuer: "https://idp", ClientId: "id"})
+	s.Error(err)
+
+	manager, err := LoadUserManager(evergreen.AuthConfig{OIDC: &evergreen.OIDCAuthConfig{
+		Issuer: "https://idp", ClientId: "id", ClientSecret: "secret"}})
+	s.NoError(err)
+	s.IsType(&OIDCUserManager{}, manager)
+	s.True(manager.IsRedirect())
+
+	_, err = LoadUserManager(evergreen.AuthConfig{
+		OIDC:  &evergreen.OIDCAuthConfig{Issuer: "https://idp", ClientId: "id", ClientSecret: "secret"},
+		Naive: &evergreen.NaiveAuthConfig{},
+	})
+	s.Error(err)
+}
+
+func (s *OIDCSuite) TestGetUserByToken() {
+	for _, kid := range []string{"rsa", "ec"} {
+		user, err := s.manager.GetUserByToken(s.idp.sign(kid, s.claims()))
+		s.Require().NoError(err)
+		s.Equal("alice", user.Username())
+		s.Equal("Alice Smith", user.DisplayName())
+		s.Equal("alice@example.com", user.Email())
+		s.Equal([]string{"mci-team"}, user.(*oidcUser).groups)
+	}
+}
+
+func (s *OIDCSuite) TestGetUserByTokenWithCustomClaims() {
+	s.manager.conf.UsernameClaim = "email"
+	s.manager.conf.GroupsClaim = "roles"
+	claims := s.claims()
+	claims["roles"] = "evg-admins"
+	delete(claims, "name")
+
+	user, err := s.manager.GetUserByToken(s.idp.sign("rsa", claims))
+	s.Require().NoError(err)
+	s.Equal("alice@example.com", user.Username())
+	s.Equal("alice@example.com", user.DisplayName())
+	s.Equal([]string{"evg-admins"}, user.(*oidcUser).groups)
+}
+
+func (s *OIDCSuite) TestGetUserByTokenWithGithubLogin() {
+	claims := s.claims()
+	claims["github"] = "alice-gh"
+
+	// the login is only trusted from a configured claim
+	user, err := s.manager.GetUserByToken(s.idp.sign("rsa", claims))
+	s.Require().NoError(err)
+	s.Empty(user.(GithubUser).GetGithubLogin())
+
+	s.manager.conf.GithubLoginClaim = "github"
+	user, err = s.manager.GetUserByToken(s.idp.sign("rsa", claims))
+	s.Require().NoError(err)
+	s.Equal("alice-gh", user.(GithubUser).GetGithubLogin())
+}
+
+func (s *OIDCSuite) TestGetUserByTokenRejectsInvalidTokens() {
+	for name, mutate := range map[string]func(map[string]interface{}){
+		"expired":        func(c map[string]interface{}) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+		"no expiry":      func(c map[string]interface{}) { delete(c, "exp") },
+		"wrong issuer":   func(c map[string]interface{}) { c["iss"] = "https://elsewhere" },
+		"wrong audience": func(c map[string]interface{}) { c["aud"] = []string{"someone-else"} },
+		"no username":    func(c map[string]interface{}) { delete(c, "preferred_username") },
+	} {
+		claims := s.claims()
+		mutate(claims)
+		_, err := s.manager.GetUserByToken(s.idp.sign("rsa", claims))
+		s.Error(err, name)
+	}
+
+	// a list audience including the client is fine
+	claims := s.claims()
+	claims["aud"] = []string{"someone-else", "evergreen"}
+	_, err := s.manager.GetUserByToken(s.idp.sign("rsa", claims))
+	s.NoError(err)
+
+	token := s.idp.sign("rsa", s.claims())
+	parts := strings.Split(token, ".")
+	forged, _ := json.Marshal(map[string]interface{}{"iss": s.idp.server.URL, "aud": "evergreen",
+		"exp": time.Now().Add(time.Hour).Unix(), "preferred_username": "mallory"})
+	_, err = s.manager.GetUserByToken(parts[0] + "." + base64.RawURLEncoding.EncodeToString(forged) + "." + parts[2])
+	s.Error(err)
+
+	_, err = s.manager.GetUserByToken("not-a-token")
+	s.Error(err)
+}
+
+func (s *OIDCSuite) TestUnknownKeysAreRefetchedAtMostOncePerInterval() {
+	_, err := s.manager.GetUserByToken(s.idp.sign("rsa", s.claims()))
+	s.Require().NoError(err)
+	calls := s.idp.jwksCalls
+
+	_, err = s.manager.GetUserByToken(s.idp.sign("rotated", s.claims()))
+	s.Error(err)
+	_, err = s.manager.GetUserByToken(s.idp.sign("rotated", s.claims()))
+	s.Error(err)
+	s.Equal(calls, s.idp.jwksCalls)
+
+	s.manager.now = func() time.Time { return time.Now().Add(2 * oidcKeyRefreshInterval) }
+	_, err = s.manager.GetUserByToken(s.idp.sign("rotated", s.claims()))
+	s.Error(err)
+	s.Equal(calls+1, s.idp.jwksCalls)
+}
+
+func (s *OIDCSuite) TestLoginFlow() {
+	login := s.manager.GetLoginHandler("http://evergreen.example.com")
+	callback := s.manager.GetLoginCallbackHandler()
+
+	w := httptest.NewRecorder()
+	login(w, httptest.NewRequest("GET", "/login/redirect?redirect=%2Fwaterfall%2Fmci", nil))
+	s.Require().Equal(http.StatusFound, w.Code)
+	cookies := w.Result().Cookies()
+	s.Require().Len(cookies, 1)
+	s.Equal(oidcStateCookie, cookies[0].Name)
+	stateCookie := cookies[0]
+	callbackRequest := func(query string) *http.Request {
+		r := httptest.NewRequest("GET", "/login/redirect/callback?"+query, nil)
+		r.AddCookie(stateCookie)
+		return r
+	}
+	location, err := url.Parse(w.Header().Get("Location"))
+	s.Require().NoError(err)
+	s.Equal(s.idp.server.URL+"/authorize", location.Scheme+"://"+location.Host+location.Path)
+	params := location.Query()
+	s.Equal("code", params.Get("response_type"))
+	s.Equal("evergreen", params.Get("client_id"))
+	s.Equal("http://evergreen.example.com/login/redirect/callback", params.Get("redirect_uri"))
+	s.Equal("openid profile email", params.Get("scope"))
+	state := params.Get("state")
+	nonce := params.Get("nonce")
+	s.NotEmpty(state)
+	s.NotEmpty(nonce)
+
+	claims := s.claims()
+	claims["nonce"] = nonce
+	idToken := s.idp.sign("rsa", claims)
+	s.idp.issueCode("good-code", idToken)
+
+	w = httptest.NewRecorder()
+	callback(w, callbackRequest("code=good-code&state="+url.QueryEscape(state)))
+	s.Require().Equal(http.StatusFound, w.Code)
+	s.Equal("/waterfall/mci", w.Header().Get("Location"))
+	s.Contains(w.Header()["Set-Cookie"], evergreen.AuthTokenCookie+"="+idToken+"; Path=/; HttpOnly")
+	s.Require().Len(s.synced, 1)
+	s.Equal("alice", s.synced[0].Username())
+
+	// a state started in another browser is sent back to the login page
+	w = httptest.NewRecorder()
+	callback(w, httptest.NewRequest("GET", "/login/redirect/callback?code=good-code&state="+url.QueryEscape(state), nil))
+	s.Equal(http.StatusFound, w.Code)
+	s.Equal("/login", w.Header().Get("Location"))
+
+	// a tampered state is sent back to the login page
+	w = httptest.NewRecorder()
+	callback(w, callbackRequest("code=good-code&state="+url.QueryEscape(state+"0")))
+	s.Equal(http.StatusFound, w.Code)
+	s.Equal("/login", w.Header().Get("Location"))
+
+	// so is an expired one
+	s.manager.now = func() time.Time { return time.Now().Add(oidcStateTTL + time.Minute) }
+	w = httptest.NewRecorder()
+	callback(w, callbackRequest("code=good-code&state="+url.QueryEscape(state)))
+	s.Equal("/login", w.Header().Get("Location"))
+	s.manager.now = time.Now
+
+	// a token issued for another login's nonce is rejected
+	claims["nonce"] = "other"
+	s.idp.issueCode("replayed-code", s.idp.sign("rsa", claims))
+	w = httptest.NewRecorder()
+	callback(w, callbackRequest("code=replayed-code&state="+url.QueryEscape(state)))
+	s.Equal(http.StatusUnauthorized, w.Code)
+
+	// as is an unknown code
+	w = httptest.NewRecorder()
+	callback(w, callbackRequest("code=bad-code&state="+url.QueryEscape(state)))
+	s.Equal(http.StatusBadGateway, w.Code)
+	s.Len(s.synced, 1)
+}
+
+func (s *OIDCSuite) TestStateOnlyRedirectsWithinSite() {
+	for redirect, expected := range map[string]string{
+		"":                   "/",
+		"/task/abc":          "/task/abc",
+		"https://evil.com/":  "/",
+		"//evil.com/path":    "/",
+		"javascript:alert()": "/",
+		"/\\evil.com":        "/",
+		"/\t/evil.com":       "/",
+		"/%5Cevil.com":       "/%5Cevil.com",
+	} {
+		got, err := s.manager.checkState(s.manager.newState(redirect, "binding"), "binding")
+		s.NoError(err)
+		s.Equal(expected, got, redirect)
+	}
+
+	_, err := s.manager.checkState(s.manager.newState("/task/abc", "binding"), "other")
+	s.Error(err)
+	_, err = s.manager.checkState(s.manager.newState("/task/abc", ""), "")
+	s.Error(err)
+}
+
+func (s *OIDCSuite) TestGrants() {
+	superUser, projects := s.manager.grants([]string{"mci-team"})
+	s.False(superUser)
+	s.Equal([]string{"mci"}, projects)
+
+	superUser, projects = s.manager.grants([]string{"evg-admins", "other-team"})
+	s.True(superUser)
+	s.Equal([]string{"mci", "other"}, projects)
+
+	superUser, projects = s.manager.grants(nil)
+	s.False(superUser)
+	s.Empty(projects)
+}
@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/role"
+	"github.com/pkg/errors"
+)
+
+// RoleStore looks up the roles and role assignments that permissions are
+// checked against.
+type RoleStore interface {
+	FindRoles() ([]role.Role, error)
+	FindAssignmentsForUser(user string, groups []string) ([]role.Assignment, error)
+}
+
+// DBRoleStore is a RoleStore backed by the database.
+type DBRoleStore struct{}
+
+func (DBRoleStore) FindRoles() ([]role.Role, error) { return role.FindAll() }
+func (DBRoleStore) FindAssignmentsForUser(user string, groups []string) ([]role.Assignment, error) {
+	return role.FindAssignmentsForUser(user, groups)
+}
+
+// CachedRoleStore is a RoleStore that loads the roles, and the assignments
+// of each user, from another store only once. It's meant to live for a
+// single request, which may check many permissions.
+type CachedRoleStore struct {
+	Store RoleStore
+
+	mu          sync.Mutex
+	roles       []role.Role
+	rolesLoaded bool
+	assignments map[string][]role.Assignment
+}
+
+// NewCachedRoleStore returns a CachedRoleStore in front of the store.
+func NewCachedRoleStore(store RoleStore) *CachedRoleStore {
+	return &CachedRoleStore{
+		Store:       store,
+		assignments: map[string][]role.Assignment{},
+	}
+}
+
+func (s *CachedRoleStore) FindRoles() ([]role.Role, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.rolesLoaded {
+		roles, err := s.Store.FindRoles()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		s.roles = roles
+		s.rolesLoaded = true
+	}
+	return s.roles, nil
+}
+
+func (s *CachedRoleStore) FindAssignmentsForUser(user string, groups []string) ([]role.Assignment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := user + "\x00" + strings.Join(groups, "\x00")
+	if assignments, ok := s.assignments[key]; ok {
+		return assignments, nil
+	}
+	assignments, err := s.Store.FindAssignmentsForUser(user, groups)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	s.assignments[key] = assignments
+	return assignments, nil
+}
+
+// Authorizer decides whether users may take actions, based on the roles
+// assigned to them and their groups.
+type Authorizer struct {
+	SuperUsers []string
+	Config     evergreen.RBACConfig
+	Store      RoleStore
+}
+
+// HasPermission returns true if the user has the permission on the
+// project, which is nil for system permissions. Super users have every
+// permission, and project admins every permission on their projects.
+// While role-based access control is disabled, every logged in user has
+// the permissions they had before roles existed.
+func (a *Authorizer) HasPermission(u User, p role.Permission, project *model.ProjectRef) (bool, error) {
+	if u == nil || u.IsNil() {
+		return false, nil
+	}
+	if IsSuperUser(a.SuperUsers, u) {
+		return true, nil
+	}
+
+	projectId := ""
+	if project != nil && role.IsProjectPermission(p) {
+		projectId = project.Identifier
+		if IsProjectAdmin(project.Identifier, project.Admins, u) {
+			return true, nil
+		}
+	}
+
+	if !a.Config.Enabled {
+		return role.IsLegacyPermission(p), nil
+	}
+	if a.Store == nil {
+		return false, errors.New("no role store configured")
+	}
+
+	roles, err := a.Store.FindRoles()
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	var groups []string
+	if g, ok := u.(GroupUser); ok {
+		groups = g.GetGroups()
+	}
+	assignments, err := a.Store.FindAssignmentsForUser(u.Username(), groups)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	return role.Allows(roles, assignments, a.Config.DefaultRoles, p, projectId), nil
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/role"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockRoleStore struct {
+	roles       []role.Role
+	assignments []role.Assignment
+}
+
+func (s *mockRoleStore) FindRoles() ([]role.Role, error) { return s.roles, nil }
+func (s *mockRoleStore) FindAssignmentsForUser(user string, groups []string) ([]role.Assignment, error) {
+	out := []role.Assignment{}
+	for _, a := range s.assignments {
+		if a.User == user {
+			out = append(out, a)
+			continue
+		}
+		for _, g := range groups {
+			if a.Group == g {
+				out = append(out, a)
+			}
+		}
+	}
+	return out, nil
+}
+
+func TestAuthorizer(t *testing.T) {
+	assert := assert.New(t)
+	store := &mockRoleStore{
+		roles: []role.Role{
+			{Id: role.TaskRestarter, Permissions: []role.Permission{role.ProjectView, role.TaskRestart}},
+			{Id: role.HostAdminId, Permissions: []role.Permission{role.HostAdmin}},
+			{Id: role.ProjectViewer, Permissions: []role.Permission{role.ProjectView}},
+		},
+		assignments: []role.Assignment{
+			{Role: role.TaskRestarter, User: "restarter", Project: "mci"},
+			{Role: role.HostAdminId, Group: "ops"},
+		},
+	}
+	a := &Authorizer{SuperUsers: []string{"super"}, Store: store}
+	mci := &model.ProjectRef{Identifier: "mci", Admins: []string{"admin"}}
+	other := &model.ProjectRef{Identifier: "other"}
+
+	super := &simpleUser{UserId: "super"}
+	admin := &simpleUser{UserId: "admin"}
+	restarter := &simpleUser{UserId: "restarter"}
+	operator := &groupUser{simpleUser: simpleUser{UserId: "operator"}, groups: []string{"ops"}}
+
+	check := func(u User, p role.Permission, project *model.ProjectRef) bool {
+		ok, err := a.HasPermission(u, p, project)
+		assert.NoError(err)
+		return ok
+	}
+
+	// while disabled, users keep their legacy permissions
+	assert.True(check(restarter, role.TaskRestart, other))
+	assert.True(check(restarter, role.HostAdmin, nil))
+	assert.False(check(restarter, role.DistroAdmin, nil))
+	assert.False(check(restarter, role.ProjectSettings, mci))
+	assert.True(check(admin, role.ProjectSettings, mci))
+	assert.False(check(nil, role.ProjectView, mci))
+
+	a.Config = evergreen.RBACConfig{Enabled: true}
+	assert.True(check(super, role.DistroAdmin, nil))
+	assert.True(check(super, role.TaskRestart, other))
+	assert.True(check(admin, role.TaskRestart, mci))
+	assert.False(check(admin, role.TaskRestart, other))
+	assert.True(check(restarter, role.TaskRestart, mci))
+	assert.False(check(restarter, role.TaskRestart, other))
+	assert.False(check(restarter, role.HostAdmin, nil))
+	assert.True(check(operator, role.HostAdmin, nil))
+	assert.False(check(operator, role.ProjectView, mci))
+
+	a.Config.DefaultRoles = []string{role.ProjectViewer}
+	assert.True(check(operator, role.ProjectView, mci))
+	assert.False(check(nil, role.ProjectView, mci))
+}
+
+type countingRoleStore struct {
+	mockRoleStore
+	roleLoads       int
+	assignmentLoads int
+}
+
+func (s *countingRoleStore) FindRoles() ([]role.Role, error) {
+	s.roleLoads++
+	return s.mockRoleStore.FindRoles()
+}
+
+func (s *countingRoleStore) FindAssignmentsForUser(user string, groups []string) ([]role.Assignment, error) {
+	s.assignmentLoads++
+	return s.mockRoleStore.FindAssignmentsForUser(user, groups)
+}
+
+func TestCachedRoleStore(t *testing.T) {
+	assert := assert.New(t)
+	store := &countingRoleStore{mockRoleStore: mockRoleStore{
+		roles:       []role.Role{{Id: role.ProjectViewer, Permissions: []role.Permission{role.ProjectView}}},
+		assignments: []role.Assignment{{Role: role.ProjectViewer, User: "viewer", Project: "mci"}},
+	}}
+	a := &Authorizer{SuperUsers: []string{"super"}, Config: evergreen.RBACConfig{Enabled: true}, Store: NewCachedRoleStore(store)}
+	viewer := &simpleUser{UserId: "viewer"}
+	other := &simpleUser{UserId: "other"}
+
+	for _, project := range []string{"mci", "other", "third"} {
+		ok, err := a.HasPermission(viewer, role.ProjectView, &model.ProjectRef{Identifier: project})
+		assert.NoError(err)
+		assert.Equal(project == "mci", ok)
+	}
+	ok, err := a.HasPermission(other, role.ProjectView, &model.ProjectRef{Identifier: "mci"})
+	assert.NoError(err)
+	assert.False(ok)
+
+	assert.Equal(1, store.roleLoads)
+	assert.Equal(2, store.assignmentLoads)
+}
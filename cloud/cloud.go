@@ -95,6 +95,8 @@ func GetCloudManager(providerName string, settings *evergreen.Settings) (CloudMa
 		provider = &openStackManager{}
 	case evergreen.ProviderNameGce:
 		provider = &gceManager{}
+	case evergreen.ProviderNameKubernetes:
+		provider = &kubernetesManager{}
 	case evergreen.ProviderNameVsphere:
 		provider = &vsphereManager{}
 	default:
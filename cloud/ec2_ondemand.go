@@ -22,6 +22,7 @@ import (
 // ec2OnDemandManager implements the CloudManager interface for Amazon EC2
 type ec2OnDemandManager struct {
 	awsCredentials *aws.Auth
+	settings       *evergreen.Settings
 }
 
 //Valid values for EC2 instance states:
@@ -83,6 +84,7 @@ func (cloudManager *ec2OnDemandManager) Configure(settings *evergreen.Settings)
 		AccessKey: settings.Providers.AWS.Id,
 		SecretKey: settings.Providers.AWS.Secret,
 	}
+	cloudManager.settings = settings
 	return nil
 }
 
@@ -166,6 +168,11 @@ func (cloudManager *ec2OnDemandManager) SpawnHost(h *host.Host) (*host.Host, err
 		options.SubnetId = ec2Settings.SubnetId
 	}
 
+	// hosts that bootstrap themselves start from their user data
+	if h.BootstrapsWithUserData() {
+		options.UserData = []byte(hostutil.BootstrapUserData(cloudManager.settings, h))
+	}
+
 	// start the instance - starting an instance does not mean you can connect
 	// to it immediately you have to use GetInstanceStatus to ensure that
 	// it's actually running
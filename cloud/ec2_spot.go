@@ -37,6 +37,7 @@ const (
 // ec2SpotManager implements the CloudManager interface for Amazon EC2 Spot
 type ec2SpotManager struct {
 	awsCredentials *aws.Auth
+	settings       *evergreen.Settings
 }
 
 type EC2SpotSettings struct {
@@ -89,6 +90,7 @@ func (cloudManager *ec2SpotManager) Configure(settings *evergreen.Settings) erro
 		AccessKey: settings.Providers.AWS.Id,
 		SecretKey: settings.Providers.AWS.Secret,
 	}
+	cloudManager.settings = settings
 	return nil
 }
 
@@ -294,6 +296,11 @@ func (cloudManager *ec2SpotManager) SpawnHost(h *host.Host) (*host.Host, error)
 		spotRequest.SubnetId = ec2Settings.SubnetId
 	}
 
+	// hosts that bootstrap themselves start from their user data
+	if h.BootstrapsWithUserData() {
+		spotRequest.UserData = []byte(hostutil.BootstrapUserData(cloudManager.settings, h))
+	}
+
 	spotResp, err := ec2Handle.RequestSpotInstances(spotRequest)
 	if err != nil {
 		//Remove the intent host if the API call failed
@@ -0,0 +1,274 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/hostutil"
+	"github.com/evergreen-ci/evergreen/model/distro"
+	"github.com/evergreen-ci/evergreen/model/event"
+	"github.com/evergreen-ci/evergreen/model/host"
+	"github.com/mitchellh/mapstructure"
+	"github.com/mongodb/grip"
+	"github.com/mongodb/grip/message"
+	"github.com/pkg/errors"
+)
+
+const defaultPodSSHPort = 22
+
+// quantityRegex matches resource quantities as accepted by the
+// orchestrator, e.g. "500m", "2", "4Gi" or "512M".
+var quantityRegex = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?(m|k|M|G|T|Ki|Mi|Gi|Ti)?$`)
+
+// kubernetesManager implements the CloudManager interface for hosts
+// that run as pods in a Kubernetes-compatible container orchestrator.
+type kubernetesManager struct {
+	client kubernetesClient
+}
+
+// kubernetesSettings specifies the settings used to configure a pod.
+type kubernetesSettings struct {
+	// Namespace is the namespace in which pods are created.
+	Namespace string `mapstructure:"namespace" json:"namespace" bson:"namespace"`
+	// Image is the container image that the pod runs. The image must
+	// run an SSH daemon in the foreground.
+	Image string `mapstructure:"image" json:"image" bson:"image"`
+	// CPU and Memory are the resources requested for (and limited to)
+	// the pod's container, e.g. "2" and "4Gi".
+	CPU    string `mapstructure:"cpu" json:"cpu" bson:"cpu"`
+	Memory string `mapstructure:"memory" json:"memory" bson:"memory"`
+	// ImagePullSecret is the name of a secret in the namespace used to
+	// pull the image from a private registry.
+	ImagePullSecret string `mapstructure:"image_pull_secret" json:"image_pull_secret" bson:"image_pull_secret"`
+	// NodeSelector restricts the nodes the pod may be scheduled on.
+	NodeSelector map[string]string `mapstructure:"node_selector" json:"node_selector" bson:"node_selector"`
+	// SSHPort is the port on which the container's SSH daemon listens.
+	SSHPort int `mapstructure:"ssh_port" json:"ssh_port" bson:"ssh_port"`
+}
+
+// Validate checks that the settings from the config file are sane.
+func (s *kubernetesSettings) Validate() error {
+	if s.Namespace == "" {
+		return errors.New("Namespace must not be blank")
+	}
+
+	if s.Image == "" {
+		return errors.New("Image must not be blank")
+	}
+
+	if s.CPU != "" && !quantityRegex.MatchString(s.CPU) {
+		return errors.Errorf("CPU '%s' is not a valid resource quantity", s.CPU)
+	}
+
+	if s.Memory != "" && !quantityRegex.MatchString(s.Memory) {
+		return errors.Errorf("Memory '%s' is not a valid resource quantity", s.Memory)
+	}
+
+	if s.SSHPort < 0 || s.SSHPort > 65535 {
+		return errors.Errorf("SSH port %d is not a valid port", s.SSHPort)
+	}
+
+	return nil
+}
+
+func (s *kubernetesSettings) getSSHPort() int {
+	if s.SSHPort == 0 {
+		return defaultPodSSHPort
+	}
+	return s.SSHPort
+}
+
+func getKubernetesSettings(d *distro.Distro) (*kubernetesSettings, error) {
+	settings := &kubernetesSettings{}
+	if err := mapstructure.Decode(d.ProviderSettings, settings); err != nil {
+		return nil, errors.Wrapf(err, "Error decoding params for distro '%s'", d.Id)
+	}
+
+	if err := settings.Validate(); err != nil {
+		return nil, errors.Wrapf(err, "Invalid Kubernetes settings in distro '%s'", d.Id)
+	}
+
+	return settings, nil
+}
+
+// GetSettings returns an empty ProviderSettings struct.
+func (*kubernetesManager) GetSettings() ProviderSettings {
+	return &kubernetesSettings{}
+}
+
+// GetInstanceName returns a name to be used for an instance. Pod
+// names must be valid DNS labels, so the name is lowercase.
+func (*kubernetesManager) GetInstanceName(_ *distro.Distro) string {
+	return fmt.Sprintf("pod-%d", rand.New(rand.NewSource(time.Now().UnixNano())).Int())
+}
+
+// SpawnHost creates a new pod for the host. The pod may not have been
+// scheduled or have an IP address when SpawnHost returns; the host's
+// DNS name is resolved with GetDNSName once the pod is running.
+func (m *kubernetesManager) SpawnHost(h *host.Host) (*host.Host, error) {
+	if h.Distro.Provider != evergreen.ProviderNameKubernetes {
+		return nil, errors.Errorf("Can't spawn instance of %s for distro %s: provider is %s",
+			evergreen.ProviderNameKubernetes, h.Distro.Id, h.Distro.Provider)
+	}
+
+	settings, err := getKubernetesSettings(&h.Distro)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	grip.Info(message.Fields{
+		"message":   "decoded Kubernetes pod settings",
+		"pod":       h.Id,
+		"namespace": settings.Namespace,
+		"image":     settings.Image,
+		"cpu":       settings.CPU,
+		"memory":    settings.Memory,
+	})
+
+	pod, err := m.client.CreatePod(h, settings)
+	if err != nil {
+		err = errors.Wrapf(err, "Failed to create pod for host '%s'", h.Id)
+		grip.Error(err)
+		return nil, err
+	}
+
+	grip.Info(message.Fields{
+		"message":   "created Kubernetes pod",
+		"pod":       h.Id,
+		"namespace": pod.Metadata.Namespace,
+		"phase":     pod.Status.Phase,
+	})
+	event.LogHostStarted(h.Id)
+
+	return h, nil
+}
+
+// GetInstanceStatus returns a universal status code representing the
+// state of a pod.
+func (m *kubernetesManager) GetInstanceStatus(h *host.Host) (CloudStatus, error) {
+	pod, err := m.client.GetPod(h)
+	if err != nil {
+		if isPodNotFound(err) {
+			return StatusTerminated, nil
+		}
+		return StatusUnknown, errors.Wrapf(err, "Failed to get pod information for host '%v'", h.Id)
+	}
+
+	return podToEvgStatus(pod), nil
+}
+
+// GetDNSName returns the IP address of the pod, which is only known
+// once the pod has been scheduled.
+func (m *kubernetesManager) GetDNSName(h *host.Host) (string, error) {
+	pod, err := m.client.GetPod(h)
+	if err != nil {
+		return "", errors.Wrapf(err, "Failed to get pod information for host '%v'", h.Id)
+	}
+
+	if pod.Status.PodIP == "" {
+		return "", errors.Errorf("pod for host '%s' does not have an IP address yet", h.Id)
+	}
+
+	settings, err := getKubernetesSettings(&h.Distro)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	if settings.getSSHPort() != defaultPodSSHPort {
+		return fmt.Sprintf("%s:%d", pod.Status.PodIP, settings.getSSHPort()), nil
+	}
+
+	return pod.Status.PodIP, nil
+}
+
+// CanSpawn returns if a given cloud provider supports spawning a new
+// host dynamically. Always returns true for Kubernetes.
+func (m *kubernetesManager) CanSpawn() (bool, error) {
+	return true, nil
+}
+
+// TerminateInstance deletes a pod.
+func (m *kubernetesManager) TerminateInstance(h *host.Host) error {
+	if h.Status == evergreen.HostTerminated {
+		err := errors.Errorf("Can not terminate %s - already marked as terminated!", h.Id)
+		grip.Error(err)
+		return err
+	}
+
+	if err := m.client.DeletePod(h); err != nil && !isPodNotFound(err) {
+		return errors.Wrap(err, "API call to delete pod failed")
+	}
+
+	grip.Info(message.Fields{
+		"message": "terminated Kubernetes pod",
+		"pod":     h.Id,
+	})
+
+	// Set the host status as terminated and update its termination time
+	return h.Terminate()
+}
+
+// Configure populates a kubernetesManager by reading relevant settings
+// from the config object.
+func (m *kubernetesManager) Configure(s *evergreen.Settings) error {
+	config := s.Providers.Kubernetes
+
+	if m.client == nil {
+		m.client = &kubernetesClientImpl{}
+	}
+
+	if err := m.client.Init(&config); err != nil {
+		return errors.Wrap(err, "Failed to initialize client connection")
+	}
+
+	return nil
+}
+
+// IsSSHReachable checks if a pod appears to be reachable via SSH by
+// attempting to contact the host directly.
+func (m *kubernetesManager) IsSSHReachable(h *host.Host, keyPath string) (bool, error) {
+	sshOpts, err := m.GetSSHOptions(h, keyPath)
+	if err != nil {
+		return false, err
+	}
+	return hostutil.CheckSSHResponse(context.TODO(), h, sshOpts)
+}
+
+// IsUp checks the pod's phase and container readiness and returns
+// true if the host should be available to connect with SSH.
+func (m *kubernetesManager) IsUp(h *host.Host) (bool, error) {
+	cloudStatus, err := m.GetInstanceStatus(h)
+	if err != nil {
+		return false, err
+	}
+	return cloudStatus == StatusRunning, nil
+}
+
+// OnUp does nothing.
+func (m *kubernetesManager) OnUp(_ *host.Host) error {
+	return nil
+}
+
+// GetSSHOptions returns an array of default SSH options for connecting
+// to a pod.
+func (m *kubernetesManager) GetSSHOptions(h *host.Host, keyPath string) ([]string, error) {
+	if keyPath == "" {
+		return []string{}, errors.New("No key specified for Kubernetes host")
+	}
+
+	opts := []string{"-i", keyPath}
+	for _, opt := range h.Distro.SSHOptions {
+		opts = append(opts, "-o", opt)
+	}
+	return opts, nil
+}
+
+// TimeTilNextPayment returns the amount of time until the next payment
+// is due for the host. For Kubernetes this is not relevant.
+func (m *kubernetesManager) TimeTilNextPayment(_ *host.Host) time.Duration {
+	return time.Duration(0)
+}
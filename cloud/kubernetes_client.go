@@ -0,0 +1,167 @@
+package cloud
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model/host"
+	"github.com/pkg/errors"
+)
+
+const kubernetesClientTimeout = time.Minute
+
+// The kubernetesClient interface wraps interaction with the
+// orchestrator's pod API.
+type kubernetesClient interface {
+	Init(*evergreen.KubernetesConfig) error
+	CreatePod(*host.Host, *kubernetesSettings) (*kubernetesPod, error)
+	GetPod(*host.Host) (*kubernetesPod, error)
+	DeletePod(*host.Host) error
+}
+
+// podNotFoundError is returned by the client when the API server
+// does not know about a pod.
+type podNotFoundError struct {
+	name string
+}
+
+func (e *podNotFoundError) Error() string { return fmt.Sprintf("pod '%s' not found", e.name) }
+
+func isPodNotFound(err error) bool {
+	_, ok := errors.Cause(err).(*podNotFoundError)
+	return ok
+}
+
+type kubernetesClientImpl struct {
+	apiServer  string
+	token      string
+	httpClient *http.Client
+}
+
+// Init validates the connection settings for the API server. The client
+// has its own transport, since its TLS settings apply to the API server
+// alone.
+func (c *kubernetesClientImpl) Init(config *evergreen.KubernetesConfig) error {
+	if config.APIServer == "" {
+		return errors.New("Kubernetes API server must not be blank")
+	}
+
+	c.apiServer = strings.TrimRight(config.APIServer, "/")
+	c.token = config.Token
+	c.httpClient = &http.Client{
+		Timeout: kubernetesClientTimeout,
+		Transport: &http.Transport{
+			Proxy:               http.ProxyFromEnvironment,
+			TLSClientConfig:     &tls.Config{InsecureSkipVerify: config.InsecureSkipVerify},
+			TLSHandshakeTimeout: 10 * time.Second,
+			IdleConnTimeout:     time.Minute,
+		},
+	}
+
+	return nil
+}
+
+func (c *kubernetesClientImpl) podsURL(namespace string) string {
+	return fmt.Sprintf("%s/api/v1/namespaces/%s/pods", c.apiServer, namespace)
+}
+
+func (c *kubernetesClientImpl) do(method, url string, in, out interface{}) (int, error) {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return 0, errors.Wrap(err, "problem marshaling request body")
+		}
+	}
+
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, errors.Wrap(err, "problem building request")
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, errors.Wrapf(err, "problem making %s request to '%s'", method, url)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, errors.Wrap(err, "problem reading response body")
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		status := &kubernetesStatus{}
+		if err = json.Unmarshal(data, status); err == nil && status.Message != "" {
+			return resp.StatusCode, errors.Errorf("API server returned %d: %s", resp.StatusCode, status.Message)
+		}
+		return resp.StatusCode, errors.Errorf("API server returned %d", resp.StatusCode)
+	}
+
+	if out != nil {
+		if err = json.Unmarshal(data, out); err != nil {
+			return resp.StatusCode, errors.Wrap(err, "problem parsing response body")
+		}
+	}
+
+	return resp.StatusCode, nil
+}
+
+// CreatePod creates a pod running the distro's image with a single
+// container that exposes the SSH port.
+func (c *kubernetesClientImpl) CreatePod(h *host.Host, s *kubernetesSettings) (*kubernetesPod, error) {
+	out := &kubernetesPod{}
+	if _, err := c.do(http.MethodPost, c.podsURL(s.Namespace), makePodSpec(h, s), out); err != nil {
+		return nil, errors.Wrapf(err, "problem creating pod '%s'", h.Id)
+	}
+
+	return out, nil
+}
+
+// GetPod returns the current state of the host's pod.
+func (c *kubernetesClientImpl) GetPod(h *host.Host) (*kubernetesPod, error) {
+	settings, err := getKubernetesSettings(&h.Distro)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	out := &kubernetesPod{}
+	code, err := c.do(http.MethodGet, fmt.Sprintf("%s/%s", c.podsURL(settings.Namespace), h.Id), nil, out)
+	if code == http.StatusNotFound {
+		return nil, &podNotFoundError{name: h.Id}
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem getting pod '%s'", h.Id)
+	}
+
+	return out, nil
+}
+
+// DeletePod deletes the host's pod.
+func (c *kubernetesClientImpl) DeletePod(h *host.Host) error {
+	settings, err := getKubernetesSettings(&h.Distro)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	code, err := c.do(http.MethodDelete, fmt.Sprintf("%s/%s", c.podsURL(settings.Namespace), h.Id), nil, nil)
+	if code == http.StatusNotFound {
+		return &podNotFoundError{name: h.Id}
+	}
+
+	return errors.Wrapf(err, "problem deleting pod '%s'", h.Id)
+}
@@ -0,0 +1,148 @@
+package cloud
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// kubernetesAPIMock is an in-process stand-in for the orchestrator's
+// pod API, which stores pods in memory.
+type kubernetesAPIMock struct {
+	token string
+
+	// API call options
+	failCreate bool
+	failGet    bool
+	failDelete bool
+
+	// Other options
+	phase string
+	ready bool
+
+	pods   map[string]*kubernetesPod
+	nextIP int
+	mu     sync.Mutex
+}
+
+func newKubernetesAPIMock(token string) (*kubernetesAPIMock, *httptest.Server) {
+	api := &kubernetesAPIMock{
+		token: token,
+		phase: podPhaseRunning,
+		ready: true,
+		pods:  map[string]*kubernetesPod{},
+	}
+
+	return api, httptest.NewServer(api)
+}
+
+func (api *kubernetesAPIMock) writeStatus(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(&kubernetesStatus{Message: msg, Code: code})
+}
+
+func (api *kubernetesAPIMock) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer "+api.token {
+		api.writeStatus(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// paths are /api/v1/namespaces/{namespace}/pods[/{name}]
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 5 || parts[0] != "api" || parts[1] != "v1" || parts[2] != "namespaces" || parts[4] != "pods" {
+		api.writeStatus(w, http.StatusNotFound, "the server could not find the requested resource")
+		return
+	}
+	namespace := parts[3]
+
+	switch {
+	case len(parts) == 5 && r.Method == http.MethodPost:
+		if api.failCreate {
+			api.writeStatus(w, http.StatusInternalServerError, "failed to create pod")
+			return
+		}
+
+		pod := &kubernetesPod{}
+		if err := json.NewDecoder(r.Body).Decode(pod); err != nil {
+			api.writeStatus(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		key := namespace + "/" + pod.Metadata.Name
+		if _, ok := api.pods[key]; ok {
+			api.writeStatus(w, http.StatusConflict, fmt.Sprintf("pods \"%s\" already exists", pod.Metadata.Name))
+			return
+		}
+
+		api.nextIP++
+		pod.Metadata.Namespace = namespace
+		pod.Status = kubernetesPodStatus{
+			Phase: api.phase,
+			PodIP: fmt.Sprintf("10.0.0.%d", api.nextIP),
+			ContainerStatuses: []kubernetesContainerStatus{
+				{Name: podContainerName, Ready: api.ready},
+			},
+		}
+		api.pods[key] = pod
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(pod)
+	case len(parts) == 6 && r.Method == http.MethodGet:
+		if api.failGet {
+			api.writeStatus(w, http.StatusInternalServerError, "failed to get pod")
+			return
+		}
+
+		pod, ok := api.pods[namespace+"/"+parts[5]]
+		if !ok {
+			api.writeStatus(w, http.StatusNotFound, fmt.Sprintf("pods \"%s\" not found", parts[5]))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(pod)
+	case len(parts) == 6 && r.Method == http.MethodDelete:
+		if api.failDelete {
+			api.writeStatus(w, http.StatusInternalServerError, "failed to delete pod")
+			return
+		}
+
+		key := namespace + "/" + parts[5]
+		if _, ok := api.pods[key]; !ok {
+			api.writeStatus(w, http.StatusNotFound, fmt.Sprintf("pods \"%s\" not found", parts[5]))
+			return
+		}
+		delete(api.pods, key)
+
+		api.writeStatus(w, http.StatusOK, "")
+	default:
+		api.writeStatus(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (api *kubernetesAPIMock) getPod(namespace, name string) (*kubernetesPod, bool) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	pod, ok := api.pods[namespace+"/"+name]
+	return pod, ok
+}
+
+func (api *kubernetesAPIMock) setPhase(namespace, name, phase string, ready bool) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	if pod, ok := api.pods[namespace+"/"+name]; ok {
+		pod.Status.Phase = phase
+		for idx := range pod.Status.ContainerStatuses {
+			pod.Status.ContainerStatuses[idx].Ready = ready
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package cloud
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model/distro"
+	"github.com/evergreen-ci/evergreen/model/host"
+	"github.com/evergreen-ci/evergreen/testutil"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/stretchr/testify/suite"
+)
+
+type KubernetesSuite struct {
+	api      *kubernetesAPIMock
+	server   *httptest.Server
+	manager  *kubernetesManager
+	settings *evergreen.Settings
+	distro   *distro.Distro
+	hostOpts HostOptions
+	suite.Suite
+}
+
+func TestKubernetesSuite(t *testing.T) {
+	suite.Run(t, new(KubernetesSuite))
+}
+
+func (s *KubernetesSuite) SetupSuite() {
+	db.SetGlobalSessionProvider(testutil.TestConfig().SessionFactory())
+}
+
+func (s *KubernetesSuite) SetupTest() {
+	s.api, s.server = newKubernetesAPIMock("token")
+	s.settings = &evergreen.Settings{
+		Providers: evergreen.CloudProviders{
+			Kubernetes: evergreen.KubernetesConfig{
+				APIServer: s.server.URL,
+				Token:     "token",
+			},
+		},
+	}
+	s.manager = &kubernetesManager{}
+	s.Require().NoError(s.manager.Configure(s.settings))
+
+	s.distro = &distro.Distro{
+		Id:       "pod-distro",
+		Provider: evergreen.ProviderNameKubernetes,
+		ProviderSettings: &map[string]interface{}{
+			"namespace":         "evergreen",
+			"image":             "evergreen/ubuntu1604:latest",
+			"cpu":               "2",
+			"memory":            "4Gi",
+			"image_pull_secret": "registry",
+			"node_selector":     map[string]interface{}{"pool": "build"},
+		},
+	}
+	s.hostOpts = HostOptions{}
+}
+
+func (s *KubernetesSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *KubernetesSuite) spawn() *host.Host {
+	h := NewIntent(*s.distro, s.manager.GetInstanceName(s.distro), s.distro.Provider, s.hostOpts)
+	h, err := s.manager.SpawnHost(h)
+	s.Require().NoError(err)
+	s.Require().NotNil(h)
+	return h
+}
+
+func (s *KubernetesSuite) TestValidateSettings() {
+	settingsOk := &kubernetesSettings{
+		Namespace: "evergreen",
+		Image:     "image",
+		CPU:       "500m",
+		Memory:    "512Mi",
+	}
+	s.NoError(settingsOk.Validate())
+
+	settingsNoNamespace := &kubernetesSettings{Image: "image"}
+	s.Error(settingsNoNamespace.Validate())
+
+	settingsNoImage := &kubernetesSettings{Namespace: "evergreen"}
+	s.Error(settingsNoImage.Validate())
+
+	settingsBadCPU := &kubernetesSettings{Namespace: "evergreen", Image: "image", CPU: "two"}
+	s.Error(settingsBadCPU.Validate())
+
+	settingsBadMemory := &kubernetesSettings{Namespace: "evergreen", Image: "image", Memory: "4 GB"}
+	s.Error(settingsBadMemory.Validate())
+
+	settingsBadPort := &kubernetesSettings{Namespace: "evergreen", Image: "image", SSHPort: 70000}
+	s.Error(settingsBadPort.Validate())
+}
+
+func (s *KubernetesSuite) TestConfigure() {
+	manager := &kubernetesManager{}
+	s.Error(manager.Configure(&evergreen.Settings{}))
+	s.NoError(manager.Configure(s.settings))
+}
+
+func (s *KubernetesSuite) TestInsecureSkipVerifyOnlyAffectsClient() {
+	client := &kubernetesClientImpl{}
+	s.Require().NoError(client.Init(&evergreen.KubernetesConfig{APIServer: s.server.URL, InsecureSkipVerify: true}))
+	s.True(client.httpClient.Transport.(*http.Transport).TLSClientConfig.InsecureSkipVerify)
+
+	pooled := util.GetHttpClient()
+	defer util.PutHttpClient(pooled)
+	s.False(pooled.Transport.(*http.Transport).TLSClientConfig.InsecureSkipVerify)
+}
+
+func (s *KubernetesSuite) TestSpawnCreatesPod() {
+	h := s.spawn()
+
+	pod, ok := s.api.getPod("evergreen", h.Id)
+	s.Require().True(ok)
+	s.Equal(h.Id, pod.Metadata.Labels[podLabelHostID])
+	s.Equal("pod-distro", pod.Metadata.Labels[podLabelDistro])
+	s.Equal("build", pod.Spec.NodeSelector["pool"])
+	s.Equal("Never", pod.Spec.RestartPolicy)
+	s.Require().Len(pod.Spec.ImagePullSecrets, 1)
+	s.Equal("registry", pod.Spec.ImagePullSecrets[0].Name)
+
+	s.Require().Len(pod.Spec.Containers, 1)
+	container := pod.Spec.Containers[0]
+	s.Equal("evergreen/ubuntu1604:latest", container.Image)
+	s.Equal("2", container.Resources.Requests["cpu"])
+	s.Equal("4Gi", container.Resources.Limits["memory"])
+	s.Require().Len(container.Ports, 1)
+	s.Equal(22, container.Ports[0].ContainerPort)
+}
+
+func (s *KubernetesSuite) TestSpawnAPIFailure() {
+	s.api.failCreate = true
+	h := NewIntent(*s.distro, s.manager.GetInstanceName(s.distro), s.distro.Provider, s.hostOpts)
+	h, err := s.manager.SpawnHost(h)
+	s.Error(err)
+	s.Nil(h)
+}
+
+func (s *KubernetesSuite) TestSpawnInvalidSettings() {
+	dProviderName := &distro.Distro{Provider: "ec2"}
+	h := NewIntent(*dProviderName, s.manager.GetInstanceName(dProviderName), dProviderName.Provider, s.hostOpts)
+	h, err := s.manager.SpawnHost(h)
+	s.Error(err)
+	s.Nil(h)
+
+	dSettingsNone := &distro.Distro{Provider: evergreen.ProviderNameKubernetes}
+	h = NewIntent(*dSettingsNone, s.manager.GetInstanceName(dSettingsNone), dSettingsNone.Provider, s.hostOpts)
+	h, err = s.manager.SpawnHost(h)
+	s.Error(err)
+	s.Nil(h)
+}
+
+func (s *KubernetesSuite) TestGetInstanceStatus() {
+	h := s.spawn()
+
+	status, err := s.manager.GetInstanceStatus(h)
+	s.NoError(err)
+	s.Equal(StatusRunning, status)
+
+	up, err := s.manager.IsUp(h)
+	s.NoError(err)
+	s.True(up)
+
+	s.api.setPhase("evergreen", h.Id, podPhaseRunning, false)
+	status, err = s.manager.GetInstanceStatus(h)
+	s.NoError(err)
+	s.Equal(StatusInitializing, status)
+
+	s.api.setPhase("evergreen", h.Id, podPhasePending, false)
+	status, err = s.manager.GetInstanceStatus(h)
+	s.NoError(err)
+	s.Equal(StatusInitializing, status)
+
+	s.api.setPhase("evergreen", h.Id, podPhaseFailed, false)
+	status, err = s.manager.GetInstanceStatus(h)
+	s.NoError(err)
+	s.Equal(StatusFailed, status)
+
+	s.api.setPhase("evergreen", h.Id, podPhaseSucceeded, false)
+	status, err = s.manager.GetInstanceStatus(h)
+	s.NoError(err)
+	s.Equal(StatusTerminated, status)
+
+	s.api.failGet = true
+	status, err = s.manager.GetInstanceStatus(h)
+	s.Error(err)
+	s.Equal(StatusUnknown, status)
+
+	up, err = s.manager.IsUp(h)
+	s.Error(err)
+	s.False(up)
+}
+
+func (s *KubernetesSuite) TestGetInstanceStatusMissingPod() {
+	h := NewIntent(*s.distro, s.manager.GetInstanceName(s.distro), s.distro.Provider, s.hostOpts)
+
+	status, err := s.manager.GetInstanceStatus(h)
+	s.NoError(err)
+	s.Equal(StatusTerminated, status)
+}
+
+func (s *KubernetesSuite) TestGetDNSName() {
+	h := s.spawn()
+
+	dns, err := s.manager.GetDNSName(h)
+	s.NoError(err)
+	s.Equal("10.0.0.1", dns)
+
+	(*s.distro.ProviderSettings)["ssh_port"] = 2222
+	h = s.spawn()
+	dns, err = s.manager.GetDNSName(h)
+	s.NoError(err)
+	s.Equal("10.0.0.2:2222", dns)
+
+	missing := NewIntent(*s.distro, s.manager.GetInstanceName(s.distro), s.distro.Provider, s.hostOpts)
+	_, err = s.manager.GetDNSName(missing)
+	s.Error(err)
+}
+
+func (s *KubernetesSuite) TestTerminateInstance() {
+	h := s.spawn()
+	_, err := h.Upsert()
+	s.Require().NoError(err)
+
+	s.api.failDelete = true
+	s.Error(s.manager.TerminateInstance(h))
+	_, ok := s.api.getPod("evergreen", h.Id)
+	s.True(ok)
+
+	s.api.failDelete = false
+	s.NoError(s.manager.TerminateInstance(h))
+	_, ok = s.api.getPod("evergreen", h.Id)
+	s.False(ok)
+
+	dbHost, err := host.FindOne(host.ById(h.Id))
+	s.NoError(err)
+	s.Equal(evergreen.HostTerminated, dbHost.Status)
+
+	// Terminate again - check we cannot remove twice.
+	s.Error(s.manager.TerminateInstance(h))
+}
+
+func (s *KubernetesSuite) TestGetSSHOptions() {
+	opt := "Option"
+	keyname := "key"
+	h := &host.Host{
+		Distro: distro.Distro{
+			SSHOptions: []string{opt},
+		},
+	}
+
+	opts, err := s.manager.GetSSHOptions(h, "")
+	s.Error(err)
+	s.Empty(opts)
+
+	opts, err = s.manager.GetSSHOptions(h, keyname)
+	s.NoError(err)
+	s.Equal([]string{"-i", keyname, "-o", opt}, opts)
+}
+
+func (s *KubernetesSuite) TestGetInstanceName() {
+	name := s.manager.GetInstanceName(s.distro)
+	s.Regexp("^pod-[0-9]+$", name)
+}
@@ -0,0 +1,160 @@
+package cloud
+
+import (
+	"github.com/evergreen-ci/evergreen/model/host"
+)
+
+const (
+	podContainerName = "evergreen"
+
+	podLabelHostID = "evergreen-host-id"
+	podLabelDistro = "evergreen-distro"
+
+	podPhasePending   = "Pending"
+	podPhaseRunning   = "Running"
+	podPhaseSucceeded = "Succeeded"
+	podPhaseFailed    = "Failed"
+)
+
+// The following types are the subset of the orchestrator's pod API
+// objects that Evergreen reads and writes.
+
+type kubernetesPod struct {
+	APIVersion string               `json:"apiVersion,omitempty"`
+	Kind       string               `json:"kind,omitempty"`
+	Metadata   kubernetesObjectMeta `json:"metadata"`
+	Spec       kubernetesPodSpec    `json:"spec"`
+	Status     kubernetesPodStatus  `json:"status,omitempty"`
+}
+
+type kubernetesObjectMeta struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+type kubernetesPodSpec struct {
+	Containers       []kubernetesContainer            `json:"containers"`
+	NodeSelector     map[string]string                `json:"nodeSelector,omitempty"`
+	ImagePullSecrets []kubernetesLocalObjectReference `json:"imagePullSecrets,omitempty"`
+	RestartPolicy    string                           `json:"restartPolicy,omitempty"`
+}
+
+type kubernetesContainer struct {
+	Name      string                         `json:"name"`
+	Image     string                         `json:"image"`
+	Ports     []kubernetesContainerPort      `json:"ports,omitempty"`
+	Resources kubernetesResourceRequirements `json:"resources,omitempty"`
+}
+
+type kubernetesContainerPort struct {
+	Name          string `json:"name,omitempty"`
+	ContainerPort int    `json:"containerPort"`
+	Protocol      string `json:"protocol,omitempty"`
+}
+
+type kubernetesResourceRequirements struct {
+	Requests map[string]string `json:"requests,omitempty"`
+	Limits   map[string]string `json:"limits,omitempty"`
+}
+
+type kubernetesLocalObjectReference struct {
+	Name string `json:"name"`
+}
+
+type kubernetesPodStatus struct {
+	Phase             string                      `json:"phase,omitempty"`
+	PodIP             string                      `json:"podIP,omitempty"`
+	HostIP            string                      `json:"hostIP,omitempty"`
+	Reason            string                      `json:"reason,omitempty"`
+	ContainerStatuses []kubernetesContainerStatus `json:"containerStatuses,omitempty"`
+}
+
+type kubernetesContainerStatus struct {
+	Name  string `json:"name"`
+	Ready bool   `json:"ready"`
+}
+
+type kubernetesStatus struct {
+	Message string `json:"message"`
+	Reason  string `json:"reason"`
+	Code    int    `json:"code"`
+}
+
+// makePodSpec builds the pod for a host. The pod never restarts its
+// container, so that a crashed host shows up as a failed pod rather
+// than silently coming back with a clean filesystem.
+func makePodSpec(h *host.Host, s *kubernetesSettings) *kubernetesPod {
+	pod := &kubernetesPod{
+		APIVersion: "v1",
+		Kind:       "Pod",
+		Metadata: kubernetesObjectMeta{
+			Name:      h.Id,
+			Namespace: s.Namespace,
+			Labels: map[string]string{
+				podLabelHostID: h.Id,
+				podLabelDistro: h.Distro.Id,
+			},
+		},
+		Spec: kubernetesPodSpec{
+			Containers: []kubernetesContainer{
+				{
+					Name:  podContainerName,
+					Image: s.Image,
+					Ports: []kubernetesContainerPort{
+						{
+							Name:          "ssh",
+							ContainerPort: s.getSSHPort(),
+							Protocol:      "TCP",
+						},
+					},
+				},
+			},
+			NodeSelector:  s.NodeSelector,
+			RestartPolicy: "Never",
+		},
+	}
+
+	resources := map[string]string{}
+	if s.CPU != "" {
+		resources["cpu"] = s.CPU
+	}
+	if s.Memory != "" {
+		resources["memory"] = s.Memory
+	}
+	if len(resources) > 0 {
+		pod.Spec.Containers[0].Resources = kubernetesResourceRequirements{
+			Requests: resources,
+			Limits:   resources,
+		}
+	}
+
+	if s.ImagePullSecret != "" {
+		pod.Spec.ImagePullSecrets = []kubernetesLocalObjectReference{{Name: s.ImagePullSecret}}
+	}
+
+	return pod
+}
+
+// podToEvgStatus converts a pod's phase to an Evergreen cloud
+// provider status. A running pod is only considered running once all
+// of its containers are ready.
+func podToEvgStatus(pod *kubernetesPod) CloudStatus {
+	switch pod.Status.Phase {
+	case podPhasePending:
+		return StatusInitializing
+	case podPhaseRunning:
+		for _, c := range pod.Status.ContainerStatuses {
+			if !c.Ready {
+				return StatusInitializing
+			}
+		}
+		return StatusRunning
+	case podPhaseSucceeded:
+		return StatusTerminated
+	case podPhaseFailed:
+		return StatusFailed
+	default:
+		return StatusUnknown
+	}
+}
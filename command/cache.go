@@ -0,0 +1,384 @@
+package command
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	cacheArchiveSuffix      = ".tar.gz"
+	cacheArchiveContentType = "application/x-gzip"
+
+	// cacheHitExpansion is set to "true" by cache.restore when it
+	// restores the exact key, and "false" otherwise.
+	cacheHitExpansion = "cache_hit"
+	// cacheRestoredKeyExpansion is set by cache.restore to the key it
+	// restored, if any.
+	cacheRestoredKeyExpansion = "cache_restored_key"
+)
+
+var (
+	// matches {{hash file ...}} in cache keys
+	cacheHashTemplate = regexp.MustCompile(`\{\{\s*hash\s+([^}]*?)\s*\}\}`)
+	// matches the characters cache keys may not contain
+	cacheKeyInvalidChars = regexp.MustCompile(`[^A-Za-z0-9._/-]`)
+)
+
+// cacheOptions are the parameters shared by cache.save and
+// cache.restore.
+type cacheOptions struct {
+	// Key names the cache entry. Besides expansions, it may contain
+	// {{hash <file> ...}} templates, which are replaced by a hash of the
+	// contents of the files, e.g. go-${build_variant}-{{hash go.sum}}.
+	// File names are relative to the working directory and may be globs.
+	Key string `mapstructure:"key" plugin:"expand"`
+
+	// Paths are the directories to save or restore, relative to the
+	// working directory or absolute. An entry is restored path by path
+	// in the order it was saved in, so both commands should list the
+	// same paths.
+	Paths []string `mapstructure:"paths" plugin:"expand"`
+
+	// Bucket is where cache entries are stored, with the provider
+	// chosen by the blob storage options.
+	Bucket             string `mapstructure:"bucket" plugin:"expand"`
+	blobStorageOptions `mapstructure:",squash" plugin:"expand"`
+
+	// AwsKey and AwsSecret are the credentials for the s3 provider.
+	AwsKey    string `mapstructure:"aws_key" plugin:"expand"`
+	AwsSecret string `mapstructure:"aws_secret" plugin:"expand"`
+
+	// Prefix is prepended to the key of each cache entry. It defaults
+	// to cache/<project>, so that projects do not share entries.
+	Prefix string `mapstructure:"prefix" plugin:"expand"`
+}
+
+func (o *cacheOptions) validate() error {
+	if o.Key == "" {
+		return errors.New("key cannot be blank")
+	}
+	if len(o.Paths) == 0 {
+		return errors.New("paths cannot be empty")
+	}
+	if err := o.validateProvider(); err != nil {
+		return errors.WithStack(err)
+	}
+	if o.AwsKey == "" && !o.isLocal() {
+		return errors.New("aws_key cannot be blank")
+	}
+	if o.AwsSecret == "" && !o.isLocal() {
+		return errors.New("aws_secret cannot be blank")
+	}
+	if err := o.validateBucketName(o.Bucket); err != nil {
+		return errors.Wrapf(err, "%v is an invalid bucket name", o.Bucket)
+	}
+	return nil
+}
+
+// resolve makes the paths absolute and fills in the defaults once the
+// options have been expanded.
+func (o *cacheOptions) resolve(conf *model.TaskConfig) {
+	o.applyDefaults(conf.Expansions)
+	for i, p := range o.Paths {
+		if !filepath.IsAbs(p) {
+			o.Paths[i] = filepath.Join(conf.WorkDir, p)
+		}
+	}
+	if o.Prefix == "" {
+		o.Prefix = path.Join("cache", conf.Task.Project)
+	}
+}
+
+// blobKey returns the key of the blob holding a cache entry, or the
+// prefix of the keys of the entries whose keys start with key.
+func (o *cacheOptions) blobKey(key string) string {
+	return strings.TrimLeft(path.Join(o.Prefix, key), "/")
+}
+
+// renderCacheKey replaces the hash templates in an expanded cache key and
+// checks that the result can be used as part of a blob key.
+func renderCacheKey(key, workDir string) (string, error) {
+	var hashErr error
+	key = cacheHashTemplate.ReplaceAllStringFunc(key, func(match string) string {
+		files := strings.Fields(cacheHashTemplate.FindStringSubmatch(match)[1])
+		hash, err := hashCacheFiles(workDir, files)
+		if err != nil && hashErr == nil {
+			hashErr = err
+		}
+		return hash
+	})
+	if hashErr != nil {
+		return "", hashErr
+	}
+
+	key = cacheKeyInvalidChars.ReplaceAllString(strings.TrimSpace(key), "_")
+	if key == "" {
+		return "", errors.New("cache key is empty")
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == ".." {
+			return "", errors.Errorf("cache key '%s' may not contain '..'", key)
+		}
+	}
+	return key, nil
+}
+
+// hashCacheFiles returns a short hash of the names and contents of the
+// files matching the patterns.
+func hashCacheFiles(workDir string, patterns []string) (string, error) {
+	if len(patterns) == 0 {
+		return "", errors.New("hash requires at least one file")
+	}
+
+	files := []string{}
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(workDir, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid pattern '%s'", pattern)
+		}
+		if len(matches) == 0 {
+			return "", errors.Errorf("no files match '%s'", pattern)
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+
+	hash := sha256.New()
+	for _, name := range files {
+		f, err := os.Open(name)
+		if err != nil {
+			return "", errors.Wrapf(err, "problem opening %s", name)
+		}
+		rel, err := filepath.Rel(workDir, name)
+		if err != nil {
+			rel = name
+		}
+		_, _ = io.WriteString(hash, filepath.ToSlash(rel)+"\x00")
+		_, err = io.Copy(hash, f)
+		_ = f.Close()
+		if err != nil {
+			return "", errors.Wrapf(err, "problem reading %s", name)
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:16], nil
+}
+
+// writeCacheArchive writes the contents of the paths to the tar writer,
+// with the entries of each path under its position in the list. Paths
+// that don't exist are skipped. It returns the number of files written.
+func writeCacheArchive(ctx context.Context, tw *tar.Writer, paths []string) (int, error) {
+	count := 0
+	for i, root := range paths {
+		if _, err := os.Stat(root); os.IsNotExist(err) {
+			continue
+		}
+
+		err := filepath.Walk(root, func(name string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if ctx.Err() != nil {
+				return errors.New("cache archive creation canceled")
+			}
+
+			rel, err := filepath.Rel(root, name)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			link := ""
+			if info.Mode()&os.ModeSymlink != 0 {
+				if link, err = os.Readlink(name); err != nil {
+					return errors.Wrapf(err, "problem reading link %s", name)
+				}
+			}
+			hdr, err := tar.FileInfoHeader(info, link)
+			if err != nil {
+				return errors.Wrapf(err, "problem archiving %s", name)
+			}
+			hdr.Name = strconv.Itoa(i) + "/" + filepath.ToSlash(rel)
+			if info.IsDir() {
+				hdr.Name += "/"
+			}
+			if err = tw.WriteHeader(hdr); err != nil {
+				return errors.Wrapf(err, "problem archiving %s", name)
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+
+			f, err := os.Open(name)
+			if err != nil {
+				return errors.Wrapf(err, "problem archiving %s", name)
+			}
+			defer f.Close()
+			if _, err = io.Copy(tw, f); err != nil {
+				return errors.Wrapf(err, "problem archiving %s", name)
+			}
+			count++
+			return nil
+		})
+		if err != nil {
+			return count, errors.WithStack(err)
+		}
+	}
+	return count, nil
+}
+
+// extractCacheArchive restores the entries of a cache archive into the
+// paths, by their position. It returns the number of files restored.
+// Since caches are shared between a project's tasks, entries may only
+// write inside of their path: links must point inside of it, and entries
+// can't be restored through a link.
+func extractCacheArchive(ctx context.Context, tr *tar.Reader, paths []string) (int, error) {
+	count := 0
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, errors.Wrap(err, "problem reading cache archive")
+		}
+		if ctx.Err() != nil {
+			return count, errors.New("cache extraction canceled")
+		}
+
+		parts := strings.SplitN(hdr.Name, "/", 2)
+		i, err := strconv.Atoi(parts[0])
+		if err != nil || i < 0 || i >= len(paths) || len(parts) != 2 {
+			continue
+		}
+		root := filepath.Clean(paths[i])
+		target := filepath.Join(root, filepath.FromSlash(parts[1]))
+		if !isWithinCachePath(root, target) {
+			return count, errors.Errorf("cache archive entry '%s' is outside of %s", hdr.Name, root)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err = checkCacheLinks(root, target); err != nil {
+				return count, errors.Wrapf(err, "problem restoring cache archive entry '%s'", hdr.Name)
+			}
+			if err = os.MkdirAll(target, os.FileMode(hdr.Mode)|0700); err != nil {
+				return count, errors.WithStack(err)
+			}
+		case tar.TypeReg, tar.TypeRegA:
+			if err = checkCacheLinks(root, filepath.Dir(target)); err != nil {
+				return count, errors.Wrapf(err, "problem restoring cache archive entry '%s'", hdr.Name)
+			}
+			if err = os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return count, errors.WithStack(err)
+			}
+			_ = os.Remove(target)
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(hdr.Mode))
+			if err != nil {
+				return count, errors.WithStack(err)
+			}
+			_, err = io.Copy(f, tr)
+			closeErr := f.Close()
+			if err != nil {
+				return count, errors.Wrapf(err, "problem restoring %s", target)
+			}
+			if closeErr != nil {
+				return count, errors.Wrapf(closeErr, "problem restoring %s", target)
+			}
+			count++
+		case tar.TypeSymlink:
+			// links only point down from their directory, so that no
+			// combination of them, however they resolve, leaves the root
+			if !isDescendingCacheLink(hdr.Linkname) {
+				return count, errors.Errorf("cache archive link '%s' must point to a path below its directory", hdr.Name)
+			}
+			if err = checkCacheLinks(root, filepath.Dir(target)); err != nil {
+				return count, errors.Wrapf(err, "problem restoring cache archive entry '%s'", hdr.Name)
+			}
+			if err = os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return count, errors.WithStack(err)
+			}
+			_ = os.Remove(target)
+			if err = os.Symlink(hdr.Linkname, target); err != nil {
+				return count, errors.Wrapf(err, "problem restoring link %s", target)
+			}
+		}
+	}
+}
+
+// isWithinCachePath returns true if the cleaned path is the root or is
+// inside of it.
+func isWithinCachePath(root, path string) bool {
+	return path == root || strings.HasPrefix(path, root+string(filepath.Separator))
+}
+
+// isDescendingCacheLink returns true if the link target is a relative path
+// without any '..' elements.
+func isDescendingCacheLink(linkname string) bool {
+	if linkname == "" || filepath.IsAbs(linkname) || strings.HasPrefix(linkname, "/") {
+		return false
+	}
+	for _, elem := range strings.Split(filepath.ToSlash(linkname), "/") {
+		if elem == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// checkCacheLinks returns an error if the directory, or any directory
+// between it and the root, is a link, so that restoring an entry into it
+// can't write elsewhere through the link.
+func checkCacheLinks(root, dir string) error {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if rel == "." {
+		return nil
+	}
+
+	path := root
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		path = filepath.Join(path, part)
+		info, err := os.Lstat(path)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return errors.Errorf("%s is a link", path)
+		}
+	}
+	return nil
+}
+
+// formatBytes renders a size for the task logs.
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,144 @@
+package command
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"strings"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/rest/client"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+// cacheRestore restores the directories saved by cache.save. It restores
+// the entry for the key if there is one, and otherwise the most recently
+// saved entry whose key starts with the first of the restore keys that
+// matches any entries. Not finding an entry is not an error; the
+// cache_hit expansion records whether the exact key was restored.
+type cacheRestore struct {
+	cacheOptions `mapstructure:",squash" plugin:"expand"`
+
+	// RestoreKeys are key prefixes to fall back on, from the most to the
+	// least specific, e.g. go-${build_variant}-.
+	RestoreKeys []string `mapstructure:"restore_keys" plugin:"expand"`
+
+	base
+}
+
+func cacheRestoreFactory() Command   { return &cacheRestore{} }
+func (c *cacheRestore) Name() string { return "cache.restore" }
+
+func (c *cacheRestore) ParseParams(params map[string]interface{}) error {
+	if err := mapstructure.Decode(params, c); err != nil {
+		return errors.Wrapf(err, "error decoding %s params", c.Name())
+	}
+
+	return errors.Wrapf(c.validate(), "error validating %s params", c.Name())
+}
+
+func (c *cacheRestore) Execute(ctx context.Context,
+	comm client.Communicator, logger client.LoggerProducer, conf *model.TaskConfig) error {
+
+	conf.Expansions.Put(cacheHitExpansion, "false")
+	conf.Expansions.Put(cacheRestoredKeyExpansion, "")
+
+	if err := util.ExpandValues(c, conf.Expansions); err != nil {
+		return errors.Wrap(err, "problem expanding params")
+	}
+	if err := c.validate(); err != nil {
+		return errors.Wrap(err, "expanded params are not valid")
+	}
+	c.resolve(conf)
+
+	key, err := renderCacheKey(c.Key, conf.WorkDir)
+	if err != nil {
+		return errors.Wrap(err, "problem building cache key")
+	}
+	bucket, err := c.openBucket(conf, c.Bucket, c.AwsKey, c.AwsSecret)
+	if err != nil {
+		return errors.Wrapf(err, "problem opening bucket %s", c.Bucket)
+	}
+
+	blobKey, err := c.findEntry(bucket, key, conf.WorkDir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if blobKey == "" {
+		logger.Task().Infof("No cache entry found for '%s'", key)
+		return nil
+	}
+	restoredKey := strings.TrimSuffix(strings.TrimPrefix(blobKey, c.blobKey("")+"/"), cacheArchiveSuffix)
+
+	r, err := bucket.Get(blobKey)
+	if thirdparty.IsBlobNotFound(err) {
+		// the entry was removed since it was listed
+		logger.Task().Infof("Cache entry '%s' is no longer available", restoredKey)
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "problem fetching cache entry '%s'", restoredKey)
+	}
+	defer r.Close()
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return errors.Wrapf(err, "problem reading cache entry '%s'", restoredKey)
+	}
+	defer gz.Close()
+
+	count, err := extractCacheArchive(ctx, tar.NewReader(gz), c.Paths)
+	if err != nil {
+		return errors.Wrapf(err, "problem restoring cache entry '%s'", restoredKey)
+	}
+	logger.Task().Infof("Restored %d files from cache entry '%s'", count, restoredKey)
+
+	conf.Expansions.Put(cacheRestoredKeyExpansion, restoredKey)
+	if restoredKey == key {
+		conf.Expansions.Put(cacheHitExpansion, "true")
+	}
+	return nil
+}
+
+// findEntry returns the blob key of the entry to restore, or an empty
+// string if there is none.
+func (c *cacheRestore) findEntry(bucket thirdparty.Bucket, key, workDir string) (string, error) {
+	exact := c.blobKey(key) + cacheArchiveSuffix
+	blobs, err := bucket.List(exact)
+	if err != nil {
+		return "", errors.Wrap(err, "problem listing cache entries")
+	}
+	for _, blob := range blobs {
+		if blob.Key == exact {
+			return exact, nil
+		}
+	}
+
+	for _, restoreKey := range c.RestoreKeys {
+		prefix, err := renderCacheKey(restoreKey, workDir)
+		if err != nil {
+			return "", errors.Wrapf(err, "problem building restore key '%s'", restoreKey)
+		}
+		blobs, err = bucket.List(c.blobKey(prefix))
+		if err != nil {
+			return "", errors.Wrap(err, "problem listing cache entries")
+		}
+
+		newest := thirdparty.BlobInfo{}
+		for _, blob := range blobs {
+			if !strings.HasSuffix(blob.Key, cacheArchiveSuffix) {
+				continue
+			}
+			if newest.Key == "" || blob.LastModified.After(newest.LastModified) {
+				newest = blob
+			}
+		}
+		if newest.Key != "" {
+			return newest.Key, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,113 @@
+package command
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/rest/client"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+// cacheSave archives a task's dependency directories and stores them
+// under a key, so that later tasks can restore them with cache.restore.
+// Entries are immutable: if an entry already exists for the key, nothing
+// is saved.
+type cacheSave struct {
+	cacheOptions `mapstructure:",squash" plugin:"expand"`
+	base
+}
+
+func cacheSaveFactory() Command   { return &cacheSave{} }
+func (c *cacheSave) Name() string { return "cache.save" }
+
+func (c *cacheSave) ParseParams(params map[string]interface{}) error {
+	if err := mapstructure.Decode(params, c); err != nil {
+		return errors.Wrapf(err, "error decoding %s params", c.Name())
+	}
+
+	return errors.Wrapf(c.validate(), "error validating %s params", c.Name())
+}
+
+func (c *cacheSave) Execute(ctx context.Context,
+	comm client.Communicator, logger client.LoggerProducer, conf *model.TaskConfig) error {
+
+	if err := util.ExpandValues(c, conf.Expansions); err != nil {
+		return errors.Wrap(err, "problem expanding params")
+	}
+	if err := c.validate(); err != nil {
+		return errors.Wrap(err, "expanded params are not valid")
+	}
+	c.resolve(conf)
+
+	key, err := renderCacheKey(c.Key, conf.WorkDir)
+	if err != nil {
+		return errors.Wrap(err, "problem building cache key")
+	}
+	blobKey := c.blobKey(key) + cacheArchiveSuffix
+	bucket, err := c.openBucket(conf, c.Bucket, c.AwsKey, c.AwsSecret)
+	if err != nil {
+		return errors.Wrapf(err, "problem opening bucket %s", c.Bucket)
+	}
+
+	existing, err := bucket.List(blobKey)
+	if err != nil {
+		return errors.Wrap(err, "problem checking for an existing cache entry")
+	}
+	for _, blob := range existing {
+		if blob.Key == blobKey {
+			logger.Task().Infof("Cache entry '%s' already exists, not saving", key)
+			return nil
+		}
+	}
+
+	archive, err := ioutil.TempFile("", "evergreen-cache")
+	if err != nil {
+		return errors.Wrap(err, "problem creating cache archive")
+	}
+	defer func() {
+		_ = archive.Close()
+		_ = os.Remove(archive.Name())
+	}()
+
+	gz := gzip.NewWriter(archive)
+	tw := tar.NewWriter(gz)
+	count, err := writeCacheArchive(ctx, tw, c.Paths)
+	if err != nil {
+		return errors.Wrap(err, "problem creating cache archive")
+	}
+	if err = tw.Close(); err != nil {
+		return errors.Wrap(err, "problem creating cache archive")
+	}
+	if err = gz.Close(); err != nil {
+		return errors.Wrap(err, "problem creating cache archive")
+	}
+	if count == 0 {
+		logger.Task().Warningf("No files to cache in %v, not saving cache entry '%s'", c.Paths, key)
+		return nil
+	}
+
+	size, err := archive.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return errors.Wrap(err, "problem sizing cache archive")
+	}
+	if _, err = archive.Seek(0, io.SeekStart); err != nil {
+		return errors.Wrap(err, "problem reading cache archive")
+	}
+
+	logger.Task().Infof("Saving %d files (%s) to cache entry '%s'", count, formatBytes(size), key)
+	err = bucket.Put(blobKey, archive, size, thirdparty.PutOptions{
+		ContentType: cacheArchiveContentType,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "problem saving cache entry '%s'", key)
+	}
+	return nil
+}
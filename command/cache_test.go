@@ -0,0 +1,243 @@
+package command
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/rest/client"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/stretchr/testify/suite"
+)
+
+type CacheSuite struct {
+	suite.Suite
+	conf   *model.TaskConfig
+	comm   client.Communicator
+	logger client.LoggerProducer
+	ctx    context.Context
+	cancel context.CancelFunc
+	tmpdir string
+}
+
+func TestCacheSuite(t *testing.T) {
+	suite.Run(t, new(CacheSuite))
+}
+
+func (s *CacheSuite) SetupTest() {
+	var err error
+	s.tmpdir, err = ioutil.TempDir("", "evergreen.command.cache.test")
+	s.Require().NoError(err)
+
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.comm = client.NewMock("http://localhost.com")
+	s.conf = &model.TaskConfig{
+		Expansions: util.NewExpansions(map[string]string{"build_variant": "linux"}),
+		Task:       &task.Task{Project: "project"},
+		Project:    &model.Project{},
+		WorkDir:    filepath.Join(s.tmpdir, "work"),
+	}
+	s.logger = s.comm.GetLoggerProducer(s.ctx, client.TaskData{ID: s.conf.Task.Id, Secret: s.conf.Task.Secret})
+
+	s.Require().NoError(os.MkdirAll(filepath.Join(s.conf.WorkDir, "vendor", "lib"), 0755))
+	s.Require().NoError(ioutil.WriteFile(filepath.Join(s.conf.WorkDir, "go.sum"), []byte("v1"), 0644))
+	s.Require().NoError(ioutil.WriteFile(filepath.Join(s.conf.WorkDir, "vendor", "lib", "lib.go"), []byte("package lib"), 0644))
+	s.Require().NoError(os.Symlink("lib", filepath.Join(s.conf.WorkDir, "vendor", "current")))
+}
+
+func (s *CacheSuite) TearDownTest() {
+	s.cancel()
+	s.Require().NoError(os.RemoveAll(s.tmpdir))
+}
+
+func (s *CacheSuite) params(extra map[string]interface{}) map[string]interface{} {
+	params := map[string]interface{}{
+		"key":      "go-${build_variant}-{{hash go.sum}}",
+		"paths":    []string{"vendor"},
+		"provider": "local",
+		"bucket":   filepath.Join(s.tmpdir, "bucket"),
+	}
+	for k, v := range extra {
+		params[k] = v
+	}
+	return params
+}
+
+func (s *CacheSuite) save() {
+	cmd := cacheSaveFactory()
+	s.Require().NoError(cmd.ParseParams(s.params(nil)))
+	s.Require().NoError(cmd.Execute(s.ctx, s.comm, s.logger, s.conf))
+}
+
+func (s *CacheSuite) restore(extra map[string]interface{}) {
+	cmd := cacheRestoreFactory()
+	s.Require().NoError(cmd.ParseParams(s.params(extra)))
+	s.Require().NoError(cmd.Execute(s.ctx, s.comm, s.logger, s.conf))
+}
+
+func (s *CacheSuite) TestParseParams() {
+	s.NoError(cacheSaveFactory().ParseParams(s.params(nil)))
+	s.NoError(cacheRestoreFactory().ParseParams(s.params(map[string]interface{}{"restore_keys": []string{"go-"}})))
+
+	s.Error(cacheSaveFactory().ParseParams(s.params(map[string]interface{}{"key": ""})))
+	s.Error(cacheSaveFactory().ParseParams(s.params(map[string]interface{}{"paths": []string{}})))
+	s.Error(cacheSaveFactory().ParseParams(s.params(map[string]interface{}{"provider": "ftp"})))
+	s.Error(cacheRestoreFactory().ParseParams(s.params(map[string]interface{}{"provider": "s3"})))
+	s.NoError(cacheRestoreFactory().ParseParams(s.params(map[string]interface{}{
+		"provider":   "s3",
+		"bucket":     "build-cache",
+		"aws_key":    "key",
+		"aws_secret": "secret",
+	})))
+}
+
+func (s *CacheSuite) TestRenderCacheKey() {
+	key, err := renderCacheKey("go-linux-{{hash go.sum}}", s.conf.WorkDir)
+	s.NoError(err)
+	s.Len(key, len("go-linux-")+16)
+
+	same, err := renderCacheKey("go-linux-{{ hash go.* }}", s.conf.WorkDir)
+	s.NoError(err)
+	s.Equal(key, same)
+
+	s.Require().NoError(ioutil.WriteFile(filepath.Join(s.conf.WorkDir, "go.sum"), []byte("v2"), 0644))
+	changed, err := renderCacheKey("go-linux-{{hash go.sum}}", s.conf.WorkDir)
+	s.NoError(err)
+	s.NotEqual(key, changed)
+
+	key, err = renderCacheKey("deps for linux:x86", s.conf.WorkDir)
+	s.NoError(err)
+	s.Equal("deps_for_linux_x86", key)
+
+	_, err = renderCacheKey("go-{{hash missing.sum}}", s.conf.WorkDir)
+	s.Error(err)
+	_, err = renderCacheKey("../go", s.conf.WorkDir)
+	s.Error(err)
+	_, err = renderCacheKey("  ", s.conf.WorkDir)
+	s.Error(err)
+}
+
+func (s *CacheSuite) TestSaveAndRestore() {
+	s.save()
+
+	blobs, err := thirdparty.NewLocalBucket(filepath.Join(s.tmpdir, "bucket")).List("cache/project/go-linux-")
+	s.NoError(err)
+	s.Len(blobs, 1)
+
+	s.Require().NoError(os.RemoveAll(filepath.Join(s.conf.WorkDir, "vendor")))
+	s.restore(nil)
+
+	data, err := ioutil.ReadFile(filepath.Join(s.conf.WorkDir, "vendor", "current", "lib.go"))
+	s.NoError(err)
+	s.Equal("package lib", string(data))
+	link, err := os.Readlink(filepath.Join(s.conf.WorkDir, "vendor", "current"))
+	s.NoError(err)
+	s.Equal("lib", link)
+	s.Equal("true", s.conf.Expansions.Get(cacheHitExpansion))
+	key, err := renderCacheKey("go-linux-{{hash go.sum}}", s.conf.WorkDir)
+	s.NoError(err)
+	s.Equal(key, s.conf.Expansions.Get(cacheRestoredKeyExpansion))
+
+	// saving again under the same key leaves the entry as it is
+	s.Require().NoError(ioutil.WriteFile(filepath.Join(s.conf.WorkDir, "vendor", "lib", "lib.go"), []byte("changed"), 0644))
+	s.save()
+	s.restore(nil)
+	data, err = ioutil.ReadFile(filepath.Join(s.conf.WorkDir, "vendor", "lib", "lib.go"))
+	s.NoError(err)
+	s.Equal("package lib", string(data))
+}
+
+func (s *CacheSuite) TestRestoreFallsBackToNewestPrefixMatch() {
+	s.save()
+	bucket := filepath.Join(s.tmpdir, "bucket", "cache", "project")
+	blobs, err := ioutil.ReadDir(bucket)
+	s.Require().NoError(err)
+	s.Require().Len(blobs, 1)
+	older := filepath.Join(bucket, blobs[0].Name())
+	s.Require().NoError(os.Chtimes(older, time.Now().Add(-time.Hour), time.Now().Add(-time.Hour)))
+
+	s.Require().NoError(ioutil.WriteFile(filepath.Join(s.conf.WorkDir, "go.sum"), []byte("v2"), 0644))
+	s.Require().NoError(ioutil.WriteFile(filepath.Join(s.conf.WorkDir, "vendor", "lib", "lib.go"), []byte("newer"), 0644))
+	s.save()
+	newest, err := renderCacheKey("go-linux-{{hash go.sum}}", s.conf.WorkDir)
+	s.NoError(err)
+
+	s.Require().NoError(ioutil.WriteFile(filepath.Join(s.conf.WorkDir, "go.sum"), []byte("v3"), 0644))
+	s.Require().NoError(os.RemoveAll(filepath.Join(s.conf.WorkDir, "vendor")))
+	s.restore(map[string]interface{}{"restore_keys": []string{"go-windows-", "go-${build_variant}-"}})
+
+	data, err := ioutil.ReadFile(filepath.Join(s.conf.WorkDir, "vendor", "lib", "lib.go"))
+	s.NoError(err)
+	s.Equal("newer", string(data))
+	s.Equal("false", s.conf.Expansions.Get(cacheHitExpansion))
+	s.Equal(newest, s.conf.Expansions.Get(cacheRestoredKeyExpansion))
+}
+
+func (s *CacheSuite) TestRestoreMiss() {
+	s.restore(map[string]interface{}{"restore_keys": []string{"go-"}})
+	s.Equal("false", s.conf.Expansions.Get(cacheHitExpansion))
+	s.Equal("", s.conf.Expansions.Get(cacheRestoredKeyExpansion))
+
+	_, err := os.Stat(filepath.Join(s.conf.WorkDir, "vendor", "lib", "lib.go"))
+	s.NoError(err)
+}
+
+func (s *CacheSuite) TestExtractRejectsEntriesOutsideOfPath() {
+	root := filepath.Join(s.tmpdir, "restore")
+	outside := filepath.Join(s.tmpdir, "outside")
+	s.Require().NoError(os.MkdirAll(outside, 0755))
+
+	archive := func(hdrs ...*tar.Header) *tar.Reader {
+		buf := &bytes.Buffer{}
+		tw := tar.NewWriter(buf)
+		for _, hdr := range hdrs {
+			if hdr.Typeflag == tar.TypeReg {
+				hdr.Size = int64(len("data"))
+			}
+			s.Require().NoError(tw.WriteHeader(hdr))
+			if hdr.Typeflag == tar.TypeReg {
+				_, err := tw.Write([]byte("data"))
+				s.Require().NoError(err)
+			}
+		}
+		s.Require().NoError(tw.Close())
+		return tar.NewReader(buf)
+	}
+	extract := func(hdrs ...*tar.Header) error {
+		s.Require().NoError(os.RemoveAll(root))
+		_, err := extractCacheArchive(s.ctx, archive(hdrs...), []string{root})
+		return err
+	}
+	file := func(name string) *tar.Header {
+		return &tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644}
+	}
+	link := func(name, target string) *tar.Header {
+		return &tar.Header{Name: name, Typeflag: tar.TypeSymlink, Linkname: target}
+	}
+
+	s.NoError(extract(file("0/lib/lib.go"), link("0/current", "lib"), link("0/lib/self", "./lib.go")))
+	target, err := os.Readlink(filepath.Join(root, "current"))
+	s.NoError(err)
+	s.Equal("lib", target)
+
+	s.Error(extract(file("0/../escape")))
+	s.Error(extract(link("0/abs", outside)))
+	s.Error(extract(link("0/rel", "../outside")))
+	s.Error(extract(file("0/lib/lib.go"), link("0/lib/up", "../lib/lib.go")))
+	// links resolve through earlier links, so '..' can't be checked as text
+	s.Error(extract(link("0/b", "."), link("0/a", "b/../outside")))
+	s.Error(extract(link("0/dir", "lib"), file("0/dir/file")))
+	s.Error(extract(link("0/dir", "lib"), &tar.Header{Name: "0/dir/sub/", Typeflag: tar.TypeDir, Mode: 0755}))
+
+	files, err := ioutil.ReadDir(outside)
+	s.NoError(err)
+	s.Empty(files)
+}
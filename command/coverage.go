@@ -0,0 +1,137 @@
+package command
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/coverage"
+	"github.com/evergreen-ci/evergreen/rest/client"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+// attachCoverage reads in coverage reports and attaches the line coverage
+// of each file in them to the task, so that it can be compared with the
+// coverage of other versions.
+type attachCoverage struct {
+	reportFiles `mapstructure:",squash" plugin:"expand"`
+
+	// Format is the format of the reports: gocover, cobertura or lcov.
+	// If it is not set, the format of each report is detected from its
+	// contents.
+	Format string `mapstructure:"format" plugin:"expand"`
+
+	// StripPrefix is removed from the start of the file names in the
+	// reports, e.g. to turn the package paths in a Go coverprofile into
+	// paths in the repository. Absolute file names in the working
+	// directory are always made relative to it.
+	StripPrefix string `mapstructure:"strip_prefix" plugin:"expand"`
+
+	base
+}
+
+func attachCoverageFactory() Command   { return &attachCoverage{} }
+func (c *attachCoverage) Name() string { return "attach.coverage" }
+
+func (c *attachCoverage) ParseParams(params map[string]interface{}) error {
+	if err := mapstructure.Decode(params, c); err != nil {
+		return errors.Wrapf(err, "error decoding '%s' params", c.Name())
+	}
+
+	if err := c.validate(); err != nil {
+		return errors.Wrapf(err, "error validating '%s' params", c.Name())
+	}
+	if !strings.Contains(c.Format, "${") {
+		return errors.Wrapf(c.validateFormat(), "error validating '%s' params", c.Name())
+	}
+	return nil
+}
+
+func (c *attachCoverage) validateFormat() error {
+	if _, ok := coverageParsers[c.Format]; c.Format != "" && !ok {
+		return errors.Errorf("unknown coverage format '%s'", c.Format)
+	}
+	return nil
+}
+
+func (c *attachCoverage) Execute(ctx context.Context,
+	comm client.Communicator, logger client.LoggerProducer, conf *model.TaskConfig) error {
+
+	if err := util.ExpandValues(c, conf.Expansions); err != nil {
+		return errors.Wrap(err, "problem expanding params")
+	}
+	if err := c.validateFormat(); err != nil {
+		return errors.WithStack(err)
+	}
+	paths, err := c.paths(conf.WorkDir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	files := []coverage.FileCoverage{}
+	for _, fn := range paths {
+		if ctx.Err() != nil {
+			return errors.New("operation canceled")
+		}
+
+		parsed, err := c.parseReport(fn)
+		if err != nil {
+			return errors.Wrapf(err, "problem parsing coverage report '%s'", fn)
+		}
+		logger.Task().Infof("Found coverage of %d files in '%s'", len(parsed), fn)
+		for i := range parsed {
+			parsed[i].File = c.fileName(conf.WorkDir, parsed[i].File)
+		}
+		files = append(files, parsed...)
+	}
+
+	files = coverage.Merge(files)
+	total := coverage.Total(files)
+	logger.Task().Infof("Attaching coverage of %d files: %d of %d lines covered (%.1f%%)",
+		len(files), total.Covered, total.Total, total.Percent())
+
+	td := client.TaskData{ID: conf.Task.Id, Secret: conf.Task.Secret}
+	if err := comm.SendCoverage(ctx, td, files); err != nil {
+		return errors.Wrap(err, "problem attaching coverage")
+	}
+	logger.Task().Info("Attach coverage succeeded")
+	return nil
+}
+
+func (c *attachCoverage) parseReport(fn string) ([]coverage.FileCoverage, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't open coverage report")
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	format := c.Format
+	if format == "" {
+		format, err = detectCoverageFormat(r)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	return coverageParsers[format](r)
+}
+
+// fileName returns the name to store the coverage of a file under.
+func (c *attachCoverage) fileName(workDir, name string) string {
+	if filepath.IsAbs(name) {
+		if rel, err := filepath.Rel(workDir, name); err == nil && !strings.HasPrefix(rel, "..") {
+			name = rel
+		}
+	}
+	name = filepath.ToSlash(name)
+	if c.StripPrefix != "" {
+		name = strings.TrimPrefix(name, c.StripPrefix)
+	}
+	return path.Clean(strings.TrimPrefix(name, "/"))
+}
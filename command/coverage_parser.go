@@ -0,0 +1,179 @@
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/evergreen-ci/evergreen/model/coverage"
+	"github.com/pkg/errors"
+)
+
+const (
+	goCoverFormat   = "gocover"
+	coberturaFormat = "cobertura"
+	lcovFormat      = "lcov"
+)
+
+var (
+	// matches a block of a Go coverprofile, saving the file, the start
+	// and end lines and the count
+	goCoverBlockRegex = regexp.MustCompile(`^(.+):(\d+)\.\d+,(\d+)\.\d+ \d+ (\d+)$`)
+)
+
+// coverageParser reads the line coverage of each file in a coverage report.
+type coverageParser func(io.Reader) ([]coverage.FileCoverage, error)
+
+var coverageParsers = map[string]coverageParser{
+	goCoverFormat:   parseGoCoverProfile,
+	coberturaFormat: parseCoberturaReport,
+	lcovFormat:      parseLCOVReport,
+}
+
+// lineCoverage records whether each line of each file is covered. A line
+// reported more than once is covered if any of the reports cover it.
+type lineCoverage struct {
+	files map[string]map[int]bool
+	order []string
+}
+
+func newLineCoverage() *lineCoverage {
+	return &lineCoverage{files: map[string]map[int]bool{}}
+}
+
+func (c *lineCoverage) add(file string, line int, covered bool) {
+	lines, ok := c.files[file]
+	if !ok {
+		lines = map[int]bool{}
+		c.files[file] = lines
+		c.order = append(c.order, file)
+	}
+	lines[line] = lines[line] || covered
+}
+
+func (c *lineCoverage) export() []coverage.FileCoverage {
+	out := make([]coverage.FileCoverage, 0, len(c.order))
+	for _, file := range c.order {
+		out = append(out, coverage.NewFileCoverage(file, c.files[file]))
+	}
+	return out
+}
+
+// detectCoverageFormat guesses the format of a coverage report from its
+// first line.
+func detectCoverageFormat(r *bufio.Reader) (string, error) {
+	head, err := r.Peek(512)
+	if err != nil && err != io.EOF {
+		return "", errors.Wrap(err, "problem reading coverage report")
+	}
+	head = bytes.TrimSpace(head)
+	switch {
+	case bytes.HasPrefix(head, []byte("mode:")):
+		return goCoverFormat, nil
+	case bytes.HasPrefix(head, []byte("<")):
+		return coberturaFormat, nil
+	case bytes.HasPrefix(head, []byte("TN:")), bytes.HasPrefix(head, []byte("SF:")):
+		return lcovFormat, nil
+	default:
+		return "", errors.New("could not detect the format of the coverage report")
+	}
+}
+
+// parseGoCoverProfile reads a profile written by go test -coverprofile.
+// Every line of a block counts as covered if the block ran.
+func parseGoCoverProfile(r io.Reader) ([]coverage.FileCoverage, error) {
+	lines := newLineCoverage()
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		matches := goCoverBlockRegex.FindStringSubmatch(line)
+		if matches == nil {
+			return nil, errors.Errorf("invalid coverprofile line '%s'", line)
+		}
+		start, _ := strconv.Atoi(matches[2])
+		end, _ := strconv.Atoi(matches[3])
+		covered := matches[4] != "0"
+		for l := start; l <= end; l++ {
+			lines.add(matches[1], l, covered)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "problem reading coverprofile")
+	}
+	return lines.export(), nil
+}
+
+type coberturaReport struct {
+	Packages []struct {
+		Classes []struct {
+			Filename string `xml:"filename,attr"`
+			Lines    []struct {
+				Number int   `xml:"number,attr"`
+				Hits   int64 `xml:"hits,attr"`
+			} `xml:"lines>line"`
+		} `xml:"classes>class"`
+	} `xml:"packages>package"`
+}
+
+// parseCoberturaReport reads a Cobertura XML report. File names are used as
+// the report gives them, which is usually relative to one of its sources.
+func parseCoberturaReport(r io.Reader) ([]coverage.FileCoverage, error) {
+	report := coberturaReport{}
+	if err := xml.NewDecoder(r).Decode(&report); err != nil {
+		return nil, errors.Wrap(err, "problem reading Cobertura report")
+	}
+
+	lines := newLineCoverage()
+	for _, pkg := range report.Packages {
+		for _, class := range pkg.Classes {
+			for _, line := range class.Lines {
+				lines.add(class.Filename, line.Number, line.Hits > 0)
+			}
+		}
+	}
+	return lines.export(), nil
+}
+
+// parseLCOVReport reads the line data of an LCOV tracefile.
+func parseLCOVReport(r io.Reader) ([]coverage.FileCoverage, error) {
+	lines := newLineCoverage()
+	file := ""
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "SF:"):
+			file = strings.TrimPrefix(line, "SF:")
+		case line == "end_of_record":
+			file = ""
+		case strings.HasPrefix(line, "DA:"):
+			if file == "" {
+				return nil, errors.Errorf("line data '%s' outside of a file record", line)
+			}
+			fields := strings.Split(strings.TrimPrefix(line, "DA:"), ",")
+			if len(fields) < 2 {
+				return nil, errors.Errorf("invalid line data '%s'", line)
+			}
+			number, err := strconv.Atoi(fields[0])
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid line number in '%s'", line)
+			}
+			hits, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid hit count in '%s'", line)
+			}
+			lines.add(file, number, hits > 0)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "problem reading LCOV report")
+	}
+	return lines.export(), nil
+}
@@ -0,0 +1,154 @@
+package command
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/coverage"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/rest/client"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/stretchr/testify/suite"
+)
+
+type CoverageSuite struct {
+	suite.Suite
+	conf   *model.TaskConfig
+	comm   *client.Mock
+	logger client.LoggerProducer
+	ctx    context.Context
+	cancel context.CancelFunc
+	tmpdir string
+}
+
+func TestCoverageSuite(t *testing.T) {
+	suite.Run(t, new(CoverageSuite))
+}
+
+func (s *CoverageSuite) SetupTest() {
+	var err error
+	s.tmpdir, err = ioutil.TempDir("", "evergreen.command.coverage.test")
+	s.Require().NoError(err)
+
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.comm = client.NewMock("http://localhost.com")
+	s.conf = &model.TaskConfig{
+		Expansions: util.NewExpansions(map[string]string{"module": "github.com/evergreen-ci/widgets/"}),
+		Task:       &task.Task{Id: "task", Secret: "secret"},
+		Project:    &model.Project{},
+		WorkDir:    s.tmpdir,
+	}
+	s.logger = s.comm.GetLoggerProducer(s.ctx, client.TaskData{ID: s.conf.Task.Id, Secret: s.conf.Task.Secret})
+
+	// the LCOV report names files by their absolute paths
+	for _, fn := range []string{"coverage.out", "cobertura.xml", "lcov.info"} {
+		data, err := ioutil.ReadFile(filepath.Join("testdata", "coverage", fn))
+		s.Require().NoError(err)
+		data = []byte(strings.Replace(string(data), "WORKDIR", filepath.ToSlash(s.tmpdir), -1))
+		s.Require().NoError(ioutil.WriteFile(filepath.Join(s.tmpdir, fn), data, 0644))
+	}
+}
+
+func (s *CoverageSuite) TearDownTest() {
+	s.cancel()
+	s.Require().NoError(os.RemoveAll(s.tmpdir))
+}
+
+func (s *CoverageSuite) parse(parse coverageParser, fn string) []coverage.FileCoverage {
+	f, err := os.Open(filepath.Join(s.tmpdir, fn))
+	s.Require().NoError(err)
+	defer f.Close()
+
+	files, err := parse(f)
+	s.Require().NoError(err)
+	return files
+}
+
+func (s *CoverageSuite) TestParseGoCoverProfile() {
+	files := s.parse(parseGoCoverProfile, "coverage.out")
+	s.Require().Len(files, 2)
+
+	s.Equal("github.com/evergreen-ci/widgets/size.go", files[0].File)
+	// the lines shared by a covered and an uncovered block are covered
+	s.Equal([]int{5, 6, 9}, files[0].Covered)
+	s.Equal([]int{7, 8}, files[0].Uncovered)
+
+	s.Equal("github.com/evergreen-ci/widgets/shape.go", files[1].File)
+	s.Empty(files[1].Covered)
+	s.Equal([]int{3, 4, 5}, files[1].Uncovered)
+
+	_, err := parseGoCoverProfile(strings.NewReader("mode: set\nnot a block\n"))
+	s.Error(err)
+}
+
+func (s *CoverageSuite) TestParseCobertura() {
+	files := s.parse(parseCoberturaReport, "cobertura.xml")
+	s.Require().Len(files, 2)
+
+	s.Equal("widgets/size.py", files[0].File)
+	s.Equal([]int{1, 2}, files[0].Covered)
+	s.Equal([]int{4}, files[0].Uncovered)
+
+	s.Equal("widgets/shape.py", files[1].File)
+	s.Equal([]int{6}, files[1].Covered)
+	s.Equal([]int{7}, files[1].Uncovered)
+}
+
+func (s *CoverageSuite) TestParseLCOV() {
+	files := s.parse(parseLCOVReport, "lcov.info")
+	s.Require().Len(files, 2)
+
+	s.Equal(filepath.ToSlash(filepath.Join(s.tmpdir, "src", "size.js")), files[0].File)
+	s.Equal([]int{1, 2}, files[0].Covered)
+	s.Equal([]int{3}, files[0].Uncovered)
+
+	s.Equal("lib/shape.js", files[1].File)
+	s.Equal([]int{11}, files[1].Covered)
+	s.Equal([]int{10}, files[1].Uncovered)
+
+	_, err := parseLCOVReport(strings.NewReader("DA:1,1\n"))
+	s.Error(err)
+	_, err = parseLCOVReport(strings.NewReader("SF:a.js\nDA:1,x\n"))
+	s.Error(err)
+}
+
+func (s *CoverageSuite) TestParseParams() {
+	cmd := attachCoverageFactory()
+	s.Error(cmd.ParseParams(map[string]interface{}{}))
+	s.Error(cmd.ParseParams(map[string]interface{}{"file": "coverage.out", "format": "jacoco"}))
+	s.NoError(cmd.ParseParams(map[string]interface{}{"file": "coverage.out", "format": "gocover"}))
+	s.NoError(cmd.ParseParams(map[string]interface{}{"file": "coverage.out", "format": "${format}"}))
+}
+
+func (s *CoverageSuite) TestExecuteDetectsFormats() {
+	cmd := attachCoverageFactory()
+	s.Require().NoError(cmd.ParseParams(map[string]interface{}{
+		"files":        []string{"coverage.out", "cobertura.xml", "lcov.info"},
+		"strip_prefix": "${module}",
+	}))
+	s.Require().NoError(cmd.Execute(s.ctx, s.comm, s.logger, s.conf))
+
+	files := s.comm.Coverage[s.conf.Task.Id]
+	names := []string{}
+	for _, f := range files {
+		names = append(names, f.File)
+	}
+	s.Equal([]string{"lib/shape.js", "shape.go", "size.go", "src/size.js", "widgets/shape.py", "widgets/size.py"}, names)
+}
+
+func (s *CoverageSuite) TestExecuteWithFormat() {
+	cmd := attachCoverageFactory()
+	s.Require().NoError(cmd.ParseParams(map[string]interface{}{"file": "lcov.info", "format": "gocover"}))
+	s.Error(cmd.Execute(s.ctx, s.comm, s.logger, s.conf))
+	s.Empty(s.comm.Coverage)
+
+	s.Require().NoError(ioutil.WriteFile(filepath.Join(s.tmpdir, "unknown.txt"), []byte("hello"), 0644))
+	cmd = attachCoverageFactory()
+	s.Require().NoError(cmd.ParseParams(map[string]interface{}{"file": "unknown.txt"}))
+	s.Error(cmd.Execute(s.ctx, s.comm, s.logger, s.conf))
+}
@@ -0,0 +1,91 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/rest/client"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// generateTasks sends project files that the task wrote to the API
+// server, which adds the functions, tasks, build variants and display
+// tasks in them to the task's version. Files ending in .json are JSON,
+// and all other files are YAML.
+type generateTasks struct {
+	reportFiles `mapstructure:",squash" plugin:"expand"`
+	base
+}
+
+func generateTasksFactory() Command   { return &generateTasks{} }
+func (c *generateTasks) Name() string { return "generate.tasks" }
+
+func (c *generateTasks) ParseParams(params map[string]interface{}) error {
+	if err := mapstructure.Decode(params, c); err != nil {
+		return errors.Wrapf(err, "error decoding '%s' params", c.Name())
+	}
+
+	return errors.Wrapf(c.validate(), "error validating '%s' params", c.Name())
+}
+
+func (c *generateTasks) Execute(ctx context.Context,
+	comm client.Communicator, logger client.LoggerProducer, conf *model.TaskConfig) error {
+
+	if err := util.ExpandValues(c, conf.Expansions); err != nil {
+		return errors.Wrap(err, "problem expanding params")
+	}
+	paths, err := c.paths(conf.WorkDir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	files := []string{}
+	for _, fn := range paths {
+		if ctx.Err() != nil {
+			return errors.New("operation canceled")
+		}
+
+		data, err := readGeneratedFile(fn)
+		if err != nil {
+			return errors.Wrapf(err, "problem reading generated file '%s'", fn)
+		}
+		logger.Task().Infof("Generating tasks from '%s'", fn)
+		files = append(files, data)
+	}
+
+	td := client.TaskData{ID: conf.Task.Id, Secret: conf.Task.Secret}
+	if err := comm.GenerateTasks(ctx, td, files); err != nil {
+		return errors.Wrap(err, "problem generating tasks")
+	}
+	logger.Task().Info("Generate tasks succeeded")
+	return nil
+}
+
+// readGeneratedFile returns the YAML in the file, converting it from JSON
+// if it's a JSON file, since not all JSON is valid YAML.
+func readGeneratedFile(fn string) (string, error) {
+	data, err := ioutil.ReadFile(fn)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if strings.ToLower(filepath.Ext(fn)) != ".json" {
+		return string(data), nil
+	}
+
+	var generated interface{}
+	if err = json.Unmarshal(data, &generated); err != nil {
+		return "", errors.Wrap(err, "file contains invalid JSON")
+	}
+	data, err = yaml.Marshal(generated)
+	if err != nil {
+		return "", errors.Wrap(err, "problem converting JSON to YAML")
+	}
+	return string(data), nil
+}
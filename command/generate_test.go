@@ -0,0 +1,93 @@
+package command
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/rest/client"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/stretchr/testify/suite"
+)
+
+type GenerateTasksSuite struct {
+	suite.Suite
+	conf   *model.TaskConfig
+	comm   *client.Mock
+	logger client.LoggerProducer
+	ctx    context.Context
+	cancel context.CancelFunc
+	tmpdir string
+}
+
+func TestGenerateTasksSuite(t *testing.T) {
+	suite.Run(t, new(GenerateTasksSuite))
+}
+
+func (s *GenerateTasksSuite) SetupTest() {
+	var err error
+	s.tmpdir, err = ioutil.TempDir("", "evergreen.command.generate.test")
+	s.Require().NoError(err)
+
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.comm = client.NewMock("http://localhost.com")
+	s.conf = &model.TaskConfig{
+		Expansions: util.NewExpansions(map[string]string{"shards": "shards"}),
+		Task:       &task.Task{Id: "task", Secret: "secret"},
+		Project:    &model.Project{},
+		WorkDir:    s.tmpdir,
+	}
+	s.logger = s.comm.GetLoggerProducer(s.ctx, client.TaskData{ID: s.conf.Task.Id, Secret: s.conf.Task.Secret})
+
+	s.Require().NoError(os.Mkdir(filepath.Join(s.tmpdir, "shards"), 0755))
+	s.Require().NoError(ioutil.WriteFile(filepath.Join(s.tmpdir, "shards", "tasks.json"),
+		[]byte(`{"tasks": [{"name": "shard_1", "commands": [{"command": "shell.exec", "params": {"script": "echo a\/b"}}]}]}`), 0644))
+	s.Require().NoError(ioutil.WriteFile(filepath.Join(s.tmpdir, "shards", "variants.yml"),
+		[]byte("buildvariants:\n- name: linux\n  tasks: [shard_1]\n"), 0644))
+}
+
+func (s *GenerateTasksSuite) TearDownTest() {
+	s.cancel()
+	s.Require().NoError(os.RemoveAll(s.tmpdir))
+}
+
+func (s *GenerateTasksSuite) TestParseParams() {
+	cmd := generateTasksFactory()
+	s.Error(cmd.ParseParams(map[string]interface{}{}))
+	s.NoError(cmd.ParseParams(map[string]interface{}{"files": []string{"*.json"}}))
+}
+
+func (s *GenerateTasksSuite) TestExecute() {
+	cmd := generateTasksFactory()
+	s.Require().NoError(cmd.ParseParams(map[string]interface{}{
+		"files": []string{"${shards}/*.json", "${shards}/*.yml"},
+	}))
+	s.Require().NoError(cmd.Execute(s.ctx, s.comm, s.logger, s.conf))
+
+	files := s.comm.Generated[s.conf.Task.Id]
+	s.Require().Len(files, 2)
+	generated, err := model.ParseGeneratedProject(files)
+	s.Require().NoError(err)
+	s.Require().Len(generated.Tasks, 1)
+	s.Equal("shard_1", generated.Tasks[0].Name)
+	s.Equal("echo a/b", generated.Tasks[0].Commands[0].Params["script"])
+	s.Len(generated.BuildVariants, 1)
+}
+
+func (s *GenerateTasksSuite) TestExecuteWithInvalidJSON() {
+	s.Require().NoError(ioutil.WriteFile(filepath.Join(s.tmpdir, "shards", "bad.json"), []byte("{"), 0644))
+	cmd := generateTasksFactory()
+	s.Require().NoError(cmd.ParseParams(map[string]interface{}{"file": "shards/bad.json"}))
+	s.Error(cmd.Execute(s.ctx, s.comm, s.logger, s.conf))
+	s.Empty(s.comm.Generated)
+}
+
+func (s *GenerateTasksSuite) TestExecuteWithoutFiles() {
+	cmd := generateTasksFactory()
+	s.Require().NoError(cmd.ParseParams(map[string]interface{}{"file": "missing/*.json"}))
+	s.Error(cmd.Execute(s.ctx, s.comm, s.logger, s.conf))
+}
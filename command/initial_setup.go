@@ -18,6 +18,8 @@ func (*initialSetup) SetDisplayName(s string)                         {}
 func (*initialSetup) Name() string                                    { return "setup.initial" }
 func (*initialSetup) SetIdleTimeout(d time.Duration)                  {}
 func (*initialSetup) IdleTimeout() time.Duration                      { return 0 }
+func (*initialSetup) Conditions() (string, string)                    { return "", "" }
+func (*initialSetup) SetConditions(string, string)                    {}
 func (*initialSetup) ParseParams(params map[string]interface{}) error { return nil }
 func (*initialSetup) Execute(ctx context.Context,
 	client client.Communicator, logger client.LoggerProducer, conf *model.TaskConfig) error {
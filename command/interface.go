@@ -37,6 +37,11 @@ type Command interface {
 
 	IdleTimeout() time.Duration
 	SetIdleTimeout(time.Duration)
+
+	// Conditions reports the if and unless expressions that decide
+	// whether the command runs.
+	Conditions() (string, string)
+	SetConditions(string, string)
 }
 
 // base contains a basic implementation of functionality that is
@@ -45,6 +50,8 @@ type base struct {
 	idleTimeout time.Duration
 	typeName    string
 	displayName string
+	ifCondition string
+	unless      string
 	mu          sync.RWMutex
 }
 
@@ -91,3 +98,18 @@ func (b *base) IdleTimeout() time.Duration {
 
 	return b.idleTimeout
 }
+
+func (b *base) Conditions() (string, string) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.ifCondition, b.unless
+}
+
+func (b *base) SetConditions(ifCondition, unless string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.ifCondition = ifCondition
+	b.unless = unless
+}
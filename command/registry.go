@@ -17,28 +17,36 @@ func init() {
 	evgRegistry = newCommandRegistry()
 
 	cmds := map[string]CommandFactory{
-		"archive.targz_pack":    tarballCreateFactory,
-		"attach.results":        attachResultsFactory,
-		"attach.xunit_results":  xunitResultsFactory,
-		"attach.artifacts":      attachArtifactsFactory,
-		"expansions.fetch_vars": fetchVarsFactory,
-		"expansions.update":     updateExpansionsFactory,
-		"git.apply_patch":       gitApplyPatchFactory,
-		"git.get_project":       gitFetchProjectFactory,
-		"gotest.parse_files":    goTestFactory,
-		"json.get":              taskDataGetFactory,
-		"json.get_history":      taskDataHistoryFactory,
-		"json.send":             taskDataSendFactory,
-		"keyval.inc":            keyValIncFactory,
-		"manifest.load":         manifestLoadFactory,
-		"s3.get":                s3GetFactory,
-		"s3.put":                s3PutFactory,
-		"s3Copy.copy":           s3CopyFactory,
-		"shell.cleanup":         shellCleanupFactory,
-		"shell.exec":            shellExecFactory,
-		"shell.track":           shellTrackFactory,
-		"simple.exec":           simpleExecFactory,
-		"setup.initial":         initialSetupFactory,
+		"archive.targz_pack":      tarballCreateFactory,
+		"attach.results":          attachResultsFactory,
+		"attach.xunit_results":    xunitResultsFactory,
+		"attach.artifacts":        attachArtifactsFactory,
+		"attach.coverage":         attachCoverageFactory,
+		"attach.cucumber_results": cucumberResultsFactory,
+		"attach.pytest_results":   pytestResultsFactory,
+		"attach.tap_results":      tapResultsFactory,
+		"cache.restore":           cacheRestoreFactory,
+		"cache.save":              cacheSaveFactory,
+		"expansions.fetch_vars":   fetchVarsFactory,
+		"expansions.update":       updateExpansionsFactory,
+		"generate.tasks":          generateTasksFactory,
+		"git.apply_patch":         gitApplyPatchFactory,
+		"git.get_project":         gitFetchProjectFactory,
+		"gotest.parse_files":      goTestFactory,
+		"gotest.parse_json":       goTestJSONFactory,
+		"json.get":                taskDataGetFactory,
+		"json.get_history":        taskDataHistoryFactory,
+		"json.send":               taskDataSendFactory,
+		"keyval.inc":              keyValIncFactory,
+		"manifest.load":           manifestLoadFactory,
+		"s3.get":                  s3GetFactory,
+		"s3.put":                  s3PutFactory,
+		"s3Copy.copy":             s3CopyFactory,
+		"shell.cleanup":           shellCleanupFactory,
+		"shell.exec":              shellExecFactory,
+		"shell.track":             shellTrackFactory,
+		"simple.exec":             simpleExecFactory,
+		"setup.initial":           initialSetupFactory,
 	}
 
 	for name, factory := range cmds {
@@ -126,6 +134,11 @@ func (r *commandRegistry) renderCommands(commandInfo model.PluginCommandConf,
 		err    error
 	)
 
+	if commandInfo.IsParallel() {
+		return nil, errors.New("parallel blocks must be run by the agent " +
+			"and can not be rendered as a single command")
+	}
+
 	if name := commandInfo.Function; name != "" {
 		cmds, ok := funcs[name]
 		if !ok {
@@ -137,6 +150,11 @@ func (r *commandRegistry) renderCommands(commandInfo model.PluginCommandConf,
 						"function: '%s' referenced within '%s'", c.Function, name))
 					continue
 				}
+				if c.IsParallel() {
+					errs = append(errs, fmt.Sprintf("can not use a parallel block within a "+
+						"function: '%s'", name))
+					continue
+				}
 
 				// if no command specific type, use the function's command type
 				if c.Type == "" {
@@ -173,6 +191,7 @@ func (r *commandRegistry) renderCommands(commandInfo model.PluginCommandConf,
 		cmd.SetType(c.Type)
 		cmd.SetDisplayName(c.DisplayName)
 		cmd.SetIdleTimeout(time.Duration(c.TimeoutSecs) * time.Second)
+		cmd.SetConditions(c.If, c.Unless)
 
 		out = append(out, cmd)
 	}
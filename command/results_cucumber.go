@@ -0,0 +1,144 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/rest/client"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+// cucumberResults reads in Cucumber JSON reports and attaches each
+// scenario in them to the task as a test, with the steps it ran as its
+// log.
+type cucumberResults struct {
+	reportFiles `mapstructure:",squash" plugin:"expand"`
+	base
+}
+
+func cucumberResultsFactory() Command   { return &cucumberResults{} }
+func (c *cucumberResults) Name() string { return "attach.cucumber_results" }
+
+func (c *cucumberResults) ParseParams(params map[string]interface{}) error {
+	if err := mapstructure.Decode(params, c); err != nil {
+		return errors.Wrapf(err, "error decoding '%s' params", c.Name())
+	}
+
+	return errors.Wrapf(c.validate(), "error validating '%s' params", c.Name())
+}
+
+func (c *cucumberResults) Execute(ctx context.Context,
+	comm client.Communicator, logger client.LoggerProducer, conf *model.TaskConfig) error {
+
+	if err := util.ExpandValues(c, conf.Expansions); err != nil {
+		return errors.Wrap(err, "problem expanding params")
+	}
+	paths, err := c.paths(conf.WorkDir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return errors.WithStack(attachReportResults(ctx, conf, logger, comm, paths, parseCucumberResults))
+}
+
+type cucumberFeature struct {
+	URI      string            `json:"uri"`
+	Name     string            `json:"name"`
+	Elements []cucumberElement `json:"elements"`
+}
+
+type cucumberElement struct {
+	Name      string         `json:"name"`
+	Type      string         `json:"type"`
+	Line      int            `json:"line"`
+	StartTime time.Time      `json:"start_timestamp"`
+	Steps     []cucumberStep `json:"steps"`
+}
+
+type cucumberStep struct {
+	Keyword string `json:"keyword"`
+	Name    string `json:"name"`
+	Result  struct {
+		Status string `json:"status"`
+		// Duration is in nanoseconds.
+		Duration     int64  `json:"duration"`
+		ErrorMessage string `json:"error_message"`
+	} `json:"result"`
+}
+
+// parseCucumberResults reads the scenarios from a Cucumber JSON report.
+// A scenario fails if any of its steps fail, or are undefined or
+// ambiguous, and is skipped if none of its steps ran. Background steps
+// count towards the scenario that follows them.
+func parseCucumberResults(r io.Reader) ([]reportTest, error) {
+	features := []cucumberFeature{}
+	if err := json.NewDecoder(r).Decode(&features); err != nil {
+		return nil, errors.Wrap(err, "problem reading Cucumber report")
+	}
+
+	tests := []reportTest{}
+	for _, feature := range features {
+		featureName := feature.Name
+		if featureName == "" {
+			featureName = feature.URI
+		}
+		seen := map[string]bool{}
+		background := []cucumberStep{}
+
+		for _, element := range feature.Elements {
+			if element.Type == "background" {
+				background = element.Steps
+				continue
+			}
+
+			name := fmt.Sprintf("%s: %s", featureName, element.Name)
+			if seen[name] {
+				// scenario outlines repeat the scenario's name
+				name = fmt.Sprintf("%s (line %d)", name, element.Line)
+			}
+			seen[name] = true
+
+			test := reportTest{
+				Name:  name,
+				Start: element.StartTime,
+			}
+			failed, ran := false, false
+			for _, step := range append(background, element.Steps...) {
+				status := step.Result.Status
+				switch status {
+				case "failed", "undefined", "ambiguous":
+					failed = true
+				case "passed":
+					ran = true
+				}
+				duration := time.Duration(step.Result.Duration)
+				test.Duration += duration
+				test.Output = append(test.Output, fmt.Sprintf("%s%s ... %s (%s)",
+					step.Keyword, step.Name, status, duration))
+				if step.Result.ErrorMessage != "" {
+					test.Output = append(test.Output, strings.Split(strings.TrimSpace(step.Result.ErrorMessage), "\n")...)
+				}
+			}
+			background = []cucumberStep{}
+
+			switch {
+			case failed:
+				test.Status = evergreen.TestFailedStatus
+			case ran:
+				test.Status = evergreen.TestSucceededStatus
+			default:
+				test.Status = evergreen.TestSkippedStatus
+			}
+			tests = append(tests, test)
+		}
+	}
+	return tests, nil
+}
@@ -0,0 +1,156 @@
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/rest/client"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+// goTestJSONResults reads in the event streams written by go test -json
+// (or go tool test2json) and attaches the tests in them to the task. Unlike
+// gotest.parse_files, it follows subtests and parallel tests, since every
+// event names its test.
+type goTestJSONResults struct {
+	reportFiles `mapstructure:",squash" plugin:"expand"`
+	base
+}
+
+func goTestJSONFactory() Command          { return &goTestJSONResults{} }
+func (c *goTestJSONResults) Name() string { return "gotest.parse_json" }
+
+func (c *goTestJSONResults) ParseParams(params map[string]interface{}) error {
+	if err := mapstructure.Decode(params, c); err != nil {
+		return errors.Wrapf(err, "error decoding '%s' params", c.Name())
+	}
+
+	return errors.Wrapf(c.validate(), "error validating '%s' params", c.Name())
+}
+
+func (c *goTestJSONResults) Execute(ctx context.Context,
+	comm client.Communicator, logger client.LoggerProducer, conf *model.TaskConfig) error {
+
+	if err := util.ExpandValues(c, conf.Expansions); err != nil {
+		return errors.Wrap(err, "problem expanding params")
+	}
+	paths, err := c.paths(conf.WorkDir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return errors.WithStack(attachReportResults(ctx, conf, logger, comm, paths, parseGoTestJSONResults))
+}
+
+// goTestEvent is an event written by test2json.
+type goTestEvent struct {
+	Time    time.Time `json:"Time"`
+	Action  string    `json:"Action"`
+	Package string    `json:"Package"`
+	Test    string    `json:"Test"`
+	Elapsed float64   `json:"Elapsed"`
+	Output  string    `json:"Output"`
+}
+
+// parseGoTestJSONResults reads the tests from a test2json event stream.
+// Tests are named as they are by gotest.parse_files, so that their history
+// carries over. Tests that start but never finish, because the test binary
+// panicked or timed out, are failures, as are packages that fail without
+// any failing tests, e.g. because they don't build.
+func parseGoTestJSONResults(r io.Reader) ([]reportTest, error) {
+	tests := []*reportTest{}
+	// the tests by package and name, and the output of each package
+	byName := map[string]map[string]*reportTest{}
+	pkgOutput := map[string][]string{}
+	pkgFailed := map[string]bool{}
+	pkgOrder := []string{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 || line[0] != '{' {
+			// go test writes build failures as plain text
+			continue
+		}
+		event := goTestEvent{}
+		if err := json.Unmarshal(line, &event); err != nil {
+			return nil, errors.Wrapf(err, "problem reading test event '%s'", line)
+		}
+
+		if _, ok := byName[event.Package]; !ok {
+			byName[event.Package] = map[string]*reportTest{}
+			pkgOrder = append(pkgOrder, event.Package)
+		}
+		if event.Test == "" {
+			switch event.Action {
+			case "output":
+				pkgOutput[event.Package] = append(pkgOutput[event.Package], strings.TrimSuffix(event.Output, "\n"))
+			case "fail":
+				pkgFailed[event.Package] = true
+			}
+			continue
+		}
+
+		test, ok := byName[event.Package][event.Test]
+		if !ok || event.Action == "run" && test.Status != "" {
+			// a test that runs again, e.g. with -count, is a new result
+			test = &reportTest{Name: event.Test, Start: event.Time}
+			byName[event.Package][event.Test] = test
+			tests = append(tests, test)
+		}
+
+		switch event.Action {
+		case "output":
+			test.Output = append(test.Output, strings.TrimSuffix(event.Output, "\n"))
+		case "pass":
+			test.Status = evergreen.TestSucceededStatus
+		case "fail":
+			test.Status = evergreen.TestFailedStatus
+		case "skip":
+			test.Status = evergreen.TestSkippedStatus
+		}
+		if event.Elapsed > 0 {
+			test.Duration = time.Duration(event.Elapsed * float64(time.Second))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "problem reading test events")
+	}
+
+	out := []reportTest{}
+	pkgTestFailed := map[string]bool{}
+	for _, pkg := range pkgOrder {
+		for _, test := range byName[pkg] {
+			if test.Status == "" {
+				test.Status = evergreen.TestFailedStatus
+				test.Output = append(test.Output, pkgOutput[pkg]...)
+			}
+			if test.Status == evergreen.TestFailedStatus {
+				pkgTestFailed[pkg] = true
+			}
+		}
+	}
+	for _, test := range tests {
+		out = append(out, *test)
+	}
+	for _, pkg := range pkgOrder {
+		if pkgFailed[pkg] && !pkgTestFailed[pkg] {
+			out = append(out, reportTest{
+				Name:   pkg,
+				Status: evergreen.TestFailedStatus,
+				Output: pkgOutput[pkg],
+			})
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,126 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/rest/client"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+// pytestResults reads in the reports written by pytest-json-report and
+// attaches the tests in them to the task, with the output and failure
+// details of each stage of a test as its log.
+type pytestResults struct {
+	reportFiles `mapstructure:",squash" plugin:"expand"`
+	base
+}
+
+func pytestResultsFactory() Command   { return &pytestResults{} }
+func (c *pytestResults) Name() string { return "attach.pytest_results" }
+
+func (c *pytestResults) ParseParams(params map[string]interface{}) error {
+	if err := mapstructure.Decode(params, c); err != nil {
+		return errors.Wrapf(err, "error decoding '%s' params", c.Name())
+	}
+
+	return errors.Wrapf(c.validate(), "error validating '%s' params", c.Name())
+}
+
+func (c *pytestResults) Execute(ctx context.Context,
+	comm client.Communicator, logger client.LoggerProducer, conf *model.TaskConfig) error {
+
+	if err := util.ExpandValues(c, conf.Expansions); err != nil {
+		return errors.Wrap(err, "problem expanding params")
+	}
+	paths, err := c.paths(conf.WorkDir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return errors.WithStack(attachReportResults(ctx, conf, logger, comm, paths, parsePytestResults))
+}
+
+type pytestReport struct {
+	Tests []pytestTest `json:"tests"`
+}
+
+type pytestTest struct {
+	NodeId   string       `json:"nodeid"`
+	Outcome  string       `json:"outcome"`
+	Setup    *pytestStage `json:"setup"`
+	Call     *pytestStage `json:"call"`
+	Teardown *pytestStage `json:"teardown"`
+}
+
+type pytestStage struct {
+	// Duration is in seconds.
+	Duration float64 `json:"duration"`
+	Outcome  string  `json:"outcome"`
+	Longrepr string  `json:"longrepr"`
+	Stdout   string  `json:"stdout"`
+	Stderr   string  `json:"stderr"`
+}
+
+// parsePytestResults reads the tests from a pytest-json-report report.
+// Tests that fail as expected (xfailed), or pass when they were expected
+// to fail (xpassed), do not fail the task, as with pytest itself.
+func parsePytestResults(r io.Reader) ([]reportTest, error) {
+	report := pytestReport{}
+	if err := json.NewDecoder(r).Decode(&report); err != nil {
+		return nil, errors.Wrap(err, "problem reading pytest report")
+	}
+
+	tests := []reportTest{}
+	for _, t := range report.Tests {
+		test := reportTest{Name: t.NodeId}
+		switch t.Outcome {
+		case "passed", "xfailed", "xpassed":
+			test.Status = evergreen.TestSucceededStatus
+		case "skipped":
+			test.Status = evergreen.TestSkippedStatus
+		default:
+			test.Status = evergreen.TestFailedStatus
+		}
+
+		stages := []struct {
+			name  string
+			stage *pytestStage
+		}{{"setup", t.Setup}, {"call", t.Call}, {"teardown", t.Teardown}}
+		for _, s := range stages {
+			if s.stage == nil {
+				continue
+			}
+			test.Duration += time.Duration(s.stage.Duration * float64(time.Second))
+			test.Output = append(test.Output, s.stage.lines(s.name)...)
+		}
+		tests = append(tests, test)
+	}
+	return tests, nil
+}
+
+// lines returns the captured output and failure details of a stage.
+func (s *pytestStage) lines(name string) []string {
+	lines := []string{}
+	sections := []struct {
+		title   string
+		content string
+	}{{"stdout", s.Stdout}, {"stderr", s.Stderr}, {s.Outcome, s.Longrepr}}
+	for _, section := range sections {
+		content := strings.TrimRight(section.content, "\n")
+		if content == "" {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("----- %s %s -----", name, section.title))
+		lines = append(lines, strings.Split(content, "\n")...)
+	}
+	return lines
+}
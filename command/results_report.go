@@ -0,0 +1,144 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/rest/client"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/pkg/errors"
+)
+
+// maxReportTestLogLines caps the output uploaded as the log of a single
+// test from a report. The end of the output, where failures usually are,
+// is kept.
+const maxReportTestLogLines = 1000
+
+// reportTest is a test read from a test report, along with the lines of
+// output to upload as its log.
+type reportTest struct {
+	Name   string
+	Status string
+	// Start is when the test started, if the report says.
+	Start    time.Time
+	Duration time.Duration
+	Output   []string
+}
+
+// reportParser reads the tests from a test report.
+type reportParser func(io.Reader) ([]reportTest, error)
+
+// reportFiles are the parameters shared by the commands that attach the
+// results from test reports.
+type reportFiles struct {
+	// File and Files are the report files to read, relative to the
+	// working directory. Both support globbing.
+	File  string   `mapstructure:"file" plugin:"expand"`
+	Files []string `mapstructure:"files" plugin:"expand"`
+}
+
+func (r *reportFiles) validate() error {
+	if r.File == "" && len(r.Files) == 0 {
+		return errors.New("must specify at least one file")
+	}
+	return nil
+}
+
+// paths returns the report files that match the parameters.
+func (r *reportFiles) paths(workDir string) ([]string, error) {
+	files := r.Files
+	if r.File != "" {
+		files = append([]string{r.File}, files...)
+	}
+	paths, err := getFilePaths(workDir, files)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(paths) == 0 {
+		return nil, errors.Errorf("no files found matching %v", files)
+	}
+	return paths, nil
+}
+
+// attachReportResults parses the report files and sends their tests to
+// the API server, each with a log of its output.
+func attachReportResults(ctx context.Context, conf *model.TaskConfig, logger client.LoggerProducer,
+	comm client.Communicator, paths []string, parse reportParser) error {
+
+	tests := []reportTest{}
+	for _, path := range paths {
+		if ctx.Err() != nil {
+			return errors.New("operation canceled")
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return errors.Wrapf(err, "couldn't open report file '%s'", path)
+		}
+		parsed, err := parse(f)
+		_ = f.Close()
+		if err != nil {
+			return errors.Wrapf(err, "problem parsing report file '%s'", path)
+		}
+		logger.Task().Infof("Found %d tests in '%s'", len(parsed), path)
+		tests = append(tests, parsed...)
+	}
+
+	td := client.TaskData{ID: conf.Task.Id, Secret: conf.Task.Secret}
+	results := &task.LocalTestResults{}
+	for _, test := range tests {
+		if ctx.Err() != nil {
+			return errors.New("operation canceled")
+		}
+
+		res := test.toModelTestResult()
+		if len(test.Output) > 0 {
+			log := &model.TestLog{
+				Name:          res.TestFile,
+				Task:          conf.Task.Id,
+				TaskExecution: conf.Task.Execution,
+				Lines:         reportLogExcerpt(test.Output),
+			}
+			logId, err := sendJSONLogs(ctx, logger, comm, td, log)
+			if err != nil {
+				logger.Task().Warningf("problem uploading logs for %s: %v", res.TestFile, err)
+			} else {
+				res.LogId = logId
+				res.LineNum = 1
+			}
+		}
+		results.Results = append(results.Results, res)
+	}
+
+	return errors.WithStack(sendJSONResults(ctx, conf, logger, comm, results))
+}
+
+func (t reportTest) toModelTestResult() task.TestResult {
+	start := t.Start
+	if util.IsZeroTime(start) {
+		start = time.Now()
+	}
+	startTime := float64(start.UnixNano()) / float64(time.Second)
+	return task.TestResult{
+		TestFile:  t.Name,
+		Status:    t.Status,
+		StartTime: startTime,
+		EndTime:   startTime + t.Duration.Seconds(),
+	}
+}
+
+// reportLogExcerpt returns the end of a test's output, if it is too long
+// to upload whole.
+func reportLogExcerpt(lines []string) []string {
+	if len(lines) <= maxReportTestLogLines {
+		return lines
+	}
+	omitted := len(lines) - maxReportTestLogLines
+	excerpt := []string{fmt.Sprintf("[%d earlier lines omitted]", omitted)}
+	return append(excerpt, lines[omitted:]...)
+}
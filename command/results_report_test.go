@@ -0,0 +1,264 @@
+package command
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/rest/client"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReportResultsSuite struct {
+	suite.Suite
+	conf   *model.TaskConfig
+	comm   *client.Mock
+	logger client.LoggerProducer
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func TestReportResultsSuite(t *testing.T) {
+	suite.Run(t, new(ReportResultsSuite))
+}
+
+func (s *ReportResultsSuite) SetupTest() {
+	wd, err := os.Getwd()
+	s.Require().NoError(err)
+
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.comm = client.NewMock("http://localhost.com")
+	s.conf = &model.TaskConfig{
+		Expansions: util.NewExpansions(map[string]string{"report_dir": "results"}),
+		Task:       &task.Task{Id: "task", Secret: "secret"},
+		Project:    &model.Project{},
+		WorkDir:    filepath.Join(wd, "testdata"),
+	}
+	s.logger = s.comm.GetLoggerProducer(s.ctx, client.TaskData{ID: s.conf.Task.Id, Secret: s.conf.Task.Secret})
+}
+
+func (s *ReportResultsSuite) TearDownTest() {
+	s.cancel()
+}
+
+func (s *ReportResultsSuite) parse(parse reportParser, file string) []reportTest {
+	f, err := os.Open(filepath.Join(s.conf.WorkDir, "results", file))
+	s.Require().NoError(err)
+	defer f.Close()
+
+	tests, err := parse(f)
+	s.Require().NoError(err)
+	return tests
+}
+
+func (s *ReportResultsSuite) TestParamsRequireFiles() {
+	for _, factory := range []CommandFactory{tapResultsFactory, goTestJSONFactory, cucumberResultsFactory, pytestResultsFactory} {
+		s.Error(factory().ParseParams(map[string]interface{}{}))
+		s.NoError(factory().ParseParams(map[string]interface{}{"file": "report.json"}))
+		s.NoError(factory().ParseParams(map[string]interface{}{"files": []string{"*.json"}}))
+	}
+}
+
+func (s *ReportResultsSuite) TestParseTAP() {
+	tests := s.parse(parseTAPResults, "tap.txt")
+	s.Require().Len(tests, 5)
+
+	s.Equal("parses the config", tests[0].Name)
+	s.Equal(evergreen.TestSucceededStatus, tests[0].Status)
+	s.Equal([]string{"ok 1 - parses the config"}, tests[0].Output)
+
+	s.Equal("rejects an empty name", tests[1].Name)
+	s.Equal(evergreen.TestFailedStatus, tests[1].Status)
+	s.Equal(12500*time.Microsecond, tests[1].Duration)
+	s.Len(tests[1].Output, 9)
+	s.Contains(tests[1].Output, "  message: 'expected an error'")
+
+	s.Equal("connects to the server", tests[2].Name)
+	s.Equal(evergreen.TestSucceededStatus, tests[2].Status)
+	s.Equal(250*time.Millisecond, tests[2].Duration)
+	s.Equal("# Subtest: connects to the server", tests[2].Output[0])
+	s.Contains(tests[2].Output, "    not ok 2 - authenticates # TODO not implemented yet")
+
+	s.Equal("test 4", tests[3].Name)
+	s.Equal(evergreen.TestSkippedStatus, tests[3].Status)
+
+	s.Equal("writes the report", tests[4].Name)
+	s.Equal(evergreen.TestFailedStatus, tests[4].Status)
+	s.Equal("# expected 3 rows, got 2", tests[4].Output[1])
+}
+
+func (s *ReportResultsSuite) TestParseTAPBailOut() {
+	tests, err := parseTAPResults(strings.NewReader("1..3\nok 1 - first\nBail out! database is down\nok 2 - second\n"))
+	s.Require().NoError(err)
+	s.Require().Len(tests, 2)
+	s.Equal(evergreen.TestSucceededStatus, tests[0].Status)
+	s.Equal("Bail out!", tests[1].Name)
+	s.Equal(evergreen.TestFailedStatus, tests[1].Status)
+	s.Equal([]string{"Bail out! database is down"}, tests[1].Output)
+}
+
+func (s *ReportResultsSuite) TestParseGoTestJSON() {
+	tests := s.parse(parseGoTestJSONResults, "gotest.json")
+	s.Require().Len(tests, 7)
+
+	expected := []struct {
+		name   string
+		status string
+	}{
+		{"TestSize", evergreen.TestSucceededStatus},
+		{"TestShapes", evergreen.TestFailedStatus},
+		{"TestShapes/square", evergreen.TestSucceededStatus},
+		{"TestShapes/circle", evergreen.TestFailedStatus},
+		{"TestColor", evergreen.TestSkippedStatus},
+		{"TestHang", evergreen.TestFailedStatus},
+		{"example.com/widgets/broken", evergreen.TestFailedStatus},
+	}
+	for i, e := range expected {
+		s.Equal(e.name, tests[i].Name)
+		s.Equal(e.status, tests[i].Status, e.name)
+	}
+
+	// the output of parallel subtests is kept apart
+	s.Equal([]string{
+		"=== RUN   TestShapes/circle",
+		"=== PAUSE TestShapes/circle",
+		"=== CONT  TestShapes/circle",
+		"    widgets_test.go:19: circle has 1 corners",
+		"--- FAIL: TestShapes/circle (0.01s)",
+	}, tests[3].Output)
+	s.Equal(10*time.Millisecond, tests[2].Duration)
+	s.False(util.IsZeroTime(tests[2].Start))
+
+	// a test that never finishes gets the package's output
+	s.Contains(tests[5].Output, "panic: test timed out after 1m0s")
+	s.Contains(tests[6].Output, "FAIL\texample.com/widgets/broken [build failed]")
+}
+
+func (s *ReportResultsSuite) TestParseGoTestJSONRepeatedTests() {
+	events := `{"Action":"run","Package":"p","Test":"TestA"}
+{"Action":"pass","Package":"p","Test":"TestA","Elapsed":0.5}
+{"Action":"run","Package":"p","Test":"TestA"}
+{"Action":"fail","Package":"p","Test":"TestA","Elapsed":1}
+{"Action":"fail","Package":"p","Elapsed":1.5}
+`
+	tests, err := parseGoTestJSONResults(strings.NewReader(events))
+	s.Require().NoError(err)
+	s.Require().Len(tests, 2)
+	s.Equal(evergreen.TestSucceededStatus, tests[0].Status)
+	s.Equal(500*time.Millisecond, tests[0].Duration)
+	s.Equal(evergreen.TestFailedStatus, tests[1].Status)
+	s.Equal(time.Second, tests[1].Duration)
+
+	_, err = parseGoTestJSONResults(strings.NewReader("{not json}\n"))
+	s.Error(err)
+}
+
+func (s *ReportResultsSuite) TestParseCucumber() {
+	tests := s.parse(parseCucumberResults, "cucumber.json")
+	s.Require().Len(tests, 3)
+
+	s.Equal("Shopping cart: Adding an item", tests[0].Name)
+	s.Equal(evergreen.TestSucceededStatus, tests[0].Status)
+	s.Equal(6*time.Millisecond, tests[0].Duration)
+	s.Equal("2026-10-17T22:00:00Z", tests[0].Start.Format(time.RFC3339))
+	s.Equal([]string{
+		"Given an empty cart ... passed (1ms)",
+		"When I add a book ... passed (2ms)",
+		"Then the cart has 1 item ... passed (3ms)",
+	}, tests[0].Output)
+
+	s.Equal("Shopping cart: Removing an item", tests[1].Name)
+	s.Equal(evergreen.TestFailedStatus, tests[1].Status)
+	s.Equal(5*time.Millisecond, tests[1].Duration)
+	s.Contains(tests[1].Output, "expected 0 items")
+
+	s.Equal("Shopping cart: Removing an item (line 20)", tests[2].Name)
+	s.Equal(evergreen.TestSkippedStatus, tests[2].Status)
+
+	_, err := parseCucumberResults(strings.NewReader(`{"elements": []}`))
+	s.Error(err)
+}
+
+func (s *ReportResultsSuite) TestParsePytest() {
+	tests := s.parse(parsePytestResults, "pytest.json")
+	s.Require().Len(tests, 4)
+
+	s.Equal("tests/test_cart.py::test_add", tests[0].Name)
+	s.Equal(evergreen.TestSucceededStatus, tests[0].Status)
+	s.Equal(252*time.Millisecond, tests[0].Duration)
+	s.Empty(tests[0].Output)
+
+	s.Equal("tests/test_cart.py::test_remove[book]", tests[1].Name)
+	s.Equal(evergreen.TestFailedStatus, tests[1].Status)
+	s.Equal([]string{
+		"----- call stdout -----",
+		"removing book",
+		"----- call failed -----",
+		"def test_remove():",
+		">       assert cart.count == 0",
+		"E       assert 1 == 0",
+	}, tests[1].Output)
+
+	s.Equal(evergreen.TestSkippedStatus, tests[2].Status)
+	s.Equal("----- setup skipped -----", tests[2].Output[0])
+
+	// expected failures don't fail the task
+	s.Equal(evergreen.TestSucceededStatus, tests[3].Status)
+}
+
+func (s *ReportResultsSuite) TestReportLogExcerpt() {
+	lines := make([]string, maxReportTestLogLines+5)
+	for i := range lines {
+		lines[i] = strings.Repeat("x", i%10)
+	}
+	excerpt := reportLogExcerpt(lines)
+	s.Len(excerpt, maxReportTestLogLines+1)
+	s.Equal("[5 earlier lines omitted]", excerpt[0])
+	s.Equal(lines[len(lines)-1], excerpt[len(excerpt)-1])
+
+	s.Equal(lines[:10], reportLogExcerpt(lines[:10]))
+}
+
+func (s *ReportResultsSuite) TestExecuteAttachesResultsAndLogs() {
+	for _, test := range []struct {
+		factory CommandFactory
+		file    string
+		results int
+		logs    int
+	}{
+		{tapResultsFactory, "tap.txt", 5, 5},
+		{goTestJSONFactory, "gotest.json", 7, 7},
+		{cucumberResultsFactory, "cucumber.json", 3, 3},
+		{pytestResultsFactory, "pytest.json", 4, 3},
+	} {
+		s.comm = client.NewMock("http://localhost.com")
+		cmd := test.factory()
+		s.Require().NoError(cmd.ParseParams(map[string]interface{}{"file": "${report_dir}/" + test.file}))
+		s.Require().NoError(cmd.Execute(s.ctx, s.comm, s.logger, s.conf), test.file)
+
+		results := s.comm.TestResults[s.conf.Task.Id]
+		s.Len(results, test.results, test.file)
+		s.Len(s.comm.TestLogs[s.conf.Task.Id], test.logs, test.file)
+		for _, log := range s.comm.TestLogs[s.conf.Task.Id] {
+			s.Equal(s.conf.Task.Id, log.Task)
+			s.NotEmpty(log.Lines)
+		}
+		for _, res := range results {
+			s.True(res.EndTime >= res.StartTime, res.TestFile)
+		}
+	}
+}
+
+func (s *ReportResultsSuite) TestExecuteFailsWithoutMatchingFiles() {
+	cmd := tapResultsFactory()
+	s.Require().NoError(cmd.ParseParams(map[string]interface{}{"files": []string{"results/*.tap"}}))
+	s.Error(cmd.Execute(s.ctx, s.comm, s.logger, s.conf))
+	s.Empty(s.comm.TestResults)
+}
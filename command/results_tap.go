@@ -0,0 +1,162 @@
+package command
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/rest/client"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+var (
+	// matches a test point, saving the result, number, description and
+	// the comment after a #, which may hold a directive
+	tapTestRegex = regexp.MustCompile(`^(ok|not ok)\b\s*(\d+)?\s*(?:-\s*)?([^#]*?)\s*(?:#\s*(.*))?$`)
+	// matches the time comment some producers add to test points
+	tapTimeRegex = regexp.MustCompile(`^time=(\S+)`)
+	// matches a bail out line, saving the reason
+	tapBailOutRegex = regexp.MustCompile(`^Bail out!\s*(.*)$`)
+	// matches the duration in a YAML diagnostic block
+	tapDurationRegex = regexp.MustCompile(`^\s*duration_ms:\s*([0-9.]+)`)
+)
+
+// tapResults reads in TAP version 13 output and attaches the tests in it
+// to the task. The output following a test point, including its YAML
+// diagnostic block, becomes the test's log.
+type tapResults struct {
+	reportFiles `mapstructure:",squash" plugin:"expand"`
+	base
+}
+
+func tapResultsFactory() Command   { return &tapResults{} }
+func (c *tapResults) Name() string { return "attach.tap_results" }
+
+func (c *tapResults) ParseParams(params map[string]interface{}) error {
+	if err := mapstructure.Decode(params, c); err != nil {
+		return errors.Wrapf(err, "error decoding '%s' params", c.Name())
+	}
+
+	return errors.Wrapf(c.validate(), "error validating '%s' params", c.Name())
+}
+
+func (c *tapResults) Execute(ctx context.Context,
+	comm client.Communicator, logger client.LoggerProducer, conf *model.TaskConfig) error {
+
+	if err := util.ExpandValues(c, conf.Expansions); err != nil {
+		return errors.Wrap(err, "problem expanding params")
+	}
+	paths, err := c.paths(conf.WorkDir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return errors.WithStack(attachReportResults(ctx, conf, logger, comm, paths, parseTAPResults))
+}
+
+// parseTAPResults reads the test points of a TAP stream. Subtests, which
+// are indented and precede the test point that summarizes them, are
+// attached to that test point's log.
+func parseTAPResults(r io.Reader) ([]reportTest, error) {
+	tests := []reportTest{}
+	var current *reportTest
+	// the lines of a subtest, which belong to the next test point
+	subtest := []string{}
+	inYAML := false
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case inYAML:
+			if trimmed == "..." {
+				inYAML = false
+			} else if matches := tapDurationRegex.FindStringSubmatch(line); matches != nil && current != nil {
+				ms, err := strconv.ParseFloat(matches[1], 64)
+				if err == nil {
+					current.Duration = time.Duration(ms * float64(time.Millisecond))
+				}
+			}
+			if current != nil {
+				current.Output = append(current.Output, line)
+			}
+		case trimmed == "---" && current != nil && len(subtest) == 0:
+			inYAML = true
+			current.Output = append(current.Output, line)
+		case strings.HasPrefix(trimmed, "# Subtest") || line != trimmed && (len(subtest) > 0 || tapTestRegex.MatchString(trimmed)):
+			subtest = append(subtest, line)
+		case tapTestRegex.MatchString(line):
+			test := newTAPTest(tapTestRegex.FindStringSubmatch(line), len(tests)+1)
+			test.Output = append(subtest, line)
+			subtest = []string{}
+			tests = append(tests, test)
+			current = &tests[len(tests)-1]
+		case tapBailOutRegex.MatchString(line):
+			tests = append(tests, reportTest{
+				Name:   "Bail out!",
+				Status: evergreen.TestFailedStatus,
+				Output: append(subtest, line),
+			})
+			return tests, nil
+		default:
+			if len(subtest) > 0 {
+				subtest = append(subtest, line)
+			} else if current != nil && trimmed != "" && !strings.HasPrefix(trimmed, "TAP version") {
+				current.Output = append(current.Output, line)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "problem reading TAP output")
+	}
+	return tests, nil
+}
+
+// newTAPTest builds a test from the matches of a test point line.
+func newTAPTest(matches []string, position int) reportTest {
+	test := reportTest{Name: matches[3]}
+	if test.Name == "" {
+		number := matches[2]
+		if number == "" {
+			number = strconv.Itoa(position)
+		}
+		test.Name = fmt.Sprintf("test %s", number)
+	}
+
+	comment := strings.ToLower(matches[4])
+	if timeMatches := tapTimeRegex.FindStringSubmatch(comment); timeMatches != nil {
+		if duration, err := time.ParseDuration(timeMatches[1]); err == nil {
+			test.Duration = duration
+		}
+	}
+
+	passed := matches[1] == "ok"
+	switch {
+	case strings.HasPrefix(comment, "skip"):
+		test.Status = evergreen.TestSkippedStatus
+	case strings.HasPrefix(comment, "todo"):
+		// failing todo tests are expected to fail
+		test.Status = evergreen.TestSucceededStatus
+		if !passed {
+			test.Status = evergreen.TestSkippedStatus
+		}
+	default:
+		test.Status = evergreen.TestSucceededStatus
+		if !passed {
+			test.Status = evergreen.TestFailedStatus
+		}
+	}
+	return test
+}
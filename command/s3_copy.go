@@ -8,7 +8,9 @@ import (
 	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/artifact"
 	"github.com/evergreen-ci/evergreen/rest/client"
+	"github.com/evergreen-ci/evergreen/thirdparty"
 	"github.com/evergreen-ci/evergreen/util"
+	"github.com/goamz/goamz/s3"
 	"github.com/mitchellh/mapstructure"
 	"github.com/pkg/errors"
 )
@@ -23,6 +25,10 @@ type s3copy struct {
 
 	// An array of file copy configurations
 	S3CopyFiles []*s3CopyFile `mapstructure:"s3_copy_files" plugin:"expand"`
+
+	// The API server copies files within S3 and S3-compatible
+	// services; the local provider's files are copied by the agent.
+	blobStorageOptions `mapstructure:",squash" plugin:"expand"`
 	base
 }
 
@@ -80,12 +86,15 @@ func (c *s3copy) ParseParams(params map[string]interface{}) error {
 // validateParams is a helper function that ensures all
 // the fields necessary for carrying out an S3 copy operation are present
 func (c *s3copy) validateParams() error {
-	if c.AwsKey == "" {
+	if c.AwsKey == "" && !c.isLocal() {
 		return errors.New("s3 AWS key cannot be blank")
 	}
-	if c.AwsSecret == "" {
+	if c.AwsSecret == "" && !c.isLocal() {
 		return errors.New("s3 AWS secret cannot be blank")
 	}
+	if err := c.validateProvider(); err != nil {
+		return errors.WithStack(err)
+	}
 	for _, s3CopyFile := range c.S3CopyFiles {
 		if s3CopyFile.Source.Bucket == "" {
 			return errors.New("s3 source bucket cannot be blank")
@@ -100,13 +109,13 @@ func (c *s3copy) validateParams() error {
 			return errors.New("s3 destination path cannot be blank")
 		}
 
-		err := validateS3BucketName(s3CopyFile.Source.Bucket)
+		err := c.validateBucketName(s3CopyFile.Source.Bucket)
 		if err != nil {
 			return errors.Wrapf(err, "source bucket '%v' is invalid",
 				s3CopyFile.Source.Bucket)
 		}
 
-		err = validateS3BucketName(s3CopyFile.Destination.Bucket)
+		err = c.validateBucketName(s3CopyFile.Destination.Bucket)
 		if err != nil {
 			return errors.Wrapf(err, "destination bucket '%v' is invalid",
 				s3CopyFile.Destination.Bucket)
@@ -125,6 +134,7 @@ func (c *s3copy) Execute(ctx context.Context,
 	if err := util.ExpandValues(c, conf.Expansions); err != nil {
 		return errors.WithStack(err)
 	}
+	c.applyDefaults(conf.Expansions)
 
 	errChan := make(chan error)
 	go func() {
@@ -171,9 +181,21 @@ func (c *s3copy) s3Copy(ctx context.Context,
 			S3DestinationBucket: s3CopyFile.Destination.Bucket,
 			S3DestinationPath:   s3CopyFile.Destination.Path,
 			S3DisplayName:       s3CopyFile.DisplayName,
+			Provider:            c.Provider,
+			Endpoint:            c.Endpoint,
+			PathStyle:           c.PathStyle,
 		}
 
-		err := comm.S3Copy(ctx, td, &s3CopyReq)
+		destination, err := c.openBucket(conf, s3CopyFile.Destination.Bucket, c.AwsKey, c.AwsSecret)
+		if err != nil {
+			return errors.Wrapf(err, "problem opening bucket %s", s3CopyFile.Destination.Bucket)
+		}
+
+		if c.isLocal() {
+			err = c.copyLocal(conf, destination, s3CopyReq)
+		} else {
+			err = comm.S3Copy(ctx, td, &s3CopyReq)
+		}
 		if err != nil {
 			err = errors.Wrap(err, "s3 push copy failed")
 			logger.Execution().Error(err)
@@ -186,7 +208,7 @@ func (c *s3copy) s3Copy(ctx context.Context,
 
 		}
 
-		err = c.attachFiles(ctx, comm, logger, td, s3CopyReq)
+		err = c.attachFiles(ctx, comm, logger, td, destination, s3CopyReq)
 		if err != nil {
 			return errors.WithStack(err)
 		}
@@ -194,13 +216,28 @@ func (c *s3copy) s3Copy(ctx context.Context,
 	return nil
 }
 
+// copyLocal copies a file between two buckets of the local provider,
+// which the API server has no access to.
+func (c *s3copy) copyLocal(conf *model.TaskConfig, destination thirdparty.Bucket, request apimodels.S3CopyRequest) error {
+	source, err := c.openBucket(conf, request.S3SourceBucket, c.AwsKey, c.AwsSecret)
+	if err != nil {
+		return errors.Wrapf(err, "problem opening bucket %s", request.S3SourceBucket)
+	}
+
+	return errors.WithStack(thirdparty.CopyBlob(source, request.S3SourcePath,
+		destination, request.S3DestinationPath, thirdparty.PutOptions{
+			Permissions: string(s3.PublicRead),
+		}))
+}
+
 // AttachTaskFiles is responsible for sending the
 // specified file to the API Server
 func (c *s3copy) attachFiles(ctx context.Context, comm client.Communicator,
-	logger client.LoggerProducer, td client.TaskData, request apimodels.S3CopyRequest) error {
+	logger client.LoggerProducer, td client.TaskData, destination thirdparty.Bucket,
+	request apimodels.S3CopyRequest) error {
 
 	remotePath := filepath.ToSlash(request.S3DestinationPath)
-	fileLink := s3baseURL + request.S3DestinationBucket + "/" + remotePath
+	fileLink := destination.URL(remotePath)
 
 	displayName := request.S3DisplayName
 
@@ -13,7 +13,6 @@ import (
 	"github.com/evergreen-ci/evergreen/rest/client"
 	"github.com/evergreen-ci/evergreen/thirdparty"
 	"github.com/evergreen-ci/evergreen/util"
-	"github.com/goamz/goamz/aws"
 	"github.com/mitchellh/mapstructure"
 	"github.com/pkg/errors"
 )
@@ -32,6 +31,8 @@ type s3get struct {
 	// Bucket is the s3 bucket holding the desired file
 	Bucket string `mapstructure:"bucket" plugin:"expand"`
 
+	blobStorageOptions `mapstructure:",squash" plugin:"expand"`
+
 	// BuildVariants stores a list of MCI build variants to run the command for.
 	// If the list is empty, it runs for all build variants.
 	BuildVariants []string `mapstructure:"build_variants" plugin:"expand"`
@@ -43,6 +44,7 @@ type s3get struct {
 	LocalFile string `mapstructure:"local_file" plugin:"expand"`
 	ExtractTo string `mapstructure:"extract_to" plugin:"expand"`
 
+	bucket thirdparty.Bucket
 	base
 }
 
@@ -66,18 +68,21 @@ func (c *s3get) ParseParams(params map[string]interface{}) error {
 // Validate that all necessary params are set, and that only one of
 // local_file and extract_to is specified.
 func (c *s3get) validateParams() error {
-	if c.AwsKey == "" {
+	if c.AwsKey == "" && !c.isLocal() {
 		return errors.New("aws_key cannot be blank")
 	}
-	if c.AwsSecret == "" {
+	if c.AwsSecret == "" && !c.isLocal() {
 		return errors.New("aws_secret cannot be blank")
 	}
+	if err := c.validateProvider(); err != nil {
+		return errors.WithStack(err)
+	}
 	if c.RemoteFile == "" {
 		return errors.New("remote_file cannot be blank")
 	}
 
 	// make sure the bucket is valid
-	if err := validateS3BucketName(c.Bucket); err != nil {
+	if err := c.validateBucketName(c.Bucket); err != nil {
 		return errors.Wrapf(err, "%v is an invalid bucket name", c.Bucket)
 	}
 
@@ -106,7 +111,11 @@ func (c *s3get) shouldRunForVariant(buildVariantName string) bool {
 // Apply the expansions from the relevant task config to all appropriate
 // fields of the s3get.
 func (c *s3get) expandParams(conf *model.TaskConfig) error {
-	return util.ExpandValues(c, conf.Expansions)
+	if err := util.ExpandValues(c, conf.Expansions); err != nil {
+		return errors.WithStack(err)
+	}
+	c.applyDefaults(conf.Expansions)
+	return nil
 }
 
 // Implementation of Execute.  Expands the parameters, and then fetches the
@@ -152,6 +161,12 @@ func (c *s3get) Execute(ctx context.Context,
 		}
 	}
 
+	bucket, err := c.openBucket(conf, c.Bucket, c.AwsKey, c.AwsSecret)
+	if err != nil {
+		return errors.Wrapf(err, "problem opening bucket %s", c.Bucket)
+	}
+	c.bucket = bucket
+
 	errChan := make(chan error)
 	go func() {
 		errChan <- errors.WithStack(c.getWithRetry(ctx, logger))
@@ -197,20 +212,8 @@ func (c *s3get) getWithRetry(ctx context.Context, logger client.LoggerProducer)
 
 // Fetch the specified resource from s3.
 func (c *s3get) get(ctx context.Context) error {
-	// get the appropriate session and bucket
-	auth := &aws.Auth{
-		AccessKey: c.AwsKey,
-		SecretKey: c.AwsSecret,
-	}
-
-	client := util.GetHttpClient()
-	defer util.PutHttpClient(client)
-
-	session := thirdparty.NewS3Session(auth, aws.USEast, client)
-	bucket := session.Bucket(c.Bucket)
-
 	// get a reader for the bucket
-	reader, err := bucket.GetReader(c.RemoteFile)
+	reader, err := c.bucket.Get(c.RemoteFile)
 	if err != nil {
 		return errors.Wrapf(err, "error getting bucket reader for file %v", c.RemoteFile)
 	}
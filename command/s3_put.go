@@ -3,7 +3,6 @@ package command
 import (
 	"context"
 	"fmt"
-	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -14,7 +13,6 @@ import (
 	"github.com/evergreen-ci/evergreen/rest/client"
 	"github.com/evergreen-ci/evergreen/thirdparty"
 	"github.com/evergreen-ci/evergreen/util"
-	"github.com/goamz/goamz/aws"
 	"github.com/mitchellh/mapstructure"
 	"github.com/mongodb/grip"
 	"github.com/pkg/errors"
@@ -43,6 +41,8 @@ type s3put struct {
 	// Bucket is the s3 bucket to use when storing the desired file
 	Bucket string `mapstructure:"bucket" plugin:"expand"`
 
+	blobStorageOptions `mapstructure:",squash" plugin:"expand"`
+
 	// Permission is the ACL to apply to the uploaded file. See:
 	//  http://docs.aws.amazon.com/AmazonS3/latest/dev/acl-overview.html#canned-acl
 	// for some examples.
@@ -77,6 +77,7 @@ type s3put struct {
 	// workDir will be empty if an absolute path is provided to the file.
 	workDir string
 
+	bucket   thirdparty.Bucket
 	taskdata client.TaskData
 	base
 }
@@ -97,12 +98,13 @@ func (s3pc *s3put) validate() error {
 	catcher := grip.NewSimpleCatcher()
 
 	// make sure the command params are valid
-	if s3pc.AwsKey == "" {
+	if s3pc.AwsKey == "" && !s3pc.isLocal() {
 		catcher.Add(errors.New("aws_key cannot be blank"))
 	}
-	if s3pc.AwsSecret == "" {
+	if s3pc.AwsSecret == "" && !s3pc.isLocal() {
 		catcher.Add(errors.New("aws_secret cannot be blank"))
 	}
+	catcher.Add(s3pc.validateProvider())
 	if s3pc.LocalFile == "" && !s3pc.isMulti() {
 		catcher.Add(errors.New("local_file and local_files_include_filter cannot both be blank"))
 	}
@@ -127,7 +129,7 @@ func (s3pc *s3put) validate() error {
 	}
 
 	// make sure the bucket is valid
-	if err := validateS3BucketName(s3pc.Bucket); err != nil {
+	if err := s3pc.validateBucketName(s3pc.Bucket); err != nil {
 		catcher.Add(errors.Wrapf(err, "%v is an invalid bucket name", s3pc.Bucket))
 	}
 
@@ -148,7 +150,11 @@ func (s3pc *s3put) expandParams(conf *model.TaskConfig) error {
 		s3pc.workDir = conf.WorkDir
 	}
 
-	return errors.WithStack(util.ExpandValues(s3pc, conf.Expansions))
+	if err := util.ExpandValues(s3pc, conf.Expansions); err != nil {
+		return errors.WithStack(err)
+	}
+	s3pc.applyDefaults(conf.Expansions)
+	return nil
 }
 
 // isMulti returns whether or not this using the multiple file upload
@@ -183,6 +189,12 @@ func (s3pc *s3put) Execute(ctx context.Context,
 
 	s3pc.taskdata = client.TaskData{ID: conf.Task.Id, Secret: conf.Task.Secret}
 
+	bucket, err := s3pc.openBucket(conf, s3pc.Bucket, s3pc.AwsKey, s3pc.AwsSecret)
+	if err != nil {
+		return errors.Wrapf(err, "problem opening bucket %s", s3pc.Bucket)
+	}
+	s3pc.bucket = bucket
+
 	if !s3pc.shouldRunForVariant(conf.BuildVariant.Name) {
 		logger.Task().Infof("Skipping S3 put of local file %v for variant %v",
 			s3pc.LocalFile, conf.BuildVariant.Name)
@@ -216,11 +228,6 @@ func (s3pc *s3put) Execute(ctx context.Context,
 func (s3pc *s3put) putWithRetry(ctx context.Context, comm client.Communicator, logger client.LoggerProducer) error {
 	backoffCounter := getS3OpBackoff()
 
-	auth := &aws.Auth{
-		AccessKey: s3pc.AwsKey,
-		SecretKey: s3pc.AwsSecret,
-	}
-
 	var (
 		err           error
 		uploadedFiles []string
@@ -265,14 +272,11 @@ retryLoop:
 					remoteName = fmt.Sprintf("%s%s", s3pc.RemoteFile, fname)
 				}
 
-				s3URL := url.URL{
-					Scheme: "s3",
-					Host:   s3pc.Bucket,
-					Path:   remoteName,
-				}
-
 				fpath = filepath.Join(s3pc.workDir, fpath)
-				err := thirdparty.PutS3File(auth, fpath, s3URL.String(), s3pc.ContentType, s3pc.Permissions)
+				err := thirdparty.PutFile(s3pc.bucket, fpath, remoteName, thirdparty.PutOptions{
+					ContentType: s3pc.ContentType,
+					Permissions: s3pc.Permissions,
+				})
 				if err != nil {
 					// retry errors other than "file doesn't exist", which we handle differently based on what
 					// kind of upload it is
@@ -317,7 +321,7 @@ func (s3pc *s3put) attachFiles(ctx context.Context, comm client.Communicator, lo
 			remoteFileName = fmt.Sprintf("%s%s", remoteFile, filepath.Base(fn))
 		}
 
-		fileLink := s3baseURL + s3pc.Bucket + "/" + remoteFileName
+		fileLink := s3pc.bucket.URL(remoteFileName)
 
 		displayName := s3pc.ResourceDisplayName
 		if s3pc.isMulti() || displayName == "" {
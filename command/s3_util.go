@@ -1,21 +1,25 @@
 package command
 
 import (
-	"errors"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
 
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/thirdparty"
 	"github.com/evergreen-ci/evergreen/util"
+	"github.com/goamz/goamz/aws"
 	"github.com/goamz/goamz/s3"
 	"github.com/jpillora/backoff"
+	"github.com/pkg/errors"
 )
 
 const (
 	maxS3OpAttempts   = 10
 	s3OpSleep         = 2 * time.Second
 	s3OpRetryMaxSleep = 20 * time.Second
-	s3baseURL         = "https://s3.amazonaws.com/"
 )
 
 var (
@@ -73,3 +77,90 @@ func getS3OpBackoff() *backoff.Backoff {
 		Jitter: true,
 	}
 }
+
+// blobStorageOptions select the storage backend of the commands that
+// store files in buckets. Left blank, they default to the blob storage
+// settings of the admin, which the agent receives as expansions, and
+// then to S3.
+type blobStorageOptions struct {
+	// Provider is one of s3, s3-compatible or local. The local provider
+	// treats bucket names as directories, relative to the admin's
+	// local root or else to the working directory.
+	Provider string `mapstructure:"provider" plugin:"expand"`
+
+	// Endpoint is the URL of the s3-compatible service, and PathStyle
+	// addresses its buckets by path rather than by host name, as MinIO
+	// and Ceph commonly require.
+	Endpoint  string `mapstructure:"endpoint" plugin:"expand"`
+	PathStyle bool   `mapstructure:"path_style"`
+}
+
+// isLocal returns whether the files are stored on the local filesystem,
+// where no credentials are needed.
+func (o *blobStorageOptions) isLocal() bool {
+	return o.Provider == evergreen.BlobProviderLocal
+}
+
+func (o *blobStorageOptions) validateProvider() error {
+	switch o.Provider {
+	case "", evergreen.BlobProviderS3, evergreen.BlobProviderLocal:
+	case evergreen.BlobProviderS3Compatible:
+		if o.Endpoint == "" {
+			return errors.New("endpoint cannot be blank for the s3-compatible provider")
+		}
+	default:
+		if !util.IsExpandable(o.Provider) {
+			return errors.Errorf("unknown provider '%s'", o.Provider)
+		}
+	}
+	return nil
+}
+
+// validateBucketName checks a bucket name, which for the local provider
+// is a directory.
+func (o *blobStorageOptions) validateBucketName(bucket string) error {
+	if o.isLocal() {
+		if bucket == "" {
+			return errors.New("must not be blank")
+		}
+		return nil
+	}
+	return validateS3BucketName(bucket)
+}
+
+// applyDefaults fills in the blank options from the admin's settings
+// once the options have been expanded.
+func (o *blobStorageOptions) applyDefaults(exp *util.Expansions) {
+	if o.Provider == "" {
+		o.Provider = exp.Get(evergreen.BlobProviderExpansion)
+	}
+	if o.Endpoint == "" {
+		o.Endpoint = exp.Get(evergreen.BlobEndpointExpansion)
+	}
+	if !o.PathStyle {
+		o.PathStyle = exp.Get(evergreen.BlobPathStyleExpansion) == "true"
+	}
+}
+
+// openBucket returns the named bucket of the configured provider.
+func (o *blobStorageOptions) openBucket(conf *model.TaskConfig, name, awsKey, awsSecret string) (thirdparty.Bucket, error) {
+	opts := thirdparty.BucketOptions{
+		Provider:  o.Provider,
+		Name:      name,
+		LocalRoot: conf.Expansions.Get(evergreen.BlobLocalRootExpansion),
+		Endpoint:  o.Endpoint,
+		PathStyle: o.PathStyle,
+		Auth: &aws.Auth{
+			AccessKey: awsKey,
+			SecretKey: awsSecret,
+		},
+	}
+	if o.isLocal() && opts.LocalRoot == "" {
+		opts.LocalRoot = conf.WorkDir
+	}
+	if opts.LocalRoot != "" && !filepath.IsAbs(opts.LocalRoot) {
+		opts.LocalRoot = filepath.Join(conf.WorkDir, opts.LocalRoot)
+	}
+
+	return thirdparty.NewBucket(opts)
+}
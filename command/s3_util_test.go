@@ -0,0 +1,171 @@
+package command
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/rest/client"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/stretchr/testify/suite"
+)
+
+// LocalBlobStorageSuite runs the s3 commands against the local provider.
+type LocalBlobStorageSuite struct {
+	suite.Suite
+	conf   *model.TaskConfig
+	comm   client.Communicator
+	mock   *client.Mock
+	logger client.LoggerProducer
+	ctx    context.Context
+	cancel context.CancelFunc
+	tmpdir string
+}
+
+func TestLocalBlobStorageSuite(t *testing.T) {
+	suite.Run(t, new(LocalBlobStorageSuite))
+}
+
+func (s *LocalBlobStorageSuite) SetupTest() {
+	var err error
+	s.tmpdir, err = ioutil.TempDir("", "evergreen.command.s3_util.test")
+	s.Require().NoError(err)
+
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.comm = client.NewMock("http://localhost.com")
+	s.mock = s.comm.(*client.Mock)
+	s.conf = &model.TaskConfig{
+		Expansions:   util.NewExpansions(map[string]string{}),
+		Task:         &task.Task{Id: "task"},
+		Project:      &model.Project{},
+		BuildVariant: &model.BuildVariant{Name: "linux"},
+		WorkDir:      filepath.Join(s.tmpdir, "work"),
+	}
+	s.logger = s.comm.GetLoggerProducer(s.ctx, client.TaskData{ID: s.conf.Task.Id, Secret: s.conf.Task.Secret})
+
+	s.Require().NoError(os.MkdirAll(s.conf.WorkDir, 0755))
+	s.Require().NoError(ioutil.WriteFile(filepath.Join(s.conf.WorkDir, "artifact.txt"), []byte("artifact"), 0644))
+}
+
+func (s *LocalBlobStorageSuite) TearDownTest() {
+	s.cancel()
+	s.Require().NoError(os.RemoveAll(s.tmpdir))
+}
+
+func (s *LocalBlobStorageSuite) execute(cmd Command, params map[string]interface{}) error {
+	if err := cmd.ParseParams(params); err != nil {
+		return err
+	}
+	return cmd.Execute(s.ctx, s.comm, s.logger, s.conf)
+}
+
+func (s *LocalBlobStorageSuite) readFile(parts ...string) string {
+	data, err := ioutil.ReadFile(filepath.Join(parts...))
+	s.Require().NoError(err)
+	return string(data)
+}
+
+func (s *LocalBlobStorageSuite) TestValidateProvider() {
+	s.NoError(s3GetFactory().ParseParams(map[string]interface{}{
+		"provider":    "local",
+		"bucket":      "uploads",
+		"remote_file": "artifact.txt",
+		"local_file":  "artifact.txt",
+	}))
+	s.Error(s3GetFactory().ParseParams(map[string]interface{}{
+		"provider":    "ftp",
+		"bucket":      "uploads",
+		"remote_file": "artifact.txt",
+		"local_file":  "artifact.txt",
+	}))
+	s.Error(s3GetFactory().ParseParams(map[string]interface{}{
+		"provider":    "s3-compatible",
+		"aws_key":     "key",
+		"aws_secret":  "secret",
+		"bucket":      "uploads",
+		"remote_file": "artifact.txt",
+		"local_file":  "artifact.txt",
+	}))
+	s.NoError(s3GetFactory().ParseParams(map[string]interface{}{
+		"provider":    "s3-compatible",
+		"endpoint":    "http://minio.example.com:9000",
+		"path_style":  true,
+		"aws_key":     "key",
+		"aws_secret":  "secret",
+		"bucket":      "uploads",
+		"remote_file": "artifact.txt",
+		"local_file":  "artifact.txt",
+	}))
+}
+
+func (s *LocalBlobStorageSuite) TestPutGetAndCopy() {
+	bucket := filepath.Join(s.tmpdir, "uploads")
+
+	s.Require().NoError(s.execute(s3PutFactory(), map[string]interface{}{
+		"provider":     "local",
+		"bucket":       bucket,
+		"local_file":   "artifact.txt",
+		"remote_file":  "staging/artifact.txt",
+		"content_type": "text/plain",
+		"permissions":  "private",
+		"display_name": "artifact",
+	}))
+	s.Equal("artifact", s.readFile(bucket, "staging", "artifact.txt"))
+	s.Require().Len(s.mock.AttachedFiles["task"], 1)
+	s.Equal("file://"+filepath.ToSlash(filepath.Join(bucket, "staging", "artifact.txt")), s.mock.AttachedFiles["task"][0].Link)
+
+	s.Require().NoError(s.execute(s3GetFactory(), map[string]interface{}{
+		"provider":    "local",
+		"bucket":      bucket,
+		"remote_file": "staging/artifact.txt",
+		"local_file":  "downloaded/artifact.txt",
+	}))
+	s.Equal("artifact", s.readFile(s.conf.WorkDir, "downloaded", "artifact.txt"))
+
+	s.Require().NoError(s.execute(s3CopyFactory(), map[string]interface{}{
+		"provider": "local",
+		"s3_copy_files": []map[string]interface{}{
+			{
+				"source":      map[string]string{"bucket": bucket, "path": "staging/artifact.txt"},
+				"destination": map[string]string{"bucket": "releases", "path": "artifact.txt"},
+			},
+		},
+	}))
+	s.Equal("artifact", s.readFile(s.conf.WorkDir, "releases", "artifact.txt"))
+	s.Len(s.mock.AttachedFiles["task"], 2)
+}
+
+func (s *LocalBlobStorageSuite) TestProviderDefaultsToAdminSettings() {
+	s.conf.Expansions.Update(evergreen.BlobStorageConfig{
+		Provider:  evergreen.BlobProviderLocal,
+		LocalRoot: filepath.Join(s.tmpdir, "blobs"),
+	}.Expansions())
+
+	// credentials are still required when the params don't name the
+	// provider, but go unused
+	s.Require().NoError(s.execute(s3PutFactory(), map[string]interface{}{
+		"aws_key":      "key",
+		"aws_secret":   "secret",
+		"bucket":       "uploads",
+		"local_file":   "artifact.txt",
+		"remote_file":  "artifact.txt",
+		"content_type": "text/plain",
+		"permissions":  "private",
+	}))
+	s.Equal("artifact", s.readFile(s.tmpdir, "blobs", "uploads", "artifact.txt"))
+
+	// but the task's own choice of provider wins
+	s.Error(s.execute(s3PutFactory(), map[string]interface{}{
+		"provider":     "ftp",
+		"bucket":       "uploads",
+		"local_file":   "artifact.txt",
+		"remote_file":  "artifact.txt",
+		"content_type": "text/plain",
+		"permissions":  "private",
+	}))
+}
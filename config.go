@@ -52,11 +52,93 @@ type GithubAuthConfig struct {
 	Organization string   `yaml:"organization"`
 }
 
+// OIDCAuthConfig holds settings for logging in with an OpenID Connect
+// identity provider. The client is registered with the provider with
+// <ui url>/login/redirect/callback as its redirect URI.
+type OIDCAuthConfig struct {
+	Issuer       string   `yaml:"issuer"`
+	ClientId     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret"`
+	Scopes       []string `yaml:"scopes"`
+
+	// The claims of the ID token holding the username and groups of a
+	// user default to "preferred_username" and "groups".
+	UsernameClaim string `yaml:"username_claim"`
+	GroupsClaim   string `yaml:"groups_claim"`
+	// GithubLoginClaim is the claim holding a user's GitHub login, which
+	// should only be set if the identity provider verifies it.
+	GithubLoginClaim string `yaml:"github_login_claim"`
+
+	// Members of the SuperUserGroups are super users, and members of
+	// the groups that ProjectAdminGroups lists for a project are admins
+	// of the project, in addition to the users configured elsewhere.
+	SuperUserGroups    []string            `yaml:"superuser_groups"`
+	ProjectAdminGroups map[string][]string `yaml:"project_admin_groups"`
+}
+
 // AuthConfig has a pointer to either a CrowConfig or a NaiveAuthConfig.
 type AuthConfig struct {
 	Crowd  *CrowdConfig      `yaml:"crowd"`
 	Naive  *NaiveAuthConfig  `yaml:"naive"`
 	Github *GithubAuthConfig `yaml:"github"`
+	OIDC   *OIDCAuthConfig   `yaml:"oidc"`
+}
+
+// RBACConfig holds settings for role-based access control. While it is
+// disabled, logged in users keep the permissions they have always had;
+// once enabled, users need a role granting a permission to use it.
+// DefaultRoles are granted to every logged in user, on every project.
+type RBACConfig struct {
+	Enabled      bool     `yaml:"enabled"`
+	DefaultRoles []string `yaml:"default_roles"`
+}
+
+// The storage backends for task artifacts.
+const (
+	BlobProviderS3           = "s3"
+	BlobProviderS3Compatible = "s3-compatible"
+	BlobProviderLocal        = "local"
+)
+
+// The expansions that carry the admin's BlobStorageConfig to the agent.
+// Project variables with the same names take precedence.
+const (
+	BlobProviderExpansion  = "blob_provider"
+	BlobEndpointExpansion  = "blob_endpoint"
+	BlobPathStyleExpansion = "blob_path_style"
+	BlobLocalRootExpansion = "blob_local_root"
+)
+
+// BlobStorageConfig selects the default storage backend of the s3.put,
+// s3.get and s3Copy.copy commands, which tasks can override with the
+// commands' provider parameter. Endpoint is the URL of an S3-compatible
+// service such as MinIO or Ceph, which is addressed with the bucket in the
+// path rather than the host name if PathStyle is set. LocalRoot is the
+// directory holding the buckets of the local provider.
+type BlobStorageConfig struct {
+	Provider  string `yaml:"provider"`
+	Endpoint  string `yaml:"endpoint"`
+	PathStyle bool   `yaml:"path_style"`
+	LocalRoot string `yaml:"local_root"`
+}
+
+// Expansions returns the configuration as the expansions that the
+// commands read it from.
+func (c BlobStorageConfig) Expansions() map[string]string {
+	exp := map[string]string{}
+	if c.Provider != "" {
+		exp[BlobProviderExpansion] = c.Provider
+	}
+	if c.Endpoint != "" {
+		exp[BlobEndpointExpansion] = c.Endpoint
+	}
+	if c.PathStyle {
+		exp[BlobPathStyleExpansion] = "true"
+	}
+	if c.LocalRoot != "" {
+		exp[BlobLocalRootExpansion] = c.LocalRoot
+	}
+	return exp
 }
 
 // RepoTrackerConfig holds settings for polling project repositories.
@@ -64,6 +146,9 @@ type RepoTrackerConfig struct {
 	NumNewRepoRevisionsToFetch int
 	MaxRepoRevisionsToSearch   int
 	MaxConcurrentRequests      int
+	// MirrorDir holds the local mirrors of projects tracked with plain
+	// git. It defaults to a directory in the system's temp directory.
+	MirrorDir string
 }
 
 type ClientBinary struct {
@@ -139,6 +224,7 @@ type CloudProviders struct {
 	DigitalOcean DigitalOceanConfig `yaml:"digitalocean"`
 	Docker       DockerConfig       `yaml:"docker"`
 	GCE          GCEConfig          `yaml:"gce"`
+	Kubernetes   KubernetesConfig   `yaml:"kubernetes"`
 	OpenStack    OpenStackConfig    `yaml:"openstack"`
 	VSphere      VSphereConfig      `yaml:"vsphere"`
 }
@@ -160,6 +246,14 @@ type DockerConfig struct {
 	APIVersion string `yaml:"api_version"`
 }
 
+// KubernetesConfig stores connection info for a Kubernetes-compatible
+// container orchestrator API server.
+type KubernetesConfig struct {
+	APIServer          string `yaml:"api_server"`
+	Token              string `yaml:"token"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+}
+
 // OpenStackConfig stores auth info for Linaro using Identity V3. All fields required.
 //
 // The config is NOT compatible with Identity V2.
@@ -207,6 +301,10 @@ type PluginConfig map[string]map[string]interface{}
 
 type AlertsConfig struct {
 	SMTP *SMTPConfig `yaml:"smtp"`
+
+	// WebhookSecret signs the payloads of webhook alerts that do not
+	// have a secret of their own.
+	WebhookSecret string `yaml:"webhook_secret"`
 }
 type WriteConcern struct {
 	W        int    `yaml:"w"`
@@ -271,6 +369,7 @@ type Settings struct {
 	Keys                map[string]string         `yaml:"keys"`
 	Credentials         map[string]string         `yaml:"credentials"`
 	AuthConfig          AuthConfig                `yaml:"auth"`
+	RBAC                RBACConfig                `yaml:"rbac"`
 	RepoTracker         RepoTrackerConfig         `yaml:"repotracker"`
 	Api                 APIConfig                 `yaml:"api"`
 	Alerts              AlertsConfig              `yaml:"alerts"`
@@ -280,6 +379,7 @@ type Settings struct {
 	Scheduler           SchedulerConfig           `yaml:"scheduler"`
 	Amboy               AmboyConfig               `yaml:"amboy"`
 	Expansions          map[string]string         `yaml:"expansions"`
+	BlobStorage         BlobStorageConfig         `yaml:"blob_storage"`
 	Plugins             PluginConfig              `yaml:"plugins"`
 	IsNonProd           bool                      `yaml:"isnonprod"`
 	LoggerConfig        LoggerConfig              `yaml:"logger_config"`
@@ -637,6 +737,24 @@ var configValidationRules = []configValidator{
 		}
 		return nil
 	},
+
+	func(settings *Settings) error {
+		blob := settings.BlobStorage
+		switch blob.Provider {
+		case "", BlobProviderS3:
+		case BlobProviderS3Compatible:
+			if blob.Endpoint == "" {
+				return errors.New("the s3-compatible blob storage provider requires an endpoint")
+			}
+		case BlobProviderLocal:
+			if blob.LocalRoot == "" {
+				return errors.New("the local blob storage provider requires a local_root")
+			}
+		default:
+			return errors.Errorf("unknown blob storage provider '%s'", blob.Provider)
+		}
+		return nil
+	},
 }
 
 func sliceContains(slice []string, elem string) bool {
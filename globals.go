@@ -105,6 +105,11 @@ const (
 	TeardownScriptName = "teardown.sh"
 
 	RoutePaginatorNextPageHeaderKey = "Link"
+	// TaskLogCompleteTrailerKey is the trailer a followed task log stream
+	// sets to "true" once the task has finished and the whole log has been
+	// sent. Streams end before then to stay within the server's timeouts,
+	// and should be resumed from the number of lines already read.
+	TaskLogCompleteTrailerKey = "X-Evergreen-Log-Complete"
 )
 
 // evergreen package names
@@ -131,6 +136,7 @@ const (
 	ProviderNameDigitalOcean = "digitalocean"
 	ProviderNameDocker       = "docker"
 	ProviderNameGce          = "gce"
+	ProviderNameKubernetes   = "kubernetes"
 	ProviderNameStatic       = "static"
 	ProviderNameOpenstack    = "openstack"
 	ProviderNameVsphere      = "vsphere"
@@ -148,6 +154,9 @@ const (
 	PatchVersionRequester       = "patch_request"
 	GithubPRRequester           = "github_pull_request"
 	RepotrackerVersionRequester = "gitter_request"
+	// AdHocVersionRequester versions are created by a project's periodic
+	// builds, rather than for a new commit
+	AdHocVersionRequester = "ad_hoc"
 )
 
 const (
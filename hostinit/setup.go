@@ -116,6 +116,12 @@ func (init *HostInit) startHosts(ctx context.Context) error {
 			continue
 		}
 
+		// hosts that bootstrap themselves register with the token
+		// that the cloud manager puts in their user data
+		if h.BootstrapsWithUserData() {
+			h.BootstrapToken = util.RandomString()
+		}
+
 		_, err = cloudManager.SpawnHost(&h)
 		if err != nil {
 			// we should maybe try and continue-on-error
@@ -226,6 +232,18 @@ func (init *HostInit) setupReadyHosts(ctx context.Context) error {
 						return
 					}
 
+					// hosts that bootstrap themselves are set up
+					// once they register with the API server
+					if h.BootstrapsWithUserData() {
+						grip.Debug(message.Fields{
+							"GUID":    init.GUID,
+							"message": "skipping setup of host that bootstraps with user data",
+							"hostid":  h.Id,
+							"runner":  RunnerName,
+						})
+						continue
+					}
+
 					grip.Info(message.Fields{
 						"GUID":    init.GUID,
 						"message": "attempting to setup host",
@@ -0,0 +1,26 @@
+package hostutil
+
+import (
+	"fmt"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model/host"
+)
+
+// BootstrapUserData returns the user data script for a host that bootstraps
+// itself rather than being provisioned over SSH. The script downloads the
+// evergreen binary as the distro's user and starts "evergreen host
+// bootstrap" in the background, which registers the host with the API
+// server using the host's one-time bootstrap token. The script cannot
+// include the host's ID, as the ID is only known once the instance starts.
+func BootstrapUserData(settings *evergreen.Settings, h *host.Host) string {
+	bootstrap := fmt.Sprintf("cd ~ && curl -fLO --retry 10 --retry-delay 10 '%s' && chmod +x %s && "+
+		"(nohup ./%s host bootstrap --api_server='%s' --token='%s' > bootstrap.log 2>&1 &)",
+		ClientURL(settings.Ui.Url, &h.Distro),
+		binaryName(&h.Distro),
+		binaryName(&h.Distro),
+		settings.ApiUrl,
+		h.BootstrapToken)
+
+	return fmt.Sprintf("#!/bin/bash\nsu - %s -c \"%s\"\n", h.Distro.User, bootstrap)
+}
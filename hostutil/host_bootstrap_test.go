@@ -0,0 +1,44 @@
+package hostutil
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model/distro"
+	"github.com/evergreen-ci/evergreen/model/host"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBootstrapUserData(t *testing.T) {
+	assert := assert.New(t)
+
+	settings := &evergreen.Settings{
+		ApiUrl: "https://api.example.com",
+		Ui:     evergreen.UIConfig{Url: "https://ui.example.com"},
+	}
+	h := &host.Host{
+		Id:             "h1",
+		BootstrapToken: "token",
+		Distro: distro.Distro{
+			Arch: "linux_amd64",
+			User: "ec2-user",
+		},
+	}
+
+	script := BootstrapUserData(settings, h)
+	assert.True(strings.HasPrefix(script, "#!/bin/bash\n"))
+	assert.Contains(script, "su - ec2-user -c")
+	assert.Contains(script, "'https://ui.example.com/clients/linux_amd64/evergreen'")
+	assert.Contains(script, "host bootstrap --api_server='https://api.example.com' --token='token'")
+	assert.NotContains(script, "h1")
+}
+
+func TestClientURL(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("https://example.com/clients/linux_amd64/evergreen",
+		ClientURL("https://example.com", &distro.Distro{Arch: "linux_amd64"}))
+	assert.Equal("https://example.com/clients/windows_amd64/evergreen.exe",
+		ClientURL("https://example.com", &distro.Distro{Arch: "windows_amd64"}))
+}
@@ -42,11 +42,16 @@ func IsWindows(d *distro.Distro) bool {
 	return strings.HasPrefix(d.Arch, "windows")
 }
 
+// ClientURL returns the URL from which a host of the distro can download the
+// evergreen binary.
+func ClientURL(url string, d *distro.Distro) string {
+	return fmt.Sprintf("%s/clients/%s", url, executableSubPath(d))
+}
+
 // CurlCommand returns a command for curling an agent binary to a host
 func CurlCommand(url string, host *host.Host) string {
-	return fmt.Sprintf("cd ~ && curl -LO '%s/clients/%s' && chmod +x %s",
-		url,
-		executableSubPath(&host.Distro),
+	return fmt.Sprintf("cd ~ && curl -LO '%s' && chmod +x %s",
+		ClientURL(url, &host.Distro),
 		binaryName(&host.Distro))
 }
 
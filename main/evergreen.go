@@ -46,12 +46,15 @@ func buildApp() *cli.App {
 		operations.Agent(),
 		operations.Admin(),
 		operations.Host(),
+		operations.Task(),
+		operations.CommitQueue(),
 
 		// Top-level commands.
 		operations.Keys(),
 		operations.Fetch(),
 		operations.Evaluate(),
 		operations.Validate(),
+		operations.RunLocal(),
 		operations.List(),
 		operations.TestHistory(),
 		operations.LastGreen(),
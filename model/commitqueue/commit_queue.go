@@ -0,0 +1,228 @@
+package commitqueue
+
+import (
+	"time"
+
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/mongodb/anser/bsonutil"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	// Collection is the name of the commit queue collection in the database.
+	Collection = "commit_queue"
+
+	// PRItemType is the type of an item that is a GitHub pull request,
+	// identified by its number.
+	PRItemType = "pr"
+	// PatchItemType is the type of an item that is a patch submitted
+	// with the CLI, identified by the patch's id.
+	PatchItemType = "patch"
+)
+
+// CommitQueueItem is a pull request or patch waiting to be tested against
+// the head of its project's branch and merged.
+type CommitQueueItem struct {
+	Issue       string    `bson:"issue" json:"issue"`
+	Type        string    `bson:"type" json:"type"`
+	Author      string    `bson:"author" json:"author"`
+	EnqueueTime time.Time `bson:"enqueue_time" json:"enqueue_time"`
+
+	// Version is the id of the patch testing the item, which is set when
+	// the item reaches the head of the queue. Revision is the head commit
+	// of the pull request that the patch tested.
+	Version  string `bson:"version,omitempty" json:"version,omitempty"`
+	Revision string `bson:"revision,omitempty" json:"revision,omitempty"`
+}
+
+// CommitQueue is a project's queue of items, which are tested and merged
+// in order, one at a time.
+type CommitQueue struct {
+	ProjectID string            `bson:"_id" json:"project_id"`
+	Queue     []CommitQueueItem `bson:"queue" json:"queue"`
+}
+
+var (
+	// BSON fields for the commit queue structs
+	ProjectIDKey = bsonutil.MustHaveTag(CommitQueue{}, "ProjectID")
+	QueueKey     = bsonutil.MustHaveTag(CommitQueue{}, "Queue")
+
+	IssueKey       = bsonutil.MustHaveTag(CommitQueueItem{}, "Issue")
+	TypeKey        = bsonutil.MustHaveTag(CommitQueueItem{}, "Type")
+	AuthorKey      = bsonutil.MustHaveTag(CommitQueueItem{}, "Author")
+	EnqueueTimeKey = bsonutil.MustHaveTag(CommitQueueItem{}, "EnqueueTime")
+	VersionKey     = bsonutil.MustHaveTag(CommitQueueItem{}, "Version")
+	RevisionKey    = bsonutil.MustHaveTag(CommitQueueItem{}, "Revision")
+
+	// keys of the fields of the item at the head of the queue
+	headIssueKey    = bsonutil.GetDottedKeyName(QueueKey, "0", IssueKey)
+	headVersionKey  = bsonutil.GetDottedKeyName(QueueKey, "0", VersionKey)
+	headRevisionKey = bsonutil.GetDottedKeyName(QueueKey, "0", RevisionKey)
+)
+
+// FindOneId returns the commit queue of the project, or nil if nothing
+// has ever been enqueued for it.
+func FindOneId(projectID string) (*CommitQueue, error) {
+	cq := &CommitQueue{}
+	err := db.FindOneQ(Collection, db.Query(bson.M{ProjectIDKey: projectID}), cq)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem finding commit queue for project '%s'", projectID)
+	}
+	return cq, nil
+}
+
+// Next returns the item at the head of the queue.
+func (q *CommitQueue) Next() (CommitQueueItem, bool) {
+	if len(q.Queue) == 0 {
+		return CommitQueueItem{}, false
+	}
+	return q.Queue[0], true
+}
+
+// FindItem returns the position of the item in the queue, or -1 if it is
+// not queued.
+func (q *CommitQueue) FindItem(issue string) int {
+	for i, item := range q.Queue {
+		if item.Issue == issue {
+			return i
+		}
+	}
+	return -1
+}
+
+// Enqueue adds the item to the end of the project's queue, creating the
+// queue if needed, and returns the item's position. An item can only be
+// queued once.
+func Enqueue(projectID string, item CommitQueueItem) (int, error) {
+	if item.Issue == "" {
+		return -1, errors.New("can't enqueue an item without an issue")
+	}
+	if item.Type != PRItemType && item.Type != PatchItemType {
+		return -1, errors.Errorf("invalid commit queue item type '%s'", item.Type)
+	}
+	if item.EnqueueTime.IsZero() {
+		item.EnqueueTime = time.Now()
+	}
+	item.Version = ""
+	item.Revision = ""
+
+	_, err := db.Upsert(
+		Collection,
+		bson.M{ProjectIDKey: projectID},
+		bson.M{"$setOnInsert": bson.M{QueueKey: []CommitQueueItem{}}},
+	)
+	if err != nil {
+		return -1, errors.Wrapf(err, "problem creating commit queue for project '%s'", projectID)
+	}
+
+	err = db.Update(
+		Collection,
+		bson.M{
+			ProjectIDKey: projectID,
+			bsonutil.GetDottedKeyName(QueueKey, IssueKey): bson.M{"$ne": item.Issue},
+		},
+		bson.M{"$push": bson.M{QueueKey: item}},
+	)
+	if err == mgo.ErrNotFound {
+		return -1, errors.Errorf("'%s' is already in the commit queue for project '%s'", item.Issue, projectID)
+	}
+	if err != nil {
+		return -1, errors.Wrapf(err, "problem adding '%s' to the commit queue for project '%s'", item.Issue, projectID)
+	}
+
+	cq, err := FindOneId(projectID)
+	if err != nil {
+		return -1, errors.WithStack(err)
+	}
+	if cq == nil {
+		return -1, errors.Errorf("commit queue for project '%s' disappeared", projectID)
+	}
+	return cq.FindItem(item.Issue), nil
+}
+
+// Remove takes the item out of the queue, and reports whether it was
+// queued.
+func (q *CommitQueue) Remove(issue string) (bool, error) {
+	err := db.Update(
+		Collection,
+		bson.M{
+			ProjectIDKey: q.ProjectID,
+			bsonutil.GetDottedKeyName(QueueKey, IssueKey): issue,
+		},
+		bson.M{"$pull": bson.M{QueueKey: bson.M{IssueKey: issue}}},
+	)
+	if err == mgo.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "problem removing '%s' from the commit queue for project '%s'", issue, q.ProjectID)
+	}
+
+	if i := q.FindItem(issue); i >= 0 {
+		q.Queue = append(q.Queue[:i], q.Queue[i+1:]...)
+	}
+	return true, nil
+}
+
+// StartHead records the patch testing the item at the head of the queue.
+// It only succeeds if the item is still at the head and isn't already
+// being tested, so that two processors don't test the same item.
+func (q *CommitQueue) StartHead(issue, version, revision string) (bool, error) {
+	err := db.Update(
+		Collection,
+		bson.M{
+			ProjectIDKey:   q.ProjectID,
+			headIssueKey:   issue,
+			headVersionKey: bson.M{"$exists": false},
+		},
+		bson.M{"$set": bson.M{
+			headVersionKey:  version,
+			headRevisionKey: revision,
+		}},
+	)
+	if err == mgo.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "problem starting '%s' in the commit queue for project '%s'", issue, q.ProjectID)
+	}
+
+	if len(q.Queue) > 0 && q.Queue[0].Issue == issue {
+		q.Queue[0].Version = version
+		q.Queue[0].Revision = revision
+	}
+	return true, nil
+}
+
+// ResetHead clears the patch testing the item at the head of the queue, so
+// that the item is tested again.
+func (q *CommitQueue) ResetHead(issue string) error {
+	err := db.Update(
+		Collection,
+		bson.M{
+			ProjectIDKey: q.ProjectID,
+			headIssueKey: issue,
+		},
+		bson.M{"$unset": bson.M{
+			headVersionKey:  1,
+			headRevisionKey: 1,
+		}},
+	)
+	if err == mgo.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "problem resetting '%s' in the commit queue for project '%s'", issue, q.ProjectID)
+	}
+
+	if len(q.Queue) > 0 && q.Queue[0].Issue == issue {
+		q.Queue[0].Version = ""
+		q.Queue[0].Revision = ""
+	}
+	return nil
+}
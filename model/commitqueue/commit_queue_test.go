@@ -0,0 +1,137 @@
+package commitqueue
+
+import (
+	"testing"
+
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestFindItem(t *testing.T) {
+	assert := assert.New(t)
+
+	cq := &CommitQueue{
+		ProjectID: "mci",
+		Queue: []CommitQueueItem{
+			{Issue: "12", Type: PRItemType},
+			{Issue: "5a0f0f5b3ff1224f3b58a0a1", Type: PatchItemType},
+		},
+	}
+	assert.Equal(0, cq.FindItem("12"))
+	assert.Equal(1, cq.FindItem("5a0f0f5b3ff1224f3b58a0a1"))
+	assert.Equal(-1, cq.FindItem("13"))
+
+	next, ok := cq.Next()
+	assert.True(ok)
+	assert.Equal("12", next.Issue)
+
+	_, ok = (&CommitQueue{}).Next()
+	assert.False(ok)
+}
+
+type CommitQueueSuite struct {
+	suite.Suite
+}
+
+func TestCommitQueueSuite(t *testing.T) {
+	suite.Run(t, new(CommitQueueSuite))
+}
+
+func (s *CommitQueueSuite) SetupSuite() {
+	db.SetGlobalSessionProvider(testutil.TestConfig().SessionFactory())
+}
+
+func (s *CommitQueueSuite) SetupTest() {
+	s.Require().NoError(db.Clear(Collection))
+}
+
+func (s *CommitQueueSuite) TestEnqueue() {
+	cq, err := FindOneId("mci")
+	s.NoError(err)
+	s.Nil(cq)
+
+	position, err := Enqueue("mci", CommitQueueItem{Issue: "12", Type: PRItemType, Author: "me"})
+	s.NoError(err)
+	s.Equal(0, position)
+	position, err = Enqueue("mci", CommitQueueItem{Issue: "13", Type: PRItemType, Author: "me", Version: "v"})
+	s.NoError(err)
+	s.Equal(1, position)
+
+	// items can't be queued twice
+	_, err = Enqueue("mci", CommitQueueItem{Issue: "12", Type: PRItemType, Author: "you"})
+	s.Error(err)
+	_, err = Enqueue("mci", CommitQueueItem{Issue: "14", Type: "branch"})
+	s.Error(err)
+
+	cq, err = FindOneId("mci")
+	s.NoError(err)
+	s.Require().NotNil(cq)
+	s.Require().Len(cq.Queue, 2)
+	s.Equal("12", cq.Queue[0].Issue)
+	s.Equal("me", cq.Queue[0].Author)
+	s.False(cq.Queue[0].EnqueueTime.IsZero())
+	s.Empty(cq.Queue[1].Version)
+}
+
+func (s *CommitQueueSuite) TestRemove() {
+	for _, issue := range []string{"12", "13", "14"} {
+		_, err := Enqueue("mci", CommitQueueItem{Issue: issue, Type: PRItemType})
+		s.Require().NoError(err)
+	}
+	cq, err := FindOneId("mci")
+	s.Require().NoError(err)
+
+	removed, err := cq.Remove("13")
+	s.NoError(err)
+	s.True(removed)
+	s.Len(cq.Queue, 2)
+
+	removed, err = cq.Remove("13")
+	s.NoError(err)
+	s.False(removed)
+
+	cq, err = FindOneId("mci")
+	s.Require().NoError(err)
+	s.Require().Len(cq.Queue, 2)
+	s.Equal("12", cq.Queue[0].Issue)
+	s.Equal("14", cq.Queue[1].Issue)
+}
+
+func (s *CommitQueueSuite) TestStartAndResetHead() {
+	for _, issue := range []string{"12", "13"} {
+		_, err := Enqueue("mci", CommitQueueItem{Issue: issue, Type: PRItemType})
+		s.Require().NoError(err)
+	}
+	cq, err := FindOneId("mci")
+	s.Require().NoError(err)
+
+	// only the head can be started, and only once
+	started, err := cq.StartHead("13", "v2", "def")
+	s.NoError(err)
+	s.False(started)
+	started, err = cq.StartHead("12", "v1", "abc")
+	s.NoError(err)
+	s.True(started)
+	started, err = cq.StartHead("12", "v3", "abc")
+	s.NoError(err)
+	s.False(started)
+
+	cq, err = FindOneId("mci")
+	s.Require().NoError(err)
+	s.Equal("v1", cq.Queue[0].Version)
+	s.Equal("abc", cq.Queue[0].Revision)
+	s.Empty(cq.Queue[1].Version)
+
+	s.NoError(cq.ResetHead("12"))
+	s.Empty(cq.Queue[0].Version)
+	cq, err = FindOneId("mci")
+	s.Require().NoError(err)
+	s.Empty(cq.Queue[0].Version)
+	s.Empty(cq.Queue[0].Revision)
+
+	started, err = cq.StartHead("12", "v4", "abc")
+	s.NoError(err)
+	s.True(started)
+}
@@ -0,0 +1,206 @@
+package model
+
+import (
+	"math"
+	"sort"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model/build"
+	"github.com/evergreen-ci/evergreen/model/coverage"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/model/version"
+	"github.com/pkg/errors"
+)
+
+// FileCoverageDiff stores a pairing of the coverage of a file in a patch
+// and in its base version.
+type FileCoverageDiff struct {
+	File     string           `json:"file"`
+	Original coverage.Summary `json:"original"`
+	Patch    coverage.Summary `json:"patch"`
+}
+
+// Delta returns the change in the file's coverage percentage, or 0 if the
+// file has no lines in either the patch or its base.
+func (d *FileCoverageDiff) Delta() float64 {
+	if d.Original.Total == 0 || d.Patch.Total == 0 {
+		return 0
+	}
+	return d.Patch.Percent() - d.Original.Percent()
+}
+
+// TaskCoverageDiff stores a diff of the coverage reported by a patch task
+// and the same task in the patch's base version.
+type TaskCoverageDiff struct {
+	Name         string             `json:"name"`
+	BuildVariant string             `json:"build_variant"`
+	Original     string             `json:"original"`
+	Patch        string             `json:"patch"`
+	Files        []FileCoverageDiff `json:"files"`
+}
+
+// VersionCoverageDiff stores a diff of the coverage of a patch and its
+// base version. Files holds the coverage of all tasks that ran in both,
+// and Tasks the coverage of each of those tasks.
+type VersionCoverageDiff struct {
+	Version     string             `json:"version"`
+	BaseVersion string             `json:"base_version"`
+	Files       []FileCoverageDiff `json:"files"`
+	Tasks       []TaskCoverageDiff `json:"tasks"`
+}
+
+// CoverageDiffFiles takes the merged coverage of two sets of files and
+// returns a diff of every file in either. Files whose coverage dropped
+// come first, ordered by how much it dropped, followed by the rest by
+// name.
+func CoverageDiffFiles(original, patch []coverage.FileCoverage) []FileCoverageDiff {
+	byFile := map[string]*FileCoverageDiff{}
+	diffs := []*FileCoverageDiff{}
+	get := func(file string) *FileCoverageDiff {
+		d, ok := byFile[file]
+		if !ok {
+			d = &FileCoverageDiff{File: file}
+			byFile[file] = d
+			diffs = append(diffs, d)
+		}
+		return d
+	}
+	for i := range original {
+		get(original[i].File).Original = original[i].Summary()
+	}
+	for i := range patch {
+		get(patch[i].File).Patch = patch[i].Summary()
+	}
+
+	out := make([]FileCoverageDiff, 0, len(diffs))
+	for _, d := range diffs {
+		out = append(out, *d)
+	}
+	sort.Sort(fileCoverageDiffs(out))
+	return out
+}
+
+type fileCoverageDiffs []FileCoverageDiff
+
+func (d fileCoverageDiffs) Len() int      { return len(d) }
+func (d fileCoverageDiffs) Swap(i, j int) { d[i], d[j] = d[j], d[i] }
+func (d fileCoverageDiffs) Less(i, j int) bool {
+	iDrop, jDrop := math.Min(d[i].Delta(), 0), math.Min(d[j].Delta(), 0)
+	if iDrop != jDrop {
+		return iDrop < jDrop
+	}
+	return d[i].File < d[j].File
+}
+
+// FindVersionCoverage returns the merged coverage reported by the latest
+// execution of each of a version's tasks.
+func FindVersionCoverage(versionId string) ([]coverage.FileCoverage, error) {
+	tasks, err := task.Find(task.ByVersion(versionId).WithFields(task.IdKey, task.ExecutionKey))
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem finding tasks for version '%s'", versionId)
+	}
+	executions := map[string]int{}
+	for _, t := range tasks {
+		executions[t.Id] = t.Execution
+	}
+
+	files, err := coverage.FindByTaskExecutions(executions)
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem finding coverage for version '%s'", versionId)
+	}
+	return coverage.Merge(files), nil
+}
+
+// CoverageDiffVersion takes a patch version and returns a diff of its
+// coverage and the coverage of its base version. Tasks are paired with
+// their base tasks as they are by StatusDiffBuilds, and only tasks that
+// ran in both and reported coverage in either are compared, so that a
+// patch that runs a subset of the tasks is not blamed for the coverage of
+// the tasks it did not run.
+func CoverageDiffVersion(patchVersion *version.Version) (*VersionCoverageDiff, error) {
+	if !evergreen.IsPatchRequester(patchVersion.Requester) {
+		return nil, errors.Errorf("version '%s' is not a patch", patchVersion.Id)
+	}
+
+	diff := &VersionCoverageDiff{
+		Version: patchVersion.Id,
+		Files:   []FileCoverageDiff{},
+		Tasks:   []TaskCoverageDiff{},
+	}
+	baseVersion, err := version.FindOne(version.BaseVersionFromPatch(patchVersion.Identifier, patchVersion.Revision))
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem finding base version for patch '%s'", patchVersion.Id)
+	}
+	if baseVersion == nil {
+		return diff, nil
+	}
+	diff.BaseVersion = baseVersion.Id
+
+	patchBuilds, err := build.Find(build.ByIds(patchVersion.BuildIds))
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem finding builds for patch '%s'", patchVersion.Id)
+	}
+	baseBuilds, err := build.Find(build.ByIds(baseVersion.BuildIds))
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem finding builds for version '%s'", baseVersion.Id)
+	}
+	baseBuildsByVariant := map[string]*build.Build{}
+	for i := range baseBuilds {
+		baseBuildsByVariant[baseBuilds[i].BuildVariant] = &baseBuilds[i]
+	}
+
+	// pair each patch task with its base task
+	pairs := []TaskStatusDiff{}
+	taskIds := []string{}
+	for i := range patchBuilds {
+		buildDiff := StatusDiffBuilds(baseBuildsByVariant[patchBuilds[i].BuildVariant], &patchBuilds[i])
+		for _, taskDiff := range buildDiff.Tasks {
+			if taskDiff.Original == "" || taskDiff.Patch == "" {
+				continue
+			}
+			pairs = append(pairs, taskDiff)
+			taskIds = append(taskIds, taskDiff.Original, taskDiff.Patch)
+		}
+	}
+	if len(pairs) == 0 {
+		return diff, nil
+	}
+
+	tasks, err := task.Find(task.ByIds(taskIds).WithFields(task.IdKey, task.ExecutionKey))
+	if err != nil {
+		return nil, errors.Wrap(err, "problem finding tasks")
+	}
+	executions := map[string]int{}
+	for _, t := range tasks {
+		executions[t.Id] = t.Execution
+	}
+	files, err := coverage.FindByTaskExecutions(executions)
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem finding coverage for patch '%s'", patchVersion.Id)
+	}
+	byTask := map[string][]coverage.FileCoverage{}
+	for _, f := range files {
+		byTask[f.TaskID] = append(byTask[f.TaskID], f)
+	}
+
+	originalFiles := []coverage.FileCoverage{}
+	patchFiles := []coverage.FileCoverage{}
+	for _, pair := range pairs {
+		original, patch := byTask[pair.Original], byTask[pair.Patch]
+		if len(original) == 0 && len(patch) == 0 {
+			continue
+		}
+		originalFiles = append(originalFiles, original...)
+		patchFiles = append(patchFiles, patch...)
+		diff.Tasks = append(diff.Tasks, TaskCoverageDiff{
+			Name:         pair.Name,
+			BuildVariant: pair.BuildVariant,
+			Original:     pair.Original,
+			Patch:        pair.Patch,
+			Files:        CoverageDiffFiles(coverage.Merge(original), coverage.Merge(patch)),
+		})
+	}
+	diff.Files = CoverageDiffFiles(coverage.Merge(originalFiles), coverage.Merge(patchFiles))
+
+	return diff, nil
+}
@@ -0,0 +1,200 @@
+package coverage
+
+import (
+	"sort"
+
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/mongodb/anser/bsonutil"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	// Collection is the name of the coverage collection in the database.
+	Collection = "coverage"
+)
+
+// FileCoverage is the line coverage of one source file, as reported by a
+// task execution. A task may report the same file more than once, e.g.
+// from unit and integration test runs, in which case the reports are
+// merged when they are read.
+type FileCoverage struct {
+	ID   bson.ObjectId `bson:"_id,omitempty" json:"-"`
+	File string        `bson:"file" json:"file"`
+	// Covered and Uncovered are the numbers of the lines that did and
+	// did not run, in ascending order. Lines that are not code, such as
+	// comments, are in neither.
+	Covered   []int `bson:"covered" json:"covered"`
+	Uncovered []int `bson:"uncovered" json:"uncovered"`
+
+	// Together, TaskID and Execution identify the task which reported
+	// the coverage.
+	TaskID    string `bson:"task_id" json:"task_id"`
+	Execution int    `bson:"execution" json:"execution"`
+
+	Version      string `bson:"version,omitempty" json:"version,omitempty"`
+	Project      string `bson:"project,omitempty" json:"project,omitempty"`
+	BuildVariant string `bson:"build_variant,omitempty" json:"build_variant,omitempty"`
+	TaskName     string `bson:"task_name,omitempty" json:"task_name,omitempty"`
+}
+
+var (
+	// BSON fields for the file coverage struct
+	IDKey           = bsonutil.MustHaveTag(FileCoverage{}, "ID")
+	FileKey         = bsonutil.MustHaveTag(FileCoverage{}, "File")
+	CoveredKey      = bsonutil.MustHaveTag(FileCoverage{}, "Covered")
+	UncoveredKey    = bsonutil.MustHaveTag(FileCoverage{}, "Uncovered")
+	TaskIDKey       = bsonutil.MustHaveTag(FileCoverage{}, "TaskID")
+	ExecutionKey    = bsonutil.MustHaveTag(FileCoverage{}, "Execution")
+	VersionKey      = bsonutil.MustHaveTag(FileCoverage{}, "Version")
+	ProjectKey      = bsonutil.MustHaveTag(FileCoverage{}, "Project")
+	BuildVariantKey = bsonutil.MustHaveTag(FileCoverage{}, "BuildVariant")
+	TaskNameKey     = bsonutil.MustHaveTag(FileCoverage{}, "TaskName")
+)
+
+// Summary counts the covered lines of a file or set of files.
+type Summary struct {
+	Covered int `json:"covered"`
+	Total   int `json:"total"`
+}
+
+// Percent returns the percentage of lines that are covered, or 0 if there
+// are no lines.
+func (s Summary) Percent() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return 100 * float64(s.Covered) / float64(s.Total)
+}
+
+// Summary returns the number of covered lines in the file.
+func (f *FileCoverage) Summary() Summary {
+	return Summary{
+		Covered: len(f.Covered),
+		Total:   len(f.Covered) + len(f.Uncovered),
+	}
+}
+
+// Insert writes the file coverage to the database.
+func (f *FileCoverage) Insert() error {
+	if f.TaskID == "" {
+		return errors.New("cannot insert coverage with empty task ID")
+	}
+	if f.File == "" {
+		return errors.New("cannot insert coverage with empty file name")
+	}
+	return db.Insert(Collection, f)
+}
+
+// InsertMany writes the coverage of many files to the database.
+func InsertMany(files []FileCoverage) error {
+	catcher := grip.NewSimpleCatcher()
+	for i := range files {
+		catcher.Add(files[i].Insert())
+	}
+	return catcher.Resolve()
+}
+
+// Find returns all file coverage that satisfies the query.
+func Find(query db.Q) ([]FileCoverage, error) {
+	files := []FileCoverage{}
+	err := db.FindAllQ(Collection, query, &files)
+	return files, err
+}
+
+// FindByTaskIDAndExecution returns the coverage reported by a task
+// execution.
+func FindByTaskIDAndExecution(taskID string, execution int) ([]FileCoverage, error) {
+	return Find(db.Query(bson.M{
+		TaskIDKey:    taskID,
+		ExecutionKey: execution,
+	}))
+}
+
+// FindByTaskExecutions returns the coverage reported by a set of tasks,
+// given the execution of each task by its id.
+func FindByTaskExecutions(executions map[string]int) ([]FileCoverage, error) {
+	if len(executions) == 0 {
+		return []FileCoverage{}, nil
+	}
+	ids := make([]string, 0, len(executions))
+	for id := range executions {
+		ids = append(ids, id)
+	}
+
+	files, err := Find(db.Query(bson.M{TaskIDKey: bson.M{"$in": ids}}))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	out := []FileCoverage{}
+	for _, f := range files {
+		if f.Execution == executions[f.TaskID] {
+			out = append(out, f)
+		}
+	}
+	return out, nil
+}
+
+// Merge combines the coverage of the same files, e.g. from different
+// tasks, into a single coverage per file, sorted by file name. A line is
+// covered if any of the reports covered it. The task fields of the merged
+// coverage are left unset.
+func Merge(files []FileCoverage) []FileCoverage {
+	lines := map[string]map[int]bool{}
+	names := []string{}
+	for _, f := range files {
+		fileLines, ok := lines[f.File]
+		if !ok {
+			fileLines = map[int]bool{}
+			lines[f.File] = fileLines
+			names = append(names, f.File)
+		}
+		for _, l := range f.Uncovered {
+			if _, ok := fileLines[l]; !ok {
+				fileLines[l] = false
+			}
+		}
+		for _, l := range f.Covered {
+			fileLines[l] = true
+		}
+	}
+	sort.Strings(names)
+
+	out := make([]FileCoverage, 0, len(names))
+	for _, name := range names {
+		out = append(out, NewFileCoverage(name, lines[name]))
+	}
+	return out
+}
+
+// NewFileCoverage makes the coverage of a file from whether each of its
+// lines is covered.
+func NewFileCoverage(file string, lines map[int]bool) FileCoverage {
+	f := FileCoverage{
+		File:      file,
+		Covered:   []int{},
+		Uncovered: []int{},
+	}
+	for l, covered := range lines {
+		if covered {
+			f.Covered = append(f.Covered, l)
+		} else {
+			f.Uncovered = append(f.Uncovered, l)
+		}
+	}
+	sort.Ints(f.Covered)
+	sort.Ints(f.Uncovered)
+	return f
+}
+
+// Total returns the number of covered lines across the files.
+func Total(files []FileCoverage) Summary {
+	total := Summary{}
+	for i := range files {
+		s := files[i].Summary()
+		total.Covered += s.Covered
+		total.Total += s.Total
+	}
+	return total
+}
@@ -0,0 +1,99 @@
+package coverage
+
+import (
+	"testing"
+
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestMerge(t *testing.T) {
+	assert := assert.New(t)
+
+	merged := Merge([]FileCoverage{
+		{File: "b.go", Covered: []int{1}, Uncovered: []int{2, 3}, TaskID: "unit"},
+		{File: "a.go", Covered: []int{4}, Uncovered: []int{5}, TaskID: "unit"},
+		{File: "b.go", Covered: []int{3}, Uncovered: []int{1, 4}, TaskID: "integration"},
+	})
+	assert.Len(merged, 2)
+
+	assert.Equal("a.go", merged[0].File)
+	assert.Equal([]int{4}, merged[0].Covered)
+	assert.Equal([]int{5}, merged[0].Uncovered)
+
+	// a line is covered if any report covers it
+	assert.Equal("b.go", merged[1].File)
+	assert.Equal([]int{1, 3}, merged[1].Covered)
+	assert.Equal([]int{2, 4}, merged[1].Uncovered)
+	assert.Empty(merged[1].TaskID)
+
+	assert.Empty(Merge(nil))
+}
+
+func TestSummary(t *testing.T) {
+	assert := assert.New(t)
+
+	files := []FileCoverage{
+		{File: "a.go", Covered: []int{1, 2, 3}, Uncovered: []int{4}},
+		{File: "b.go", Covered: []int{}, Uncovered: []int{1, 2, 3, 4}},
+	}
+	assert.Equal(Summary{Covered: 3, Total: 4}, files[0].Summary())
+	assert.Equal(75.0, files[0].Summary().Percent())
+
+	total := Total(files)
+	assert.Equal(Summary{Covered: 3, Total: 8}, total)
+	assert.Equal(37.5, total.Percent())
+
+	assert.Equal(0.0, Summary{}.Percent())
+}
+
+type CoverageSuite struct {
+	suite.Suite
+}
+
+func TestCoverageSuite(t *testing.T) {
+	suite.Run(t, new(CoverageSuite))
+}
+
+func (s *CoverageSuite) SetupSuite() {
+	db.SetGlobalSessionProvider(testutil.TestConfig().SessionFactory())
+}
+
+func (s *CoverageSuite) SetupTest() {
+	s.Require().NoError(db.Clear(Collection))
+}
+
+func (s *CoverageSuite) TestInsertValidates() {
+	s.Error((&FileCoverage{File: "a.go"}).Insert())
+	s.Error((&FileCoverage{TaskID: "t1"}).Insert())
+}
+
+func (s *CoverageSuite) TestFindByTaskExecutions() {
+	s.Require().NoError(InsertMany([]FileCoverage{
+		{File: "a.go", Covered: []int{1}, TaskID: "t1", Execution: 0},
+		{File: "a.go", Covered: []int{1, 2}, TaskID: "t1", Execution: 1},
+		{File: "b.go", Covered: []int{1}, TaskID: "t1", Execution: 1},
+		{File: "a.go", Uncovered: []int{1}, TaskID: "t2", Execution: 0},
+		{File: "c.go", Uncovered: []int{1}, TaskID: "t3", Execution: 0},
+	}))
+
+	files, err := FindByTaskIDAndExecution("t1", 1)
+	s.NoError(err)
+	s.Len(files, 2)
+
+	files, err = FindByTaskExecutions(map[string]int{"t1": 1, "t2": 0})
+	s.NoError(err)
+	s.Len(files, 3)
+	for _, f := range files {
+		s.NotEqual("c.go", f.File)
+		if f.TaskID == "t1" {
+			s.Equal(1, f.Execution)
+		}
+	}
+
+	files, err = FindByTaskExecutions(map[string]int{})
+	s.NoError(err)
+	s.Empty(files)
+}
@@ -0,0 +1,134 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model/build"
+	"github.com/evergreen-ci/evergreen/model/coverage"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/model/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoverageDiffFiles(t *testing.T) {
+	assert := assert.New(t)
+
+	original := []coverage.FileCoverage{
+		{File: "a.go", Covered: []int{1, 2}, Uncovered: []int{3, 4}},
+		{File: "b.go", Covered: []int{1, 2, 3, 4}},
+		{File: "c.go", Covered: []int{1}, Uncovered: []int{2}},
+		{File: "removed.go", Covered: []int{1}},
+	}
+	patch := []coverage.FileCoverage{
+		{File: "a.go", Covered: []int{1, 2, 3}, Uncovered: []int{4}},
+		{File: "added.go", Uncovered: []int{1}},
+		{File: "b.go", Covered: []int{1}, Uncovered: []int{2, 3, 4}},
+		{File: "c.go", Uncovered: []int{1, 2}},
+	}
+
+	diffs := CoverageDiffFiles(original, patch)
+	files := []string{}
+	for _, d := range diffs {
+		files = append(files, d.File)
+	}
+	// drops come first, the largest first, then the rest by name
+	assert.Equal([]string{"b.go", "c.go", "a.go", "added.go", "removed.go"}, files)
+
+	assert.Equal(coverage.Summary{Covered: 4, Total: 4}, diffs[0].Original)
+	assert.Equal(coverage.Summary{Covered: 1, Total: 4}, diffs[0].Patch)
+	assert.Equal(-75.0, diffs[0].Delta())
+	assert.Equal(25.0, diffs[2].Delta())
+
+	// files in only one of the versions have no delta
+	assert.Equal(coverage.Summary{}, diffs[3].Original)
+	assert.Equal(0.0, diffs[3].Delta())
+	assert.Equal(coverage.Summary{}, diffs[4].Patch)
+	assert.Equal(0.0, diffs[4].Delta())
+}
+
+func TestCoverageDiffVersion(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	require.NoError(db.ClearCollections(version.Collection, build.Collection, task.Collection, coverage.Collection))
+
+	base := &version.Version{
+		Id:         "base",
+		Identifier: "widgets",
+		Revision:   "abc",
+		Requester:  evergreen.RepotrackerVersionRequester,
+		BuildIds:   []string{"base_linux"},
+	}
+	patch := &version.Version{
+		Id:         "patch",
+		Identifier: "widgets",
+		Revision:   "abc",
+		Requester:  evergreen.PatchVersionRequester,
+		BuildIds:   []string{"patch_linux"},
+	}
+	require.NoError(base.Insert())
+	require.NoError(patch.Insert())
+
+	builds := []build.Build{
+		{
+			Id:           "base_linux",
+			BuildVariant: "linux",
+			DisplayName:  "Linux",
+			Tasks: []build.TaskCache{
+				{Id: "base_unit", DisplayName: "unit"},
+				{Id: "base_lint", DisplayName: "lint"},
+				{Id: "base_integration", DisplayName: "integration"},
+			},
+		},
+		{
+			Id:           "patch_linux",
+			BuildVariant: "linux",
+			DisplayName:  "Linux",
+			Tasks: []build.TaskCache{
+				{Id: "patch_unit", DisplayName: "unit"},
+				{Id: "patch_lint", DisplayName: "lint"},
+			},
+		},
+	}
+	for i := range builds {
+		require.NoError(builds[i].Insert())
+	}
+	for _, t := range []task.Task{
+		{Id: "base_unit", Execution: 0},
+		{Id: "base_lint", Execution: 0},
+		{Id: "base_integration", Execution: 0},
+		{Id: "patch_unit", Execution: 1},
+		{Id: "patch_lint", Execution: 0},
+	} {
+		require.NoError(t.Insert())
+	}
+	require.NoError(coverage.InsertMany([]coverage.FileCoverage{
+		{File: "a.go", Covered: []int{1, 2}, Uncovered: []int{3, 4}, TaskID: "base_unit", Execution: 0},
+		{File: "a.go", Covered: []int{3, 4}, TaskID: "base_integration", Execution: 0},
+		{File: "a.go", Covered: []int{1, 2, 3, 4}, TaskID: "patch_unit", Execution: 0},
+		{File: "a.go", Covered: []int{1}, Uncovered: []int{2, 3, 4}, TaskID: "patch_unit", Execution: 1},
+	}))
+
+	diff, err := CoverageDiffVersion(patch)
+	require.NoError(err)
+	assert.Equal("patch", diff.Version)
+	assert.Equal("base", diff.BaseVersion)
+
+	// the integration task didn't run in the patch, and lint reported
+	// no coverage
+	require.Len(diff.Tasks, 1)
+	assert.Equal("unit", diff.Tasks[0].Name)
+	assert.Equal("Linux", diff.Tasks[0].BuildVariant)
+	assert.Equal("base_unit", diff.Tasks[0].Original)
+	assert.Equal("patch_unit", diff.Tasks[0].Patch)
+
+	require.Len(diff.Files, 1)
+	assert.Equal(coverage.Summary{Covered: 2, Total: 4}, diff.Files[0].Original)
+	assert.Equal(coverage.Summary{Covered: 1, Total: 4}, diff.Files[0].Patch)
+	assert.Equal(-25.0, diff.Files[0].Delta())
+
+	_, err = CoverageDiffVersion(base)
+	assert.Error(err)
+}
@@ -0,0 +1,54 @@
+package distro
+
+import "github.com/evergreen-ci/evergreen/util"
+
+// Capabilities describes the hosts of a distro, so that tasks can select
+// distros by what they require rather than by name. A distro's architecture
+// is its Arch.
+type Capabilities struct {
+	OS       string   `bson:"os,omitempty" json:"os,omitempty" mapstructure:"os,omitempty"`
+	CPUs     int      `bson:"cpus,omitempty" json:"cpus,omitempty" mapstructure:"cpus,omitempty"`
+	MemoryGB int      `bson:"memory_gb,omitempty" json:"memory_gb,omitempty" mapstructure:"memory_gb,omitempty"`
+	GPU      bool     `bson:"gpu,omitempty" json:"gpu,omitempty" mapstructure:"gpu,omitempty"`
+	Docker   bool     `bson:"docker,omitempty" json:"docker,omitempty" mapstructure:"docker,omitempty"`
+	Tags     []string `bson:"tags,omitempty" json:"tags,omitempty" mapstructure:"tags,omitempty"`
+}
+
+// Requirements constrain the capabilities of the distros that a task can
+// run on. Unset fields don't constrain the distro. GPU and Docker, when set,
+// must match the distro exactly, so tasks can also require GPU-free hosts.
+// A distro must have all of the required tags.
+type Requirements struct {
+	Arch        string   `yaml:"arch,omitempty" bson:"arch,omitempty"`
+	OS          string   `yaml:"os,omitempty" bson:"os,omitempty"`
+	MinCPUs     int      `yaml:"min_cpus,omitempty" bson:"min_cpus,omitempty"`
+	MinMemoryGB int      `yaml:"min_memory_gb,omitempty" bson:"min_memory_gb,omitempty"`
+	GPU         *bool    `yaml:"gpu,omitempty" bson:"gpu,omitempty"`
+	Docker      *bool    `yaml:"docker,omitempty" bson:"docker,omitempty"`
+	Tags        []string `yaml:"tags,omitempty" bson:"tags,omitempty"`
+}
+
+// SatisfiedBy returns true if the distro has the required capabilities.
+func (r *Requirements) SatisfiedBy(d *Distro) bool {
+	if r.Arch != "" && r.Arch != d.Arch {
+		return false
+	}
+	if r.OS != "" && r.OS != d.Capabilities.OS {
+		return false
+	}
+	if r.MinCPUs > d.Capabilities.CPUs || r.MinMemoryGB > d.Capabilities.MemoryGB {
+		return false
+	}
+	if r.GPU != nil && *r.GPU != d.Capabilities.GPU {
+		return false
+	}
+	if r.Docker != nil && *r.Docker != d.Capabilities.Docker {
+		return false
+	}
+	for _, tag := range r.Tags {
+		if !util.StringSliceContains(d.Capabilities.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
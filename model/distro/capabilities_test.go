@@ -0,0 +1,43 @@
+package distro
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequirementsSatisfiedBy(t *testing.T) {
+	assert := assert.New(t)
+
+	yes, no := true, false
+	d := &Distro{
+		Id:   "big-linux",
+		Arch: "linux_amd64",
+		Capabilities: Capabilities{
+			OS:       "ubuntu1604",
+			CPUs:     16,
+			MemoryGB: 64,
+			Docker:   true,
+			Tags:     []string{"ssd", "large"},
+		},
+	}
+
+	assert.True((&Requirements{}).SatisfiedBy(d))
+	assert.True((&Requirements{
+		Arch:        "linux_amd64",
+		OS:          "ubuntu1604",
+		MinCPUs:     16,
+		MinMemoryGB: 32,
+		GPU:         &no,
+		Docker:      &yes,
+		Tags:        []string{"ssd"},
+	}).SatisfiedBy(d))
+
+	assert.False((&Requirements{Arch: "windows_amd64"}).SatisfiedBy(d))
+	assert.False((&Requirements{OS: "rhel70"}).SatisfiedBy(d))
+	assert.False((&Requirements{MinCPUs: 32}).SatisfiedBy(d))
+	assert.False((&Requirements{MinMemoryGB: 128}).SatisfiedBy(d))
+	assert.False((&Requirements{GPU: &yes}).SatisfiedBy(d))
+	assert.False((&Requirements{Docker: &no}).SatisfiedBy(d))
+	assert.False((&Requirements{Tags: []string{"ssd", "gpu"}}).SatisfiedBy(d))
+}
@@ -20,11 +20,18 @@ var (
 	SSHOptionsKey       = bsonutil.MustHaveTag(Distro{}, "SSHOptions")
 	WorkDirKey          = bsonutil.MustHaveTag(Distro{}, "WorkDir")
 
-	UserDataKey = bsonutil.MustHaveTag(Distro{}, "UserData")
+	UserDataKey        = bsonutil.MustHaveTag(Distro{}, "UserData")
+	BootstrapMethodKey = bsonutil.MustHaveTag(Distro{}, "BootstrapMethod")
 
 	SpawnAllowedKey = bsonutil.MustHaveTag(Distro{}, "SpawnAllowed")
 	ExpansionsKey   = bsonutil.MustHaveTag(Distro{}, "Expansions")
 
+	PrioritizationKey = bsonutil.MustHaveTag(Distro{}, "Prioritization")
+	ProjectQuotasKey  = bsonutil.MustHaveTag(Distro{}, "ProjectQuotas")
+
+	CapabilitiesKey = bsonutil.MustHaveTag(Distro{}, "Capabilities")
+	HourlyCostKey   = bsonutil.MustHaveTag(Distro{}, "HourlyCost")
+
 	// bson fields for the UserData struct
 	UserDataFileKey     = bsonutil.MustHaveTag(UserData{}, "File")
 	UserDataValidateKey = bsonutil.MustHaveTag(UserData{}, "Validate")
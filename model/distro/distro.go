@@ -32,8 +32,126 @@ type Distro struct {
 	SSHOptions  []string `bson:"ssh_options,omitempty" json:"ssh_options,omitempty" mapstructure:"ssh_options,omitempty"`
 	UserData    UserData `bson:"user_data,omitempty" json:"user_data,omitempty" mapstructure:"user_data,omitempty"`
 
+	BootstrapMethod string `bson:"bootstrap_method,omitempty" json:"bootstrap_method,omitempty" mapstructure:"bootstrap_method,omitempty"`
+
 	SpawnAllowed bool        `bson:"spawn_allowed" json:"spawn_allowed,omitempty" mapstructure:"spawn_allowed,omitempty"`
 	Expansions   []Expansion `bson:"expansions,omitempty" json:"expansions,omitempty" mapstructure:"expansions,omitempty"`
+
+	Prioritization PrioritizationSettings `bson:"prioritization,omitempty" json:"prioritization,omitempty" mapstructure:"prioritization,omitempty"`
+	ProjectQuotas  []ProjectQuota         `bson:"project_quotas,omitempty" json:"project_quotas,omitempty" mapstructure:"project_quotas,omitempty"`
+
+	// Capabilities and HourlyCost are used to choose a distro for tasks
+	// that declare distro requirements: they run on the cheapest distro
+	// with free capacity that satisfies them.
+	Capabilities Capabilities `bson:"capabilities,omitempty" json:"capabilities,omitempty" mapstructure:"capabilities,omitempty"`
+	HourlyCost   float64      `bson:"hourly_cost,omitempty" json:"hourly_cost,omitempty" mapstructure:"hourly_cost,omitempty"`
+}
+
+// Methods for bootstrapping a distro's hosts. Hosts bootstrapped over SSH
+// have their setup script run and their agent started by the app server,
+// while hosts bootstrapped with user data download the agent, register
+// themselves and run their setup script without any inbound connections.
+const (
+	BootstrapMethodSSH      = "ssh"
+	BootstrapMethodUserData = "user-data"
+)
+
+// ValidBootstrapMethods lists the methods a distro may use to bootstrap
+// its hosts.
+var ValidBootstrapMethods = []string{
+	BootstrapMethodSSH,
+	BootstrapMethodUserData,
+}
+
+// BootstrapsWithUserData returns true if the distro's hosts bootstrap
+// themselves from their user data.
+func (d *Distro) BootstrapsWithUserData() bool {
+	return d.BootstrapMethod == BootstrapMethodUserData
+}
+
+// Task prioritization strategies, which decide the order of a
+// distro's task queue.
+const (
+	PrioritizationStrategyDefault          = "default"
+	PrioritizationStrategyFIFO             = "fifo"
+	PrioritizationStrategyShortestJobFirst = "shortest-job-first"
+	PrioritizationStrategyFairShare        = "fair-share"
+	PrioritizationStrategyPatchFirst       = "patch-first"
+)
+
+// ValidPrioritizationStrategies lists the strategies a distro may use.
+var ValidPrioritizationStrategies = []string{
+	PrioritizationStrategyDefault,
+	PrioritizationStrategyFIFO,
+	PrioritizationStrategyShortestJobFirst,
+	PrioritizationStrategyFairShare,
+	PrioritizationStrategyPatchFirst,
+}
+
+// Names of the factors that prioritization strategies weigh when
+// comparing two tasks.
+const (
+	PrioritizationFactorPriority        = "priority"
+	PrioritizationFactorNumDeps         = "num_deps"
+	PrioritizationFactorAge             = "age"
+	PrioritizationFactorCreateTime      = "create_time"
+	PrioritizationFactorRuntime         = "runtime"
+	PrioritizationFactorShortestRuntime = "shortest_runtime"
+	PrioritizationFactorSimilarFailing  = "similar_failing"
+	PrioritizationFactorRecentlyFailing = "recently_failing"
+	PrioritizationFactorFairShare       = "fair_share"
+)
+
+// ValidPrioritizationFactors lists the factors that may be weighted.
+var ValidPrioritizationFactors = []string{
+	PrioritizationFactorPriority,
+	PrioritizationFactorNumDeps,
+	PrioritizationFactorAge,
+	PrioritizationFactorCreateTime,
+	PrioritizationFactorRuntime,
+	PrioritizationFactorShortestRuntime,
+	PrioritizationFactorSimilarFailing,
+	PrioritizationFactorRecentlyFailing,
+	PrioritizationFactorFairShare,
+}
+
+// PrioritizationSettings selects the strategy the scheduler uses to
+// order the distro's task queue. Weights override the strategy's
+// default weight for a factor; a weight of zero disables the factor.
+type PrioritizationSettings struct {
+	Strategy string         `bson:"strategy,omitempty" json:"strategy,omitempty" mapstructure:"strategy,omitempty"`
+	Weights  map[string]int `bson:"weights,omitempty" json:"weights,omitempty" mapstructure:"weights,omitempty"`
+}
+
+// GetStrategy returns the distro's prioritization strategy, or the
+// default strategy if none is set.
+func (s PrioritizationSettings) GetStrategy() string {
+	if s.Strategy == "" {
+		return PrioritizationStrategyDefault
+	}
+	return s.Strategy
+}
+
+// ProjectQuota limits a project's share of a distro's hosts. While the
+// project has tasks queued, it is guaranteed up to Guaranteed hosts, and
+// it may burst up to Ceiling hosts when others aren't using them. A
+// ceiling of zero means that the project's use of the distro is only
+// limited by the distro's pool size.
+type ProjectQuota struct {
+	Project    string `bson:"project" json:"project" mapstructure:"project"`
+	Guaranteed int    `bson:"guaranteed,omitempty" json:"guaranteed,omitempty" mapstructure:"guaranteed,omitempty"`
+	Ceiling    int    `bson:"ceiling,omitempty" json:"ceiling,omitempty" mapstructure:"ceiling,omitempty"`
+}
+
+// GetProjectQuota returns the distro's quota for the given project, and
+// whether the distro has one.
+func (d *Distro) GetProjectQuota(project string) (ProjectQuota, bool) {
+	for _, q := range d.ProjectQuotas {
+		if q.Project == project {
+			return q, true
+		}
+	}
+	return ProjectQuota{}, false
 }
 
 type ValidateFormat string
@@ -25,6 +25,7 @@ const (
 	EventHostProvisionError       = "HOST_PROVISION_ERROR"
 	EventHostProvisionFailed      = "HOST_PROVISION_FAILED"
 	EventHostProvisioned          = "HOST_PROVISIONED"
+	EventHostSetupProgress        = "HOST_SETUP_PROGRESS"
 	EventHostRunningTaskSet       = "HOST_RUNNING_TASK_SET"
 	EventHostRunningTaskCleared   = "HOST_RUNNING_TASK_CLEARED"
 	EventHostTaskPidSet           = "HOST_TASK_PID_SET"
@@ -136,6 +137,12 @@ func LogProvisionFailed(hostId string, setupLogs string) {
 	LogHostEvent(hostId, EventHostProvisionFailed, HostEventData{Logs: setupLogs})
 }
 
+// LogHostSetupProgress records output from a setup script that a host is
+// running on itself.
+func LogHostSetupProgress(hostId string, setupLogs string) {
+	LogHostEvent(hostId, EventHostSetupProgress, HostEventData{Logs: setupLogs})
+}
+
 func LogHostTeardown(hostId, teardownLogs string, success bool, duration time.Duration) {
 	LogHostEvent(hostId, EventHostTeardown,
 		HostEventData{Logs: teardownLogs, Successful: success, Duration: duration})
@@ -0,0 +1,355 @@
+package model
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/evergreen-ci/evergreen/model/build"
+	"github.com/evergreen-ci/evergreen/model/patch"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/model/version"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// GeneratedProject is a fragment of a project configuration that a running
+// task generated with generate.tasks. Its functions and tasks must be new
+// to the task's version. Its build variants are added to the version if
+// they're new, and otherwise add their tasks and display tasks to the
+// version's variants of the same name.
+type GeneratedProject struct {
+	Functions     map[string]*YAMLCommandSet
+	Tasks         []parserTask
+	BuildVariants []parserBV
+}
+
+// ParseGeneratedProject parses and merges the files a task generated. The
+// files are YAML, or JSON, which is a subset of it.
+func ParseGeneratedProject(files []string) (*GeneratedProject, error) {
+	g := &GeneratedProject{Functions: map[string]*YAMLCommandSet{}}
+	tasks := map[string]bool{}
+	variants := map[string]bool{}
+	catcher := grip.NewBasicCatcher()
+	for i, data := range files {
+		pp, errs := createIntermediateProject([]byte(data))
+		if len(errs) > 0 {
+			for _, err := range errs {
+				catcher.Add(errors.Wrapf(err, "problem parsing generated file %d", i+1))
+			}
+			continue
+		}
+		if !onlyHasGeneratableFields(pp) {
+			catcher.Add(errors.Errorf("generated file %d can only define functions, tasks and build variants", i+1))
+			continue
+		}
+
+		for name, commands := range pp.Functions {
+			if _, ok := g.Functions[name]; ok {
+				catcher.Add(errors.Errorf("function '%s' is generated more than once", name))
+				continue
+			}
+			g.Functions[name] = commands
+		}
+		for _, t := range pp.Tasks {
+			if tasks[t.Name] {
+				catcher.Add(errors.Errorf("task '%s' is generated more than once", t.Name))
+				continue
+			}
+			tasks[t.Name] = true
+			g.Tasks = append(g.Tasks, t)
+		}
+		for _, bv := range pp.BuildVariants {
+			if bv.matrix != nil {
+				catcher.Add(errors.Errorf("generated file %d defines matrix '%s', but matrices can't be generated",
+					i+1, bv.matrix.Id))
+				continue
+			}
+			if variants[bv.Name] {
+				catcher.Add(errors.Errorf("build variant '%s' is generated more than once", bv.Name))
+				continue
+			}
+			variants[bv.Name] = true
+			g.BuildVariants = append(g.BuildVariants, bv)
+		}
+	}
+	if catcher.HasErrors() {
+		return nil, catcher.Resolve()
+	}
+	return g, nil
+}
+
+// onlyHasGeneratableFields returns true if the project only sets the
+// fields that generated files can define.
+func onlyHasGeneratableFields(pp *parserProject) bool {
+	rest := *pp
+	rest.Functions = nil
+	rest.Tasks = nil
+	rest.BuildVariants = nil
+	return reflect.DeepEqual(rest, parserProject{})
+}
+
+// NewProject returns the version's project with the generated functions,
+// tasks and build variants added to it. Build variants that the version
+// already has only take the tasks and display tasks of the generated
+// variant.
+func (g *GeneratedProject) NewProject(v *version.Version) (*Project, error) {
+	pp, errs := createIntermediateProject([]byte(v.Config))
+	if len(errs) > 0 {
+		return nil, errors.Wrapf(errs[0], "problem parsing config for version '%s'", v.Id)
+	}
+
+	catcher := grip.NewBasicCatcher()
+	if pp.Functions == nil && len(g.Functions) > 0 {
+		pp.Functions = map[string]*YAMLCommandSet{}
+	}
+	for name, commands := range g.Functions {
+		if _, ok := pp.Functions[name]; ok {
+			catcher.Add(errors.Errorf("generated function '%s' is already defined in the project", name))
+			continue
+		}
+		pp.Functions[name] = commands
+	}
+	for _, t := range g.Tasks {
+		for _, existing := range pp.Tasks {
+			if existing.Name == t.Name {
+				catcher.Add(errors.Errorf("generated task '%s' is already defined in the project", t.Name))
+				break
+			}
+		}
+		pp.Tasks = append(pp.Tasks, t)
+	}
+	for _, bv := range g.BuildVariants {
+		existing := false
+		for i := range pp.BuildVariants {
+			if pp.BuildVariants[i].Name == bv.Name {
+				pp.BuildVariants[i].Tasks = append(pp.BuildVariants[i].Tasks, bv.Tasks...)
+				pp.BuildVariants[i].DisplayTasks = append(pp.BuildVariants[i].DisplayTasks, bv.DisplayTasks...)
+				existing = true
+				break
+			}
+		}
+		if !existing {
+			pp.BuildVariants = append(pp.BuildVariants, bv)
+		}
+	}
+	if catcher.HasErrors() {
+		return nil, catcher.Resolve()
+	}
+
+	p, errs := translateProject(pp)
+	if len(errs) > 0 {
+		for _, err := range errs {
+			catcher.Add(err)
+		}
+		return nil, errors.Wrap(catcher.Resolve(), "problem evaluating generated project")
+	}
+	p.Identifier = v.Identifier
+	return p, nil
+}
+
+// AddGeneratedTasks saves the project as the version's config, along with
+// the config of the version's patch if it has one, and records that the task
+// with the given id generated the tasks and display tasks the project has
+// that the version's old config didn't. It then creates and activates them.
+// It returns false without doing anything if the version's config is no
+// longer the one in v, e.g. because another task in the version generated
+// tasks first. If creating the tasks fails, FinishGeneratedTasks creates the
+// rest of them.
+func AddGeneratedTasks(v *version.Version, p *Project, taskId string) (bool, error) {
+	oldProject := &Project{}
+	if err := LoadProjectInto([]byte(v.Config), v.Identifier, oldProject); err != nil {
+		return false, errors.Wrapf(err, "problem loading config for version '%s'", v.Id)
+	}
+	newTasks := newTaskVariantPairs(oldProject, p)
+	for _, pair := range newTasks.DisplayTasks {
+		// creating a build's tasks without names creates all of them
+		if len(newTasks.ExecTasks.TaskNames(pair.Variant)) == 0 {
+			return false, errors.Errorf("generated display task '%s' on variant '%s' has no new execution tasks",
+				pair.TaskName, pair.Variant)
+		}
+	}
+
+	config, err := yaml.Marshal(p)
+	if err != nil {
+		return false, errors.Wrap(err, "problem marshalling generated project")
+	}
+
+	generated := version.GeneratedTasks{
+		TaskId:       taskId,
+		Tasks:        toVariantTasks(newTasks.ExecTasks),
+		DisplayTasks: toVariantTasks(newTasks.DisplayTasks),
+	}
+	err = version.UpdateOne(
+		bson.M{version.IdKey: v.Id, version.ConfigKey: v.Config},
+		bson.M{
+			"$set":  bson.M{version.ConfigKey: string(config)},
+			"$push": bson.M{version.GeneratedTasksKey: generated},
+		},
+	)
+	if err == mgo.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "problem updating config for version '%s'", v.Id)
+	}
+	v.Config = string(config)
+	v.GeneratedTasks = append(v.GeneratedTasks, generated)
+
+	err = patch.UpdateOne(
+		bson.M{patch.VersionKey: v.Id},
+		bson.M{"$set": bson.M{patch.PatchedConfigKey: string(config)}},
+	)
+	if err != nil && err != mgo.ErrNotFound {
+		return true, errors.Wrapf(err, "problem updating patched config for version '%s'", v.Id)
+	}
+
+	return true, errors.WithStack(createGeneratedTasks(v, p, newTasks))
+}
+
+// FinishGeneratedTasks creates the tasks that AddGeneratedTasks added to the
+// version's config that don't exist yet, which finishes adding them if
+// creating them failed part way.
+func FinishGeneratedTasks(v *version.Version, generated *version.GeneratedTasks) error {
+	p := &Project{}
+	if err := LoadProjectInto([]byte(v.Config), v.Identifier, p); err != nil {
+		return errors.Wrapf(err, "problem loading config for version '%s'", v.Id)
+	}
+	return errors.WithStack(createGeneratedTasks(v, p, TaskVariantPairs{
+		ExecTasks:    fromVariantTasks(generated.Tasks),
+		DisplayTasks: fromVariantTasks(generated.DisplayTasks),
+	}))
+}
+
+// createGeneratedTasks creates and activates the generated tasks and display
+// tasks that the version doesn't have yet, adding builds to the version for
+// the variants that don't have them.
+func createGeneratedTasks(v *version.Version, p *Project, generated TaskVariantPairs) error {
+	existingTasks, err := task.Find(task.ByVersion(v.Id).WithFields(task.BuildVariantKey, task.DisplayNameKey))
+	if err != nil {
+		return errors.Wrapf(err, "problem finding tasks for version '%s'", v.Id)
+	}
+	exists := map[TVPair]bool{}
+	for _, t := range existingTasks {
+		exists[TVPair{Variant: t.BuildVariant, TaskName: t.DisplayName}] = true
+	}
+	missing := func(pairs TVPairSet, variant string) []string {
+		names := []string{}
+		for _, name := range pairs.TaskNames(variant) {
+			if !exists[TVPair{Variant: variant, TaskName: name}] {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+
+	taskIds := NewTaskIdTable(p, v)
+	existingBuilds, err := build.Find(build.ByVersion(v.Id))
+	if err != nil {
+		return errors.Wrapf(err, "problem finding builds for version '%s'", v.Id)
+	}
+
+	for _, bv := range p.BuildVariants {
+		taskNames := missing(generated.ExecTasks, bv.Name)
+		displayNames := missing(generated.DisplayTasks, bv.Name)
+		if len(taskNames) == 0 {
+			continue
+		}
+
+		var b *build.Build
+		for i := range existingBuilds {
+			if existingBuilds[i].BuildVariant == bv.Name {
+				b = &existingBuilds[i]
+				break
+			}
+		}
+		if b != nil {
+			b.Activated = true
+			if _, err = AddTasksToBuild(b, p, v, taskNames, displayNames); err != nil {
+				return errors.Wrapf(err, "problem adding generated tasks to build '%s'", b.Id)
+			}
+			if util.StringSliceContains(v.BuildIds, b.Id) {
+				continue
+			}
+		} else {
+			buildId, err := CreateBuildFromVersion(p, v, taskIds, bv.Name, true, taskNames, displayNames)
+			if err != nil {
+				return errors.Wrapf(err, "problem creating build for generated variant '%s'", bv.Name)
+			}
+			b = &build.Build{Id: buildId}
+		}
+
+		err = version.UpdateOne(
+			bson.M{version.IdKey: v.Id},
+			bson.M{
+				"$push": bson.M{
+					version.BuildIdsKey: b.Id,
+					version.BuildVariantsKey: version.BuildStatus{
+						BuildVariant: bv.Name,
+						BuildId:      b.Id,
+						Activated:    true,
+					},
+				},
+			},
+		)
+		if err != nil {
+			return errors.Wrapf(err, "problem adding build '%s' to version '%s'", b.Id, v.Id)
+		}
+		v.BuildIds = append(v.BuildIds, b.Id)
+	}
+	return nil
+}
+
+func toVariantTasks(pairs TVPairSet) []version.VariantTask {
+	out := make([]version.VariantTask, 0, len(pairs))
+	for _, pair := range pairs {
+		out = append(out, version.VariantTask{Variant: pair.Variant, Task: pair.TaskName})
+	}
+	return out
+}
+
+func fromVariantTasks(tasks []version.VariantTask) TVPairSet {
+	out := make(TVPairSet, 0, len(tasks))
+	for _, t := range tasks {
+		out = append(out, TVPair{Variant: t.Variant, TaskName: t.Task})
+	}
+	return out
+}
+
+// newTaskVariantPairs returns the tasks and display tasks of the new
+// project's variants that aren't in the old project's.
+func newTaskVariantPairs(oldProject, newProject *Project) TaskVariantPairs {
+	old := map[TVPair]bool{}
+	for _, bv := range oldProject.BuildVariants {
+		for _, t := range bv.Tasks {
+			old[TVPair{Variant: bv.Name, TaskName: t.Name}] = true
+		}
+		for _, dt := range bv.DisplayTasks {
+			old[TVPair{Variant: bv.Name, TaskName: dt.Name}] = true
+		}
+	}
+
+	pairs := TaskVariantPairs{}
+	for _, bv := range newProject.BuildVariants {
+		for _, t := range bv.Tasks {
+			if pair := (TVPair{Variant: bv.Name, TaskName: t.Name}); !old[pair] {
+				pairs.ExecTasks = append(pairs.ExecTasks, pair)
+			}
+		}
+		for _, dt := range bv.DisplayTasks {
+			if pair := (TVPair{Variant: bv.Name, TaskName: dt.Name}); !old[pair] {
+				pairs.DisplayTasks = append(pairs.DisplayTasks, pair)
+			}
+		}
+	}
+	return pairs
+}
+
+// String returns a summary of the generated project for logging.
+func (g *GeneratedProject) String() string {
+	return fmt.Sprintf("%d functions, %d tasks and %d build variants",
+		len(g.Functions), len(g.Tasks), len(g.BuildVariants))
+}
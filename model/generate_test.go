@@ -0,0 +1,252 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model/build"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/model/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const generateBaseConfig = `
+functions:
+  setup:
+  - command: shell.exec
+tasks:
+- name: compile
+  commands:
+  - func: setup
+- name: generator
+  commands:
+  - command: generate.tasks
+    params:
+      files: [shards.json]
+buildvariants:
+- name: linux
+  display_name: Linux
+  run_on: [ubuntu]
+  tasks: [compile, generator]
+`
+
+const generatedShards = `
+functions:
+  run shard:
+  - command: shell.exec
+tasks:
+- name: shard_1
+  depends_on:
+  - name: compile
+  commands:
+  - func: setup
+  - func: run shard
+- name: shard_2
+  commands:
+  - func: run shard
+buildvariants:
+- name: linux
+  tasks: [shard_1, shard_2]
+  display_tasks:
+  - name: shards
+    execution_tasks: [shard_1, shard_2]
+- name: windows
+  display_name: Windows
+  run_on: [windows]
+  tasks: [shard_1, compile]
+`
+
+func TestParseGeneratedProject(t *testing.T) {
+	assert := assert.New(t)
+
+	g, err := ParseGeneratedProject([]string{generatedShards, "tasks:\n- name: shard_3\n"})
+	assert.NoError(err)
+	assert.Len(g.Functions, 1)
+	assert.Len(g.Tasks, 3)
+	assert.Len(g.BuildVariants, 2)
+
+	for name, files := range map[string][]string{
+		"InvalidYAML":      {"tasks: {"},
+		"ProjectField":     {"stepback: true\ntasks:\n- name: shard_1\n"},
+		"DuplicateTask":    {"tasks:\n- name: shard_1\n", "tasks:\n- name: shard_1\n"},
+		"DuplicateVariant": {"buildvariants:\n- name: linux\n- name: linux\n"},
+		"Matrix":           {"buildvariants:\n- matrix_name: test\n  matrix_spec: {os: '*'}\n"},
+	} {
+		_, err = ParseGeneratedProject(files)
+		assert.Error(err, name)
+	}
+}
+
+func TestGeneratedProjectNewProject(t *testing.T) {
+	assert := assert.New(t)
+
+	v := &version.Version{Id: "v", Identifier: "mci", Config: generateBaseConfig}
+	g, err := ParseGeneratedProject([]string{generatedShards})
+	assert.NoError(err)
+	p, err := g.NewProject(v)
+	assert.NoError(err)
+	assert.Equal("mci", p.Identifier)
+	assert.Len(p.Functions, 2)
+	assert.Len(p.Tasks, 4)
+
+	// the existing variant gets the new tasks, and keeps its other fields
+	linux := p.FindBuildVariant("linux")
+	if assert.NotNil(linux) {
+		assert.Equal("Linux", linux.DisplayName)
+		assert.Equal([]string{"ubuntu"}, linux.RunOn)
+		assert.Len(linux.Tasks, 4)
+		assert.Len(linux.DisplayTasks, 1)
+	}
+	windows := p.FindBuildVariant("windows")
+	if assert.NotNil(windows) {
+		assert.Len(windows.Tasks, 2)
+	}
+
+	oldProject := &Project{}
+	assert.NoError(LoadProjectInto([]byte(generateBaseConfig), "mci", oldProject))
+	pairs := newTaskVariantPairs(oldProject, p)
+	assert.Equal([]string{"shard_1", "shard_2"}, pairs.ExecTasks.TaskNames("linux"))
+	assert.Equal([]string{"shard_1", "compile"}, pairs.ExecTasks.TaskNames("windows"))
+	assert.Equal([]string{"shards"}, pairs.DisplayTasks.TaskNames("linux"))
+
+	// generated functions and tasks must be new
+	for _, fragment := range []string{
+		"functions:\n  setup: []\n",
+		"tasks:\n- name: compile\n",
+	} {
+		g, err = ParseGeneratedProject([]string{fragment})
+		assert.NoError(err)
+		_, err = g.NewProject(v)
+		assert.Error(err, fragment)
+	}
+}
+
+type GenerateSuite struct {
+	v *version.Version
+	suite.Suite
+}
+
+func TestGenerateSuite(t *testing.T) {
+	suite.Run(t, new(GenerateSuite))
+}
+
+func (s *GenerateSuite) SetupTest() {
+	s.Require().NoError(db.ClearCollections(version.Collection, build.Collection, task.Collection))
+
+	s.v = &version.Version{
+		Id:         "v",
+		Identifier: "mci",
+		Revision:   "abc",
+		Requester:  evergreen.RepotrackerVersionRequester,
+		CreateTime: time.Now(),
+		Config:     generateBaseConfig,
+	}
+	project := &Project{}
+	s.Require().NoError(LoadProjectInto([]byte(generateBaseConfig), "mci", project))
+	buildId, err := CreateBuildFromVersion(project, s.v, NewTaskIdTable(project, s.v), "linux", true, nil, nil)
+	s.Require().NoError(err)
+	s.v.BuildIds = []string{buildId}
+	s.v.BuildVariants = []version.BuildStatus{{BuildVariant: "linux", BuildId: buildId, Activated: true}}
+	s.Require().NoError(s.v.Insert())
+}
+
+func (s *GenerateSuite) TestAddGeneratedTasks() {
+	g, err := ParseGeneratedProject([]string{generatedShards})
+	s.Require().NoError(err)
+	p, err := g.NewProject(s.v)
+	s.Require().NoError(err)
+
+	added, err := AddGeneratedTasks(s.v, p, "generator_task")
+	s.NoError(err)
+	s.True(added)
+
+	v, err := version.FindOne(version.ById(s.v.Id))
+	s.Require().NoError(err)
+	s.Len(v.BuildIds, 2)
+	saved := &Project{}
+	s.Require().NoError(LoadProjectInto([]byte(v.Config), "mci", saved))
+	s.NotNil(saved.FindTaskForVariant("shard_1", "windows"))
+
+	tasks, err := task.Find(task.ByVersion(s.v.Id))
+	s.Require().NoError(err)
+	names := map[string][]string{}
+	for _, t := range tasks {
+		s.True(t.Activated, t.DisplayName)
+		names[t.BuildVariant] = append(names[t.BuildVariant], t.DisplayName)
+	}
+	s.Len(names["linux"], 5)
+	s.Contains(names["linux"], "shards")
+	s.Len(names["windows"], 2)
+
+	// the generated task depends on the existing one
+	shard, err := task.FindOne(task.ById(NewTaskIdTable(p, s.v).ExecutionTasks.GetId("linux", "shard_1")))
+	s.Require().NoError(err)
+	s.Require().NotNil(shard)
+	s.Require().Len(shard.DependsOn, 1)
+	s.Equal(NewTaskIdTable(p, s.v).ExecutionTasks.GetId("linux", "compile"), shard.DependsOn[0].TaskId)
+}
+
+func (s *GenerateSuite) TestAddGeneratedTasksAfterConfigChanged() {
+	g, err := ParseGeneratedProject([]string{generatedShards})
+	s.Require().NoError(err)
+	stale := *s.v
+	stale.Config = "tasks: []"
+	p, err := g.NewProject(s.v)
+	s.Require().NoError(err)
+
+	added, err := AddGeneratedTasks(&stale, p, "generator_task")
+	s.NoError(err)
+	s.False(added)
+
+	tasks, err := task.Find(task.ByVersion(s.v.Id))
+	s.Require().NoError(err)
+	s.Len(tasks, 2)
+}
+
+func (s *GenerateSuite) TestFinishGeneratedTasks() {
+	g, err := ParseGeneratedProject([]string{generatedShards})
+	s.Require().NoError(err)
+	p, err := g.NewProject(s.v)
+	s.Require().NoError(err)
+	added, err := AddGeneratedTasks(s.v, p, "generator_task")
+	s.Require().NoError(err)
+	s.Require().True(added)
+
+	// lose some of the generated tasks and builds, as if creating them failed
+	ids := NewTaskIdTable(p, s.v)
+	s.Require().NoError(task.Remove(ids.ExecutionTasks.GetId("linux", "shard_2")))
+	s.Require().NoError(db.Remove(build.Collection, bson.M{build.BuildVariantKey: "windows"}))
+	s.Require().NoError(db.RemoveAll(task.Collection, bson.M{task.BuildVariantKey: "windows"}))
+	s.Require().NoError(version.UpdateOne(bson.M{version.IdKey: s.v.Id},
+		bson.M{"$set": bson.M{version.BuildIdsKey: s.v.BuildIds[:1], version.BuildVariantsKey: s.v.BuildVariants}}))
+
+	v, err := version.FindOne(version.ById(s.v.Id))
+	s.Require().NoError(err)
+	generated := v.FindGeneratedTasks("generator_task")
+	s.Require().NotNil(generated)
+	s.Nil(v.FindGeneratedTasks("compile_task"))
+	s.NoError(FinishGeneratedTasks(v, generated))
+
+	v, err = version.FindOne(version.ById(s.v.Id))
+	s.Require().NoError(err)
+	s.Len(v.BuildIds, 2)
+	tasks, err := task.Find(task.ByVersion(s.v.Id))
+	s.Require().NoError(err)
+	names := map[string][]string{}
+	for _, t := range tasks {
+		names[t.BuildVariant] = append(names[t.BuildVariant], t.DisplayName)
+	}
+	s.Len(names["linux"], 5)
+	s.Contains(names["linux"], "shard_2")
+	s.Len(names["windows"], 2)
+
+	// finishing again doesn't duplicate anything
+	s.NoError(FinishGeneratedTasks(v, generated))
+	tasks, err = task.Find(task.ByVersion(s.v.Id))
+	s.Require().NoError(err)
+	s.Len(tasks, 7)
+}
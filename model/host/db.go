@@ -37,6 +37,10 @@ var (
 	TerminationTimeKey       = bsonutil.MustHaveTag(Host{}, "TerminationTime")
 	LTCTimeKey               = bsonutil.MustHaveTag(Host{}, "LastTaskCompletedTime")
 	LTCKey                   = bsonutil.MustHaveTag(Host{}, "LastTaskCompleted")
+	LastGroupKey             = bsonutil.MustHaveTag(Host{}, "LastGroup")
+	LastBuildVariantKey      = bsonutil.MustHaveTag(Host{}, "LastBuildVariant")
+	LastVersionKey           = bsonutil.MustHaveTag(Host{}, "LastVersion")
+	LastProjectKey           = bsonutil.MustHaveTag(Host{}, "LastProject")
 	StatusKey                = bsonutil.MustHaveTag(Host{}, "Status")
 	AgentRevisionKey         = bsonutil.MustHaveTag(Host{}, "AgentRevision")
 	NeedsNewAgentKey         = bsonutil.MustHaveTag(Host{}, "NeedsNewAgent")
@@ -52,6 +56,7 @@ var (
 	ProjectKey               = bsonutil.MustHaveTag(Host{}, "Project")
 	ProvisionOptionsKey      = bsonutil.MustHaveTag(Host{}, "ProvisionOptions")
 	StartTimeKey             = bsonutil.MustHaveTag(Host{}, "StartTime")
+	BootstrapTokenKey        = bsonutil.MustHaveTag(Host{}, "BootstrapToken")
 )
 
 // === Queries ===
@@ -221,6 +226,14 @@ func ByIds(ids []string) db.Q {
 	})
 }
 
+// ByBootstrapToken returns the starting host that was given the bootstrap token.
+func ByBootstrapToken(token string) db.Q {
+	return db.Query(bson.M{
+		StatusKey:         evergreen.HostStarting,
+		BootstrapTokenKey: token,
+	})
+}
+
 // ByRunningTaskId returns a host running the task with the given id.
 func ByRunningTaskId(taskId string) db.Q {
 	return db.Query(bson.D{{Name: RunningTaskKey, Value: taskId}})
@@ -332,11 +345,13 @@ var IsProvisioningFailure = db.Query(bson.D{{Name: StatusKey, Value: evergreen.H
 
 // NeedsNewAgent returns hosts that are running and need a new agent, have no Last Commmunication Time,
 // or have one that exists that is greater than the MaxLTCInterval duration away from the current time.
+// Hosts bootstrapped with user data are never included.
 func NeedsNewAgent(currentTime time.Time) db.Q {
 	cutoffTime := currentTime.Add(-MaxLCTInterval)
 	return db.Query(bson.M{
 		StatusKey:    evergreen.HostRunning,
 		StartedByKey: evergreen.User,
+		bsonutil.GetDottedKeyName(DistroKey, distro.BootstrapMethodKey): bson.M{"$ne": distro.BootstrapMethodUserData},
 		"$or": []bson.M{
 			{LastCommunicationTimeKey: util.ZeroTime},
 			{LastCommunicationTimeKey: bson.M{"$lte": cutoffTime}},
@@ -52,6 +52,13 @@ type Host struct {
 
 	LastTaskCompletedTime time.Time `bson:"last_task_completed_time" json:"last_task_completed_time"`
 	LastTaskCompleted     string    `bson:"last_task" json:"last_task"`
+	// the task group of the last task dispatched to the host, if any, which
+	// along with the task's build variant, version and project keeps the
+	// host running the rest of the group's tasks
+	LastGroup             string    `bson:"last_group,omitempty" json:"last_group,omitempty"`
+	LastBuildVariant      string    `bson:"last_bv,omitempty" json:"last_bv,omitempty"`
+	LastVersion           string    `bson:"last_version,omitempty" json:"last_version,omitempty"`
+	LastProject           string    `bson:"last_project,omitempty" json:"last_project,omitempty"`
 	LastCommunicationTime time.Time `bson:"last_communication" json:"last_communication"`
 
 	Status    string `bson:"status" json:"status"`
@@ -68,6 +75,10 @@ type Host struct {
 	// stores userdata that was placed on the host at spawn time
 	UserData string `bson:"userdata" json:"userdata,omitempty"`
 
+	// one-time token that a host bootstrapped with user data exchanges for
+	// its secret when it registers with the API server
+	BootstrapToken string `bson:"bootstrap_token,omitempty" json:"-"`
+
 	// the last time that the host's reachability was checked
 	LastReachabilityCheck time.Time `bson:"last_reachability_check" json:"last_reachability_check"`
 
@@ -214,6 +225,44 @@ func (h *Host) SetQuarantined() error {
 	return h.SetStatus(evergreen.HostQuarantined)
 }
 
+// BootstrapsWithUserData returns true if the host downloads the agent and
+// registers itself with the API server, rather than being set up over SSH.
+// Spawn hosts are always set up over SSH.
+func (h *Host) BootstrapsWithUserData() bool {
+	return !h.UserHost && h.Distro.BootstrapsWithUserData()
+}
+
+// Register exchanges a starting host's one-time bootstrap token for a host
+// secret, and marks the host as initializing. It returns mgo.ErrNotFound if
+// the token is wrong or the host has already registered.
+func (h *Host) Register(token string) error {
+	if token == "" {
+		return mgo.ErrNotFound
+	}
+	secret := util.RandomString()
+	err := UpdateOne(
+		bson.M{
+			IdKey:             h.Id,
+			StatusKey:         evergreen.HostStarting,
+			BootstrapTokenKey: token,
+		},
+		bson.M{
+			"$set": bson.M{
+				StatusKey: evergreen.HostInitializing,
+				SecretKey: secret,
+			},
+			"$unset": bson.M{BootstrapTokenKey: 1},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	h.Status = evergreen.HostInitializing
+	h.Secret = secret
+	h.BootstrapToken = ""
+	return nil
+}
+
 // CreateSecret generates a host secret and updates the host both locally
 // and in the database.
 func (h *Host) CreateSecret() error {
@@ -381,6 +430,43 @@ func (host *Host) UpdateRunningTask(prevTaskId, newTaskId string,
 	return true, nil
 }
 
+// SetLastTaskGroup records the task group, if any, of the task most
+// recently dispatched to the host.
+func (h *Host) SetLastTaskGroup(t *task.Task) error {
+	update := bson.M{
+		"$unset": bson.M{
+			LastGroupKey:        1,
+			LastBuildVariantKey: 1,
+			LastVersionKey:      1,
+			LastProjectKey:      1,
+		},
+	}
+	if t.TaskGroup != "" {
+		update = bson.M{
+			"$set": bson.M{
+				LastGroupKey:        t.TaskGroup,
+				LastBuildVariantKey: t.BuildVariant,
+				LastVersionKey:      t.Version,
+				LastProjectKey:      t.Project,
+			},
+		}
+	}
+	if err := UpdateOne(bson.M{IdKey: h.Id}, update); err != nil {
+		return errors.Wrapf(err, "problem setting last task group for host %s", h.Id)
+	}
+
+	h.LastGroup = t.TaskGroup
+	h.LastBuildVariant = ""
+	h.LastVersion = ""
+	h.LastProject = ""
+	if t.TaskGroup != "" {
+		h.LastBuildVariant = t.BuildVariant
+		h.LastVersion = t.Version
+		h.LastProject = t.Project
+	}
+	return nil
+}
+
 // SetAgentRevision sets the updated agent revision for the host
 func (h *Host) SetAgentRevision(agentRevision string) error {
 	err := UpdateOne(bson.M{IdKey: h.Id},
@@ -550,6 +636,7 @@ func (h *Host) Upsert() (*mgo.ChangeInfo, error) {
 				ProjectKey:          h.Project,
 				ProvisionOptionsKey: h.ProvisionOptions,
 				StartTimeKey:        h.StartTime,
+				BootstrapTokenKey:   h.BootstrapToken,
 			},
 			"$setOnInsert": bson.M{
 				StatusKey:     h.Status,
@@ -12,6 +12,7 @@ import (
 	"github.com/evergreen-ci/evergreen/testutil"
 	. "github.com/smartystreets/goconvey/convey"
 	"github.com/stretchr/testify/assert"
+	mgo "gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 )
 
@@ -810,6 +811,19 @@ func TestFindNeedsNewAgent(t *testing.T) {
 			So(len(hosts), ShouldEqual, 1)
 			So(hosts[0].Id, ShouldEqual, "h")
 		})
+		Convey("with a host that bootstraps with user data", func() {
+			h := Host{
+				Id:            "h",
+				Status:        evergreen.HostRunning,
+				StartedBy:     evergreen.User,
+				NeedsNewAgent: true,
+				Distro:        distro.Distro{BootstrapMethod: distro.BootstrapMethodUserData},
+			}
+			So(h.Insert(), ShouldBeNil)
+			hosts, err := Find(NeedsNewAgent(now))
+			So(err, ShouldBeNil)
+			So(len(hosts), ShouldEqual, 0)
+		})
 	})
 }
 
@@ -1024,3 +1038,98 @@ func TestHostFindingWithTask(t *testing.T) {
 	assert.Equal(task2.Id, hosts[1].RunningTaskFull.Id)
 	assert.Nil(hosts[2].RunningTaskFull)
 }
+
+func TestClaimTaskGroup(t *testing.T) {
+	assert := assert.New(t) // nolint
+	assert.NoError(db.ClearCollections(Collection, TaskGroupHostsCollection))
+
+	inGroup := &task.Task{TaskGroup: "tests", BuildVariant: "linux", Project: "mci", Version: "v1"}
+	h1 := &Host{Id: "h1", Status: evergreen.HostRunning}
+	h2 := &Host{Id: "h2", Status: evergreen.HostRunning}
+	h3 := &Host{Id: "h3", Status: evergreen.HostRunning}
+	for _, h := range []*Host{h1, h2, h3} {
+		assert.NoError(h.Insert())
+	}
+
+	claimed, err := h1.ClaimTaskGroup(inGroup, 2)
+	assert.NoError(err)
+	assert.True(claimed)
+	claimed, err = h2.ClaimTaskGroup(inGroup, 2)
+	assert.NoError(err)
+	assert.True(claimed)
+	claimed, err = h3.ClaimTaskGroup(inGroup, 2)
+	assert.NoError(err)
+	assert.False(claimed)
+
+	// hosts can claim a group they're already running
+	claimed, err = h1.ClaimTaskGroup(inGroup, 2)
+	assert.NoError(err)
+	assert.True(claimed)
+
+	// other versions' groups are separate
+	claimed, err = h3.ClaimTaskGroup(&task.Task{TaskGroup: "tests", BuildVariant: "linux", Project: "mci", Version: "v2"}, 1)
+	assert.NoError(err)
+	assert.True(claimed)
+
+	// a host leaves the group when it runs a task outside of it
+	assert.NoError(h2.SetLastTaskGroup(&task.Task{BuildVariant: "linux", Version: "v1"}))
+	assert.Empty(h2.LastGroup)
+	assert.Empty(h2.LastVersion)
+	assert.NoError(h2.ReleaseTaskGroups())
+	assert.NoError(h3.SetLastTaskGroup(inGroup))
+	assert.Equal("tests", h3.LastGroup)
+	assert.NoError(h3.ReleaseTaskGroups())
+	claimed, err = h3.ClaimTaskGroup(inGroup, 2)
+	assert.NoError(err)
+	assert.True(claimed)
+	claimed, err = h2.ClaimTaskGroup(inGroup, 2)
+	assert.NoError(err)
+	assert.False(claimed)
+
+	// stopped hosts make room
+	assert.NoError(h1.SetStatus(evergreen.HostTerminated))
+	claimed, err = h2.ClaimTaskGroup(inGroup, 2)
+	assert.NoError(err)
+	assert.True(claimed)
+}
+
+func TestRegisterWithBootstrapToken(t *testing.T) {
+	assert := assert.New(t) // nolint
+	assert.NoError(db.ClearCollections(Collection))
+
+	h := &Host{
+		Id:             "h1",
+		Status:         evergreen.HostStarting,
+		BootstrapToken: "token",
+		Distro:         distro.Distro{BootstrapMethod: distro.BootstrapMethodUserData},
+	}
+	assert.NoError(h.Insert())
+	assert.True(h.BootstrapsWithUserData())
+
+	found, err := FindOne(ByBootstrapToken("wrong"))
+	assert.NoError(err)
+	assert.Nil(found)
+	found, err = FindOne(ByBootstrapToken("token"))
+	assert.NoError(err)
+	assert.NotNil(found)
+	assert.Equal(h.Id, found.Id)
+
+	assert.Equal(mgo.ErrNotFound, h.Register(""))
+	assert.Equal(mgo.ErrNotFound, h.Register("wrong"))
+	assert.NoError(h.Register("token"))
+	assert.Equal(evergreen.HostInitializing, h.Status)
+	assert.NotEmpty(h.Secret)
+
+	dbHost, err := FindOne(ById(h.Id))
+	assert.NoError(err)
+	assert.Equal(evergreen.HostInitializing, dbHost.Status)
+	assert.Equal(h.Secret, dbHost.Secret)
+	assert.Empty(dbHost.BootstrapToken)
+
+	// the token can only be used once
+	assert.Equal(mgo.ErrNotFound, h.Register("token"))
+
+	// spawn hosts are always set up over ssh
+	h.UserHost = true
+	assert.False(h.BootstrapsWithUserData())
+}
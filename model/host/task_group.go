@@ -0,0 +1,118 @@
+package host
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/mongodb/anser/bsonutil"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	// TaskGroupHostsCollection is the name of the MongoDB collection that
+	// stores which hosts are running each task group.
+	TaskGroupHostsCollection = "task_group_hosts"
+)
+
+// taskGroupHosts are the hosts running a task group of a build variant of a
+// version. Hosts are only added while there are fewer of them than the
+// group's max hosts, so hosts can't exceed it by claiming the group at once.
+type taskGroupHosts struct {
+	Id    string   `bson:"_id"`
+	Hosts []string `bson:"hosts"`
+}
+
+var (
+	taskGroupHostsIdKey    = bsonutil.MustHaveTag(taskGroupHosts{}, "Id")
+	taskGroupHostsHostsKey = bsonutil.MustHaveTag(taskGroupHosts{}, "Hosts")
+)
+
+func taskGroupHostsId(group, buildVariant, project, version string) string {
+	return strings.Join([]string{project, version, buildVariant, group}, "_")
+}
+
+// ClaimTaskGroup adds the host to the hosts running the task's task group,
+// returning false if as many other hosts as the group allows already are.
+// Hosts that have stopped running are removed from the group to make room.
+func (h *Host) ClaimTaskGroup(t *task.Task, maxHosts int) (bool, error) {
+	if maxHosts < 1 {
+		maxHosts = 1
+	}
+	id := taskGroupHostsId(t.TaskGroup, t.BuildVariant, t.Project, t.Version)
+	claim := func() (bool, error) {
+		// a full group doesn't match, so the upsert tries to insert a
+		// second document for the group and fails as a duplicate
+		_, err := db.Upsert(TaskGroupHostsCollection,
+			bson.M{
+				taskGroupHostsIdKey: id,
+				"$or": []bson.M{
+					{taskGroupHostsHostsKey: h.Id},
+					{fmt.Sprintf("%s.%d", taskGroupHostsHostsKey, maxHosts-1): bson.M{"$exists": false}},
+				},
+			},
+			bson.M{"$addToSet": bson.M{taskGroupHostsHostsKey: h.Id}},
+		)
+		if mgo.IsDup(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, errors.Wrapf(err, "problem adding host '%s' to task group '%s'", h.Id, id)
+		}
+		return true, nil
+	}
+
+	ok, err := claim()
+	if ok || err != nil {
+		return ok, err
+	}
+
+	group := &taskGroupHosts{}
+	err = db.FindOne(TaskGroupHostsCollection, bson.M{taskGroupHostsIdKey: id}, db.NoProjection, db.NoSort, group)
+	if err == mgo.ErrNotFound {
+		return claim()
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "problem finding hosts running task group '%s'", id)
+	}
+	stopped, err := Find(db.Query(bson.M{
+		IdKey:     bson.M{"$in": group.Hosts},
+		StatusKey: bson.M{"$ne": evergreen.HostRunning},
+	}).WithFields(IdKey))
+	if err != nil {
+		return false, errors.Wrapf(err, "problem finding stopped hosts in task group '%s'", id)
+	}
+	if len(stopped) == 0 {
+		return false, nil
+	}
+	stoppedIds := make([]string, 0, len(stopped))
+	for _, stoppedHost := range stopped {
+		stoppedIds = append(stoppedIds, stoppedHost.Id)
+	}
+	err = db.Update(TaskGroupHostsCollection,
+		bson.M{taskGroupHostsIdKey: id},
+		bson.M{"$pull": bson.M{taskGroupHostsHostsKey: bson.M{"$in": stoppedIds}}},
+	)
+	if err != nil {
+		return false, errors.Wrapf(err, "problem removing stopped hosts from task group '%s'", id)
+	}
+	return claim()
+}
+
+// ReleaseTaskGroups removes the host from the hosts running every task group
+// except the one its last task was in.
+func (h *Host) ReleaseTaskGroups() error {
+	query := bson.M{taskGroupHostsHostsKey: h.Id}
+	if h.LastGroup != "" {
+		query[taskGroupHostsIdKey] = bson.M{
+			"$ne": taskGroupHostsId(h.LastGroup, h.LastBuildVariant, h.LastProject, h.LastVersion),
+		}
+	}
+	_, err := db.UpdateAll(TaskGroupHostsCollection, query,
+		bson.M{"$pull": bson.M{taskGroupHostsHostsKey: h.Id}})
+	return errors.Wrapf(err, "problem removing host '%s' from task groups", h.Id)
+}
@@ -441,6 +441,8 @@ func CreateBuildFromVersion(project *Project, v *version.Version, taskIds TaskId
 	rev := v.Revision
 	if evergreen.IsPatchRequester(v.Requester) {
 		rev = fmt.Sprintf("patch_%s_%s", v.Revision, v.Id)
+	} else if v.Requester == evergreen.AdHocVersionRequester {
+		rev = fmt.Sprintf("periodic_%s_%s", v.Revision, v.Id)
 	}
 
 	// create a new build id
@@ -728,7 +730,7 @@ func TryMarkPatchBuildFinished(b *build.Build, finishTime time.Time, updates *St
 // createOneTask is a helper to create a single task.
 func createOneTask(id string, buildVarTask BuildVariantTask, project *Project,
 	buildVariant *BuildVariant, b *build.Build, v *version.Version) *task.Task {
-	return &task.Task{
+	t := &task.Task{
 		Id:                  id,
 		Secret:              util.RandomString(),
 		DisplayName:         buildVarTask.Name,
@@ -750,6 +752,17 @@ func createOneTask(id string, buildVarTask BuildVariantTask, project *Project,
 		Project:             project.Identifier,
 		Priority:            buildVarTask.Priority,
 	}
+	if tg := project.FindTaskGroup(buildVarTask.TaskGroup); tg != nil {
+		t.TaskGroup = tg.Name
+		t.TaskGroupMaxHosts = tg.MaxHosts
+		for i, name := range tg.Tasks {
+			if name == t.DisplayName {
+				t.TaskGroupOrder = i + 1
+				break
+			}
+		}
+	}
+	return t
 }
 
 func createDisplayTask(id string, displayName string, execTasks []string,
@@ -22,6 +22,10 @@ const (
 
 	// GithubAlias is a special alias to specify default variants and tasks for GitHub pull requests.
 	GithubAlias = "__github"
+
+	// CommitQueueAlias is a special alias to specify the variants and tasks
+	// that must pass before an item in a project's commit queue is merged.
+	CommitQueueAlias = "__commit_queue"
 )
 
 // Intent represents an intent to create a patch build and is processed by an amboy queue.
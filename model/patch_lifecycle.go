@@ -201,6 +201,20 @@ func IncludePatchDependencies(project *Project, tvpairs []TVPair) []TVPair {
 // with the patch applied
 func MakePatchedConfig(p *patch.Patch, remoteConfigPath, projectConfig string) (
 	*Project, error) {
+	data, err := MakePatchedFile(p, remoteConfigPath, projectConfig)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	project := &Project{}
+	if err = LoadProjectInto(data, p.Project, project); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return project, nil
+}
+
+// MakePatchedFile takes in the path to a file in the project's repository and
+// its current contents, and returns its contents with the patch applied.
+func MakePatchedFile(p *patch.Patch, remoteConfigPath, projectConfig string) ([]byte, error) {
 	for _, patchPart := range p.Patches {
 		// we only need to patch the main project and not any other modules
 		if patchPart.ModuleName != "" {
@@ -297,11 +311,7 @@ func MakePatchedConfig(p *patch.Patch, remoteConfigPath, projectConfig string) (
 		if err != nil {
 			return nil, errors.Wrap(err, "could not read patched config file")
 		}
-		project := &Project{}
-		if err = LoadProjectInto(data, p.Project, project); err != nil {
-			return nil, errors.WithStack(err)
-		}
-		return project, nil
+		return data, nil
 	}
 	return nil, errors.New("no patch on project")
 }
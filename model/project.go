@@ -22,6 +22,7 @@ import (
 const (
 	TestCommandType   = "test"
 	SystemCommandType = "system"
+	SetupCommandType  = "setup"
 )
 
 const (
@@ -52,6 +53,8 @@ type Project struct {
 	Functions       map[string]*YAMLCommandSet `yaml:"functions,omitempty" bson:"functions"`
 	Tasks           []ProjectTask              `yaml:"tasks,omitempty" bson:"tasks"`
 	ExecTimeoutSecs int                        `yaml:"exec_timeout_secs,omitempty" bson:"exec_timeout_secs"`
+	PeriodicBuilds  []PeriodicBuildDefinition  `yaml:"periodic_builds,omitempty" bson:"periodic_builds,omitempty"`
+	TaskGroups      []TaskGroup                `yaml:"task_groups,omitempty" bson:"task_groups,omitempty"`
 
 	// Flag that indicates a project as requiring user authentication
 	Private bool `yaml:"private,omitempty" bson:"private"`
@@ -73,8 +76,50 @@ type BuildVariantTask struct {
 	ExecTimeoutSecs int   `yaml:"exec_timeout_secs,omitempty" bson:"exec_timeout_secs"`
 	Stepback        *bool `yaml:"stepback,omitempty" bson:"stepback,omitempty"`
 
+	// Retry overrides the task's retry policy on this variant.
+	Retry *TaskRetryPolicy `yaml:"retry,omitempty" bson:"retry,omitempty"`
+
 	// the distros that the task can be run on
 	Distros []string `yaml:"distros,omitempty" bson:"distros"`
+
+	// DistroRequires selects the distro that the task runs on by its
+	// capabilities, if the task doesn't name its distros.
+	DistroRequires *distro.Requirements `yaml:"distro_requires,omitempty" bson:"distro_requires,omitempty"`
+
+	// TaskGroup is the task group the task was added to the variant by,
+	// if any.
+	TaskGroup string `yaml:"task_group,omitempty" bson:"task_group,omitempty"`
+}
+
+// TaskGroup is a set of tasks that run back to back on the same hosts,
+// sharing a task directory. Build variants list a task group like a task
+// to run all of its tasks. A group's setup commands run before its first
+// task on a host and its teardown commands after its last one, and each
+// task runs the group's per-task setup and teardown commands instead of
+// the project's pre and post commands.
+type TaskGroup struct {
+	Name string `yaml:"name" bson:"name"`
+	// MaxHosts is how many hosts can run the group's tasks at once. It
+	// defaults to one, which runs the tasks one after another in order.
+	MaxHosts      int             `yaml:"max_hosts,omitempty" bson:"max_hosts"`
+	SetupGroup    *YAMLCommandSet `yaml:"setup_group,omitempty" bson:"setup_group,omitempty"`
+	TeardownGroup *YAMLCommandSet `yaml:"teardown_group,omitempty" bson:"teardown_group,omitempty"`
+	SetupTask     *YAMLCommandSet `yaml:"setup_task,omitempty" bson:"setup_task,omitempty"`
+	TeardownTask  *YAMLCommandSet `yaml:"teardown_task,omitempty" bson:"teardown_task,omitempty"`
+	Tasks         []string        `yaml:"tasks" bson:"tasks"`
+}
+
+// PeriodicBuildDefinition schedules versions of the project's branch head
+// on a cron schedule, whether or not there were new commits. The versions
+// run the variants and tasks matched by the project alias, or everything
+// if there is no alias.
+type PeriodicBuildDefinition struct {
+	// ID identifies the definition when tracking when it last ran. It
+	// defaults to the alias.
+	ID      string `yaml:"id,omitempty" bson:"id"`
+	Cron    string `yaml:"cron,omitempty" bson:"cron"`
+	Alias   string `yaml:"alias,omitempty" bson:"alias,omitempty"`
+	Message string `yaml:"message,omitempty" bson:"message,omitempty"`
 }
 
 type DisplayTask struct {
@@ -105,6 +150,12 @@ func (bvt *BuildVariantTask) Populate(pt ProjectTask) {
 	if bvt.Stepback == nil {
 		bvt.Stepback = pt.Stepback
 	}
+	if bvt.Retry == nil {
+		bvt.Retry = pt.Retry
+	}
+	if bvt.DistroRequires == nil {
+		bvt.DistroRequires = pt.DistroRequires
+	}
 }
 
 // UnmarshalYAML allows tasks to be referenced as single selector strings.
@@ -153,6 +204,10 @@ type BuildVariant struct {
 	// provided for the task
 	RunOn []string `yaml:"run_on,omitempty" bson:"run_on"`
 
+	// DistroRequires selects the default distro for the variant's tasks by
+	// its capabilities, instead of RunOn.
+	DistroRequires *distro.Requirements `yaml:"distro_requires,omitempty" bson:"distro_requires,omitempty"`
+
 	// all of the tasks to be run on the build variant, compile through tests.
 	Tasks        []BuildVariantTask `yaml:"tasks,omitempty" bson:"tasks"`
 	DisplayTasks []DisplayTask      `yaml:"display_tasks,omitempty" bson:"display_tasks,omitempty"`
@@ -190,6 +245,12 @@ type PluginCommandConf struct {
 	// variants.
 	Variants []string `yaml:"variants,omitempty" bson:"variants"`
 
+	// If and Unless are conditions, evaluated by the agent against the
+	// task's expansions, that decide whether the command runs. The
+	// command only runs when If is true and Unless is false.
+	If     string `yaml:"if,omitempty" bson:"if,omitempty"`
+	Unless string `yaml:"unless,omitempty" bson:"unless,omitempty"`
+
 	// TimeoutSecs indicates the maximum duration the command is allowed to run for.
 	TimeoutSecs int `yaml:"timeout_secs,omitempty" bson:"timeout_secs"`
 
@@ -198,6 +259,19 @@ type PluginCommandConf struct {
 
 	// Vars defines variables that can be used within commands.
 	Vars map[string]string `yaml:"vars,omitempty" bson:"vars"`
+
+	// Parallel, if set, makes this entry a block of commands that the
+	// agent runs concurrently rather than a single command.
+	Parallel *ParallelCommandBlock `yaml:"parallel,omitempty" bson:"parallel,omitempty"`
+}
+
+// ParallelCommandBlock is a list of commands that run at the same time
+// within a task. By default the block waits for all of its commands to
+// finish; if FailFast is set, the remaining commands are canceled as
+// soon as one of them fails.
+type ParallelCommandBlock struct {
+	FailFast bool                `yaml:"fail_fast,omitempty" bson:"fail_fast"`
+	Commands []PluginCommandConf `yaml:"commands,omitempty" bson:"commands"`
 }
 
 type ArtifactInstructions struct {
@@ -214,7 +288,7 @@ func (c *YAMLCommandSet) List() []PluginCommandConf {
 	if len(c.MultiCommand) > 0 {
 		return c.MultiCommand
 	}
-	if c.SingleCommand != nil && (c.SingleCommand.Command != "" || c.SingleCommand.Function != "") {
+	if c.SingleCommand != nil && (c.SingleCommand.Command != "" || c.SingleCommand.Function != "" || c.SingleCommand.Parallel != nil) {
 		return []PluginCommandConf{*c.SingleCommand}
 	}
 	return []PluginCommandConf{}
@@ -290,6 +364,14 @@ type ProjectTask struct {
 	//   3. false = overriding the project setting with false
 	Patchable *bool `yaml:"patchable,omitempty" bson:"patchable,omitempty"`
 	Stepback  *bool `yaml:"stepback,omitempty" bson:"stepback,omitempty"`
+
+	// Retry describes how the task is automatically re-executed
+	// when it fails.
+	Retry *TaskRetryPolicy `yaml:"retry,omitempty" bson:"retry,omitempty"`
+
+	// DistroRequires selects the distro that the task runs on by its
+	// capabilities, on variants that don't name the task's distros.
+	DistroRequires *distro.Requirements `yaml:"distro_requires,omitempty" bson:"distro_requires,omitempty"`
 }
 
 // TaskIdTable is a map of [variant, task display name]->[task id].
@@ -399,6 +481,8 @@ func NewTaskIdTable(p *Project, v *version.Version) TaskIdConfig {
 
 		if evergreen.IsPatchRequester(v.Requester) {
 			rev = fmt.Sprintf("patch_%s_%s", v.Revision, v.Id)
+		} else if v.Requester == evergreen.AdHocVersionRequester {
+			rev = fmt.Sprintf("periodic_%s_%s", v.Revision, v.Id)
 		}
 		for _, t := range bv.Tasks {
 			// create a unique Id for each task
@@ -464,6 +548,8 @@ func generateIdsForVariant(vt TVPair, proj *Project, v *version.Version, tasks T
 	rev := v.Revision
 	if v.Requester == evergreen.PatchVersionRequester {
 		rev = fmt.Sprintf("patch_%s_%s", v.Revision, v.Id)
+	} else if v.Requester == evergreen.AdHocVersionRequester {
+		rev = fmt.Sprintf("periodic_%s_%s", v.Revision, v.Id)
 	}
 	for _, t := range projBV.Tasks {
 		// create Ids for each task that can run on the variant and is requested by the patch.
@@ -568,6 +654,18 @@ func (p PluginCommandConf) RunOnVariant(variant string) bool {
 	return len(p.Variants) == 0 || util.StringSliceContains(p.Variants, variant)
 }
 
+// ShouldRun returns true if the plugin command's conditions allow it to
+// run in the given context.
+func (p PluginCommandConf) ShouldRun(ctx *util.ConditionContext) (bool, error) {
+	return util.EvaluateConditions(p.If, p.Unless, ctx)
+}
+
+// IsParallel returns true if the plugin command is a block of commands
+// to run concurrently.
+func (p PluginCommandConf) IsParallel() bool {
+	return p.Parallel != nil
+}
+
 // GetDisplayName returns the  display name of the plugin command. If none is
 // defined, it returns the command's identifier.
 func (p PluginCommandConf) GetDisplayName() string {
@@ -683,6 +781,17 @@ func (p *Project) FindBuildVariant(build string) *BuildVariant {
 	return nil
 }
 
+// FindTaskGroup returns the task group with the given name, or nil if the
+// project has no such group.
+func (p *Project) FindTaskGroup(name string) *TaskGroup {
+	for _, tg := range p.TaskGroups {
+		if tg.Name == name {
+			return &tg
+		}
+	}
+	return nil
+}
+
 func (p *Project) FindProjectTask(name string) *ProjectTask {
 	for _, t := range p.Tasks {
 		if t.Name == name {
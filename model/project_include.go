@@ -0,0 +1,281 @@
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"github.com/pkg/errors"
+)
+
+// ProjectInclude is a file whose functions, tasks, build variants and
+// matrix axes are merged into the project configuration that includes it.
+// The file name is relative to the root of the project's repository, or
+// to the root of the module's repository if a module is given. Files
+// included by a module's file are in the same module unless they name
+// another.
+type ProjectInclude struct {
+	FileName string `yaml:"filename"`
+	Module   string `yaml:"module"`
+}
+
+// UnmarshalYAML allows includes to be given as just a file name.
+func (pi *ProjectInclude) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var fileName string
+	if err := unmarshal(&fileName); err == nil {
+		*pi = ProjectInclude{FileName: fileName}
+		return nil
+	}
+	type includeCopyType ProjectInclude
+	var ic includeCopyType
+	if err := unmarshal(&ic); err != nil {
+		return err
+	}
+	*pi = ProjectInclude(ic)
+	return nil
+}
+
+// projectIncludes is an intermediary type for unmarshalling a single
+// include or a list of them.
+type projectIncludes []ProjectInclude
+
+// UnmarshalYAML reads YAML into an array of ProjectInclude. It will
+// successfully unmarshal a list of includes or a single include.
+func (pis *projectIncludes) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	pi := ProjectInclude{}
+	if err := unmarshal(&pi); err == nil {
+		*pis = projectIncludes([]ProjectInclude{pi})
+		return nil
+	}
+	var slice []ProjectInclude
+	if err := unmarshal(&slice); err != nil {
+		return err
+	}
+	*pis = projectIncludes(slice)
+	return nil
+}
+
+func (pi ProjectInclude) String() string {
+	if pi.Module != "" {
+		return fmt.Sprintf("%s:%s", pi.Module, pi.FileName)
+	}
+	return pi.FileName
+}
+
+// ProjectFileReader reads a file included by a project configuration. The
+// module is nil for files in the project's own repository.
+type ProjectFileReader func(module *Module, fileName string) ([]byte, error)
+
+// ProjectHasIncludes returns true if the project configuration includes
+// other files.
+func ProjectHasIncludes(data []byte) bool {
+	pp, errs := createIntermediateProject(data)
+	return len(errs) == 0 && len(pp.Include) > 0
+}
+
+// ReadModuleFile reads a file from the module's ref, or from its branch if
+// it has no ref. Files in GitHub modules are read with the API when there
+// is a token, and files in other modules are fetched with git. Since the
+// module comes from a project configuration, which anyone submitting a
+// patch can change, only https and ssh repositories are fetched.
+func ReadModuleFile(module *Module, fileName, githubOauthToken string) ([]byte, error) {
+	ref := module.Ref
+	if ref == "" {
+		ref = module.Branch
+	}
+	if strings.HasPrefix(ref, "-") {
+		return nil, errors.Errorf("invalid ref '%s' for module '%s'", ref, module.Name)
+	}
+	if owner, repo, ok := thirdparty.ParseGithubRepoURL(module.Repo); ok && githubOauthToken != "" {
+		return thirdparty.GetGithubFileContents(githubOauthToken, owner, repo, fileName, ref)
+	}
+	if err := thirdparty.ValidateGitRemoteURL(module.Repo); err != nil {
+		return nil, errors.Wrapf(err, "can't read files from module '%s'", module.Name)
+	}
+	return thirdparty.ReadGitRemoteFile(module.Repo, ref, fileName)
+}
+
+const mainProjectFile = "the project file"
+
+// includeMerger merges included files into a project, keeping track of
+// where everything was defined so that conflicts can be reported.
+type includeMerger struct {
+	project *parserProject
+	read    ProjectFileReader
+	sources map[string]string
+	merged  map[string]bool
+	errs    []error
+}
+
+// mergeIncludes merges the files the project includes, and the files they
+// include, into the project. Defining the same function, task, build
+// variant or axis in more than one file is an error, as is defining pre,
+// post or timeout more than once.
+func mergeIncludes(pp *parserProject, read ProjectFileReader) []error {
+	if len(pp.Include) == 0 {
+		return nil
+	}
+	if read == nil {
+		return []error{errors.New("project includes other files, which can't be read here")}
+	}
+
+	m := &includeMerger{
+		project: pp,
+		read:    read,
+		sources: map[string]string{},
+		merged:  map[string]bool{},
+	}
+	m.record(pp, mainProjectFile)
+
+	includes := pp.Include
+	pp.Include = nil
+	for _, include := range includes {
+		m.include(include, []string{mainProjectFile})
+	}
+	return m.errs
+}
+
+func (m *includeMerger) include(include ProjectInclude, chain []string) {
+	name := include.String()
+	for _, parent := range chain {
+		if parent == name {
+			m.errs = append(m.errs, errors.Errorf("include cycle: %s -> %s",
+				strings.Join(chain, " -> "), name))
+			return
+		}
+	}
+	if m.merged[name] {
+		return
+	}
+	m.merged[name] = true
+
+	if include.FileName == "" {
+		m.errs = append(m.errs, errors.Errorf("include in %s has no file name", chain[len(chain)-1]))
+		return
+	}
+	var module *Module
+	if include.Module != "" {
+		for i := range m.project.Modules {
+			if m.project.Modules[i].Name == include.Module {
+				module = &m.project.Modules[i]
+				break
+			}
+		}
+		if module == nil {
+			m.errs = append(m.errs, errors.Errorf("included file '%s' is in module '%s', which isn't defined",
+				name, include.Module))
+			return
+		}
+	}
+
+	data, err := m.read(module, include.FileName)
+	if err != nil {
+		m.errs = append(m.errs, errors.Wrapf(err, "problem reading included file '%s'", name))
+		return
+	}
+	pp, errs := createIntermediateProject(data)
+	if len(errs) > 0 {
+		for _, err := range errs {
+			m.errs = append(m.errs, errors.Wrapf(err, "problem parsing included file '%s'", name))
+		}
+		return
+	}
+	if !onlyHasIncludableFields(pp) {
+		m.errs = append(m.errs, errors.Errorf("included file '%s' can only define functions, tasks, "+
+			"build variants, axes, pre, post, timeout and includes", name))
+		return
+	}
+
+	m.record(pp, name)
+	if m.project.Functions == nil && len(pp.Functions) > 0 {
+		m.project.Functions = map[string]*YAMLCommandSet{}
+	}
+	for fn, commands := range pp.Functions {
+		m.project.Functions[fn] = commands
+	}
+	m.project.Tasks = append(m.project.Tasks, pp.Tasks...)
+	m.project.BuildVariants = append(m.project.BuildVariants, pp.BuildVariants...)
+	m.project.Axes = append(m.project.Axes, pp.Axes...)
+	if pp.Pre != nil {
+		m.project.Pre = pp.Pre
+	}
+	if pp.Post != nil {
+		m.project.Post = pp.Post
+	}
+	if pp.Timeout != nil {
+		m.project.Timeout = pp.Timeout
+	}
+
+	chain = append(chain[:len(chain):len(chain)], name)
+	for _, nested := range pp.Include {
+		if nested.Module == "" {
+			nested.Module = include.Module
+		}
+		m.include(nested, chain)
+	}
+}
+
+// record notes that everything the project defines comes from the given
+// file, reporting anything that was already defined elsewhere.
+func (m *includeMerger) record(pp *parserProject, source string) {
+	definitions := []string{}
+	for fn := range pp.Functions {
+		definitions = append(definitions, fmt.Sprintf("function '%s'", fn))
+	}
+	for _, t := range pp.Tasks {
+		definitions = append(definitions, fmt.Sprintf("task '%s'", t.Name))
+	}
+	for _, bv := range pp.BuildVariants {
+		if bv.matrix != nil {
+			definitions = append(definitions, fmt.Sprintf("matrix '%s'", bv.matrix.Id))
+		} else {
+			definitions = append(definitions, fmt.Sprintf("build variant '%s'", bv.Name))
+		}
+	}
+	for _, axis := range pp.Axes {
+		definitions = append(definitions, fmt.Sprintf("axis '%s'", axis.Id))
+	}
+	if pp.Pre != nil {
+		definitions = append(definitions, "pre")
+	}
+	if pp.Post != nil {
+		definitions = append(definitions, "post")
+	}
+	if pp.Timeout != nil {
+		definitions = append(definitions, "timeout")
+	}
+
+	for _, definition := range definitions {
+		if previous, ok := m.sources[definition]; ok {
+			if previous != source {
+				m.errs = append(m.errs, errors.Errorf("%s is defined in both %s and '%s'",
+					definition, quoteSource(previous), source))
+			}
+			continue
+		}
+		m.sources[definition] = source
+	}
+}
+
+func quoteSource(source string) string {
+	if source == mainProjectFile {
+		return source
+	}
+	return fmt.Sprintf("'%s'", source)
+}
+
+// onlyHasIncludableFields returns true if the project only sets the fields
+// that included files can define.
+func onlyHasIncludableFields(pp *parserProject) bool {
+	rest := *pp
+	rest.Functions = nil
+	rest.Tasks = nil
+	rest.BuildVariants = nil
+	rest.Axes = nil
+	rest.Pre = nil
+	rest.Post = nil
+	rest.Timeout = nil
+	rest.Include = nil
+	return reflect.DeepEqual(rest, parserProject{})
+}
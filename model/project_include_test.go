@@ -0,0 +1,190 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"github.com/stretchr/testify/assert"
+)
+
+// mapFileReader reads included files from a map of file names, prefixed
+// with the module name and a colon for files in modules.
+func mapFileReader(files map[string]string) ProjectFileReader {
+	return func(module *Module, fileName string) ([]byte, error) {
+		name := fileName
+		if module != nil {
+			name = module.Name + ":" + fileName
+		}
+		data, ok := files[name]
+		if !ok {
+			return nil, thirdparty.FileNotFoundError{}
+		}
+		return []byte(data), nil
+	}
+}
+
+func TestLoadProjectWithIncludes(t *testing.T) {
+	assert := assert.New(t)
+
+	yml := `
+include:
+- etc/tasks.yml
+- filename: etc/variants.yml
+- filename: evergreen/common.yml
+  module: enterprise
+modules:
+- name: enterprise
+  repo: git@github.com:evergreen-ci/enterprise.git
+  branch: master
+functions:
+  setup:
+  - command: shell.exec
+tasks:
+- name: compile
+  commands:
+  - func: setup
+`
+	files := map[string]string{
+		"etc/tasks.yml": `
+include: etc/more_tasks.yml
+functions:
+  run tests:
+  - command: shell.exec
+tasks:
+- name: test
+  depends_on:
+  - name: compile
+  commands:
+  - func: run tests
+`,
+		"etc/more_tasks.yml": `
+tasks:
+- name: lint
+  commands:
+  - func: setup
+`,
+		"etc/variants.yml": `
+post:
+- command: attach.results
+buildvariants:
+- name: linux
+  run_on: [ubuntu]
+  tasks: ["*"]
+`,
+		"enterprise:evergreen/common.yml": `
+include: evergreen/enterprise_variants.yml
+`,
+		"enterprise:evergreen/enterprise_variants.yml": `
+buildvariants:
+- name: enterprise
+  run_on: [ubuntu]
+  tasks: [compile]
+`,
+	}
+
+	assert.True(ProjectHasIncludes([]byte(yml)))
+	assert.False(ProjectHasIncludes([]byte(files["etc/more_tasks.yml"])))
+
+	p := &Project{}
+	assert.NoError(LoadProjectWithIncludes([]byte(yml), "mci", mapFileReader(files), p))
+	assert.Equal("mci", p.Identifier)
+	assert.Len(p.Functions, 2)
+	assert.Contains(p.Functions, "run tests")
+	assert.NotNil(p.FindProjectTask("compile"))
+	assert.NotNil(p.FindProjectTask("test"))
+	assert.NotNil(p.FindProjectTask("lint"))
+	assert.NotNil(p.Post)
+
+	linux := p.FindBuildVariant("linux")
+	if assert.NotNil(linux) {
+		// selectors match tasks from every file
+		assert.Len(linux.Tasks, 3)
+	}
+	assert.NotNil(p.FindBuildVariant("enterprise"))
+
+	// includes can't be resolved without a reader
+	assert.Error(LoadProjectInto([]byte(yml), "mci", &Project{}))
+}
+
+func TestLoadProjectWithIncludeErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	for name, test := range map[string]struct {
+		yml      string
+		files    map[string]string
+		contains string
+	}{
+		"Conflict": {
+			yml: "include: a.yml\ntasks:\n- name: compile\n",
+			files: map[string]string{
+				"a.yml": "tasks:\n- name: compile\n",
+			},
+			contains: "task 'compile' is defined in both the project file and 'a.yml'",
+		},
+		"ConflictBetweenIncludes": {
+			yml: "include: [a.yml, b.yml]\n",
+			files: map[string]string{
+				"a.yml": "functions:\n  setup: []\n",
+				"b.yml": "functions:\n  setup: []\n",
+			},
+			contains: "function 'setup' is defined in both 'a.yml' and 'b.yml'",
+		},
+		"PreDefinedTwice": {
+			yml: "include: a.yml\npre:\n- command: shell.exec\n",
+			files: map[string]string{
+				"a.yml": "pre:\n- command: shell.exec\n",
+			},
+			contains: "pre is defined in both",
+		},
+		"Cycle": {
+			yml: "include: a.yml\n",
+			files: map[string]string{
+				"a.yml": "include: b.yml\n",
+				"b.yml": "include: a.yml\n",
+			},
+			contains: "include cycle: the project file -> a.yml -> b.yml -> a.yml",
+		},
+		"MissingFile": {
+			yml:      "include: a.yml\n",
+			files:    map[string]string{},
+			contains: "problem reading included file 'a.yml'",
+		},
+		"UndefinedModule": {
+			yml:      "include:\n- filename: a.yml\n  module: enterprise\n",
+			files:    map[string]string{},
+			contains: "module 'enterprise', which isn't defined",
+		},
+		"ProjectFieldInInclude": {
+			yml: "include: a.yml\n",
+			files: map[string]string{
+				"a.yml": "stepback: true\ntasks:\n- name: compile\n",
+			},
+			contains: "included file 'a.yml' can only define",
+		},
+		"InvalidYAML": {
+			yml: "include: a.yml\n",
+			files: map[string]string{
+				"a.yml": "tasks: {",
+			},
+			contains: "problem parsing included file 'a.yml'",
+		},
+	} {
+		err := LoadProjectWithIncludes([]byte(test.yml), "mci", mapFileReader(test.files), &Project{})
+		if assert.Error(err, name) {
+			assert.Contains(err.Error(), test.contains, name)
+		}
+	}
+}
+
+func TestReadModuleFileRejectsUnsafeRepos(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, module := range []Module{
+		{Name: "option", Repo: "--upload-pack=touch /tmp/evergreen", Branch: "master"},
+		{Name: "local", Repo: "file:///var/lib/evergreen/repo.git", Branch: "master"},
+		{Name: "ref", Repo: "git@example.com:owner/repo.git", Ref: "--upload-pack=touch /tmp/evergreen"},
+	} {
+		_, err := ReadModuleFile(&module, "a.yml", "")
+		assert.Error(err, module.Name)
+	}
+}
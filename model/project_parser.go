@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"reflect"
 
+	"github.com/evergreen-ci/evergreen/model/distro"
 	"github.com/evergreen-ci/evergreen/util"
 	"github.com/mongodb/grip"
 	"github.com/pkg/errors"
@@ -58,6 +59,9 @@ type parserProject struct {
 	Functions       map[string]*YAMLCommandSet `yaml:"functions"`
 	Tasks           []parserTask               `yaml:"tasks"`
 	ExecTimeoutSecs int                        `yaml:"exec_timeout_secs"`
+	PeriodicBuilds  []PeriodicBuildDefinition  `yaml:"periodic_builds"`
+	Include         projectIncludes            `yaml:"include"`
+	TaskGroups      []TaskGroup                `yaml:"task_groups"`
 
 	// Matrix code
 	Axes []matrixAxis `yaml:"axes"`
@@ -65,16 +69,18 @@ type parserProject struct {
 
 // parserTask represents an intermediary state of task definitions.
 type parserTask struct {
-	Name            string              `yaml:"name"`
-	Priority        int64               `yaml:"priority"`
-	ExecTimeoutSecs int                 `yaml:"exec_timeout_secs"`
-	DisableCleanup  bool                `yaml:"disable_cleanup"`
-	DependsOn       parserDependencies  `yaml:"depends_on"`
-	Requires        taskSelectors       `yaml:"requires"`
-	Commands        []PluginCommandConf `yaml:"commands"`
-	Tags            parserStringSlice   `yaml:"tags"`
-	Patchable       *bool               `yaml:"patchable"`
-	Stepback        *bool               `yaml:"stepback"`
+	Name            string               `yaml:"name"`
+	Priority        int64                `yaml:"priority"`
+	ExecTimeoutSecs int                  `yaml:"exec_timeout_secs"`
+	DisableCleanup  bool                 `yaml:"disable_cleanup"`
+	DependsOn       parserDependencies   `yaml:"depends_on"`
+	Requires        taskSelectors        `yaml:"requires"`
+	Commands        []PluginCommandConf  `yaml:"commands"`
+	Tags            parserStringSlice    `yaml:"tags"`
+	Patchable       *bool                `yaml:"patchable"`
+	Stepback        *bool                `yaml:"stepback"`
+	Retry           *TaskRetryPolicy     `yaml:"retry"`
+	DistroRequires  *distro.Requirements `yaml:"distro_requires"`
 }
 
 type displayTask struct {
@@ -217,18 +223,19 @@ func (ts *taskSelector) UnmarshalYAML(unmarshal func(interface{}) error) error {
 
 // parserBV is a helper type storing intermediary variant definitions.
 type parserBV struct {
-	Name         string            `yaml:"name"`
-	DisplayName  string            `yaml:"display_name"`
-	Expansions   util.Expansions   `yaml:"expansions"`
-	Tags         parserStringSlice `yaml:"tags"`
-	Modules      parserStringSlice `yaml:"modules"`
-	Disabled     bool              `yaml:"disabled"`
-	Push         bool              `yaml:"push"`
-	BatchTime    *int              `yaml:"batchtime"`
-	Stepback     *bool             `yaml:"stepback"`
-	RunOn        parserStringSlice `yaml:"run_on"`
-	Tasks        parserBVTasks     `yaml:"tasks"`
-	DisplayTasks []displayTask     `yaml:"display_tasks"`
+	Name           string               `yaml:"name"`
+	DisplayName    string               `yaml:"display_name"`
+	Expansions     util.Expansions      `yaml:"expansions"`
+	Tags           parserStringSlice    `yaml:"tags"`
+	Modules        parserStringSlice    `yaml:"modules"`
+	Disabled       bool                 `yaml:"disabled"`
+	Push           bool                 `yaml:"push"`
+	BatchTime      *int                 `yaml:"batchtime"`
+	Stepback       *bool                `yaml:"stepback"`
+	RunOn          parserStringSlice    `yaml:"run_on"`
+	DistroRequires *distro.Requirements `yaml:"distro_requires"`
+	Tasks          parserBVTasks        `yaml:"tasks"`
+	DisplayTasks   []displayTask        `yaml:"display_tasks"`
 
 	// internal matrix stuff
 	matrixId  string
@@ -272,15 +279,18 @@ func (pbv *parserBV) UnmarshalYAML(unmarshal func(interface{}) error) error {
 
 // parserBVTask is a helper type storing intermediary variant task configurations.
 type parserBVTask struct {
-	Name            string             `yaml:"name"`
-	Patchable       *bool              `yaml:"patchable"`
-	Priority        int64              `yaml:"priority"`
-	DependsOn       parserDependencies `yaml:"depends_on"`
-	Requires        taskSelectors      `yaml:"requires"`
-	ExecTimeoutSecs int                `yaml:"exec_timeout_secs"`
-	Stepback        *bool              `yaml:"stepback"`
-	Distros         parserStringSlice  `yaml:"distros"`
-	RunOn           parserStringSlice  `yaml:"run_on"` // Alias for "Distros" TODO: deprecate Distros
+	Name            string               `yaml:"name"`
+	Patchable       *bool                `yaml:"patchable"`
+	Priority        int64                `yaml:"priority"`
+	DependsOn       parserDependencies   `yaml:"depends_on"`
+	Requires        taskSelectors        `yaml:"requires"`
+	ExecTimeoutSecs int                  `yaml:"exec_timeout_secs"`
+	Stepback        *bool                `yaml:"stepback"`
+	Retry           *TaskRetryPolicy     `yaml:"retry"`
+	Distros         parserStringSlice    `yaml:"distros"`
+	RunOn           parserStringSlice    `yaml:"run_on"` // Alias for "Distros" TODO: deprecate Distros
+	TaskGroup       string               `yaml:"task_group"`
+	DistroRequires  *distro.Requirements `yaml:"distro_requires"`
 }
 
 // UnmarshalYAML allows the YAML parser to read both a single selector string or
@@ -357,8 +367,15 @@ func (pss *parserStringSlice) UnmarshalYAML(unmarshal func(interface{}) error) e
 
 // LoadProjectInto loads the raw data from the config file into project
 // and sets the project's identifier field to identifier. Tags are evaluateed.
+// The config can't include other files.
 func LoadProjectInto(data []byte, identifier string, project *Project) error {
-	p, errs := projectFromYAML(data) // ignore warnings, for now (TODO)
+	return LoadProjectWithIncludes(data, identifier, nil, project)
+}
+
+// LoadProjectWithIncludes is like LoadProjectInto, but first merges in the
+// files the config includes, which are read with read.
+func LoadProjectWithIncludes(data []byte, identifier string, read ProjectFileReader, project *Project) error {
+	p, errs := projectFromYAMLWithIncludes(data, read) // ignore warnings, for now (TODO)
 	if len(errs) > 0 {
 		// create a human-readable error list
 		buf := bytes.Buffer{}
@@ -381,10 +398,19 @@ func LoadProjectInto(data []byte, identifier string, project *Project) error {
 // projectFromYAML reads and evaluates project YAML, returning a project and warnings and
 // errors encountered during parsing or evaluation.
 func projectFromYAML(yml []byte) (*Project, []error) {
+	return projectFromYAMLWithIncludes(yml, nil)
+}
+
+// projectFromYAMLWithIncludes is like projectFromYAML, but first merges in
+// the files the project includes.
+func projectFromYAMLWithIncludes(yml []byte, read ProjectFileReader) (*Project, []error) {
 	pp, errs := createIntermediateProject(yml)
 	if len(errs) > 0 {
 		return nil, errs
 	}
+	if errs = mergeIncludes(pp, read); len(errs) > 0 {
+		return nil, errs
+	}
 	p, errs := translateProject(pp)
 	return p, errs
 }
@@ -427,6 +453,13 @@ func translateProject(pp *parserProject) (*Project, []error) {
 		Modules:         pp.Modules,
 		Functions:       pp.Functions,
 		ExecTimeoutSecs: pp.ExecTimeoutSecs,
+		PeriodicBuilds:  pp.PeriodicBuilds,
+		TaskGroups:      pp.TaskGroups,
+	}
+	for i := range proj.PeriodicBuilds {
+		if proj.PeriodicBuilds[i].ID == "" {
+			proj.PeriodicBuilds[i].ID = proj.PeriodicBuilds[i].Alias
+		}
 	}
 	tse := NewParserTaskSelectorEvaluator(pp.Tasks)
 	ase := NewAxisSelectorEvaluator(pp.Axes)
@@ -435,6 +468,14 @@ func translateProject(pp *parserProject) (*Project, []error) {
 	matrixVariants, errs := buildMatrixVariants(pp.Axes, ase, matrices)
 	evalErrs = append(evalErrs, errs...)
 	pp.BuildVariants = append(regularBVs, matrixVariants...)
+	for i := range proj.TaskGroups {
+		if proj.TaskGroups[i].MaxHosts == 0 {
+			proj.TaskGroups[i].MaxHosts = 1
+		}
+	}
+	for i := range pp.BuildVariants {
+		pp.BuildVariants[i].Tasks = expandTaskGroups(pp.BuildVariants[i].Tasks, proj.TaskGroups)
+	}
 	vse := NewVariantSelectorEvaluator(pp.BuildVariants, ase)
 	proj.Tasks, errs = evaluateTasks(tse, vse, pp.Tasks)
 	evalErrs = append(evalErrs, errs...)
@@ -443,6 +484,32 @@ func translateProject(pp *parserProject) (*Project, []error) {
 	return proj, evalErrs
 }
 
+// expandTaskGroups replaces the task groups in a variant's tasks with the
+// tasks in them, which take the settings given for the group.
+func expandTaskGroups(pbvts parserBVTasks, tgs []TaskGroup) parserBVTasks {
+	expanded := parserBVTasks{}
+	for _, pbvt := range pbvts {
+		var group *TaskGroup
+		for i := range tgs {
+			if tgs[i].Name == pbvt.Name {
+				group = &tgs[i]
+				break
+			}
+		}
+		if group == nil {
+			expanded = append(expanded, pbvt)
+			continue
+		}
+		for _, name := range group.Tasks {
+			t := pbvt
+			t.Name = name
+			t.TaskGroup = group.Name
+			expanded = append(expanded, t)
+		}
+	}
+	return expanded
+}
+
 // sieveMatrixVariants takes a set of parserBVs and groups them into regular
 // buildvariant matrix definitions and matrix definitions.
 func sieveMatrixVariants(bvs []parserBV) (regular []parserBV, matrices []matrix) {
@@ -471,6 +538,8 @@ func evaluateTasks(tse *taskSelectorEvaluator, vse *variantSelectorEvaluator,
 			Tags:            pt.Tags,
 			Patchable:       pt.Patchable,
 			Stepback:        pt.Stepback,
+			Retry:           pt.Retry,
+			DistroRequires:  pt.DistroRequires,
 		}
 		t.DependsOn, errs = evaluateDependsOn(tse, vse, pt.DependsOn)
 		evalErrs = append(evalErrs, errs...)
@@ -489,16 +558,17 @@ func evaluateBuildVariants(tse *taskSelectorEvaluator, vse *variantSelectorEvalu
 	var evalErrs, errs []error
 	for _, pbv := range pbvs {
 		bv := BuildVariant{
-			DisplayName: pbv.DisplayName,
-			Name:        pbv.Name,
-			Expansions:  pbv.Expansions,
-			Modules:     pbv.Modules,
-			Disabled:    pbv.Disabled,
-			Push:        pbv.Push,
-			BatchTime:   pbv.BatchTime,
-			Stepback:    pbv.Stepback,
-			RunOn:       pbv.RunOn,
-			Tags:        pbv.Tags,
+			DisplayName:    pbv.DisplayName,
+			Name:           pbv.Name,
+			Expansions:     pbv.Expansions,
+			Modules:        pbv.Modules,
+			Disabled:       pbv.Disabled,
+			Push:           pbv.Push,
+			BatchTime:      pbv.BatchTime,
+			Stepback:       pbv.Stepback,
+			RunOn:          pbv.RunOn,
+			Tags:           pbv.Tags,
+			DistroRequires: pbv.DistroRequires,
 		}
 		bv.Tasks, errs = evaluateBVTasks(tse, vse, pbv.Tasks)
 		// evaluate any rules passed in during matrix construction
@@ -623,7 +693,10 @@ func evaluateBVTasks(tse *taskSelectorEvaluator, vse *variantSelectorEvaluator,
 				Priority:        pt.Priority,
 				ExecTimeoutSecs: pt.ExecTimeoutSecs,
 				Stepback:        pt.Stepback,
+				Retry:           pt.Retry,
 				Distros:         pt.Distros,
+				TaskGroup:       pt.TaskGroup,
+				DistroRequires:  pt.DistroRequires,
 			}
 			t.DependsOn, errs = evaluateDependsOn(tse, vse, pt.DependsOn)
 			evalErrs = append(evalErrs, errs...)
@@ -8,6 +8,7 @@ import (
 
 	. "github.com/smartystreets/goconvey/convey"
 	"github.com/stretchr/testify/assert"
+	yaml "gopkg.in/yaml.v2"
 )
 
 // ShouldContainResembling tests whether a slice contains an element that DeepEquals
@@ -782,3 +783,223 @@ tasks:
 	assert.Equal("execTask2", proj.BuildVariants[0].DisplayTasks[1].ExecutionTasks[0])
 	assert.Equal("execTask4", proj.BuildVariants[0].DisplayTasks[1].ExecutionTasks[1])
 }
+
+func TestCommandConditionParsing(t *testing.T) {
+	assert := assert.New(t) //nolint
+	yml := `
+functions:
+  setup:
+    - command: shell.exec
+      if: ${os} matches windows.*
+      params:
+        script: echo windows
+tasks:
+- name: compile
+  commands:
+  - func: setup
+    unless: ${is_patch} == true
+  - command: shell.exec
+    if: failed
+    params:
+      script: echo cleanup
+`
+	p := &Project{}
+	assert.NoError(LoadProjectInto([]byte(yml), "conditions", p))
+
+	setup := p.Functions["setup"].List()
+	assert.Len(setup, 1)
+	assert.Equal("${os} matches windows.*", setup[0].If)
+
+	task := p.FindProjectTask("compile")
+	assert.NotNil(task)
+	assert.Len(task.Commands, 2)
+	assert.Equal("${is_patch} == true", task.Commands[0].Unless)
+	assert.Equal("failed", task.Commands[1].If)
+}
+
+func TestParallelCommandParsing(t *testing.T) {
+	assert := assert.New(t) //nolint
+	yml := `
+tasks:
+- name: compile
+  commands:
+  - parallel:
+      fail_fast: true
+      commands:
+      - command: shell.exec
+        params:
+          script: make server
+      - func: build client
+  - command: shell.exec
+    params:
+      script: make test
+`
+	p := &Project{}
+	assert.NoError(LoadProjectInto([]byte(yml), "parallel", p))
+
+	task := p.FindProjectTask("compile")
+	assert.NotNil(task)
+	assert.Len(task.Commands, 2)
+	assert.True(task.Commands[0].IsParallel())
+	assert.True(task.Commands[0].Parallel.FailFast)
+	assert.Len(task.Commands[0].Parallel.Commands, 2)
+	assert.Equal("shell.exec", task.Commands[0].Parallel.Commands[0].Command)
+	assert.Equal("build client", task.Commands[0].Parallel.Commands[1].Function)
+	assert.False(task.Commands[1].IsParallel())
+}
+
+func TestPeriodicBuildParsing(t *testing.T) {
+	assert := assert.New(t) //nolint
+	yml := `
+periodic_builds:
+- cron: "0 3 * * *"
+  alias: nightly
+- id: soak
+  cron: "@weekly"
+  message: weekly soak tests
+`
+	p := &Project{}
+	assert.NoError(LoadProjectInto([]byte(yml), "periodic", p))
+
+	assert.Len(p.PeriodicBuilds, 2)
+	assert.Equal("nightly", p.PeriodicBuilds[0].ID)
+	assert.Equal("0 3 * * *", p.PeriodicBuilds[0].Cron)
+	assert.Equal("nightly", p.PeriodicBuilds[0].Alias)
+	assert.Equal("soak", p.PeriodicBuilds[1].ID)
+	assert.Empty(p.PeriodicBuilds[1].Alias)
+	assert.Equal("weekly soak tests", p.PeriodicBuilds[1].Message)
+}
+
+func TestTaskGroupParsing(t *testing.T) {
+	assert := assert.New(t) //nolint
+	yml := `
+tasks:
+- name: compile
+- name: test_1
+- name: test_2
+- name: lint
+task_groups:
+- name: tests
+  max_hosts: 2
+  setup_group:
+  - command: shell.exec
+  teardown_task:
+  - command: shell.exec
+  tasks: [test_1, test_2]
+- name: lints
+  tasks: [lint]
+buildvariants:
+- name: linux
+  tasks:
+  - name: compile
+  - name: tests
+    priority: 10
+  - name: lints
+`
+	p := &Project{}
+	assert.NoError(LoadProjectInto([]byte(yml), "groups", p))
+
+	assert.Len(p.TaskGroups, 2)
+	tg := p.FindTaskGroup("tests")
+	if assert.NotNil(tg) {
+		assert.Equal(2, tg.MaxHosts)
+		assert.Equal([]string{"test_1", "test_2"}, tg.Tasks)
+		assert.NotNil(tg.SetupGroup)
+		assert.NotNil(tg.TeardownTask)
+		assert.Nil(tg.SetupTask)
+	}
+	if tg = p.FindTaskGroup("lints"); assert.NotNil(tg) {
+		assert.Equal(1, tg.MaxHosts)
+	}
+	assert.Nil(p.FindTaskGroup("compile"))
+
+	// groups in a variant are replaced by their tasks
+	bv := p.FindBuildVariant("linux")
+	if assert.NotNil(bv) && assert.Len(bv.Tasks, 4) {
+		assert.Equal("compile", bv.Tasks[0].Name)
+		assert.Empty(bv.Tasks[0].TaskGroup)
+		assert.Equal("test_1", bv.Tasks[1].Name)
+		assert.Equal("tests", bv.Tasks[1].TaskGroup)
+		assert.Equal(int64(10), bv.Tasks[1].Priority)
+		assert.Equal("test_2", bv.Tasks[2].Name)
+		assert.Equal("tests", bv.Tasks[2].TaskGroup)
+		assert.Equal("lint", bv.Tasks[3].Name)
+		assert.Equal("lints", bv.Tasks[3].TaskGroup)
+	}
+
+	// groups survive a round trip through a version's config
+	out, err := yaml.Marshal(p)
+	assert.NoError(err)
+	saved := &Project{}
+	assert.NoError(LoadProjectInto(out, "groups", saved))
+	assert.Len(saved.TaskGroups, 2)
+	if bvt := saved.FindTaskForVariant("test_2", "linux"); assert.NotNil(bvt) {
+		assert.Equal("tests", bvt.TaskGroup)
+	}
+}
+
+func TestDistroRequirementsParsing(t *testing.T) {
+	assert := assert.New(t) //nolint
+	yml := `
+tasks:
+- name: compile
+  distro_requires:
+    arch: linux_amd64
+    min_cpus: 8
+- name: gpu_test
+- name: lint
+buildvariants:
+- name: linux
+  distro_requires:
+    os: ubuntu1604
+    gpu: false
+    tags: [ssd]
+  tasks:
+  - name: compile
+  - name: gpu_test
+    distro_requires:
+      gpu: true
+      min_memory_gb: 32
+  - name: lint
+    distros: [small]
+`
+	p := &Project{}
+	assert.NoError(LoadProjectInto([]byte(yml), "requirements", p))
+
+	bv := p.FindBuildVariant("linux")
+	if assert.NotNil(bv) && assert.NotNil(bv.DistroRequires) {
+		assert.Equal("ubuntu1604", bv.DistroRequires.OS)
+		if assert.NotNil(bv.DistroRequires.GPU) {
+			assert.False(*bv.DistroRequires.GPU)
+		}
+		assert.Equal([]string{"ssd"}, bv.DistroRequires.Tags)
+	}
+
+	// task requirements are inherited from the project task
+	if bvt := p.FindTaskForVariant("compile", "linux"); assert.NotNil(bvt) && assert.NotNil(bvt.DistroRequires) {
+		assert.Equal("linux_amd64", bvt.DistroRequires.Arch)
+		assert.Equal(8, bvt.DistroRequires.MinCPUs)
+	}
+	if bvt := p.FindTaskForVariant("gpu_test", "linux"); assert.NotNil(bvt) && assert.NotNil(bvt.DistroRequires) {
+		assert.Equal(32, bvt.DistroRequires.MinMemoryGB)
+		if assert.NotNil(bvt.DistroRequires.GPU) {
+			assert.True(*bvt.DistroRequires.GPU)
+		}
+	}
+	if bvt := p.FindTaskForVariant("lint", "linux"); assert.NotNil(bvt) {
+		assert.Nil(bvt.DistroRequires)
+		assert.Equal([]string{"small"}, bvt.Distros)
+	}
+
+	// requirements survive a round trip through a version's config
+	out, err := yaml.Marshal(p)
+	assert.NoError(err)
+	saved := &Project{}
+	assert.NoError(LoadProjectInto(out, "requirements", saved))
+	if bv = saved.FindBuildVariant("linux"); assert.NotNil(bv) {
+		assert.Equal(p.FindBuildVariant("linux").DistroRequires, bv.DistroRequires)
+	}
+	if bvt := saved.FindTaskForVariant("compile", "linux"); assert.NotNil(bvt) && assert.NotNil(bvt.DistroRequires) {
+		assert.Equal(8, bvt.DistroRequires.MinCPUs)
+	}
+}
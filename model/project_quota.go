@@ -0,0 +1,77 @@
+package model
+
+import (
+	"sort"
+
+	"github.com/evergreen-ci/evergreen/model/distro"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/pkg/errors"
+)
+
+// ProjectQuotaUsage reports how much of a distro a project is using,
+// against the project's quota on that distro.
+type ProjectQuotaUsage struct {
+	Project    string
+	Guaranteed int
+	Ceiling    int
+	Running    int
+	Queued     int
+}
+
+// FindProjectQuotaUsage returns the usage of every project that has a
+// quota on the distro, or that has tasks running on or queued for it,
+// sorted by project.
+func FindProjectQuotaUsage(d *distro.Distro) ([]ProjectQuotaUsage, error) {
+	running, err := task.CountRunningTasksByProject(d.Id)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	queued := map[string]int{}
+	queue, err := FindTaskQueueForDistro(d.Id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem finding task queue for distro '%s'", d.Id)
+	}
+	if queue != nil {
+		for _, item := range queue.Queue {
+			queued[item.Project]++
+		}
+	}
+
+	return BuildProjectQuotaUsage(d, running, queued), nil
+}
+
+// BuildProjectQuotaUsage combines the distro's quotas with the number
+// of tasks each project has running and queued on the distro.
+func BuildProjectQuotaUsage(d *distro.Distro, running, queued map[string]int) []ProjectQuotaUsage {
+	projects := map[string]bool{}
+	for _, q := range d.ProjectQuotas {
+		projects[q.Project] = true
+	}
+	for p := range running {
+		projects[p] = true
+	}
+	for p := range queued {
+		projects[p] = true
+	}
+
+	names := make([]string, 0, len(projects))
+	for p := range projects {
+		names = append(names, p)
+	}
+	sort.Strings(names)
+
+	usage := make([]ProjectQuotaUsage, 0, len(names))
+	for _, p := range names {
+		q, _ := d.GetProjectQuota(p)
+		usage = append(usage, ProjectQuotaUsage{
+			Project:    p,
+			Guaranteed: q.Guaranteed,
+			Ceiling:    q.Ceiling,
+			Running:    running[p],
+			Queued:     queued[p],
+		})
+	}
+
+	return usage
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/evergreen-ci/evergreen/model/distro"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildProjectQuotaUsage(t *testing.T) {
+	assert := assert.New(t)
+	d := &distro.Distro{
+		Id: "d",
+		ProjectQuotas: []distro.ProjectQuota{
+			{Project: "small", Guaranteed: 1},
+			{Project: "big", Guaranteed: 2, Ceiling: 5},
+		},
+	}
+
+	usage := BuildProjectQuotaUsage(d, map[string]int{"big": 3, "other": 1}, map[string]int{"big": 10})
+	assert.Equal([]ProjectQuotaUsage{
+		{Project: "big", Guaranteed: 2, Ceiling: 5, Running: 3, Queued: 10},
+		{Project: "other", Running: 1},
+		{Project: "small", Guaranteed: 1},
+	}, usage)
+}
@@ -18,6 +18,7 @@ type ProjectRef struct {
 	Repo               string `bson:"repo_name" json:"repo_name" yaml:"repo"`
 	Branch             string `bson:"branch_name" json:"branch_name" yaml:"branch"`
 	RepoKind           string `bson:"repo_kind" json:"repo_kind" yaml:"repokind"`
+	RepoURL            string `bson:"repo_url,omitempty" json:"repo_url" yaml:"repo_url"`
 	Enabled            bool   `bson:"enabled" json:"enabled" yaml:"enabled"`
 	Private            bool   `bson:"private" json:"private" yaml:"private"`
 	BatchTime          int    `bson:"batch_time" json:"batch_time" yaml:"batchtime"`
@@ -39,6 +40,17 @@ type ProjectRef struct {
 	// RepoDetails contain the details of the status of the consistency
 	// between what is in GitHub and what is in Evergreen
 	RepotrackerError *RepositoryErrorDetails `bson:"repotracker_error" json:"repotracker_error"`
+
+	// CommitQueue configures the project's queue of pull requests and
+	// patches that are tested and merged one at a time.
+	CommitQueue CommitQueueParams `bson:"commit_queue" json:"commit_queue"`
+}
+
+// CommitQueueParams determines whether a project's commit queue is
+// processed and how the pull requests it tests are merged.
+type CommitQueueParams struct {
+	Enabled     bool   `bson:"enabled" json:"enabled"`
+	MergeMethod string `bson:"merge_method" json:"merge_method"`
 }
 
 // RepositoryErrorDetails indicates whether or not there is an invalid revision and if there is one,
@@ -75,6 +87,7 @@ var (
 	ProjectRefRepoKey               = bsonutil.MustHaveTag(ProjectRef{}, "Repo")
 	ProjectRefBranchKey             = bsonutil.MustHaveTag(ProjectRef{}, "Branch")
 	ProjectRefRepoKindKey           = bsonutil.MustHaveTag(ProjectRef{}, "RepoKind")
+	ProjectRefRepoURLKey            = bsonutil.MustHaveTag(ProjectRef{}, "RepoURL")
 	ProjectRefEnabledKey            = bsonutil.MustHaveTag(ProjectRef{}, "Enabled")
 	ProjectRefPrivateKey            = bsonutil.MustHaveTag(ProjectRef{}, "Private")
 	ProjectRefBatchTimeKey          = bsonutil.MustHaveTag(ProjectRef{}, "BatchTime")
@@ -87,8 +100,24 @@ var (
 	ProjectRefAlertsKey             = bsonutil.MustHaveTag(ProjectRef{}, "Alerts")
 	ProjectRefRepotrackerError      = bsonutil.MustHaveTag(ProjectRef{}, "RepotrackerError")
 	ProjectRefAdminsKey             = bsonutil.MustHaveTag(ProjectRef{}, "Admins")
+	ProjectRefCommitQueueKey        = bsonutil.MustHaveTag(ProjectRef{}, "CommitQueue")
+
+	commitQueueEnabledKey = bsonutil.MustHaveTag(CommitQueueParams{}, "Enabled")
+)
+
+const (
+	CommitQueueMergeMethodMerge  = "merge"
+	CommitQueueMergeMethodSquash = "squash"
+	CommitQueueMergeMethodRebase = "rebase"
 )
 
+// ValidCommitQueueMergeMethods are the ways GitHub can merge a pull request.
+var ValidCommitQueueMergeMethods = []string{
+	CommitQueueMergeMethodMerge,
+	CommitQueueMergeMethodSquash,
+	CommitQueueMergeMethodRebase,
+}
+
 const (
 	ProjectRefCollection = "project_ref"
 )
@@ -182,6 +211,25 @@ func FindOneProjectRefByRepo(owner, repoName string) (*ProjectRef, error) {
 	return &projectRef, err
 }
 
+// FindProjectRefsWithCommitQueueEnabled returns the enabled project refs
+// whose commit queue is enabled.
+func FindProjectRefsWithCommitQueueEnabled() ([]ProjectRef, error) {
+	projectRefs := []ProjectRef{}
+	err := db.FindAll(
+		ProjectRefCollection,
+		bson.M{
+			ProjectRefEnabledKey: true,
+			bsonutil.GetDottedKeyName(ProjectRefCommitQueueKey, commitQueueEnabledKey): true,
+		},
+		db.NoProjection,
+		db.NoSort,
+		db.NoSkip,
+		db.NoLimit,
+		&projectRefs,
+	)
+	return projectRefs, err
+}
+
 // FindProjectRefs returns limit refs starting at project identifier key
 // in the sortDir direction
 func FindProjectRefs(key string, limit int, sortDir int, isAuthenticated bool) ([]ProjectRef, error) {
@@ -237,6 +285,7 @@ func (projectRef *ProjectRef) Upsert() error {
 		bson.M{
 			"$set": bson.M{
 				ProjectRefRepoKindKey:           projectRef.RepoKind,
+				ProjectRefRepoURLKey:            projectRef.RepoURL,
 				ProjectRefEnabledKey:            projectRef.Enabled,
 				ProjectRefPrivateKey:            projectRef.Private,
 				ProjectRefBatchTimeKey:          projectRef.BatchTime,
@@ -252,6 +301,7 @@ func (projectRef *ProjectRef) Upsert() error {
 				ProjectRefAlertsKey:             projectRef.Alerts,
 				ProjectRefRepotrackerError:      projectRef.RepotrackerError,
 				ProjectRefAdminsKey:             projectRef.Admins,
+				ProjectRefCommitQueueKey:        projectRef.CommitQueue,
 			},
 		},
 	)
@@ -282,6 +332,12 @@ func (p *ProjectRef) GetBatchTime(variant *BuildVariant) int {
 
 // Location generates and returns the ssh hostname and path to the repo.
 func (projectRef *ProjectRef) Location() (string, error) {
+	if projectRef.RepoKind == GitRepoType {
+		if projectRef.RepoURL == "" {
+			return "", errors.Errorf("No repo url in project ref: %v", projectRef.Identifier)
+		}
+		return projectRef.RepoURL, nil
+	}
 	if projectRef.Owner == "" {
 		return "", errors.Errorf("No owner in project ref: %v", projectRef.Identifier)
 	}
@@ -14,6 +14,10 @@ type Repository struct {
 	Project             string `bson:"_id"`
 	LastRevision        string `bson:"last_revision"`
 	RevisionOrderNumber int    `bson:"last_commit_number"`
+
+	// PeriodicBuilds holds the time each of the project's periodic
+	// builds last ran, keyed by the periodic build's id.
+	PeriodicBuilds map[string]time.Time `bson:"periodic_builds,omitempty"`
 }
 
 var (
@@ -24,6 +28,8 @@ var (
 		"LastRevision")
 	RepositoryOrderNumberKey = bsonutil.MustHaveTag(Repository{},
 		"RevisionOrderNumber")
+	RepoPeriodicBuildsKey = bsonutil.MustHaveTag(Repository{},
+		"PeriodicBuilds")
 )
 
 const (
@@ -32,11 +38,14 @@ const (
 
 const (
 	GithubRepoType = "github"
+	// GitRepoType projects are tracked with plain git against the
+	// project ref's RepoURL, so they can live on any git server
+	GitRepoType = "git"
 )
 
-// valid repositories - currently only github supported
+// valid repositories
 var (
-	ValidRepoTypes = []string{GithubRepoType}
+	ValidRepoTypes = []string{GithubRepoType, GitRepoType}
 )
 
 type Revision struct {
@@ -80,6 +89,23 @@ func UpdateLastRevision(projectId, revision string) error {
 	)
 }
 
+// UpdateLastPeriodicBuild records the time a project's periodic build
+// last ran.
+func UpdateLastPeriodicBuild(projectId, periodicBuildId string, runTime time.Time) error {
+	_, err := db.Upsert(
+		RepositoriesCollection,
+		bson.M{
+			RepoProjectKey: projectId,
+		},
+		bson.M{
+			"$set": bson.M{
+				bsonutil.GetDottedKeyName(RepoPeriodicBuildsKey, periodicBuildId): runTime,
+			},
+		},
+	)
+	return err
+}
+
 // GetNewRevisionOrderNumber gets a new revision order number for a project.
 func GetNewRevisionOrderNumber(projectId string) (int, error) {
 	repo := &Repository{}
@@ -0,0 +1,145 @@
+package role
+
+import (
+	"time"
+
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/mongodb/anser/bsonutil"
+	"github.com/pkg/errors"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	// AssignmentCollection holds the roles assigned to users and groups.
+	AssignmentCollection = "role_assignments"
+)
+
+// Assignment grants a role to either a user or a group, on a single
+// project or, if Project is empty, on every project.
+type Assignment struct {
+	Id        bson.ObjectId `bson:"_id" json:"id"`
+	Role      string        `bson:"role" json:"role"`
+	User      string        `bson:"user,omitempty" json:"user,omitempty"`
+	Group     string        `bson:"group,omitempty" json:"group,omitempty"`
+	Project   string        `bson:"project,omitempty" json:"project,omitempty"`
+	CreatedAt time.Time     `bson:"created_at" json:"created_at"`
+	CreatedBy string        `bson:"created_by,omitempty" json:"created_by,omitempty"`
+}
+
+var (
+	AssignmentIdKey        = bsonutil.MustHaveTag(Assignment{}, "Id")
+	AssignmentRoleKey      = bsonutil.MustHaveTag(Assignment{}, "Role")
+	AssignmentUserKey      = bsonutil.MustHaveTag(Assignment{}, "User")
+	AssignmentGroupKey     = bsonutil.MustHaveTag(Assignment{}, "Group")
+	AssignmentProjectKey   = bsonutil.MustHaveTag(Assignment{}, "Project")
+	AssignmentCreatedAtKey = bsonutil.MustHaveTag(Assignment{}, "CreatedAt")
+	AssignmentCreatedByKey = bsonutil.MustHaveTag(Assignment{}, "CreatedBy")
+)
+
+// Validate checks that the assignment is of a role to exactly one of a
+// user or a group.
+func (a *Assignment) Validate() error {
+	if a.Role == "" {
+		return errors.New("assignment must have a role")
+	}
+	if (a.User == "") == (a.Group == "") {
+		return errors.New("assignment must be to either a user or a group")
+	}
+	return nil
+}
+
+// Insert stores a new assignment, giving it an id.
+func (a *Assignment) Insert() error {
+	if err := a.Validate(); err != nil {
+		return errors.WithStack(err)
+	}
+	r, err := FindOne(a.Role)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if r == nil {
+		return errors.Errorf("role '%s' does not exist", a.Role)
+	}
+
+	a.Id = bson.NewObjectId()
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = time.Now()
+	}
+	return errors.Wrapf(db.Insert(AssignmentCollection, a), "problem assigning role '%s'", a.Role)
+}
+
+// FindAssignments returns the assignments matching each of the non-empty
+// filters, sorted by role.
+func FindAssignments(user, group, project string) ([]Assignment, error) {
+	query := bson.M{}
+	if user != "" {
+		query[AssignmentUserKey] = user
+	}
+	if group != "" {
+		query[AssignmentGroupKey] = group
+	}
+	if project != "" {
+		query[AssignmentProjectKey] = project
+	}
+
+	out := []Assignment{}
+	q := db.Query(query).Sort([]string{AssignmentRoleKey, AssignmentCreatedAtKey})
+	if err := db.FindAllQ(AssignmentCollection, q, &out); err != nil {
+		return nil, errors.Wrap(err, "problem finding role assignments")
+	}
+	return out, nil
+}
+
+// FindAssignmentsForUser returns the assignments to the user and to any
+// of their groups.
+func FindAssignmentsForUser(user string, groups []string) ([]Assignment, error) {
+	or := []bson.M{{AssignmentUserKey: user}}
+	if len(groups) > 0 {
+		or = append(or, bson.M{AssignmentGroupKey: bson.M{"$in": groups}})
+	}
+
+	out := []Assignment{}
+	if err := db.FindAllQ(AssignmentCollection, db.Query(bson.M{"$or": or}), &out); err != nil {
+		return nil, errors.Wrapf(err, "problem finding role assignments for user '%s'", user)
+	}
+	return out, nil
+}
+
+// FindOneAssignment returns the assignment with the id, or nil if there
+// is none.
+func FindOneAssignment(id string) (*Assignment, error) {
+	if !bson.IsObjectIdHex(id) {
+		return nil, nil
+	}
+	out := &Assignment{}
+	err := db.FindOneQ(AssignmentCollection, db.Query(bson.M{AssignmentIdKey: bson.ObjectIdHex(id)}), out)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem finding role assignment '%s'", id)
+	}
+	return out, nil
+}
+
+// RemoveAssignment removes the assignment with the id.
+func RemoveAssignment(id string) error {
+	if !bson.IsObjectIdHex(id) {
+		return errors.Errorf("invalid role assignment id '%s'", id)
+	}
+	err := db.Remove(AssignmentCollection, bson.M{AssignmentIdKey: bson.ObjectIdHex(id)})
+	if err != nil && err != mgo.ErrNotFound {
+		return errors.Wrapf(err, "problem removing role assignment '%s'", id)
+	}
+	return nil
+}
+
+// RemoveAssignmentsOfRole removes every assignment of the role.
+func RemoveAssignmentsOfRole(id string) error {
+	err := db.RemoveAll(AssignmentCollection, bson.M{AssignmentRoleKey: id})
+	if err != nil && err != mgo.ErrNotFound {
+		return errors.Wrapf(err, "problem removing assignments of role '%s'", id)
+	}
+	return nil
+}
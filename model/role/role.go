@@ -0,0 +1,267 @@
+package role
+
+import (
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/mongodb/anser/bsonutil"
+	"github.com/pkg/errors"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	// Collection holds the roles defined by administrators, in addition
+	// to the built-in roles.
+	Collection = "roles"
+)
+
+// Permission is an action a role allows its holders to take.
+type Permission string
+
+const (
+	// ProjectView allows viewing a project's private versions, patches
+	// and tasks.
+	ProjectView Permission = "project_view"
+	// PatchSubmit allows submitting and scheduling patches.
+	PatchSubmit Permission = "patch_submit"
+	// TaskRestart allows restarting, aborting, activating and setting
+	// the priority of tasks, and of the builds, versions and patches
+	// they belong to.
+	TaskRestart Permission = "task_restart"
+	// ProjectSettings allows changing a project's settings.
+	ProjectSettings Permission = "project_settings"
+	// DistroAdmin allows creating, changing and removing distros.
+	DistroAdmin Permission = "distro_admin"
+	// HostAdmin allows changing the status of and terminating hosts.
+	HostAdmin Permission = "host_admin"
+)
+
+type permissionInfo struct {
+	// project permissions may be granted for a single project, the
+	// others only for the whole system
+	project bool
+	// legacy permissions are allowed for every logged in user while
+	// role-based access control is disabled
+	legacy bool
+}
+
+var permissions = map[Permission]permissionInfo{
+	ProjectView:     {project: true, legacy: true},
+	PatchSubmit:     {project: true, legacy: true},
+	TaskRestart:     {project: true, legacy: true},
+	ProjectSettings: {project: true},
+	DistroAdmin:     {},
+	HostAdmin:       {legacy: true},
+}
+
+// IsValidPermission returns true if p is a known permission.
+func IsValidPermission(p Permission) bool {
+	_, ok := permissions[p]
+	return ok
+}
+
+// IsProjectPermission returns true if p may be granted for a single
+// project.
+func IsProjectPermission(p Permission) bool {
+	return permissions[p].project
+}
+
+// IsLegacyPermission returns true if p was allowed for every logged in
+// user before roles existed.
+func IsLegacyPermission(p Permission) bool {
+	return permissions[p].legacy
+}
+
+// Role is a named set of permissions.
+type Role struct {
+	Id          string       `bson:"_id" json:"id"`
+	Name        string       `bson:"name" json:"name"`
+	Permissions []Permission `bson:"permissions" json:"permissions"`
+}
+
+var (
+	IdKey          = bsonutil.MustHaveTag(Role{}, "Id")
+	NameKey        = bsonutil.MustHaveTag(Role{}, "Name")
+	PermissionsKey = bsonutil.MustHaveTag(Role{}, "Permissions")
+)
+
+// The ids of the built-in roles.
+const (
+	ProjectViewer  = "project_viewer"
+	PatchSubmitter = "patch_submitter"
+	TaskRestarter  = "task_restarter"
+	ProjectAdmin   = "project_admin"
+	DistroAdminId  = "distro_admin"
+	HostAdminId    = "host_admin"
+)
+
+// builtInRoles are always defined, and can't be changed or removed.
+var builtInRoles = []Role{
+	{
+		Id:          ProjectViewer,
+		Name:        "Project Viewer",
+		Permissions: []Permission{ProjectView},
+	},
+	{
+		Id:          PatchSubmitter,
+		Name:        "Patch Submitter",
+		Permissions: []Permission{ProjectView, PatchSubmit},
+	},
+	{
+		Id:          TaskRestarter,
+		Name:        "Task Restarter",
+		Permissions: []Permission{ProjectView, PatchSubmit, TaskRestart},
+	},
+	{
+		Id:          ProjectAdmin,
+		Name:        "Project Admin",
+		Permissions: []Permission{ProjectView, PatchSubmit, TaskRestart, ProjectSettings},
+	},
+	{
+		Id:          DistroAdminId,
+		Name:        "Distro Admin",
+		Permissions: []Permission{DistroAdmin},
+	},
+	{
+		Id:          HostAdminId,
+		Name:        "Host Admin",
+		Permissions: []Permission{HostAdmin},
+	},
+}
+
+// BuiltInRoles returns the roles that are always defined.
+func BuiltInRoles() []Role {
+	return append([]Role{}, builtInRoles...)
+}
+
+// IsBuiltIn returns true if id is the id of a built-in role.
+func IsBuiltIn(id string) bool {
+	for _, r := range builtInRoles {
+		if r.Id == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that the role has an id and only known permissions.
+func (r *Role) Validate() error {
+	if r.Id == "" {
+		return errors.New("role must have an id")
+	}
+	if len(r.Permissions) == 0 {
+		return errors.Errorf("role '%s' must have at least one permission", r.Id)
+	}
+	for _, p := range r.Permissions {
+		if !IsValidPermission(p) {
+			return errors.Errorf("role '%s' has unknown permission '%s'", r.Id, p)
+		}
+	}
+	return nil
+}
+
+// HasPermission returns true if the role grants p.
+func (r *Role) HasPermission(p Permission) bool {
+	for _, rp := range r.Permissions {
+		if rp == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Upsert stores the role, replacing a stored role with the same id.
+// Built-in roles can't be replaced.
+func (r *Role) Upsert() error {
+	if IsBuiltIn(r.Id) {
+		return errors.Errorf("can't change built-in role '%s'", r.Id)
+	}
+	if err := r.Validate(); err != nil {
+		return errors.WithStack(err)
+	}
+	_, err := db.Upsert(
+		Collection,
+		bson.M{IdKey: r.Id},
+		bson.M{
+			"$set": bson.M{
+				NameKey:        r.Name,
+				PermissionsKey: r.Permissions,
+			},
+		},
+	)
+	return errors.Wrapf(err, "problem storing role '%s'", r.Id)
+}
+
+// FindAll returns the built-in roles followed by the stored ones.
+func FindAll() ([]Role, error) {
+	stored := []Role{}
+	q := db.Query(bson.M{}).Sort([]string{IdKey})
+	if err := db.FindAllQ(Collection, q, &stored); err != nil {
+		return nil, errors.Wrap(err, "problem finding roles")
+	}
+
+	out := make([]Role, 0, len(builtInRoles)+len(stored))
+	out = append(out, builtInRoles...)
+	return append(out, stored...), nil
+}
+
+// FindOne returns the role with the id, or nil if there is none.
+func FindOne(id string) (*Role, error) {
+	for _, r := range builtInRoles {
+		if r.Id == id {
+			out := r
+			return &out, nil
+		}
+	}
+
+	out := &Role{}
+	err := db.FindOneQ(Collection, db.Query(bson.M{IdKey: id}), out)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem finding role '%s'", id)
+	}
+	return out, nil
+}
+
+// Remove removes a stored role along with its assignments. Built-in
+// roles can't be removed.
+func Remove(id string) error {
+	if IsBuiltIn(id) {
+		return errors.Errorf("can't remove built-in role '%s'", id)
+	}
+	if err := db.Remove(Collection, bson.M{IdKey: id}); err != nil && err != mgo.ErrNotFound {
+		return errors.Wrapf(err, "problem removing role '%s'", id)
+	}
+	return errors.WithStack(RemoveAssignmentsOfRole(id))
+}
+
+// Allows returns true if any of the assignments, or any of the default
+// roles, grants the permission for the project. System permissions are
+// only granted by assignments for every project. An empty project only
+// matches assignments for every project.
+func Allows(roles []Role, assignments []Assignment, defaultRoles []string, p Permission, project string) bool {
+	byId := make(map[string]Role, len(roles))
+	for _, r := range roles {
+		byId[r.Id] = r
+	}
+	grants := func(id string) bool {
+		r, ok := byId[id]
+		return ok && r.HasPermission(p)
+	}
+
+	for _, id := range defaultRoles {
+		if grants(id) {
+			return true
+		}
+	}
+	for _, a := range assignments {
+		if a.Project != "" && (a.Project != project || !IsProjectPermission(p)) {
+			continue
+		}
+		if grants(a.Role) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package role
+
+import (
+	"testing"
+
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+func init() {
+	db.SetGlobalSessionProvider(testutil.TestConfig().SessionFactory())
+}
+
+func TestAllows(t *testing.T) {
+	assert := assert.New(t)
+	roles := append([]Role{}, builtInRoles...)
+	roles = append(roles, Role{Id: "restarter_only", Permissions: []Permission{TaskRestart}})
+
+	// nothing is allowed without assignments
+	assert.False(Allows(roles, nil, nil, ProjectView, "mci"))
+
+	// assignments for a project only apply to that project
+	assignments := []Assignment{{Role: TaskRestarter, User: "me", Project: "mci"}}
+	assert.True(Allows(roles, assignments, nil, TaskRestart, "mci"))
+	assert.True(Allows(roles, assignments, nil, PatchSubmit, "mci"))
+	assert.False(Allows(roles, assignments, nil, ProjectSettings, "mci"))
+	assert.False(Allows(roles, assignments, nil, TaskRestart, "other"))
+	assert.False(Allows(roles, assignments, nil, TaskRestart, ""))
+
+	// assignments for every project apply to each of them
+	assignments = []Assignment{{Role: "restarter_only", Group: "team"}}
+	assert.True(Allows(roles, assignments, nil, TaskRestart, "mci"))
+	assert.True(Allows(roles, assignments, nil, TaskRestart, ""))
+	assert.False(Allows(roles, assignments, nil, ProjectView, "mci"))
+
+	// system permissions can't be granted for a single project
+	assignments = []Assignment{{Role: DistroAdminId, User: "me", Project: "mci"}}
+	assert.False(Allows(roles, assignments, nil, DistroAdmin, "mci"))
+	assignments[0].Project = ""
+	assert.True(Allows(roles, assignments, nil, DistroAdmin, ""))
+
+	// default roles apply everywhere, and unknown roles grant nothing
+	assert.True(Allows(roles, nil, []string{ProjectViewer}, ProjectView, "mci"))
+	assert.False(Allows(roles, nil, []string{"missing"}, ProjectView, "mci"))
+}
+
+func TestPermissions(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(IsValidPermission(HostAdmin))
+	assert.False(IsValidPermission("fly"))
+	assert.True(IsProjectPermission(TaskRestart))
+	assert.False(IsProjectPermission(DistroAdmin))
+	assert.True(IsLegacyPermission(TaskRestart))
+	assert.False(IsLegacyPermission(ProjectSettings))
+
+	r := &Role{Id: "r"}
+	assert.Error(r.Validate())
+	r.Permissions = []Permission{"fly"}
+	assert.Error(r.Validate())
+	r.Permissions = []Permission{ProjectView}
+	assert.NoError(r.Validate())
+}
+
+type RoleSuite struct {
+	suite.Suite
+}
+
+func TestRoleSuite(t *testing.T) {
+	suite.Run(t, new(RoleSuite))
+}
+
+func (s *RoleSuite) SetupTest() {
+	s.Require().NoError(db.ClearCollections(Collection, AssignmentCollection))
+}
+
+func (s *RoleSuite) TestRoles() {
+	s.Error((&Role{Id: ProjectAdmin, Permissions: []Permission{ProjectView}}).Upsert())
+	s.NoError((&Role{Id: "viewer_plus", Permissions: []Permission{ProjectView, TaskRestart}}).Upsert())
+
+	roles, err := FindAll()
+	s.NoError(err)
+	s.Len(roles, len(builtInRoles)+1)
+
+	r, err := FindOne("viewer_plus")
+	s.NoError(err)
+	s.Require().NotNil(r)
+	s.True(r.HasPermission(TaskRestart))
+
+	r, err = FindOne(HostAdminId)
+	s.NoError(err)
+	s.Require().NotNil(r)
+	s.True(r.HasPermission(HostAdmin))
+
+	s.Error(Remove(HostAdminId))
+	s.NoError(Remove("viewer_plus"))
+	r, err = FindOne("viewer_plus")
+	s.NoError(err)
+	s.Nil(r)
+}
+
+func (s *RoleSuite) TestAssignments() {
+	s.Error((&Assignment{Role: ProjectViewer}).Insert())
+	s.Error((&Assignment{Role: ProjectViewer, User: "me", Group: "team"}).Insert())
+	s.Error((&Assignment{Role: "missing", User: "me"}).Insert())
+
+	mine := &Assignment{Role: ProjectViewer, User: "me", Project: "mci"}
+	s.NoError(mine.Insert())
+	s.NotEmpty(mine.Id)
+	s.NoError((&Assignment{Role: HostAdminId, Group: "ops"}).Insert())
+	s.NoError((&Assignment{Role: HostAdminId, User: "you"}).Insert())
+
+	assignments, err := FindAssignmentsForUser("me", []string{"ops", "dev"})
+	s.NoError(err)
+	s.Len(assignments, 2)
+
+	assignments, err = FindAssignments("", "", "mci")
+	s.NoError(err)
+	s.Require().Len(assignments, 1)
+	s.Equal("me", assignments[0].User)
+
+	found, err := FindOneAssignment(mine.Id.Hex())
+	s.NoError(err)
+	s.Require().NotNil(found)
+	s.Equal(ProjectViewer, found.Role)
+
+	s.NoError(RemoveAssignment(mine.Id.Hex()))
+	s.Error(RemoveAssignment("not an id"))
+	found, err = FindOneAssignment(mine.Id.Hex())
+	s.NoError(err)
+	s.Nil(found)
+}
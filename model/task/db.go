@@ -58,6 +58,8 @@ var (
 	CostKey                = bsonutil.MustHaveTag(Task{}, "Cost")
 	ExecutionTasksKey      = bsonutil.MustHaveTag(Task{}, "ExecutionTasks")
 	DisplayOnlyKey         = bsonutil.MustHaveTag(Task{}, "DisplayOnly")
+	GeneratedTasksKey      = bsonutil.MustHaveTag(Task{}, "GeneratedTasks")
+	TaskGroupKey           = bsonutil.MustHaveTag(Task{}, "TaskGroup")
 
 	// BSON fields for the test result struct
 	TestResultStatusKey    = bsonutil.MustHaveTag(TestResult{}, "Status")
@@ -510,6 +512,35 @@ func CostDataByDistroIdPipeline(distroId string, starttime time.Time, duration t
 	return pipeline
 }
 
+// CountRunningTasksByProject returns the number of tasks of each
+// project that are dispatched to or running on hosts of the given distro.
+func CountRunningTasksByProject(distroId string) (map[string]int, error) {
+	pipeline := []bson.M{
+		{"$match": bson.M{
+			DistroIdKey: distroId,
+			StatusKey:   bson.M{"$in": []string{evergreen.TaskDispatched, evergreen.TaskStarted}},
+		}},
+		{"$group": bson.M{
+			"_id":   "$" + ProjectKey,
+			"count": bson.M{"$sum": 1},
+		}},
+	}
+
+	out := []struct {
+		Project string `bson:"_id"`
+		Count   int    `bson:"count"`
+	}{}
+	if err := Aggregate(pipeline, &out); err != nil {
+		return nil, errors.Wrapf(err, "problem counting running tasks for distro '%s'", distroId)
+	}
+
+	counts := make(map[string]int, len(out))
+	for _, c := range out {
+		counts[c.Project] = c.Count
+	}
+	return counts, nil
+}
+
 // FindCostTaskByProject fetches all tasks of a project matching the
 // given time range, starting at task's IdKey in sortDir direction.
 func FindCostTaskByProject(project, taskId string, starttime,
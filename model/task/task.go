@@ -109,6 +109,17 @@ type Task struct {
 	DisplayOnly    bool     `bson:"display_only,omitempty" json:"display_only,omitempty"`
 	ExecutionTasks []string `bson:"execution_tasks,omitempty" json:"execution_tasks,omitempty"`
 	DisplayTask    *Task    `bson:"-" json:"-"` // this is a local pointer from an exec to display task
+
+	// TaskGroup is the task group the task runs in, if any, along with
+	// how many hosts can run the group's tasks at once and the task's
+	// position in the group.
+	TaskGroup         string `bson:"task_group,omitempty" json:"task_group,omitempty"`
+	TaskGroupMaxHosts int    `bson:"task_group_max_hosts,omitempty" json:"task_group_max_hosts,omitempty"`
+	TaskGroupOrder    int    `bson:"task_group_order,omitempty" json:"task_group_order,omitempty"`
+
+	// GeneratedTasks is true once the tasks this task generated with
+	// generate.tasks have been added to its version.
+	GeneratedTasks bool `bson:"generated_tasks,omitempty" json:"generated_tasks,omitempty"`
 }
 
 // Dependency represents a task that must be completed before the owning
@@ -183,6 +194,9 @@ type TestResult struct {
 	StartTime float64 `json:"start" bson:"start"`
 	EndTime   float64 `json:"end" bson:"end"`
 
+	Quarantined bool `json:"quarantined,omitempty" bson:"quarantined,omitempty"`
+	Flaky       bool `json:"flaky,omitempty" bson:"flaky,omitempty"`
+
 	// LogRaw is not saved in the task
 	LogRaw string `json:"log_raw" bson:"log_raw,omitempty"`
 }
@@ -311,10 +325,11 @@ func (t *Task) AllDependenciesSatisfied(cache map[string]Task) (bool, error) {
 }
 
 // HasFailedTests iterates through a tasks' tests and returns true if
-// that task had any failed tests.
+// that task had any failed tests. Failures of quarantined tests do not
+// count.
 func (t *Task) HasFailedTests() bool {
 	for _, test := range t.LocalTestResults {
-		if test.Status == evergreen.TestFailedStatus {
+		if test.Status == evergreen.TestFailedStatus && !test.Quarantined {
 			return true
 		}
 	}
@@ -869,12 +884,36 @@ func (t *Task) MarkStart(startTime time.Time) error {
 	)
 }
 
-// SetResults sets the results of the task in LocalTestResults
+// SetResults sets the results of the task in LocalTestResults. Each
+// result records where it falls in the test's history, and is flagged
+// if the test is quarantined in the task's project or has become flaky.
 func (t *Task) SetResults(results []TestResult) error {
-	catcher := grip.NewSimpleCatcher()
-	var testResult testresult.TestResult
+	quarantined, err := testresult.FindQuarantinedTestFiles(t.Project)
+	if err != nil {
+		return errors.Wrap(err, "error finding quarantined tests")
+	}
+
+	testResults := make([]testresult.TestResult, 0, len(results))
 	for _, result := range results {
-		testResult = result.convertToNewStyleTestResult()
+		testResult := result.convertToNewStyleTestResult()
+		testResult.Project = t.Project
+		testResult.BuildVariant = t.BuildVariant
+		testResult.TaskName = t.DisplayName
+		testResult.Revision = t.Revision
+		testResult.Order = t.RevisionOrderNumber
+		testResult.Quarantined = quarantined[testResult.TestFile]
+		testResults = append(testResults, testResult)
+	}
+
+	// the results are still worth keeping without the flaky flag
+	grip.Error(message.WrapError(testresult.DetectFlakyResults(testResults), message.Fields{
+		"message":   "problem detecting flaky tests",
+		"task_id":   t.Id,
+		"execution": t.Execution,
+	}))
+
+	catcher := grip.NewSimpleCatcher()
+	for _, testResult := range testResults {
 		catcher.Add(testResult.InsertByTaskIDAndExecution(t.Id, t.Execution))
 	}
 	return errors.Wrap(catcher.Resolve(), "error inserting into testresults collection")
@@ -896,16 +935,18 @@ func (t TestResult) convertToNewStyleTestResult() testresult.TestResult {
 
 func ConvertToOld(in *testresult.TestResult) TestResult {
 	return TestResult{
-		Status:    in.Status,
-		TestFile:  in.TestFile,
-		URL:       in.URL,
-		URLRaw:    in.URLRaw,
-		LogId:     in.LogID,
-		LineNum:   in.LineNum,
-		ExitCode:  in.ExitCode,
-		StartTime: in.StartTime,
-		EndTime:   in.EndTime,
-		LogRaw:    in.LogRaw,
+		Status:      in.Status,
+		TestFile:    in.TestFile,
+		URL:         in.URL,
+		URLRaw:      in.URLRaw,
+		LogId:       in.LogID,
+		LineNum:     in.LineNum,
+		ExitCode:    in.ExitCode,
+		StartTime:   in.StartTime,
+		EndTime:     in.EndTime,
+		Quarantined: in.Quarantined,
+		Flaky:       in.Flaky,
+		LogRaw:      in.LogRaw,
 	}
 }
 
@@ -940,6 +981,22 @@ func (t *Task) SetCost(cost float64) error {
 	)
 }
 
+// SetGeneratedTasks records that the tasks the task generated have been
+// added to its version.
+func (t *Task) SetGeneratedTasks() error {
+	t.GeneratedTasks = true
+	return UpdateOne(
+		bson.M{
+			IdKey: t.Id,
+		},
+		bson.M{
+			"$set": bson.M{
+				GeneratedTasksKey: true,
+			},
+		},
+	)
+}
+
 // AbortBuild sets the abort flag on all tasks associated with the build which are in an abortable
 // state
 func AbortBuild(buildId string) error {
@@ -1152,15 +1209,17 @@ func (t *Task) MergeNewTestResults() error {
 	}
 	for _, result := range newTestResults {
 		t.LocalTestResults = append(t.LocalTestResults, TestResult{
-			Status:    result.Status,
-			TestFile:  result.TestFile,
-			URL:       result.URL,
-			URLRaw:    result.URLRaw,
-			LogId:     result.LogID,
-			LineNum:   result.LineNum,
-			ExitCode:  result.ExitCode,
-			StartTime: result.StartTime,
-			EndTime:   result.EndTime,
+			Status:      result.Status,
+			TestFile:    result.TestFile,
+			URL:         result.URL,
+			URLRaw:      result.URLRaw,
+			LogId:       result.LogID,
+			LineNum:     result.LineNum,
+			ExitCode:    result.ExitCode,
+			StartTime:   result.StartTime,
+			EndTime:     result.EndTime,
+			Quarantined: result.Quarantined,
+			Flaky:       result.Flaky,
 		})
 	}
 	return nil
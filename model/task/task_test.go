@@ -8,6 +8,7 @@ import (
 	"github.com/evergreen-ci/evergreen/apimodels"
 	"github.com/evergreen-ci/evergreen/db"
 	"github.com/evergreen-ci/evergreen/model/build"
+	"github.com/evergreen-ci/evergreen/model/testresult"
 	"github.com/evergreen-ci/evergreen/testutil"
 	"github.com/evergreen-ci/evergreen/util"
 	. "github.com/smartystreets/goconvey/convey"
@@ -880,3 +881,58 @@ func TestDisplayTaskUpdates(t *testing.T) {
 	assert.NotNil(dbTask)
 	assert.Equal(evergreen.TaskStarted, dbTask.Status)
 }
+
+func TestHasFailedTestsIgnoresQuarantined(t *testing.T) {
+	assert := assert.New(t)
+
+	task := &Task{
+		LocalTestResults: []TestResult{
+			{TestFile: "TestFoo", Status: evergreen.TestSucceededStatus},
+			{TestFile: "TestBar", Status: evergreen.TestFailedStatus, Quarantined: true},
+		},
+	}
+	assert.False(task.HasFailedTests())
+
+	task.LocalTestResults = append(task.LocalTestResults, TestResult{TestFile: "TestBaz", Status: evergreen.TestFailedStatus})
+	assert.True(task.HasFailedTests())
+}
+
+func TestSetResultsMarksQuarantinedTests(t *testing.T) {
+	assert := assert.New(t)
+	assert.NoError(db.ClearCollections(Collection, testresult.Collection, testresult.QuarantineCollection))
+
+	assert.NoError((&testresult.QuarantinedTest{Project: "project", TestFile: "TestBar"}).Upsert())
+
+	task := &Task{
+		Id:                  "task",
+		Project:             "project",
+		BuildVariant:        "variant",
+		DisplayName:         "test",
+		Revision:            "abcdef",
+		RevisionOrderNumber: 4,
+		Status:              evergreen.TaskStarted,
+	}
+	assert.NoError(task.Insert())
+	assert.NoError(task.SetResults([]TestResult{
+		{TestFile: "TestFoo", Status: evergreen.TestSucceededStatus},
+		{TestFile: "TestBar", Status: evergreen.TestFailedStatus},
+	}))
+
+	results, err := testresult.FindByTaskIDAndExecution(task.Id, task.Execution)
+	assert.NoError(err)
+	assert.Len(results, 2)
+	for _, result := range results {
+		assert.Equal("project", result.Project)
+		assert.Equal("variant", result.BuildVariant)
+		assert.Equal("test", result.TaskName)
+		assert.Equal("abcdef", result.Revision)
+		assert.Equal(4, result.Order)
+		assert.Equal(result.TestFile == "TestBar", result.Quarantined)
+	}
+
+	dbTask, err := FindOne(ById(task.Id))
+	assert.NoError(err)
+	assert.NotNil(dbTask)
+	assert.Len(dbTask.LocalTestResults, 2)
+	assert.False(dbTask.HasFailedTests())
+}
@@ -20,6 +20,8 @@ import (
 type StatusChanges struct {
 	PatchNewStatus string
 	BuildNewStatus string
+	// TaskRetried is set when the task's retry policy restarted it.
+	TaskRetried bool
 }
 
 func SetActiveState(taskId string, caller string, active bool) error {
@@ -140,6 +142,16 @@ func TryResetTask(taskId, user, origin string, p *Project, detail *apimodels.Tas
 	if t.IsPartOfDisplay() {
 		return fmt.Errorf("cannot restart execution task %s because it is part of a display task", t.Id)
 	}
+	// tasks with a retry policy are only reset automatically while the
+	// policy allows another attempt
+	if origin != evergreen.UIPackage && origin != evergreen.RESTV2Package && detail != nil {
+		if policy := p.FindRetryPolicy(t.DisplayName, t.BuildVariant); policy != nil && !policy.ShouldRetry(t.Execution, detail) {
+			grip.Debugf("Task '%s' has no retries remaining under its retry policy, marking as failed", t.Id)
+			updates := StatusChanges{}
+			return errors.WithStack(MarkEnd(t.Id, origin, time.Now(), detail, p, false, &updates))
+		}
+	}
+
 	// if we've reached the max number of executions for this task, mark it as finished and failed
 	if t.Execution >= evergreen.MaxTaskExecution {
 		// restarting from the UI bypasses the restart cap
@@ -346,6 +358,14 @@ func MarkEnd(taskId, caller string, finishTime time.Time, detail *apimodels.Task
 		}
 	}
 
+	if !t.IsPartOfDisplay() && p.FindRetryPolicy(t.DisplayName, t.BuildVariant).ShouldRetry(t.Execution, detail) {
+		if err = retryTask(t, detail); err != nil {
+			return errors.WithStack(err)
+		}
+		updates.TaskRetried = true
+		return nil
+	}
+
 	// no need to activate/deactivate other task if this is a patch request's task
 	if evergreen.IsPatchRequester(t.Requester) {
 		return errors.Wrap(UpdateBuildAndVersionStatusForTask(t.Id, updates),
@@ -291,3 +291,62 @@ func FindMostRecentLogMessages(taskId string, execution int, numMsgs int,
 
 	return logMsgs, nil
 }
+
+// FindTaskLogsInOrder returns the chunks of a task execution's log in the
+// order they were inserted, skipping the first skip chunks. Messages are
+// only ever appended to chunks, so a log that is still being written can
+// be read on from the last chunk read. A limit of 0 returns all of the
+// remaining chunks.
+func FindTaskLogsInOrder(taskId string, execution, skip, limit int) ([]TaskLog, error) {
+	session, db, err := getSessionAndDB()
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	result := []TaskLog{}
+	err = db.C(TaskLogCollection).Find(
+		bson.M{
+			TaskLogTaskIdKey:    taskId,
+			TaskLogExecutionKey: execution,
+		},
+	).Sort(TaskLogIdKey).Skip(skip).Limit(limit).All(&result)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	return result, err
+}
+
+// FilterLogMessages returns the messages with one of the severities and
+// one of the types, which also match the older names of the types. To
+// ignore severity or type filtering, pass in empty slices.
+func FilterLogMessages(msgs []apimodels.LogMessage, severities []string,
+	msgTypes []string) []apimodels.LogMessage {
+	oldMsgTypes := []string{}
+	for _, msgType := range msgTypes {
+		switch msgType {
+		case apimodels.SystemLogPrefix:
+			oldMsgTypes = append(oldMsgTypes, "system")
+		case apimodels.AgentLogPrefix:
+			oldMsgTypes = append(oldMsgTypes, "agent")
+		case apimodels.TaskLogPrefix:
+			oldMsgTypes = append(oldMsgTypes, "task")
+		}
+	}
+
+	filtered := []apimodels.LogMessage{}
+	for _, logMsg := range msgs {
+		if len(severities) != 0 &&
+			!util.StringSliceContains(severities, logMsg.Severity) {
+			continue
+		}
+		if len(msgTypes) != 0 {
+			if !(util.StringSliceContains(msgTypes, logMsg.Type) ||
+				util.StringSliceContains(oldMsgTypes, logMsg.Type)) {
+				continue
+			}
+		}
+		filtered = append(filtered, logMsg)
+	}
+	return filtered
+}
@@ -40,6 +40,9 @@ type TaskQueueItem struct {
 	Project             string        `bson:"project" json:"project"`
 	ExpectedDuration    time.Duration `bson:"exp_dur" json:"exp_dur"`
 	Priority            int64         `bson:"priority" json:"priority"`
+	Version             string        `bson:"version" json:"version"`
+	Group               string        `bson:"group_name,omitempty" json:"group_name,omitempty"`
+	GroupMaxHosts       int           `bson:"group_max_hosts,omitempty" json:"group_max_hosts,omitempty"`
 }
 
 var (
@@ -0,0 +1,102 @@
+package model
+
+import (
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/apimodels"
+	"github.com/evergreen-ci/evergreen/model/event"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mongodb/grip"
+	"github.com/mongodb/grip/message"
+	"github.com/pkg/errors"
+)
+
+// TaskRetryPolicyCaller is recorded as the user for restarts made by a
+// task's retry policy.
+const TaskRetryPolicyCaller = "retry-policy"
+
+// ValidRetryFailureTypes are the failure types that a retry policy may
+// apply to.
+var ValidRetryFailureTypes = []string{SystemCommandType, SetupCommandType, TestCommandType}
+
+// TaskRetryPolicy describes how many times a failed task is
+// automatically re-executed, and which failures cause a retry. System
+// failures are always retried; On adds setup and test failures.
+type TaskRetryPolicy struct {
+	Attempts int      `yaml:"attempts,omitempty" bson:"attempts,omitempty"`
+	On       []string `yaml:"on,omitempty" bson:"on,omitempty"`
+}
+
+// getFailureType classifies a failed task's end detail as a system,
+// setup, or test failure. Tasks that lost their heartbeat are system
+// failures regardless of the command that was running.
+func getFailureType(detail *apimodels.TaskEndDetail) string {
+	if detail.Status == evergreen.TaskSystemFailed ||
+		(detail.TimedOut && detail.Description == task.AgentHeartbeat) {
+		return SystemCommandType
+	}
+
+	switch detail.Type {
+	case SystemCommandType, SetupCommandType:
+		return detail.Type
+	default:
+		return TestCommandType
+	}
+}
+
+// AppliesTo returns true if the policy covers the type of failure
+// described by the detail.
+func (r *TaskRetryPolicy) AppliesTo(detail *apimodels.TaskEndDetail) bool {
+	if r == nil || detail == nil || detail.Status == evergreen.TaskSucceeded ||
+		detail.Status == evergreen.TaskUndispatched {
+		return false
+	}
+
+	failureType := getFailureType(detail)
+	return failureType == SystemCommandType || util.StringSliceContains(r.On, failureType)
+}
+
+// ShouldRetry returns true if a task that finished the given execution
+// with the given detail has retries remaining under the policy.
+func (r *TaskRetryPolicy) ShouldRetry(execution int, detail *apimodels.TaskEndDetail) bool {
+	if !r.AppliesTo(detail) {
+		return false
+	}
+
+	return execution < r.Attempts && execution < evergreen.MaxTaskExecution
+}
+
+// FindRetryPolicy returns the retry policy for a task on a variant,
+// with the variant's policy taking precedence over the task's, or nil
+// if the task has no retry policy.
+func (p *Project) FindRetryPolicy(taskName, variant string) *TaskRetryPolicy {
+	if p == nil || p.FindProjectTask(taskName) == nil {
+		return nil
+	}
+
+	bvt := p.FindTaskForVariant(taskName, variant)
+	if bvt == nil {
+		return nil
+	}
+
+	return bvt.Retry
+}
+
+// retryTask archives the finished execution of a task and resets it
+// so that it is scheduled again as a new execution.
+func retryTask(t *task.Task, detail *apimodels.TaskEndDetail) error {
+	grip.Info(message.Fields{
+		"message":      "retrying task under retry policy",
+		"task":         t.Id,
+		"execution":    t.Execution,
+		"failure_type": getFailureType(detail),
+		"description":  detail.Description,
+	})
+
+	if err := resetTask(t.Id); err != nil {
+		return errors.Wrapf(err, "problem retrying task '%s'", t.Id)
+	}
+	event.LogTaskRestarted(t.Id, TaskRetryPolicyCaller)
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/apimodels"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model/build"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/model/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskRetryPolicyAppliesTo(t *testing.T) {
+	assert := assert.New(t) //nolint
+
+	var nilPolicy *TaskRetryPolicy
+	assert.False(nilPolicy.AppliesTo(&apimodels.TaskEndDetail{Status: evergreen.TaskFailed, Type: SystemCommandType}))
+
+	policy := &TaskRetryPolicy{Attempts: 2}
+	assert.False(policy.AppliesTo(nil))
+	assert.False(policy.AppliesTo(&apimodels.TaskEndDetail{Status: evergreen.TaskSucceeded}))
+	assert.True(policy.AppliesTo(&apimodels.TaskEndDetail{Status: evergreen.TaskFailed, Type: SystemCommandType}))
+	assert.True(policy.AppliesTo(&apimodels.TaskEndDetail{Status: evergreen.TaskSystemFailed}))
+	assert.True(policy.AppliesTo(&apimodels.TaskEndDetail{Status: evergreen.TaskFailed, TimedOut: true, Description: task.AgentHeartbeat}))
+	assert.False(policy.AppliesTo(&apimodels.TaskEndDetail{Status: evergreen.TaskFailed, Type: SetupCommandType}))
+	assert.False(policy.AppliesTo(&apimodels.TaskEndDetail{Status: evergreen.TaskFailed, Type: TestCommandType}))
+	assert.False(policy.AppliesTo(&apimodels.TaskEndDetail{Status: evergreen.TaskFailed}))
+
+	policy.On = []string{SetupCommandType}
+	assert.True(policy.AppliesTo(&apimodels.TaskEndDetail{Status: evergreen.TaskFailed, Type: SetupCommandType}))
+	assert.False(policy.AppliesTo(&apimodels.TaskEndDetail{Status: evergreen.TaskFailed, Type: TestCommandType}))
+
+	policy.On = []string{TestCommandType}
+	assert.True(policy.AppliesTo(&apimodels.TaskEndDetail{Status: evergreen.TaskFailed}))
+}
+
+func TestTaskRetryPolicyShouldRetry(t *testing.T) {
+	assert := assert.New(t) //nolint
+
+	detail := &apimodels.TaskEndDetail{Status: evergreen.TaskFailed, Type: SystemCommandType}
+	policy := &TaskRetryPolicy{Attempts: 2}
+	assert.True(policy.ShouldRetry(0, detail))
+	assert.True(policy.ShouldRetry(1, detail))
+	assert.False(policy.ShouldRetry(2, detail))
+
+	policy.Attempts = evergreen.MaxTaskExecution + 10
+	assert.True(policy.ShouldRetry(evergreen.MaxTaskExecution-1, detail))
+	assert.False(policy.ShouldRetry(evergreen.MaxTaskExecution, detail))
+
+	assert.False(policy.ShouldRetry(0, &apimodels.TaskEndDetail{Status: evergreen.TaskFailed, Type: TestCommandType}))
+}
+
+func TestFindRetryPolicy(t *testing.T) {
+	assert := assert.New(t)   //nolint
+	require := require.New(t) //nolint
+
+	yml := `
+tasks:
+- name: compile
+  retry:
+    attempts: 2
+    on: ["setup"]
+- name: test
+- name: lint
+  retry:
+    attempts: 1
+buildvariants:
+- name: linux
+  run_on: "ubuntu"
+  tasks:
+  - name: compile
+  - name: test
+    retry:
+      attempts: 3
+      on: ["test"]
+  - name: lint
+    retry:
+      attempts: 2
+`
+	p := &Project{}
+	require.NoError(LoadProjectInto([]byte(yml), "retry", p))
+
+	compile := p.FindRetryPolicy("compile", "linux")
+	require.NotNil(compile)
+	assert.Equal(2, compile.Attempts)
+	assert.Equal([]string{SetupCommandType}, compile.On)
+
+	test := p.FindRetryPolicy("test", "linux")
+	require.NotNil(test)
+	assert.Equal(3, test.Attempts)
+	assert.Equal([]string{TestCommandType}, test.On)
+
+	lint := p.FindRetryPolicy("lint", "linux")
+	require.NotNil(lint)
+	assert.Equal(2, lint.Attempts)
+	assert.Empty(lint.On)
+
+	assert.Nil(p.FindRetryPolicy("compile", "windows"))
+	assert.Nil(p.FindRetryPolicy("nonexistent", "linux"))
+
+	var nilProject *Project
+	assert.Nil(nilProject.FindRetryPolicy("compile", "linux"))
+}
+
+func TestMarkEndRetriesTask(t *testing.T) {
+	assert := assert.New(t)   //nolint
+	require := require.New(t) //nolint
+	require.NoError(db.ClearCollections(task.Collection, task.OldCollection, build.Collection, version.Collection))
+
+	p := &Project{
+		Identifier: "sample",
+		Tasks: []ProjectTask{
+			{Name: "compile", Retry: &TaskRetryPolicy{Attempts: 1}},
+		},
+		BuildVariants: []BuildVariant{
+			{Name: "linux", Tasks: []BuildVariantTask{{Name: "compile"}}},
+		},
+	}
+	b := &build.Build{
+		Id:      "buildtest",
+		Status:  evergreen.BuildStarted,
+		Version: "abc",
+		Tasks: []build.TaskCache{
+			{Id: "compile", Status: evergreen.TaskStarted, Activated: true},
+		},
+	}
+	v := &version.Version{
+		Id:     b.Version,
+		Status: evergreen.VersionStarted,
+	}
+	testTask := &task.Task{
+		Id:           "compile",
+		DisplayName:  "compile",
+		BuildVariant: "linux",
+		Activated:    true,
+		BuildId:      b.Id,
+		Project:      "sample",
+		Status:       evergreen.TaskStarted,
+	}
+	require.NoError(b.Insert())
+	require.NoError(v.Insert())
+	require.NoError(testTask.Insert())
+
+	detail := &apimodels.TaskEndDetail{Status: evergreen.TaskFailed, Type: SystemCommandType}
+	updates := StatusChanges{}
+	require.NoError(MarkEnd(testTask.Id, "test", time.Now(), detail, p, false, &updates))
+	assert.True(updates.TaskRetried)
+
+	dbTask, err := task.FindOne(task.ById(testTask.Id))
+	require.NoError(err)
+	require.NotNil(dbTask)
+	assert.Equal(evergreen.TaskUndispatched, dbTask.Status)
+	assert.Equal(1, dbTask.Execution)
+
+	// the policy's single retry is used up, so the next failure sticks
+	require.NoError(dbTask.MarkStart(time.Now()))
+	updates = StatusChanges{}
+	require.NoError(MarkEnd(testTask.Id, "test", time.Now(), detail, p, false, &updates))
+	assert.False(updates.TaskRetried)
+
+	dbTask, err = task.FindOne(task.ById(testTask.Id))
+	require.NoError(err)
+	require.NotNil(dbTask)
+	assert.Equal(evergreen.TaskFailed, dbTask.Status)
+	assert.Equal(1, dbTask.Execution)
+}
@@ -0,0 +1,147 @@
+package testresult
+
+import (
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	// FlakyDetectionWindow is the number of most recent results of a
+	// test, across executions and revisions, that are examined when
+	// deciding whether the test is flaky.
+	FlakyDetectionWindow = 10
+
+	// FlakyStateChangeThreshold is the number of times a test must flip
+	// between passing and failing within the detection window to be
+	// considered flaky.
+	FlakyStateChangeThreshold = 2
+)
+
+// FindHistory returns the most recent results of a test on a given task
+// and variant of a project, newest first. Results from later executions
+// of the same revision sort before earlier ones.
+func FindHistory(project, buildVariant, taskName, testFile string, limit int) ([]TestResult, error) {
+	q := db.Query(bson.M{
+		ProjectKey:      project,
+		BuildVariantKey: buildVariant,
+		TaskNameKey:     taskName,
+		TestFileKey:     testFile,
+	}).Sort([]string{"-" + OrderKey, "-" + ExecutionKey}).Limit(limit)
+
+	results, err := Find(q)
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem finding history for test '%s'", testFile)
+	}
+	return results, nil
+}
+
+// FindHistories returns the most recent results of each of the tests on a
+// given task and variant of a project, newest first, in a single query.
+// Only the status of each result is returned. Tests without any results
+// are left out of the map.
+func FindHistories(project, buildVariant, taskName string, testFiles []string, limit int) (map[string][]TestResult, error) {
+	pipeline := []bson.M{
+		{"$match": bson.M{
+			ProjectKey:      project,
+			BuildVariantKey: buildVariant,
+			TaskNameKey:     taskName,
+			TestFileKey:     bson.M{"$in": testFiles},
+		}},
+		{"$sort": bson.D{
+			{Name: TestFileKey, Value: 1},
+			{Name: OrderKey, Value: -1},
+			{Name: ExecutionKey, Value: -1},
+		}},
+		{"$group": bson.M{
+			"_id":     "$" + TestFileKey,
+			"results": bson.M{"$push": bson.M{StatusKey: "$" + StatusKey}},
+		}},
+		{"$project": bson.M{
+			"results": bson.M{"$slice": []interface{}{"$results", limit}},
+		}},
+	}
+
+	var output []struct {
+		TestFile string       `bson:"_id"`
+		Results  []TestResult `bson:"results"`
+	}
+	if err := db.Aggregate(Collection, pipeline, &output); err != nil {
+		return nil, errors.Wrapf(err, "problem finding history for tests of task '%s'", taskName)
+	}
+
+	histories := make(map[string][]TestResult, len(output))
+	for _, history := range output {
+		histories[history.TestFile] = history.Results
+	}
+	return histories, nil
+}
+
+// CountStateChanges returns the number of times consecutive results in
+// the history flip between passing and failing. Results that neither
+// passed nor failed, such as skipped tests, are ignored.
+func CountStateChanges(history []TestResult) int {
+	changes := 0
+	last := ""
+	for _, result := range history {
+		if result.Status != evergreen.TestSucceededStatus && result.Status != evergreen.TestFailedStatus {
+			continue
+		}
+		if last != "" && result.Status != last {
+			changes++
+		}
+		last = result.Status
+	}
+	return changes
+}
+
+// IsFlaky returns true if the history flips between passing and failing
+// often enough within the detection window for the test to be flaky.
+func IsFlaky(history []TestResult) bool {
+	if len(history) > FlakyDetectionWindow {
+		history = history[:FlakyDetectionWindow]
+	}
+	return CountStateChanges(history) >= FlakyStateChangeThreshold
+}
+
+// DetectFlaky sets the Flaky flag on a result that has not yet been
+// inserted, by combining it with the test's recorded history. Results
+// without a project and task name have no history and are left alone.
+func (t *TestResult) DetectFlaky() error {
+	if t.Project == "" || t.TaskName == "" {
+		return nil
+	}
+
+	history, err := FindHistory(t.Project, t.BuildVariant, t.TaskName, t.TestFile, FlakyDetectionWindow-1)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	t.Flaky = IsFlaky(append([]TestResult{*t}, history...))
+	return nil
+}
+
+// DetectFlakyResults sets the Flaky flag on results of a single task that
+// have not yet been inserted, looking up the history of all of their
+// tests at once. Results without a project and task name are left alone.
+func DetectFlakyResults(results []TestResult) error {
+	if len(results) == 0 || results[0].Project == "" || results[0].TaskName == "" {
+		return nil
+	}
+
+	testFiles := make([]string, 0, len(results))
+	for _, result := range results {
+		testFiles = append(testFiles, result.TestFile)
+	}
+	histories, err := FindHistories(results[0].Project, results[0].BuildVariant, results[0].TaskName,
+		testFiles, FlakyDetectionWindow-1)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for i := range results {
+		results[i].Flaky = IsFlaky(append([]TestResult{results[i]}, histories[results[i].TestFile]...))
+	}
+	return nil
+}
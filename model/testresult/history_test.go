@@ -0,0 +1,155 @@
+package testresult
+
+import (
+	"testing"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func init() {
+	db.SetGlobalSessionProvider(testutil.TestConfig().SessionFactory())
+}
+
+func makeHistory(statuses ...string) []TestResult {
+	out := make([]TestResult, 0, len(statuses))
+	for _, status := range statuses {
+		out = append(out, TestResult{Status: status})
+	}
+	return out
+}
+
+func TestCountStateChanges(t *testing.T) {
+	assert := assert.New(t) //nolint
+
+	pass := evergreen.TestSucceededStatus
+	fail := evergreen.TestFailedStatus
+	skip := evergreen.TestSkippedStatus
+
+	assert.Equal(0, CountStateChanges(nil))
+	assert.Equal(0, CountStateChanges(makeHistory(pass, pass, pass)))
+	assert.Equal(1, CountStateChanges(makeHistory(fail, fail, pass, pass)))
+	assert.Equal(3, CountStateChanges(makeHistory(pass, fail, pass, fail)))
+	assert.Equal(0, CountStateChanges(makeHistory(pass, skip, pass)))
+	assert.Equal(2, CountStateChanges(makeHistory(fail, skip, pass, skip, fail)))
+}
+
+func TestIsFlaky(t *testing.T) {
+	assert := assert.New(t) //nolint
+
+	pass := evergreen.TestSucceededStatus
+	fail := evergreen.TestFailedStatus
+
+	assert.False(IsFlaky(makeHistory(pass, pass, pass)))
+	assert.False(IsFlaky(makeHistory(fail, pass, pass, pass)))
+	assert.True(IsFlaky(makeHistory(fail, pass, fail)))
+	assert.True(IsFlaky(makeHistory(pass, fail, fail, pass)))
+
+	// flips outside of the detection window are not counted
+	history := makeHistory(fail)
+	for i := 0; i < FlakyDetectionWindow; i++ {
+		history = append(history, TestResult{Status: pass})
+	}
+	history = append(history, makeHistory(fail, pass, fail)...)
+	assert.False(IsFlaky(history))
+}
+
+func TestDetectFlaky(t *testing.T) {
+	assert := assert.New(t)   //nolint
+	require := require.New(t) //nolint
+	require.NoError(db.Clear(Collection))
+
+	statuses := []string{evergreen.TestSucceededStatus, evergreen.TestFailedStatus, evergreen.TestSucceededStatus}
+	for i, status := range statuses {
+		result := TestResult{
+			ID:           bson.NewObjectId(),
+			Status:       status,
+			TestFile:     "TestFoo",
+			TaskID:       "task",
+			Execution:    i,
+			Project:      "project",
+			BuildVariant: "variant",
+			TaskName:     "test",
+			Order:        1,
+		}
+		require.NoError(result.Insert())
+	}
+
+	history, err := FindHistory("project", "variant", "test", "TestFoo", FlakyDetectionWindow)
+	require.NoError(err)
+	require.Len(history, 3)
+	assert.Equal(2, history[0].Execution)
+	assert.Equal(0, history[2].Execution)
+
+	result := &TestResult{
+		Status:       evergreen.TestSucceededStatus,
+		TestFile:     "TestFoo",
+		Project:      "project",
+		BuildVariant: "variant",
+		TaskName:     "test",
+		Order:        2,
+	}
+	require.NoError(result.DetectFlaky())
+	assert.True(result.Flaky)
+
+	other := &TestResult{
+		Status:       evergreen.TestFailedStatus,
+		TestFile:     "TestBar",
+		Project:      "project",
+		BuildVariant: "variant",
+		TaskName:     "test",
+		Order:        2,
+	}
+	require.NoError(other.DetectFlaky())
+	assert.False(other.Flaky)
+}
+
+func TestDetectFlakyResults(t *testing.T) {
+	assert := assert.New(t)   //nolint
+	require := require.New(t) //nolint
+	require.NoError(db.Clear(Collection))
+
+	statuses := []string{evergreen.TestSucceededStatus, evergreen.TestFailedStatus}
+	for i, status := range statuses {
+		for _, testFile := range []string{"TestFoo", "TestBar"} {
+			result := TestResult{
+				ID:           bson.NewObjectId(),
+				Status:       status,
+				TestFile:     testFile,
+				TaskID:       "task",
+				Execution:    0,
+				Project:      "project",
+				BuildVariant: "variant",
+				TaskName:     "test",
+				Order:        i + 1,
+			}
+			require.NoError(result.Insert())
+		}
+	}
+
+	histories, err := FindHistories("project", "variant", "test", []string{"TestFoo", "TestBaz"}, FlakyDetectionWindow)
+	require.NoError(err)
+	require.Len(histories, 1)
+	require.Len(histories["TestFoo"], 2)
+	assert.Equal(evergreen.TestFailedStatus, histories["TestFoo"][0].Status)
+
+	results := []TestResult{
+		{Status: evergreen.TestSucceededStatus, TestFile: "TestFoo"},
+		{Status: evergreen.TestFailedStatus, TestFile: "TestBar"},
+		{Status: evergreen.TestSucceededStatus, TestFile: "TestBaz"},
+	}
+	for i := range results {
+		results[i].Project = "project"
+		results[i].BuildVariant = "variant"
+		results[i].TaskName = "test"
+		results[i].Order = 3
+	}
+	require.NoError(DetectFlakyResults(results))
+	assert.True(results[0].Flaky)
+	assert.False(results[1].Flaky)
+	assert.False(results[2].Flaky)
+}
@@ -0,0 +1,124 @@
+package testresult
+
+import (
+	"time"
+
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/mongodb/anser/bsonutil"
+	"github.com/pkg/errors"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	// QuarantineCollection is the name of the collection holding each
+	// project's quarantined tests.
+	QuarantineCollection = "test_quarantine"
+)
+
+// QuarantinedTest is a test whose failures should not fail the tasks
+// that run it. Results of quarantined tests are still recorded, and
+// their failures remain visible.
+type QuarantinedTest struct {
+	ID        bson.ObjectId `bson:"_id"`
+	Project   string        `bson:"project"`
+	TestFile  string        `bson:"test_file"`
+	Reason    string        `bson:"reason,omitempty"`
+	User      string        `bson:"user,omitempty"`
+	CreatedAt time.Time     `bson:"created_at"`
+}
+
+var (
+	QuarantineIDKey        = bsonutil.MustHaveTag(QuarantinedTest{}, "ID")
+	QuarantineProjectKey   = bsonutil.MustHaveTag(QuarantinedTest{}, "Project")
+	QuarantineTestFileKey  = bsonutil.MustHaveTag(QuarantinedTest{}, "TestFile")
+	QuarantineReasonKey    = bsonutil.MustHaveTag(QuarantinedTest{}, "Reason")
+	QuarantineUserKey      = bsonutil.MustHaveTag(QuarantinedTest{}, "User")
+	QuarantineCreatedAtKey = bsonutil.MustHaveTag(QuarantinedTest{}, "CreatedAt")
+)
+
+// FindQuarantinedTests returns all of a project's quarantined tests,
+// sorted by test file.
+func FindQuarantinedTests(project string) ([]QuarantinedTest, error) {
+	out := []QuarantinedTest{}
+	q := db.Query(bson.M{QuarantineProjectKey: project}).Sort([]string{QuarantineTestFileKey})
+	if err := db.FindAllQ(QuarantineCollection, q, &out); err != nil {
+		return nil, errors.Wrapf(err, "problem finding quarantined tests for project '%s'", project)
+	}
+	return out, nil
+}
+
+// FindOneQuarantinedTest returns a project's quarantine entry for a test,
+// or nil if the test is not quarantined.
+func FindOneQuarantinedTest(project, testFile string) (*QuarantinedTest, error) {
+	out := &QuarantinedTest{}
+	q := db.Query(bson.M{
+		QuarantineProjectKey:  project,
+		QuarantineTestFileKey: testFile,
+	})
+	err := db.FindOneQ(QuarantineCollection, q, out)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem finding quarantined test '%s'", testFile)
+	}
+	return out, nil
+}
+
+// FindQuarantinedTestFiles returns the set of test files quarantined in
+// a project.
+func FindQuarantinedTestFiles(project string) (map[string]bool, error) {
+	tests, err := FindQuarantinedTests(project)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	out := make(map[string]bool, len(tests))
+	for _, t := range tests {
+		out[t.TestFile] = true
+	}
+	return out, nil
+}
+
+// Upsert adds the test to its project's quarantine list, replacing the
+// reason and user of an existing entry for the same test.
+func (q *QuarantinedTest) Upsert() error {
+	if q.Project == "" || q.TestFile == "" {
+		return errors.New("quarantined test must have a project and a test file")
+	}
+	if q.CreatedAt.IsZero() {
+		q.CreatedAt = time.Now()
+	}
+
+	_, err := db.Upsert(
+		QuarantineCollection,
+		bson.M{
+			QuarantineProjectKey:  q.Project,
+			QuarantineTestFileKey: q.TestFile,
+		},
+		bson.M{
+			"$set": bson.M{
+				QuarantineReasonKey: q.Reason,
+				QuarantineUserKey:   q.User,
+			},
+			"$setOnInsert": bson.M{
+				QuarantineIDKey:        bson.NewObjectId(),
+				QuarantineCreatedAtKey: q.CreatedAt,
+			},
+		},
+	)
+	return errors.Wrapf(err, "problem quarantining test '%s' in project '%s'", q.TestFile, q.Project)
+}
+
+// RemoveQuarantinedTest removes a test from its project's quarantine list.
+func RemoveQuarantinedTest(project, testFile string) error {
+	err := db.Remove(QuarantineCollection, bson.M{
+		QuarantineProjectKey:  project,
+		QuarantineTestFileKey: testFile,
+	})
+	if err == mgo.ErrNotFound {
+		return nil
+	}
+	return errors.Wrapf(err, "problem removing quarantined test '%s' from project '%s'", testFile, project)
+}
@@ -0,0 +1,60 @@
+package testresult
+
+import (
+	"testing"
+
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/stretchr/testify/suite"
+)
+
+type QuarantineSuite struct {
+	suite.Suite
+}
+
+func TestQuarantineSuite(t *testing.T) {
+	suite.Run(t, new(QuarantineSuite))
+}
+
+func (s *QuarantineSuite) SetupTest() {
+	s.Require().NoError(db.Clear(QuarantineCollection))
+}
+
+func (s *QuarantineSuite) TestUpsertAndFind() {
+	s.Error((&QuarantinedTest{Project: "project"}).Upsert())
+	s.Error((&QuarantinedTest{TestFile: "TestFoo"}).Upsert())
+
+	s.NoError((&QuarantinedTest{Project: "project", TestFile: "TestFoo", Reason: "flaky"}).Upsert())
+	s.NoError((&QuarantinedTest{Project: "project", TestFile: "TestBar"}).Upsert())
+	s.NoError((&QuarantinedTest{Project: "other", TestFile: "TestFoo"}).Upsert())
+
+	tests, err := FindQuarantinedTests("project")
+	s.NoError(err)
+	s.Require().Len(tests, 2)
+	s.Equal("TestBar", tests[0].TestFile)
+	s.Equal("TestFoo", tests[1].TestFile)
+	s.Equal("flaky", tests[1].Reason)
+
+	// upserting the same test replaces its reason
+	s.NoError((&QuarantinedTest{Project: "project", TestFile: "TestFoo", Reason: "timing", User: "me"}).Upsert())
+	test, err := FindOneQuarantinedTest("project", "TestFoo")
+	s.NoError(err)
+	s.Require().NotNil(test)
+	s.Equal("timing", test.Reason)
+	s.Equal("me", test.User)
+	s.Equal(tests[1].ID, test.ID)
+
+	files, err := FindQuarantinedTestFiles("project")
+	s.NoError(err)
+	s.Equal(map[string]bool{"TestFoo": true, "TestBar": true}, files)
+}
+
+func (s *QuarantineSuite) TestRemove() {
+	s.NoError((&QuarantinedTest{Project: "project", TestFile: "TestFoo"}).Upsert())
+
+	s.NoError(RemoveQuarantinedTest("project", "TestFoo"))
+	test, err := FindOneQuarantinedTest("project", "TestFoo")
+	s.NoError(err)
+	s.Nil(test)
+
+	s.NoError(RemoveQuarantinedTest("project", "TestFoo"))
+}
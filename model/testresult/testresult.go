@@ -30,6 +30,20 @@ type TestResult struct {
 	TaskID    string `bson:"task_id" json:"task_id"`
 	Execution int    `bson:"task_execution" json:"task_execution"`
 
+	// Project, BuildVariant, TaskName, Revision, and Order locate the
+	// test in its history across executions and revisions
+	Project      string `bson:"project,omitempty" json:"project,omitempty"`
+	BuildVariant string `bson:"build_variant,omitempty" json:"build_variant,omitempty"`
+	TaskName     string `bson:"task_name,omitempty" json:"task_name,omitempty"`
+	Revision     string `bson:"revision,omitempty" json:"revision,omitempty"`
+	Order        int    `bson:"order,omitempty" json:"order,omitempty"`
+
+	// Quarantined is set when the test was on its project's quarantine
+	// list when the result was attached, and Flaky is set when the
+	// test's recent history flips between passing and failing.
+	Quarantined bool `bson:"quarantined,omitempty" json:"quarantined,omitempty"`
+	Flaky       bool `bson:"flaky,omitempty" json:"flaky,omitempty"`
+
 	// LogRaw is not persisted to the database
 	LogRaw string `json:"log_raw" bson:"log_raw,omitempty"`
 }
@@ -47,6 +61,14 @@ var (
 	EndTimeKey   = bsonutil.MustHaveTag(TestResult{}, "EndTime")
 	TaskIDKey    = bsonutil.MustHaveTag(TestResult{}, "TaskID")
 	ExecutionKey = bsonutil.MustHaveTag(TestResult{}, "Execution")
+
+	ProjectKey      = bsonutil.MustHaveTag(TestResult{}, "Project")
+	BuildVariantKey = bsonutil.MustHaveTag(TestResult{}, "BuildVariant")
+	TaskNameKey     = bsonutil.MustHaveTag(TestResult{}, "TaskName")
+	RevisionKey     = bsonutil.MustHaveTag(TestResult{}, "Revision")
+	OrderKey        = bsonutil.MustHaveTag(TestResult{}, "Order")
+	QuarantinedKey  = bsonutil.MustHaveTag(TestResult{}, "Quarantined")
+	FlakyKey        = bsonutil.MustHaveTag(TestResult{}, "Flaky")
 )
 
 // FindByTaskIDAndExecution returns test results from the testresults collection for a given task.
@@ -22,6 +22,12 @@ var (
 	SettingsKey     = bsonutil.MustHaveTag(DBUser{}, "Settings")
 	APIKeyKey       = bsonutil.MustHaveTag(DBUser{}, "APIKey")
 	PubKeysKey      = bsonutil.MustHaveTag(DBUser{}, "PubKeys")
+
+	GroupsKey          = bsonutil.MustHaveTag(DBUser{}, "Groups")
+	SuperUserGrantKey  = bsonutil.MustHaveTag(DBUser{}, "SuperUserGrant")
+	AdminProjectsKey   = bsonutil.MustHaveTag(DBUser{}, "AdminProjects")
+	GroupsUpdatedAtKey = bsonutil.MustHaveTag(DBUser{}, "GroupsUpdatedAt")
+	GithubLoginKey     = bsonutil.MustHaveTag(DBUser{}, "GithubLogin")
 )
 
 var (
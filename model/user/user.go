@@ -10,6 +10,12 @@ import (
 	"gopkg.in/mgo.v2/bson"
 )
 
+// GroupGrantTTL is how long the groups recorded at a user's login, and the
+// permissions they grant, are honored. Users must log in again to renew
+// them, so that removing a user from a group takes effect even for users
+// who only use their API key.
+const GroupGrantTTL = 12 * time.Hour
+
 type DBUser struct {
 	Id           string       `bson:"_id"`
 	FirstName    string       `bson:"first_name"`
@@ -21,6 +27,19 @@ type DBUser struct {
 	CreatedAt    time.Time    `bson:"created_at"`
 	Settings     UserSettings `bson:"settings"`
 	APIKey       string       `bson:"apikey"`
+
+	// Groups are the user's groups as reported by the identity
+	// provider at their last login, at GroupsUpdatedAt, and
+	// SuperUserGrant and AdminProjects the permissions those groups
+	// grant.
+	Groups          []string  `bson:"groups,omitempty" json:"groups,omitempty"`
+	SuperUserGrant  bool      `bson:"super_user_grant,omitempty" json:"super_user_grant,omitempty"`
+	AdminProjects   []string  `bson:"admin_projects,omitempty" json:"admin_projects,omitempty"`
+	GroupsUpdatedAt time.Time `bson:"groups_updated_at,omitempty" json:"groups_updated_at,omitempty"`
+
+	// GithubLogin is the user's GitHub login, as verified by the identity
+	// provider they logged in with. It's empty if none has verified it.
+	GithubLogin string `bson:"github_login,omitempty" json:"github_login,omitempty"`
 }
 
 type PubKey struct {
@@ -57,6 +76,71 @@ func (u *DBUser) IsNil() bool {
 	return u == nil
 }
 
+// GetGroups returns the groups the user belonged to at their last login,
+// or none if that was more than GroupGrantTTL ago.
+func (u *DBUser) GetGroups() []string {
+	if u.groupsExpired() {
+		return nil
+	}
+	return u.Groups
+}
+
+// IsGrantedSuperUser returns true if the user's groups make them a super
+// user.
+func (u *DBUser) IsGrantedSuperUser() bool {
+	return u.SuperUserGrant && !u.groupsExpired()
+}
+
+// IsGrantedProjectAdmin returns true if the user's groups make them an
+// admin of the project.
+func (u *DBUser) IsGrantedProjectAdmin(project string) bool {
+	if u.groupsExpired() {
+		return false
+	}
+	for _, p := range u.AdminProjects {
+		if p == project {
+			return true
+		}
+	}
+	return false
+}
+
+func (u *DBUser) groupsExpired() bool {
+	return time.Since(u.GroupsUpdatedAt) > GroupGrantTTL
+}
+
+// UpdateGroups records the user's groups and the permissions they grant,
+// replacing those recorded at the user's previous login.
+func (u *DBUser) UpdateGroups(groups []string, superUser bool, adminProjects []string) error {
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			GroupsKey:          groups,
+			SuperUserGrantKey:  superUser,
+			AdminProjectsKey:   adminProjects,
+			GroupsUpdatedAtKey: now,
+		},
+	}
+	if err := UpdateOne(bson.M{IdKey: u.Id}, update); err != nil {
+		return errors.Wrapf(err, "problem updating groups of user '%s'", u.Id)
+	}
+
+	u.Groups = groups
+	u.SuperUserGrant = superUser
+	u.AdminProjects = adminProjects
+	u.GroupsUpdatedAt = now
+	return nil
+}
+
+// SetGithubLogin records the user's verified GitHub login.
+func (u *DBUser) SetGithubLogin(login string) error {
+	if err := UpdateOne(bson.M{IdKey: u.Id}, bson.M{"$set": bson.M{GithubLoginKey: login}}); err != nil {
+		return errors.Wrapf(err, "problem updating GitHub login of user '%s'", u.Id)
+	}
+	u.GithubLogin = login
+	return nil
+}
+
 func (u *DBUser) GetPublicKey(keyname string) (string, error) {
 	for _, publicKey := range u.PubKeys {
 		if publicKey.Name == keyname {
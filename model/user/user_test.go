@@ -117,3 +117,16 @@ func (s *UserTestSuite) TestDeletePublicKeyThatDoesntExist() {
 	s.NoError(err)
 	s.checkUserNotDestroyed(u, s.users[0])
 }
+
+func (s *UserTestSuite) TestGroupGrantsExpire() {
+	u := s.users[0]
+	s.Require().NoError(u.UpdateGroups([]string{"admins"}, true, []string{"mci"}))
+	s.True(u.IsGrantedSuperUser())
+	s.True(u.IsGrantedProjectAdmin("mci"))
+	s.Equal([]string{"admins"}, u.GetGroups())
+
+	u.GroupsUpdatedAt = time.Now().Add(-GroupGrantTTL - time.Minute)
+	s.False(u.IsGrantedSuperUser())
+	s.False(u.IsGrantedProjectAdmin("mci"))
+	s.Empty(u.GetGroups())
+}
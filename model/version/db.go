@@ -40,6 +40,7 @@ var (
 	IdentifierKey          = bsonutil.MustHaveTag(Version{}, "Identifier")
 	RemoteKey              = bsonutil.MustHaveTag(Version{}, "Remote")
 	RemoteURLKey           = bsonutil.MustHaveTag(Version{}, "RemotePath")
+	GeneratedTasksKey      = bsonutil.MustHaveTag(Version{}, "GeneratedTasks")
 )
 
 // ById returns a db.Q object which will filter on {_id : <the id param>}
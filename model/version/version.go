@@ -44,6 +44,22 @@ type Version struct {
 	// this field is omitted in the database
 	Errors   []string `bson:"errors,omitempty" json:"errors,omitempty"`
 	Warnings []string `bson:"warnings,omitempty" json:"warnings,omitempty"`
+
+	// GeneratedTasks are the tasks that generate.tasks added to the
+	// version's config, which are recorded along with the config so that
+	// creating them can be finished if it fails part way.
+	GeneratedTasks []GeneratedTasks `bson:"generated_tasks,omitempty" json:"generated_tasks,omitempty"`
+}
+
+// FindGeneratedTasks returns the tasks that the task with the given id
+// added to the version's config, or nil if it hasn't added any.
+func (self *Version) FindGeneratedTasks(taskId string) *GeneratedTasks {
+	for i := range self.GeneratedTasks {
+		if self.GeneratedTasks[i].TaskId == taskId {
+			return &self.GeneratedTasks[i]
+		}
+	}
+	return nil
 }
 
 func (self *Version) UpdateBuildVariants() error {
@@ -69,6 +85,20 @@ type BuildStatus struct {
 	BuildId      string    `bson:"build_id,omitempty" json:"build_id,omitempty"`
 }
 
+// GeneratedTasks are the tasks and display tasks that a task generated,
+// by build variant.
+type GeneratedTasks struct {
+	TaskId       string        `bson:"task_id" json:"task_id"`
+	Tasks        []VariantTask `bson:"tasks,omitempty" json:"tasks,omitempty"`
+	DisplayTasks []VariantTask `bson:"display_tasks,omitempty" json:"display_tasks,omitempty"`
+}
+
+// VariantTask names a task of a build variant.
+type VariantTask struct {
+	Variant string `bson:"variant" json:"variant"`
+	Task    string `bson:"task" json:"task"`
+}
+
 var (
 	BuildStatusVariantKey    = bsonutil.MustHaveTag(BuildStatus{}, "BuildVariant")
 	BuildStatusActivatedKey  = bsonutil.MustHaveTag(BuildStatus{}, "Activated")
@@ -0,0 +1,37 @@
+package webhook
+
+import (
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/mongodb/anser/bsonutil"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	// Collection is the name of the collection in MongoDB that stores
+	// the webhook delivery log.
+	Collection = "webhook_deliveries"
+)
+
+var (
+	IdKey         = bsonutil.MustHaveTag(Delivery{}, "Id")
+	URLKey        = bsonutil.MustHaveTag(Delivery{}, "URL")
+	SourceKey     = bsonutil.MustHaveTag(Delivery{}, "Source")
+	TriggerKey    = bsonutil.MustHaveTag(Delivery{}, "Trigger")
+	AttemptsKey   = bsonutil.MustHaveTag(Delivery{}, "Attempts")
+	StatusCodeKey = bsonutil.MustHaveTag(Delivery{}, "StatusCode")
+	DeliveredKey  = bsonutil.MustHaveTag(Delivery{}, "Delivered")
+	ErrorKey      = bsonutil.MustHaveTag(Delivery{}, "Error")
+	CreatedAtKey  = bsonutil.MustHaveTag(Delivery{}, "CreatedAt")
+)
+
+// ByURL returns the most recent deliveries to the given URL, newest
+// first.
+func ByURL(url string, limit int) db.Q {
+	return db.Query(bson.M{URLKey: url}).Sort([]string{"-" + CreatedAtKey}).Limit(limit)
+}
+
+// Failed returns the most recent deliveries that could not be
+// delivered, newest first.
+func Failed(limit int) db.Q {
+	return db.Query(bson.M{DeliveredKey: false}).Sort([]string{"-" + CreatedAtKey}).Limit(limit)
+}
@@ -0,0 +1,177 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model/build"
+	"github.com/evergreen-ci/evergreen/model/host"
+	"github.com/evergreen-ci/evergreen/model/patch"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/model/version"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// PayloadVersion is the version of the payload schema. It is bumped
+// whenever a field is removed or changes meaning, so that receivers can
+// tell which schema they are reading.
+const PayloadVersion = 1
+
+const (
+	// SourceAlerts and SourceNotify identify the subsystem that sent
+	// a webhook.
+	SourceAlerts = "alerts"
+	SourceNotify = "notify"
+)
+
+// Payload is the JSON document posted to webhooks. Only the sections
+// that apply to the event that triggered the webhook are set.
+type Payload struct {
+	SchemaVersion int       `json:"schema_version"`
+	Source        string    `json:"source"`
+	Trigger       string    `json:"trigger"`
+	Subject       string    `json:"subject,omitempty"`
+	URL           string    `json:"url,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	Project     *ProjectInfo `json:"project,omitempty"`
+	Task        *TaskInfo    `json:"task,omitempty"`
+	Build       *BuildInfo   `json:"build,omitempty"`
+	Version     *VersionInfo `json:"version,omitempty"`
+	Patch       *PatchInfo   `json:"patch,omitempty"`
+	Host        *HostInfo    `json:"host,omitempty"`
+	FailedTests []string     `json:"failed_tests,omitempty"`
+}
+
+// NewPayload returns a payload for the given source and trigger, with
+// the current schema version.
+func NewPayload(source, trigger string) *Payload {
+	return &Payload{
+		SchemaVersion: PayloadVersion,
+		Source:        source,
+		Trigger:       trigger,
+		CreatedAt:     time.Now(),
+	}
+}
+
+type ProjectInfo struct {
+	Identifier  string `json:"identifier"`
+	DisplayName string `json:"display_name,omitempty"`
+	Branch      string `json:"branch,omitempty"`
+}
+
+type TaskInfo struct {
+	Id           string    `json:"id"`
+	DisplayName  string    `json:"display_name"`
+	BuildVariant string    `json:"build_variant"`
+	Status       string    `json:"status"`
+	Execution    int       `json:"execution"`
+	Requester    string    `json:"requester,omitempty"`
+	Description  string    `json:"description,omitempty"`
+	TimedOut     bool      `json:"timed_out,omitempty"`
+	FinishTime   time.Time `json:"finish_time,omitempty"`
+}
+
+func NewTaskInfo(t *task.Task) *TaskInfo {
+	return &TaskInfo{
+		Id:           t.Id,
+		DisplayName:  t.DisplayName,
+		BuildVariant: t.BuildVariant,
+		Status:       t.Status,
+		Execution:    t.Execution,
+		Requester:    t.Requester,
+		Description:  t.Details.Description,
+		TimedOut:     t.Details.TimedOut,
+		FinishTime:   t.FinishTime,
+	}
+}
+
+type BuildInfo struct {
+	Id           string `json:"id"`
+	DisplayName  string `json:"display_name"`
+	BuildVariant string `json:"build_variant"`
+	Status       string `json:"status"`
+}
+
+func NewBuildInfo(b *build.Build) *BuildInfo {
+	return &BuildInfo{
+		Id:           b.Id,
+		DisplayName:  b.DisplayName,
+		BuildVariant: b.BuildVariant,
+		Status:       b.Status,
+	}
+}
+
+type VersionInfo struct {
+	Id       string `json:"id"`
+	Revision string `json:"revision"`
+	Author   string `json:"author"`
+	Message  string `json:"message"`
+}
+
+func NewVersionInfo(v *version.Version) *VersionInfo {
+	return &VersionInfo{
+		Id:       v.Id,
+		Revision: v.Revision,
+		Author:   v.Author,
+		Message:  v.Message,
+	}
+}
+
+type PatchInfo struct {
+	Id          string `json:"id"`
+	Author      string `json:"author"`
+	Description string `json:"description"`
+}
+
+func NewPatchInfo(p *patch.Patch) *PatchInfo {
+	return &PatchInfo{
+		Id:          p.Id.Hex(),
+		Author:      p.Author,
+		Description: p.Description,
+	}
+}
+
+type HostInfo struct {
+	Id     string `json:"id"`
+	Distro string `json:"distro"`
+	Host   string `json:"host,omitempty"`
+}
+
+func NewHostInfo(h *host.Host) *HostInfo {
+	return &HostInfo{
+		Id:     h.Id,
+		Distro: h.Distro.Id,
+		Host:   h.Host,
+	}
+}
+
+// Delivery records an attempt to deliver a webhook, for the delivery
+// log.
+type Delivery struct {
+	Id         bson.ObjectId `bson:"_id"`
+	URL        string        `bson:"url"`
+	Source     string        `bson:"source"`
+	Trigger    string        `bson:"trigger"`
+	Attempts   int           `bson:"attempts"`
+	StatusCode int           `bson:"status_code,omitempty"`
+	Delivered  bool          `bson:"delivered"`
+	Error      string        `bson:"error,omitempty"`
+	CreatedAt  time.Time     `bson:"created_at"`
+	Duration   time.Duration `bson:"duration"`
+}
+
+// Insert adds the delivery to the delivery log.
+func (d *Delivery) Insert() error {
+	if d.Id == "" {
+		d.Id = bson.NewObjectId()
+	}
+	return db.Insert(Collection, d)
+}
+
+// FindDeliveries returns the deliveries matching the query.
+func FindDeliveries(query db.Q) ([]Delivery, error) {
+	deliveries := []Delivery{}
+	err := db.FindAllQ(Collection, query, &deliveries)
+	return deliveries, err
+}
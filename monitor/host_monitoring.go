@@ -111,10 +111,15 @@ func checkHostReachability(host host.Host, settings *evergreen.Settings) error {
 	// take different action, depending on how the cloud provider reports the host's status
 	switch cloudStatus {
 	case cloud.StatusRunning:
-		// check if the host is reachable via SSH
-		reachable, err := cloudHost.IsSSHReachable()
-		if err != nil {
-			return errors.Wrapf(err, "error checking ssh reachability for host %s", host.Id)
+		// check if the host is reachable via SSH. Hosts that bootstrap
+		// themselves with user data don't accept SSH connections, so
+		// they're reachable whenever the cloud provider says they're running.
+		reachable := true
+		if !host.BootstrapsWithUserData() {
+			reachable, err = cloudHost.IsSSHReachable()
+			if err != nil {
+				return errors.Wrapf(err, "error checking ssh reachability for host %s", host.Id)
+			}
 		}
 
 		// log the status update if the reachability of the host is changing
@@ -182,8 +182,9 @@ func terminateHost(ctx context.Context, h *host.Host, settings *evergreen.Settin
 		return errors.Wrapf(err, "error getting cloud host for %v", h.Id)
 	}
 
-	// run teardown script if we have one, sending notifications if things go awry
-	if h.Distro.Teardown != "" && h.Provisioned {
+	// run teardown script if we have one, sending notifications if things go awry.
+	// Hosts that bootstrap themselves with user data can't be reached over SSH.
+	if h.Distro.Teardown != "" && h.Provisioned && !h.BootstrapsWithUserData() {
 		grip.Info(message.Fields{
 			"runner":  RunnerName,
 			"message": "running teardown script for host",
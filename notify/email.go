@@ -3,6 +3,8 @@ package notify
 import (
 	"strings"
 
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model/webhook"
 	"github.com/evergreen-ci/evergreen/util"
 	"github.com/mongodb/grip"
 	"github.com/mongodb/grip/message"
@@ -21,6 +23,8 @@ type Email interface {
 	GetChangeInfo() []ChangeInfo
 	// Should you skip sending this email given the provided variants to skip
 	ShouldSkip(skipVariants []string) bool
+	// the payload to post to webhooks subscribed to this notification
+	GetWebhookPayload(uiRoot string) *webhook.Payload
 }
 
 // "Base class" for structs that implement the Email interface. Stores the
@@ -73,6 +77,17 @@ func (self *BuildEmail) GetRecipients(defaultRecipient string) []string {
 	return []string{defaultRecipient}
 }
 
+func (self *BuildEmail) GetWebhookPayload(uiRoot string) *webhook.Payload {
+	payload := webhook.NewPayload(webhook.SourceNotify, self.Trigger.Key.NotificationName)
+	payload.Subject = self.Subject
+	payload.Project = &webhook.ProjectInfo{Identifier: self.Trigger.Key.Project}
+	if self.Trigger.Current != nil {
+		payload.Build = webhook.NewBuildInfo(self.Trigger.Current)
+		payload.URL = uiRoot + "/build/" + self.Trigger.Current.Id
+	}
+	return payload
+}
+
 // Implements Email interface for task-specific emails. Defines skip logic for
 // emails specific to tasks
 type TaskEmail struct {
@@ -103,3 +118,19 @@ func (self *TaskEmail) IsLikelySystemFailure() bool {
 func (self *TaskEmail) GetRecipients(defaultRecipient string) []string {
 	return []string{defaultRecipient}
 }
+
+func (self *TaskEmail) GetWebhookPayload(uiRoot string) *webhook.Payload {
+	payload := webhook.NewPayload(webhook.SourceNotify, self.Trigger.Key.NotificationName)
+	payload.Subject = self.Subject
+	payload.Project = &webhook.ProjectInfo{Identifier: self.Trigger.Key.Project}
+	if self.Trigger.Current != nil {
+		payload.Task = webhook.NewTaskInfo(self.Trigger.Current)
+		payload.URL = uiRoot + "/task/" + self.Trigger.Current.Id
+		for _, test := range self.Trigger.Current.LocalTestResults {
+			if test.Status == evergreen.TestFailedStatus {
+				payload.FailedTests = append(payload.FailedTests, test.TestFile)
+			}
+		}
+	}
+	return payload
+}
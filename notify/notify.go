@@ -3,6 +3,7 @@ package notify
 import (
 	"fmt"
 	"io/ioutil"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
@@ -16,6 +17,8 @@ import (
 	"github.com/evergreen-ci/evergreen/model/task"
 	"github.com/evergreen-ci/evergreen/model/user"
 	"github.com/evergreen-ci/evergreen/model/version"
+	"github.com/evergreen-ci/evergreen/model/webhook"
+	"github.com/evergreen-ci/evergreen/thirdparty"
 	"github.com/evergreen-ci/evergreen/util"
 	"github.com/evergreen-ci/evergreen/web"
 	"github.com/mongodb/grip"
@@ -291,6 +294,10 @@ func ValidateNotifications(mciNotification *MCINotification) error {
 			}
 		}
 
+		if err = validateWebhooks(notification.Webhooks); err != nil {
+			return errors.Wrapf(err, "invalid webhook for ”%v” notification", notification.Name)
+		}
+
 		allNotifications = append(allNotifications, notification.Name)
 	}
 
@@ -306,6 +313,9 @@ func ValidateNotifications(mciNotification *MCINotification) error {
 					return errors.Errorf("Team ”%v” contains a non-existent subscription - %v", team.Name, notification)
 				}
 			}
+			if err = validateWebhooks(subscription.Webhooks); err != nil {
+				return errors.Wrapf(err, "invalid webhook for team ”%v”", team.Name)
+			}
 			for _, buildVariant := range subscription.SkipVariants {
 				buildVariants, ok := projectNameToBuildVariants[subscription.Project]
 				if !ok {
@@ -404,6 +414,9 @@ func SendNotifications(settings *evergreen.Settings, mciNotification *MCINotific
 			NotificationRequester: evergreen.RepotrackerVersionRequester,
 		}
 
+		// post all triggered notifications to the notification's webhooks
+		sendWebhookNotifications(settings, notification.Webhooks, notification.SkipVariants, emails[key])
+
 		for _, recipient := range notification.Recipients {
 			// send all triggered notifications
 			for _, email := range emails[key] {
@@ -445,6 +458,8 @@ func SendNotifications(settings *evergreen.Settings, mciNotification *MCINotific
 					NotificationRequester: evergreen.RepotrackerVersionRequester,
 				}
 
+				sendWebhookNotifications(settings, subscription.Webhooks, subscription.SkipVariants, emails[key])
+
 				// send all triggered notifications for this key
 				for _, email := range emails[key] {
 					// determine if this notification should be skipped - based on the buildvariant
@@ -588,7 +603,7 @@ func findProjectBuildVariants() (map[string][]string, error) {
 func constructChangeInfo(v *version.Version, notification *NotificationKey) (changeInfo *ChangeInfo) {
 	changeInfo = &ChangeInfo{}
 	switch notification.NotificationRequester {
-	case evergreen.RepotrackerVersionRequester:
+	case evergreen.RepotrackerVersionRequester, evergreen.AdHocVersionRequester:
 		changeInfo.Project = v.Identifier
 		changeInfo.Author = v.Author
 		changeInfo.Message = v.Message
@@ -872,6 +887,53 @@ func TrySendNotification(recipients []string, subject, body string, mailer Maile
 	return errors.WithStack(err)
 }
 
+// newWebhookSender returns the transport used to post notifications to
+// webhooks.
+var newWebhookSender = func(target WebhookTarget) webhookSender {
+	return thirdparty.NewWebhookSender(target.URL, target.Secret)
+}
+
+type webhookSender interface {
+	Send(*webhook.Payload) error
+}
+
+// Helper function to post triggered notifications to webhooks, skipping
+// those for the given variants. Each delivery is retried and recorded by
+// the webhook transport, so failures are only logged here.
+func sendWebhookNotifications(settings *evergreen.Settings, targets []WebhookTarget,
+	skipVariants []string, emails []Email) {
+
+	for _, target := range targets {
+		sender := newWebhookSender(target)
+		for _, email := range emails {
+			if email.ShouldSkip(skipVariants) {
+				continue
+			}
+
+			err := sender.Send(email.GetWebhookPayload(settings.Ui.Url))
+			grip.Error(message.WrapError(err, message.Fields{
+				"runner":  RunnerName,
+				"message": "unable to send webhook notification",
+				"url":     target.URL,
+				"subject": email.GetSubject(),
+			}))
+		}
+	}
+}
+
+func validateWebhooks(targets []WebhookTarget) error {
+	for _, target := range targets {
+		u, err := url.Parse(target.URL)
+		if err != nil {
+			return errors.Wrapf(err, "webhook url '%s' is invalid", target.URL)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return errors.Errorf("webhook url '%s' must be an http or https url", target.URL)
+		}
+	}
+	return nil
+}
+
 // Helper function to send notification to a given user
 func TrySendNotificationToUser(userId string, subject, body string, mailer Mailer) error {
 	dbUser, err := user.FindOne(user.ById(userId))
@@ -890,17 +952,26 @@ func TrySendNotificationToUser(userId string, subject, body string, mailer Maile
 
 // stores supported notifications
 type Notification struct {
-	Name         string   `yaml:"name"`
-	Project      string   `yaml:"project"`
-	Recipients   []string `yaml:"recipients"`
-	SkipVariants []string `yaml:"skip_variants"`
+	Name         string          `yaml:"name"`
+	Project      string          `yaml:"project"`
+	Recipients   []string        `yaml:"recipients"`
+	SkipVariants []string        `yaml:"skip_variants"`
+	Webhooks     []WebhookTarget `yaml:"webhooks"`
 }
 
 // stores notifications subscription for a team
 type Subscription struct {
-	Project      string   `yaml:"project"`
-	SkipVariants []string `yaml:"skip_variants"`
-	NotifyOn     []string `yaml:"notify_on"`
+	Project      string          `yaml:"project"`
+	SkipVariants []string        `yaml:"skip_variants"`
+	NotifyOn     []string        `yaml:"notify_on"`
+	Webhooks     []WebhookTarget `yaml:"webhooks"`
+}
+
+// stores a webhook that notifications are posted to, and the secret
+// its payloads are signed with
+type WebhookTarget struct {
+	URL    string `yaml:"url"`
+	Secret string `yaml:"secret"`
 }
 
 // stores 10gen team information
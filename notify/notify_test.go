@@ -218,18 +218,18 @@ func TestNotify(t *testing.T) {
 			"notification keys", func() {
 			notificationSettings := &MCINotification{}
 			notificationSettings.Notifications = []Notification{
-				{"task_failure", "project", []string{"user@mongodb"}, []string{}},
-				{"task_success_to_failure", "project", []string{"user@mongodb"}, []string{}},
+				{"task_failure", "project", []string{"user@mongodb"}, []string{}, nil},
+				{"task_success_to_failure", "project", []string{"user@mongodb"}, []string{}, nil},
 			}
 			notificationSettings.Teams = []Team{
 				{
 					"myteam",
 					"myteam@me.com",
-					[]Subscription{{"task", []string{}, []string{"task_failure"}}},
+					[]Subscription{{"task", []string{}, []string{"task_failure"}, nil}},
 				},
 			}
 			notificationSettings.PatchNotifications = []Subscription{
-				{"patch_project", []string{}, []string{}},
+				{"patch_project", []string{}, []string{}, nil},
 			}
 
 			notificationKeyFailure := NotificationKey{"project", "task_failure", "task", "gitter_request"}
@@ -257,17 +257,17 @@ func TestNotify(t *testing.T) {
 		Convey("SendNotifications should send emails correctly", func() {
 			notificationSettings := &MCINotification{}
 			notificationSettings.Notifications = []Notification{
-				{"task_failure", "project", []string{"user@mongodb"}, []string{}},
+				{"task_failure", "project", []string{"user@mongodb"}, []string{}, nil},
 			}
 			notificationSettings.Teams = []Team{
 				{
 					"myteam",
 					"myteam@me.com",
-					[]Subscription{{"task", []string{}, []string{"task_failure"}}},
+					[]Subscription{{"task", []string{}, []string{"task_failure"}, nil}},
 				},
 			}
 			notificationSettings.PatchNotifications = []Subscription{
-				{"patch_project", []string{}, []string{}},
+				{"patch_project", []string{}, []string{}, nil},
 			}
 
 			fakeTask, err := task.FindOne(task.ById("task8"))
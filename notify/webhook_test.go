@@ -0,0 +1,96 @@
+package notify
+
+import (
+	"testing"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/model/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockWebhookSender struct {
+	payloads *[]*webhook.Payload
+}
+
+func (s *mockWebhookSender) Send(p *webhook.Payload) error {
+	*s.payloads = append(*s.payloads, p)
+	return nil
+}
+
+func TestSendWebhookNotifications(t *testing.T) {
+	assert := assert.New(t)
+
+	payloads := []*webhook.Payload{}
+	targets := []string{}
+	oldSender := newWebhookSender
+	defer func() { newWebhookSender = oldSender }()
+	newWebhookSender = func(target WebhookTarget) webhookSender {
+		targets = append(targets, target.URL)
+		return &mockWebhookSender{payloads: &payloads}
+	}
+
+	key := NotificationKey{
+		Project:               "project",
+		NotificationName:      taskFailureKey,
+		NotificationType:      taskType,
+		NotificationRequester: evergreen.RepotrackerVersionRequester,
+	}
+	emails := map[NotificationKey][]Email{
+		key: {
+			&TaskEmail{
+				EmailBase: EmailBase{Subject: "compile failed"},
+				Trigger: TriggeredTaskNotification{
+					Current: &task.Task{
+						Id:           "t1",
+						BuildVariant: "linux",
+						LocalTestResults: []task.TestResult{
+							{TestFile: "a_test", Status: evergreen.TestFailedStatus},
+							{TestFile: "b_test", Status: evergreen.TestSucceededStatus},
+						},
+					},
+					Key: key,
+				},
+			},
+			&TaskEmail{
+				EmailBase: EmailBase{Subject: "skipped"},
+				Trigger: TriggeredTaskNotification{
+					Current: &task.Task{Id: "t2", BuildVariant: "windows"},
+					Key:     key,
+				},
+			},
+		},
+	}
+	notifications := &MCINotification{
+		Notifications: []Notification{{
+			Name:         taskFailureKey,
+			Project:      "project",
+			SkipVariants: []string{"windows"},
+			Webhooks:     []WebhookTarget{{URL: "http://example.com/hook", Secret: "shh"}},
+		}},
+	}
+
+	settings := &evergreen.Settings{}
+	settings.Ui.Url = "http://evergreen.example.com"
+	assert.NoError(SendNotifications(settings, notifications, emails, MockMailer{}))
+
+	assert.Equal([]string{"http://example.com/hook"}, targets)
+	assert.Len(payloads, 1)
+	p := payloads[0]
+	assert.Equal(webhook.SourceNotify, p.Source)
+	assert.Equal(taskFailureKey, p.Trigger)
+	assert.Equal("compile failed", p.Subject)
+	assert.Equal("project", p.Project.Identifier)
+	assert.Equal("t1", p.Task.Id)
+	assert.Equal("http://evergreen.example.com/task/t1", p.URL)
+	assert.Equal([]string{"a_test"}, p.FailedTests)
+}
+
+func TestValidateWebhooks(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.NoError(validateWebhooks(nil))
+	assert.NoError(validateWebhooks([]WebhookTarget{{URL: "https://example.com/hook"}}))
+	assert.Error(validateWebhooks([]WebhookTarget{{URL: ""}}))
+	assert.Error(validateWebhooks([]WebhookTarget{{URL: "ftp://example.com"}}))
+}
@@ -0,0 +1,127 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/evergreen-ci/evergreen/rest/client"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+const commitQueueItemFlagName = "item"
+
+func CommitQueue() cli.Command {
+	return cli.Command{
+		Name:  "commit-queue",
+		Usage: "work with a project's queue of pull requests and patches to test and merge",
+		Subcommands: []cli.Command{
+			listCommitQueue(),
+			enqueueCommitQueueItem(),
+			deleteCommitQueueItem(),
+		},
+	}
+}
+
+func listCommitQueue() cli.Command {
+	return cli.Command{
+		Name:   "list",
+		Usage:  "list the items in a project's commit queue, in the order they will be merged",
+		Flags:  addProjectFlag(),
+		Before: setPlainLogger,
+		Action: func(c *cli.Context) error {
+			return withCommitQueueProject(c, func(ctx context.Context, comm client.Communicator, projectID string) error {
+				cq, err := comm.GetCommitQueue(ctx, projectID)
+				if err != nil {
+					return errors.WithStack(err)
+				}
+
+				if len(cq.Queue) == 0 {
+					fmt.Printf("the commit queue for project '%s' is empty\n", projectID)
+					return nil
+				}
+				for i, item := range cq.Queue {
+					status := "waiting"
+					if item.Version != "" {
+						status = fmt.Sprintf("testing in version %s", item.Version)
+					}
+					fmt.Printf("%3d: %-5s %s, added by %s at %s, %s\n", i, item.Type, item.Issue, item.Author,
+						time.Time(item.EnqueueTime).Format(time.RFC822), status)
+				}
+				return nil
+			})
+		},
+	}
+}
+
+func enqueueCommitQueueItem() cli.Command {
+	return cli.Command{
+		Name:  "merge",
+		Usage: "add a pull request number or patch id to the end of a project's commit queue",
+		Flags: addProjectFlag(cli.StringFlag{
+			Name:  joinFlagNames(commitQueueItemFlagName, "i"),
+			Usage: "the number of the pull request or the id of the patch",
+		}),
+		Before: mergeBeforeFuncs(setPlainLogger, requireStringFlag(commitQueueItemFlagName)),
+		Action: func(c *cli.Context) error {
+			item := c.String(commitQueueItemFlagName)
+			return withCommitQueueProject(c, func(ctx context.Context, comm client.Communicator, projectID string) error {
+				position, err := comm.EnqueueItem(ctx, projectID, item)
+				if err != nil {
+					return errors.WithStack(err)
+				}
+				fmt.Printf("added %s to the commit queue for project '%s' at position %d\n", item, projectID, position)
+				return nil
+			})
+		},
+	}
+}
+
+func deleteCommitQueueItem() cli.Command {
+	return cli.Command{
+		Name:  "delete",
+		Usage: "remove a pull request number or patch id from a project's commit queue",
+		Flags: addProjectFlag(cli.StringFlag{
+			Name:  joinFlagNames(commitQueueItemFlagName, "i"),
+			Usage: "the number of the pull request or the id of the patch",
+		}),
+		Before: mergeBeforeFuncs(setPlainLogger, requireStringFlag(commitQueueItemFlagName)),
+		Action: func(c *cli.Context) error {
+			item := c.String(commitQueueItemFlagName)
+			return withCommitQueueProject(c, func(ctx context.Context, comm client.Communicator, projectID string) error {
+				if err := comm.DeleteCommitQueueItem(ctx, projectID, item); err != nil {
+					return errors.WithStack(err)
+				}
+				fmt.Printf("removed %s from the commit queue for project '%s'\n", item, projectID)
+				return nil
+			})
+		},
+	}
+}
+
+// withCommitQueueProject calls the operation with a communicator and the
+// project named by the flag, or the user's default project.
+func withCommitQueueProject(c *cli.Context, op func(context.Context, client.Communicator, string) error) error {
+	confPath := c.Parent().Parent().String(confFlagName)
+	projectID := c.String(projectFlagName)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conf, err := NewClientSetttings(confPath)
+	if err != nil {
+		return errors.Wrap(err, "problem loading configuration")
+	}
+	if projectID == "" {
+		projectID = conf.FindDefaultProject()
+	}
+	if projectID == "" {
+		return errors.Errorf("flag '--%s' was not specified and there is no default project", projectFlagName)
+	}
+
+	comm := conf.GetRestCommunicator(ctx)
+	defer comm.Close()
+
+	return op(ctx, comm, projectID)
+}
@@ -2,9 +2,7 @@ package operations
 
 import (
 	"fmt"
-	"io/ioutil"
 
-	"github.com/evergreen-ci/evergreen/model"
 	"github.com/pkg/errors"
 	"github.com/urfave/cli"
 	yaml "gopkg.in/yaml.v2"
@@ -34,15 +32,9 @@ func Evaluate() cli.Command {
 			showTasks := c.Bool(taskFlagName)
 			showVariants := c.Bool(variantsFlagName)
 
-			configBytes, err := ioutil.ReadFile(path)
+			p, err := loadLocalConfig(path)
 			if err != nil {
-				return errors.Wrap(err, "error reading project config")
-			}
-
-			p := &model.Project{}
-			err = model.LoadProjectInto(configBytes, "", p)
-			if err != nil {
-				return errors.Wrap(err, "error loading project")
+				return errors.WithStack(err)
 			}
 
 			var out interface{}
@@ -0,0 +1,308 @@
+package operations
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/apimodels"
+	"github.com/evergreen-ci/evergreen/rest/client"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mongodb/grip"
+	"github.com/mongodb/grip/message"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+const (
+	// bootstrapLogInterval is how often the output of a running setup
+	// script is sent to the API server.
+	bootstrapLogInterval = 10 * time.Second
+	// bootstrapAgentRestartInterval is how long to wait before starting
+	// a new agent after the previous one exits.
+	bootstrapAgentRestartInterval = 10 * time.Second
+	// bootstrapMaxSetupLogBytes caps the setup script output that is kept
+	// to send with the setup result.
+	bootstrapMaxSetupLogBytes = 1024 * 1024
+	// bootstrapAgentDirectory holds the agent binary that the bootstrap
+	// process downloads, which is separate from the running binary so that
+	// it can be replaced.
+	bootstrapAgentDirectory = "agent"
+)
+
+func hostBootstrap() cli.Command {
+	const (
+		apiServerFlagName = "api_server"
+		tokenFlagName     = "token"
+	)
+
+	return cli.Command{
+		Name:  "bootstrap",
+		Usage: "register a build host, run its setup script, and keep an agent running",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  apiServerFlagName,
+				Usage: "URL of the API server",
+			},
+			cli.StringFlag{
+				Name:  tokenFlagName,
+				Usage: "one-time token that the host registers with",
+			},
+		},
+		Before: mergeBeforeFuncs(
+			func(c *cli.Context) error {
+				grip.SetName("evergreen.bootstrap")
+				return nil
+			},
+			requireStringFlag(apiServerFlagName),
+			requireStringFlag(tokenFlagName),
+		),
+		Action: func(c *cli.Context) error {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			comm := client.NewCommunicator(c.String(apiServerFlagName))
+			defer comm.Close()
+
+			registration, err := comm.RegisterHost(ctx, c.String(tokenFlagName))
+			if err != nil {
+				return errors.Wrap(err, "problem registering host")
+			}
+			comm.SetHostID(registration.HostId)
+			comm.SetHostSecret(registration.Secret)
+
+			grip.Info(message.Fields{
+				"message": "registered host",
+				"host":    registration.HostId,
+			})
+
+			if err = runBootstrapSetup(ctx, comm, registration); err != nil {
+				return errors.Wrap(err, "problem setting up host")
+			}
+
+			return errors.WithStack(runBootstrapAgent(ctx, comm, c.String(apiServerFlagName), registration))
+		},
+	}
+}
+
+// setupOutput collects the output of a setup script while it runs, so that
+// it can be sent to the API server in pieces.
+type setupOutput struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	unsent bytes.Buffer
+}
+
+func (o *setupOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if remaining := bootstrapMaxSetupLogBytes - o.buf.Len(); remaining > 0 {
+		if len(p) > remaining {
+			o.buf.Write(p[:remaining])
+		} else {
+			o.buf.Write(p)
+		}
+	}
+	return o.unsent.Write(p)
+}
+
+// flush returns the output written since the last call.
+func (o *setupOutput) flush() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	out := o.unsent.String()
+	o.unsent.Reset()
+	return out
+}
+
+func (o *setupOutput) String() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.buf.String()
+}
+
+// runBootstrapSetup runs the distro's setup script, streaming its output to
+// the API server, and reports whether it succeeded.
+func runBootstrapSetup(ctx context.Context, comm client.Communicator, registration *apimodels.HostRegistrationResponse) error {
+	output := &setupOutput{}
+	err := runBootstrapSetupScript(ctx, comm, registration, output)
+	grip.Error(message.WrapError(err, message.Fields{
+		"message": "setup script failed",
+		"host":    registration.HostId,
+	}))
+
+	result := &apimodels.HostSetupResult{
+		Success: err == nil,
+		Logs:    output.String(),
+	}
+	if err != nil {
+		result.Logs += "\n" + err.Error()
+	}
+
+	if endErr := comm.EndHostSetup(ctx, result); endErr != nil {
+		return errors.Wrap(endErr, "problem sending setup result")
+	}
+
+	return errors.Wrap(err, "problem running setup script")
+}
+
+func runBootstrapSetupScript(ctx context.Context, comm client.Communicator, registration *apimodels.HostRegistrationResponse, output *setupOutput) error {
+	grip.Warning(os.MkdirAll(registration.WorkingDirectory, 0777))
+
+	if registration.Setup == "" {
+		return nil
+	}
+
+	if err := ioutil.WriteFile(evergreen.SetupScriptName, []byte(registration.Setup), 0755); err != nil {
+		return errors.Wrap(err, "problem writing setup script")
+	}
+	defer func() {
+		grip.Warning(os.Remove(evergreen.SetupScriptName))
+	}()
+
+	setupCtx, cancel := context.WithTimeout(ctx, setupTimeout)
+	defer cancel()
+
+	cmd := getShCommandWithSudo(setupCtx, evergreen.SetupScriptName, registration.SetupAsSudo)
+	cmd.Stdout = output
+	cmd.Stderr = output
+
+	if err := cmd.Start(); err != nil {
+		return errors.Wrap(err, "problem starting setup script")
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	ticker := time.NewTicker(bootstrapLogInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case err := <-done:
+			grip.Warning(os.MkdirAll(registration.WorkingDirectory, 0777))
+			return errors.WithStack(err)
+		case <-ticker.C:
+			if logs := output.flush(); logs != "" {
+				grip.Warning(comm.AppendHostSetupLog(ctx, logs))
+			}
+		}
+	}
+}
+
+// runBootstrapAgent downloads and runs the agent, and starts a new agent
+// whenever the previous one exits, until the API server says the host should
+// stop.
+func runBootstrapAgent(ctx context.Context, comm client.Communicator, apiServer string, registration *apimodels.HostRegistrationResponse) error {
+	if err := os.MkdirAll(bootstrapAgentDirectory, 0755); err != nil {
+		return errors.Wrap(err, "problem creating agent directory")
+	}
+	binary, err := filepath.Abs(filepath.Join(bootstrapAgentDirectory, filepath.Base(registration.AgentURL)))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for {
+		if err = downloadAgent(ctx, registration.AgentURL, binary); err != nil {
+			grip.Error(message.WrapError(err, message.Fields{
+				"message": "problem downloading agent",
+				"url":     registration.AgentURL,
+			}))
+		} else {
+			if err = comm.StartHostAgent(ctx); err != nil {
+				return errors.Wrap(err, "host should not start an agent")
+			}
+
+			cmd := bootstrapAgentCommand(ctx, binary, apiServer, registration)
+			grip.Info(message.Fields{
+				"message": "starting agent",
+				"command": cmd.Args,
+			})
+			grip.Warning(message.WrapError(cmd.Run(), message.Fields{
+				"message": "agent exited",
+			}))
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.WithStack(ctx.Err())
+		case <-time.After(bootstrapAgentRestartInterval):
+		}
+	}
+}
+
+func bootstrapAgentCommand(ctx context.Context, binary, apiServer string, registration *apimodels.HostRegistrationResponse) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, binary,
+		"agent",
+		fmt.Sprintf("--api_server=%s", apiServer),
+		fmt.Sprintf("--host_id=%s", registration.HostId),
+		fmt.Sprintf("--host_secret=%s", registration.Secret),
+		fmt.Sprintf("--log_prefix=%s", filepath.Join(registration.WorkingDirectory, "agent")),
+		fmt.Sprintf("--working_directory=%s", registration.WorkingDirectory),
+		"--cleanup")
+
+	keys := make([]string, 0, len(registration.AgentEnvironment))
+	for k := range registration.AgentEnvironment {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	cmd.Env = os.Environ()
+	for _, k := range keys {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, registration.AgentEnvironment[k]))
+	}
+
+	return cmd
+}
+
+// downloadAgent replaces the agent binary at path with the one at url.
+func downloadAgent(ctx context.Context, url, path string) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	httpClient := util.GetHttpClient()
+	defer util.PutHttpClient(httpClient)
+
+	resp, err := httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.Wrapf(err, "problem downloading '%s'", url)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("problem downloading '%s': %s", url, resp.Status)
+	}
+
+	tmp := path + ".download"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return errors.Wrapf(err, "problem creating '%s'", tmp)
+	}
+	_, err = io.Copy(file, resp.Body)
+	catcher := grip.NewSimpleCatcher()
+	catcher.Add(err)
+	catcher.Add(file.Close())
+	if catcher.HasErrors() {
+		grip.Warning(os.Remove(tmp))
+		return errors.Wrapf(catcher.Resolve(), "problem writing '%s'", tmp)
+	}
+
+	return errors.Wrapf(os.Rename(tmp, path), "problem moving agent to '%s'", path)
+}
@@ -0,0 +1,96 @@
+package operations
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/apimodels"
+	"github.com/evergreen-ci/evergreen/rest/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBootstrapSetup(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	registration := &apimodels.HostRegistrationResponse{
+		HostId:           "host",
+		WorkingDirectory: filepath.Join(dir, "data"),
+	}
+
+	// With no setup script, setup succeeds
+	comm := client.NewMock("url")
+	assert.NoError(runBootstrapSetup(context.Background(), comm, registration))
+	require.NotNil(comm.HostSetupResult)
+	assert.True(comm.HostSetupResult.Success)
+	_, err = os.Stat(registration.WorkingDirectory)
+	assert.NoError(err)
+
+	// A successful setup script sends its output
+	comm = client.NewMock("url")
+	registration.Setup = "echo \"hello, world\""
+	assert.NoError(runBootstrapSetup(context.Background(), comm, registration))
+	require.NotNil(comm.HostSetupResult)
+	assert.True(comm.HostSetupResult.Success)
+	assert.Contains(comm.HostSetupResult.Logs, "hello, world")
+
+	// Ensure the script is deleted after running
+	_, err = os.Stat(evergreen.SetupScriptName)
+	assert.True(os.IsNotExist(err))
+
+	// A failing setup script is reported as a failure
+	comm = client.NewMock("url")
+	registration.Setup = "echo \"goodbye\"; exit 1"
+	assert.Error(runBootstrapSetup(context.Background(), comm, registration))
+	require.NotNil(comm.HostSetupResult)
+	assert.False(comm.HostSetupResult.Success)
+	assert.Contains(comm.HostSetupResult.Logs, "goodbye")
+}
+
+func TestSetupOutput(t *testing.T) {
+	assert := assert.New(t)
+
+	output := &setupOutput{}
+	_, err := output.Write([]byte("foo"))
+	assert.NoError(err)
+	assert.Equal("foo", output.flush())
+	assert.Equal("", output.flush())
+
+	_, err = output.Write([]byte("bar"))
+	assert.NoError(err)
+	assert.Equal("bar", output.flush())
+	assert.Equal("foobar", output.String())
+}
+
+func TestBootstrapAgentCommand(t *testing.T) {
+	assert := assert.New(t)
+
+	registration := &apimodels.HostRegistrationResponse{
+		HostId:           "host",
+		Secret:           "secret",
+		WorkingDirectory: "/data/mci",
+		AgentEnvironment: map[string]string{"GRIP_SUMO_ENDPOINT": "endpoint"},
+	}
+
+	cmd := bootstrapAgentCommand(context.Background(), "/home/user/agent/evergreen", "https://example.com", registration)
+	assert.Equal([]string{
+		"/home/user/agent/evergreen",
+		"agent",
+		"--api_server=https://example.com",
+		"--host_id=host",
+		"--host_secret=secret",
+		"--log_prefix=/data/mci/agent",
+		"--working_directory=/data/mci",
+		"--cleanup",
+	}, cmd.Args)
+	assert.Contains(cmd.Env, "GRIP_SUMO_ENDPOINT=endpoint")
+}
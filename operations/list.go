@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"sort"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/evergreen-ci/evergreen/model"
@@ -288,17 +291,43 @@ func listDistros(ctx context.Context, confPath string, onlyUserSpawnable bool) e
 }
 
 // LoadLocalConfig loads the local project config into a project
-func loadLocalConfig(filepath string) (*model.Project, error) {
-	configBytes, err := ioutil.ReadFile(filepath)
+func loadLocalConfig(configPath string) (*model.Project, error) {
+	configBytes, err := ioutil.ReadFile(configPath)
 	if err != nil {
 		return nil, errors.Wrap(err, "error reading project config")
 	}
 
 	project := &model.Project{}
-	err = model.LoadProjectInto(configBytes, "", project)
+	err = model.LoadProjectWithIncludes(configBytes, "", localProjectFileReader(configPath), project)
 	if err != nil {
 		return nil, errors.Wrap(err, "error loading project")
 	}
 
 	return project, nil
 }
+
+// localProjectFileReader reads the files included by the project config at
+// configPath from the working copy that the config is in, and from the
+// remotes of modules.
+func localProjectFileReader(configPath string) model.ProjectFileReader {
+	root := localRepositoryRoot(configPath)
+	return func(module *model.Module, fileName string) ([]byte, error) {
+		if module != nil {
+			return model.ReadModuleFile(module, fileName, "")
+		}
+		return ioutil.ReadFile(filepath.Join(root, fileName))
+	}
+}
+
+// localRepositoryRoot returns the root of the git working copy that the
+// file is in, or the file's directory if it isn't in one.
+func localRepositoryRoot(fileName string) string {
+	dir := filepath.Dir(fileName)
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return dir
+	}
+	return strings.TrimSpace(string(out))
+}
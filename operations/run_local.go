@@ -0,0 +1,211 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/agent"
+	"github.com/evergreen-ci/evergreen/command"
+	"github.com/evergreen-ci/evergreen/rest/client"
+	"github.com/mongodb/grip"
+	"github.com/mongodb/grip/message"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func RunLocal() cli.Command {
+	const (
+		taskFlagName           = "task"
+		variantFlagName        = "variant"
+		dirFlagName            = "dir"
+		outputFlagName         = "output"
+		expansionFlagName      = "expansion"
+		expansionsFileFlagName = "expansions_file"
+		revisionFlagName       = "revision"
+	)
+
+	return cli.Command{
+		Name:  "run-local",
+		Usage: "run a task from a project configuration file on this machine, without an evergreen host or server",
+		Flags: mergeFlagSlices(addProjectFlag(), addPathFlag(
+			cli.StringFlag{
+				Name:  joinFlagNames(taskFlagName, "t"),
+				Usage: "name of the task to run",
+			},
+			cli.StringFlag{
+				Name:  joinFlagNames(variantFlagName, "v"),
+				Usage: "name of the build variant to run the task on",
+			},
+			cli.StringFlag{
+				Name:  joinFlagNames(dirFlagName, "d"),
+				Usage: "working directory for the task. defaults to a new temporary directory",
+			},
+			cli.StringFlag{
+				Name:  joinFlagNames(outputFlagName, "o"),
+				Usage: "directory to write logs, test results and artifacts to",
+				Value: "evergreen-local",
+			},
+			cli.StringSliceFlag{
+				Name:  joinFlagNames(expansionFlagName, "e"),
+				Usage: "set an expansion as 'key=value'; may specify more than once",
+			},
+			cli.StringFlag{
+				Name:  expansionsFileFlagName,
+				Usage: "path to a yaml file of expansions, which take the place of project variables",
+			},
+			cli.StringFlag{
+				Name:  revisionFlagName,
+				Usage: "value of the ${revision} expansion",
+			},
+		)),
+		Before: mergeBeforeFuncs(
+			requirePathFlag,
+			requireStringFlag(taskFlagName),
+			requireStringFlag(variantFlagName),
+		),
+		Action: func(c *cli.Context) error {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			configPath := c.String(pathFlagName)
+			projectConfig, err := ioutil.ReadFile(configPath)
+			if err != nil {
+				return errors.Wrap(err, "problem reading project configuration")
+			}
+
+			expansions, err := getLocalExpansions(c.String(expansionsFileFlagName), c.StringSlice(expansionFlagName))
+			if err != nil {
+				return errors.WithStack(err)
+			}
+
+			opts := client.LocalOptions{
+				ProjectConfig:    projectConfig,
+				ReadIncludedFile: localProjectFileReader(configPath),
+				ProjectID:        c.String(projectFlagName),
+				TaskName:         c.String(taskFlagName),
+				BuildVariant:     c.String(variantFlagName),
+				Revision:         c.String(revisionFlagName),
+				WorkDir:          c.String(dirFlagName),
+				Expansions:       expansions,
+			}
+
+			if opts.OutputDir, err = filepath.Abs(c.String(outputFlagName)); err != nil {
+				return errors.Wrap(err, "problem resolving output directory")
+			}
+
+			if opts.WorkDir == "" {
+				if opts.WorkDir, err = ioutil.TempDir("", "evergreen-local"); err != nil {
+					return errors.Wrap(err, "problem creating working directory")
+				}
+				defer os.RemoveAll(opts.WorkDir)
+			} else if err = os.MkdirAll(opts.WorkDir, 0777); err != nil {
+				return errors.Wrapf(err, "problem creating working directory '%s'", opts.WorkDir)
+			}
+
+			// fill in the defaults before they're used to check the
+			// task and configure the agent
+			if err = opts.Validate(); err != nil {
+				return errors.Wrap(err, "invalid local options")
+			}
+
+			if err = checkLocalTask(opts); err != nil {
+				return errors.WithStack(err)
+			}
+
+			comm, err := client.NewLocal(opts)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			defer comm.Close()
+
+			grip.Info(message.Fields{
+				"message":  "running task locally",
+				"task":     opts.TaskName,
+				"variant":  opts.BuildVariant,
+				"dir":      opts.WorkDir,
+				"output":   opts.OutputDir,
+				"commands": command.RegisteredCommandNames(),
+			})
+
+			agt := agent.New(agent.Options{
+				HostID:           opts.DistroID,
+				WorkingDirectory: opts.WorkDir,
+			}, comm)
+
+			if err = agt.RunTask(ctx, comm.TaskData()); err != nil {
+				return errors.Wrap(err, "problem running task")
+			}
+
+			detail := comm.GetEndTaskDetail()
+			if detail == nil {
+				return errors.New("task did not report a final status")
+			}
+
+			fmt.Printf("Task '%s' finished with status '%s'; output is in '%s'\n",
+				opts.TaskName, detail.Status, opts.OutputDir)
+
+			if detail.Status != evergreen.TaskSucceeded {
+				return errors.Errorf("task failed in '%s' (%s)", detail.Description, detail.Type)
+			}
+
+			return nil
+		},
+	}
+}
+
+// checkLocalTask verifies that the requested task runs on the
+// requested variant before handing the project to the agent, so that
+// typos produce a useful error rather than a failed task.
+func checkLocalTask(opts client.LocalOptions) error {
+	project, err := opts.LoadProject()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if project.FindProjectTask(opts.TaskName) == nil {
+		return errors.Errorf("task '%s' is not defined in the project", opts.TaskName)
+	}
+
+	bv := project.FindBuildVariant(opts.BuildVariant)
+	if bv == nil {
+		return errors.Errorf("build variant '%s' is not defined in the project", opts.BuildVariant)
+	}
+
+	for _, t := range bv.Tasks {
+		if t.Name == opts.TaskName {
+			return nil
+		}
+	}
+
+	return errors.Errorf("task '%s' does not run on build variant '%s'", opts.TaskName, opts.BuildVariant)
+}
+
+func getLocalExpansions(fn string, pairs []string) (map[string]string, error) {
+	expansions := map[string]string{}
+
+	if fn != "" {
+		data, err := ioutil.ReadFile(fn)
+		if err != nil {
+			return nil, errors.Wrapf(err, "problem reading expansions file '%s'", fn)
+		}
+		if err = yaml.Unmarshal(data, &expansions); err != nil {
+			return nil, errors.Wrapf(err, "problem parsing expansions file '%s'", fn)
+		}
+	}
+
+	for _, pair := range pairs {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, errors.Errorf("expansion '%s' is not of the form 'key=value'", pair)
+		}
+		expansions[kv[0]] = kv[1]
+	}
+
+	return expansions, nil
+}
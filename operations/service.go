@@ -11,6 +11,7 @@ func Service() cli.Command {
 			startRunnerService(),
 			startWebService(),
 			handcrankRunner(),
+			simulateScheduler(),
 		},
 	}
 }
@@ -0,0 +1,207 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/scheduler"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mongodb/grip"
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/send"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+func simulateScheduler() cli.Command {
+	return cli.Command{
+		Name:    "simulate-scheduler",
+		Aliases: []string{"scheduler-sim"},
+		Usage:   "replay the scheduler and host allocators against a snapshot of the task queues",
+		Subcommands: []cli.Command{
+			schedulerSnapshot(),
+			schedulerReplay(),
+		},
+	}
+}
+
+func schedulerSnapshot() cli.Command {
+	return cli.Command{
+		Name:   "snapshot",
+		Usage:  "write the current task queues, hosts and expected task durations to a file",
+		Flags:  serviceConfigFlags(addOutputPath()...),
+		Before: mergeBeforeFuncs(requireStringFlag(pathFlagName), requireFileExists(confFlagName)),
+		Action: func(c *cli.Context) error {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			env := evergreen.GetEnvironment()
+			if err := env.Configure(ctx, c.String(confFlagName)); err != nil {
+				return errors.Wrap(err, "problem configuring application environment")
+			}
+
+			snapshot, err := scheduler.TakeSimulationSnapshot()
+			if err != nil {
+				return errors.Wrap(err, "problem taking scheduler snapshot")
+			}
+
+			return errors.Wrap(util.WriteJSONInto(c.String(pathFlagName), snapshot),
+				"problem writing scheduler snapshot")
+		},
+	}
+}
+
+func schedulerReplay() cli.Command {
+	const (
+		allocatorFlagName   = "allocator"
+		distrosFlagName     = "distros"
+		poolSizeFlagName    = "pool-size"
+		intervalFlagName    = "interval"
+		startupFlagName     = "host-startup"
+		idleTimeoutFlagName = "idle-timeout"
+		maxDurationFlagName = "max-duration"
+	)
+
+	return cli.Command{
+		Name:  "replay",
+		Usage: "replay a scheduler snapshot and report wait times, host hours and makespan",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  joinFlagNames(pathFlagName, "filename", "file", "f"),
+				Usage: "path to a scheduler snapshot",
+			},
+			cli.StringSliceFlag{
+				Name: joinFlagNames(allocatorFlagName, "a"),
+				Usage: fmt.Sprintf("host allocator to replay (%s); specify more than once to compare, defaults to all",
+					strings.Join(scheduler.SimulationAllocators(), ", ")),
+			},
+			cli.StringFlag{
+				Name:  distrosFlagName,
+				Usage: "path to a JSON file of proposed distro configurations, replacing the snapshot's distros with the same id",
+			},
+			cli.StringSliceFlag{
+				Name:  poolSizeFlagName,
+				Usage: "override the pool size of a distro, as <distro>=<size>; specify more than once for multiple distros",
+			},
+			cli.DurationFlag{
+				Name:  intervalFlagName,
+				Usage: "how often the scheduler runs",
+				Value: 20 * time.Second,
+			},
+			cli.DurationFlag{
+				Name:  startupFlagName,
+				Usage: "how long new hosts take to start running tasks",
+				Value: 5 * time.Minute,
+			},
+			cli.DurationFlag{
+				Name:  idleTimeoutFlagName,
+				Usage: "how long dynamic hosts may be idle before they are terminated",
+				Value: 7 * time.Minute,
+			},
+			cli.DurationFlag{
+				Name:  maxDurationFlagName,
+				Usage: "stop the simulation after this much simulated time",
+				Value: 7 * 24 * time.Hour,
+			},
+		},
+		Before: mergeBeforeFuncs(requireStringFlag(pathFlagName), requireFileExists(pathFlagName)),
+		Action: func(c *cli.Context) error {
+			snapshot := &scheduler.SimulationSnapshot{}
+			if err := readJSONFile(c.String(pathFlagName), snapshot); err != nil {
+				return errors.Wrap(err, "problem reading scheduler snapshot")
+			}
+
+			opts := scheduler.SimulationOptions{
+				Interval:        c.Duration(intervalFlagName),
+				HostStartupTime: c.Duration(startupFlagName),
+				IdleTimeout:     c.Duration(idleTimeoutFlagName),
+				MaxDuration:     c.Duration(maxDurationFlagName),
+				PoolSizes:       map[string]int{},
+			}
+
+			if fn := c.String(distrosFlagName); fn != "" {
+				if err := readJSONFile(fn, &opts.Distros); err != nil {
+					return errors.Wrap(err, "problem reading proposed distros")
+				}
+			}
+
+			for _, override := range c.StringSlice(poolSizeFlagName) {
+				parts := strings.SplitN(override, "=", 2)
+				if len(parts) != 2 {
+					return errors.Errorf("pool size '%s' is not of the form <distro>=<size>", override)
+				}
+				size, err := strconv.Atoi(parts[1])
+				if err != nil {
+					return errors.Wrapf(err, "invalid pool size for distro '%s'", parts[0])
+				}
+				opts.PoolSizes[parts[0]] = size
+			}
+
+			allocators := c.StringSlice(allocatorFlagName)
+			if len(allocators) == 0 {
+				allocators = scheduler.SimulationAllocators()
+			}
+
+			// the allocators log every scheduling pass, which would
+			// drown out the report
+			grip.CatchWarning(grip.GetSender().SetLevel(send.LevelInfo{
+				Default:   level.Info,
+				Threshold: level.Warning,
+			}))
+
+			reports := []*scheduler.SimulationReport{}
+			for _, allocator := range allocators {
+				opts.Allocator = allocator
+				report, err := scheduler.Simulate(snapshot, opts)
+				if err != nil {
+					return errors.Wrapf(err, "problem simulating the '%s' allocator", allocator)
+				}
+				reports = append(reports, report)
+			}
+
+			return errors.WithStack(printSimulationReports(reports))
+		},
+	}
+}
+
+func readJSONFile(fn string, data interface{}) error {
+	file, err := os.Open(fn)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(util.ReadJSONInto(file, data))
+}
+
+func printSimulationReports(reports []*scheduler.SimulationReport) error {
+	w := new(tabwriter.Writer)
+	// Format in tab-separated columns with a tab stop of 8.
+	w.Init(os.Stdout, 0, 8, 1, '\t', 0)
+
+	fmt.Fprintln(w, "allocator\tdistro\ttasks run\tnot run\tmean wait\tmedian wait\tp90 wait\tmax wait\thost hours\tpeak hosts\tmakespan")
+	for _, report := range reports {
+		rows := append([]scheduler.DistroSimulationReport{}, report.Distros...)
+		for _, r := range append(rows, report.Total) {
+			name := r.Distro
+			if name == "" {
+				name = "(total)"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\t%s\t%s\t%s\t%.1f\t%d\t%s\n",
+				report.Allocator, name, r.TasksRun, r.TasksNotRun,
+				roundDuration(r.MeanWait), roundDuration(r.MedianWait),
+				roundDuration(r.P90Wait), roundDuration(r.MaxWait),
+				r.HostHours, r.PeakHosts, roundDuration(r.Makespan))
+		}
+	}
+
+	return w.Flush()
+}
+
+func roundDuration(d time.Duration) time.Duration {
+	return d - d%time.Second
+}
@@ -0,0 +1,102 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/evergreen-ci/evergreen/rest/client"
+	"github.com/evergreen-ci/evergreen/rest/model"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+func Task() cli.Command {
+	return cli.Command{
+		Name:  "task",
+		Usage: "inspect evergreen tasks",
+		Subcommands: []cli.Command{
+			taskLogs(),
+		},
+	}
+}
+
+func taskLogs() cli.Command {
+	const (
+		taskFlagName      = "task"
+		followFlagName    = "follow"
+		typeFlagName      = "type"
+		severityFlagName  = "severity"
+		executionFlagName = "execution"
+	)
+
+	return cli.Command{
+		Name:  "logs",
+		Usage: "print a task's log",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  joinFlagNames(taskFlagName, "t"),
+				Usage: "the id of the task",
+			},
+			cli.BoolFlag{
+				Name:  joinFlagNames(followFlagName, "f"),
+				Usage: "keep printing the log as it is written, until the task finishes",
+			},
+			cli.StringSliceFlag{
+				Name:  typeFlagName,
+				Usage: "only print lines of the type (task, agent or system), may be specified more than once",
+			},
+			cli.StringSliceFlag{
+				Name:  severityFlagName,
+				Usage: "only print lines of the severity (error, warning, info or debug), may be specified more than once",
+			},
+			cli.IntFlag{
+				Name:  executionFlagName,
+				Usage: "print the log of the execution, instead of the latest one",
+				Value: -1,
+			},
+		},
+		Before: mergeBeforeFuncs(setPlainLogger, requireStringFlag(taskFlagName)),
+		Action: func(c *cli.Context) error {
+			confPath := c.Parent().Parent().String(confFlagName)
+			opts := client.TaskLogOptions{
+				TaskID:     c.String(taskFlagName),
+				Execution:  c.Int(executionFlagName),
+				Types:      c.StringSlice(typeFlagName),
+				Severities: c.StringSlice(severityFlagName),
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			conf, err := NewClientSetttings(confPath)
+			if err != nil {
+				return errors.Wrap(err, "problem loading configuration")
+			}
+			comm := conf.GetRestCommunicator(ctx)
+			defer comm.Close()
+
+			if c.Bool(followFlagName) {
+				err = comm.FollowTaskLogs(ctx, opts, printLogMessage)
+				return errors.Wrap(err, "problem following task log")
+			}
+
+			msgs, err := comm.GetTaskLogs(ctx, opts)
+			if err != nil {
+				return errors.Wrap(err, "problem fetching task log")
+			}
+			for _, msg := range msgs {
+				if err = printLogMessage(msg); err != nil {
+					return errors.WithStack(err)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func printLogMessage(msg model.APILogMessage) error {
+	_, err := fmt.Printf("[%s] [%s] %s\n", time.Time(msg.Timestamp).Format("2006/01/02 15:04:05.000"),
+		msg.Severity, msg.Message)
+	return err
+}
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"io/ioutil"
 
+	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/validator"
 	"github.com/pkg/errors"
 	"github.com/urfave/cli"
+	"gopkg.in/yaml.v2"
 )
 
 func Validate() cli.Command {
@@ -41,6 +43,17 @@ func Validate() cli.Command {
 			if err != nil {
 				return err
 			}
+			// included files can only be read here, so the merged
+			// project is validated instead
+			if model.ProjectHasIncludes(confFile) {
+				project, err := loadLocalConfig(path)
+				if err != nil {
+					return errors.WithStack(err)
+				}
+				if confFile, err = yaml.Marshal(project); err != nil {
+					return errors.Wrap(err, "problem marshaling merged project")
+				}
+			}
 
 			projErrors, err := ac.ValidateLocalConfig(confFile)
 			if err != nil {
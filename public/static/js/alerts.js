@@ -12,6 +12,14 @@ function NewAlert(recipient){
 	issue: args[2],
       },
     }
+  } else if (recipient.startsWith("WEBHOOK:")) {
+    // webhook alerts are denoted with "WEBHOOK:url" format
+    return {
+      provider: "webhook",
+      settings: {
+        url: recipient.substring("WEBHOOK:".length),
+      },
+    }
   } else if (recipient.startsWith("SLACK:")) {
     var args = recipient.split(":")
       return {
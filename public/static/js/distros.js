@@ -22,6 +22,9 @@ mciModule.controller('DistrosCtrl', function($scope, $window, $location, mciDist
   }, {
     'id': 'docker',
     'display': 'Docker'
+  }, {
+    'id': 'kubernetes',
+    'display': 'Kubernetes (Pod)'
   }, {
     'id': 'openstack',
     'display': 'OpenStack'
@@ -314,10 +317,16 @@ mciModule.controller('DistrosCtrl', function($scope, $window, $location, mciDist
         'setup': $scope.activeDistro.setup,
         'pool_size': $scope.activeDistro.pool_size,
         'setup_as_sudo' : $scope.activeDistro.setup_as_sudo,
+        'bootstrap_method': $scope.activeDistro.bootstrap_method,
+        'hourly_cost': $scope.activeDistro.hourly_cost,
 
       }
       newDistro.settings = _.clone($scope.activeDistro.settings);
       newDistro.expansions = _.clone($scope.activeDistro.expansions);
+      newDistro.capabilities = _.clone($scope.activeDistro.capabilities);
+      if (newDistro.capabilities && newDistro.capabilities.tags) {
+        newDistro.capabilities.tags = newDistro.capabilities.tags.slice();
+      }
 
       $scope.distros.unshift(newDistro);
       $scope.hasNew = true;
@@ -191,12 +191,15 @@ mciModule.controller('ProjectCtrl', function($scope, $window, $http, $location)
           branch_name: $scope.projectRef.branch_name,
           owner_name: $scope.projectRef.owner_name,
           repo_name: $scope.projectRef.repo_name,
+          repo_kind: $scope.projectRef.repo_kind || "github",
+          repo_url: $scope.projectRef.repo_url,
           enabled: $scope.projectRef.enabled,
           private: $scope.projectRef.private,
           alert_config: $scope.projectRef.alert_config || {},
           repotracker_error: $scope.projectRef.repotracker_error || {},
           admins : $scope.projectRef.admins || [],
           setup_github_hook: $scope.githubHookId != 0,
+          commit_queue: $scope.projectRef.commit_queue || {},
         };
 
         $scope.displayName = $scope.projectRef.display_name ? $scope.projectRef.display_name : $scope.projectRef.identifier;
@@ -360,6 +363,9 @@ mciModule.controller('ProjectCtrl', function($scope, $window, $http, $location)
     if (alertObj.provider=='slack'){
       return "Send a slack message to "+alertObj.settings.channel
     }
+    if (alertObj.provider=='webhook'){
+      return "Post to the webhook at "+alertObj.settings.url
+    }
     return 'unknown'
   }
 
@@ -0,0 +1,160 @@
+package repotracker
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"github.com/pkg/errors"
+)
+
+// GitRepositoryPoller is a RepoPoller that tracks any https or ssh git
+// remote, such as a self-hosted git server, through a local mirror of the
+// project's branch rather than through a hosting API.
+type GitRepositoryPoller struct {
+	ProjectRef *model.ProjectRef
+	mirror     *thirdparty.GitMirror
+}
+
+// NewGitRepositoryPoller constructs and returns a pointer to a
+// GitRepositoryPoller that keeps its mirror of the project under
+// mirrorDir.
+func NewGitRepositoryPoller(projectRef *model.ProjectRef, mirrorDir string) *GitRepositoryPoller {
+	return &GitRepositoryPoller{
+		ProjectRef: projectRef,
+		mirror: thirdparty.NewGitMirror(projectRef.RepoURL, projectRef.Branch,
+			filepath.Join(mirrorDir, projectRef.Identifier+".git")),
+	}
+}
+
+// syncFor fetches from the remote only if the mirror does not have the
+// revision yet, since the revisions being stored were usually just
+// fetched by GetRevisionsSince.
+func (p *GitRepositoryPoller) syncFor(revision string) error {
+	if p.mirror.HasCommit(revision) {
+		return nil
+	}
+	return errors.WithStack(p.mirror.Sync())
+}
+
+// gitCommitToRevision converts a GitCommit struct to a model.Revision
+// struct
+func gitCommitToRevision(commit thirdparty.GitCommit) model.Revision {
+	return model.Revision{
+		Author:          commit.AuthorName,
+		AuthorEmail:     commit.AuthorEmail,
+		RevisionMessage: commit.Message,
+		Revision:        commit.SHA,
+		CreateTime:      time.Now(),
+	}
+}
+
+// GetRemoteConfig reads the project's configuration file as at the given
+// revision from the mirror.
+func (p *GitRepositoryPoller) GetRemoteConfig(revision string) (*model.Project, error) {
+	if err := p.syncFor(revision); err != nil {
+		return nil, err
+	}
+
+	data, err := p.mirror.ReadFile(revision, p.ProjectRef.RemotePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// included files are read as at the same revision
+	read := func(module *model.Module, fileName string) ([]byte, error) {
+		if module != nil {
+			return model.ReadModuleFile(module, fileName, "")
+		}
+		return p.mirror.ReadFile(revision, fileName)
+	}
+
+	projectConfig := &model.Project{}
+	if err = model.LoadProjectWithIncludes(data, p.ProjectRef.Identifier, read, projectConfig); err != nil {
+		return nil, thirdparty.YAMLFormatError{Message: err.Error()}
+	}
+	return projectConfig, nil
+}
+
+// GetChangedFiles returns the paths of the files modified by the given
+// revision.
+func (p *GitRepositoryPoller) GetChangedFiles(revision string) ([]string, error) {
+	if err := p.syncFor(revision); err != nil {
+		return nil, err
+	}
+	return p.mirror.ChangedFiles(revision)
+}
+
+// GetRevisionsSince returns every commit on the project's branch made
+// after 'revision'. Since the mirror holds the branch's whole history
+// there is no need to search for the revision, so maxRevisionsToSearch is
+// not used.
+func (p *GitRepositoryPoller) GetRevisionsSince(revision string, maxRevisionsToSearch int) ([]model.Revision, error) {
+	if err := p.mirror.Sync(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if !p.mirror.HasCommit(revision) || !p.mirror.OnBranch(revision) {
+		return []model.Revision{}, p.recordMissingRevision(revision)
+	}
+
+	commits, err := p.mirror.Log(revision, 0)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	revisions := make([]model.Revision, 0, len(commits))
+	for _, commit := range commits {
+		revisions = append(revisions, gitCommitToRevision(commit))
+	}
+	return revisions, nil
+}
+
+// GetRecentRevisions returns the most recent 'maxRevisions' commits on
+// the project's branch.
+func (p *GitRepositoryPoller) GetRecentRevisions(maxRevisions int) ([]model.Revision, error) {
+	if err := p.mirror.Sync(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	commits, err := p.mirror.Log("", maxRevisions)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	revisions := make([]model.Revision, 0, len(commits))
+	for _, commit := range commits {
+		revisions = append(revisions, gitCommitToRevision(commit))
+	}
+	return revisions, nil
+}
+
+// recordMissingRevision marks the project ref as inconsistent when the
+// last tracked revision is no longer on the branch, e.g. after a force
+// push, suggesting the commit the revision was based on when the mirror
+// still has it.
+func (p *GitRepositoryPoller) recordMissingRevision(revision string) error {
+	if len(revision) < 10 {
+		return errors.Errorf("invalid revision: %v", revision)
+	}
+
+	var revisionError error
+	details := &model.RepositoryErrorDetails{
+		Exists:          true,
+		InvalidRevision: revision[:10],
+	}
+	baseRevision, err := p.mirror.MergeBase(revision)
+	if err != nil {
+		revisionError = errors.Errorf("revision %v is not on branch %v and no suggested merge base commit was found, must fix on projects settings page",
+			revision, p.ProjectRef.Branch)
+	} else {
+		details.MergeBaseRevision = baseRevision
+		revisionError = errors.Errorf("base revision, %v not found, suggested base revision, %v found, must confirm on project settings page",
+			revision, baseRevision)
+	}
+
+	p.ProjectRef.RepotrackerError = details
+	if err = p.ProjectRef.Upsert(); err != nil {
+		return errors.Wrap(err, "unable to update projectRef revision details")
+	}
+	return revisionError
+}
@@ -0,0 +1,177 @@
+package repotracker
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"github.com/stretchr/testify/suite"
+)
+
+const gitPollerTestConfig = `
+tasks:
+- name: compile
+buildvariants:
+- name: linux
+  run_on: [ubuntu]
+  tasks:
+  - name: compile
+`
+
+type GitPollerSuite struct {
+	dir    string
+	remote string
+	ref    *model.ProjectRef
+	poller *GitRepositoryPoller
+	suite.Suite
+}
+
+func TestGitPollerSuite(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	suite.Run(t, new(GitPollerSuite))
+}
+
+func (s *GitPollerSuite) SetupTest() {
+	var err error
+	s.dir, err = ioutil.TempDir("", "git-poller-test")
+	s.Require().NoError(err)
+	s.remote = filepath.Join(s.dir, "remote")
+	s.Require().NoError(os.MkdirAll(s.remote, 0755))
+	s.git("init", "--quiet")
+	s.git("checkout", "--quiet", "-b", "master")
+
+	s.ref = &model.ProjectRef{
+		Identifier: "git-project",
+		RepoKind:   model.GitRepoType,
+		RepoURL:    "file://" + s.remote,
+		Branch:     "master",
+		RemotePath: "evergreen.yml",
+		Enabled:    true,
+	}
+	s.poller = NewGitRepositoryPoller(s.ref, filepath.Join(s.dir, "mirrors"))
+}
+
+func (s *GitPollerSuite) TearDownTest() {
+	s.NoError(os.RemoveAll(s.dir))
+}
+
+func (s *GitPollerSuite) git(args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = s.remote
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Bob", "GIT_AUTHOR_EMAIL=bob@example.com",
+		"GIT_COMMITTER_NAME=Bob", "GIT_COMMITTER_EMAIL=bob@example.com")
+	out, err := cmd.CombinedOutput()
+	s.Require().NoError(err, string(out))
+	return strings.TrimSpace(string(out))
+}
+
+func (s *GitPollerSuite) commit(message, file, contents string) string {
+	s.Require().NoError(ioutil.WriteFile(filepath.Join(s.remote, file), []byte(contents), 0644))
+	s.git("add", file)
+	s.git("commit", "--quiet", "-m", message)
+	return s.git("rev-parse", "HEAD")
+}
+
+func (s *GitPollerSuite) TestGetRecentRevisions() {
+	first := s.commit("first", "evergreen.yml", gitPollerTestConfig)
+	second := s.commit("second", "main.go", "package main")
+
+	revisions, err := s.poller.GetRecentRevisions(10)
+	s.Require().NoError(err)
+	s.Require().Len(revisions, 2)
+	s.Equal(second, revisions[0].Revision)
+	s.Equal("second", revisions[0].RevisionMessage)
+	s.Equal("Bob", revisions[0].Author)
+	s.Equal("bob@example.com", revisions[0].AuthorEmail)
+	s.Equal(first, revisions[1].Revision)
+
+	revisions, err = s.poller.GetRecentRevisions(1)
+	s.NoError(err)
+	s.Len(revisions, 1)
+}
+
+func (s *GitPollerSuite) TestGetRevisionsSince() {
+	first := s.commit("first", "evergreen.yml", gitPollerTestConfig)
+	second := s.commit("second", "main.go", "package main")
+	third := s.commit("third", "main.go", "package main\n")
+
+	revisions, err := s.poller.GetRevisionsSince(first, 1)
+	s.Require().NoError(err)
+	s.Require().Len(revisions, 2)
+	s.Equal(third, revisions[0].Revision)
+	s.Equal(second, revisions[1].Revision)
+
+	revisions, err = s.poller.GetRevisionsSince(third, 10)
+	s.NoError(err)
+	s.Len(revisions, 0)
+
+	_, err = s.poller.GetRevisionsSince("abc", 10)
+	s.Error(err)
+}
+
+func (s *GitPollerSuite) TestGetRevisionsSinceRewrittenHistory() {
+	s.Require().NoError(db.Clear(model.ProjectRefCollection))
+	s.Require().NoError(s.ref.Insert())
+
+	base := s.commit("base", "evergreen.yml", gitPollerTestConfig)
+	dropped := s.commit("dropped", "main.go", "package main")
+	_, err := s.poller.GetRecentRevisions(10)
+	s.Require().NoError(err)
+
+	s.git("reset", "--quiet", "--hard", base)
+	s.commit("replacement", "main.go", "package main\n")
+
+	revisions, err := s.poller.GetRevisionsSince(dropped, 10)
+	s.Error(err)
+	s.Len(revisions, 0)
+
+	ref, err := model.FindOneProjectRef(s.ref.Identifier)
+	s.Require().NoError(err)
+	s.Require().NotNil(ref.RepotrackerError)
+	s.True(ref.RepotrackerError.Exists)
+	s.Equal(dropped[:10], ref.RepotrackerError.InvalidRevision)
+	s.Equal(base, ref.RepotrackerError.MergeBaseRevision)
+}
+
+func (s *GitPollerSuite) TestGetChangedFiles() {
+	s.commit("first", "evergreen.yml", gitPollerTestConfig)
+	second := s.commit("second", "main.go", "package main")
+
+	files, err := s.poller.GetChangedFiles(second)
+	s.NoError(err)
+	s.Equal([]string{"main.go"}, files)
+}
+
+func (s *GitPollerSuite) TestGetRemoteConfig() {
+	first := s.commit("first", "main.go", "package main")
+	second := s.commit("second", "evergreen.yml", gitPollerTestConfig)
+	third := s.commit("third", "evergreen.yml", "tasks: [")
+
+	_, err := s.poller.GetRemoteConfig(first)
+	s.IsType(thirdparty.FileNotFoundError{}, err)
+
+	project, err := s.poller.GetRemoteConfig(second)
+	s.Require().NoError(err)
+	s.Equal(s.ref.Identifier, project.Identifier)
+	s.Len(project.Tasks, 1)
+	s.Len(project.BuildVariants, 1)
+
+	_, err = s.poller.GetRemoteConfig(third)
+	s.IsType(thirdparty.YAMLFormatError{}, err)
+}
+
+func (s *GitPollerSuite) TestNewRepoPoller() {
+	conf := &evergreen.Settings{}
+	s.IsType(&GitRepositoryPoller{}, NewRepoPoller(conf, s.ref))
+	s.IsType(&GithubRepositoryPoller{}, NewRepoPoller(conf, &model.ProjectRef{RepoKind: model.GithubRepoType}))
+}
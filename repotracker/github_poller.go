@@ -82,8 +82,17 @@ func (gRepoPoller *GithubRepositoryPoller) GetRemoteConfig(
 		return nil, thirdparty.FileDecodeError{err.Error()}
 	}
 
+	// included files are read as at the same revision
+	read := func(module *model.Module, fileName string) ([]byte, error) {
+		if module != nil {
+			return model.ReadModuleFile(module, fileName, gRepoPoller.OauthToken)
+		}
+		return thirdparty.GetGithubFileContents(gRepoPoller.OauthToken, projectRef.Owner,
+			projectRef.Repo, fileName, projectFileRevision)
+	}
+
 	projectConfig = &model.Project{}
-	err = model.LoadProjectInto(projectFileBytes, projectRef.Identifier, projectConfig)
+	err = model.LoadProjectWithIncludes(projectFileBytes, projectRef.Identifier, read, projectConfig)
 	if err != nil {
 		return nil, thirdparty.YAMLFormatError{err.Error()}
 	}
@@ -0,0 +1,219 @@
+package repotracker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/version"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mongodb/grip"
+	"github.com/mongodb/grip/message"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// CreatePeriodicVersions creates a version of the most recent revision of
+// the project's branch for each of the project's periodic builds that is
+// due at the given time. Cron specifications are evaluated in UTC. A
+// periodic build's schedule starts the first time it's seen, and runs
+// missed while the repotracker wasn't running are collapsed into one. Runs
+// of periodic builds whose alias doesn't match any tasks are skipped.
+func (repoTracker *RepoTracker) CreatePeriodicVersions(now time.Time) error {
+	ref := repoTracker.ProjectRef
+	now = now.UTC()
+
+	repository, err := model.FindRepository(ref.Identifier)
+	if err != nil {
+		return errors.Wrapf(err, "error finding repository '%s'", ref.Identifier)
+	}
+	if repository == nil || repository.LastRevision == "" {
+		return nil
+	}
+
+	head, err := version.FindOne(version.ByProjectIdAndRevision(ref.Identifier, repository.LastRevision))
+	if err != nil {
+		return errors.Wrapf(err, "error finding version for revision '%s'", repository.LastRevision)
+	}
+	if head == nil {
+		return nil
+	}
+	if len(head.Errors) > 0 || head.Config == "" {
+		grip.Warning(message.Fields{
+			"message": "skipping periodic builds because the most recent version has no valid config",
+			"runner":  RunnerName,
+			"project": ref.Identifier,
+			"version": head.Id,
+		})
+		return nil
+	}
+
+	project := &model.Project{}
+	if err = model.LoadProjectInto([]byte(head.Config), ref.Identifier, project); err != nil {
+		return errors.Wrapf(err, "error loading config for version '%s'", head.Id)
+	}
+
+	catcher := grip.NewBasicCatcher()
+	for _, def := range project.PeriodicBuilds {
+		if def.ID == "" {
+			continue
+		}
+		lastRun, ok := repository.PeriodicBuilds[def.ID]
+		if !ok {
+			catcher.Add(model.UpdateLastPeriodicBuild(ref.Identifier, def.ID, now))
+			continue
+		}
+
+		schedule, err := util.ParseCron(def.Cron)
+		if err != nil {
+			catcher.Add(errors.Wrapf(err, "invalid cron specification for periodic build '%s'", def.ID))
+			continue
+		}
+		next := schedule.Next(lastRun.UTC())
+		if next.IsZero() || next.After(now) {
+			continue
+		}
+
+		tasks, err := getPeriodicBuildTasks(project, def)
+		if err != nil {
+			catcher.Add(errors.Wrapf(err, "error finding tasks for periodic build '%s'", def.ID))
+			continue
+		}
+		if len(tasks.ExecTasks) == 0 {
+			// the alias may be added later, so this isn't an error, but
+			// the run is recorded so it isn't retried on every pass
+			grip.Warning(message.Fields{
+				"message":        "skipping periodic build whose alias doesn't match any tasks",
+				"runner":         RunnerName,
+				"project":        ref.Identifier,
+				"periodic_build": def.ID,
+				"alias":          def.Alias,
+			})
+			catcher.Add(model.UpdateLastPeriodicBuild(ref.Identifier, def.ID, now))
+			continue
+		}
+
+		v, err := createPeriodicVersion(ref, project, head, tasks, def, now)
+		if err != nil {
+			catcher.Add(errors.Wrapf(err, "error creating version for periodic build '%s'", def.ID))
+			continue
+		}
+		grip.Info(message.Fields{
+			"message":        "created version for periodic build",
+			"runner":         RunnerName,
+			"project":        ref.Identifier,
+			"periodic_build": def.ID,
+			"revision":       head.Revision,
+			"version":        v.Id,
+			"scheduled":      next,
+		})
+		catcher.Add(model.UpdateLastPeriodicBuild(ref.Identifier, def.ID, now))
+	}
+
+	return catcher.Resolve()
+}
+
+// createPeriodicVersion creates and activates a version of the base version's
+// revision, running the tasks selected by the periodic build.
+func createPeriodicVersion(ref *model.ProjectRef, project *model.Project, base *version.Version,
+	tasks model.TaskVariantPairs, def model.PeriodicBuildDefinition, now time.Time) (*version.Version, error) {
+	msg := def.Message
+	if msg == "" {
+		msg = fmt.Sprintf("periodic build '%s'", def.ID)
+	}
+	v := &version.Version{
+		Id:                  bson.NewObjectId().Hex(),
+		Branch:              ref.Branch,
+		CreateTime:          now,
+		Identifier:          ref.Identifier,
+		Message:             msg,
+		Owner:               ref.Owner,
+		RemotePath:          ref.RemotePath,
+		Repo:                ref.Repo,
+		RepoKind:            ref.RepoKind,
+		Requester:           evergreen.AdHocVersionRequester,
+		Revision:            base.Revision,
+		Status:              evergreen.VersionCreated,
+		RevisionOrderNumber: base.RevisionOrderNumber,
+		Config:              base.Config,
+	}
+
+	taskIds := model.NewPatchTaskIdTable(project, v, tasks)
+	for _, bv := range project.BuildVariants {
+		taskNames := tasks.ExecTasks.TaskNames(bv.Name)
+		if len(taskNames) == 0 {
+			continue
+		}
+		buildId, err := model.CreateBuildFromVersion(project, v, taskIds, bv.Name, true,
+			taskNames, tasks.DisplayTasks.TaskNames(bv.Name))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		v.BuildIds = append(v.BuildIds, buildId)
+		v.BuildVariants = append(v.BuildVariants, version.BuildStatus{
+			BuildVariant: bv.Name,
+			Activated:    true,
+			ActivateAt:   now,
+			BuildId:      buildId,
+		})
+	}
+
+	if err := v.Insert(); err != nil {
+		for _, buildStatus := range v.BuildVariants {
+			if buildErr := model.DeleteBuild(buildStatus.BuildId); buildErr != nil {
+				grip.Error(message.WrapError(buildErr, message.Fields{
+					"runner":     RunnerName,
+					"message":    "issue deleting build",
+					"version_id": v.Id,
+					"build_id":   buildStatus.BuildId,
+				}))
+			}
+		}
+		return nil, errors.WithStack(err)
+	}
+	return v, nil
+}
+
+// getPeriodicBuildTasks returns the tasks matched by the periodic build's
+// alias, or all tasks if it has none, along with their dependencies and
+// the display tasks whose execution tasks all run.
+func getPeriodicBuildTasks(project *model.Project, def model.PeriodicBuildDefinition) (model.TaskVariantPairs, error) {
+	var pairs []model.TVPair
+	if def.Alias != "" {
+		var err error
+		pairs, err = project.BuildProjectTVPairsWithAlias(def.Alias)
+		if err != nil {
+			return model.TaskVariantPairs{}, errors.Wrapf(err, "error finding tasks for alias '%s'", def.Alias)
+		}
+	} else {
+		for _, bv := range project.BuildVariants {
+			for _, t := range bv.Tasks {
+				pairs = append(pairs, model.TVPair{Variant: bv.Name, TaskName: t.Name})
+			}
+		}
+	}
+	enabled := []model.TVPair{}
+	for _, pair := range pairs {
+		if bv := project.FindBuildVariant(pair.Variant); bv != nil && !bv.Disabled {
+			enabled = append(enabled, pair)
+		}
+	}
+
+	tasks := model.TaskVariantPairs{
+		ExecTasks: model.IncludePatchDependencies(project, enabled),
+	}
+	for _, bv := range project.BuildVariants {
+		taskNames := tasks.ExecTasks.TaskNames(bv.Name)
+		for _, dt := range bv.DisplayTasks {
+			included := len(dt.ExecutionTasks) > 0
+			for _, et := range dt.ExecutionTasks {
+				included = included && util.StringSliceContains(taskNames, et)
+			}
+			if included {
+				tasks.DisplayTasks = append(tasks.DisplayTasks, model.TVPair{Variant: bv.Name, TaskName: dt.Name})
+			}
+		}
+	}
+	return tasks, nil
+}
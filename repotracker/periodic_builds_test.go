@@ -0,0 +1,175 @@
+package repotracker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/build"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/model/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+const periodicBuildsConfig = `
+tasks:
+- name: compile
+- name: fuzz
+  depends_on:
+  - name: compile
+- name: lint
+buildvariants:
+- name: linux
+  display_name: Linux
+  run_on: [test-distro-one]
+  tasks: [compile, fuzz, lint]
+  display_tasks:
+  - name: checks
+    execution_tasks: [compile, fuzz]
+- name: windows
+  display_name: Windows
+  disabled: true
+  run_on: [test-distro-one]
+  tasks: [compile]
+periodic_builds:
+- cron: "0 3 * * *"
+  alias: nightly
+`
+
+func TestGetPeriodicBuildTasks(t *testing.T) {
+	assert := assert.New(t)
+
+	project := &model.Project{}
+	assert.NoError(model.LoadProjectInto([]byte(periodicBuildsConfig), "mci", project))
+
+	// without an alias everything on enabled variants runs
+	tasks, err := getPeriodicBuildTasks(project, model.PeriodicBuildDefinition{ID: "all"})
+	assert.NoError(err)
+	assert.Len(tasks.ExecTasks, 3)
+	assert.Empty(tasks.ExecTasks.TaskNames("windows"))
+	assert.Equal([]string{"checks"}, tasks.DisplayTasks.TaskNames("linux"))
+}
+
+type PeriodicBuildsSuite struct {
+	tracker *RepoTracker
+	head    *version.Version
+	suite.Suite
+}
+
+func TestPeriodicBuildsSuite(t *testing.T) {
+	suite.Run(t, new(PeriodicBuildsSuite))
+}
+
+func (s *PeriodicBuildsSuite) SetupTest() {
+	s.Require().NoError(db.ClearCollections(version.Collection, build.Collection, task.Collection,
+		model.RepositoriesCollection, model.ProjectVarsCollection))
+
+	ref := &model.ProjectRef{
+		Identifier: "mci",
+		Owner:      "evergreen-ci",
+		Repo:       "evergreen",
+		Branch:     "master",
+		RepoKind:   model.GithubRepoType,
+		Enabled:    true,
+	}
+	s.tracker = &RepoTracker{testConfig, ref, nil}
+
+	order, err := model.GetNewRevisionOrderNumber("mci")
+	s.Require().NoError(err)
+	s.Require().NoError(model.UpdateLastRevision("mci", "abc"))
+	s.head = &version.Version{
+		Id:                  "mci_abc",
+		Identifier:          "mci",
+		Revision:            "abc",
+		Requester:           evergreen.RepotrackerVersionRequester,
+		RevisionOrderNumber: order,
+		Config:              periodicBuildsConfig,
+	}
+	s.Require().NoError(s.head.Insert())
+
+	vars := &model.ProjectVars{
+		Id: "mci",
+		PatchDefinitions: []model.PatchDefinition{
+			{Alias: "nightly", Variant: "linux", Task: "fuzz"},
+		},
+	}
+	s.Require().NoError(vars.Insert())
+}
+
+func (s *PeriodicBuildsSuite) periodicVersions() []version.Version {
+	versions, err := version.Find(version.ByMostRecentForRequester("mci", evergreen.AdHocVersionRequester))
+	s.Require().NoError(err)
+	return versions
+}
+
+func (s *PeriodicBuildsSuite) TestScheduleStartsWhenFirstSeen() {
+	now := time.Date(2018, time.May, 16, 3, 0, 0, 0, time.UTC)
+	s.NoError(s.tracker.CreatePeriodicVersions(now))
+	s.Empty(s.periodicVersions())
+
+	repository, err := model.FindRepository("mci")
+	s.Require().NoError(err)
+	s.Require().Contains(repository.PeriodicBuilds, "nightly")
+	s.True(now.Equal(repository.PeriodicBuilds["nightly"]))
+
+	// not yet due
+	s.NoError(s.tracker.CreatePeriodicVersions(now.Add(23 * time.Hour)))
+	s.Empty(s.periodicVersions())
+}
+
+func (s *PeriodicBuildsSuite) TestCreatesVersionWhenDue() {
+	lastRun := time.Date(2018, time.May, 15, 3, 0, 0, 0, time.UTC)
+	s.Require().NoError(model.UpdateLastPeriodicBuild("mci", "nightly", lastRun))
+
+	now := lastRun.Add(24*time.Hour + time.Minute)
+	s.NoError(s.tracker.CreatePeriodicVersions(now))
+	versions := s.periodicVersions()
+	s.Require().Len(versions, 1)
+	v := versions[0]
+	s.Equal("abc", v.Revision)
+	s.Equal(s.head.RevisionOrderNumber, v.RevisionOrderNumber)
+	s.Require().Len(v.BuildVariants, 1)
+	s.Equal("linux", v.BuildVariants[0].BuildVariant)
+	s.True(v.BuildVariants[0].Activated)
+
+	// the alias's task runs along with its dependencies
+	tasks, err := task.Find(task.ByVersion(v.Id))
+	s.Require().NoError(err)
+	names := []string{}
+	for _, t := range tasks {
+		s.True(t.Activated)
+		s.Equal(evergreen.AdHocVersionRequester, t.Requester)
+		names = append(names, t.DisplayName)
+	}
+	s.Contains(names, "fuzz")
+	s.Contains(names, "compile")
+	s.Contains(names, "checks")
+	s.NotContains(names, "lint")
+
+	// the commit's version is unaffected
+	mainline, err := version.FindOne(version.ByProjectIdAndRevision("mci", "abc"))
+	s.Require().NoError(err)
+	s.Equal(s.head.Id, mainline.Id)
+
+	// it only runs once per scheduled time
+	s.NoError(s.tracker.CreatePeriodicVersions(now.Add(time.Hour)))
+	s.Len(s.periodicVersions(), 1)
+}
+
+func (s *PeriodicBuildsSuite) TestMissingAliasSkipsRun() {
+	s.Require().NoError(db.Clear(model.ProjectVarsCollection))
+	lastRun := time.Date(2018, time.May, 15, 3, 0, 0, 0, time.UTC)
+	s.Require().NoError(model.UpdateLastPeriodicBuild("mci", "nightly", lastRun))
+
+	now := lastRun.Add(48 * time.Hour)
+	s.NoError(s.tracker.CreatePeriodicVersions(now))
+	s.Empty(s.periodicVersions())
+
+	// the skipped run is recorded, so it isn't retried
+	repository, err := model.FindRepository("mci")
+	s.Require().NoError(err)
+	s.True(now.Equal(repository.PeriodicBuilds["nightly"]))
+}
@@ -25,6 +25,11 @@ const (
 	DefaultNumNewRepoRevisionsToFetch = 200
 	DefaultMaxRepoRevisionsToSearch   = 50
 	DefaultNumConcurrentRequests      = 10
+
+	// DefaultMirrorDirName is the directory in the system's temp
+	// directory that holds the mirrors of git projects, if no mirror
+	// directory is configured
+	DefaultMirrorDirName = "evergreen-repotracker"
 )
 
 // RepoTracker is used to manage polling repository changes and storing such
@@ -2,6 +2,8 @@ package repotracker
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -196,6 +198,18 @@ func runRepoTracker(config *evergreen.Settings) error {
 	return nil
 }
 
+// NewRepoPoller returns the poller for the project's repo kind.
+func NewRepoPoller(conf *evergreen.Settings, project *model.ProjectRef) RepoPoller {
+	if project.RepoKind == model.GitRepoType {
+		mirrorDir := conf.RepoTracker.MirrorDir
+		if mirrorDir == "" {
+			mirrorDir = filepath.Join(os.TempDir(), DefaultMirrorDirName)
+		}
+		return NewGitRepositoryPoller(project, mirrorDir)
+	}
+	return NewGithubRepositoryPoller(project, conf.Credentials["github"])
+}
+
 func repoTrackerWorker(conf *evergreen.Settings, num int, projects <-chan model.ProjectRef, id int, wg *sync.WaitGroup) {
 	grip.Debug(message.Fields{
 		"runner":  RunnerName,
@@ -219,7 +233,7 @@ func repoTrackerWorker(conf *evergreen.Settings, num int, projects <-chan model.
 		tracker := &RepoTracker{
 			conf,
 			&project,
-			NewGithubRepositoryPoller(&project, conf.Credentials["github"]),
+			NewRepoPoller(conf, &project),
 		}
 
 		if err := tracker.FetchRevisions(num); err != nil {
@@ -235,6 +249,19 @@ func repoTrackerWorker(conf *evergreen.Settings, num int, projects <-chan model.
 			continue
 		}
 
+		if err := tracker.CreatePeriodicVersions(time.Now()); err != nil {
+			errored = append(errored, project.String())
+			grip.Warning(message.Fields{
+				"project": project.Identifier,
+				"error":   err,
+				"message": "problem creating periodic versions",
+				"runner":  RunnerName,
+				"worker":  id,
+			})
+
+			continue
+		}
+
 		completed = append(completed, project.String())
 	}
 
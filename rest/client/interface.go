@@ -8,6 +8,7 @@ import (
 	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/admin"
 	"github.com/evergreen-ci/evergreen/model/artifact"
+	"github.com/evergreen-ci/evergreen/model/coverage"
 	"github.com/evergreen-ci/evergreen/model/distro"
 	"github.com/evergreen-ci/evergreen/model/manifest"
 	patchmodel "github.com/evergreen-ci/evergreen/model/patch"
@@ -69,6 +70,18 @@ type Communicator interface {
 	// GetNextTask returns a next task response by getting the next task for a given host.
 	GetNextTask(context.Context) (*apimodels.NextTaskResponse, error)
 
+	// Host bootstrap operations, used by hosts that set themselves up
+	// from their user data.
+	//
+	// RegisterHost exchanges the host's one-time token for its ID and secret.
+	RegisterHost(context.Context, string) (*apimodels.HostRegistrationResponse, error)
+	// AppendHostSetupLog sends output from the host's setup script.
+	AppendHostSetupLog(context.Context, string) error
+	// EndHostSetup reports whether the host's setup script succeeded.
+	EndHostSetup(context.Context, *apimodels.HostSetupResult) error
+	// StartHostAgent reports that the host is starting a new agent.
+	StartHostAgent(context.Context) error
+
 	// Constructs a new LogProducer instance for use by tasks.
 	GetLoggerProducer(context.Context, TaskData) LoggerProducer
 
@@ -81,6 +94,8 @@ type Communicator interface {
 	// used by task commands.
 	SendTestResults(context.Context, TaskData, *task.LocalTestResults) error
 	SendTestLog(context.Context, TaskData, *model.TestLog) (string, error)
+	SendCoverage(context.Context, TaskData, []coverage.FileCoverage) error
+	GenerateTasks(context.Context, TaskData, []string) error
 	GetTaskPatch(context.Context, TaskData) (*patchmodel.Patch, error)
 	GetPatchFile(context.Context, TaskData, string) (string, error)
 
@@ -140,4 +155,15 @@ type Communicator interface {
 
 	// List variant/task aliases
 	ListAliases(context.Context, string) ([]model.PatchDefinition, error)
+
+	// GetTaskLogs returns the lines of a task's log written so far, and
+	// FollowTaskLogs passes each line to the handler as it is written,
+	// until the task finishes.
+	GetTaskLogs(context.Context, TaskLogOptions) ([]restmodel.APILogMessage, error)
+	FollowTaskLogs(context.Context, TaskLogOptions, func(restmodel.APILogMessage) error) error
+
+	// Commit queue methods
+	GetCommitQueue(context.Context, string) (*restmodel.APICommitQueue, error)
+	EnqueueItem(context.Context, string, string) (int, error)
+	DeleteCommitQueueItem(context.Context, string, string) error
 }
@@ -0,0 +1,638 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/apimodels"
+	serviceModel "github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/admin"
+	"github.com/evergreen-ci/evergreen/model/artifact"
+	"github.com/evergreen-ci/evergreen/model/coverage"
+	"github.com/evergreen-ci/evergreen/model/distro"
+	"github.com/evergreen-ci/evergreen/model/manifest"
+	patchmodel "github.com/evergreen-ci/evergreen/model/patch"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/model/version"
+	"github.com/evergreen-ci/evergreen/rest/model"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mongodb/grip"
+	"github.com/mongodb/grip/logging"
+	"github.com/mongodb/grip/message"
+	"github.com/mongodb/grip/send"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// LocalTaskID and LocalVersionID are the identifiers used for
+	// the task and version documents that a Local communicator
+	// synthesizes.
+	LocalTaskID    = "local_task"
+	LocalVersionID = "local_version"
+
+	localLogDirectory      = "logs"
+	localTestLogDirectory  = "test_logs"
+	localJSONDirectory     = "json"
+	localTestResultsFile   = "test_results.json"
+	localCoverageFile      = "coverage.json"
+	localGeneratedFile     = "generated_tasks.json"
+	localArtifactsFile     = "artifacts.json"
+	localEndTaskDetailFile = "end_task.json"
+)
+
+// LocalOptions describes the task that a Local communicator serves to
+// the agent and where it writes the data that the agent reports.
+type LocalOptions struct {
+	// ProjectConfig holds the raw contents of the project's YAML file.
+	ProjectConfig []byte
+	// ReadIncludedFile reads the files that the project configuration
+	// includes. Configurations with includes can't be loaded without it.
+	ReadIncludedFile serviceModel.ProjectFileReader
+	ProjectID        string
+	TaskName         string
+	BuildVariant     string
+	DistroID         string
+	Revision         string
+
+	// WorkDir is the directory the agent creates task directories in.
+	WorkDir string
+	// OutputDir is the directory that logs, test results, test logs,
+	// coverage, generated tasks, and artifacts are written to.
+	OutputDir  string
+	Expansions map[string]string
+}
+
+// Validate checks that the options are sufficient to run a task
+// locally and populates defaults.
+func (o *LocalOptions) Validate() error {
+	catcher := grip.NewBasicCatcher()
+	if len(o.ProjectConfig) == 0 {
+		catcher.Add(errors.New("must specify a project configuration"))
+	}
+	if o.TaskName == "" {
+		catcher.Add(errors.New("must specify a task name"))
+	}
+	if o.BuildVariant == "" {
+		catcher.Add(errors.New("must specify a build variant"))
+	}
+	if o.WorkDir == "" {
+		catcher.Add(errors.New("must specify a working directory"))
+	}
+	if o.OutputDir == "" {
+		catcher.Add(errors.New("must specify an output directory"))
+	}
+	if catcher.HasErrors() {
+		return catcher.Resolve()
+	}
+
+	if o.ProjectID == "" {
+		o.ProjectID = "local"
+	}
+	if o.DistroID == "" {
+		o.DistroID = "localhost"
+	}
+	if o.Expansions == nil {
+		o.Expansions = map[string]string{}
+	}
+
+	return nil
+}
+
+// LoadProject loads the project configuration, merging in the files it
+// includes.
+func (o *LocalOptions) LoadProject() (*serviceModel.Project, error) {
+	project := &serviceModel.Project{}
+	if err := serviceModel.LoadProjectWithIncludes(o.ProjectConfig, o.ProjectID, o.ReadIncludedFile, project); err != nil {
+		return nil, errors.Wrap(err, "problem loading project configuration")
+	}
+	return project, nil
+}
+
+// Local implements Communicator for running a single task on a
+// workstation without an API server: task data is constructed from
+// the LocalOptions, and everything the agent reports is written to
+// files in the output directory.
+type Local struct {
+	opts LocalOptions
+
+	// project and config are the project configuration with the files
+	// it includes merged in, which is what the agent is given, as it
+	// can't read included files itself
+	project *serviceModel.Project
+	config  string
+
+	maxAttempts  int
+	timeoutStart time.Duration
+	timeoutMax   time.Duration
+
+	// these fields have setters
+	hostID     string
+	hostSecret string
+	apiUser    string
+	apiKey     string
+
+	createdAt       time.Time
+	endTaskDetail   *apimodels.TaskEndDetail
+	testResults     []task.TestResult
+	coverage        []coverage.FileCoverage
+	generated       []string
+	artifacts       []*artifact.File
+	keyVal          map[string]*serviceModel.KeyVal
+	lastMessageSent time.Time
+
+	mu sync.RWMutex
+}
+
+// NewLocal constructs a Local communicator, creating the output
+// directory if needed.
+func NewLocal(opts LocalOptions) (*Local, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid local options")
+	}
+
+	project, err := opts.LoadProject()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	config, err := yaml.Marshal(project)
+	if err != nil {
+		return nil, errors.Wrap(err, "problem marshaling project configuration")
+	}
+
+	for _, dir := range []string{opts.OutputDir, filepath.Join(opts.OutputDir, localLogDirectory)} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, errors.Wrapf(err, "problem creating directory '%s'", dir)
+		}
+	}
+
+	return &Local{
+		opts:         opts,
+		project:      project,
+		config:       string(config),
+		maxAttempts:  defaultMaxAttempts,
+		timeoutStart: defaultTimeoutStart,
+		timeoutMax:   defaultTimeoutMax,
+		createdAt:    time.Now(),
+		keyVal:       make(map[string]*serviceModel.KeyVal),
+	}, nil
+}
+
+// TaskData returns the task credentials that the agent should use
+// when running the local task.
+func (c *Local) TaskData() TaskData {
+	return TaskData{ID: LocalTaskID, Secret: LocalTaskID}
+}
+
+// GetEndTaskDetail returns the final task status reported by the
+// agent, or nil if the task has not finished.
+func (c *Local) GetEndTaskDetail() *apimodels.TaskEndDetail {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.endTaskDetail
+}
+
+func (c *Local) Close() {}
+
+func (c *Local) LastMessageAt() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.lastMessageSent
+}
+
+func (c *Local) UpdateLastMessageTime() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastMessageSent = time.Now()
+}
+
+// nolint
+func (c *Local) SetTimeoutStart(timeoutStart time.Duration) { c.timeoutStart = timeoutStart }
+func (c *Local) SetTimeoutMax(timeoutMax time.Duration)     { c.timeoutMax = timeoutMax }
+func (c *Local) SetMaxAttempts(attempts int)                { c.maxAttempts = attempts }
+func (c *Local) SetHostID(hostID string)                    { c.hostID = hostID }
+func (c *Local) SetHostSecret(hostSecret string)            { c.hostSecret = hostSecret }
+func (c *Local) GetHostID() string                          { return c.hostID }
+func (c *Local) GetHostSecret() string                      { return c.hostSecret }
+func (c *Local) SetAPIUser(apiUser string)                  { c.apiUser = apiUser }
+func (c *Local) SetAPIKey(apiKey string)                    { c.apiKey = apiKey }
+
+// StartTask is a noop for local tasks.
+func (c *Local) StartTask(ctx context.Context, td TaskData) error { return nil }
+
+// EndTask records the final status of the task in the output
+// directory.
+func (c *Local) EndTask(ctx context.Context, detail *apimodels.TaskEndDetail, td TaskData) (*apimodels.EndTaskResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.endTaskDetail = detail
+	if err := c.writeJSON(localEndTaskDetailFile, detail); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &apimodels.EndTaskResponse{}, nil
+}
+
+// GetTask returns a task document for the configured task.
+func (c *Local) GetTask(ctx context.Context, td TaskData) (*task.Task, error) {
+	return &task.Task{
+		Id:           td.ID,
+		Secret:       td.Secret,
+		CreateTime:   c.createdAt,
+		Version:      LocalVersionID,
+		Project:      c.opts.ProjectID,
+		Revision:     c.opts.Revision,
+		BuildId:      fmt.Sprintf("%s_%s", c.opts.ProjectID, c.opts.BuildVariant),
+		DistroId:     c.opts.DistroID,
+		BuildVariant: c.opts.BuildVariant,
+		DisplayName:  c.opts.TaskName,
+		Requester:    evergreen.RepotrackerVersionRequester,
+		Status:       evergreen.TaskStarted,
+	}, nil
+}
+
+// GetProjectRef returns a project ref built from the repository
+// fields of the project configuration.
+func (c *Local) GetProjectRef(ctx context.Context, td TaskData) (*serviceModel.ProjectRef, error) {
+	return &serviceModel.ProjectRef{
+		Identifier: c.opts.ProjectID,
+		Owner:      c.project.Owner,
+		Repo:       c.project.Repo,
+		Branch:     c.project.Branch,
+		RepoKind:   c.project.RepoKind,
+		RemotePath: c.project.RemotePath,
+		Enabled:    true,
+	}, nil
+}
+
+// GetDistro returns a distro rooted in the configured working directory.
+func (c *Local) GetDistro(ctx context.Context, td TaskData) (*distro.Distro, error) {
+	return &distro.Distro{
+		Id:       c.opts.DistroID,
+		Provider: evergreen.HostTypeStatic,
+		WorkDir:  c.opts.WorkDir,
+	}, nil
+}
+
+// GetVersion returns a version containing the local project configuration.
+func (c *Local) GetVersion(ctx context.Context, td TaskData) (*version.Version, error) {
+	return &version.Version{
+		Id:         LocalVersionID,
+		CreateTime: c.createdAt,
+		Revision:   c.opts.Revision,
+		Identifier: c.opts.ProjectID,
+		Requester:  evergreen.RepotrackerVersionRequester,
+		Config:     c.config,
+	}, nil
+}
+
+// Heartbeat never aborts local tasks.
+func (c *Local) Heartbeat(ctx context.Context, td TaskData) (bool, error) { return false, nil }
+
+// FetchExpansionVars returns the expansions supplied in the options,
+// which take the place of project variables.
+func (c *Local) FetchExpansionVars(ctx context.Context, td TaskData) (*apimodels.ExpansionVars, error) {
+	vars := apimodels.ExpansionVars{}
+	for k, v := range c.opts.Expansions {
+		vars[k] = v
+	}
+	return &vars, nil
+}
+
+// GetNextTask is not supported, as a Local communicator only serves
+// a single task.
+func (c *Local) GetNextTask(ctx context.Context) (*apimodels.NextTaskResponse, error) {
+	return nil, errors.New("local communicator does not dispatch tasks")
+}
+
+// RegisterHost is not supported, as local tasks do not run on hosts.
+func (c *Local) RegisterHost(ctx context.Context, token string) (*apimodels.HostRegistrationResponse, error) {
+	return nil, errors.New("local communicator does not bootstrap hosts")
+}
+
+// AppendHostSetupLog is not supported, as local tasks do not run on hosts.
+func (c *Local) AppendHostSetupLog(ctx context.Context, logs string) error {
+	return errors.New("local communicator does not bootstrap hosts")
+}
+
+// EndHostSetup is not supported, as local tasks do not run on hosts.
+func (c *Local) EndHostSetup(ctx context.Context, result *apimodels.HostSetupResult) error {
+	return errors.New("local communicator does not bootstrap hosts")
+}
+
+// StartHostAgent is not supported, as local tasks do not run on hosts.
+func (c *Local) StartHostAgent(ctx context.Context) error {
+	return errors.New("local communicator does not bootstrap hosts")
+}
+
+// GetLoggerProducer constructs a LoggerProducer that writes each log
+// channel to the console and to a file in the output directory.
+func (c *Local) GetLoggerProducer(ctx context.Context, td TaskData) LoggerProducer {
+	local := grip.GetSender()
+
+	exec := newLogSender(ctx, c, apimodels.AgentLogPrefix, td)
+	grip.CatchWarning(exec.SetFormatter(send.MakeDefaultFormatter()))
+	exec = send.NewConfiguredMultiSender(local, exec)
+
+	task := newTimeoutLogSender(ctx, c, apimodels.TaskLogPrefix, td)
+	grip.CatchWarning(task.SetFormatter(send.MakeDefaultFormatter()))
+	task = send.NewConfiguredMultiSender(local, task)
+
+	system := newLogSender(ctx, c, apimodels.SystemLogPrefix, td)
+	grip.CatchWarning(system.SetFormatter(send.MakeDefaultFormatter()))
+
+	return &logHarness{
+		execution: logging.MakeGrip(exec),
+		task:      logging.MakeGrip(task),
+		system:    logging.MakeGrip(system),
+	}
+}
+
+// SendLogMessages appends log messages to the file for their log type.
+func (c *Local) SendLogMessages(ctx context.Context, td TaskData, msgs []apimodels.LogMessage) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	byType := map[string][]string{}
+	for _, msg := range msgs {
+		byType[msg.Type] = append(byType[msg.Type],
+			fmt.Sprintf("[%s] [%s] %s\n", msg.Timestamp.Format(time.RFC3339Nano), msg.Severity, msg.Message))
+	}
+
+	catcher := grip.NewBasicCatcher()
+	for logType, lines := range byType {
+		catcher.Add(c.appendLines(localLogFileName(logType), lines))
+	}
+
+	return catcher.Resolve()
+}
+
+// SendProcessInfo discards process information for local tasks.
+func (c *Local) SendProcessInfo(ctx context.Context, td TaskData, procs []*message.ProcessInfo) error {
+	return nil
+}
+
+// SendSystemInfo discards system information for local tasks.
+func (c *Local) SendSystemInfo(ctx context.Context, td TaskData, sysinfo *message.SystemInfo) error {
+	return nil
+}
+
+// SendTestResults appends the results to the test results file.
+func (c *Local) SendTestResults(ctx context.Context, td TaskData, results *task.LocalTestResults) error {
+	if results == nil || len(results.Results) == 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.testResults = append(c.testResults, results.Results...)
+	return errors.WithStack(c.writeJSON(localTestResultsFile, c.testResults))
+}
+
+// SendCoverage appends the coverage to the coverage file.
+func (c *Local) SendCoverage(ctx context.Context, td TaskData, files []coverage.FileCoverage) error {
+	if len(files) == 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.coverage = append(c.coverage, files...)
+	return errors.WithStack(c.writeJSON(localCoverageFile, c.coverage))
+}
+
+// GenerateTasks checks that the generated project files can be parsed and
+// appends them to the generated tasks file. Generated tasks aren't run
+// locally.
+func (c *Local) GenerateTasks(ctx context.Context, td TaskData, files []string) error {
+	if _, err := serviceModel.ParseGeneratedProject(files); err != nil {
+		return errors.Wrap(err, "problem parsing generated tasks")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generated = append(c.generated, files...)
+	return errors.WithStack(c.writeJSON(localGeneratedFile, c.generated))
+}
+
+// SendTestLog writes the test log to the test log directory and
+// returns its path as the log's id.
+func (c *Local) SendTestLog(ctx context.Context, td TaskData, log *serviceModel.TestLog) (string, error) {
+	if log == nil {
+		return "", nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Join(c.opts.OutputDir, localTestLogDirectory), 0755); err != nil {
+		return "", errors.Wrap(err, "problem creating test log directory")
+	}
+
+	fn := filepath.Join(localTestLogDirectory, fmt.Sprintf("%s.json", util.CleanForPath(log.Name)))
+	if err := c.writeJSON(fn, log); err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return filepath.Join(c.opts.OutputDir, fn), nil
+}
+
+// GetTaskPatch is not supported, as local tasks do not belong to patches.
+func (c *Local) GetTaskPatch(ctx context.Context, td TaskData) (*patchmodel.Patch, error) {
+	return nil, errors.New("local tasks do not have patches")
+}
+
+// GetPatchFile is not supported, as local tasks do not belong to patches.
+func (c *Local) GetPatchFile(ctx context.Context, td TaskData, patchFileID string) (string, error) {
+	return "", errors.New("local tasks do not have patches")
+}
+
+// AttachFiles records the files in the artifacts file.
+func (c *Local) AttachFiles(ctx context.Context, td TaskData, taskFiles []*artifact.File) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.artifacts = append(c.artifacts, taskFiles...)
+	return errors.WithStack(c.writeJSON(localArtifactsFile, c.artifacts))
+}
+
+// GetManifest returns an empty manifest.
+func (c *Local) GetManifest(ctx context.Context, td TaskData) (*manifest.Manifest, error) {
+	return &manifest.Manifest{
+		Id:          LocalVersionID,
+		Revision:    c.opts.Revision,
+		ProjectName: c.opts.ProjectID,
+	}, nil
+}
+
+// S3Copy is not supported, as it requires the API server's credentials.
+func (c *Local) S3Copy(ctx context.Context, td TaskData, req *apimodels.S3CopyRequest) error {
+	return errors.New("s3 copy is not supported for local tasks")
+}
+
+// KeyValInc increments a counter held in memory for the duration of
+// the local task.
+func (c *Local) KeyValInc(ctx context.Context, td TaskData, kv *serviceModel.KeyVal) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.keyVal[kv.Key]
+	if !ok {
+		cached = &serviceModel.KeyVal{}
+		*cached = *kv
+		c.keyVal[kv.Key] = cached
+	}
+	cached.Value++
+	*kv = *cached
+	return nil
+}
+
+// PostJSONData writes the document to the json directory.
+func (c *Local) PostJSONData(ctx context.Context, td TaskData, path string, data interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Join(c.opts.OutputDir, localJSONDirectory), 0755); err != nil {
+		return errors.Wrap(err, "problem creating json directory")
+	}
+
+	return errors.WithStack(c.writeJSON(filepath.Join(localJSONDirectory, fmt.Sprintf("%s.json", util.CleanForPath(path))), data))
+}
+
+// GetJSONData is not supported, as local tasks have no history.
+func (c *Local) GetJSONData(ctx context.Context, td TaskData, tn, dn, vn string) ([]byte, error) {
+	return nil, errors.New("json data is not available for local tasks")
+}
+
+// GetJSONHistory is not supported, as local tasks have no history.
+func (c *Local) GetJSONHistory(ctx context.Context, td TaskData, tags bool, tn, dn string) ([]byte, error) {
+	return nil, errors.New("json history is not available for local tasks")
+}
+
+var errLocalUnsupported = errors.New("operation is not supported by the local communicator")
+
+// nolint
+func (c *Local) SetBannerMessage(ctx context.Context, m string, t admin.BannerTheme) error {
+	return errLocalUnsupported
+}
+func (c *Local) GetBannerMessage(ctx context.Context) (string, error) { return "", errLocalUnsupported }
+func (c *Local) SetServiceFlags(ctx context.Context, f *model.APIServiceFlags) error {
+	return errLocalUnsupported
+}
+func (c *Local) GetServiceFlags(ctx context.Context) (*model.APIServiceFlags, error) {
+	return nil, errLocalUnsupported
+}
+func (c *Local) RestartRecentTasks(ctx context.Context, starAt, endAt time.Time) error {
+	return errLocalUnsupported
+}
+func (c *Local) GetHostsByUser(ctx context.Context, user string) ([]*model.APIHost, error) {
+	return nil, errLocalUnsupported
+}
+func (c *Local) CreateSpawnHost(ctx context.Context, distroID string, keyName string) (*model.APIHost, error) {
+	return nil, errLocalUnsupported
+}
+func (c *Local) TerminateSpawnHost(ctx context.Context, hostID string) error {
+	return errLocalUnsupported
+}
+func (c *Local) ChangeSpawnHostPassword(context.Context, string, string) error {
+	return errLocalUnsupported
+}
+func (c *Local) ExtendSpawnHostExpiration(context.Context, string, int) error {
+	return errLocalUnsupported
+}
+func (c *Local) GetHosts(ctx context.Context, f func([]*model.APIHost) error) error {
+	return errLocalUnsupported
+}
+func (c *Local) GetDistrosList(ctx context.Context) ([]model.APIDistro, error) {
+	return nil, errLocalUnsupported
+}
+func (c *Local) GetCurrentUsersKeys(ctx context.Context) ([]model.APIPubKey, error) {
+	return nil, errLocalUnsupported
+}
+func (c *Local) AddPublicKey(ctx context.Context, keyName, keyValue string) error {
+	return errLocalUnsupported
+}
+func (c *Local) DeletePublicKey(ctx context.Context, keyName string) error {
+	return errLocalUnsupported
+}
+func (c *Local) ListAliases(ctx context.Context, keyName string) ([]serviceModel.PatchDefinition, error) {
+	return nil, errLocalUnsupported
+}
+
+func (c *Local) GetTaskLogs(ctx context.Context, opts TaskLogOptions) ([]model.APILogMessage, error) {
+	return nil, errLocalUnsupported
+}
+
+func (c *Local) FollowTaskLogs(ctx context.Context, opts TaskLogOptions, handler func(model.APILogMessage) error) error {
+	return errLocalUnsupported
+}
+
+func (c *Local) GetCommitQueue(ctx context.Context, projectID string) (*model.APICommitQueue, error) {
+	return nil, errLocalUnsupported
+}
+
+func (c *Local) EnqueueItem(ctx context.Context, projectID, item string) (int, error) {
+	return -1, errLocalUnsupported
+}
+
+func (c *Local) DeleteCommitQueueItem(ctx context.Context, projectID, item string) error {
+	return errLocalUnsupported
+}
+
+////////////////////////////////////////////////////////////////////////
+//
+// helpers for writing output; callers must hold the lock.
+
+func localLogFileName(logType string) string {
+	var name string
+	switch logType {
+	case apimodels.AgentLogPrefix:
+		name = "agent"
+	case apimodels.TaskLogPrefix:
+		name = "task"
+	case apimodels.SystemLogPrefix:
+		name = "system"
+	default:
+		name = strings.ToLower(logType)
+	}
+
+	return filepath.Join(localLogDirectory, name+".log")
+}
+
+func (c *Local) appendLines(fn string, lines []string) error {
+	f, err := os.OpenFile(filepath.Join(c.opts.OutputDir, fn), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "problem opening log file '%s'", fn)
+	}
+	defer f.Close()
+
+	for _, line := range lines {
+		if _, err = f.WriteString(line); err != nil {
+			return errors.Wrapf(err, "problem writing to log file '%s'", fn)
+		}
+	}
+
+	return nil
+}
+
+func (c *Local) writeJSON(fn string, data interface{}) error {
+	out, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return errors.Wrapf(err, "problem marshaling data for '%s'", fn)
+	}
+
+	return errors.Wrapf(ioutil.WriteFile(filepath.Join(c.opts.OutputDir, fn), out, 0644),
+		"problem writing '%s'", fn)
+}
@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/evergreen-ci/evergreen/apimodels"
+	serviceModel "github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/artifact"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/stretchr/testify/suite"
+)
+
+type LocalCommunicatorSuite struct {
+	dir  string
+	comm *Local
+	suite.Suite
+}
+
+func TestLocalCommunicatorSuite(t *testing.T) {
+	suite.Run(t, new(LocalCommunicatorSuite))
+}
+
+func (s *LocalCommunicatorSuite) SetupTest() {
+	var err error
+	s.dir, err = ioutil.TempDir("", "local-communicator")
+	s.Require().NoError(err)
+
+	s.comm, err = NewLocal(LocalOptions{
+		ProjectConfig: []byte("owner: evergreen-ci\nrepo: evergreen\nbranch: master\n"),
+		TaskName:      "compile",
+		BuildVariant:  "ubuntu",
+		WorkDir:       s.dir,
+		OutputDir:     filepath.Join(s.dir, "out"),
+		Expansions:    map[string]string{"foo": "bar"},
+	})
+	s.Require().NoError(err)
+}
+
+func (s *LocalCommunicatorSuite) TearDownTest() {
+	s.NoError(os.RemoveAll(s.dir))
+}
+
+func (s *LocalCommunicatorSuite) TestOptionsValidation() {
+	opts := LocalOptions{}
+	s.Error(opts.Validate())
+
+	opts = LocalOptions{
+		ProjectConfig: []byte("tasks: []"),
+		TaskName:      "compile",
+		BuildVariant:  "ubuntu",
+		WorkDir:       s.dir,
+		OutputDir:     s.dir,
+	}
+	s.NoError(opts.Validate())
+	s.Equal("local", opts.ProjectID)
+	s.Equal("localhost", opts.DistroID)
+	s.NotNil(opts.Expansions)
+}
+
+func (s *LocalCommunicatorSuite) TestTaskData() {
+	ctx := context.Background()
+	td := s.comm.TaskData()
+
+	t, err := s.comm.GetTask(ctx, td)
+	s.NoError(err)
+	s.Equal("compile", t.DisplayName)
+	s.Equal("ubuntu", t.BuildVariant)
+
+	ref, err := s.comm.GetProjectRef(ctx, td)
+	s.NoError(err)
+	s.Equal("evergreen-ci", ref.Owner)
+	s.Equal("evergreen", ref.Repo)
+	s.Equal("master", ref.Branch)
+
+	d, err := s.comm.GetDistro(ctx, td)
+	s.NoError(err)
+	s.Equal(s.dir, d.WorkDir)
+
+	vars, err := s.comm.FetchExpansionVars(ctx, td)
+	s.NoError(err)
+	s.Equal("bar", (*vars)["foo"])
+
+	_, err = s.comm.GetNextTask(ctx)
+	s.Error(err)
+}
+
+func (s *LocalCommunicatorSuite) TestIncludedFilesAreMerged() {
+	opts := LocalOptions{
+		ProjectConfig: []byte("include: tasks.yml\nowner: evergreen-ci\n"),
+		TaskName:      "compile",
+		BuildVariant:  "ubuntu",
+		WorkDir:       s.dir,
+		OutputDir:     filepath.Join(s.dir, "out"),
+	}
+	_, err := NewLocal(opts)
+	s.Error(err)
+
+	opts.ReadIncludedFile = func(module *serviceModel.Module, fileName string) ([]byte, error) {
+		s.Nil(module)
+		s.Equal("tasks.yml", fileName)
+		return []byte("tasks:\n- name: compile\n"), nil
+	}
+	comm, err := NewLocal(opts)
+	s.Require().NoError(err)
+
+	v, err := comm.GetVersion(context.Background(), comm.TaskData())
+	s.Require().NoError(err)
+	project := &serviceModel.Project{}
+	s.Require().NoError(serviceModel.LoadProjectInto([]byte(v.Config), "local", project))
+	s.NotNil(project.FindProjectTask("compile"))
+	s.Equal("evergreen-ci", project.Owner)
+}
+
+func (s *LocalCommunicatorSuite) TestLogsAreWrittenByType() {
+	ctx := context.Background()
+	td := s.comm.TaskData()
+
+	s.NoError(s.comm.SendLogMessages(ctx, td, []apimodels.LogMessage{
+		{Type: apimodels.TaskLogPrefix, Severity: apimodels.LogInfoPrefix, Message: "task message", Timestamp: time.Now()},
+		{Type: apimodels.AgentLogPrefix, Severity: apimodels.LogInfoPrefix, Message: "agent message", Timestamp: time.Now()},
+	}))
+
+	out, err := ioutil.ReadFile(filepath.Join(s.dir, "out", "logs", "task.log"))
+	s.NoError(err)
+	s.Contains(string(out), "task message")
+	s.NotContains(string(out), "agent message")
+
+	out, err = ioutil.ReadFile(filepath.Join(s.dir, "out", "logs", "agent.log"))
+	s.NoError(err)
+	s.Contains(string(out), "agent message")
+}
+
+func (s *LocalCommunicatorSuite) TestResultsAndArtifactsAccumulate() {
+	ctx := context.Background()
+	td := s.comm.TaskData()
+
+	s.NoError(s.comm.SendTestResults(ctx, td, &task.LocalTestResults{Results: []task.TestResult{{TestFile: "one"}}}))
+	s.NoError(s.comm.SendTestResults(ctx, td, &task.LocalTestResults{Results: []task.TestResult{{TestFile: "two"}}}))
+	s.NoError(s.comm.SendTestResults(ctx, td, nil))
+
+	results := []task.TestResult{}
+	data, err := ioutil.ReadFile(filepath.Join(s.dir, "out", localTestResultsFile))
+	s.NoError(err)
+	s.NoError(json.Unmarshal(data, &results))
+	s.Len(results, 2)
+
+	s.NoError(s.comm.AttachFiles(ctx, td, []*artifact.File{{Name: "binary", Link: "file:///tmp/binary"}}))
+	files := []artifact.File{}
+	data, err = ioutil.ReadFile(filepath.Join(s.dir, "out", localArtifactsFile))
+	s.NoError(err)
+	s.NoError(json.Unmarshal(data, &files))
+	s.Len(files, 1)
+}
+
+func (s *LocalCommunicatorSuite) TestEndTaskIsRecorded() {
+	s.Nil(s.comm.GetEndTaskDetail())
+
+	resp, err := s.comm.EndTask(context.Background(), &apimodels.TaskEndDetail{Status: "success"}, s.comm.TaskData())
+	s.NoError(err)
+	s.NotNil(resp)
+	s.Equal("success", s.comm.GetEndTaskDetail().Status)
+
+	_, err = os.Stat(filepath.Join(s.dir, "out", localEndTaskDetailFile))
+	s.NoError(err)
+}
+
+func (s *LocalCommunicatorSuite) TestKeyValInc() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for i := int64(1); i <= 3; i++ {
+		kv := &serviceModel.KeyVal{Key: "counter"}
+		s.NoError(s.comm.KeyValInc(ctx, s.comm.TaskData(), kv))
+		s.Equal(i, kv.Value)
+	}
+
+	kv := &serviceModel.KeyVal{Key: "other"}
+	s.NoError(s.comm.KeyValInc(ctx, s.comm.TaskData(), kv))
+	s.Equal(int64(1), kv.Value)
+}
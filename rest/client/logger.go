@@ -7,6 +7,7 @@ import (
 	"github.com/mongodb/grip"
 	"github.com/mongodb/grip/level"
 	"github.com/mongodb/grip/logging"
+	"github.com/mongodb/grip/message"
 	"github.com/mongodb/grip/send"
 	"github.com/pkg/errors"
 )
@@ -150,3 +151,89 @@ func (l *singleChannelLogHarness) Close() error {
 
 	return errors.Wrap(catcher.Resolve(), "problem closing log harness")
 }
+
+////////////////////////////////////////////////////////////////////////
+//
+// Prefixed LoggerProducer
+
+type prefixedLogHarness struct {
+	execution grip.Journaler
+	task      grip.Journaler
+	system    grip.Journaler
+	mu        sync.Mutex
+	writers   []io.WriteCloser
+}
+
+// NewPrefixedLogHarness returns a LoggerProducer that writes to the
+// same channels as the given LoggerProducer, prefixing every message
+// with the given prefix. This makes it possible to tell apart the
+// output of commands that share a logger and run at the same time.
+//
+// Closing the returned LoggerProducer only closes its own writers; the
+// underlying LoggerProducer must be closed separately.
+func NewPrefixedLogHarness(logger LoggerProducer, prefix string) LoggerProducer {
+	return &prefixedLogHarness{
+		execution: logging.MakeGrip(newPrefixSender(logger.Execution().GetSender(), prefix)),
+		task:      logging.MakeGrip(newPrefixSender(logger.Task().GetSender(), prefix)),
+		system:    logging.MakeGrip(newPrefixSender(logger.System().GetSender(), prefix)),
+	}
+}
+
+func (l *prefixedLogHarness) Execution() grip.Journaler { return l.execution }
+func (l *prefixedLogHarness) Task() grip.Journaler      { return l.task }
+func (l *prefixedLogHarness) System() grip.Journaler    { return l.system }
+
+func (l *prefixedLogHarness) TaskWriter(p level.Priority) io.WriteCloser {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	w := send.MakeWriterSender(l.task.GetSender(), p)
+	l.writers = append(l.writers, w)
+	return w
+}
+
+func (l *prefixedLogHarness) SystemWriter(p level.Priority) io.WriteCloser {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	w := send.MakeWriterSender(l.system.GetSender(), p)
+	l.writers = append(l.writers, w)
+	return w
+}
+
+func (l *prefixedLogHarness) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	catcher := grip.NewCatcher()
+
+	for _, w := range l.writers {
+		catcher.Add(w.Close())
+	}
+
+	return errors.Wrap(catcher.Resolve(), "problem closing prefixed log harness")
+}
+
+// prefixSender wraps a sender, adding a prefix to every message it
+// sends. Closing a prefixSender does not close the wrapped sender.
+type prefixSender struct {
+	prefix string
+	send.Sender
+}
+
+func newPrefixSender(sender send.Sender, prefix string) send.Sender {
+	return &prefixSender{
+		prefix: prefix,
+		Sender: sender,
+	}
+}
+
+func (s *prefixSender) Send(m message.Composer) {
+	if !m.Loggable() {
+		return
+	}
+
+	s.Sender.Send(message.NewFormattedMessage(m.Priority(), "[%s] %s", s.prefix, m.String()))
+}
+
+func (s *prefixSender) Close() error { return nil }
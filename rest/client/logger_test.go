@@ -0,0 +1,32 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/mongodb/grip/level"
+	"github.com/mongodb/grip/send"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixedLogHarness(t *testing.T) {
+	assert := assert.New(t)
+	sender, err := send.NewInternalLogger("test", send.LevelInfo{Default: level.Info, Threshold: level.Debug})
+	assert.NoError(err)
+	logger := NewPrefixedLogHarness(NewSingleChannelLogHarness("test", sender), "compile")
+
+	logger.Task().Info("hello")
+	msg, ok := sender.GetMessageSafe()
+	assert.True(ok)
+	assert.Equal("[compile] hello", msg.Message.String())
+	assert.Equal(level.Info, msg.Priority)
+
+	logger.Execution().Debug("")
+	assert.False(sender.HasMessage())
+
+	// closing the prefixed logger leaves the underlying sender open
+	assert.NoError(logger.Close())
+	logger.System().Warning("still open")
+	msg, ok = sender.GetMessageSafe()
+	assert.True(ok)
+	assert.Equal("[compile] still open", msg.Message.String())
+}
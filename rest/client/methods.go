@@ -13,6 +13,7 @@ import (
 	"github.com/evergreen-ci/evergreen/apimodels"
 	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/artifact"
+	"github.com/evergreen-ci/evergreen/model/coverage"
 	"github.com/evergreen-ci/evergreen/model/distro"
 	"github.com/evergreen-ci/evergreen/model/manifest"
 	patchmodel "github.com/evergreen-ci/evergreen/model/patch"
@@ -418,6 +419,49 @@ func (c *communicatorImpl) SendTestResults(ctx context.Context, taskData TaskDat
 	return nil
 }
 
+// SendCoverage posts the line coverage of a set of files for the
+// communicator's task. If there are no files, this operation is a noop.
+func (c *communicatorImpl) SendCoverage(ctx context.Context, taskData TaskData, files []coverage.FileCoverage) error {
+	if len(files) == 0 {
+		return nil
+	}
+	info := requestInfo{
+		method:   post,
+		taskData: &taskData,
+		version:  v1,
+	}
+	info.setTaskPathSuffix("coverage")
+	resp, err := c.retryRequest(ctx, info, files)
+	if err != nil {
+		return errors.Wrapf(err, "failed to post coverage for task %s", taskData.ID)
+	}
+	defer resp.Body.Close()
+	return nil
+}
+
+// GenerateTasks posts the project files a task generated, whose functions,
+// tasks and build variants the server adds to the task's version. The
+// request isn't retried, since an invalid project won't become valid.
+func (c *communicatorImpl) GenerateTasks(ctx context.Context, taskData TaskData, files []string) error {
+	info := requestInfo{
+		method:   post,
+		taskData: &taskData,
+		version:  v1,
+	}
+	info.setTaskPathSuffix("generate")
+	resp, err := c.request(ctx, info, files)
+	if err != nil {
+		return errors.Wrapf(err, "failed to generate tasks for task %s", taskData.ID)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return errors.Errorf("failed to generate tasks for task %s (status %d): %s",
+			taskData.ID, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
 // AttachFiles attaches task files.
 func (c *communicatorImpl) AttachFiles(ctx context.Context, taskData TaskData, taskFiles []*artifact.File) error {
 	if len(taskFiles) == 0 {
@@ -597,3 +641,76 @@ func (c *communicatorImpl) SendSystemInfo(ctx context.Context, td TaskData, sysi
 
 	return errors.Wrap(err, "problem sending sysinfo results")
 }
+
+// RegisterHost exchanges a host's one-time bootstrap token for its ID and
+// secret, and the information it needs to set itself up. The host's ID is
+// not known when its user data is written, so the request is not made on
+// behalf of a host.
+func (c *communicatorImpl) RegisterHost(ctx context.Context, token string) (*apimodels.HostRegistrationResponse, error) {
+	info := requestInfo{
+		method:  post,
+		version: v1,
+		path:    "hosts/bootstrap/register",
+	}
+	resp, err := c.retryRequest(ctx, info, &apimodels.HostRegistrationRequest{Token: token})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to register host")
+	}
+	defer resp.Body.Close()
+
+	registration := &apimodels.HostRegistrationResponse{}
+	if err = util.ReadJSONInto(resp.Body, registration); err != nil {
+		return nil, errors.Wrap(err, "failed to read host registration from response")
+	}
+	return registration, nil
+}
+
+// AppendHostSetupLog sends output from the host's setup script.
+func (c *communicatorImpl) AppendHostSetupLog(ctx context.Context, logs string) error {
+	info := requestInfo{
+		method:  post,
+		version: v1,
+		path:    fmt.Sprintf("hosts/%s/bootstrap/logs", c.hostID),
+	}
+	resp, err := c.retryRequest(ctx, info, &apimodels.HostSetupLog{Logs: logs})
+	if err != nil {
+		return errors.Wrapf(err, "failed to send setup logs for host %s", c.hostID)
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// EndHostSetup reports the result of the host's setup script.
+func (c *communicatorImpl) EndHostSetup(ctx context.Context, result *apimodels.HostSetupResult) error {
+	info := requestInfo{
+		method:  post,
+		version: v1,
+		path:    fmt.Sprintf("hosts/%s/bootstrap/setup", c.hostID),
+	}
+	resp, err := c.retryRequest(ctx, info, result)
+	if err != nil {
+		return errors.Wrapf(err, "failed to send setup result for host %s", c.hostID)
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// StartHostAgent tells the API server that the host is starting the agent
+// it just downloaded. It returns an error if the host should not run an
+// agent anymore.
+func (c *communicatorImpl) StartHostAgent(ctx context.Context) error {
+	info := requestInfo{
+		method:  post,
+		version: v1,
+		path:    fmt.Sprintf("hosts/%s/bootstrap/agent", c.hostID),
+	}
+	resp, err := c.retryRequest(ctx, info, "")
+	if err != nil {
+		return errors.Wrapf(err, "failed to start agent on host %s", c.hostID)
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 	serviceModel "github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/admin"
 	"github.com/evergreen-ci/evergreen/model/artifact"
+	"github.com/evergreen-ci/evergreen/model/coverage"
 	"github.com/evergreen-ci/evergreen/model/distro"
 	"github.com/evergreen-ci/evergreen/model/manifest"
 	patchmodel "github.com/evergreen-ci/evergreen/model/patch"
@@ -62,6 +63,17 @@ type Mock struct {
 	logMessages map[string][]apimodels.LogMessage
 	PatchFiles  map[string]string
 	keyVal      map[string]*serviceModel.KeyVal
+	TestResults map[string][]task.TestResult
+	TestLogs    map[string][]*serviceModel.TestLog
+	Coverage    map[string][]coverage.FileCoverage
+	Generated   map[string][]string
+
+	// host bootstrap data
+	HostRegistration    *apimodels.HostRegistrationResponse
+	HostSetupLogs       []string
+	HostSetupResult     *apimodels.HostSetupResult
+	HostAgentStarts     int
+	HostAgentShouldStop bool
 
 	LastMessageSent time.Time
 
@@ -85,6 +97,10 @@ func NewMock(serverURL string) *Mock {
 		ProcInfo:      make(map[string][]*message.ProcessInfo),
 		SysInfo:       make(map[string]*message.SystemInfo),
 		AttachedFiles: make(map[string][]*artifact.File),
+		TestResults:   make(map[string][]task.TestResult),
+		TestLogs:      make(map[string][]*serviceModel.TestLog),
+		Coverage:      make(map[string][]coverage.FileCoverage),
+		Generated:     make(map[string][]string),
 		serverURL:     serverURL,
 	}
 }
@@ -355,6 +371,14 @@ func (c *Mock) RestartRecentTasks(ctx context.Context, starAt, endAt time.Time)
 // SendResults posts a set of test results for the communicator's task.
 // If results are empty or nil, this operation is a noop.
 func (c *Mock) SendTestResults(ctx context.Context, td TaskData, results *task.LocalTestResults) error {
+	if results == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.TestResults[td.ID] = append(c.TestResults[td.ID], results.Results...)
 	return nil
 }
 
@@ -369,9 +393,79 @@ func (c *Mock) AttachFiles(ctx context.Context, td TaskData, taskFiles []*artifa
 	return nil
 }
 
+// SendCoverage posts the coverage of a set of files for the communicator's
+// task.
+func (c *Mock) SendCoverage(ctx context.Context, td TaskData, files []coverage.FileCoverage) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Coverage[td.ID] = append(c.Coverage[td.ID], files...)
+	return nil
+}
+
+// RegisterHost returns the mock's HostRegistration, or a registration with
+// an ID and secret if it's not set.
+func (c *Mock) RegisterHost(ctx context.Context, token string) (*apimodels.HostRegistrationResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.HostRegistration == nil {
+		c.HostRegistration = &apimodels.HostRegistrationResponse{HostId: "host", Secret: "secret"}
+	}
+	return c.HostRegistration, nil
+}
+
+// AppendHostSetupLog records the setup logs.
+func (c *Mock) AppendHostSetupLog(ctx context.Context, logs string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.HostSetupLogs = append(c.HostSetupLogs, logs)
+	return nil
+}
+
+// EndHostSetup records the setup result.
+func (c *Mock) EndHostSetup(ctx context.Context, result *apimodels.HostSetupResult) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.HostSetupResult = result
+	return nil
+}
+
+// StartHostAgent counts agent starts, and returns an error if
+// HostAgentShouldStop is set.
+func (c *Mock) StartHostAgent(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.HostAgentShouldStop {
+		return errors.New("HostAgentShouldStop is true")
+	}
+	c.HostAgentStarts++
+	return nil
+}
+
+// GenerateTasks posts the project files a task generated.
+func (c *Mock) GenerateTasks(ctx context.Context, td TaskData, files []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Generated[td.ID] = append(c.Generated[td.ID], files...)
+	return nil
+}
+
 // SendTestLog posts a test log for a communicator's task. Is a
 // noop if the test Log is nil.
 func (c *Mock) SendTestLog(ctx context.Context, td TaskData, log *serviceModel.TestLog) (string, error) {
+	if log == nil {
+		return "", nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.TestLogs[td.ID] = append(c.TestLogs[td.ID], log)
 	return "", nil
 }
 
@@ -471,3 +565,23 @@ func (c *Mock) DeletePublicKey(ctx context.Context, keyName string) error {
 func (c *Mock) ListAliases(ctx context.Context, keyName string) ([]serviceModel.PatchDefinition, error) {
 	return nil, errors.New("(c *Mock) ListAliases not implemented")
 }
+
+func (c *Mock) GetTaskLogs(ctx context.Context, opts TaskLogOptions) ([]model.APILogMessage, error) {
+	return nil, errors.New("(c *Mock) GetTaskLogs not implemented")
+}
+
+func (c *Mock) FollowTaskLogs(ctx context.Context, opts TaskLogOptions, handler func(model.APILogMessage) error) error {
+	return errors.New("(c *Mock) FollowTaskLogs not implemented")
+}
+
+func (c *Mock) GetCommitQueue(ctx context.Context, projectID string) (*model.APICommitQueue, error) {
+	return nil, errors.New("(c *Mock) GetCommitQueue not implemented")
+}
+
+func (c *Mock) EnqueueItem(ctx context.Context, projectID, item string) (int, error) {
+	return -1, errors.New("(c *Mock) EnqueueItem not implemented")
+}
+
+func (c *Mock) DeleteCommitQueueItem(ctx context.Context, projectID, item string) error {
+	return errors.New("(c *Mock) DeleteCommitQueueItem not implemented")
+}
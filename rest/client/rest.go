@@ -4,10 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/evergreen-ci/evergreen"
 	serviceModel "github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/admin"
 	"github.com/evergreen-ci/evergreen/rest"
@@ -431,3 +436,211 @@ func (c *communicatorImpl) ListAliases(ctx context.Context, project string) ([]s
 	}
 	return patchAliases, nil
 }
+
+// GetCommitQueue returns the items in a project's commit queue.
+func (c *communicatorImpl) GetCommitQueue(ctx context.Context, projectID string) (*model.APICommitQueue, error) {
+	info := requestInfo{
+		method:  get,
+		version: apiVersion2,
+		path:    fmt.Sprintf("commit_queue/%s", projectID),
+	}
+
+	resp, err := c.request(ctx, info, "")
+	if err != nil {
+		return nil, errors.Wrap(err, "problem reaching evergreen API server")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		errMsg := rest.APIError{}
+		if err = util.ReadJSONInto(resp.Body, &errMsg); err != nil {
+			return nil, errors.Wrap(err, "problem getting commit queue and parsing error message")
+		}
+		return nil, errors.Wrap(errMsg, "problem getting commit queue")
+	}
+
+	cq := &model.APICommitQueue{}
+	if err = util.ReadJSONInto(resp.Body, cq); err != nil {
+		return nil, errors.Wrap(err, "problem reading commit queue")
+	}
+	return cq, nil
+}
+
+// EnqueueItem adds a pull request number or patch id to the end of a
+// project's commit queue, and returns its position in the queue.
+func (c *communicatorImpl) EnqueueItem(ctx context.Context, projectID, item string) (int, error) {
+	info := requestInfo{
+		method:  put,
+		version: apiVersion2,
+		path:    fmt.Sprintf("commit_queue/%s/%s", projectID, item),
+	}
+
+	resp, err := c.request(ctx, info, "")
+	if err != nil {
+		return -1, errors.Wrap(err, "problem reaching evergreen API server")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		errMsg := rest.APIError{}
+		if err = util.ReadJSONInto(resp.Body, &errMsg); err != nil {
+			return -1, errors.Wrap(err, "problem enqueueing item and parsing error message")
+		}
+		return -1, errors.Wrap(errMsg, "problem enqueueing item")
+	}
+
+	position := model.APICommitQueuePosition{}
+	if err = util.ReadJSONInto(resp.Body, &position); err != nil {
+		return -1, errors.Wrap(err, "problem reading commit queue position")
+	}
+	return position.Position, nil
+}
+
+// DeleteCommitQueueItem removes a pull request number or patch id from a
+// project's commit queue.
+func (c *communicatorImpl) DeleteCommitQueueItem(ctx context.Context, projectID, item string) error {
+	info := requestInfo{
+		method:  delete,
+		version: apiVersion2,
+		path:    fmt.Sprintf("commit_queue/%s/%s", projectID, item),
+	}
+
+	resp, err := c.request(ctx, info, "")
+	if err != nil {
+		return errors.Wrap(err, "problem reaching evergreen API server")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		errMsg := rest.APIError{}
+		if err = util.ReadJSONInto(resp.Body, &errMsg); err != nil {
+			return errors.Wrap(err, "problem deleting item and parsing error message")
+		}
+		return errors.Wrap(errMsg, "problem deleting item")
+	}
+	return nil
+}
+
+// TaskLogOptions selects the lines of a task's log to fetch.
+type TaskLogOptions struct {
+	TaskID string
+	// Execution is the task execution whose log to fetch; if it is
+	// negative, the log of the latest execution is fetched.
+	Execution int
+	// Types and Severities filter the lines by their names, e.g. "task"
+	// and "error". Empty lists do not filter.
+	Types      []string
+	Severities []string
+}
+
+func (opts TaskLogOptions) path(offset int, follow bool) string {
+	q := url.Values{}
+	if opts.Execution >= 0 {
+		q.Set("execution", strconv.Itoa(opts.Execution))
+	}
+	if len(opts.Types) > 0 {
+		q.Set("type", strings.Join(opts.Types, ","))
+	}
+	if len(opts.Severities) > 0 {
+		q.Set("severity", strings.Join(opts.Severities, ","))
+	}
+	if offset > 0 {
+		q.Set("offset", strconv.Itoa(offset))
+	}
+	if follow {
+		q.Set("follow", "true")
+	}
+	path := fmt.Sprintf("tasks/%s/logs", opts.TaskID)
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+	return path
+}
+
+// GetTaskLogs returns the lines of a task's log written so far.
+func (c *communicatorImpl) GetTaskLogs(ctx context.Context, opts TaskLogOptions) ([]model.APILogMessage, error) {
+	info := requestInfo{
+		method:  get,
+		path:    opts.path(0, false),
+		version: apiVersion2,
+	}
+
+	p, err := newPaginatorHelper(&info, c)
+	if err != nil {
+		return nil, err
+	}
+
+	msgs := []model.APILogMessage{}
+	for p.hasMore() {
+		resp, err := p.getNextPage(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "problem getting logs for task '%s'", opts.TaskID)
+		}
+
+		page := []model.APILogMessage{}
+		err = util.ReadJSONInto(resp.Body, &page)
+		if err != nil {
+			return nil, errors.Wrap(err, "problem reading task logs")
+		}
+		msgs = append(msgs, page...)
+	}
+	return msgs, nil
+}
+
+// FollowTaskLogs calls the handler with each line of a task's log, as it
+// is written, until the task finishes or the context is canceled. The
+// server ends each stream after a while, so it is resumed from the number
+// of lines already read until the server says the log is complete.
+func (c *communicatorImpl) FollowTaskLogs(ctx context.Context, opts TaskLogOptions, handler func(model.APILogMessage) error) error {
+	offset := 0
+	for {
+		info := requestInfo{
+			method:  get,
+			path:    opts.path(offset, true),
+			version: apiVersion2,
+		}
+		resp, err := c.request(ctx, info, nil)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return errors.Wrapf(err, "problem following logs for task '%s'", opts.TaskID)
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return errors.Errorf("problem following logs for task '%s': %s", opts.TaskID, resp.Status)
+		}
+
+		read, err := readTaskLogStream(resp, handler)
+		offset += read
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "problem following logs for task '%s'", opts.TaskID)
+		}
+		if resp.Trailer.Get(evergreen.TaskLogCompleteTrailerKey) == "true" {
+			return nil
+		}
+	}
+}
+
+// readTaskLogStream passes each line of a followed log to the handler and
+// returns the number of lines read.
+func readTaskLogStream(resp *http.Response, handler func(model.APILogMessage) error) (int, error) {
+	defer resp.Body.Close()
+
+	count := 0
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		msg := model.APILogMessage{}
+		err := decoder.Decode(&msg)
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, errors.Wrap(err, "problem reading log line")
+		}
+		count++
+		if err = handler(msg); err != nil {
+			return count, errors.WithStack(err)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/rest/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFollowTaskLogsResumesUntilComplete(t *testing.T) {
+	assert := assert.New(t)
+
+	offsets := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal("/rest/v2/tasks/task/logs", r.URL.Path)
+		assert.Equal("true", r.URL.Query().Get("follow"))
+		assert.Equal("task", r.URL.Query().Get("type"))
+		offset := r.URL.Query().Get("offset")
+		offsets = append(offsets, offset)
+
+		w.Header().Set("Trailer", evergreen.TaskLogCompleteTrailerKey)
+		complete := "false"
+		if offset == "" {
+			fmt.Fprintln(w, `{"message": "one"}`)
+			fmt.Fprintln(w, `{"message": "two"}`)
+		} else {
+			fmt.Fprintln(w, `{"message": "three"}`)
+			complete = "true"
+		}
+		w.Header().Set(evergreen.TaskLogCompleteTrailerKey, complete)
+	}))
+	defer server.Close()
+
+	comm := NewCommunicator(server.URL)
+	defer comm.Close()
+
+	msgs := []string{}
+	err := comm.FollowTaskLogs(context.Background(), TaskLogOptions{
+		TaskID:    "task",
+		Execution: -1,
+		Types:     []string{"task"},
+	}, func(msg model.APILogMessage) error {
+		msgs = append(msgs, string(msg.Message))
+		return nil
+	})
+	assert.NoError(err)
+	assert.Equal([]string{"one", "two", "three"}, msgs)
+	assert.Equal([]string{"", "2"}, offsets)
+}
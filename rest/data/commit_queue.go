@@ -0,0 +1,158 @@
+package data
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/evergreen-ci/evergreen/model/commitqueue"
+	"github.com/evergreen-ci/evergreen/model/patch"
+	"github.com/evergreen-ci/evergreen/rest"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// DBCommitQueueConnector is a struct that implements the commit queue
+// related methods from the Connector through interactions with the
+// backing database.
+type DBCommitQueueConnector struct{}
+
+// FindCommitQueueByID returns the project's commit queue, which is empty
+// if nothing has been enqueued.
+func (cc *DBCommitQueueConnector) FindCommitQueueByID(projectID string) (*commitqueue.CommitQueue, error) {
+	cq, err := commitqueue.FindOneId(projectID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if cq == nil {
+		cq = &commitqueue.CommitQueue{ProjectID: projectID}
+	}
+	return cq, nil
+}
+
+// EnqueueItem adds the item to the end of the project's commit queue and
+// returns its position. Patches must be for the project and belong to the
+// user adding them.
+func (cc *DBCommitQueueConnector) EnqueueItem(projectID string, item commitqueue.CommitQueueItem) (int, error) {
+	if item.Type == commitqueue.PatchItemType {
+		p, err := patch.FindOne(patch.ById(bson.ObjectIdHex(item.Issue)))
+		if err != nil {
+			return -1, errors.Wrapf(err, "problem finding patch '%s'", item.Issue)
+		}
+		if p == nil {
+			return -1, &rest.APIError{
+				StatusCode: http.StatusNotFound,
+				Message:    fmt.Sprintf("patch with id %s not found", item.Issue),
+			}
+		}
+		if p.Project != projectID {
+			return -1, &rest.APIError{
+				StatusCode: http.StatusBadRequest,
+				Message:    fmt.Sprintf("patch %s is not for project %s", item.Issue, projectID),
+			}
+		}
+		if p.Author != item.Author {
+			return -1, &rest.APIError{
+				StatusCode: http.StatusForbidden,
+				Message:    fmt.Sprintf("patch %s does not belong to %s", item.Issue, item.Author),
+			}
+		}
+	}
+
+	cq, err := commitqueue.FindOneId(projectID)
+	if err != nil {
+		return -1, errors.WithStack(err)
+	}
+	if cq != nil && cq.FindItem(item.Issue) >= 0 {
+		return -1, &rest.APIError{
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("%s is already in the commit queue", item.Issue),
+		}
+	}
+
+	position, err := commitqueue.Enqueue(projectID, item)
+	if err != nil {
+		return -1, errors.WithStack(err)
+	}
+	return position, nil
+}
+
+// CommitQueueRemoveItem removes the item from the project's commit queue,
+// and reports whether it was queued.
+func (cc *DBCommitQueueConnector) CommitQueueRemoveItem(projectID, issue string) (bool, error) {
+	cq, err := commitqueue.FindOneId(projectID)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	if cq == nil {
+		return false, nil
+	}
+
+	removed, err := cq.Remove(issue)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return removed, nil
+}
+
+// MockCommitQueueConnector stores cached commit queues, by project id,
+// that are queried against by the implementations of the Connector
+// interface's commit queue related functions.
+type MockCommitQueueConnector struct {
+	CachedQueues map[string]*commitqueue.CommitQueue
+	StoredError  error
+}
+
+// FindCommitQueueByID returns the cached commit queue of the project.
+func (mcc *MockCommitQueueConnector) FindCommitQueueByID(projectID string) (*commitqueue.CommitQueue, error) {
+	if mcc.StoredError != nil {
+		return nil, mcc.StoredError
+	}
+
+	cq, ok := mcc.CachedQueues[projectID]
+	if !ok {
+		return &commitqueue.CommitQueue{ProjectID: projectID}, nil
+	}
+	return cq, nil
+}
+
+// EnqueueItem adds the item to the end of the cached commit queue.
+func (mcc *MockCommitQueueConnector) EnqueueItem(projectID string, item commitqueue.CommitQueueItem) (int, error) {
+	if mcc.StoredError != nil {
+		return -1, mcc.StoredError
+	}
+
+	if mcc.CachedQueues == nil {
+		mcc.CachedQueues = map[string]*commitqueue.CommitQueue{}
+	}
+	cq, ok := mcc.CachedQueues[projectID]
+	if !ok {
+		cq = &commitqueue.CommitQueue{ProjectID: projectID}
+		mcc.CachedQueues[projectID] = cq
+	}
+	if cq.FindItem(item.Issue) >= 0 {
+		return -1, &rest.APIError{
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("%s is already in the commit queue", item.Issue),
+		}
+	}
+	cq.Queue = append(cq.Queue, item)
+	return len(cq.Queue) - 1, nil
+}
+
+// CommitQueueRemoveItem removes the item from the cached commit queue.
+func (mcc *MockCommitQueueConnector) CommitQueueRemoveItem(projectID, issue string) (bool, error) {
+	if mcc.StoredError != nil {
+		return false, mcc.StoredError
+	}
+
+	cq, ok := mcc.CachedQueues[projectID]
+	if !ok {
+		return false, nil
+	}
+	i := cq.FindItem(issue)
+	if i < 0 {
+		return false, nil
+	}
+	cq.Queue = append(cq.Queue[:i], cq.Queue[i+1:]...)
+	return true, nil
+}
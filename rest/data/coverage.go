@@ -0,0 +1,124 @@
+package data
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/coverage"
+	"github.com/evergreen-ci/evergreen/model/version"
+	"github.com/evergreen-ci/evergreen/rest"
+	"github.com/pkg/errors"
+)
+
+// DBCoverageConnector is a struct that implements the coverage related
+// methods from the Connector through interactions with the backing
+// database.
+type DBCoverageConnector struct{}
+
+// FindTaskCoverage returns the merged coverage reported by a task
+// execution.
+func (cc *DBCoverageConnector) FindTaskCoverage(taskId string, execution int) ([]coverage.FileCoverage, error) {
+	files, err := coverage.FindByTaskIDAndExecution(taskId, execution)
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem finding coverage for task '%s'", taskId)
+	}
+	return coverage.Merge(files), nil
+}
+
+// FindVersionCoverage returns the merged coverage reported by the tasks of
+// a version.
+func (cc *DBCoverageConnector) FindVersionCoverage(versionId string) ([]coverage.FileCoverage, error) {
+	files, err := model.FindVersionCoverage(versionId)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return files, nil
+}
+
+// GetVersionCoverageDiff returns a diff of the coverage of a patch version
+// and its base version.
+func (cc *DBCoverageConnector) GetVersionCoverageDiff(versionId string) (*model.VersionCoverageDiff, error) {
+	v, err := version.FindOne(version.ById(versionId))
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem finding version '%s'", versionId)
+	}
+	if v == nil {
+		return nil, &rest.APIError{
+			StatusCode: http.StatusNotFound,
+			Message:    fmt.Sprintf("version with id %s not found", versionId),
+		}
+	}
+	if !evergreen.IsPatchRequester(v.Requester) {
+		return nil, &rest.APIError{
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("version %s is not a patch", versionId),
+		}
+	}
+
+	diff, err := model.CoverageDiffVersion(v)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return diff, nil
+}
+
+// MockCoverageConnector stores cached coverage, by task id, and coverage
+// diffs, by version id, that are queried against by the implementations
+// of the Connector interface's coverage related functions.
+type MockCoverageConnector struct {
+	CachedCoverage map[string][]coverage.FileCoverage
+	CachedDiffs    map[string]*model.VersionCoverageDiff
+	StoredError    error
+}
+
+// FindTaskCoverage returns the merged cached coverage of the task
+// execution.
+func (mcc *MockCoverageConnector) FindTaskCoverage(taskId string, execution int) ([]coverage.FileCoverage, error) {
+	if mcc.StoredError != nil {
+		return nil, mcc.StoredError
+	}
+
+	files := []coverage.FileCoverage{}
+	for _, f := range mcc.CachedCoverage[taskId] {
+		if f.Execution == execution {
+			files = append(files, f)
+		}
+	}
+	return coverage.Merge(files), nil
+}
+
+// FindVersionCoverage returns the merged cached coverage of the version's
+// tasks.
+func (mcc *MockCoverageConnector) FindVersionCoverage(versionId string) ([]coverage.FileCoverage, error) {
+	if mcc.StoredError != nil {
+		return nil, mcc.StoredError
+	}
+
+	files := []coverage.FileCoverage{}
+	for _, taskFiles := range mcc.CachedCoverage {
+		for _, f := range taskFiles {
+			if f.Version == versionId {
+				files = append(files, f)
+			}
+		}
+	}
+	return coverage.Merge(files), nil
+}
+
+// GetVersionCoverageDiff returns the cached diff of the version.
+func (mcc *MockCoverageConnector) GetVersionCoverageDiff(versionId string) (*model.VersionCoverageDiff, error) {
+	if mcc.StoredError != nil {
+		return nil, mcc.StoredError
+	}
+
+	diff, ok := mcc.CachedDiffs[versionId]
+	if !ok {
+		return nil, &rest.APIError{
+			StatusCode: http.StatusNotFound,
+			Message:    fmt.Sprintf("version with id %s not found", versionId),
+		}
+	}
+	return diff, nil
+}
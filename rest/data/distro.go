@@ -5,10 +5,13 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/distro"
 	"github.com/evergreen-ci/evergreen/model/task"
 	"github.com/evergreen-ci/evergreen/rest"
 	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2"
 )
 
 // DBDistroConnector is a struct that implements the Distro related methods
@@ -67,11 +70,35 @@ func (tc *DBDistroConnector) FindCostByDistroId(distroId string,
 	return &dc, nil
 }
 
+// FindProjectQuotaUsage returns the usage of the distro by each project,
+// against the project quotas of the distro with the given ID.
+func (dc *DBDistroConnector) FindProjectQuotaUsage(distroId string) ([]model.ProjectQuotaUsage, error) {
+	d, err := distro.FindOne(distro.ById(distroId))
+	if err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, &rest.APIError{
+				StatusCode: http.StatusNotFound,
+				Message:    fmt.Sprintf("distro with id '%s' not found", distroId),
+			}
+		}
+		return nil, errors.Wrapf(err, "error finding distro with id %s", distroId)
+	}
+
+	usage, err := model.FindProjectQuotaUsage(d)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return usage, nil
+}
+
 // MockDistroConnector is a struct that implements mock versions of
 // Distro-related methods for testing.
 type MockDistroConnector struct {
 	CachedDistros []distro.Distro
 	CachedTasks   []task.Task
+
+	// CachedQueuedTasks maps distro IDs to the tasks in their queues.
+	CachedQueuedTasks map[string][]task.Task
 }
 
 // FindAllDistros is a mock implementation for testing.
@@ -116,3 +143,31 @@ func (mdc *MockDistroConnector) FindCostByDistroId(distroId string,
 
 	return &dc, nil
 }
+
+// FindProjectQuotaUsage computes the project quota usage of a cached
+// distro from the cached tasks running on it and the cached queue.
+func (mdc *MockDistroConnector) FindProjectQuotaUsage(distroId string) ([]model.ProjectQuotaUsage, error) {
+	for _, d := range mdc.CachedDistros {
+		if d.Id != distroId {
+			continue
+		}
+
+		running := map[string]int{}
+		for _, t := range mdc.CachedTasks {
+			if t.DistroId == distroId && (t.Status == evergreen.TaskDispatched || t.Status == evergreen.TaskStarted) {
+				running[t.Project]++
+			}
+		}
+		queued := map[string]int{}
+		for _, t := range mdc.CachedQueuedTasks[distroId] {
+			queued[t.Project]++
+		}
+
+		return model.BuildProjectQuotaUsage(&d, running, queued), nil
+	}
+
+	return nil, &rest.APIError{
+		StatusCode: http.StatusNotFound,
+		Message:    fmt.Sprintf("distro with id '%s' not found", distroId),
+	}
+}
@@ -1,16 +1,27 @@
 package data
 
+import (
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/auth"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/role"
+)
+
 // DBConnector is a struct that implements all of the methods which
 // connect to the service layer of evergreen. These methods abstract the link
 // between the service and the API layers, allowing for changes in the
 // service architecture without forcing changes to the API.
 type DBConnector struct {
 	superUsers []string
+	rbac       evergreen.RBACConfig
 	URL        string
 	Prefix     string
 
 	DBUserConnector
 	DBTaskConnector
+	DBTaskLogConnector
+	DBCoverageConnector
+	DBCommitQueueConnector
 	DBContextConnector
 	DBDistroConnector
 	DBHostConnector
@@ -24,6 +35,8 @@ type DBConnector struct {
 	DBAdminConnector
 	DBStatusConnector
 	DBAliasConnector
+	DBQuarantineConnector
+	DBRoleConnector
 }
 
 func (ctx *DBConnector) GetSuperUsers() []string   { return ctx.superUsers }
@@ -33,13 +46,31 @@ func (ctx *DBConnector) SetURL(url string)         { ctx.URL = url }
 func (ctx *DBConnector) GetPrefix() string         { return ctx.Prefix }
 func (ctx *DBConnector) SetPrefix(prefix string)   { ctx.Prefix = prefix }
 
+func (ctx *DBConnector) GetRBACConfig() evergreen.RBACConfig     { return ctx.rbac }
+func (ctx *DBConnector) SetRBACConfig(conf evergreen.RBACConfig) { ctx.rbac = conf }
+
+// HasPermission checks the user's permission against the roles assigned
+// through the connector.
+func (ctx *DBConnector) HasPermission(u auth.User, p role.Permission, project *model.ProjectRef) (bool, error) {
+	authorizer := &auth.Authorizer{
+		SuperUsers: ctx.superUsers,
+		Config:     ctx.rbac,
+		Store:      &ctx.DBRoleConnector,
+	}
+	return authorizer.HasPermission(u, p, project)
+}
+
 type MockConnector struct {
 	superUsers []string
+	rbac       evergreen.RBACConfig
 	URL        string
 	Prefix     string
 
 	MockUserConnector
 	MockTaskConnector
+	MockTaskLogConnector
+	MockCoverageConnector
+	MockCommitQueueConnector
 	MockContextConnector
 	MockDistroConnector
 	MockHostConnector
@@ -53,6 +84,8 @@ type MockConnector struct {
 	MockAdminConnector
 	MockStatusConnector
 	MockAliasConnector
+	MockQuarantineConnector
+	MockRoleConnector
 }
 
 func (ctx *MockConnector) GetSuperUsers() []string   { return ctx.superUsers }
@@ -61,3 +94,17 @@ func (ctx *MockConnector) GetURL() string            { return ctx.URL }
 func (ctx *MockConnector) SetURL(url string)         { ctx.URL = url }
 func (ctx *MockConnector) GetPrefix() string         { return ctx.Prefix }
 func (ctx *MockConnector) SetPrefix(prefix string)   { ctx.Prefix = prefix }
+
+func (ctx *MockConnector) GetRBACConfig() evergreen.RBACConfig     { return ctx.rbac }
+func (ctx *MockConnector) SetRBACConfig(conf evergreen.RBACConfig) { ctx.rbac = conf }
+
+// HasPermission checks the user's permission against the roles assigned
+// through the connector.
+func (ctx *MockConnector) HasPermission(u auth.User, p role.Permission, project *model.ProjectRef) (bool, error) {
+	authorizer := &auth.Authorizer{
+		SuperUsers: ctx.superUsers,
+		Config:     ctx.rbac,
+		Store:      &ctx.MockRoleConnector,
+	}
+	return authorizer.HasPermission(u, p, project)
+}
@@ -3,13 +3,17 @@ package data
 import (
 	"time"
 
+	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/auth"
 	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/admin"
 	"github.com/evergreen-ci/evergreen/model/build"
+	"github.com/evergreen-ci/evergreen/model/commitqueue"
+	"github.com/evergreen-ci/evergreen/model/coverage"
 	"github.com/evergreen-ci/evergreen/model/distro"
 	"github.com/evergreen-ci/evergreen/model/host"
 	"github.com/evergreen-ci/evergreen/model/patch"
+	"github.com/evergreen-ci/evergreen/model/role"
 	"github.com/evergreen-ci/evergreen/model/task"
 	"github.com/evergreen-ci/evergreen/model/testresult"
 	"github.com/evergreen-ci/evergreen/model/user"
@@ -38,6 +42,14 @@ type Connector interface {
 	GetPrefix() string
 	SetPrefix(string)
 
+	// Get and Set RBACConfig provide access to the role-based access
+	// control settings.
+	GetRBACConfig() evergreen.RBACConfig
+	SetRBACConfig(evergreen.RBACConfig)
+	// HasPermission returns true if the user has the permission on the
+	// given project, or on the whole system if the project is nil.
+	HasPermission(auth.User, role.Permission, *model.ProjectRef) (bool, error)
+
 	// FindTaskById is a method to find a specific task given its ID.
 	FindTaskById(string) (*task.Task, error)
 	FindTasksByIds([]string) ([]task.Task, error)
@@ -46,6 +58,29 @@ type Connector interface {
 	ResetTask(string, string, *model.Project) error
 	AbortTask(string, string) error
 
+	// FindTaskLogs returns the chunks of a task execution's log in the
+	// order they were written, skipping the given number of chunks.
+	FindTaskLogs(string, int, int) ([]model.TaskLog, error)
+
+	// FindTaskCoverage returns the merged line coverage reported by a
+	// task execution.
+	FindTaskCoverage(string, int) ([]coverage.FileCoverage, error)
+	// FindVersionCoverage returns the merged line coverage reported by
+	// the latest executions of a version's tasks.
+	FindVersionCoverage(string) ([]coverage.FileCoverage, error)
+	// GetVersionCoverageDiff compares the coverage of a patch version
+	// with its base version.
+	GetVersionCoverageDiff(string) (*model.VersionCoverageDiff, error)
+
+	// FindCommitQueueByID returns a project's commit queue.
+	FindCommitQueueByID(string) (*commitqueue.CommitQueue, error)
+	// EnqueueItem adds an item to the end of a project's commit queue and
+	// returns its position.
+	EnqueueItem(string, commitqueue.CommitQueueItem) (int, error)
+	// CommitQueueRemoveItem removes an item from a project's commit queue
+	// and reports whether it was queued.
+	CommitQueueRemoveItem(string, string) (bool, error)
+
 	// FindTasksByBuildId is a method to find a set of tasks which all have the same
 	// BuildId. It takes the buildId being queried for as its first parameter,
 	// as well as a taskId and limit for paginating through the results.
@@ -103,6 +138,10 @@ type Connector interface {
 	// FindAllDistros is a method to find a sorted list of all distros.
 	FindAllDistros() ([]distro.Distro, error)
 
+	// FindProjectQuotaUsage returns each project's usage of the distro
+	// with the given ID, against the distro's project quotas.
+	FindProjectQuotaUsage(string) ([]model.ProjectQuotaUsage, error)
+
 	// FindTaskSystemMetrics and FindTaskProcessMetrics provide
 	// access to the metrics data collected by agents during task execution
 	FindTaskSystemMetrics(string, time.Time, int, int) ([]*message.SystemInfo, error)
@@ -173,4 +212,31 @@ type Connector interface {
 
 	// FindProjectAliases queries the database to find all aliases.
 	FindProjectAliases(string) ([]model.PatchDefinition, error)
+
+	// FindQuarantinedTests returns the tests quarantined in a project.
+	FindQuarantinedTests(string) ([]testresult.QuarantinedTest, error)
+	// AddQuarantinedTest adds a test to its project's quarantine list.
+	AddQuarantinedTest(*testresult.QuarantinedTest) error
+	// RemoveQuarantinedTest removes the given test from a project's
+	// quarantine list.
+	RemoveQuarantinedTest(string, string) error
+
+	// FindRoles returns the built-in and stored roles.
+	FindRoles() ([]role.Role, error)
+	// UpsertRole stores a role, replacing one with the same id.
+	UpsertRole(*role.Role) error
+	// DeleteRole removes a stored role and its assignments.
+	DeleteRole(string) error
+	// FindRoleAssignments returns the role assignments matching the
+	// given user, group and project, each of which may be empty.
+	FindRoleAssignments(string, string, string) ([]role.Assignment, error)
+	// FindAssignmentsForUser returns the role assignments to the given
+	// user and to any of the given groups.
+	FindAssignmentsForUser(string, []string) ([]role.Assignment, error)
+	// FindRoleAssignmentById returns the role assignment with the id.
+	FindRoleAssignmentById(string) (*role.Assignment, error)
+	// AddRoleAssignment assigns a role.
+	AddRoleAssignment(*role.Assignment) error
+	// RemoveRoleAssignment removes the role assignment with the id.
+	RemoveRoleAssignment(string) error
 }
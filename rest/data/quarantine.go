@@ -0,0 +1,83 @@
+package data
+
+import (
+	"time"
+
+	"github.com/evergreen-ci/evergreen/model/testresult"
+	"github.com/pkg/errors"
+)
+
+// DBQuarantineConnector is a struct that implements the test quarantine
+// related methods from the Connector through interactions with the
+// backing database.
+type DBQuarantineConnector struct{}
+
+// FindQuarantinedTests returns the tests quarantined in a project.
+func (qc *DBQuarantineConnector) FindQuarantinedTests(projectId string) ([]testresult.QuarantinedTest, error) {
+	tests, err := testresult.FindQuarantinedTests(projectId)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return tests, nil
+}
+
+// AddQuarantinedTest adds a test to its project's quarantine list, or
+// updates the reason for an already quarantined test.
+func (qc *DBQuarantineConnector) AddQuarantinedTest(test *testresult.QuarantinedTest) error {
+	return errors.WithStack(test.Upsert())
+}
+
+// RemoveQuarantinedTest removes a test from a project's quarantine list.
+func (qc *DBQuarantineConnector) RemoveQuarantinedTest(projectId, testFile string) error {
+	return errors.WithStack(testresult.RemoveQuarantinedTest(projectId, testFile))
+}
+
+// MockQuarantineConnector is a struct that implements the test
+// quarantine related methods from the Connector through a cached slice
+// of quarantined tests.
+type MockQuarantineConnector struct {
+	CachedQuarantinedTests []testresult.QuarantinedTest
+}
+
+// FindQuarantinedTests returns the cached tests for a project.
+func (qc *MockQuarantineConnector) FindQuarantinedTests(projectId string) ([]testresult.QuarantinedTest, error) {
+	tests := []testresult.QuarantinedTest{}
+	for _, t := range qc.CachedQuarantinedTests {
+		if t.Project == projectId {
+			tests = append(tests, t)
+		}
+	}
+	return tests, nil
+}
+
+// AddQuarantinedTest adds the test to the cache, replacing an existing
+// entry for the same test.
+func (qc *MockQuarantineConnector) AddQuarantinedTest(test *testresult.QuarantinedTest) error {
+	if test.Project == "" || test.TestFile == "" {
+		return errors.New("quarantined test must have a project and a test file")
+	}
+	if test.CreatedAt.IsZero() {
+		test.CreatedAt = time.Now()
+	}
+
+	for idx, t := range qc.CachedQuarantinedTests {
+		if t.Project == test.Project && t.TestFile == test.TestFile {
+			qc.CachedQuarantinedTests[idx].Reason = test.Reason
+			qc.CachedQuarantinedTests[idx].User = test.User
+			return nil
+		}
+	}
+	qc.CachedQuarantinedTests = append(qc.CachedQuarantinedTests, *test)
+	return nil
+}
+
+// RemoveQuarantinedTest removes the test from the cache.
+func (qc *MockQuarantineConnector) RemoveQuarantinedTest(projectId, testFile string) error {
+	for idx, t := range qc.CachedQuarantinedTests {
+		if t.Project == projectId && t.TestFile == testFile {
+			qc.CachedQuarantinedTests = append(qc.CachedQuarantinedTests[:idx], qc.CachedQuarantinedTests[idx+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package data
+
+import (
+	"time"
+
+	"github.com/evergreen-ci/evergreen/model/role"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// DBRoleConnector is a struct that implements the role related methods
+// from the Connector through interactions with the backing database.
+type DBRoleConnector struct{}
+
+// FindRoles returns the built-in and stored roles.
+func (rc *DBRoleConnector) FindRoles() ([]role.Role, error) {
+	roles, err := role.FindAll()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return roles, nil
+}
+
+// UpsertRole stores a role, replacing one with the same id.
+func (rc *DBRoleConnector) UpsertRole(r *role.Role) error {
+	return errors.WithStack(r.Upsert())
+}
+
+// DeleteRole removes a stored role and its assignments.
+func (rc *DBRoleConnector) DeleteRole(id string) error {
+	return errors.WithStack(role.Remove(id))
+}
+
+// FindRoleAssignments returns the role assignments matching each of the
+// non-empty filters.
+func (rc *DBRoleConnector) FindRoleAssignments(user, group, project string) ([]role.Assignment, error) {
+	assignments, err := role.FindAssignments(user, group, project)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return assignments, nil
+}
+
+// FindAssignmentsForUser returns the role assignments to the user and to
+// any of their groups.
+func (rc *DBRoleConnector) FindAssignmentsForUser(user string, groups []string) ([]role.Assignment, error) {
+	assignments, err := role.FindAssignmentsForUser(user, groups)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return assignments, nil
+}
+
+// FindRoleAssignmentById returns the role assignment with the id, or nil
+// if there is none.
+func (rc *DBRoleConnector) FindRoleAssignmentById(id string) (*role.Assignment, error) {
+	a, err := role.FindOneAssignment(id)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return a, nil
+}
+
+// AddRoleAssignment stores a new role assignment.
+func (rc *DBRoleConnector) AddRoleAssignment(a *role.Assignment) error {
+	return errors.WithStack(a.Insert())
+}
+
+// RemoveRoleAssignment removes the role assignment with the id.
+func (rc *DBRoleConnector) RemoveRoleAssignment(id string) error {
+	return errors.WithStack(role.RemoveAssignment(id))
+}
+
+// MockRoleConnector is a struct that implements the role related
+// methods from the Connector through cached slices of roles and
+// assignments.
+type MockRoleConnector struct {
+	CachedRoles       []role.Role
+	CachedAssignments []role.Assignment
+}
+
+// FindRoles returns the built-in roles followed by the cached ones.
+func (rc *MockRoleConnector) FindRoles() ([]role.Role, error) {
+	return append(role.BuiltInRoles(), rc.CachedRoles...), nil
+}
+
+// UpsertRole adds the role to the cache, replacing one with the same id.
+func (rc *MockRoleConnector) UpsertRole(r *role.Role) error {
+	if role.IsBuiltIn(r.Id) {
+		return errors.Errorf("can't change built-in role '%s'", r.Id)
+	}
+	if err := r.Validate(); err != nil {
+		return errors.WithStack(err)
+	}
+	for idx := range rc.CachedRoles {
+		if rc.CachedRoles[idx].Id == r.Id {
+			rc.CachedRoles[idx] = *r
+			return nil
+		}
+	}
+	rc.CachedRoles = append(rc.CachedRoles, *r)
+	return nil
+}
+
+// DeleteRole removes the role and its assignments from the cache.
+func (rc *MockRoleConnector) DeleteRole(id string) error {
+	if role.IsBuiltIn(id) {
+		return errors.Errorf("can't remove built-in role '%s'", id)
+	}
+	roles := []role.Role{}
+	for _, r := range rc.CachedRoles {
+		if r.Id != id {
+			roles = append(roles, r)
+		}
+	}
+	rc.CachedRoles = roles
+
+	assignments := []role.Assignment{}
+	for _, a := range rc.CachedAssignments {
+		if a.Role != id {
+			assignments = append(assignments, a)
+		}
+	}
+	rc.CachedAssignments = assignments
+	return nil
+}
+
+// FindRoleAssignments returns the cached assignments matching each of the
+// non-empty filters.
+func (rc *MockRoleConnector) FindRoleAssignments(user, group, project string) ([]role.Assignment, error) {
+	out := []role.Assignment{}
+	for _, a := range rc.CachedAssignments {
+		if (user != "" && a.User != user) || (group != "" && a.Group != group) ||
+			(project != "" && a.Project != project) {
+			continue
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+// FindAssignmentsForUser returns the cached assignments to the user and
+// to any of their groups.
+func (rc *MockRoleConnector) FindAssignmentsForUser(user string, groups []string) ([]role.Assignment, error) {
+	out := []role.Assignment{}
+	for _, a := range rc.CachedAssignments {
+		if a.User != "" && a.User == user {
+			out = append(out, a)
+			continue
+		}
+		for _, g := range groups {
+			if a.Group != "" && a.Group == g {
+				out = append(out, a)
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+// FindRoleAssignmentById returns the cached assignment with the id, or
+// nil if there is none.
+func (rc *MockRoleConnector) FindRoleAssignmentById(id string) (*role.Assignment, error) {
+	for _, a := range rc.CachedAssignments {
+		if a.Id.Hex() == id {
+			out := a
+			return &out, nil
+		}
+	}
+	return nil, nil
+}
+
+// AddRoleAssignment adds the assignment to the cache, giving it an id.
+func (rc *MockRoleConnector) AddRoleAssignment(a *role.Assignment) error {
+	if err := a.Validate(); err != nil {
+		return errors.WithStack(err)
+	}
+	roles, _ := rc.FindRoles()
+	if !roleExists(roles, a.Role) {
+		return errors.Errorf("role '%s' does not exist", a.Role)
+	}
+	a.Id = bson.NewObjectId()
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = time.Now()
+	}
+	rc.CachedAssignments = append(rc.CachedAssignments, *a)
+	return nil
+}
+
+// RemoveRoleAssignment removes the assignment from the cache.
+func (rc *MockRoleConnector) RemoveRoleAssignment(id string) error {
+	for idx, a := range rc.CachedAssignments {
+		if a.Id.Hex() == id {
+			rc.CachedAssignments = append(rc.CachedAssignments[:idx], rc.CachedAssignments[idx+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func roleExists(roles []role.Role, id string) bool {
+	for _, r := range roles {
+		if r.Id == id {
+			return true
+		}
+	}
+	return false
+}
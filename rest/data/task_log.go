@@ -0,0 +1,48 @@
+package data
+
+import (
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/pkg/errors"
+)
+
+// DBTaskLogConnector is a struct that implements the TaskLog related
+// methods from the Connector through interactions with the backing
+// database.
+type DBTaskLogConnector struct{}
+
+// FindTaskLogs returns the chunks of a task execution's log in the order
+// they were inserted, skipping the first skip chunks.
+func (tlc *DBTaskLogConnector) FindTaskLogs(taskId string, execution, skip int) ([]model.TaskLog, error) {
+	logs, err := model.FindTaskLogsInOrder(taskId, execution, skip, 0)
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem finding logs for task '%s'", taskId)
+	}
+	return logs, nil
+}
+
+// MockTaskLogConnector stores a cached set of log chunks, by task id, that
+// are queried against by the implementations of the Connector interface's
+// TaskLog related functions.
+type MockTaskLogConnector struct {
+	CachedLogs  map[string][]model.TaskLog
+	StoredError error
+}
+
+// FindTaskLogs returns the cached chunks of the task execution's log,
+// skipping the first skip chunks.
+func (mtlc *MockTaskLogConnector) FindTaskLogs(taskId string, execution, skip int) ([]model.TaskLog, error) {
+	if mtlc.StoredError != nil {
+		return nil, mtlc.StoredError
+	}
+
+	logs := []model.TaskLog{}
+	for _, l := range mtlc.CachedLogs[taskId] {
+		if l.Execution == execution {
+			logs = append(logs, l)
+		}
+	}
+	if skip >= len(logs) {
+		return []model.TaskLog{}, nil
+	}
+	return logs[skip:], nil
+}
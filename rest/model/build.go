@@ -9,8 +9,9 @@ import (
 )
 
 var (
-	commitOrigin = "commit"
-	patchOrigin  = "patch"
+	commitOrigin   = "commit"
+	patchOrigin    = "patch"
+	periodicOrigin = "periodic"
 )
 
 // APIBuild is the model to be returned by the API whenever builds are fetched.
@@ -69,6 +70,8 @@ func (apiBuild *APIBuild) BuildFromService(h interface{}) error {
 		origin = commitOrigin
 	} else if evergreen.IsPatchRequester(v.Requester) {
 		origin = patchOrigin
+	} else if v.Requester == evergreen.AdHocVersionRequester {
+		origin = periodicOrigin
 	}
 	apiBuild.Origin = APIString(origin)
 	return nil
@@ -0,0 +1,116 @@
+package model
+
+import (
+	"time"
+
+	"github.com/evergreen-ci/evergreen/model/commitqueue"
+	"github.com/pkg/errors"
+)
+
+// APICommitQueue is the model to be returned by the API for a project's
+// commit queue.
+type APICommitQueue struct {
+	ProjectID APIString            `json:"project_id"`
+	Queue     []APICommitQueueItem `json:"queue"`
+}
+
+// APICommitQueueItem is the model to be returned by the API for an item in
+// a commit queue.
+type APICommitQueueItem struct {
+	Issue       APIString `json:"issue"`
+	Type        APIString `json:"type"`
+	Author      APIString `json:"author"`
+	EnqueueTime APITime   `json:"enqueue_time"`
+	Version     APIString `json:"version"`
+}
+
+// APICommitQueuePosition is the position of an item that was added to a
+// commit queue, where 0 is the head of the queue.
+type APICommitQueuePosition struct {
+	Position int `json:"position"`
+}
+
+// BuildFromService converts from a service level commit queue to an
+// APICommitQueue.
+func (apiQueue *APICommitQueue) BuildFromService(h interface{}) error {
+	var v *commitqueue.CommitQueue
+	switch cq := h.(type) {
+	case commitqueue.CommitQueue:
+		v = &cq
+	case *commitqueue.CommitQueue:
+		v = cq
+	default:
+		return errors.Errorf("incorrect type when converting commit queue: %T", h)
+	}
+
+	apiQueue.ProjectID = APIString(v.ProjectID)
+	apiQueue.Queue = make([]APICommitQueueItem, 0, len(v.Queue))
+	for _, item := range v.Queue {
+		apiItem := APICommitQueueItem{}
+		if err := apiItem.BuildFromService(item); err != nil {
+			return errors.WithStack(err)
+		}
+		apiQueue.Queue = append(apiQueue.Queue, apiItem)
+	}
+	return nil
+}
+
+// ToService returns a service level commit queue using the data from the
+// APICommitQueue.
+func (apiQueue *APICommitQueue) ToService() (interface{}, error) {
+	cq := commitqueue.CommitQueue{
+		ProjectID: string(apiQueue.ProjectID),
+		Queue:     make([]commitqueue.CommitQueueItem, 0, len(apiQueue.Queue)),
+	}
+	for _, apiItem := range apiQueue.Queue {
+		item, err := apiItem.ToService()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		cq.Queue = append(cq.Queue, item.(commitqueue.CommitQueueItem))
+	}
+	return cq, nil
+}
+
+// BuildFromService converts from a service level commit queue item to an
+// APICommitQueueItem.
+func (apiItem *APICommitQueueItem) BuildFromService(h interface{}) error {
+	v, ok := h.(commitqueue.CommitQueueItem)
+	if !ok {
+		return errors.Errorf("incorrect type when converting commit queue item: %T", h)
+	}
+
+	apiItem.Issue = APIString(v.Issue)
+	apiItem.Type = APIString(v.Type)
+	apiItem.Author = APIString(v.Author)
+	apiItem.EnqueueTime = NewTime(v.EnqueueTime)
+	apiItem.Version = APIString(v.Version)
+	return nil
+}
+
+// ToService returns a service level commit queue item using the data from
+// the APICommitQueueItem.
+func (apiItem *APICommitQueueItem) ToService() (interface{}, error) {
+	return commitqueue.CommitQueueItem{
+		Issue:       string(apiItem.Issue),
+		Type:        string(apiItem.Type),
+		Author:      string(apiItem.Author),
+		EnqueueTime: time.Time(apiItem.EnqueueTime),
+		Version:     string(apiItem.Version),
+	}, nil
+}
+
+// BuildFromService sets the position from an int.
+func (apiPos *APICommitQueuePosition) BuildFromService(h interface{}) error {
+	position, ok := h.(int)
+	if !ok {
+		return errors.Errorf("incorrect type when converting commit queue position: %T", h)
+	}
+	apiPos.Position = position
+	return nil
+}
+
+// ToService returns the position as an int.
+func (apiPos *APICommitQueuePosition) ToService() (interface{}, error) {
+	return apiPos.Position, nil
+}
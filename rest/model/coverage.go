@@ -0,0 +1,136 @@
+package model
+
+import (
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/coverage"
+	"github.com/pkg/errors"
+)
+
+// APICoverageSummary counts the covered lines of a file or set of files.
+type APICoverageSummary struct {
+	Covered int     `json:"covered"`
+	Total   int     `json:"total"`
+	Percent float64 `json:"percent"`
+}
+
+func newAPICoverageSummary(s coverage.Summary) APICoverageSummary {
+	return APICoverageSummary{
+		Covered: s.Covered,
+		Total:   s.Total,
+		Percent: s.Percent(),
+	}
+}
+
+// APIFileCoverage is the model to be returned by the API for the line
+// coverage of a file.
+type APIFileCoverage struct {
+	File APIString `json:"file"`
+	APICoverageSummary
+	CoveredLines   []int `json:"covered_lines,omitempty"`
+	UncoveredLines []int `json:"uncovered_lines,omitempty"`
+}
+
+// BuildFromService converts from service level file coverage to an
+// APIFileCoverage.
+func (apiCov *APIFileCoverage) BuildFromService(h interface{}) error {
+	var v *coverage.FileCoverage
+	switch f := h.(type) {
+	case coverage.FileCoverage:
+		v = &f
+	case *coverage.FileCoverage:
+		v = f
+	default:
+		return errors.Errorf("incorrect type when converting file coverage: %T", h)
+	}
+
+	apiCov.File = APIString(v.File)
+	apiCov.APICoverageSummary = newAPICoverageSummary(v.Summary())
+	apiCov.CoveredLines = v.Covered
+	apiCov.UncoveredLines = v.Uncovered
+	return nil
+}
+
+// ToService returns service level file coverage using the data from the
+// APIFileCoverage.
+func (apiCov *APIFileCoverage) ToService() (interface{}, error) {
+	return coverage.FileCoverage{
+		File:      string(apiCov.File),
+		Covered:   apiCov.CoveredLines,
+		Uncovered: apiCov.UncoveredLines,
+	}, nil
+}
+
+// APIFileCoverageDiff is the coverage of a file in a patch and in its base
+// version. Delta is the change in the percentage of covered lines.
+type APIFileCoverageDiff struct {
+	File     APIString          `json:"file"`
+	Original APICoverageSummary `json:"original"`
+	Patch    APICoverageSummary `json:"patch"`
+	Delta    float64            `json:"delta"`
+}
+
+func newAPIFileCoverageDiffs(diffs []model.FileCoverageDiff) []APIFileCoverageDiff {
+	out := make([]APIFileCoverageDiff, 0, len(diffs))
+	for i := range diffs {
+		out = append(out, APIFileCoverageDiff{
+			File:     APIString(diffs[i].File),
+			Original: newAPICoverageSummary(diffs[i].Original),
+			Patch:    newAPICoverageSummary(diffs[i].Patch),
+			Delta:    diffs[i].Delta(),
+		})
+	}
+	return out
+}
+
+// APITaskCoverageDiff is the coverage diff of a patch task and the same
+// task in the patch's base version.
+type APITaskCoverageDiff struct {
+	DisplayName    APIString             `json:"display_name"`
+	BuildVariant   APIString             `json:"build_variant"`
+	OriginalTaskId APIString             `json:"original_task_id"`
+	PatchTaskId    APIString             `json:"patch_task_id"`
+	Files          []APIFileCoverageDiff `json:"files"`
+}
+
+// APIVersionCoverageDiff is the model to be returned by the API when the
+// coverage of a patch is compared with its base version.
+type APIVersionCoverageDiff struct {
+	VersionId     APIString             `json:"version_id"`
+	BaseVersionId APIString             `json:"base_version_id"`
+	Files         []APIFileCoverageDiff `json:"files"`
+	Tasks         []APITaskCoverageDiff `json:"tasks"`
+}
+
+// BuildFromService converts from a service level coverage diff to an
+// APIVersionCoverageDiff.
+func (apiDiff *APIVersionCoverageDiff) BuildFromService(h interface{}) error {
+	var v *model.VersionCoverageDiff
+	switch d := h.(type) {
+	case model.VersionCoverageDiff:
+		v = &d
+	case *model.VersionCoverageDiff:
+		v = d
+	default:
+		return errors.Errorf("incorrect type when converting coverage diff: %T", h)
+	}
+
+	apiDiff.VersionId = APIString(v.Version)
+	apiDiff.BaseVersionId = APIString(v.BaseVersion)
+	apiDiff.Files = newAPIFileCoverageDiffs(v.Files)
+	apiDiff.Tasks = make([]APITaskCoverageDiff, 0, len(v.Tasks))
+	for _, t := range v.Tasks {
+		apiDiff.Tasks = append(apiDiff.Tasks, APITaskCoverageDiff{
+			DisplayName:    APIString(t.Name),
+			BuildVariant:   APIString(t.BuildVariant),
+			OriginalTaskId: APIString(t.Original),
+			PatchTaskId:    APIString(t.Patch),
+			Files:          newAPIFileCoverageDiffs(t.Files),
+		})
+	}
+	return nil
+}
+
+// ToService is not implemented for APIVersionCoverageDiff.
+func (apiDiff *APIVersionCoverageDiff) ToService() (interface{}, error) {
+	return nil, errors.New("ToService not implemented for APIVersionCoverageDiff")
+}
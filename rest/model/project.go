@@ -22,6 +22,12 @@ type APIProject struct {
 	DeactivatePrevious bool                     `json:"deactivate_previous"`
 	Admins             []APIString              `json:"admins"`
 	Vars               map[string]string        `json:"vars"`
+	CommitQueue        APICommitQueueParams     `json:"commit_queue"`
+}
+
+type APICommitQueueParams struct {
+	Enabled     bool      `json:"enabled"`
+	MergeMethod APIString `json:"merge_method"`
 }
 
 type alertConfig struct {
@@ -60,6 +66,10 @@ func (apiProject *APIProject) BuildFromService(p interface{}) error {
 
 	apiProject.AlertSettings = alertSettings
 	apiProject.DeactivatePrevious = v.DeactivatePrevious
+	apiProject.CommitQueue = APICommitQueueParams{
+		Enabled:     v.CommitQueue.Enabled,
+		MergeMethod: APIString(v.CommitQueue.MergeMethod),
+	}
 
 	admins := []APIString{}
 	for _, a := range v.Admins {
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/pkg/errors"
+)
+
+// APIProjectQuotaUsage is the model to be returned by the API whenever
+// the project quota usage of a distro is fetched.
+type APIProjectQuotaUsage struct {
+	Project    APIString `json:"project_id"`
+	Guaranteed int       `json:"guaranteed"`
+	Ceiling    int       `json:"ceiling"`
+	Running    int       `json:"running"`
+	Queued     int       `json:"queued"`
+}
+
+// BuildFromService converts from a service level project quota usage to
+// an APIProjectQuotaUsage.
+func (apiUsage *APIProjectQuotaUsage) BuildFromService(h interface{}) error {
+	var v *model.ProjectQuotaUsage
+	switch u := h.(type) {
+	case model.ProjectQuotaUsage:
+		v = &u
+	case *model.ProjectQuotaUsage:
+		v = u
+	default:
+		return errors.Errorf("incorrect type when converting project quota usage: %T", h)
+	}
+
+	apiUsage.Project = APIString(v.Project)
+	apiUsage.Guaranteed = v.Guaranteed
+	apiUsage.Ceiling = v.Ceiling
+	apiUsage.Running = v.Running
+	apiUsage.Queued = v.Queued
+	return nil
+}
+
+// ToService is not implemented for APIProjectQuotaUsage, since usage is
+// only ever reported.
+func (apiUsage *APIProjectQuotaUsage) ToService() (interface{}, error) {
+	return nil, errors.New("ToService() is not implemented for APIProjectQuotaUsage")
+}
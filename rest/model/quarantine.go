@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"github.com/evergreen-ci/evergreen/model/testresult"
+	"github.com/pkg/errors"
+)
+
+// APIQuarantinedTest is the model to be returned by the API whenever a
+// project's quarantined tests are fetched.
+type APIQuarantinedTest struct {
+	Project   APIString `json:"project_id"`
+	TestFile  APIString `json:"test_file"`
+	Reason    APIString `json:"reason"`
+	User      APIString `json:"user"`
+	CreatedAt APITime   `json:"created_at"`
+}
+
+// BuildFromService converts from a service level quarantined test to an
+// APIQuarantinedTest.
+func (apiTest *APIQuarantinedTest) BuildFromService(h interface{}) error {
+	var v *testresult.QuarantinedTest
+	switch t := h.(type) {
+	case testresult.QuarantinedTest:
+		v = &t
+	case *testresult.QuarantinedTest:
+		v = t
+	default:
+		return errors.Errorf("incorrect type when converting quarantined test: %T", h)
+	}
+
+	apiTest.Project = APIString(v.Project)
+	apiTest.TestFile = APIString(v.TestFile)
+	apiTest.Reason = APIString(v.Reason)
+	apiTest.User = APIString(v.User)
+	apiTest.CreatedAt = NewTime(v.CreatedAt)
+	return nil
+}
+
+// ToService returns a service layer quarantined test using the data
+// from the APIQuarantinedTest.
+func (apiTest *APIQuarantinedTest) ToService() (interface{}, error) {
+	return &testresult.QuarantinedTest{
+		Project:   string(apiTest.Project),
+		TestFile:  string(apiTest.TestFile),
+		Reason:    string(apiTest.Reason),
+		User:      string(apiTest.User),
+		CreatedAt: time.Time(apiTest.CreatedAt),
+	}, nil
+}
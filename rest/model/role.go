@@ -0,0 +1,106 @@
+package model
+
+import (
+	"time"
+
+	"github.com/evergreen-ci/evergreen/model/role"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// APIRole is the model to be returned by the API whenever roles are
+// fetched.
+type APIRole struct {
+	Id          APIString   `json:"id"`
+	Name        APIString   `json:"name"`
+	Permissions []APIString `json:"permissions"`
+	BuiltIn     bool        `json:"built_in"`
+}
+
+// BuildFromService converts from a service level role to an APIRole.
+func (apiRole *APIRole) BuildFromService(h interface{}) error {
+	var v *role.Role
+	switch r := h.(type) {
+	case role.Role:
+		v = &r
+	case *role.Role:
+		v = r
+	default:
+		return errors.Errorf("incorrect type when converting role: %T", h)
+	}
+
+	apiRole.Id = APIString(v.Id)
+	apiRole.Name = APIString(v.Name)
+	apiRole.Permissions = make([]APIString, len(v.Permissions))
+	for i, p := range v.Permissions {
+		apiRole.Permissions[i] = APIString(p)
+	}
+	apiRole.BuiltIn = role.IsBuiltIn(v.Id)
+	return nil
+}
+
+// ToService returns a service layer role using the data from the
+// APIRole.
+func (apiRole *APIRole) ToService() (interface{}, error) {
+	r := &role.Role{
+		Id:          string(apiRole.Id),
+		Name:        string(apiRole.Name),
+		Permissions: make([]role.Permission, len(apiRole.Permissions)),
+	}
+	for i, p := range apiRole.Permissions {
+		r.Permissions[i] = role.Permission(p)
+	}
+	return r, nil
+}
+
+// APIRoleAssignment is the model to be returned by the API whenever role
+// assignments are fetched.
+type APIRoleAssignment struct {
+	Id        APIString `json:"id"`
+	Role      APIString `json:"role"`
+	User      APIString `json:"user"`
+	Group     APIString `json:"group"`
+	Project   APIString `json:"project_id"`
+	CreatedAt APITime   `json:"created_at"`
+	CreatedBy APIString `json:"created_by"`
+}
+
+// BuildFromService converts from a service level role assignment to an
+// APIRoleAssignment.
+func (apiAssignment *APIRoleAssignment) BuildFromService(h interface{}) error {
+	var v *role.Assignment
+	switch a := h.(type) {
+	case role.Assignment:
+		v = &a
+	case *role.Assignment:
+		v = a
+	default:
+		return errors.Errorf("incorrect type when converting role assignment: %T", h)
+	}
+
+	apiAssignment.Id = APIString(v.Id.Hex())
+	apiAssignment.Role = APIString(v.Role)
+	apiAssignment.User = APIString(v.User)
+	apiAssignment.Group = APIString(v.Group)
+	apiAssignment.Project = APIString(v.Project)
+	apiAssignment.CreatedAt = NewTime(v.CreatedAt)
+	apiAssignment.CreatedBy = APIString(v.CreatedBy)
+	return nil
+}
+
+// ToService returns a service layer role assignment using the data from
+// the APIRoleAssignment.
+func (apiAssignment *APIRoleAssignment) ToService() (interface{}, error) {
+	a := &role.Assignment{
+		Role:      string(apiAssignment.Role),
+		User:      string(apiAssignment.User),
+		Group:     string(apiAssignment.Group),
+		Project:   string(apiAssignment.Project),
+		CreatedAt: time.Time(apiAssignment.CreatedAt),
+		CreatedBy: string(apiAssignment.CreatedBy),
+	}
+	if bson.IsObjectIdHex(string(apiAssignment.Id)) {
+		a.Id = bson.ObjectIdHex(string(apiAssignment.Id))
+	}
+	return a, nil
+}
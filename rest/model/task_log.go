@@ -0,0 +1,98 @@
+package model
+
+import (
+	"time"
+
+	"github.com/evergreen-ci/evergreen/apimodels"
+	"github.com/pkg/errors"
+)
+
+var (
+	// the names the API uses for the stored log types and severities
+	logTypeNames = map[string]string{
+		apimodels.SystemLogPrefix: "system",
+		apimodels.AgentLogPrefix:  "agent",
+		apimodels.TaskLogPrefix:   "task",
+	}
+	logSeverityNames = map[string]string{
+		apimodels.LogErrorPrefix: "error",
+		apimodels.LogWarnPrefix:  "warning",
+		apimodels.LogDebugPrefix: "debug",
+		apimodels.LogInfoPrefix:  "info",
+	}
+)
+
+// APILogMessage is the model to be returned by the API whenever a line of
+// a task's log is fetched.
+type APILogMessage struct {
+	Type      APIString `json:"type"`
+	Severity  APIString `json:"severity"`
+	Message   APIString `json:"message"`
+	Timestamp APITime   `json:"timestamp"`
+}
+
+// BuildFromService converts from a service level log message to an
+// APILogMessage.
+func (apiMsg *APILogMessage) BuildFromService(h interface{}) error {
+	var v *apimodels.LogMessage
+	switch m := h.(type) {
+	case apimodels.LogMessage:
+		v = &m
+	case *apimodels.LogMessage:
+		v = m
+	default:
+		return errors.Errorf("incorrect type when converting log message: %T", h)
+	}
+
+	apiMsg.Type = APIString(v.Type)
+	if name, ok := logTypeNames[v.Type]; ok {
+		apiMsg.Type = APIString(name)
+	}
+	apiMsg.Severity = APIString(v.Severity)
+	if name, ok := logSeverityNames[v.Severity]; ok {
+		apiMsg.Severity = APIString(name)
+	}
+	apiMsg.Message = APIString(v.Message)
+	apiMsg.Timestamp = NewTime(v.Timestamp)
+	return nil
+}
+
+// ToService returns a service layer log message using the data from the
+// APILogMessage.
+func (apiMsg *APILogMessage) ToService() (interface{}, error) {
+	msgType, err := LogTypePrefix(string(apiMsg.Type))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	severity, err := LogSeverityPrefix(string(apiMsg.Severity))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &apimodels.LogMessage{
+		Type:      msgType,
+		Severity:  severity,
+		Message:   string(apiMsg.Message),
+		Timestamp: time.Time(apiMsg.Timestamp),
+	}, nil
+}
+
+// LogTypePrefix returns the stored form of a log type, given either its
+// name, e.g. "task", or its stored form.
+func LogTypePrefix(name string) (string, error) {
+	return logPrefix(logTypeNames, "type", name)
+}
+
+// LogSeverityPrefix returns the stored form of a log severity, given
+// either its name, e.g. "error", or its stored form.
+func LogSeverityPrefix(name string) (string, error) {
+	return logPrefix(logSeverityNames, "severity", name)
+}
+
+func logPrefix(names map[string]string, kind, name string) (string, error) {
+	for prefix, n := range names {
+		if name == n || name == prefix {
+			return prefix, nil
+		}
+	}
+	return "", errors.Errorf("'%s' is not a valid log %s", name, kind)
+}
@@ -18,6 +18,9 @@ type APITest struct {
 	ExitCode  int       `json:"exit_code"`
 	StartTime APITime   `json:"start_time"`
 	EndTime   APITime   `json:"end_time"`
+
+	Quarantined bool `json:"quarantined"`
+	Flaky       bool `json:"flaky"`
 }
 
 // TestLogs is a struct for storing the information about logs that will
@@ -35,6 +38,8 @@ func (at *APITest) BuildFromService(st interface{}) error {
 		at.Status = APIString(v.Status)
 		at.TestFile = APIString(v.TestFile)
 		at.ExitCode = v.ExitCode
+		at.Quarantined = v.Quarantined
+		at.Flaky = v.Flaky
 
 		startTime := util.FromPythonTime(v.StartTime)
 		endTime := util.FromPythonTime(v.EndTime)
@@ -58,14 +63,16 @@ func (at *APITest) BuildFromService(st interface{}) error {
 
 func (at *APITest) ToService() (interface{}, error) {
 	return &testresult.TestResult{
-		Status:    string(at.Status),
-		TestFile:  string(at.TestFile),
-		URL:       string(at.Logs.URL),
-		URLRaw:    string(at.Logs.URLRaw),
-		LogID:     string(at.Logs.LogId),
-		LineNum:   at.Logs.LineNum,
-		ExitCode:  at.ExitCode,
-		StartTime: util.ToPythonTime(time.Time(at.StartTime)),
-		EndTime:   util.ToPythonTime(time.Time(at.EndTime)),
+		Status:      string(at.Status),
+		TestFile:    string(at.TestFile),
+		URL:         string(at.Logs.URL),
+		URLRaw:      string(at.Logs.URLRaw),
+		LogID:       string(at.Logs.LogId),
+		LineNum:     at.Logs.LineNum,
+		ExitCode:    at.ExitCode,
+		StartTime:   util.ToPythonTime(time.Time(at.StartTime)),
+		EndTime:     util.ToPythonTime(time.Time(at.EndTime)),
+		Quarantined: at.Quarantined,
+		Flaky:       at.Flaky,
 	}, nil
 }
@@ -2,13 +2,16 @@ package route
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 
 	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/auth"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/role"
 	"github.com/evergreen-ci/evergreen/rest"
 	"github.com/evergreen-ci/evergreen/rest/data"
-	"github.com/evergreen-ci/evergreen/util"
+	"github.com/pkg/errors"
 )
 
 // Authenticator is an interface which defines how requests can authenticate
@@ -59,8 +62,11 @@ func (p *ProjectAdminAuthenticator) Authenticate(ctx context.Context, sc data.Co
 	u := GetUser(ctx)
 
 	// If either a superuser or admin, request is allowed to proceed.
-	if auth.IsSuperUser(sc.GetSuperUsers(), u) ||
-		util.StringSliceContains(projCtx.ProjectRef.Admins, u.Username()) {
+	if auth.IsSuperUser(sc.GetSuperUsers(), u) {
+		return nil
+	}
+	if projCtx.ProjectRef != nil &&
+		auth.IsProjectAdmin(projCtx.ProjectRef.Identifier, projCtx.ProjectRef.Admins, u) {
 		return nil
 	}
 
@@ -88,6 +94,42 @@ func (rua *RequireUserAuthenticator) Authenticate(ctx context.Context, sc data.C
 	return nil
 }
 
+// PermissionAuthenticator only allows users with the permission to
+// complete the request. Project permissions are checked against the
+// project context's project, when PrefetchProjectContext has set one.
+type PermissionAuthenticator struct {
+	Permission role.Permission
+}
+
+// Authenticate checks the user's roles for the permission. Anonymous
+// requests get 'NotFound' errors, as with the other authenticators, while
+// users without the permission are told they are forbidden.
+func (p *PermissionAuthenticator) Authenticate(ctx context.Context, sc data.Connector) error {
+	u := GetUser(ctx)
+	if u == nil {
+		return rest.APIError{
+			StatusCode: http.StatusNotFound,
+			Message:    "Not found",
+		}
+	}
+
+	var projectRef *model.ProjectRef
+	if projCtx := GetProjectContext(ctx); projCtx != nil {
+		projectRef = projCtx.ProjectRef
+	}
+	allowed, err := sc.HasPermission(u, p.Permission, projectRef)
+	if err != nil {
+		return errors.Wrap(err, "problem checking permissions")
+	}
+	if !allowed {
+		return rest.APIError{
+			StatusCode: http.StatusForbidden,
+			Message:    fmt.Sprintf("user '%s' does not have permission '%s'", u.Username(), p.Permission),
+		}
+	}
+	return nil
+}
+
 func validPriority(priority int64, user auth.User, sc data.Connector) bool {
 	if priority > evergreen.MaxTaskPriority {
 		return auth.IsSuperUser(sc.GetSuperUsers(), user)
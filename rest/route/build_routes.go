@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model/role"
 	"github.com/evergreen-ci/evergreen/rest"
 	"github.com/evergreen-ci/evergreen/rest/data"
 	"github.com/evergreen-ci/evergreen/rest/model"
@@ -30,13 +31,14 @@ func getBuildByIdRouteManager(route string, version int) *RouteManager {
 		Version: version,
 		Methods: []MethodHandler{
 			{
-				Authenticator:  &NoAuthAuthenticator{},
-				RequestHandler: &buildGetHandler{},
-				MethodType:     http.MethodGet,
+				PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
+				Authenticator:     &NoAuthAuthenticator{},
+				RequestHandler:    &buildGetHandler{},
+				MethodType:        http.MethodGet,
 			},
 			{
-				PrefetchFunctions: []PrefetchFunc{PrefetchUser},
-				Authenticator:     &RequireUserAuthenticator{},
+				PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
+				Authenticator:     &PermissionAuthenticator{Permission: role.TaskRestart},
 				RequestHandler:    &buildChangeStatusHandler{},
 				MethodType:        http.MethodPatch,
 			},
@@ -169,8 +171,8 @@ func getBuildAbortRouteManager(route string, version int) *RouteManager {
 		Version: version,
 		Methods: []MethodHandler{
 			{
-				PrefetchFunctions: []PrefetchFunc{PrefetchUser},
-				Authenticator:     &RequireUserAuthenticator{},
+				PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
+				Authenticator:     &PermissionAuthenticator{Permission: role.TaskRestart},
 				RequestHandler:    (&buildAbortHandler{}).Handler(),
 				MethodType:        http.MethodPost,
 			},
@@ -234,8 +236,8 @@ func getBuildRestartManager(route string, version int) *RouteManager {
 		Version: version,
 		Methods: []MethodHandler{
 			{
-				PrefetchFunctions: []PrefetchFunc{PrefetchUser},
-				Authenticator:     &RequireUserAuthenticator{},
+				PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
+				Authenticator:     &PermissionAuthenticator{Permission: role.TaskRestart},
 				RequestHandler:    (&buildRestartHandler{}).Handler(),
 				MethodType:        http.MethodPost,
 			},
@@ -0,0 +1,277 @@
+package route
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	serviceModel "github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/commitqueue"
+	"github.com/evergreen-ci/evergreen/model/role"
+	"github.com/evergreen-ci/evergreen/model/user"
+	"github.com/evergreen-ci/evergreen/rest"
+	"github.com/evergreen-ci/evergreen/rest/data"
+	"github.com/evergreen-ci/evergreen/rest/model"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+)
+
+////////////////////////////////////////////////////////////////////////
+//
+// Handler for fetching a project's commit queue
+//
+//    /commit_queue/{project_id}
+
+func getCommitQueueRouteManager(route string, version int) *RouteManager {
+	return &RouteManager{
+		Route:   route,
+		Version: version,
+		Methods: []MethodHandler{
+			{
+				PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
+				Authenticator:     &NoAuthAuthenticator{},
+				RequestHandler:    &commitQueueGetHandler{},
+				MethodType:        http.MethodGet,
+			},
+		},
+	}
+}
+
+// commitQueueGetHandler is the RequestHandler for the items in a project's
+// commit queue, in the order they will be merged.
+type commitQueueGetHandler struct {
+	projectID string
+}
+
+func (h *commitQueueGetHandler) Handler() RequestHandler {
+	return &commitQueueGetHandler{}
+}
+
+func (h *commitQueueGetHandler) ParseAndValidate(ctx context.Context, r *http.Request) error {
+	projectRef, err := getCommitQueueProjectRef(ctx, r)
+	if err != nil {
+		return err
+	}
+	h.projectID = projectRef.Identifier
+	return nil
+}
+
+func (h *commitQueueGetHandler) Execute(ctx context.Context, sc data.Connector) (ResponseData, error) {
+	cq, err := sc.FindCommitQueueByID(h.projectID)
+	if err != nil {
+		if _, ok := err.(*rest.APIError); !ok {
+			err = errors.Wrap(err, "Database error")
+		}
+		return ResponseData{}, err
+	}
+
+	cqModel := &model.APICommitQueue{}
+	if err = cqModel.BuildFromService(cq); err != nil {
+		return ResponseData{}, errors.Wrap(err, "API model error")
+	}
+	return ResponseData{
+		Result: []model.Model{cqModel},
+	}, nil
+}
+
+////////////////////////////////////////////////////////////////////////
+//
+// Handlers for adding an item to and removing an item from a project's
+// commit queue. Items are pull request numbers or patch ids.
+//
+//    /commit_queue/{project_id}/{item}
+
+func getCommitQueueItemRouteManager(route string, version int) *RouteManager {
+	return &RouteManager{
+		Route:   route,
+		Version: version,
+		Methods: []MethodHandler{
+			{
+				PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
+				Authenticator:     &PermissionAuthenticator{Permission: role.PatchSubmit},
+				RequestHandler:    &commitQueueEnqueueItemHandler{},
+				MethodType:        http.MethodPut,
+			},
+			{
+				PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
+				Authenticator:     &PermissionAuthenticator{Permission: role.PatchSubmit},
+				RequestHandler:    &commitQueueDeleteItemHandler{},
+				MethodType:        http.MethodDelete,
+			},
+		},
+	}
+}
+
+// commitQueueEnqueueItemHandler is the RequestHandler for adding a pull
+// request or patch to the end of a project's commit queue.
+type commitQueueEnqueueItemHandler struct {
+	projectRef *serviceModel.ProjectRef
+	item       commitqueue.CommitQueueItem
+	user       *user.DBUser
+}
+
+func (h *commitQueueEnqueueItemHandler) Handler() RequestHandler {
+	return &commitQueueEnqueueItemHandler{}
+}
+
+func (h *commitQueueEnqueueItemHandler) ParseAndValidate(ctx context.Context, r *http.Request) error {
+	projectRef, err := getCommitQueueProjectRef(ctx, r)
+	if err != nil {
+		return err
+	}
+	if !projectRef.CommitQueue.Enabled {
+		return rest.APIError{
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("commit queue is not enabled for project %s", projectRef.Identifier),
+		}
+	}
+	h.projectRef = projectRef
+	h.user = MustHaveUser(ctx)
+
+	issue := mux.Vars(r)["item"]
+	itemType, err := commitQueueItemType(issue)
+	if err != nil {
+		return err
+	}
+	h.item = commitqueue.CommitQueueItem{
+		Issue:       issue,
+		Type:        itemType,
+		Author:      h.user.Username(),
+		EnqueueTime: time.Now(),
+	}
+	return nil
+}
+
+func (h *commitQueueEnqueueItemHandler) Execute(ctx context.Context, sc data.Connector) (ResponseData, error) {
+	// patches are pushed without review, unlike pull requests
+	if h.item.Type == commitqueue.PatchItemType {
+		allowed, err := sc.HasPermission(h.user, role.ProjectSettings, h.projectRef)
+		if err != nil {
+			return ResponseData{}, errors.Wrap(err, "problem checking permissions")
+		}
+		if !allowed {
+			return ResponseData{}, rest.APIError{
+				StatusCode: http.StatusForbidden,
+				Message:    fmt.Sprintf("only users who can change the settings of project %s can add patches to its commit queue", h.projectRef.Identifier),
+			}
+		}
+	}
+
+	position, err := sc.EnqueueItem(h.projectRef.Identifier, h.item)
+	if err != nil {
+		if _, ok := err.(*rest.APIError); !ok {
+			err = errors.Wrap(err, "Database error")
+		}
+		return ResponseData{}, err
+	}
+
+	positionModel := &model.APICommitQueuePosition{}
+	if err = positionModel.BuildFromService(position); err != nil {
+		return ResponseData{}, errors.Wrap(err, "API model error")
+	}
+	return ResponseData{
+		Result: []model.Model{positionModel},
+	}, nil
+}
+
+// commitQueueDeleteItemHandler is the RequestHandler for removing an item
+// from a project's commit queue. Users can remove the items they added,
+// while project admins can remove any item.
+type commitQueueDeleteItemHandler struct {
+	projectRef *serviceModel.ProjectRef
+	issue      string
+	user       *user.DBUser
+}
+
+func (h *commitQueueDeleteItemHandler) Handler() RequestHandler {
+	return &commitQueueDeleteItemHandler{}
+}
+
+func (h *commitQueueDeleteItemHandler) ParseAndValidate(ctx context.Context, r *http.Request) error {
+	var err error
+	h.projectRef, err = getCommitQueueProjectRef(ctx, r)
+	if err != nil {
+		return err
+	}
+	h.issue = mux.Vars(r)["item"]
+	h.user = MustHaveUser(ctx)
+	return nil
+}
+
+func (h *commitQueueDeleteItemHandler) Execute(ctx context.Context, sc data.Connector) (ResponseData, error) {
+	cq, err := sc.FindCommitQueueByID(h.projectRef.Identifier)
+	if err != nil {
+		if _, ok := err.(*rest.APIError); !ok {
+			err = errors.Wrap(err, "Database error")
+		}
+		return ResponseData{}, err
+	}
+
+	i := cq.FindItem(h.issue)
+	if i < 0 {
+		return ResponseData{}, rest.APIError{
+			StatusCode: http.StatusNotFound,
+			Message:    fmt.Sprintf("%s is not in the commit queue", h.issue),
+		}
+	}
+	if cq.Queue[i].Author != h.user.Username() {
+		allowed, err := sc.HasPermission(h.user, role.ProjectSettings, h.projectRef)
+		if err != nil {
+			return ResponseData{}, errors.Wrap(err, "problem checking permissions")
+		}
+		if !allowed {
+			return ResponseData{}, rest.APIError{
+				StatusCode: http.StatusForbidden,
+				Message:    fmt.Sprintf("user '%s' can't remove items added by other users", h.user.Username()),
+			}
+		}
+	}
+
+	removed, err := sc.CommitQueueRemoveItem(h.projectRef.Identifier, h.issue)
+	if err != nil {
+		if _, ok := err.(*rest.APIError); !ok {
+			err = errors.Wrap(err, "Database error")
+		}
+		return ResponseData{}, err
+	}
+	if !removed {
+		return ResponseData{}, rest.APIError{
+			StatusCode: http.StatusNotFound,
+			Message:    fmt.Sprintf("%s is not in the commit queue", h.issue),
+		}
+	}
+	return ResponseData{}, nil
+}
+
+// getCommitQueueProjectRef returns the project named by the request. The
+// project context falls back to another project when the named one doesn't
+// exist, so that is checked too.
+func getCommitQueueProjectRef(ctx context.Context, r *http.Request) (*serviceModel.ProjectRef, error) {
+	projectID := mux.Vars(r)["project_id"]
+	projCtx := MustHaveProjectContext(ctx)
+	if projCtx.ProjectRef == nil || projCtx.ProjectRef.Identifier != projectID {
+		return nil, rest.APIError{
+			StatusCode: http.StatusNotFound,
+			Message:    fmt.Sprintf("project with id %s not found", projectID),
+		}
+	}
+	return projCtx.ProjectRef, nil
+}
+
+// commitQueueItemType returns the type of the item, which is a pull request
+// if it's a number and a patch if it's a patch id.
+func commitQueueItemType(issue string) (string, error) {
+	if prNumber, err := strconv.Atoi(issue); err == nil && prNumber > 0 {
+		return commitqueue.PRItemType, nil
+	}
+	if bson.IsObjectIdHex(issue) {
+		return commitqueue.PatchItemType, nil
+	}
+	return "", rest.APIError{
+		StatusCode: http.StatusBadRequest,
+		Message:    fmt.Sprintf("'%s' is neither a pull request number nor a patch id", issue),
+	}
+}
@@ -0,0 +1,165 @@
+package route
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/evergreen-ci/evergreen"
+	serviceModel "github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/commitqueue"
+	"github.com/evergreen-ci/evergreen/model/user"
+	"github.com/evergreen-ci/evergreen/rest"
+	"github.com/evergreen-ci/evergreen/rest/data"
+	"github.com/evergreen-ci/evergreen/rest/model"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/suite"
+)
+
+type CommitQueueRouteSuite struct {
+	sc  *data.MockConnector
+	ctx context.Context
+	suite.Suite
+}
+
+func TestCommitQueueRouteSuite(t *testing.T) {
+	suite.Run(t, new(CommitQueueRouteSuite))
+}
+
+func (s *CommitQueueRouteSuite) SetupTest() {
+	s.sc = &data.MockConnector{
+		MockCommitQueueConnector: data.MockCommitQueueConnector{
+			CachedQueues: map[string]*commitqueue.CommitQueue{
+				"mci": {
+					ProjectID: "mci",
+					Queue: []commitqueue.CommitQueueItem{
+						{Issue: "12", Type: commitqueue.PRItemType, Author: "me", Version: "v1"},
+						{Issue: "5a0f0f5b3ff1224f3b58a0a1", Type: commitqueue.PatchItemType, Author: "you"},
+					},
+				},
+			},
+		},
+	}
+	s.ctx = context.WithValue(context.Background(), evergreen.RequestUser, &user.DBUser{Id: "me"})
+	s.ctx = context.WithValue(s.ctx, RequestContext, &serviceModel.Context{
+		ProjectRef: &serviceModel.ProjectRef{
+			Identifier:  "mci",
+			CommitQueue: serviceModel.CommitQueueParams{Enabled: true},
+		},
+	})
+}
+
+// parse runs the handler's ParseAndValidate on a request routed like the
+// commit queue routes, so that the route variables are set.
+func (s *CommitQueueRouteSuite) parse(ctx context.Context, h RequestHandler, method, url string) error {
+	var err error
+	r := mux.NewRouter()
+	handle := func(w http.ResponseWriter, req *http.Request) {
+		err = h.ParseAndValidate(ctx, req)
+	}
+	r.HandleFunc("/commit_queue/{project_id}", handle).Methods(method)
+	r.HandleFunc("/commit_queue/{project_id}/{item}", handle).Methods(method)
+
+	req, reqErr := http.NewRequest(method, url, nil)
+	s.Require().NoError(reqErr)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	return err
+}
+
+func (s *CommitQueueRouteSuite) TestGetCommitQueue() {
+	handler := getCommitQueueRouteManager("", 2).Methods[0].RequestHandler.Handler()
+	s.Require().NoError(s.parse(s.ctx, handler, http.MethodGet, "/commit_queue/mci"))
+
+	res, err := handler.Execute(s.ctx, s.sc)
+	s.Require().NoError(err)
+	s.Require().Len(res.Result, 1)
+
+	cq := res.Result[0].(*model.APICommitQueue)
+	s.Equal(model.APIString("mci"), cq.ProjectID)
+	s.Require().Len(cq.Queue, 2)
+	s.Equal(model.APIString("12"), cq.Queue[0].Issue)
+	s.Equal(model.APIString("v1"), cq.Queue[0].Version)
+	s.Equal(model.APIString(commitqueue.PatchItemType), cq.Queue[1].Type)
+
+	// the project context falls back to another project when the
+	// requested one doesn't exist
+	err = s.parse(s.ctx, handler, http.MethodGet, "/commit_queue/missing")
+	s.Require().Error(err)
+	s.Equal(http.StatusNotFound, err.(rest.APIError).StatusCode)
+}
+
+func (s *CommitQueueRouteSuite) TestEnqueueItem() {
+	handler := getCommitQueueItemRouteManager("", 2).Methods[0].RequestHandler.Handler()
+	s.Require().NoError(s.parse(s.ctx, handler, http.MethodPut, "/commit_queue/mci/13"))
+
+	res, err := handler.Execute(s.ctx, s.sc)
+	s.Require().NoError(err)
+	s.Require().Len(res.Result, 1)
+	s.Equal(2, res.Result[0].(*model.APICommitQueuePosition).Position)
+
+	queue := s.sc.MockCommitQueueConnector.CachedQueues["mci"].Queue
+	s.Require().Len(queue, 3)
+	s.Equal(commitqueue.PRItemType, queue[2].Type)
+	s.Equal("me", queue[2].Author)
+
+	handler = getCommitQueueItemRouteManager("", 2).Methods[0].RequestHandler.Handler()
+	s.Require().NoError(s.parse(s.ctx, handler, http.MethodPut, "/commit_queue/mci/5a0f0f5b3ff1224f3b58a0a2"))
+	_, err = handler.Execute(s.ctx, s.sc)
+	s.Require().NoError(err)
+	s.Equal(commitqueue.PatchItemType, s.sc.MockCommitQueueConnector.CachedQueues["mci"].Queue[3].Type)
+
+	// items can only be queued once
+	handler = getCommitQueueItemRouteManager("", 2).Methods[0].RequestHandler.Handler()
+	s.Require().NoError(s.parse(s.ctx, handler, http.MethodPut, "/commit_queue/mci/12"))
+	_, err = handler.Execute(s.ctx, s.sc)
+	s.Error(err)
+}
+
+func (s *CommitQueueRouteSuite) TestEnqueueItemInvalid() {
+	for _, url := range []string{"/commit_queue/mci/master", "/commit_queue/mci/-1"} {
+		handler := getCommitQueueItemRouteManager("", 2).Methods[0].RequestHandler.Handler()
+		err := s.parse(s.ctx, handler, http.MethodPut, url)
+		s.Require().Error(err, url)
+		s.Equal(http.StatusBadRequest, err.(rest.APIError).StatusCode)
+	}
+
+	ctx := context.WithValue(s.ctx, RequestContext, &serviceModel.Context{
+		ProjectRef: &serviceModel.ProjectRef{Identifier: "mci"},
+	})
+	handler := getCommitQueueItemRouteManager("", 2).Methods[0].RequestHandler.Handler()
+	err := s.parse(ctx, handler, http.MethodPut, "/commit_queue/mci/13")
+	s.Require().Error(err)
+	s.Equal(http.StatusBadRequest, err.(rest.APIError).StatusCode)
+}
+
+func (s *CommitQueueRouteSuite) TestDeleteItem() {
+	handler := getCommitQueueItemRouteManager("", 2).Methods[1].RequestHandler.Handler()
+	s.Require().NoError(s.parse(s.ctx, handler, http.MethodDelete, "/commit_queue/mci/12"))
+	_, err := handler.Execute(s.ctx, s.sc)
+	s.Require().NoError(err)
+	s.Len(s.sc.MockCommitQueueConnector.CachedQueues["mci"].Queue, 1)
+
+	handler = getCommitQueueItemRouteManager("", 2).Methods[1].RequestHandler.Handler()
+	s.Require().NoError(s.parse(s.ctx, handler, http.MethodDelete, "/commit_queue/mci/12"))
+	_, err = handler.Execute(s.ctx, s.sc)
+	s.Require().Error(err)
+	s.Equal(http.StatusNotFound, err.(rest.APIError).StatusCode)
+}
+
+func (s *CommitQueueRouteSuite) TestDeleteOtherUsersItem() {
+	s.sc.SetSuperUsers([]string{"admin"})
+	handler := getCommitQueueItemRouteManager("", 2).Methods[1].RequestHandler.Handler()
+	s.Require().NoError(s.parse(s.ctx, handler, http.MethodDelete, "/commit_queue/mci/5a0f0f5b3ff1224f3b58a0a1"))
+	_, err := handler.Execute(s.ctx, s.sc)
+	s.Require().Error(err)
+	s.Equal(http.StatusForbidden, err.(rest.APIError).StatusCode)
+	s.Len(s.sc.MockCommitQueueConnector.CachedQueues["mci"].Queue, 2)
+
+	// project admins can remove any item
+	MustHaveProjectContext(s.ctx).ProjectRef.Admins = []string{"me"}
+	s.Require().NoError(s.parse(s.ctx, handler, http.MethodDelete, "/commit_queue/mci/5a0f0f5b3ff1224f3b58a0a1"))
+	_, err = handler.Execute(s.ctx, s.sc)
+	s.Require().NoError(err)
+	s.Len(s.sc.MockCommitQueueConnector.CachedQueues["mci"].Queue, 1)
+}
@@ -0,0 +1,233 @@
+package route
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/evergreen-ci/evergreen/model/coverage"
+	"github.com/evergreen-ci/evergreen/rest"
+	"github.com/evergreen-ci/evergreen/rest/data"
+	"github.com/evergreen-ci/evergreen/rest/model"
+	"github.com/pkg/errors"
+)
+
+////////////////////////////////////////////////////////////////////////
+//
+// Handler for fetching the coverage of a task
+//
+//    /tasks/{task_id}/coverage
+
+func getTaskCoverageRouteManager(route string, version int) *RouteManager {
+	return &RouteManager{
+		Route:   route,
+		Version: version,
+		Methods: []MethodHandler{
+			{
+				PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
+				Authenticator:     &NoAuthAuthenticator{},
+				RequestHandler:    &taskCoverageHandler{},
+				MethodType:        http.MethodGet,
+			},
+		},
+	}
+}
+
+// taskCoverageHandler is the RequestHandler for the coverage reported by
+// an execution of a task, which defaults to the latest one.
+type taskCoverageHandler struct {
+	taskId    string
+	execution int
+	lines     bool
+}
+
+func (h *taskCoverageHandler) Handler() RequestHandler {
+	return &taskCoverageHandler{}
+}
+
+func (h *taskCoverageHandler) ParseAndValidate(ctx context.Context, r *http.Request) error {
+	projCtx := MustHaveProjectContext(ctx)
+	if projCtx.Task == nil {
+		return rest.APIError{
+			Message:    "Task not found",
+			StatusCode: http.StatusNotFound,
+		}
+	}
+	h.taskId = projCtx.Task.Id
+	h.execution = projCtx.Task.Execution
+
+	vals := r.URL.Query()
+	if execution := vals.Get("execution"); execution != "" {
+		var err error
+		h.execution, err = strconv.Atoi(execution)
+		if err != nil || h.execution < 0 {
+			return rest.APIError{
+				StatusCode: http.StatusBadRequest,
+				Message:    fmt.Sprintf("Value '%s' provided for 'execution' must be a non-negative integer", execution),
+			}
+		}
+	}
+
+	var err error
+	h.lines, err = parseCoverageLinesParam(r)
+	return err
+}
+
+func (h *taskCoverageHandler) Execute(ctx context.Context, sc data.Connector) (ResponseData, error) {
+	files, err := sc.FindTaskCoverage(h.taskId, h.execution)
+	if err != nil {
+		if _, ok := err.(*rest.APIError); !ok {
+			err = errors.Wrap(err, "Database error")
+		}
+		return ResponseData{}, err
+	}
+
+	return makeFileCoverageResponse(files, h.lines)
+}
+
+////////////////////////////////////////////////////////////////////////
+//
+// Handler for fetching the coverage of a version
+//
+//    /versions/{version_id}/coverage
+
+func getVersionCoverageRouteManager(route string, version int) *RouteManager {
+	return &RouteManager{
+		Route:   route,
+		Version: version,
+		Methods: []MethodHandler{
+			{
+				PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
+				Authenticator:     &NoAuthAuthenticator{},
+				RequestHandler:    &versionCoverageHandler{},
+				MethodType:        http.MethodGet,
+			},
+		},
+	}
+}
+
+// versionCoverageHandler is the RequestHandler for the merged coverage
+// reported by all of a version's tasks.
+type versionCoverageHandler struct {
+	versionId string
+	lines     bool
+}
+
+func (h *versionCoverageHandler) Handler() RequestHandler {
+	return &versionCoverageHandler{}
+}
+
+func (h *versionCoverageHandler) ParseAndValidate(ctx context.Context, r *http.Request) error {
+	h.versionId = getVersionIdFromRequest(r)
+	if h.versionId == "" {
+		return errors.New("request data incomplete")
+	}
+
+	var err error
+	h.lines, err = parseCoverageLinesParam(r)
+	return err
+}
+
+func (h *versionCoverageHandler) Execute(ctx context.Context, sc data.Connector) (ResponseData, error) {
+	files, err := sc.FindVersionCoverage(h.versionId)
+	if err != nil {
+		if _, ok := err.(*rest.APIError); !ok {
+			err = errors.Wrap(err, "Database error")
+		}
+		return ResponseData{}, err
+	}
+
+	return makeFileCoverageResponse(files, h.lines)
+}
+
+////////////////////////////////////////////////////////////////////////
+//
+// Handler for comparing the coverage of a patch with its base version
+//
+//    /versions/{version_id}/coverage/diff
+
+func getVersionCoverageDiffRouteManager(route string, version int) *RouteManager {
+	return &RouteManager{
+		Route:   route,
+		Version: version,
+		Methods: []MethodHandler{
+			{
+				PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
+				Authenticator:     &NoAuthAuthenticator{},
+				RequestHandler:    &versionCoverageDiffHandler{},
+				MethodType:        http.MethodGet,
+			},
+		},
+	}
+}
+
+// versionCoverageDiffHandler is the RequestHandler for the change in
+// coverage of each file between a patch and its base version.
+type versionCoverageDiffHandler struct {
+	versionId string
+}
+
+func (h *versionCoverageDiffHandler) Handler() RequestHandler {
+	return &versionCoverageDiffHandler{}
+}
+
+func (h *versionCoverageDiffHandler) ParseAndValidate(ctx context.Context, r *http.Request) error {
+	h.versionId = getVersionIdFromRequest(r)
+	if h.versionId == "" {
+		return errors.New("request data incomplete")
+	}
+	return nil
+}
+
+func (h *versionCoverageDiffHandler) Execute(ctx context.Context, sc data.Connector) (ResponseData, error) {
+	diff, err := sc.GetVersionCoverageDiff(h.versionId)
+	if err != nil {
+		if _, ok := err.(*rest.APIError); !ok {
+			err = errors.Wrap(err, "Database error")
+		}
+		return ResponseData{}, err
+	}
+
+	diffModel := &model.APIVersionCoverageDiff{}
+	if err = diffModel.BuildFromService(diff); err != nil {
+		return ResponseData{}, errors.Wrap(err, "API model error")
+	}
+	return ResponseData{
+		Result: []model.Model{diffModel},
+	}, nil
+}
+
+// parseCoverageLinesParam reads whether the numbers of the covered and
+// uncovered lines of each file should be returned, which they are not by
+// default.
+func parseCoverageLinesParam(r *http.Request) (bool, error) {
+	lines := r.URL.Query().Get("lines")
+	if lines == "" {
+		return false, nil
+	}
+	include, err := strconv.ParseBool(lines)
+	if err != nil {
+		return false, rest.APIError{
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("Value '%s' provided for 'lines' must be a boolean", lines),
+		}
+	}
+	return include, nil
+}
+
+func makeFileCoverageResponse(files []coverage.FileCoverage, lines bool) (ResponseData, error) {
+	models := make([]model.Model, 0, len(files))
+	for i := range files {
+		fileModel := &model.APIFileCoverage{}
+		if err := fileModel.BuildFromService(&files[i]); err != nil {
+			return ResponseData{}, errors.Wrap(err, "API model error")
+		}
+		if !lines {
+			fileModel.CoveredLines = nil
+			fileModel.UncoveredLines = nil
+		}
+		models = append(models, fileModel)
+	}
+	return ResponseData{Result: models}, nil
+}
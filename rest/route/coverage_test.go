@@ -0,0 +1,143 @@
+package route
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	serviceModel "github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/coverage"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/rest"
+	"github.com/evergreen-ci/evergreen/rest/data"
+	"github.com/evergreen-ci/evergreen/rest/model"
+	"github.com/stretchr/testify/suite"
+)
+
+type CoverageRouteSuite struct {
+	sc  *data.MockConnector
+	ctx context.Context
+	suite.Suite
+}
+
+func TestCoverageRouteSuite(t *testing.T) {
+	suite.Run(t, new(CoverageRouteSuite))
+}
+
+func (s *CoverageRouteSuite) SetupTest() {
+	s.sc = &data.MockConnector{
+		MockTaskConnector: data.MockTaskConnector{
+			CachedTasks: []task.Task{{Id: "unit", Execution: 1}},
+		},
+		MockCoverageConnector: data.MockCoverageConnector{
+			CachedCoverage: map[string][]coverage.FileCoverage{
+				"unit": {
+					{File: "b.go", Covered: []int{1, 2}, Uncovered: []int{3}, TaskID: "unit", Execution: 1, Version: "v1"},
+					{File: "a.go", Covered: []int{1}, Uncovered: []int{2}, TaskID: "unit", Execution: 1, Version: "v1"},
+					{File: "a.go", Covered: []int{2}, TaskID: "unit", Execution: 0, Version: "v1"},
+				},
+				"integration": {
+					{File: "b.go", Covered: []int{3}, TaskID: "integration", Version: "v1"},
+				},
+			},
+			CachedDiffs: map[string]*serviceModel.VersionCoverageDiff{
+				"patch": {
+					Version:     "patch",
+					BaseVersion: "base",
+					Files: []serviceModel.FileCoverageDiff{
+						{File: "a.go", Original: coverage.Summary{Covered: 2, Total: 4}, Patch: coverage.Summary{Covered: 1, Total: 4}},
+					},
+					Tasks: []serviceModel.TaskCoverageDiff{
+						{Name: "unit", BuildVariant: "Linux", Original: "base_unit", Patch: "patch_unit"},
+					},
+				},
+			},
+		},
+	}
+	s.ctx = context.WithValue(context.Background(), RequestContext, &serviceModel.Context{
+		Task: &s.sc.MockTaskConnector.CachedTasks[0],
+	})
+}
+
+func (s *CoverageRouteSuite) TestTaskCoverage() {
+	handler := getTaskCoverageRouteManager("", 2).Methods[0].RequestHandler.Handler()
+	req, err := http.NewRequest(http.MethodGet, "/tasks/unit/coverage", nil)
+	s.Require().NoError(err)
+	s.Require().NoError(handler.ParseAndValidate(s.ctx, req))
+
+	res, err := handler.Execute(s.ctx, s.sc)
+	s.Require().NoError(err)
+	s.Require().Len(res.Result, 2)
+
+	a := res.Result[0].(*model.APIFileCoverage)
+	s.Equal(model.APIString("a.go"), a.File)
+	s.Equal(1, a.Covered)
+	s.Equal(2, a.Total)
+	s.Equal(50.0, a.Percent)
+	s.Nil(a.CoveredLines)
+	s.Nil(a.UncoveredLines)
+}
+
+func (s *CoverageRouteSuite) TestTaskCoverageForExecutionWithLines() {
+	handler := getTaskCoverageRouteManager("", 2).Methods[0].RequestHandler.Handler()
+	req, err := http.NewRequest(http.MethodGet, "/tasks/unit/coverage?execution=0&lines=true", nil)
+	s.Require().NoError(err)
+	s.Require().NoError(handler.ParseAndValidate(s.ctx, req))
+
+	res, err := handler.Execute(s.ctx, s.sc)
+	s.Require().NoError(err)
+	s.Require().Len(res.Result, 1)
+
+	a := res.Result[0].(*model.APIFileCoverage)
+	s.Equal([]int{2}, a.CoveredLines)
+	s.Empty(a.UncoveredLines)
+}
+
+func (s *CoverageRouteSuite) TestTaskCoverageInvalidParams() {
+	for _, url := range []string{"/tasks/unit/coverage?execution=-1", "/tasks/unit/coverage?lines=maybe"} {
+		handler := getTaskCoverageRouteManager("", 2).Methods[0].RequestHandler.Handler()
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		s.Require().NoError(err)
+		err = handler.ParseAndValidate(s.ctx, req)
+		s.Require().Error(err, url)
+		s.Equal(http.StatusBadRequest, err.(rest.APIError).StatusCode)
+	}
+
+	handler := getTaskCoverageRouteManager("", 2).Methods[0].RequestHandler.Handler()
+	req, err := http.NewRequest(http.MethodGet, "/tasks/missing/coverage", nil)
+	s.Require().NoError(err)
+	ctx := context.WithValue(context.Background(), RequestContext, &serviceModel.Context{})
+	s.Error(handler.ParseAndValidate(ctx, req))
+}
+
+func (s *CoverageRouteSuite) TestVersionCoverage() {
+	handler := &versionCoverageHandler{versionId: "v1"}
+	res, err := handler.Execute(s.ctx, s.sc)
+	s.Require().NoError(err)
+	s.Require().Len(res.Result, 2)
+
+	// the latest execution of each task is merged
+	b := res.Result[1].(*model.APIFileCoverage)
+	s.Equal(model.APIString("b.go"), b.File)
+	s.Equal(3, b.Covered)
+	s.Equal(3, b.Total)
+}
+
+func (s *CoverageRouteSuite) TestVersionCoverageDiff() {
+	handler := &versionCoverageDiffHandler{versionId: "patch"}
+	res, err := handler.Execute(s.ctx, s.sc)
+	s.Require().NoError(err)
+	s.Require().Len(res.Result, 1)
+
+	diff := res.Result[0].(*model.APIVersionCoverageDiff)
+	s.Equal(model.APIString("base"), diff.BaseVersionId)
+	s.Require().Len(diff.Files, 1)
+	s.Equal(-25.0, diff.Files[0].Delta)
+	s.Equal(50.0, diff.Files[0].Original.Percent)
+	s.Require().Len(diff.Tasks, 1)
+	s.Equal(model.APIString("patch_unit"), diff.Tasks[0].PatchTaskId)
+
+	handler = &versionCoverageDiffHandler{versionId: "missing"}
+	_, err = handler.Execute(s.ctx, s.sc)
+	s.Error(err)
+}
@@ -0,0 +1,96 @@
+package route
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model/distro"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/rest/data"
+	"github.com/evergreen-ci/evergreen/rest/model"
+	"github.com/stretchr/testify/suite"
+)
+
+type DistroQuotaSuite struct {
+	sc *data.MockConnector
+	rm *RouteManager
+	suite.Suite
+}
+
+func TestDistroQuotaSuite(t *testing.T) {
+	suite.Run(t, new(DistroQuotaSuite))
+}
+
+func (s *DistroQuotaSuite) SetupTest() {
+	s.rm = getDistroQuotaRouteManager("/distros/{distro_id}/quotas", 2)
+	s.sc = &data.MockConnector{
+		MockDistroConnector: data.MockDistroConnector{
+			CachedDistros: []distro.Distro{
+				{
+					Id: "shared",
+					ProjectQuotas: []distro.ProjectQuota{
+						{Project: "big", Guaranteed: 2, Ceiling: 10},
+						{Project: "small", Guaranteed: 1},
+					},
+				},
+			},
+			CachedTasks: []task.Task{
+				{Id: "t1", DistroId: "shared", Project: "big", Status: evergreen.TaskStarted},
+				{Id: "t2", DistroId: "shared", Project: "big", Status: evergreen.TaskDispatched},
+				{Id: "t3", DistroId: "shared", Project: "big", Status: evergreen.TaskSucceeded},
+				{Id: "t4", DistroId: "shared", Project: "other", Status: evergreen.TaskStarted},
+				{Id: "t5", DistroId: "elsewhere", Project: "small", Status: evergreen.TaskStarted},
+			},
+			CachedQueuedTasks: map[string][]task.Task{
+				"shared": {
+					{Id: "q1", Project: "big"},
+					{Id: "q2", Project: "small"},
+				},
+			},
+		},
+	}
+}
+
+func (s *DistroQuotaSuite) TestGetUsage() {
+	handler := s.rm.Methods[0].RequestHandler.Handler()
+	handler.(*distroQuotaGetHandler).distroId = "shared"
+
+	resp, err := handler.Execute(context.Background(), s.sc)
+	s.NoError(err)
+	s.Require().Len(resp.Result, 3)
+
+	big := resp.Result[0].(*model.APIProjectQuotaUsage)
+	s.Equal(model.APIString("big"), big.Project)
+	s.Equal(2, big.Guaranteed)
+	s.Equal(10, big.Ceiling)
+	s.Equal(2, big.Running)
+	s.Equal(1, big.Queued)
+
+	other := resp.Result[1].(*model.APIProjectQuotaUsage)
+	s.Equal(model.APIString("other"), other.Project)
+	s.Equal(0, other.Guaranteed)
+	s.Equal(1, other.Running)
+
+	small := resp.Result[2].(*model.APIProjectQuotaUsage)
+	s.Equal(model.APIString("small"), small.Project)
+	s.Equal(1, small.Guaranteed)
+	s.Equal(0, small.Running)
+	s.Equal(1, small.Queued)
+}
+
+func (s *DistroQuotaSuite) TestMissingDistro() {
+	handler := s.rm.Methods[0].RequestHandler.Handler()
+	handler.(*distroQuotaGetHandler).distroId = "missing"
+
+	_, err := handler.Execute(context.Background(), s.sc)
+	s.Error(err)
+}
+
+func (s *DistroQuotaSuite) TestRequiresDistro() {
+	handler := s.rm.Methods[0].RequestHandler.Handler()
+	req, err := http.NewRequest(http.MethodGet, "/distros//quotas", nil)
+	s.Require().NoError(err)
+	s.Error(handler.ParseAndValidate(context.Background(), req))
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/evergreen-ci/evergreen/rest"
 	"github.com/evergreen-ci/evergreen/rest/data"
 	"github.com/evergreen-ci/evergreen/rest/model"
+	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
 )
 
@@ -55,3 +56,66 @@ func (dgh *distroGetHandler) Execute(ctx context.Context, sc data.Connector) (Re
 		Result: models,
 	}, nil
 }
+
+////////////////////////////////////////////////////////////////////////
+//
+// Handler for the usage of a distro against its project quotas
+//
+//    /distros/{distro_id}/quotas
+
+func getDistroQuotaRouteManager(route string, version int) *RouteManager {
+	return &RouteManager{
+		Route: route,
+		Methods: []MethodHandler{
+			{
+				Authenticator:  &NoAuthAuthenticator{},
+				RequestHandler: &distroQuotaGetHandler{},
+				MethodType:     http.MethodGet,
+			},
+		},
+		Version: version,
+	}
+}
+
+type distroQuotaGetHandler struct {
+	distroId string
+}
+
+func (h *distroQuotaGetHandler) Handler() RequestHandler {
+	return &distroQuotaGetHandler{}
+}
+
+func (h *distroQuotaGetHandler) ParseAndValidate(ctx context.Context, r *http.Request) error {
+	h.distroId = mux.Vars(r)["distro_id"]
+	if h.distroId == "" {
+		return rest.APIError{
+			StatusCode: http.StatusBadRequest,
+			Message:    "must specify a distro",
+		}
+	}
+
+	return nil
+}
+
+func (h *distroQuotaGetHandler) Execute(ctx context.Context, sc data.Connector) (ResponseData, error) {
+	usage, err := sc.FindProjectQuotaUsage(h.distroId)
+	if err != nil {
+		if _, ok := err.(*rest.APIError); !ok {
+			err = errors.Wrap(err, "Database error")
+		}
+		return ResponseData{}, err
+	}
+
+	models := make([]model.Model, len(usage))
+	for i, u := range usage {
+		usageModel := &model.APIProjectQuotaUsage{}
+		if err = usageModel.BuildFromService(u); err != nil {
+			return ResponseData{}, errors.Wrap(err, "problem converting project quota usage")
+		}
+		models[i] = usageModel
+	}
+
+	return ResponseData{
+		Result: models,
+	}, nil
+}
@@ -58,6 +58,15 @@ type RequestHandler interface {
 	Execute(context.Context, data.Connector) (ResponseData, error)
 }
 
+// ResponseStreamer is a kind of response metadata for responses that are
+// written out as they are produced, rather than encoded from the results.
+type ResponseStreamer interface {
+	// Stream writes the response until it is complete or the context is
+	// canceled. Since the status has been sent by the time it fails, its
+	// errors can only be logged.
+	Stream(context.Context, http.ResponseWriter) error
+}
+
 // makeHandler makes an http.HandlerFunc that wraps calls to each of the api
 // Method functions. It marshalls the response to JSON and writes it out to
 // as the response. If any of the functions return an error, it handles creating
@@ -102,6 +111,12 @@ func makeHandler(methodHandler MethodHandler, sc data.Connector) http.HandlerFun
 				return
 			}
 			util.WriteJSON(w, http.StatusOK, result.Result)
+		case ResponseStreamer:
+			grip.Warning(message.WrapError(m.Stream(ctx, w), message.Fields{
+				"message": "problem streaming response",
+				"method":  r.Method,
+				"path":    r.URL.Path,
+			}))
 		default:
 			if len(result.Result) == 1 {
 				util.WriteJSON(w, http.StatusOK, result.Result[0])
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/auth"
+	"github.com/evergreen-ci/evergreen/model/role"
 	"github.com/evergreen-ci/evergreen/rest"
 	"github.com/evergreen-ci/evergreen/rest/data"
 	"github.com/evergreen-ci/evergreen/rest/model"
@@ -28,14 +30,15 @@ func getPatchByIdManager(route string, version int) *RouteManager {
 		Version: version,
 		Methods: []MethodHandler{
 			{
-				MethodType:     http.MethodGet,
-				Authenticator:  &NoAuthAuthenticator{},
-				RequestHandler: &patchByIdHandler{},
+				PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
+				MethodType:        http.MethodGet,
+				Authenticator:     &NoAuthAuthenticator{},
+				RequestHandler:    &patchByIdHandler{},
 			},
 			{
-				PrefetchFunctions: []PrefetchFunc{PrefetchUser},
+				PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
 				MethodType:        http.MethodPatch,
-				Authenticator:     &RequireUserAuthenticator{},
+				Authenticator:     &PermissionAuthenticator{Permission: role.TaskRestart},
 				RequestHandler:    &patchChangeStatusHandler{},
 			},
 		},
@@ -158,6 +161,9 @@ type patchesByUserHandler struct {
 
 type patchesByUserArgs struct {
 	user string
+	// viewer is the user making the request, who only sees the patches
+	// of private projects they can view
+	viewer auth.User
 }
 
 func getPatchesByUserManager(route string, version int) *RouteManager {
@@ -186,7 +192,7 @@ func (p *patchesByUserHandler) Handler() RequestHandler {
 }
 
 func (p *patchesByUserHandler) ParseAndValidate(ctx context.Context, r *http.Request) error {
-	p.Args = patchesByUserArgs{mux.Vars(r)["user_id"]}
+	p.Args = patchesByUserArgs{user: mux.Vars(r)["user_id"], viewer: GetUser(ctx)}
 
 	return p.PaginationExecutor.ParseAndValidate(ctx, r)
 }
@@ -253,7 +259,20 @@ func patchesByUserPaginator(key string, limit int, args interface{}, sc data.Con
 		patches = patches[:limit]
 	}
 	models := []model.Model{}
+	visible := map[string]bool{}
 	for _, info := range patches {
+		canView, ok := visible[info.Project]
+		if !ok {
+			canView, err = canViewProject(sc, args.(patchesByUserArgs).viewer, info.Project)
+			if err != nil {
+				return []model.Model{}, nil, errors.WithStack(err)
+			}
+			visible[info.Project] = canView
+		}
+		if !canView {
+			continue
+		}
+
 		patchModel := &model.APIPatch{}
 		if err = patchModel.BuildFromService(info); err != nil {
 			return []model.Model{}, nil, &rest.APIError{
@@ -268,6 +287,20 @@ func patchesByUserPaginator(key string, limit int, args interface{}, sc data.Con
 	return models, pages, nil
 }
 
+// canViewProject returns true if the project is public or the user has
+// permission to view it.
+func canViewProject(sc data.Connector, u auth.User, projectId string) (bool, error) {
+	opCtx, err := sc.FetchContext("", "", "", "", projectId)
+	if err != nil {
+		return false, errors.Wrapf(err, "problem finding project '%s'", projectId)
+	}
+	if opCtx.ProjectRef == nil || !opCtx.ProjectRef.Private {
+		return true, nil
+	}
+	allowed, err := sc.HasPermission(u, role.ProjectView, opCtx.ProjectRef)
+	return allowed, errors.Wrap(err, "problem checking permissions")
+}
+
 ////////////////////////////////////////////////////////////////////////
 //
 // Handler for the patches for a project
@@ -285,9 +318,10 @@ func getPatchesByProjectManager(route string, version int) *RouteManager {
 		Version: version,
 		Methods: []MethodHandler{
 			{
-				MethodType:     http.MethodGet,
-				Authenticator:  &NoAuthAuthenticator{},
-				RequestHandler: p.Handler(),
+				PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
+				MethodType:        http.MethodGet,
+				Authenticator:     &NoAuthAuthenticator{},
+				RequestHandler:    p.Handler(),
 			},
 		},
 	}
@@ -402,9 +436,9 @@ func getPatchAbortManager(route string, version int) *RouteManager {
 		Version: version,
 		Methods: []MethodHandler{
 			{
-				PrefetchFunctions: []PrefetchFunc{PrefetchUser},
+				PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
 				MethodType:        http.MethodPost,
-				Authenticator:     &RequireUserAuthenticator{},
+				Authenticator:     &PermissionAuthenticator{Permission: role.TaskRestart},
 				RequestHandler:    p.Handler(),
 			},
 		},
@@ -470,9 +504,9 @@ func getPatchRestartManager(route string, version int) *RouteManager {
 		Version: version,
 		Methods: []MethodHandler{
 			{
-				PrefetchFunctions: []PrefetchFunc{PrefetchUser},
+				PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
 				MethodType:        http.MethodPost,
-				Authenticator:     &RequireUserAuthenticator{},
+				Authenticator:     &PermissionAuthenticator{Permission: role.TaskRestart},
 				RequestHandler:    p.Handler(),
 			},
 		},
@@ -6,10 +6,12 @@ import (
 
 	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/role"
 	"github.com/evergreen-ci/evergreen/model/user"
 	"github.com/evergreen-ci/evergreen/rest"
 	"github.com/evergreen-ci/evergreen/rest/data"
 	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
 )
 
 type (
@@ -63,6 +65,7 @@ func PrefetchUser(ctx context.Context, sc data.Connector, r *http.Request) (cont
 
 // PrefetchProjectContext gets the information related to the project that the request contains
 // and fetches the associated project context and attaches that to the request context.
+// Private projects are only found for users allowed to view them.
 func PrefetchProjectContext(ctx context.Context, sc data.Connector, r *http.Request) (context.Context, error) {
 	vars := mux.Vars(r)
 	taskId := vars["task_id"]
@@ -93,6 +96,20 @@ func PrefetchProjectContext(ctx context.Context, sc data.Connector, r *http.Requ
 		}
 	}
 
+	if opCtx.ProjectRef != nil && opCtx.ProjectRef.Private {
+		allowed, err := sc.HasPermission(user, role.ProjectView, opCtx.ProjectRef)
+		if err != nil {
+			return ctx, errors.Wrap(err, "problem checking permissions")
+		}
+		if !allowed {
+			// hide the existence of the project from users who can't view it
+			return ctx, rest.APIError{
+				StatusCode: http.StatusNotFound,
+				Message:    "Project not found",
+			}
+		}
+	}
+
 	ctx = context.WithValue(ctx, RequestContext, &opCtx)
 
 	return ctx, nil
@@ -9,6 +9,7 @@ import (
 	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/patch"
+	"github.com/evergreen-ci/evergreen/model/role"
 	"github.com/evergreen-ci/evergreen/model/user"
 	"github.com/evergreen-ci/evergreen/rest"
 	"github.com/evergreen-ci/evergreen/rest/data"
@@ -142,6 +143,36 @@ func TestPrefetchProject(t *testing.T) {
 
 				So(ctx.Value(RequestContext), ShouldResemble, &opCtx)
 			})
+			Convey("with access control enabled", func() {
+				serviceContext.SetRBACConfig(evergreen.RBACConfig{Enabled: true})
+				serviceContext.SetSuperUsers([]string{"admin"})
+				opCtx := model.Context{}
+				opCtx.ProjectRef = &model.ProjectRef{
+					Identifier: "private_project",
+					Private:    true,
+				}
+				ctx = context.WithValue(ctx, evergreen.RequestUser, &user.DBUser{Id: "test_user"})
+				serviceContext.MockContextConnector.CachedContext = opCtx
+				Convey("should error if the user can't view the private project", func() {
+					ctx, err = PrefetchProjectContext(ctx, serviceContext, req)
+					So(ctx.Value(RequestContext), ShouldBeNil)
+
+					errToResemble := rest.APIError{
+						StatusCode: http.StatusNotFound,
+						Message:    "Project not found",
+					}
+					So(err, ShouldResemble, errToResemble)
+				})
+				Convey("should succeed if the user is a viewer of the private project", func() {
+					serviceContext.MockRoleConnector.CachedAssignments = []role.Assignment{
+						{Id: "a", Role: role.ProjectViewer, User: "test_user", Project: "private_project"},
+					}
+					ctx, err = PrefetchProjectContext(ctx, serviceContext, req)
+					So(err, ShouldBeNil)
+
+					So(ctx.Value(RequestContext), ShouldResemble, &opCtx)
+				})
+			})
 		})
 	})
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/evergreen-ci/evergreen/model/role"
 	"github.com/evergreen-ci/evergreen/model/user"
 	"github.com/evergreen-ci/evergreen/rest"
 	"github.com/evergreen-ci/evergreen/rest/data"
@@ -103,6 +104,16 @@ func projectPaginator(key string, limit int, args interface{}, sc data.Connector
 	}
 	models := []model.Model{}
 	for _, p := range projects {
+		if p.Private {
+			allowed, err := sc.HasPermission(args.(projectGetArgs).User, role.ProjectView, &p)
+			if err != nil {
+				return []model.Model{}, nil, errors.Wrap(err, "problem checking permissions")
+			}
+			if !allowed {
+				continue
+			}
+		}
+
 		projectModel := &model.APIProject{}
 		if err = projectModel.BuildFromService(p); err != nil {
 			return []model.Model{}, nil, &rest.APIError{
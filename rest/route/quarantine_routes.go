@@ -0,0 +1,206 @@
+package route
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/evergreen-ci/evergreen/model/testresult"
+	"github.com/evergreen-ci/evergreen/rest"
+	"github.com/evergreen-ci/evergreen/rest/data"
+	"github.com/evergreen-ci/evergreen/rest/model"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/pkg/errors"
+)
+
+////////////////////////////////////////////////////////////////////////
+//
+// Handlers for managing a project's quarantined tests
+//
+//    /projects/{project_id}/quarantine
+
+func getQuarantineRouteManager(route string, version int) *RouteManager {
+	return &RouteManager{
+		Route:   route,
+		Version: version,
+		Methods: []MethodHandler{
+			{
+				PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
+				Authenticator:     &NoAuthAuthenticator{},
+				RequestHandler:    &quarantineGetHandler{},
+				MethodType:        http.MethodGet,
+			},
+			{
+				PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
+				Authenticator:     &ProjectAdminAuthenticator{},
+				RequestHandler:    &quarantinePostHandler{},
+				MethodType:        http.MethodPost,
+			},
+			{
+				PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
+				Authenticator:     &ProjectAdminAuthenticator{},
+				RequestHandler:    &quarantineDeleteHandler{},
+				MethodType:        http.MethodDelete,
+			},
+		},
+	}
+}
+
+// projectIdFromContext returns the identifier of the project attached
+// to the request, or a not found error if there is none.
+func projectIdFromContext(ctx context.Context) (string, error) {
+	projCtx := MustHaveProjectContext(ctx)
+	if projCtx.ProjectRef == nil {
+		return "", &rest.APIError{
+			StatusCode: http.StatusNotFound,
+			Message:    "Project not found",
+		}
+	}
+	return projCtx.ProjectRef.Identifier, nil
+}
+
+////////////////////////////////////////////////////////////////////////
+//
+// GET /projects/{project_id}/quarantine
+
+type quarantineGetHandler struct{}
+
+func (h *quarantineGetHandler) Handler() RequestHandler {
+	return &quarantineGetHandler{}
+}
+
+func (h *quarantineGetHandler) ParseAndValidate(ctx context.Context, r *http.Request) error {
+	return nil
+}
+
+func (h *quarantineGetHandler) Execute(ctx context.Context, sc data.Connector) (ResponseData, error) {
+	projectId, err := projectIdFromContext(ctx)
+	if err != nil {
+		return ResponseData{}, err
+	}
+
+	tests, err := sc.FindQuarantinedTests(projectId)
+	if err != nil {
+		if _, ok := err.(*rest.APIError); !ok {
+			err = errors.Wrap(err, "Database error")
+		}
+		return ResponseData{}, err
+	}
+
+	models := make([]model.Model, len(tests))
+	for i, t := range tests {
+		testModel := &model.APIQuarantinedTest{}
+		if err = testModel.BuildFromService(t); err != nil {
+			return ResponseData{}, errors.Wrap(err, "problem converting quarantined test")
+		}
+		models[i] = testModel
+	}
+
+	return ResponseData{
+		Result: models,
+	}, nil
+}
+
+////////////////////////////////////////////////////////////////////////
+//
+// POST /projects/{project_id}/quarantine
+
+type quarantinePostHandler struct {
+	testFile string
+	reason   string
+}
+
+func (h *quarantinePostHandler) Handler() RequestHandler {
+	return &quarantinePostHandler{}
+}
+
+func (h *quarantinePostHandler) ParseAndValidate(ctx context.Context, r *http.Request) error {
+	body := util.NewRequestReader(r)
+	defer body.Close()
+
+	test := model.APIQuarantinedTest{}
+	if err := util.ReadJSONInto(body, &test); err != nil {
+		return &rest.APIError{
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("failed to unmarshal quarantined test: %s", err),
+		}
+	}
+
+	h.testFile = strings.TrimSpace(string(test.TestFile))
+	if h.testFile == "" {
+		return &rest.APIError{
+			StatusCode: http.StatusBadRequest,
+			Message:    "must specify a test file to quarantine",
+		}
+	}
+	h.reason = string(test.Reason)
+
+	return nil
+}
+
+func (h *quarantinePostHandler) Execute(ctx context.Context, sc data.Connector) (ResponseData, error) {
+	projectId, err := projectIdFromContext(ctx)
+	if err != nil {
+		return ResponseData{}, err
+	}
+
+	test := &testresult.QuarantinedTest{
+		Project:  projectId,
+		TestFile: h.testFile,
+		Reason:   h.reason,
+	}
+	if u := GetUser(ctx); u != nil {
+		test.User = u.Username()
+	}
+
+	if err = sc.AddQuarantinedTest(test); err != nil {
+		return ResponseData{}, errors.Wrap(err, "problem quarantining test")
+	}
+
+	testModel := &model.APIQuarantinedTest{}
+	if err = testModel.BuildFromService(test); err != nil {
+		return ResponseData{}, errors.Wrap(err, "problem converting quarantined test")
+	}
+
+	return ResponseData{
+		Result: []model.Model{testModel},
+	}, nil
+}
+
+////////////////////////////////////////////////////////////////////////
+//
+// DELETE /projects/{project_id}/quarantine?test_file={test_file}
+
+type quarantineDeleteHandler struct {
+	testFile string
+}
+
+func (h *quarantineDeleteHandler) Handler() RequestHandler {
+	return &quarantineDeleteHandler{}
+}
+
+func (h *quarantineDeleteHandler) ParseAndValidate(ctx context.Context, r *http.Request) error {
+	h.testFile = strings.TrimSpace(r.URL.Query().Get("test_file"))
+	if h.testFile == "" {
+		return &rest.APIError{
+			StatusCode: http.StatusBadRequest,
+			Message:    "must specify a test file to remove from quarantine",
+		}
+	}
+
+	return nil
+}
+
+func (h *quarantineDeleteHandler) Execute(ctx context.Context, sc data.Connector) (ResponseData, error) {
+	projectId, err := projectIdFromContext(ctx)
+	if err != nil {
+		return ResponseData{}, err
+	}
+
+	if err = sc.RemoveQuarantinedTest(projectId, h.testFile); err != nil {
+		return ResponseData{}, errors.Wrap(err, "problem removing test from quarantine")
+	}
+
+	return ResponseData{}, nil
+}
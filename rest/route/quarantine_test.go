@@ -0,0 +1,120 @@
+package route
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/evergreen-ci/evergreen"
+	serviceModel "github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/testresult"
+	"github.com/evergreen-ci/evergreen/model/user"
+	"github.com/evergreen-ci/evergreen/rest/data"
+	"github.com/evergreen-ci/evergreen/rest/model"
+	"github.com/stretchr/testify/suite"
+)
+
+type QuarantineRouteSuite struct {
+	sc  *data.MockConnector
+	rm  *RouteManager
+	ctx context.Context
+	suite.Suite
+}
+
+func TestQuarantineRouteSuite(t *testing.T) {
+	suite.Run(t, new(QuarantineRouteSuite))
+}
+
+func (s *QuarantineRouteSuite) SetupTest() {
+	s.rm = getQuarantineRouteManager("", 2)
+	s.sc = &data.MockConnector{
+		MockQuarantineConnector: data.MockQuarantineConnector{
+			CachedQuarantinedTests: []testresult.QuarantinedTest{
+				{Project: "project", TestFile: "TestFoo", Reason: "flaky"},
+				{Project: "other", TestFile: "TestBar"},
+			},
+		},
+	}
+
+	s.ctx = context.WithValue(context.Background(), RequestContext, &serviceModel.Context{
+		ProjectRef: &serviceModel.ProjectRef{
+			Identifier: "project",
+			Admins:     []string{"admin"},
+		},
+	})
+	s.ctx = context.WithValue(s.ctx, evergreen.RequestUser, &user.DBUser{Id: "admin"})
+}
+
+func (s *QuarantineRouteSuite) TestGet() {
+	resp, err := s.rm.Methods[0].Execute(s.ctx, s.sc)
+	s.NoError(err)
+	s.Require().Len(resp.Result, 1)
+	test := resp.Result[0].(*model.APIQuarantinedTest)
+	s.Equal(model.APIString("TestFoo"), test.TestFile)
+	s.Equal(model.APIString("flaky"), test.Reason)
+}
+
+func (s *QuarantineRouteSuite) TestGetMissingProject() {
+	ctx := context.WithValue(context.Background(), RequestContext, &serviceModel.Context{})
+	_, err := s.rm.Methods[0].Execute(ctx, s.sc)
+	s.Error(err)
+}
+
+func (s *QuarantineRouteSuite) TestPost() {
+	handler := s.rm.Methods[1].RequestHandler.Handler()
+	req, err := http.NewRequest(http.MethodPost, "/projects/project/quarantine",
+		bytes.NewBufferString(`{"test_file": "TestBaz", "reason": "hangs"}`))
+	s.Require().NoError(err)
+	s.NoError(handler.ParseAndValidate(s.ctx, req))
+
+	resp, err := handler.Execute(s.ctx, s.sc)
+	s.NoError(err)
+	s.Require().Len(resp.Result, 1)
+	test := resp.Result[0].(*model.APIQuarantinedTest)
+	s.Equal(model.APIString("project"), test.Project)
+	s.Equal(model.APIString("TestBaz"), test.TestFile)
+	s.Equal(model.APIString("admin"), test.User)
+
+	tests, err := s.sc.FindQuarantinedTests("project")
+	s.NoError(err)
+	s.Len(tests, 2)
+}
+
+func (s *QuarantineRouteSuite) TestPostRequiresTestFile() {
+	handler := s.rm.Methods[1].RequestHandler.Handler()
+	req, err := http.NewRequest(http.MethodPost, "/projects/project/quarantine",
+		bytes.NewBufferString(`{"reason": "hangs"}`))
+	s.Require().NoError(err)
+	s.Error(handler.ParseAndValidate(s.ctx, req))
+}
+
+func (s *QuarantineRouteSuite) TestDelete() {
+	handler := s.rm.Methods[2].RequestHandler.Handler()
+	req, err := http.NewRequest(http.MethodDelete, "/projects/project/quarantine?test_file=TestFoo", nil)
+	s.Require().NoError(err)
+	s.NoError(handler.ParseAndValidate(s.ctx, req))
+
+	_, err = handler.Execute(s.ctx, s.sc)
+	s.NoError(err)
+
+	tests, err := s.sc.FindQuarantinedTests("project")
+	s.NoError(err)
+	s.Empty(tests)
+
+	req, err = http.NewRequest(http.MethodDelete, "/projects/project/quarantine", nil)
+	s.Require().NoError(err)
+	s.Error(handler.ParseAndValidate(s.ctx, req))
+}
+
+func (s *QuarantineRouteSuite) TestModificationsRequireProjectAdmin() {
+	s.NoError(s.rm.Methods[1].Authenticate(s.ctx, s.sc))
+
+	s.sc.SetSuperUsers([]string{"root"})
+	ctx := context.WithValue(s.ctx, evergreen.RequestUser, &user.DBUser{Id: "someone"})
+	s.Error(s.rm.Methods[1].Authenticate(ctx, s.sc))
+	s.Error(s.rm.Methods[2].Authenticate(ctx, s.sc))
+
+	ctx = context.WithValue(s.ctx, evergreen.RequestUser, nil)
+	s.Error(s.rm.Methods[1].Authenticate(ctx, s.sc))
+}
@@ -0,0 +1,426 @@
+package route
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/evergreen-ci/evergreen/auth"
+	"github.com/evergreen-ci/evergreen/model/role"
+	"github.com/evergreen-ci/evergreen/rest"
+	"github.com/evergreen-ci/evergreen/rest/data"
+	"github.com/evergreen-ci/evergreen/rest/model"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+)
+
+////////////////////////////////////////////////////////////////////////
+//
+// Handlers for managing roles and their assignments
+//
+//    /roles
+//    /roles/{role_id}
+//    /role_assignments
+//    /role_assignments/{assignment_id}
+
+func getRolesRouteManager(route string, version int) *RouteManager {
+	return &RouteManager{
+		Route:   route,
+		Version: version,
+		Methods: []MethodHandler{
+			{
+				PrefetchFunctions: []PrefetchFunc{PrefetchUser},
+				Authenticator:     &RequireUserAuthenticator{},
+				RequestHandler:    &rolesGetHandler{},
+				MethodType:        http.MethodGet,
+			},
+			{
+				PrefetchFunctions: []PrefetchFunc{PrefetchUser},
+				Authenticator:     &SuperUserAuthenticator{},
+				RequestHandler:    &rolePostHandler{},
+				MethodType:        http.MethodPost,
+			},
+		},
+	}
+}
+
+func getRoleIdRouteManager(route string, version int) *RouteManager {
+	return &RouteManager{
+		Route:   route,
+		Version: version,
+		Methods: []MethodHandler{
+			{
+				PrefetchFunctions: []PrefetchFunc{PrefetchUser},
+				Authenticator:     &SuperUserAuthenticator{},
+				RequestHandler:    &roleDeleteHandler{},
+				MethodType:        http.MethodDelete,
+			},
+		},
+	}
+}
+
+func getRoleAssignmentsRouteManager(route string, version int) *RouteManager {
+	return &RouteManager{
+		Route:   route,
+		Version: version,
+		Methods: []MethodHandler{
+			{
+				PrefetchFunctions: []PrefetchFunc{PrefetchUser},
+				Authenticator:     &RequireUserAuthenticator{},
+				RequestHandler:    &roleAssignmentsGetHandler{},
+				MethodType:        http.MethodGet,
+			},
+			{
+				PrefetchFunctions: []PrefetchFunc{PrefetchUser},
+				Authenticator:     &RequireUserAuthenticator{},
+				RequestHandler:    &roleAssignmentPostHandler{},
+				MethodType:        http.MethodPost,
+			},
+		},
+	}
+}
+
+func getRoleAssignmentIdRouteManager(route string, version int) *RouteManager {
+	return &RouteManager{
+		Route:   route,
+		Version: version,
+		Methods: []MethodHandler{
+			{
+				PrefetchFunctions: []PrefetchFunc{PrefetchUser},
+				Authenticator:     &RequireUserAuthenticator{},
+				RequestHandler:    &roleAssignmentDeleteHandler{},
+				MethodType:        http.MethodDelete,
+			},
+		},
+	}
+}
+
+// checkCanManageAssignments returns an error unless the user may manage
+// the role assignments of the project. Super users manage every
+// assignment; users who may change a project's settings manage the
+// assignments of project roles on that project.
+func checkCanManageAssignments(ctx context.Context, sc data.Connector, project, roleId string) error {
+	u := GetUser(ctx)
+	if auth.IsSuperUser(sc.GetSuperUsers(), u) {
+		return nil
+	}
+
+	forbidden := rest.APIError{
+		StatusCode: http.StatusForbidden,
+		Message:    "only super users may manage role assignments for every project",
+	}
+	if project == "" {
+		return forbidden
+	}
+
+	if roleId != "" {
+		roles, err := sc.FindRoles()
+		if err != nil {
+			return errors.Wrap(err, "Database error")
+		}
+		for _, r := range roles {
+			if r.Id != roleId {
+				continue
+			}
+			for _, p := range r.Permissions {
+				if !role.IsProjectPermission(p) {
+					return rest.APIError{
+						StatusCode: http.StatusForbidden,
+						Message:    fmt.Sprintf("only super users may assign role '%s'", roleId),
+					}
+				}
+			}
+		}
+	}
+
+	projectRef, err := sc.FindProjectByBranch(project)
+	if err != nil {
+		return errors.Wrap(err, "Database error")
+	}
+	if projectRef == nil {
+		return rest.APIError{
+			StatusCode: http.StatusNotFound,
+			Message:    fmt.Sprintf("project '%s' not found", project),
+		}
+	}
+	allowed, err := sc.HasPermission(u, role.ProjectSettings, projectRef)
+	if err != nil {
+		return errors.Wrap(err, "problem checking permissions")
+	}
+	if !allowed {
+		return rest.APIError{
+			StatusCode: http.StatusForbidden,
+			Message:    fmt.Sprintf("user '%s' may not manage role assignments of project '%s'", u.Username(), project),
+		}
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////
+//
+// GET /roles
+
+type rolesGetHandler struct{}
+
+func (h *rolesGetHandler) Handler() RequestHandler {
+	return &rolesGetHandler{}
+}
+
+func (h *rolesGetHandler) ParseAndValidate(ctx context.Context, r *http.Request) error {
+	return nil
+}
+
+func (h *rolesGetHandler) Execute(ctx context.Context, sc data.Connector) (ResponseData, error) {
+	roles, err := sc.FindRoles()
+	if err != nil {
+		return ResponseData{}, errors.Wrap(err, "Database error")
+	}
+
+	models := make([]model.Model, len(roles))
+	for i, r := range roles {
+		roleModel := &model.APIRole{}
+		if err = roleModel.BuildFromService(r); err != nil {
+			return ResponseData{}, errors.Wrap(err, "problem converting role")
+		}
+		models[i] = roleModel
+	}
+
+	return ResponseData{
+		Result: models,
+	}, nil
+}
+
+////////////////////////////////////////////////////////////////////////
+//
+// POST /roles
+
+type rolePostHandler struct {
+	role *role.Role
+}
+
+func (h *rolePostHandler) Handler() RequestHandler {
+	return &rolePostHandler{}
+}
+
+func (h *rolePostHandler) ParseAndValidate(ctx context.Context, r *http.Request) error {
+	body := util.NewRequestReader(r)
+	defer body.Close()
+
+	apiRole := model.APIRole{}
+	if err := util.ReadJSONInto(body, &apiRole); err != nil {
+		return rest.APIError{
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("failed to unmarshal role: %s", err),
+		}
+	}
+	i, err := apiRole.ToService()
+	if err != nil {
+		return errors.Wrap(err, "problem converting role")
+	}
+	h.role = i.(*role.Role)
+	h.role.Id = strings.TrimSpace(h.role.Id)
+
+	if role.IsBuiltIn(h.role.Id) {
+		return rest.APIError{
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("can't change built-in role '%s'", h.role.Id),
+		}
+	}
+	if err = h.role.Validate(); err != nil {
+		return rest.APIError{
+			StatusCode: http.StatusBadRequest,
+			Message:    err.Error(),
+		}
+	}
+	return nil
+}
+
+func (h *rolePostHandler) Execute(ctx context.Context, sc data.Connector) (ResponseData, error) {
+	if err := sc.UpsertRole(h.role); err != nil {
+		return ResponseData{}, errors.Wrap(err, "problem storing role")
+	}
+
+	roleModel := &model.APIRole{}
+	if err := roleModel.BuildFromService(h.role); err != nil {
+		return ResponseData{}, errors.Wrap(err, "problem converting role")
+	}
+	return ResponseData{
+		Result: []model.Model{roleModel},
+	}, nil
+}
+
+////////////////////////////////////////////////////////////////////////
+//
+// DELETE /roles/{role_id}
+
+type roleDeleteHandler struct {
+	roleId string
+}
+
+func (h *roleDeleteHandler) Handler() RequestHandler {
+	return &roleDeleteHandler{}
+}
+
+func (h *roleDeleteHandler) ParseAndValidate(ctx context.Context, r *http.Request) error {
+	h.roleId = mux.Vars(r)["role_id"]
+	if role.IsBuiltIn(h.roleId) {
+		return rest.APIError{
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("can't remove built-in role '%s'", h.roleId),
+		}
+	}
+	return nil
+}
+
+func (h *roleDeleteHandler) Execute(ctx context.Context, sc data.Connector) (ResponseData, error) {
+	if err := sc.DeleteRole(h.roleId); err != nil {
+		return ResponseData{}, errors.Wrap(err, "problem removing role")
+	}
+	return ResponseData{}, nil
+}
+
+////////////////////////////////////////////////////////////////////////
+//
+// GET /role_assignments?user={user}&group={group}&project_id={project_id}
+
+type roleAssignmentsGetHandler struct {
+	user    string
+	group   string
+	project string
+}
+
+func (h *roleAssignmentsGetHandler) Handler() RequestHandler {
+	return &roleAssignmentsGetHandler{}
+}
+
+func (h *roleAssignmentsGetHandler) ParseAndValidate(ctx context.Context, r *http.Request) error {
+	query := r.URL.Query()
+	h.user = query.Get("user")
+	h.group = query.Get("group")
+	h.project = query.Get("project_id")
+	return nil
+}
+
+func (h *roleAssignmentsGetHandler) Execute(ctx context.Context, sc data.Connector) (ResponseData, error) {
+	if err := checkCanManageAssignments(ctx, sc, h.project, ""); err != nil {
+		return ResponseData{}, err
+	}
+
+	assignments, err := sc.FindRoleAssignments(h.user, h.group, h.project)
+	if err != nil {
+		return ResponseData{}, errors.Wrap(err, "Database error")
+	}
+
+	models := make([]model.Model, len(assignments))
+	for i, a := range assignments {
+		assignmentModel := &model.APIRoleAssignment{}
+		if err = assignmentModel.BuildFromService(a); err != nil {
+			return ResponseData{}, errors.Wrap(err, "problem converting role assignment")
+		}
+		models[i] = assignmentModel
+	}
+
+	return ResponseData{
+		Result: models,
+	}, nil
+}
+
+////////////////////////////////////////////////////////////////////////
+//
+// POST /role_assignments
+
+type roleAssignmentPostHandler struct {
+	assignment *role.Assignment
+}
+
+func (h *roleAssignmentPostHandler) Handler() RequestHandler {
+	return &roleAssignmentPostHandler{}
+}
+
+func (h *roleAssignmentPostHandler) ParseAndValidate(ctx context.Context, r *http.Request) error {
+	body := util.NewRequestReader(r)
+	defer body.Close()
+
+	apiAssignment := model.APIRoleAssignment{}
+	if err := util.ReadJSONInto(body, &apiAssignment); err != nil {
+		return rest.APIError{
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("failed to unmarshal role assignment: %s", err),
+		}
+	}
+	i, err := apiAssignment.ToService()
+	if err != nil {
+		return errors.Wrap(err, "problem converting role assignment")
+	}
+	h.assignment = i.(*role.Assignment)
+
+	if err = h.assignment.Validate(); err != nil {
+		return rest.APIError{
+			StatusCode: http.StatusBadRequest,
+			Message:    err.Error(),
+		}
+	}
+	return nil
+}
+
+func (h *roleAssignmentPostHandler) Execute(ctx context.Context, sc data.Connector) (ResponseData, error) {
+	if err := checkCanManageAssignments(ctx, sc, h.assignment.Project, h.assignment.Role); err != nil {
+		return ResponseData{}, err
+	}
+
+	h.assignment.CreatedBy = MustHaveUser(ctx).Username()
+	if err := sc.AddRoleAssignment(h.assignment); err != nil {
+		return ResponseData{}, rest.APIError{
+			StatusCode: http.StatusBadRequest,
+			Message:    errors.Wrap(err, "problem assigning role").Error(),
+		}
+	}
+
+	assignmentModel := &model.APIRoleAssignment{}
+	if err := assignmentModel.BuildFromService(h.assignment); err != nil {
+		return ResponseData{}, errors.Wrap(err, "problem converting role assignment")
+	}
+	return ResponseData{
+		Result: []model.Model{assignmentModel},
+	}, nil
+}
+
+////////////////////////////////////////////////////////////////////////
+//
+// DELETE /role_assignments/{assignment_id}
+
+type roleAssignmentDeleteHandler struct {
+	assignmentId string
+}
+
+func (h *roleAssignmentDeleteHandler) Handler() RequestHandler {
+	return &roleAssignmentDeleteHandler{}
+}
+
+func (h *roleAssignmentDeleteHandler) ParseAndValidate(ctx context.Context, r *http.Request) error {
+	h.assignmentId = mux.Vars(r)["assignment_id"]
+	return nil
+}
+
+func (h *roleAssignmentDeleteHandler) Execute(ctx context.Context, sc data.Connector) (ResponseData, error) {
+	assignment, err := sc.FindRoleAssignmentById(h.assignmentId)
+	if err != nil {
+		return ResponseData{}, errors.Wrap(err, "Database error")
+	}
+	if assignment == nil {
+		return ResponseData{}, rest.APIError{
+			StatusCode: http.StatusNotFound,
+			Message:    fmt.Sprintf("role assignment '%s' not found", h.assignmentId),
+		}
+	}
+	if err = checkCanManageAssignments(ctx, sc, assignment.Project, assignment.Role); err != nil {
+		return ResponseData{}, err
+	}
+
+	if err = sc.RemoveRoleAssignment(h.assignmentId); err != nil {
+		return ResponseData{}, errors.Wrap(err, "problem removing role assignment")
+	}
+	return ResponseData{}, nil
+}
@@ -0,0 +1,160 @@
+package route
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/evergreen-ci/evergreen"
+	serviceModel "github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/role"
+	"github.com/evergreen-ci/evergreen/model/user"
+	"github.com/evergreen-ci/evergreen/rest"
+	"github.com/evergreen-ci/evergreen/rest/data"
+	"github.com/evergreen-ci/evergreen/rest/model"
+	"github.com/stretchr/testify/suite"
+)
+
+type RoleRouteSuite struct {
+	sc      *data.MockConnector
+	project *serviceModel.ProjectRef
+	suite.Suite
+}
+
+func TestRoleRouteSuite(t *testing.T) {
+	suite.Run(t, new(RoleRouteSuite))
+}
+
+func (s *RoleRouteSuite) SetupTest() {
+	s.project = &serviceModel.ProjectRef{
+		Identifier: "project",
+		Admins:     []string{"admin"},
+	}
+	s.sc = &data.MockConnector{
+		MockBuildConnector: data.MockBuildConnector{
+			CachedProjects: map[string]*serviceModel.ProjectRef{"project": s.project},
+		},
+	}
+	s.sc.SetSuperUsers([]string{"root"})
+	s.sc.SetRBACConfig(evergreen.RBACConfig{Enabled: true})
+	s.Require().NoError(s.sc.AddRoleAssignment(&role.Assignment{Role: role.TaskRestarter, User: "restarter", Project: "project"}))
+	s.Require().NoError(s.sc.AddRoleAssignment(&role.Assignment{Role: role.HostAdminId, Group: "ops"}))
+}
+
+func (s *RoleRouteSuite) userContext(id string, groups ...string) context.Context {
+	ctx := context.WithValue(context.Background(), RequestContext, &serviceModel.Context{ProjectRef: s.project})
+	return context.WithValue(ctx, evergreen.RequestUser, &user.DBUser{Id: id, Groups: groups})
+}
+
+func (s *RoleRouteSuite) TestPermissionAuthenticator() {
+	restart := &PermissionAuthenticator{Permission: role.TaskRestart}
+	hosts := &PermissionAuthenticator{Permission: role.HostAdmin}
+
+	s.NoError(restart.Authenticate(s.userContext("restarter"), s.sc))
+	s.NoError(restart.Authenticate(s.userContext("admin"), s.sc))
+	s.NoError(restart.Authenticate(s.userContext("root"), s.sc))
+	s.NoError(hosts.Authenticate(s.userContext("operator", "ops"), s.sc))
+
+	err := restart.Authenticate(s.userContext("operator", "ops"), s.sc)
+	s.Require().Error(err)
+	s.Equal(http.StatusForbidden, err.(rest.APIError).StatusCode)
+	err = hosts.Authenticate(s.userContext("restarter"), s.sc)
+	s.Require().Error(err)
+	s.Equal(http.StatusForbidden, err.(rest.APIError).StatusCode)
+
+	err = restart.Authenticate(context.Background(), s.sc)
+	s.Require().Error(err)
+	s.Equal(http.StatusNotFound, err.(rest.APIError).StatusCode)
+
+	// without role-based access control, users keep their old permissions
+	s.sc.SetRBACConfig(evergreen.RBACConfig{})
+	s.NoError(restart.Authenticate(s.userContext("operator"), s.sc))
+	s.Error((&PermissionAuthenticator{Permission: role.DistroAdmin}).Authenticate(s.userContext("operator"), s.sc))
+}
+
+func (s *RoleRouteSuite) TestRoles() {
+	rm := getRolesRouteManager("", 2)
+
+	post := rm.Methods[1].RequestHandler.Handler()
+	req, err := http.NewRequest("POST", "/roles", bytes.NewBufferString(`{"id": "builtin", "permissions": ["fly"]}`))
+	s.NoError(err)
+	s.Error(post.ParseAndValidate(s.userContext("root"), req))
+
+	req, err = http.NewRequest("POST", "/roles", bytes.NewBufferString(`{"id": "project_admin", "permissions": ["project_view"]}`))
+	s.NoError(err)
+	s.Error(post.ParseAndValidate(s.userContext("root"), req))
+
+	req, err = http.NewRequest("POST", "/roles", bytes.NewBufferString(`{"id": "watcher", "name": "Watcher", "permissions": ["project_view"]}`))
+	s.NoError(err)
+	s.NoError(post.ParseAndValidate(s.userContext("root"), req))
+	_, err = post.Execute(s.userContext("root"), s.sc)
+	s.NoError(err)
+
+	resp, err := rm.Methods[0].Execute(s.userContext("root"), s.sc)
+	s.NoError(err)
+	s.Len(resp.Result, len(role.BuiltInRoles())+1)
+	watcher := resp.Result[len(resp.Result)-1].(*model.APIRole)
+	s.Equal(model.APIString("watcher"), watcher.Id)
+	s.False(watcher.BuiltIn)
+
+	s.Error((&SuperUserAuthenticator{}).Authenticate(s.userContext("admin"), s.sc))
+}
+
+func (s *RoleRouteSuite) TestAssignments() {
+	rm := getRoleAssignmentsRouteManager("", 2)
+
+	// project admins may assign project roles on their project
+	post := rm.Methods[1].RequestHandler.Handler()
+	req, err := http.NewRequest("POST", "/role_assignments", bytes.NewBufferString(`{"role": "project_viewer", "user": "viewer", "project_id": "project"}`))
+	s.NoError(err)
+	s.NoError(post.ParseAndValidate(s.userContext("admin"), req))
+	resp, err := post.Execute(s.userContext("admin"), s.sc)
+	s.NoError(err)
+	s.Require().Len(resp.Result, 1)
+	assignment := resp.Result[0].(*model.APIRoleAssignment)
+	s.Equal(model.APIString("admin"), assignment.CreatedBy)
+
+	// but not system roles, roles for every project, or on other projects
+	for _, body := range []string{
+		`{"role": "host_admin", "user": "viewer", "project_id": "project"}`,
+		`{"role": "project_viewer", "user": "viewer"}`,
+	} {
+		post = rm.Methods[1].RequestHandler.Handler()
+		req, err = http.NewRequest("POST", "/role_assignments", bytes.NewBufferString(body))
+		s.NoError(err)
+		s.NoError(post.ParseAndValidate(s.userContext("admin"), req))
+		_, err = post.Execute(s.userContext("admin"), s.sc)
+		s.Error(err)
+	}
+	post = rm.Methods[1].RequestHandler.Handler()
+	req, err = http.NewRequest("POST", "/role_assignments", bytes.NewBufferString(`{"role": "project_viewer", "user": "viewer", "project_id": "project"}`))
+	s.NoError(err)
+	s.NoError(post.ParseAndValidate(s.userContext("restarter"), req))
+	_, err = post.Execute(s.userContext("restarter"), s.sc)
+	s.Error(err)
+
+	// assignments must be to exactly one of a user or a group
+	post = rm.Methods[1].RequestHandler.Handler()
+	req, err = http.NewRequest("POST", "/role_assignments", bytes.NewBufferString(`{"role": "project_viewer", "user": "viewer", "group": "team"}`))
+	s.NoError(err)
+	s.Error(post.ParseAndValidate(s.userContext("root"), req))
+
+	get := &roleAssignmentsGetHandler{project: "project"}
+	resp, err = get.Execute(s.userContext("admin"), s.sc)
+	s.NoError(err)
+	s.Len(resp.Result, 2)
+	_, err = (&roleAssignmentsGetHandler{}).Execute(s.userContext("admin"), s.sc)
+	s.Error(err)
+	resp, err = (&roleAssignmentsGetHandler{}).Execute(s.userContext("root"), s.sc)
+	s.NoError(err)
+	s.Len(resp.Result, 3)
+
+	del := &roleAssignmentDeleteHandler{assignmentId: string(assignment.Id)}
+	_, err = del.Execute(s.userContext("restarter"), s.sc)
+	s.Error(err)
+	_, err = del.Execute(s.userContext("admin"), s.sc)
+	s.NoError(err)
+	_, err = del.Execute(s.userContext("admin"), s.sc)
+	s.Error(err)
+}
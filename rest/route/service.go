@@ -3,6 +3,7 @@ package route
 import (
 	"net/http"
 
+	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/rest/data"
 	"github.com/gorilla/mux"
 	"github.com/mongodb/amboy"
@@ -11,12 +12,13 @@ import (
 // AttachHandler attaches the api's request handlers to the given mux router.
 // It builds a Connector then attaches each of the main functions for
 // the api to the router.
-func AttachHandler(root *mux.Router, queue amboy.Queue, URL, prefix string, superUsers []string, githubSecret []byte) http.Handler {
+func AttachHandler(root *mux.Router, queue amboy.Queue, URL, prefix string, superUsers []string, rbac evergreen.RBACConfig, githubSecret []byte) http.Handler {
 	sc := &data.DBConnector{}
 
 	sc.SetURL(URL)
 	sc.SetPrefix(prefix)
 	sc.SetSuperUsers(superUsers)
+	sc.SetRBACConfig(rbac)
 	return GetHandler(root, sc, queue, githubSecret)
 }
 
@@ -34,7 +36,10 @@ func GetHandler(r *mux.Router, sc data.Connector, queue amboy.Queue, githubSecre
 		"/builds/{build_id}/abort":                             getBuildAbortRouteManager,
 		"/builds/{build_id}/restart":                           getBuildRestartManager,
 		"/builds/{build_id}/tasks":                             getTasksByBuildRouteManager,
+		"/commit_queue/{project_id}":                           getCommitQueueRouteManager,
+		"/commit_queue/{project_id}/{item}":                    getCommitQueueItemRouteManager,
 		"/distros":                                             getDistroRouteManager,
+		"/distros/{distro_id}/quotas":                          getDistroQuotaRouteManager,
 		"/hosts":                                               getHostRouteManager,
 		"/hosts/{host_id}":                                     getHostIDRouteManager,
 		"/hosts/{host_id}/change_password":                     getHostChangeRDPPasswordRouteManager,
@@ -47,9 +52,12 @@ func GetHandler(r *mux.Router, sc data.Connector, queue amboy.Queue, githubSecre
 		"/patches/{patch_id}/restart":                          getPatchRestartManager,
 		"/projects":                                            getProjectRouteManager,
 		"/projects/{project_id}/patches":                       getPatchesByProjectManager,
+		"/projects/{project_id}/quarantine":                    getQuarantineRouteManager,
 		"/projects/{project_id}/revisions/{commit_hash}/tasks": getTasksByProjectAndCommitRouteManager,
 		"/tasks/{task_id}":                                     getTaskRouteManager,
 		"/tasks/{task_id}/abort":                               getTaskAbortManager,
+		"/tasks/{task_id}/coverage":                            getTaskCoverageRouteManager,
+		"/tasks/{task_id}/logs":                                getTaskLogRouteManager,
 		"/tasks/{task_id}/restart":                             getTaskRestartRouteManager,
 		"/tasks/{task_id}/tests":                               getTestRouteManager,
 		"/tasks/{task_id}/metrics/process":                     getTaskProcessMetricsManager,
@@ -60,9 +68,15 @@ func GetHandler(r *mux.Router, sc data.Connector, queue amboy.Queue, githubSecre
 		"/versions/{version_id}":                               getVersionIdRouteManager,
 		"/versions/{version_id}/builds":                        getBuildsForVersionRouteManager,
 		"/versions/{version_id}/abort":                         getAbortVersionRouteManager,
+		"/versions/{version_id}/coverage":                      getVersionCoverageRouteManager,
+		"/versions/{version_id}/coverage/diff":                 getVersionCoverageDiffRouteManager,
 		"/versions/{version_id}/restart":                       getRestartVersionRouteManager,
 		"/status/hosts/distros":                                getHostStatsByDistroManager,
 		"/status/recent_tasks":                                 getRecentTasksRouteManager,
+		"/roles":                                               getRolesRouteManager,
+		"/roles/{role_id}":                                     getRoleIdRouteManager,
+		"/role_assignments":                                    getRoleAssignmentsRouteManager,
+		"/role_assignments/{assignment_id}":                    getRoleAssignmentIdRouteManager,
 		"/keys":                                                getKeysRouteManager,
 		"/keys/{key_name}":                                     getKeysDeleteRouteManager,
 		"/hooks/github":                                        getGithubHooksRouteManager(queue, githubSecret),
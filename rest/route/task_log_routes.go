@@ -0,0 +1,415 @@
+package route
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/apimodels"
+	serviceModel "github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/rest"
+	"github.com/evergreen-ci/evergreen/rest/data"
+	"github.com/evergreen-ci/evergreen/rest/model"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mongodb/grip"
+	"github.com/pkg/errors"
+)
+
+const (
+	// taskLogFollowPollInterval is how often a followed log is checked
+	// for new lines.
+	taskLogFollowPollInterval = time.Second
+	// taskLogFollowMaxDuration bounds a followed log stream to stay
+	// within the server's write timeout; clients resume from the number
+	// of lines they have read.
+	taskLogFollowMaxDuration = 45 * time.Second
+	// taskLogKeepAliveInterval is how long an event stream may go
+	// without any output before a comment is sent to keep it open.
+	taskLogKeepAliveInterval = 15 * time.Second
+
+	eventStreamContentType = "text/event-stream"
+	ndjsonContentType      = "application/x-ndjson"
+)
+
+////////////////////////////////////////////////////////////////////////
+//
+// Handler for fetching and following a task's log
+//
+//    /tasks/{task_id}/logs
+
+func getTaskLogRouteManager(route string, version int) *RouteManager {
+	tlgh := &taskLogGetHandler{}
+	return &RouteManager{
+		Route:   route,
+		Version: version,
+		Methods: []MethodHandler{
+			{
+				PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
+				Authenticator:     &RequireUserAuthenticator{},
+				RequestHandler:    tlgh.Handler(),
+				MethodType:        http.MethodGet,
+			},
+		},
+	}
+}
+
+// taskLogGetHandlerArgs are the additional arguments that are needed when
+// fetching paginated lines of a task's log.
+type taskLogGetHandlerArgs struct {
+	taskId     string
+	execution  int
+	severities []string
+	msgTypes   []string
+}
+
+// taskLogGetHandler is the MethodHandler for the GET /tasks/{task_id}/logs
+// route. Lines are paginated by their offset among the lines matching the
+// filters. With follow=true, the lines from the offset on are instead
+// streamed as they are written, until the task finishes.
+type taskLogGetHandler struct {
+	*PaginationExecutor
+
+	follow      bool
+	eventStream bool
+}
+
+func (h *taskLogGetHandler) Handler() RequestHandler {
+	return &taskLogGetHandler{
+		PaginationExecutor: &PaginationExecutor{
+			KeyQueryParam:   "offset",
+			LimitQueryParam: "limit",
+			Paginator:       taskLogPaginator,
+			Args:            taskLogGetHandlerArgs{},
+		},
+	}
+}
+
+// ParseAndValidate fetches the task and the filters from the request. The
+// type and severity filters are comma-separated lists of names or stored
+// prefixes. The execution defaults to the task's latest one.
+func (h *taskLogGetHandler) ParseAndValidate(ctx context.Context, r *http.Request) error {
+	projCtx := MustHaveProjectContext(ctx)
+	if projCtx.Task == nil {
+		return rest.APIError{
+			Message:    "Task not found",
+			StatusCode: http.StatusNotFound,
+		}
+	}
+
+	vals := r.URL.Query()
+	args := taskLogGetHandlerArgs{
+		taskId:    projCtx.Task.Id,
+		execution: projCtx.Task.Execution,
+	}
+	var err error
+	if execution := vals.Get("execution"); execution != "" {
+		args.execution, err = strconv.Atoi(execution)
+		if err != nil || args.execution < 0 {
+			return rest.APIError{
+				StatusCode: http.StatusBadRequest,
+				Message:    fmt.Sprintf("Value '%s' provided for 'execution' must be a non-negative integer", execution),
+			}
+		}
+	}
+	if args.msgTypes, err = parseLogFilter(vals.Get("type"), model.LogTypePrefix); err != nil {
+		return err
+	}
+	if args.severities, err = parseLogFilter(vals.Get("severity"), model.LogSeverityPrefix); err != nil {
+		return err
+	}
+	if follow := vals.Get("follow"); follow != "" {
+		h.follow, err = strconv.ParseBool(follow)
+		if err != nil {
+			return rest.APIError{
+				StatusCode: http.StatusBadRequest,
+				Message:    fmt.Sprintf("Value '%s' provided for 'follow' must be a boolean", follow),
+			}
+		}
+	}
+	h.eventStream = strings.Contains(r.Header.Get("Accept"), eventStreamContentType)
+	h.Args = args
+
+	if err = h.PaginationExecutor.ParseAndValidate(ctx, r); err != nil {
+		return err
+	}
+	// event stream clients resume from the id of the last event they saw
+	if lastId := r.Header.Get("Last-Event-ID"); h.key == "" && lastId != "" {
+		h.key = lastId
+	}
+	_, err = parseLogOffset(h.key)
+	return err
+}
+
+// Execute returns a page of the log, or a streamer that follows it.
+func (h *taskLogGetHandler) Execute(ctx context.Context, sc data.Connector) (ResponseData, error) {
+	if !h.follow {
+		return h.PaginationExecutor.Execute(ctx, sc)
+	}
+
+	offset, err := parseLogOffset(h.key)
+	if err != nil {
+		return ResponseData{}, err
+	}
+	args := h.Args.(taskLogGetHandlerArgs)
+	return ResponseData{
+		Metadata: &taskLogStreamer{
+			sc:           sc,
+			args:         args,
+			offset:       offset,
+			eventStream:  h.eventStream,
+			pollInterval: taskLogFollowPollInterval,
+			maxDuration:  taskLogFollowMaxDuration,
+		},
+	}, nil
+}
+
+// parseLogFilter converts a comma-separated list of log types or
+// severities to their stored prefixes.
+func parseLogFilter(value string, prefix func(string) (string, error)) ([]string, error) {
+	prefixes := []string{}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		p, err := prefix(strings.ToLower(name))
+		if err != nil {
+			p, err = prefix(strings.ToUpper(name))
+		}
+		if err != nil {
+			return nil, rest.APIError{
+				StatusCode: http.StatusBadRequest,
+				Message:    err.Error(),
+			}
+		}
+		prefixes = append(prefixes, p)
+	}
+	return prefixes, nil
+}
+
+func parseLogOffset(key string) (int, error) {
+	if key == "" {
+		return 0, nil
+	}
+	offset, err := strconv.Atoi(key)
+	if err != nil || offset < 0 {
+		return 0, rest.APIError{
+			StatusCode: http.StatusBadRequest,
+			Message:    fmt.Sprintf("Value '%s' provided for 'offset' must be a non-negative integer", key),
+		}
+	}
+	return offset, nil
+}
+
+// taskLogPaginator is the PaginatorFunc that implements the functionality
+// of paginating over the lines of a task's log that match the filters.
+func taskLogPaginator(key string, limit int, args interface{}, sc data.Connector) ([]model.Model,
+	*PageResult, error) {
+	tlArgs, ok := args.(taskLogGetHandlerArgs)
+	if !ok {
+		grip.EmergencyPanic("Task log pagination args had wrong type")
+	}
+	if limit <= 0 {
+		return []model.Model{}, nil, rest.APIError{
+			StatusCode: http.StatusBadRequest,
+			Message:    "Value provided for 'limit' must be positive",
+		}
+	}
+	offset, err := parseLogOffset(key)
+	if err != nil {
+		return []model.Model{}, nil, err
+	}
+
+	logs, err := sc.FindTaskLogs(tlArgs.taskId, tlArgs.execution, 0)
+	if err != nil {
+		return []model.Model{}, nil, errors.Wrap(err, "Database error")
+	}
+	msgs := []apimodels.LogMessage{}
+	for _, l := range logs {
+		msgs = append(msgs, serviceModel.FilterLogMessages(l.Messages, tlArgs.severities, tlArgs.msgTypes)...)
+	}
+
+	pages := &PageResult{}
+	if offset+limit < len(msgs) {
+		pages.Next = &Page{
+			Relation: "next",
+			Key:      strconv.Itoa(offset + limit),
+			Limit:    limit,
+		}
+	}
+	if offset > 0 {
+		prev := offset - limit
+		if prev < 0 {
+			prev = 0
+		}
+		pages.Prev = &Page{
+			Relation: "prev",
+			Key:      strconv.Itoa(prev),
+			Limit:    offset - prev,
+		}
+	}
+
+	end := offset + limit
+	if end > len(msgs) {
+		end = len(msgs)
+	}
+	if offset > end {
+		offset = end
+	}
+	models := make([]model.Model, 0, end-offset)
+	for _, msg := range msgs[offset:end] {
+		apiMsg := &model.APILogMessage{}
+		if err = apiMsg.BuildFromService(msg); err != nil {
+			return []model.Model{}, nil, errors.Wrap(err, "Model error")
+		}
+		models = append(models, apiMsg)
+	}
+	return models, pages, nil
+}
+
+// taskLogStreamer writes the lines of a task's log as they are written,
+// as newline-delimited JSON or, if the client asked for it, as
+// server-sent events whose ids are the offsets to resume from. The stream
+// ends once the task has finished and all of its log has been written, or
+// after maxDuration, and sets the TaskLogCompleteTrailerKey trailer to say
+// which.
+type taskLogStreamer struct {
+	sc           data.Connector
+	args         taskLogGetHandlerArgs
+	offset       int
+	eventStream  bool
+	pollInterval time.Duration
+	maxDuration  time.Duration
+}
+
+func (s *taskLogStreamer) Stream(ctx context.Context, w http.ResponseWriter) error {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		util.WriteJSON(w, http.StatusInternalServerError, rest.APIError{
+			StatusCode: http.StatusInternalServerError,
+			Message:    "Streaming is not supported",
+		})
+		return errors.New("response writer does not support flushing")
+	}
+
+	contentType := ndjsonContentType
+	if s.eventStream {
+		contentType = eventStreamContentType
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Trailer", evergreen.TaskLogCompleteTrailerKey)
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	complete := false
+	defer func() {
+		w.Header().Set(evergreen.TaskLogCompleteTrailerKey, strconv.FormatBool(complete))
+	}()
+
+	deadline := time.Now().Add(s.maxDuration)
+	lastWrite := time.Now()
+	skip, sent := s.offset, s.offset
+	// chunks is the number of chunks read so far, and consumed the number
+	// of messages read from the last of them, which may still grow
+	chunks, consumed := 0, 0
+	for {
+		// check whether the task is done before reading the log, so that
+		// the last read includes everything it logged
+		t, err := s.sc.FindTaskById(s.args.taskId)
+		if err != nil {
+			return errors.Wrapf(err, "problem finding task '%s'", s.args.taskId)
+		}
+		if t == nil {
+			return errors.Errorf("task '%s' not found", s.args.taskId)
+		}
+		finished := t.Execution > s.args.execution || task.IsFinished(*t)
+
+		from := chunks
+		if chunks > 0 {
+			from = chunks - 1
+		}
+		logs, err := s.sc.FindTaskLogs(s.args.taskId, s.args.execution, from)
+		if err != nil {
+			return errors.Wrapf(err, "problem finding logs for task '%s'", s.args.taskId)
+		}
+		for i, l := range logs {
+			msgs := l.Messages
+			if i == 0 && chunks > 0 {
+				if consumed > len(msgs) {
+					consumed = len(msgs)
+				}
+				msgs = msgs[consumed:]
+			} else {
+				chunks++
+			}
+			consumed = len(l.Messages)
+
+			for _, msg := range serviceModel.FilterLogMessages(msgs, s.args.severities, s.args.msgTypes) {
+				if skip > 0 {
+					skip--
+					continue
+				}
+				sent++
+				if err = s.write(w, msg, sent); err != nil {
+					return errors.Wrap(err, "problem writing log line")
+				}
+				lastWrite = time.Now()
+			}
+		}
+
+		if finished {
+			complete = true
+			if s.eventStream {
+				if _, err = fmt.Fprint(w, "event: end\ndata: {}\n\n"); err != nil {
+					return errors.Wrap(err, "problem ending event stream")
+				}
+			}
+			flusher.Flush()
+			return nil
+		}
+		if s.eventStream && time.Since(lastWrite) >= taskLogKeepAliveInterval {
+			if _, err = fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return errors.Wrap(err, "problem writing to event stream")
+			}
+			lastWrite = time.Now()
+		}
+		flusher.Flush()
+
+		if time.Now().After(deadline) {
+			return nil
+		}
+		timer := time.NewTimer(s.pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// write writes a line of the log, with the offset of the line after it.
+func (s *taskLogStreamer) write(w http.ResponseWriter, msg apimodels.LogMessage, next int) error {
+	apiMsg := &model.APILogMessage{}
+	if err := apiMsg.BuildFromService(msg); err != nil {
+		return errors.WithStack(err)
+	}
+	out, err := json.Marshal(apiMsg)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if s.eventStream {
+		_, err = fmt.Fprintf(w, "id: %d\nevent: log\ndata: %s\n\n", next, out)
+	} else {
+		_, err = fmt.Fprintf(w, "%s\n", out)
+	}
+	return errors.WithStack(err)
+}
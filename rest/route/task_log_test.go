@@ -0,0 +1,267 @@
+package route
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/apimodels"
+	serviceModel "github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/model/user"
+	"github.com/evergreen-ci/evergreen/rest/data"
+	"github.com/evergreen-ci/evergreen/rest/model"
+	"github.com/stretchr/testify/suite"
+)
+
+type TaskLogRouteSuite struct {
+	sc  *data.MockConnector
+	rm  *RouteManager
+	ctx context.Context
+	suite.Suite
+}
+
+func TestTaskLogRouteSuite(t *testing.T) {
+	suite.Run(t, new(TaskLogRouteSuite))
+}
+
+func (s *TaskLogRouteSuite) SetupTest() {
+	s.rm = getTaskLogRouteManager("", 2)
+
+	logs := []serviceModel.TaskLog{}
+	for i := 0; i < 3; i++ {
+		chunk := serviceModel.TaskLog{TaskId: "task", Execution: 1}
+		for j := 0; j < 4; j++ {
+			msgType, severity := apimodels.TaskLogPrefix, apimodels.LogInfoPrefix
+			if j == 3 {
+				msgType, severity = apimodels.AgentLogPrefix, apimodels.LogErrorPrefix
+			}
+			chunk.Messages = append(chunk.Messages, apimodels.LogMessage{
+				Type:      msgType,
+				Severity:  severity,
+				Message:   fmt.Sprintf("line %d", i*4+j),
+				Timestamp: time.Now(),
+			})
+		}
+		logs = append(logs, chunk)
+	}
+	logs = append(logs, serviceModel.TaskLog{
+		TaskId:    "task",
+		Execution: 0,
+		Messages:  []apimodels.LogMessage{{Type: "task", Severity: apimodels.LogInfoPrefix, Message: "old"}},
+	})
+
+	s.sc = &data.MockConnector{
+		MockTaskConnector: data.MockTaskConnector{
+			CachedTasks: []task.Task{{Id: "task", Execution: 1, Status: evergreen.TaskSucceeded}},
+		},
+		MockTaskLogConnector: data.MockTaskLogConnector{
+			CachedLogs: map[string][]serviceModel.TaskLog{"task": logs},
+		},
+	}
+
+	s.ctx = context.WithValue(context.Background(), RequestContext, &serviceModel.Context{
+		Task: &s.sc.MockTaskConnector.CachedTasks[0],
+	})
+	s.ctx = context.WithValue(s.ctx, evergreen.RequestUser, &user.DBUser{Id: "user"})
+}
+
+func (s *TaskLogRouteSuite) request(url string, header http.Header) (RequestHandler, error) {
+	handler := s.rm.Methods[0].RequestHandler.Handler()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	s.Require().NoError(err)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	return handler, handler.ParseAndValidate(s.ctx, req)
+}
+
+func (s *TaskLogRouteSuite) messages(resp ResponseData) []string {
+	msgs := []string{}
+	for _, m := range resp.Result {
+		msgs = append(msgs, string(m.(*model.APILogMessage).Message))
+	}
+	return msgs
+}
+
+func (s *TaskLogRouteSuite) TestGetPage() {
+	handler, err := s.request("/tasks/task/logs?offset=2&limit=4", nil)
+	s.Require().NoError(err)
+	resp, err := handler.Execute(s.ctx, s.sc)
+	s.Require().NoError(err)
+	s.Equal([]string{"line 2", "line 3", "line 4", "line 5"}, s.messages(resp))
+
+	msg := resp.Result[1].(*model.APILogMessage)
+	s.Equal(model.APIString("agent"), msg.Type)
+	s.Equal(model.APIString("error"), msg.Severity)
+
+	pages := resp.Metadata.(*PaginationMetadata).Pages
+	s.Require().NotNil(pages.Next)
+	s.Equal("6", pages.Next.Key)
+	s.Require().NotNil(pages.Prev)
+	s.Equal("0", pages.Prev.Key)
+	s.Equal(2, pages.Prev.Limit)
+}
+
+func (s *TaskLogRouteSuite) TestGetFiltered() {
+	handler, err := s.request("/tasks/task/logs?type=task&severity=info,W", nil)
+	s.Require().NoError(err)
+	resp, err := handler.Execute(s.ctx, s.sc)
+	s.Require().NoError(err)
+	s.Len(resp.Result, 9)
+	s.Nil(resp.Metadata.(*PaginationMetadata).Pages.Next)
+
+	handler, err = s.request("/tasks/task/logs?severity=error&offset=1", nil)
+	s.Require().NoError(err)
+	resp, err = handler.Execute(s.ctx, s.sc)
+	s.Require().NoError(err)
+	s.Equal([]string{"line 7", "line 11"}, s.messages(resp))
+}
+
+func (s *TaskLogRouteSuite) TestGetEarlierExecution() {
+	handler, err := s.request("/tasks/task/logs?execution=0&type=task", nil)
+	s.Require().NoError(err)
+	resp, err := handler.Execute(s.ctx, s.sc)
+	s.Require().NoError(err)
+	s.Equal([]string{"old"}, s.messages(resp))
+}
+
+func (s *TaskLogRouteSuite) TestInvalidParams() {
+	for _, url := range []string{
+		"/tasks/task/logs?type=build",
+		"/tasks/task/logs?severity=fatal",
+		"/tasks/task/logs?offset=-1",
+		"/tasks/task/logs?execution=latest",
+		"/tasks/task/logs?follow=sometimes",
+	} {
+		_, err := s.request(url, nil)
+		s.Error(err, url)
+	}
+
+	s.ctx = context.WithValue(s.ctx, RequestContext, &serviceModel.Context{})
+	_, err := s.request("/tasks/task/logs", nil)
+	s.Error(err)
+}
+
+func (s *TaskLogRouteSuite) follow(url string, header http.Header) *httptest.ResponseRecorder {
+	handler, err := s.request(url, header)
+	s.Require().NoError(err)
+	resp, err := handler.Execute(s.ctx, s.sc)
+	s.Require().NoError(err)
+	streamer, ok := resp.Metadata.(ResponseStreamer)
+	s.Require().True(ok)
+
+	w := httptest.NewRecorder()
+	s.NoError(streamer.Stream(s.ctx, w))
+	return w
+}
+
+func (s *TaskLogRouteSuite) TestFollowFinishedTask() {
+	w := s.follow("/tasks/task/logs?follow=true&type=agent", nil)
+	s.Equal(http.StatusOK, w.Code)
+	s.Equal(ndjsonContentType, w.Header().Get("Content-Type"))
+	s.Equal("true", w.Result().Trailer.Get(evergreen.TaskLogCompleteTrailerKey))
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	s.Require().Len(lines, 3)
+	msg := model.APILogMessage{}
+	s.NoError(json.Unmarshal([]byte(lines[2]), &msg))
+	s.Equal(model.APIString("line 11"), msg.Message)
+}
+
+func (s *TaskLogRouteSuite) TestFollowEventStream() {
+	header := http.Header{}
+	header.Set("Accept", eventStreamContentType)
+	header.Set("Last-Event-ID", "10")
+	w := s.follow("/tasks/task/logs?follow=1", header)
+	s.Equal(eventStreamContentType, w.Header().Get("Content-Type"))
+
+	body := w.Body.String()
+	s.Equal(2, strings.Count(body, "event: log\n"))
+	s.Contains(body, "id: 11\nevent: log\n")
+	s.Contains(body, `"message":"line 11"`)
+	s.NotContains(body, `"message":"line 9"`)
+	s.True(strings.HasSuffix(body, "event: end\ndata: {}\n\n"))
+}
+
+func (s *TaskLogRouteSuite) TestFollowRunningTaskEndsAtDeadline() {
+	s.sc.MockTaskConnector.CachedTasks[0].Status = evergreen.TaskStarted
+	streamer := &taskLogStreamer{
+		sc:           s.sc,
+		args:         taskLogGetHandlerArgs{taskId: "task", execution: 1},
+		offset:       11,
+		pollInterval: time.Millisecond,
+		maxDuration:  20 * time.Millisecond,
+	}
+
+	w := httptest.NewRecorder()
+	s.NoError(streamer.Stream(s.ctx, w))
+	s.Equal("false", w.Result().Trailer.Get(evergreen.TaskLogCompleteTrailerKey))
+	s.Equal(1, strings.Count(w.Body.String(), "\n"))
+}
+
+func (s *TaskLogRouteSuite) TestFollowStopsWhenCanceled() {
+	s.sc.MockTaskConnector.CachedTasks[0].Status = evergreen.TaskStarted
+	streamer := &taskLogStreamer{
+		sc:           s.sc,
+		args:         taskLogGetHandlerArgs{taskId: "task", execution: 1},
+		pollInterval: time.Hour,
+		maxDuration:  time.Hour,
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	cancel()
+	w := httptest.NewRecorder()
+	s.NoError(streamer.Stream(ctx, w))
+	s.Equal(12, strings.Count(w.Body.String(), "\n"))
+}
+
+// growingLogConnector appends to the log each time it is read, and marks
+// the task finished on the last read.
+type growingLogConnector struct {
+	*data.MockConnector
+	reads int
+}
+
+func (c *growingLogConnector) FindTaskLogs(taskId string, execution, skip int) ([]serviceModel.TaskLog, error) {
+	c.reads++
+	logs := c.MockTaskLogConnector.CachedLogs[taskId]
+	switch c.reads {
+	case 2:
+		logs[2].Messages = append(logs[2].Messages, apimodels.LogMessage{Type: apimodels.TaskLogPrefix, Message: "appended"})
+	case 3:
+		logs = append(logs, serviceModel.TaskLog{
+			TaskId:    taskId,
+			Execution: execution,
+			Messages:  []apimodels.LogMessage{{Type: apimodels.TaskLogPrefix, Message: "new chunk"}},
+		})
+		c.MockTaskConnector.CachedTasks[0].Status = evergreen.TaskFailed
+	}
+	c.MockTaskLogConnector.CachedLogs[taskId] = logs
+	return c.MockConnector.FindTaskLogs(taskId, execution, skip)
+}
+
+func (s *TaskLogRouteSuite) TestFollowReadsAppendedMessages() {
+	s.sc.MockTaskConnector.CachedTasks[0].Status = evergreen.TaskStarted
+	streamer := &taskLogStreamer{
+		sc:           &growingLogConnector{MockConnector: s.sc},
+		args:         taskLogGetHandlerArgs{taskId: "task", execution: 1, msgTypes: []string{apimodels.TaskLogPrefix}},
+		pollInterval: time.Millisecond,
+		maxDuration:  time.Minute,
+	}
+
+	w := httptest.NewRecorder()
+	s.NoError(streamer.Stream(s.ctx, w))
+	s.Equal("true", w.Result().Trailer.Get(evergreen.TaskLogCompleteTrailerKey))
+
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	s.Require().Len(lines, 11)
+	s.Contains(lines[9], `"message":"appended"`)
+	s.Contains(lines[10], `"message":"new chunk"`)
+}
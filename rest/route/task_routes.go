@@ -10,6 +10,7 @@ import (
 	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/auth"
 	serviceModel "github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/role"
 	"github.com/evergreen-ci/evergreen/model/task"
 	"github.com/evergreen-ci/evergreen/rest"
 	"github.com/evergreen-ci/evergreen/rest/data"
@@ -27,7 +28,7 @@ func getTaskRestartRouteManager(route string, version int) *RouteManager {
 	trh := &taskRestartHandler{}
 	taskRestart := MethodHandler{
 		PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
-		Authenticator:     &RequireUserAuthenticator{},
+		Authenticator:     &PermissionAuthenticator{Permission: role.TaskRestart},
 		RequestHandler:    trh.Handler(),
 		MethodType:        http.MethodPost,
 	}
@@ -43,7 +44,7 @@ func getTaskRestartRouteManager(route string, version int) *RouteManager {
 func getTasksByBuildRouteManager(route string, version int) *RouteManager {
 	tbh := &tasksByBuildHandler{}
 	tasksByBuild := MethodHandler{
-		PrefetchFunctions: []PrefetchFunc{PrefetchUser},
+		PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
 		Authenticator:     &RequireUserAuthenticator{},
 		RequestHandler:    tbh.Handler(),
 		MethodType:        http.MethodGet,
@@ -60,15 +61,15 @@ func getTasksByBuildRouteManager(route string, version int) *RouteManager {
 func getTaskRouteManager(route string, version int) *RouteManager {
 	tep := &TaskExecutionPatchHandler{}
 	taskExecutionPatch := MethodHandler{
-		PrefetchFunctions: []PrefetchFunc{PrefetchProjectContext, PrefetchUser},
-		Authenticator:     &NoAuthAuthenticator{},
+		PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
+		Authenticator:     &PermissionAuthenticator{Permission: role.TaskRestart},
 		RequestHandler:    tep.Handler(),
 		MethodType:        http.MethodPatch,
 	}
 
 	tgh := &taskGetHandler{}
 	taskGet := MethodHandler{
-		PrefetchFunctions: []PrefetchFunc{PrefetchUser},
+		PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
 		Authenticator:     &RequireUserAuthenticator{},
 		RequestHandler:    tgh.Handler(),
 		MethodType:        http.MethodGet,
@@ -85,7 +86,7 @@ func getTaskRouteManager(route string, version int) *RouteManager {
 func getTasksByProjectAndCommitRouteManager(route string, version int) *RouteManager {
 	tph := &tasksByProjectHandler{}
 	tasksByProj := MethodHandler{
-		PrefetchFunctions: []PrefetchFunc{PrefetchUser},
+		PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
 		Authenticator:     &RequireUserAuthenticator{},
 		RequestHandler:    tph.Handler(),
 		MethodType:        http.MethodGet,
@@ -106,9 +107,9 @@ func getTaskAbortManager(route string, version int) *RouteManager {
 		Version: version,
 		Methods: []MethodHandler{
 			{
-				PrefetchFunctions: []PrefetchFunc{PrefetchUser},
+				PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
 				MethodType:        http.MethodPost,
-				Authenticator:     &RequireUserAuthenticator{},
+				Authenticator:     &PermissionAuthenticator{Permission: role.TaskRestart},
 				RequestHandler:    t.Handler(),
 			},
 		},
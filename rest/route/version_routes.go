@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/evergreen-ci/evergreen/model/role"
 	"github.com/evergreen-ci/evergreen/rest"
 	"github.com/evergreen-ci/evergreen/rest/data"
 	"github.com/evergreen-ci/evergreen/rest/model"
@@ -26,9 +27,10 @@ func getVersionIdRouteManager(route string, version int) *RouteManager {
 		Route: route,
 		Methods: []MethodHandler{
 			{
-				Authenticator:  &NoAuthAuthenticator{},
-				RequestHandler: &versionHandler{},
-				MethodType:     http.MethodGet,
+				PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
+				Authenticator:     &NoAuthAuthenticator{},
+				RequestHandler:    &versionHandler{},
+				MethodType:        http.MethodGet,
 			},
 		},
 		Version: version,
@@ -85,9 +87,10 @@ func getBuildsForVersionRouteManager(route string, version int) *RouteManager {
 		Route: route,
 		Methods: []MethodHandler{
 			{
-				Authenticator:  &NoAuthAuthenticator{},
-				RequestHandler: &buildsForVersionHandler{},
-				MethodType:     http.MethodGet,
+				PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
+				Authenticator:     &NoAuthAuthenticator{},
+				RequestHandler:    &buildsForVersionHandler{},
+				MethodType:        http.MethodGet,
 			},
 		},
 		Version: version,
@@ -158,8 +161,8 @@ func getAbortVersionRouteManager(route string, version int) *RouteManager {
 		Route: route,
 		Methods: []MethodHandler{
 			{
-				PrefetchFunctions: []PrefetchFunc{PrefetchUser},
-				Authenticator:     &RequireUserAuthenticator{},
+				PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
+				Authenticator:     &PermissionAuthenticator{Permission: role.TaskRestart},
 				RequestHandler:    &versionAbortHandler{},
 				MethodType:        http.MethodPost,
 			},
@@ -227,8 +230,8 @@ func getRestartVersionRouteManager(route string, version int) *RouteManager {
 		Route: route,
 		Methods: []MethodHandler{
 			{
-				PrefetchFunctions: []PrefetchFunc{PrefetchUser},
-				Authenticator:     &RequireUserAuthenticator{},
+				PrefetchFunctions: []PrefetchFunc{PrefetchUser, PrefetchProjectContext},
+				Authenticator:     &PermissionAuthenticator{Permission: role.TaskRestart},
 				RequestHandler:    &versionRestartHandler{},
 				MethodType:        http.MethodPost,
 			},
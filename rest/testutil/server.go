@@ -26,6 +26,7 @@ func NewTestServerFromSettings(settings *evergreen.Settings) (*httptest.Server,
 
 	sc.SetPrefix(evergreen.RestRoutePrefix)
 	sc.SetSuperUsers(settings.SuperUsers)
+	sc.SetRBACConfig(settings.RBAC)
 
 	return NewTestServerFromConnector(testServerPort, sc)
 }
@@ -0,0 +1,92 @@
+package scheduler
+
+import (
+	"sort"
+
+	"github.com/evergreen-ci/evergreen/model/distro"
+	"github.com/evergreen-ci/evergreen/model/host"
+	"github.com/pkg/errors"
+)
+
+// distroSelector chooses distros for tasks that declare distro requirements
+// instead of naming their distros. Each task goes to the cheapest distro that
+// satisfies its requirements and has free capacity, which is the number of
+// hosts the distro can still start plus its idle hosts. (Static distros can't
+// start hosts, so their capacity is only their idle hosts.) Tasks use up the
+// capacity of the distro they're placed on, so that tasks spill over to more
+// expensive distros as the cheaper ones fill up.
+type distroSelector struct {
+	distros  []distro.Distro
+	capacity map[string]int
+}
+
+// distrosByCost sorts distros from cheapest to most expensive, and by ID
+// among distros that cost the same.
+type distrosByCost []distro.Distro
+
+func (d distrosByCost) Len() int      { return len(d) }
+func (d distrosByCost) Swap(i, j int) { d[i], d[j] = d[j], d[i] }
+func (d distrosByCost) Less(i, j int) bool {
+	if d[i].HourlyCost != d[j].HourlyCost {
+		return d[i].HourlyCost < d[j].HourlyCost
+	}
+	return d[i].Id < d[j].Id
+}
+
+func newDistroSelector(distros []distro.Distro, hosts []host.Host) *distroSelector {
+	s := &distroSelector{
+		distros:  make([]distro.Distro, len(distros)),
+		capacity: make(map[string]int, len(distros)),
+	}
+	copy(s.distros, distros)
+	sort.Stable(distrosByCost(s.distros))
+
+	live := map[string]int{}
+	busy := map[string]int{}
+	for _, h := range hosts {
+		live[h.Distro.Id]++
+		if h.RunningTask != "" {
+			busy[h.Distro.Id]++
+		}
+	}
+	for _, d := range s.distros {
+		size := d.PoolSize
+		if live[d.Id] > size {
+			size = live[d.Id]
+		}
+		s.capacity[d.Id] = size - busy[d.Id]
+	}
+
+	return s
+}
+
+// findDistroSelector loads the live hosts to construct a distroSelector.
+func findDistroSelector(distros []distro.Distro) (*distroSelector, error) {
+	hosts, err := host.Find(host.IsLive)
+	if err != nil {
+		return nil, errors.Wrap(err, "error finding live hosts")
+	}
+	return newDistroSelector(distros, hosts), nil
+}
+
+// selectDistro returns the ID of the cheapest distro with free capacity that
+// satisfies the requirements, and uses up one host of its capacity. If all
+// of the distros that satisfy the requirements are full, it returns the
+// cheapest one. It returns an empty string if no distro satisfies them.
+func (s *distroSelector) selectDistro(reqs *distro.Requirements) string {
+	cheapest := ""
+	for i := range s.distros {
+		d := &s.distros[i]
+		if !reqs.SatisfiedBy(d) {
+			continue
+		}
+		if s.capacity[d.Id] > 0 {
+			s.capacity[d.Id]--
+			return d.Id
+		}
+		if cheapest == "" {
+			cheapest = d.Id
+		}
+	}
+	return cheapest
+}
@@ -0,0 +1,78 @@
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/evergreen-ci/evergreen/model/distro"
+	"github.com/evergreen-ci/evergreen/model/host"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistroSelector(t *testing.T) {
+	assert := assert.New(t)
+
+	distros := []distro.Distro{
+		{
+			Id:           "large",
+			Arch:         "linux_amd64",
+			PoolSize:     5,
+			HourlyCost:   2,
+			Capabilities: distro.Capabilities{OS: "linux", CPUs: 16, MemoryGB: 64},
+		},
+		{
+			Id:           "small",
+			Arch:         "linux_amd64",
+			PoolSize:     1,
+			HourlyCost:   0.5,
+			Capabilities: distro.Capabilities{OS: "linux", CPUs: 2, MemoryGB: 4},
+		},
+		{
+			Id:           "medium",
+			Arch:         "linux_amd64",
+			PoolSize:     2,
+			HourlyCost:   1,
+			Capabilities: distro.Capabilities{OS: "linux", CPUs: 8, MemoryGB: 32, Docker: true},
+		},
+		{
+			// static distros can have more hosts than their pool size
+			Id:           "static",
+			Arch:         "windows_amd64",
+			HourlyCost:   1,
+			Capabilities: distro.Capabilities{OS: "windows", CPUs: 4, MemoryGB: 16},
+		},
+	}
+	hosts := []host.Host{
+		{Id: "m1", Distro: distros[2], RunningTask: "t1"},
+		{Id: "s1", Distro: distros[3]},
+		{Id: "s2", Distro: distros[3], RunningTask: "t2"},
+		{Id: "s3", Distro: distros[3]},
+	}
+	s := newDistroSelector(distros, hosts)
+
+	assert.Equal([]string{"small", "medium", "static", "large"},
+		[]string{s.distros[0].Id, s.distros[1].Id, s.distros[2].Id, s.distros[3].Id})
+	assert.Equal(map[string]int{"small": 1, "medium": 1, "static": 2, "large": 5}, s.capacity)
+
+	// tasks go to the cheapest distro with capacity that satisfies them,
+	// spilling over as distros fill up
+	linux := &distro.Requirements{OS: "linux"}
+	assert.Equal("small", s.selectDistro(linux))
+	assert.Equal("medium", s.selectDistro(linux))
+	assert.Equal("large", s.selectDistro(linux))
+
+	// when every satisfying distro is full, the cheapest one is used
+	docker := true
+	containers := &distro.Requirements{MinCPUs: 4, Docker: &docker}
+	assert.Equal("medium", s.selectDistro(containers))
+	assert.Equal(0, s.capacity["medium"])
+
+	windows := &distro.Requirements{Arch: "windows_amd64"}
+	assert.Equal("static", s.selectDistro(windows))
+	assert.Equal("static", s.selectDistro(windows))
+	assert.Equal("static", s.selectDistro(windows))
+	assert.Equal(0, s.capacity["static"])
+
+	// no distro satisfies the requirements
+	assert.Equal("", s.selectDistro(&distro.Requirements{MinMemoryGB: 128}))
+	assert.Equal("", s.selectDistro(&distro.Requirements{Tags: []string{"gpu"}}))
+}
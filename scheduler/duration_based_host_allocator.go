@@ -230,15 +230,30 @@ func computeRunningTasksDuration(existingDistroHosts []host.Host,
 		runningTasksMap[runningTask.Id] = runningTask
 	}
 
+	return computeRunningTasksDurationAt(existingDistroHosts, runningTasksMap,
+		taskDurations, time.Now())
+}
+
+// computeRunningTasksDurationAt returns the estimated time to completion, as
+// of the given time, of the tasks running on the given hosts, looking the
+// tasks up in the given map of task id => task
+func computeRunningTasksDurationAt(existingDistroHosts []host.Host,
+	runningTasksMap map[string]task.Task, taskDurations model.ProjectTaskDurations,
+	now time.Time) (runningTasksDuration float64, err error) {
+
 	// compute the total time to completion for running tasks
-	for _, runningTaskId := range runningTaskIds {
+	for _, existingDistroHost := range existingDistroHosts {
+		runningTaskId := existingDistroHost.RunningTask
+		if runningTaskId == "" {
+			continue
+		}
 		runningTask, ok := runningTasksMap[runningTaskId]
 		if !ok {
 			return runningTasksDuration, errors.Errorf(
 				"Unable to find running task with _id %v", runningTaskId)
 		}
 		expectedDuration := model.GetTaskExpectedDuration(runningTask, taskDurations)
-		elapsedTime := time.Since(runningTask.StartTime)
+		elapsedTime := now.Sub(runningTask.StartTime)
 		if elapsedTime > expectedDuration {
 			// probably an outlier; or an unknown data point
 			continue
@@ -452,8 +467,14 @@ func (self *DurationBasedHostAllocator) numNewHostsForDistro(
 
 	// determine the total remaining running time of all
 	// tasks currently running on the hosts for this distro
-	runningTasksDuration, err := computeRunningTasksDuration(
-		existingDistroHosts, projectTaskDurations)
+	var runningTasksDuration float64
+	if hostAllocatorData.runningTasks != nil {
+		runningTasksDuration, err = computeRunningTasksDurationAt(existingDistroHosts,
+			hostAllocatorData.runningTasks, projectTaskDurations, hostAllocatorData.now)
+	} else {
+		runningTasksDuration, err = computeRunningTasksDuration(
+			existingDistroHosts, projectTaskDurations)
+	}
 
 	if err != nil {
 		return numNewHosts, err
@@ -478,17 +499,21 @@ func (self *DurationBasedHostAllocator) numNewHostsForDistro(
 		scheduledTasksDuration, runningTasksDuration,
 		float64(len(existingDistroHosts)), MaxDurationPerDistroHost)
 
+	// task groups can only use as many hosts as their max hosts, however
+	// many tasks they have queued
+	taskQueueLength := numHostsForQueue(taskQueueItems)
+
 	// revise the new host estimate based on the cap of the number of new hosts
 	// and the number of free hosts
 	numNewHosts = numNewDistroHosts(distro.PoolSize, len(existingDistroHosts),
-		numFreeHosts, durationBasedNumNewHosts, len(taskQueueItems))
+		numFreeHosts, durationBasedNumNewHosts, taskQueueLength)
 
 	// create an entry for this distro in the scheduling map
 	distroData := DistroScheduleData{
 		nominalNumNewHosts:   numNewHosts,
 		numFreeHosts:         numFreeHosts,
 		poolSize:             distro.PoolSize,
-		taskQueueLength:      len(taskQueueItems),
+		taskQueueLength:      taskQueueLength,
 		sharedTasksDuration:  sharedTasksDuration,
 		runningTasksDuration: runningTasksDuration,
 		numExistingHosts:     len(existingDistroHosts),
@@ -555,6 +580,17 @@ func (self *DurationBasedHostAllocator) numNewHostsForDistro(
 	numNewHosts = orderedScheduleNumNewHosts(distroScheduleData, distro.Id,
 		MaxDurationPerDistroHost, SharedTasksAllocationProportion)
 
+	// make sure that there are enough hosts for every project with
+	// queued tasks to get its guaranteed share of the distro
+	guaranteedHosts := guaranteedHostsNeeded(&distro, taskQueueItems,
+		hostAllocatorData.runningTasksByProject[distro.Id]) - numFreeHosts
+	if guaranteedHosts > numNewHosts {
+		numNewHosts = util.Min(guaranteedHosts, distro.PoolSize-len(existingDistroHosts))
+		if numNewHosts < 0 {
+			numNewHosts = 0
+		}
+	}
+
 	estRuntime := time.Duration(scheduledTasksDuration+runningTasksDuration) * time.Second
 	curTasksRuntime := time.Duration(runningTasksDuration) * time.Second
 	schTasksRuntime := time.Duration(scheduledTasksDuration) * time.Second
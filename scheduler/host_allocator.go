@@ -1,10 +1,13 @@
 package scheduler
 
 import (
+	"time"
+
 	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/distro"
 	"github.com/evergreen-ci/evergreen/model/host"
+	"github.com/evergreen-ci/evergreen/model/task"
 )
 
 // HostAllocator is responsible for determining how many new hosts should be spun up.
@@ -14,6 +17,7 @@ import (
 //  taskQueueItems: a map of distro name -> task queue items for that distro (a TaskQueue object)
 //  projectTaskDurations: the expected duration of tasks by project and variant
 //  taskRunDistros: a map of task id -> distros the task is allowed to run on
+//  runningTasksByProject: a map of distro name -> the number of tasks each project is running on it
 // Returns a map of distro name -> how many hosts need to be spun up for that distro.
 type HostAllocator interface {
 	NewHostsNeeded(allocatorData HostAllocatorData, settings *evergreen.Settings) (map[string]int, error)
@@ -26,4 +30,14 @@ type HostAllocatorData struct {
 	taskRunDistros       map[string][]string
 	distros              map[string]distro.Distro
 	projectTaskDurations model.ProjectTaskDurations
+
+	// distro name -> project -> number of the project's tasks running
+	// on the distro, for distros with project quotas
+	runningTasksByProject map[string]map[string]int
+
+	// when set, the tasks running on the distros' hosts by id, and the
+	// current time; these stand in for the database and the wall clock
+	// when the allocator is run by a simulation
+	runningTasks map[string]task.Task
+	now          time.Time
 }
@@ -0,0 +1,185 @@
+package scheduler
+
+import (
+	"sort"
+
+	"github.com/evergreen-ci/evergreen/model/distro"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/pkg/errors"
+)
+
+// prioritizationStrategy describes how a distro's task queue is
+// ordered: the factors weighed when comparing two tasks, in order of
+// their default weight, and whether patch tasks are queued ahead of
+// commit tasks rather than interleaved with them.
+type prioritizationStrategy struct {
+	factors    []weightedFactor
+	patchFirst bool
+}
+
+type weightedFactor struct {
+	name   string
+	weight int
+}
+
+// prioritizationFactors maps the name of each factor to the comparator
+// that implements it.
+var prioritizationFactors = map[string]taskPriorityCmp{
+	distro.PrioritizationFactorPriority:        byPriority,
+	distro.PrioritizationFactorNumDeps:         byNumDeps,
+	distro.PrioritizationFactorAge:             byAge,
+	distro.PrioritizationFactorCreateTime:      byCreateTime,
+	distro.PrioritizationFactorRuntime:         byRuntime,
+	distro.PrioritizationFactorShortestRuntime: byShortestRuntime,
+	distro.PrioritizationFactorSimilarFailing:  bySimilarFailing,
+	distro.PrioritizationFactorRecentlyFailing: byRecentlyFailing,
+	distro.PrioritizationFactorFairShare:       byFairShare,
+}
+
+// prioritizationFactorSetup maps factors to the name of the setup
+// function that caches the data they need; factors may share a setup
+// function.
+var prioritizationFactorSetup = map[string]string{
+	distro.PrioritizationFactorRuntime:         "previous_tasks",
+	distro.PrioritizationFactorRecentlyFailing: "previous_tasks",
+	distro.PrioritizationFactorSimilarFailing:  "similar_failing",
+	distro.PrioritizationFactorFairShare:       "fair_share",
+}
+
+var sortSetupFuncs = map[string]sortSetupFunc{
+	"previous_tasks":  cachePreviousTasks,
+	"similar_failing": cacheSimilarFailing,
+	"fair_share":      cacheFairShareRanks,
+}
+
+// prioritizationStrategies holds the named strategies that distros may
+// select. The default weights of each strategy are powers of two, so
+// that by default every factor only breaks ties left by the factors
+// before it.
+var prioritizationStrategies = map[string]prioritizationStrategy{
+	distro.PrioritizationStrategyDefault: {
+		factors: []weightedFactor{
+			{distro.PrioritizationFactorPriority, 32},
+			{distro.PrioritizationFactorNumDeps, 16},
+			{distro.PrioritizationFactorAge, 8},
+			{distro.PrioritizationFactorRuntime, 4},
+			{distro.PrioritizationFactorSimilarFailing, 2},
+			{distro.PrioritizationFactorRecentlyFailing, 1},
+		},
+	},
+	distro.PrioritizationStrategyFIFO: {
+		factors: []weightedFactor{
+			{distro.PrioritizationFactorPriority, 2},
+			{distro.PrioritizationFactorCreateTime, 1},
+		},
+	},
+	distro.PrioritizationStrategyShortestJobFirst: {
+		factors: []weightedFactor{
+			{distro.PrioritizationFactorPriority, 4},
+			{distro.PrioritizationFactorShortestRuntime, 2},
+			{distro.PrioritizationFactorCreateTime, 1},
+		},
+	},
+	distro.PrioritizationStrategyFairShare: {
+		factors: []weightedFactor{
+			{distro.PrioritizationFactorPriority, 8},
+			{distro.PrioritizationFactorFairShare, 4},
+			{distro.PrioritizationFactorNumDeps, 2},
+			{distro.PrioritizationFactorCreateTime, 1},
+		},
+	},
+	distro.PrioritizationStrategyPatchFirst: {
+		factors: []weightedFactor{
+			{distro.PrioritizationFactorPriority, 4},
+			{distro.PrioritizationFactorNumDeps, 2},
+			{distro.PrioritizationFactorCreateTime, 1},
+		},
+		patchFirst: true,
+	},
+}
+
+// newCmpBasedTaskComparatorForDistro returns a task comparator that
+// implements the distro's prioritization strategy, using the distro's
+// weights where they override the strategy's defaults. Factors that
+// are only given a weight by the distro are weighed after the
+// strategy's own factors.
+func newCmpBasedTaskComparatorForDistro(d *distro.Distro) (*CmpBasedTaskComparator, error) {
+	strategy, ok := prioritizationStrategies[d.Prioritization.GetStrategy()]
+	if !ok {
+		return nil, errors.Errorf("distro '%s' has unknown prioritization strategy '%s'",
+			d.Id, d.Prioritization.Strategy)
+	}
+
+	factors := []weightedFactor{}
+	seen := map[string]bool{}
+	for _, f := range strategy.factors {
+		if w, ok := d.Prioritization.Weights[f.name]; ok {
+			f.weight = w
+		}
+		factors = append(factors, f)
+		seen[f.name] = true
+	}
+
+	extra := []string{}
+	for name := range d.Prioritization.Weights {
+		if !seen[name] {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+	for _, name := range extra {
+		factors = append(factors, weightedFactor{name: name, weight: d.Prioritization.Weights[name]})
+	}
+
+	comparator := &CmpBasedTaskComparator{patchFirst: strategy.patchFirst}
+	setupSeen := map[string]bool{}
+	for _, f := range factors {
+		if f.weight == 0 {
+			continue
+		}
+
+		cmp, ok := prioritizationFactors[f.name]
+		if !ok {
+			return nil, errors.Errorf("distro '%s' has unknown prioritization factor '%s'", d.Id, f.name)
+		}
+		comparator.comparators = append(comparator.comparators, cmp)
+		comparator.weights = append(comparator.weights, f.weight)
+
+		if setup, ok := prioritizationFactorSetup[f.name]; ok && !setupSeen[setup] {
+			comparator.setupFuncs = append(comparator.setupFuncs, sortSetupFuncs[setup])
+			setupSeen[setup] = true
+		}
+	}
+
+	return comparator, nil
+}
+
+// cacheFairShareRanks ranks each task among the tasks of its own
+// project, by priority, number of dependents, and creation time. The
+// fair share factor prefers the task with the lower rank, so that the
+// queue takes turns between projects rather than being dominated by
+// the project with the most tasks.
+func cacheFairShareRanks(comparator *CmpBasedTaskComparator) error {
+	byProject := map[string][]task.Task{}
+	for _, t := range comparator.tasks {
+		byProject[t.Project] = append(byProject[t.Project], t)
+	}
+
+	comparator.fairShareRanks = make(map[string]int, len(comparator.tasks))
+	for _, tasks := range byProject {
+		projectComparator := &CmpBasedTaskComparator{
+			tasks:       tasks,
+			comparators: []taskPriorityCmp{byPriority, byNumDeps, byCreateTime},
+		}
+		sort.Stable(projectComparator)
+		if len(projectComparator.errsDuringSort) > 0 {
+			return errors.Wrap(projectComparator.errsDuringSort[0], "cacheFairShareRanks")
+		}
+
+		for rank, t := range projectComparator.tasks {
+			comparator.fairShareRanks[t.Id] = rank
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package scheduler
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model/distro"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func prioritizedTaskIds(tasks []task.Task) []string {
+	ids := make([]string, len(tasks))
+	for idx, t := range tasks {
+		ids[idx] = t.Id
+	}
+	return ids
+}
+
+func TestComparatorForDistro(t *testing.T) {
+	assert := assert.New(t)
+
+	comparator, err := newCmpBasedTaskComparatorForDistro(&distro.Distro{Id: "d"})
+	assert.NoError(err)
+	assert.Len(comparator.comparators, 6)
+	assert.Equal([]int{32, 16, 8, 4, 2, 1}, comparator.weights)
+	assert.Len(comparator.setupFuncs, 2)
+	assert.False(comparator.patchFirst)
+
+	comparator, err = newCmpBasedTaskComparatorForDistro(&distro.Distro{
+		Id: "d",
+		Prioritization: distro.PrioritizationSettings{
+			Strategy: distro.PrioritizationStrategyFIFO,
+			Weights: map[string]int{
+				distro.PrioritizationFactorPriority:        0,
+				distro.PrioritizationFactorRecentlyFailing: 5,
+				distro.PrioritizationFactorRuntime:         3,
+			},
+		},
+	})
+	assert.NoError(err)
+	assert.Equal([]int{1, 5, 3}, comparator.weights)
+	assert.Len(comparator.setupFuncs, 1)
+
+	comparator, err = newCmpBasedTaskComparatorForDistro(&distro.Distro{
+		Id:             "d",
+		Prioritization: distro.PrioritizationSettings{Strategy: distro.PrioritizationStrategyPatchFirst},
+	})
+	assert.NoError(err)
+	assert.True(comparator.patchFirst)
+
+	_, err = newCmpBasedTaskComparatorForDistro(&distro.Distro{
+		Id:             "d",
+		Prioritization: distro.PrioritizationSettings{Strategy: "lottery"},
+	})
+	assert.Error(err)
+
+	_, err = newCmpBasedTaskComparatorForDistro(&distro.Distro{
+		Id:             "d",
+		Prioritization: distro.PrioritizationSettings{Weights: map[string]int{"luck": 1}},
+	})
+	assert.Error(err)
+}
+
+func TestWeightedComparison(t *testing.T) {
+	assert := assert.New(t)
+	older := task.Task{Id: "older", CreateTime: time.Now().Add(-time.Hour), Priority: 1}
+	newer := task.Task{Id: "newer", CreateTime: time.Now(), Priority: 2}
+
+	comparator := &CmpBasedTaskComparator{
+		comparators: []taskPriorityCmp{byPriority, byCreateTime},
+		weights:     []int{2, 1},
+	}
+	moreImportant, err := comparator.taskMoreImportantThan(newer, older)
+	assert.NoError(err)
+	assert.True(moreImportant)
+
+	// a heavier factor outweighs the earlier one
+	comparator.weights = []int{1, 2}
+	moreImportant, err = comparator.taskMoreImportantThan(newer, older)
+	assert.NoError(err)
+	assert.False(moreImportant)
+	moreImportant, err = comparator.taskMoreImportantThan(older, newer)
+	assert.NoError(err)
+	assert.True(moreImportant)
+
+	// balanced factors are a tie
+	comparator.weights = []int{1, 1}
+	moreImportant, err = comparator.taskMoreImportantThan(older, newer)
+	assert.NoError(err)
+	assert.False(moreImportant)
+
+	comparator.weights = []int{1}
+	_, err = comparator.taskMoreImportantThan(older, newer)
+	assert.Error(err)
+}
+
+func TestPrioritizationStrategies(t *testing.T) {
+	start := time.Now()
+	newTask := func(id, project, requester string, age int, duration time.Duration) task.Task {
+		return task.Task{
+			Id:               id,
+			Project:          project,
+			Requester:        requester,
+			CreateTime:       start.Add(-time.Duration(age) * time.Minute),
+			ExpectedDuration: duration,
+		}
+	}
+	prioritize := func(t *testing.T, strategy string, tasks []task.Task) []string {
+		prioritizer := &CmpBasedTaskPrioritizer{}
+		d := &distro.Distro{
+			Id:             "d",
+			Prioritization: distro.PrioritizationSettings{Strategy: strategy},
+		}
+		out, err := prioritizer.PrioritizeTasks(d, &evergreen.Settings{}, tasks)
+		require.NoError(t, err)
+		return prioritizedTaskIds(out)
+	}
+	patch := evergreen.PatchVersionRequester
+
+	t.Run("FIFO", func(t *testing.T) {
+		tasks := []task.Task{
+			newTask("t1", "big", patch, 1, 0),
+			newTask("t2", "big", patch, 3, 0),
+			newTask("t3", "small", patch, 2, 0),
+		}
+		assert.Equal(t, []string{"t2", "t3", "t1"}, prioritize(t, distro.PrioritizationStrategyFIFO, tasks))
+	})
+
+	t.Run("ShortestJobFirst", func(t *testing.T) {
+		tasks := []task.Task{
+			newTask("t1", "big", patch, 3, time.Hour),
+			newTask("t2", "big", patch, 2, time.Minute),
+			newTask("t3", "small", patch, 1, 10*time.Minute),
+		}
+		assert.Equal(t, []string{"t2", "t3", "t1"}, prioritize(t, distro.PrioritizationStrategyShortestJobFirst, tasks))
+	})
+
+	t.Run("FairShare", func(t *testing.T) {
+		tasks := []task.Task{
+			newTask("big1", "big", patch, 10, 0),
+			newTask("big2", "big", patch, 9, 0),
+			newTask("big3", "big", patch, 8, 0),
+			newTask("big4", "big", patch, 7, 0),
+			newTask("small1", "small", patch, 2, 0),
+			newTask("small2", "small", patch, 1, 0),
+		}
+		assert.Equal(t, []string{"big1", "small1", "big2", "small2", "big3", "big4"},
+			prioritize(t, distro.PrioritizationStrategyFairShare, tasks))
+	})
+
+	t.Run("PatchFirst", func(t *testing.T) {
+		tasks := []task.Task{
+			newTask("commit1", "big", evergreen.RepotrackerVersionRequester, 10, 0),
+			newTask("patch1", "big", patch, 2, 0),
+			newTask("commit2", "big", evergreen.RepotrackerVersionRequester, 9, 0),
+			newTask("patch2", "small", patch, 1, 0),
+		}
+		assert.Equal(t, []string{"patch1", "patch2", "commit1", "commit2"},
+			prioritize(t, distro.PrioritizationStrategyPatchFirst, tasks))
+	})
+
+	t.Run("PeriodicBuilds", func(t *testing.T) {
+		tasks := []task.Task{
+			newTask("periodic1", "big", evergreen.AdHocVersionRequester, 3, 0),
+			newTask("patch1", "big", patch, 2, 0),
+			newTask("periodic2", "big", evergreen.AdHocVersionRequester, 1, 0),
+		}
+		// tasks of periodic builds are queued along with commit tasks
+		ids := prioritize(t, distro.PrioritizationStrategyFIFO, tasks)
+		sort.Strings(ids)
+		assert.Equal(t, []string{"patch1", "periodic1", "periodic2"}, ids)
+	})
+}
@@ -0,0 +1,124 @@
+package scheduler
+
+import (
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/distro"
+	"github.com/evergreen-ci/evergreen/model/task"
+)
+
+// applyProjectQuotas reorders a distro's prioritized task queue
+// according to the distro's project quotas, given the number of tasks
+// each project already has running on the distro.
+//
+// Tasks with a priority above the maximum task priority stay at the
+// front of the queue. They are followed by the tasks that fill each
+// project's guaranteed share, and then by the rest of the tasks; both
+// groups take turns between projects, keeping the prioritized order
+// within each project. Tasks that would put a project over its burst
+// ceiling are held back, and are considered again the next time the
+// distro is scheduled.
+func applyProjectQuotas(d *distro.Distro, tasks []task.Task, running map[string]int) ([]task.Task, []task.Task) {
+	if len(d.ProjectQuotas) == 0 {
+		return tasks, nil
+	}
+
+	usage := make(map[string]int, len(running))
+	for p, n := range running {
+		usage[p] = n
+	}
+
+	queued := make([]task.Task, 0, len(tasks))
+	held := []task.Task{}
+	guaranteed := newProjectTaskLists()
+	rest := newProjectTaskLists()
+
+	for _, t := range tasks {
+		if t.Priority > evergreen.MaxTaskPriority {
+			queued = append(queued, t)
+			usage[t.Project]++
+			continue
+		}
+
+		q, ok := d.GetProjectQuota(t.Project)
+		if ok && q.Ceiling > 0 && usage[t.Project] >= q.Ceiling {
+			held = append(held, t)
+			continue
+		}
+
+		usage[t.Project]++
+		if ok && usage[t.Project] <= q.Guaranteed {
+			guaranteed.add(t)
+		} else {
+			rest.add(t)
+		}
+	}
+
+	queued = append(queued, guaranteed.interleave()...)
+	queued = append(queued, rest.interleave()...)
+
+	return queued, held
+}
+
+// guaranteedHostsNeeded returns the number of hosts the distro needs to
+// provide every project with queued tasks its guaranteed share, beyond
+// the hosts already running the projects' tasks.
+func guaranteedHostsNeeded(d *distro.Distro, queue []model.TaskQueueItem, running map[string]int) int {
+	if len(d.ProjectQuotas) == 0 {
+		return 0
+	}
+
+	queued := map[string][]model.TaskQueueItem{}
+	for _, item := range queue {
+		queued[item.Project] = append(queued[item.Project], item)
+	}
+
+	needed := 0
+	for _, q := range d.ProjectQuotas {
+		deficit := q.Guaranteed - running[q.Project]
+		if numHosts := numHostsForQueue(queued[q.Project]); numHosts < deficit {
+			deficit = numHosts
+		}
+		if deficit > 0 {
+			needed += deficit
+		}
+	}
+
+	return needed
+}
+
+// projectTaskLists holds a list of tasks for each project, remembering
+// the order in which the projects were first seen.
+type projectTaskLists struct {
+	projects []string
+	tasks    map[string][]task.Task
+}
+
+func newProjectTaskLists() *projectTaskLists {
+	return &projectTaskLists{tasks: map[string][]task.Task{}}
+}
+
+func (l *projectTaskLists) add(t task.Task) {
+	if _, ok := l.tasks[t.Project]; !ok {
+		l.projects = append(l.projects, t.Project)
+	}
+	l.tasks[t.Project] = append(l.tasks[t.Project], t)
+}
+
+// interleave returns the tasks of all projects, taking one task from
+// each project in turn.
+func (l *projectTaskLists) interleave() []task.Task {
+	out := []task.Task{}
+	for idx := 0; ; idx++ {
+		added := false
+		for _, p := range l.projects {
+			if idx < len(l.tasks[p]) {
+				out = append(out, l.tasks[p][idx])
+				added = true
+			}
+		}
+		if !added {
+			return out
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package scheduler
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/distro"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyProjectQuotas(t *testing.T) {
+	assert := assert.New(t)
+
+	// high priority tasks are at the front of a prioritized queue
+	tasks := []task.Task{{Id: "urgent", Project: "big", Priority: 101}}
+	for i := 0; i < 6; i++ {
+		tasks = append(tasks, task.Task{Id: fmt.Sprintf("big%d", i), Project: "big"})
+	}
+	tasks = append(tasks,
+		task.Task{Id: "small0", Project: "small"},
+		task.Task{Id: "small1", Project: "small"},
+		task.Task{Id: "other0", Project: "other"},
+	)
+
+	// without quotas, the queue is unchanged
+	d := &distro.Distro{Id: "d"}
+	queued, held := applyProjectQuotas(d, tasks, nil)
+	assert.Equal(tasks, queued)
+	assert.Empty(held)
+
+	d.ProjectQuotas = []distro.ProjectQuota{
+		{Project: "big", Ceiling: 4},
+		{Project: "small", Guaranteed: 2},
+	}
+	queued, held = applyProjectQuotas(d, tasks, map[string]int{"big": 1})
+	assert.Equal([]string{"urgent", "small0", "small1", "big0", "other0", "big1"}, prioritizedTaskIds(queued))
+	assert.Equal([]string{"big2", "big3", "big4", "big5"}, prioritizedTaskIds(held))
+
+	// a project already using its guaranteed share gets no head start
+	queued, held = applyProjectQuotas(d, tasks, map[string]int{"big": 1, "small": 2})
+	assert.Equal([]string{"urgent", "big0", "small0", "other0", "big1", "small1"}, prioritizedTaskIds(queued))
+	assert.Len(held, 4)
+}
+
+func TestGuaranteedHostsNeeded(t *testing.T) {
+	assert := assert.New(t)
+	d := &distro.Distro{
+		Id: "d",
+		ProjectQuotas: []distro.ProjectQuota{
+			{Project: "big", Guaranteed: 5},
+			{Project: "small", Guaranteed: 3},
+			{Project: "idle", Guaranteed: 10},
+		},
+	}
+	queue := []model.TaskQueueItem{
+		{Id: "b1", Project: "big"},
+		{Id: "b2", Project: "big"},
+		{Id: "b3", Project: "big"},
+		{Id: "s1", Project: "small"},
+		{Id: "s2", Project: "small"},
+	}
+
+	// big is limited by its queued tasks, small by its running tasks,
+	// and idle has no tasks queued
+	assert.Equal(4, guaranteedHostsNeeded(d, queue, map[string]int{"small": 2}))
+	assert.Equal(0, guaranteedHostsNeeded(&distro.Distro{Id: "d"}, queue, nil))
+
+	// a task group can only use its max hosts
+	queue = []model.TaskQueueItem{
+		{Id: "b1", Project: "big", Group: "tests", GroupMaxHosts: 1},
+		{Id: "b2", Project: "big", Group: "tests", GroupMaxHosts: 1},
+		{Id: "b3", Project: "big", Group: "tests", GroupMaxHosts: 1},
+	}
+	assert.Equal(1, guaranteedHostsNeeded(d, queue, nil))
+}
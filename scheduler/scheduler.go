@@ -75,18 +75,18 @@ func (s *Scheduler) Schedule(ctx context.Context) error {
 		"span":     time.Since(startAt).String(),
 	})
 
-	// split the tasks by distro
-	tasksByDistro, taskRunDistros, err := s.splitTasksByDistro(runnableTasks)
-	if err != nil {
-		return errors.Wrap(err, "Error splitting tasks by distro to run on")
-	}
-
 	// load in all of the distros
 	distros, err := distro.Find(distro.All)
 	if err != nil {
 		return errors.Wrap(err, "Error finding distros")
 	}
 
+	// split the tasks by distro
+	tasksByDistro, taskRunDistros, err := s.splitTasksByDistro(runnableTasks, distros)
+	if err != nil {
+		return errors.Wrap(err, "Error splitting tasks by distro to run on")
+	}
+
 	// get the expected run duration of all runnable tasks
 	taskExpectedDuration, err := s.GetExpectedDurations(runnableTasks)
 
@@ -105,6 +105,7 @@ func (s *Scheduler) Schedule(ctx context.Context) error {
 		}
 		distroInputChan <- distroSchedulerInput{
 			distroId:               d.Id,
+			distro:                 d,
 			runnableTasksForDistro: runnableTasksForDistro,
 		}
 
@@ -132,7 +133,7 @@ func (s *Scheduler) Schedule(ctx context.Context) error {
 			for d := range distroInputChan {
 				distroStartTime := time.Now()
 				// schedule the distro
-				res := s.scheduleDistro(d.distroId, d.runnableTasksForDistro, taskExpectedDuration)
+				res := s.scheduleDistro(d.distro, d.runnableTasksForDistro, taskExpectedDuration)
 				if res.err != nil {
 					grip.Error(message.Fields{
 						"operation": "scheduling distro",
@@ -157,13 +158,16 @@ func (s *Scheduler) Schedule(ctx context.Context) error {
 					"span":     time.Since(distroStartTime).String(),
 					"duration": time.Since(distroStartTime),
 				})
-				if len(d.runnableTasksForDistro) != len(res.taskQueueItem) {
+				if len(d.runnableTasksForDistro) != len(res.taskQueueItem)+len(res.heldTasks) {
 					delta := make(map[string]string)
 					for _, t := range res.taskQueueItem {
 						delta[t.Id] = "res.taskQueueItem"
 					}
+					for _, t := range res.heldTasks {
+						delta[t.Id] = "res.heldTasks"
+					}
 					for _, i := range d.runnableTasksForDistro {
-						if delta[i.Id] == "res.taskQueueItem" {
+						if _, ok := delta[i.Id]; ok {
 							delete(delta, i.Id)
 						} else {
 							delta[i.Id] = "d.runnableTasksForDistro"
@@ -185,6 +189,7 @@ func (s *Scheduler) Schedule(ctx context.Context) error {
 
 	// prioritize the tasks, one distro at a time
 	taskQueueItems := make(map[string][]model.TaskQueueItem)
+	runningTasksByProject := make(map[string]map[string]int)
 
 	resDoneChan := make(chan struct{})
 	catcher := grip.NewSimpleCatcher()
@@ -197,6 +202,9 @@ func (s *Scheduler) Schedule(ctx context.Context) error {
 			}
 			schedulerEvents[res.distroId] = res.schedulerEvent
 			taskQueueItems[res.distroId] = res.taskQueueItem
+			if res.runningTasksByProject != nil {
+				runningTasksByProject[res.distroId] = res.runningTasksByProject
+			}
 		}
 	}()
 
@@ -254,11 +262,12 @@ func (s *Scheduler) Schedule(ctx context.Context) error {
 
 	// construct the data that will be needed by the host allocator
 	hostAllocatorData := HostAllocatorData{
-		existingDistroHosts:  hostsByDistro,
-		distros:              distrosByName,
-		taskQueueItems:       taskQueueItems,
-		taskRunDistros:       taskRunDistros,
-		projectTaskDurations: taskExpectedDuration,
+		existingDistroHosts:   hostsByDistro,
+		distros:               distrosByName,
+		taskQueueItems:        taskQueueItems,
+		taskRunDistros:        taskRunDistros,
+		projectTaskDurations:  taskExpectedDuration,
+		runningTasksByProject: runningTasksByProject,
 	}
 
 	// figure out how many new hosts we need
@@ -327,6 +336,7 @@ func (s *Scheduler) Schedule(ctx context.Context) error {
 
 type distroSchedulerInput struct {
 	distroId               string
+	distro                 distro.Distro
 	runnableTasksForDistro []task.Task
 }
 
@@ -335,11 +345,18 @@ type distroSchedulerResult struct {
 	schedulerEvent event.TaskQueueInfo
 	taskQueueItem  []model.TaskQueueItem
 	err            error
+
+	// tasks held back by the distro's project quotas, and the number
+	// of tasks each project has running on the distro
+	heldTasks             []task.Task
+	runningTasksByProject map[string]int
 }
 
-func (s *Scheduler) scheduleDistro(distroId string, runnableTasksForDistro []task.Task,
+func (s *Scheduler) scheduleDistro(d distro.Distro, runnableTasksForDistro []task.Task,
 	taskExpectedDuration model.ProjectTaskDurations) distroSchedulerResult {
 
+	distroId := d.Id
+
 	res := distroSchedulerResult{
 		distroId: distroId,
 	}
@@ -349,13 +366,36 @@ func (s *Scheduler) scheduleDistro(distroId string, runnableTasksForDistro []tas
 		"num_tasks": len(runnableTasksForDistro),
 	})
 
-	prioritizedTasks, err := s.PrioritizeTasks(distroId, s.Settings,
+	prioritizedTasks, err := s.PrioritizeTasks(&d, s.Settings,
 		runnableTasksForDistro)
 	if err != nil {
 		res.err = errors.Wrap(err, "Error prioritizing tasks")
 		return res
 	}
 
+	if len(d.ProjectQuotas) > 0 {
+		res.runningTasksByProject, err = task.CountRunningTasksByProject(distroId)
+		if err != nil {
+			res.err = errors.Wrap(err, "Error finding running tasks for project quotas")
+			return res
+		}
+		prioritizedTasks, res.heldTasks = applyProjectQuotas(&d, prioritizedTasks, res.runningTasksByProject)
+		heldTaskIds := make([]string, 0, len(res.heldTasks))
+		for _, t := range res.heldTasks {
+			heldTaskIds = append(heldTaskIds, t.Id)
+		}
+		grip.InfoWhen(len(res.heldTasks) > 0, message.Fields{
+			"runner":        RunnerName,
+			"distro":        distroId,
+			"message":       "held back tasks of projects over their quota",
+			"reason":        "project at its burst ceiling",
+			"held_tasks":    len(res.heldTasks),
+			"held_task_ids": heldTaskIds,
+		})
+	}
+
+	prioritizedTasks = orderTaskGroups(prioritizedTasks)
+
 	// persist the queue of tasks
 	grip.Debug(message.Fields{
 		"runner":    RunnerName,
@@ -416,8 +456,14 @@ func (s *Scheduler) updateVersionBuildVarMap(versionStr string,
 		return errors.Wrapf(err, "unable to load project config for version %s", versionStr)
 	}
 
-	// create buildvariant map (for accessing purposes)
+	// create buildvariant map (for accessing purposes), with each
+	// variant's tasks populated from the project's task definitions
 	for _, buildVariant := range project.BuildVariants {
+		for i := range buildVariant.Tasks {
+			if pt := project.FindProjectTask(buildVariant.Tasks[i].Name); pt != nil {
+				buildVariant.Tasks[i].Populate(*pt)
+			}
+		}
 		key := versionBuildVariant{versionStr, buildVariant.Name}
 		versionBuildVarMap[key] = buildVariant
 	}
@@ -428,12 +474,17 @@ func (s *Scheduler) updateVersionBuildVarMap(versionStr string,
 // Takes in a list of tasks, and splits them by distro.
 // Returns a map of distro name -> tasks that can be run on that distro
 // and a map of task id -> distros that the task can be run on (for tasks
-// that can be run on multiple distro)
-func (s *Scheduler) splitTasksByDistro(tasksToSplit []task.Task) (
+// that can be run on multiple distro). Tasks that declare distro
+// requirements are placed on one of the given distros that satisfies them.
+func (s *Scheduler) splitTasksByDistro(tasksToSplit []task.Task, distros []distro.Distro) (
 	map[string][]task.Task, map[string][]string, error) {
 	tasksByDistro := make(map[string][]task.Task)
 	taskRunDistros := make(map[string][]string)
 
+	// only look up hosts to find distros' free capacity if some task
+	// has distro requirements
+	var selector *distroSelector
+
 	// map of versionBuildVariant -> build variant
 	versionBuildVarMap := make(map[versionBuildVariant]model.BuildVariant)
 
@@ -488,10 +539,37 @@ func (s *Scheduler) splitTasksByDistro(tasksToSplit []task.Task) (
 		}
 
 		// use the specified distros for the task, or, if none are specified,
-		// the default distros for the build variant
+		// a distro that satisfies the task's requirements, or the default
+		// distros or requirements for the build variant
 		distrosToUse := buildVariant.RunOn
+		requirements := buildVariant.DistroRequires
 		if len(taskSpec.Distros) != 0 {
 			distrosToUse = taskSpec.Distros
+			requirements = nil
+		} else if taskSpec.DistroRequires != nil {
+			requirements = taskSpec.DistroRequires
+		}
+		if requirements != nil {
+			if selector == nil {
+				var err error
+				selector, err = findDistroSelector(distros)
+				if err != nil {
+					return nil, nil, errors.WithStack(err)
+				}
+			}
+			distroId := selector.selectDistro(requirements)
+			if distroId == "" {
+				grip.Info(message.Fields{
+					"runner":       RunnerName,
+					"variant":      task.BuildVariant,
+					"project":      task.Project,
+					"task":         task.Id,
+					"requirements": requirements,
+					"message":      "no distro satisfies the task's distro requirements",
+				})
+				continue
+			}
+			distrosToUse = []string{distroId}
 		}
 		// remove duplicates to avoid scheduling twice
 		distrosToUse = util.UniqueStrings(distrosToUse)
@@ -48,7 +48,7 @@ func MockFindRunnableTasks() ([]task.Task, error) {
 
 type MockTaskPrioritizer struct{}
 
-func (self *MockTaskPrioritizer) PrioritizeTasks(distroId string, settings *evergreen.Settings,
+func (self *MockTaskPrioritizer) PrioritizeTasks(d *distro.Distro, settings *evergreen.Settings,
 	tasks []task.Task) ([]task.Task, error) {
 	return nil, errors.New("PrioritizeTasks not implemented")
 }
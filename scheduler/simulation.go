@@ -0,0 +1,567 @@
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/distro"
+	"github.com/evergreen-ci/evergreen/model/host"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/pkg/errors"
+)
+
+const (
+	SimulationAllocatorDuration = "duration"
+	SimulationAllocatorDeficit  = "deficit"
+
+	defaultSimulationInterval    = 20 * time.Second
+	defaultSimulationIdleTimeout = 7 * time.Minute
+	defaultSimulationMaxDuration = 7 * 24 * time.Hour
+)
+
+// simulationAllocators holds the host allocators that a simulation can
+// replay, by name.
+var simulationAllocators = map[string]HostAllocator{
+	SimulationAllocatorDuration: &DurationBasedHostAllocator{},
+	SimulationAllocatorDeficit:  &DeficitBasedHostAllocator{},
+}
+
+// SimulationAllocators returns the names of the host allocators that a
+// simulation can replay.
+func SimulationAllocators() []string {
+	names := make([]string, 0, len(simulationAllocators))
+	for name := range simulationAllocators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SimulationSnapshot is the state of the scheduler at a point in time:
+// the distros, their task queues and live hosts, the tasks running on
+// those hosts, and the expected durations of the running and queued
+// tasks.
+type SimulationSnapshot struct {
+	Time          time.Time                  `json:"time"`
+	Distros       []distro.Distro            `json:"distros"`
+	TaskQueues    []model.TaskQueue          `json:"task_queues"`
+	Hosts         []host.Host                `json:"hosts"`
+	RunningTasks  []task.Task                `json:"running_tasks"`
+	TaskDurations model.ProjectTaskDurations `json:"task_durations"`
+}
+
+// TakeSimulationSnapshot captures the current state of the scheduler
+// from the database.
+func TakeSimulationSnapshot() (*SimulationSnapshot, error) {
+	snapshot := &SimulationSnapshot{Time: time.Now()}
+
+	var err error
+	snapshot.Distros, err = distro.Find(distro.All)
+	if err != nil {
+		return nil, errors.Wrap(err, "problem finding distros")
+	}
+
+	snapshot.TaskQueues, err = model.FindAllTaskQueues()
+	if err != nil {
+		return nil, errors.Wrap(err, "problem finding task queues")
+	}
+
+	// the simulation has no use for setup scripts, expansions or host
+	// credentials, so they are left out of the snapshot
+	for i := range snapshot.Distros {
+		snapshot.Distros[i].Setup = ""
+		snapshot.Distros[i].Teardown = ""
+		snapshot.Distros[i].UserData = distro.UserData{}
+		snapshot.Distros[i].Expansions = nil
+	}
+
+	hosts, err := host.Find(host.IsLive)
+	if err != nil {
+		return nil, errors.Wrap(err, "problem finding live hosts")
+	}
+
+	runningTaskIds := []string{}
+	for _, h := range hosts {
+		snapshot.Hosts = append(snapshot.Hosts, host.Host{
+			Id:           h.Id,
+			Distro:       distro.Distro{Id: h.Distro.Id},
+			Provider:     h.Provider,
+			Status:       h.Status,
+			RunningTask:  h.RunningTask,
+			CreationTime: h.CreationTime,
+		})
+		if h.RunningTask != "" {
+			runningTaskIds = append(runningTaskIds, h.RunningTask)
+		}
+	}
+	if len(runningTaskIds) > 0 {
+		snapshot.RunningTasks, err = task.Find(task.ByIds(runningTaskIds))
+		if err != nil {
+			return nil, errors.Wrap(err, "problem finding running tasks")
+		}
+	}
+
+	// the expected durations are looked up by project and variant, so
+	// the queued tasks only need to carry those and their names
+	tasks := append([]task.Task{}, snapshot.RunningTasks...)
+	for _, queue := range snapshot.TaskQueues {
+		for _, item := range queue.Queue {
+			tasks = append(tasks, task.Task{
+				Id:           item.Id,
+				DisplayName:  item.DisplayName,
+				BuildVariant: item.BuildVariant,
+				Project:      item.Project,
+			})
+		}
+	}
+	snapshot.TaskDurations, err = (&DBTaskDurationEstimator{}).GetExpectedDurations(tasks)
+	if err != nil {
+		return nil, errors.Wrap(err, "problem finding expected task durations")
+	}
+
+	return snapshot, nil
+}
+
+// SimulationOptions control how a snapshot is replayed.
+type SimulationOptions struct {
+	// Allocator is the name of the host allocator to replay.
+	Allocator string
+
+	// Interval is how often the scheduler runs; tasks are dispatched
+	// and hosts are allocated and terminated at this resolution.
+	Interval time.Duration
+
+	// HostStartupTime is how long a new host takes to be able to run
+	// tasks, if any, and IdleTimeout how long a dynamic host may sit idle
+	// before it is terminated.
+	HostStartupTime time.Duration
+	IdleTimeout     time.Duration
+
+	// MaxDuration bounds the simulated time, for configurations that
+	// can never drain their queues.
+	MaxDuration time.Duration
+
+	// Distros replace the snapshot's distros with the same id, and
+	// PoolSizes override the pool sizes of the resulting distros, to
+	// try out a proposed configuration.
+	Distros   []distro.Distro
+	PoolSizes map[string]int
+}
+
+func (opts *SimulationOptions) setDefaults() {
+	if opts.Allocator == "" {
+		opts.Allocator = SimulationAllocatorDuration
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = defaultSimulationInterval
+	}
+	if opts.HostStartupTime < 0 {
+		opts.HostStartupTime = 0
+	}
+	if opts.IdleTimeout <= 0 {
+		opts.IdleTimeout = defaultSimulationIdleTimeout
+	}
+	if opts.MaxDuration <= 0 {
+		opts.MaxDuration = defaultSimulationMaxDuration
+	}
+}
+
+// SimulationReport summarizes a replay of a snapshot.
+type SimulationReport struct {
+	Allocator string                   `json:"allocator"`
+	Duration  time.Duration            `json:"duration"`
+	Total     DistroSimulationReport   `json:"total"`
+	Distros   []DistroSimulationReport `json:"distros"`
+}
+
+// DistroSimulationReport summarizes how the queued tasks of a distro,
+// or of all distros, fared in a simulation. Wait times only cover the
+// tasks that were queued in the snapshot, and host hours only count
+// dynamic hosts.
+type DistroSimulationReport struct {
+	Distro      string        `json:"distro,omitempty"`
+	TasksRun    int           `json:"tasks_run"`
+	TasksNotRun int           `json:"tasks_not_run"`
+	MeanWait    time.Duration `json:"mean_wait"`
+	MedianWait  time.Duration `json:"median_wait"`
+	P90Wait     time.Duration `json:"p90_wait"`
+	MaxWait     time.Duration `json:"max_wait"`
+	HostHours   float64       `json:"host_hours"`
+	PeakHosts   int           `json:"peak_hosts"`
+	Makespan    time.Duration `json:"makespan"`
+}
+
+type simulatedHost struct {
+	id        string
+	distro    string
+	static    bool
+	created   time.Time
+	readyAt   time.Time
+	idleSince time.Time
+	task      string
+	taskEnds  time.Time
+}
+
+type simulatedDistro struct {
+	distro    distro.Distro
+	queue     []model.TaskQueueItem
+	hosts     []*simulatedHost
+	waits     []time.Duration
+	lastEnd   time.Time
+	hostHours float64
+	peakHosts int
+}
+
+// simulation replays the scheduler and a host allocator against a
+// snapshot, on a simulated clock.
+type simulation struct {
+	opts      SimulationOptions
+	allocator HostAllocator
+	start     time.Time
+	now       time.Time
+	distroIds []string
+	distros   map[string]*simulatedDistro
+	durations model.ProjectTaskDurations
+	running   map[string]task.Task
+	// task id => the distros whose queues hold the task
+	taskRunDistros map[string][]string
+	dispatched     map[string]bool
+	numHosts       int
+}
+
+// Simulate replays the snapshot with the given options, and reports the
+// queue wait times, host usage and makespan of each distro. Dynamic
+// distros are simulated with the mock cloud provider, so no cloud
+// provider is contacted.
+func Simulate(snapshot *SimulationSnapshot, opts SimulationOptions) (*SimulationReport, error) {
+	opts.setDefaults()
+	allocator, ok := simulationAllocators[opts.Allocator]
+	if !ok {
+		return nil, errors.Errorf("'%s' is not a known host allocator", opts.Allocator)
+	}
+
+	sim := &simulation{
+		opts:           opts,
+		allocator:      allocator,
+		start:          snapshot.Time,
+		now:            snapshot.Time,
+		distros:        map[string]*simulatedDistro{},
+		durations:      snapshot.TaskDurations,
+		running:        map[string]task.Task{},
+		taskRunDistros: map[string][]string{},
+		dispatched:     map[string]bool{},
+	}
+	if err := sim.load(snapshot); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	for !sim.done() && sim.now.Sub(sim.start) < opts.MaxDuration {
+		sim.finishTasks()
+		sim.dispatchTasks()
+		sim.terminateIdleHosts()
+		if err := sim.allocateHosts(); err != nil {
+			return nil, errors.Wrapf(err, "problem allocating hosts at %s",
+				sim.now.Sub(sim.start))
+		}
+		sim.now = sim.now.Add(opts.Interval)
+	}
+
+	return sim.report(), nil
+}
+
+func (sim *simulation) load(snapshot *SimulationSnapshot) error {
+	proposed := map[string]distro.Distro{}
+	for _, d := range sim.opts.Distros {
+		proposed[d.Id] = d
+	}
+
+	for _, d := range snapshot.Distros {
+		if p, ok := proposed[d.Id]; ok {
+			d = p
+		}
+		if size, ok := sim.opts.PoolSizes[d.Id]; ok {
+			d.PoolSize = size
+		}
+		if d.Provider != evergreen.ProviderNameStatic {
+			d.Provider = evergreen.ProviderNameMock
+		}
+		sim.distros[d.Id] = &simulatedDistro{distro: d}
+		sim.distroIds = append(sim.distroIds, d.Id)
+	}
+	sort.Strings(sim.distroIds)
+
+	for _, queue := range snapshot.TaskQueues {
+		d, ok := sim.distros[queue.Distro]
+		if !ok {
+			return errors.Errorf("snapshot has a task queue for unknown distro '%s'", queue.Distro)
+		}
+		d.queue = append(d.queue, queue.Queue...)
+		for _, item := range queue.Queue {
+			sim.taskRunDistros[item.Id] = append(sim.taskRunDistros[item.Id], queue.Distro)
+		}
+	}
+
+	runningTasks := map[string]task.Task{}
+	for _, t := range snapshot.RunningTasks {
+		runningTasks[t.Id] = t
+	}
+
+	for _, h := range snapshot.Hosts {
+		d, ok := sim.distros[h.Distro.Id]
+		if !ok {
+			// hosts of distros that have since been removed are
+			// never allocated or given tasks
+			continue
+		}
+
+		simHost := &simulatedHost{
+			id:        h.Id,
+			distro:    d.distro.Id,
+			static:    d.distro.Provider == evergreen.ProviderNameStatic,
+			created:   sim.start,
+			readyAt:   sim.start,
+			idleSince: sim.start,
+		}
+		if h.Status != evergreen.HostRunning {
+			simHost.readyAt = h.CreationTime.Add(sim.opts.HostStartupTime)
+			if simHost.readyAt.Before(sim.start) {
+				simHost.readyAt = sim.start
+			}
+		}
+
+		if h.RunningTask != "" {
+			t, ok := runningTasks[h.RunningTask]
+			if !ok {
+				t = task.Task{Id: h.RunningTask, StartTime: sim.start}
+			}
+			simHost.task = t.Id
+			simHost.taskEnds = t.StartTime.Add(model.GetTaskExpectedDuration(t, sim.durations))
+			if simHost.taskEnds.Before(sim.start) {
+				simHost.taskEnds = sim.start
+			}
+			sim.running[t.Id] = t
+		}
+
+		d.hosts = append(d.hosts, simHost)
+	}
+
+	return nil
+}
+
+// done returns true once every queued task has been dispatched and
+// every running task has finished.
+func (sim *simulation) done() bool {
+	for _, d := range sim.distros {
+		if len(d.queue) > 0 {
+			return false
+		}
+	}
+	return len(sim.running) == 0
+}
+
+func (sim *simulation) finishTasks() {
+	for _, id := range sim.distroIds {
+		d := sim.distros[id]
+		for _, h := range d.hosts {
+			if h.task == "" || h.taskEnds.After(sim.now) {
+				continue
+			}
+			delete(sim.running, h.task)
+			if h.taskEnds.After(d.lastEnd) {
+				d.lastEnd = h.taskEnds
+			}
+			h.task = ""
+			h.idleSince = h.taskEnds
+		}
+	}
+}
+
+func (sim *simulation) dispatchTasks() {
+	for _, id := range sim.distroIds {
+		d := sim.distros[id]
+		for _, h := range d.hosts {
+			if h.task != "" || h.readyAt.After(sim.now) {
+				continue
+			}
+
+			item, ok := sim.nextTask(d)
+			if !ok {
+				break
+			}
+
+			t := task.Task{
+				Id:           item.Id,
+				DisplayName:  item.DisplayName,
+				BuildVariant: item.BuildVariant,
+				Project:      item.Project,
+				StartTime:    sim.now,
+			}
+			sim.running[t.Id] = t
+			sim.dispatched[t.Id] = true
+			h.task = t.Id
+			h.taskEnds = sim.now.Add(item.ExpectedDuration)
+			d.waits = append(d.waits, sim.now.Sub(sim.start))
+		}
+	}
+}
+
+// nextTask pops the first task in the distro's queue that has not been
+// dispatched from the queue of another distro.
+func (sim *simulation) nextTask(d *simulatedDistro) (model.TaskQueueItem, bool) {
+	for len(d.queue) > 0 {
+		item := d.queue[0]
+		d.queue = d.queue[1:]
+		if !sim.dispatched[item.Id] {
+			return item, true
+		}
+	}
+	return model.TaskQueueItem{}, false
+}
+
+func (sim *simulation) terminateIdleHosts() {
+	for _, id := range sim.distroIds {
+		d := sim.distros[id]
+		hosts := d.hosts[:0]
+		for _, h := range d.hosts {
+			if !h.static && h.task == "" && !h.readyAt.After(sim.now) &&
+				sim.now.Sub(h.idleSince) >= sim.opts.IdleTimeout {
+				d.hostHours += sim.now.Sub(h.created).Hours()
+				continue
+			}
+			hosts = append(hosts, h)
+		}
+		d.hosts = hosts
+	}
+}
+
+func (sim *simulation) allocateHosts() error {
+	data := HostAllocatorData{
+		taskQueueItems:       map[string][]model.TaskQueueItem{},
+		existingDistroHosts:  map[string][]host.Host{},
+		taskRunDistros:       map[string][]string{},
+		distros:              map[string]distro.Distro{},
+		projectTaskDurations: sim.durations,
+		runningTasks:         sim.running,
+		now:                  sim.now,
+	}
+
+	for _, id := range sim.distroIds {
+		d := sim.distros[id]
+		queue := []model.TaskQueueItem{}
+		for _, item := range d.queue {
+			if !sim.dispatched[item.Id] {
+				queue = append(queue, item)
+				data.taskRunDistros[item.Id] = sim.taskRunDistros[item.Id]
+			}
+		}
+		d.queue = queue
+
+		hosts := make([]host.Host, 0, len(d.hosts))
+		for _, h := range d.hosts {
+			hosts = append(hosts, host.Host{
+				Id:          h.id,
+				Distro:      d.distro,
+				Provider:    d.distro.Provider,
+				RunningTask: h.task,
+			})
+		}
+
+		data.taskQueueItems[id] = queue
+		data.existingDistroHosts[id] = hosts
+		data.distros[id] = d.distro
+	}
+
+	newHosts, err := sim.allocator.NewHostsNeeded(data, &evergreen.Settings{})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for _, id := range sim.distroIds {
+		d := sim.distros[id]
+		for i := 0; i < newHosts[id]; i++ {
+			sim.numHosts++
+			readyAt := sim.now.Add(sim.opts.HostStartupTime)
+			d.hosts = append(d.hosts, &simulatedHost{
+				id:        fmt.Sprintf("sim-%s-%d", id, sim.numHosts),
+				distro:    id,
+				created:   sim.now,
+				readyAt:   readyAt,
+				idleSince: readyAt,
+			})
+		}
+		if len(d.hosts) > d.peakHosts {
+			d.peakHosts = len(d.hosts)
+		}
+	}
+
+	return nil
+}
+
+func (sim *simulation) report() *SimulationReport {
+	report := &SimulationReport{
+		Allocator: sim.opts.Allocator,
+		Duration:  sim.now.Sub(sim.start),
+	}
+
+	allWaits := []time.Duration{}
+	for _, id := range sim.distroIds {
+		d := sim.distros[id]
+		for _, h := range d.hosts {
+			if !h.static {
+				d.hostHours += sim.now.Sub(h.created).Hours()
+			}
+		}
+
+		r := DistroSimulationReport{
+			Distro:      id,
+			TasksRun:    len(d.waits),
+			TasksNotRun: len(d.queue),
+			HostHours:   d.hostHours,
+			PeakHosts:   d.peakHosts,
+		}
+		if !d.lastEnd.IsZero() {
+			r.Makespan = d.lastEnd.Sub(sim.start)
+		}
+		setWaitStats(&r, d.waits)
+		report.Distros = append(report.Distros, r)
+
+		allWaits = append(allWaits, d.waits...)
+		report.Total.TasksNotRun += r.TasksNotRun
+		report.Total.HostHours += r.HostHours
+		report.Total.PeakHosts += r.PeakHosts
+		if r.Makespan > report.Total.Makespan {
+			report.Total.Makespan = r.Makespan
+		}
+	}
+	report.Total.TasksRun = len(allWaits)
+	setWaitStats(&report.Total, allWaits)
+
+	return report
+}
+
+type durationSlice []time.Duration
+
+func (s durationSlice) Len() int           { return len(s) }
+func (s durationSlice) Less(i, j int) bool { return s[i] < s[j] }
+func (s durationSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+func setWaitStats(r *DistroSimulationReport, waits []time.Duration) {
+	if len(waits) == 0 {
+		return
+	}
+
+	sorted := append(durationSlice{}, waits...)
+	sort.Sort(sorted)
+
+	var total time.Duration
+	for _, w := range sorted {
+		total += w
+	}
+	r.MeanWait = total / time.Duration(len(sorted))
+	r.MedianWait = sorted[len(sorted)/2]
+	r.P90Wait = sorted[(len(sorted)*9)/10]
+	r.MaxWait = sorted[len(sorted)-1]
+}
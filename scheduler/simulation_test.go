@@ -0,0 +1,124 @@
+package scheduler
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/distro"
+	"github.com/evergreen-ci/evergreen/model/host"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/stretchr/testify/assert"
+)
+
+func simulationSnapshot(numTasks int, taskDuration time.Duration) *SimulationSnapshot {
+	snapshot := &SimulationSnapshot{
+		Time: time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Distros: []distro.Distro{
+			{Id: "d", Provider: evergreen.ProviderNameEc2OnDemand, PoolSize: 2},
+		},
+		TaskQueues: []model.TaskQueue{{Distro: "d"}},
+	}
+	for i := 0; i < numTasks; i++ {
+		snapshot.TaskQueues[0].Queue = append(snapshot.TaskQueues[0].Queue, model.TaskQueueItem{
+			Id:               fmt.Sprintf("t%d", i),
+			Project:          "p",
+			ExpectedDuration: taskDuration,
+		})
+	}
+	return snapshot
+}
+
+func TestSimulateDeficitAllocator(t *testing.T) {
+	assert := assert.New(t)
+
+	report, err := Simulate(simulationSnapshot(4, 10*time.Minute), SimulationOptions{
+		Allocator: SimulationAllocatorDeficit,
+		Interval:  time.Minute,
+	})
+	assert.NoError(err)
+	assert.Len(report.Distros, 1)
+
+	// two hosts are started on the first pass and pick up tasks on the
+	// second, then run the remaining tasks once the first ones finish
+	r := report.Distros[0]
+	assert.Equal("d", r.Distro)
+	assert.Equal(4, r.TasksRun)
+	assert.Equal(0, r.TasksNotRun)
+	assert.Equal(2, r.PeakHosts)
+	assert.Equal(11*time.Minute, r.MedianWait)
+	assert.Equal(11*time.Minute, r.MaxWait)
+	assert.Equal(6*time.Minute, r.MeanWait)
+	assert.Equal(21*time.Minute, r.Makespan)
+	assert.True(r.HostHours > 0.7)
+	assert.Equal(r.TasksRun, report.Total.TasksRun)
+	assert.Equal(r.Makespan, report.Total.Makespan)
+}
+
+func TestSimulateProposedConfiguration(t *testing.T) {
+	assert := assert.New(t)
+
+	report, err := Simulate(simulationSnapshot(4, 10*time.Minute), SimulationOptions{
+		Allocator: SimulationAllocatorDeficit,
+		Interval:  time.Minute,
+		PoolSizes: map[string]int{"d": 1},
+	})
+	assert.NoError(err)
+	assert.Equal(1, report.Distros[0].PeakHosts)
+	assert.Equal(41*time.Minute, report.Distros[0].Makespan)
+
+	// a pool without hosts never runs its tasks
+	report, err = Simulate(simulationSnapshot(4, 10*time.Minute), SimulationOptions{
+		Allocator:   SimulationAllocatorDeficit,
+		MaxDuration: time.Hour,
+		Distros:     []distro.Distro{{Id: "d", Provider: evergreen.ProviderNameEc2OnDemand}},
+	})
+	assert.NoError(err)
+	assert.Equal(0, report.Distros[0].TasksRun)
+	assert.Equal(4, report.Distros[0].TasksNotRun)
+	assert.Equal(time.Hour, report.Duration)
+}
+
+func TestSimulateDurationAllocator(t *testing.T) {
+	assert := assert.New(t)
+
+	// a host that is already running a task, which the duration based
+	// allocator estimates from the snapshot rather than the database
+	snapshot := simulationSnapshot(6, 30*time.Minute)
+	snapshot.Hosts = []host.Host{
+		{Id: "h", Distro: distro.Distro{Id: "d"}, Status: evergreen.HostRunning, RunningTask: "running"},
+	}
+	snapshot.RunningTasks = []task.Task{
+		{Id: "running", Project: "p", StartTime: snapshot.Time.Add(-5 * time.Minute)},
+	}
+
+	report, err := Simulate(snapshot, SimulationOptions{Allocator: SimulationAllocatorDuration})
+	assert.NoError(err)
+	r := report.Distros[0]
+	assert.Equal(6, r.TasksRun)
+	assert.Equal(0, r.TasksNotRun)
+	assert.True(r.PeakHosts > 1)
+	assert.True(r.PeakHosts <= 2)
+}
+
+func TestSimulateSharedTasksRunOnce(t *testing.T) {
+	assert := assert.New(t)
+
+	snapshot := simulationSnapshot(2, 10*time.Minute)
+	snapshot.Distros = append(snapshot.Distros,
+		distro.Distro{Id: "other", Provider: evergreen.ProviderNameEc2OnDemand, PoolSize: 2})
+	snapshot.TaskQueues = append(snapshot.TaskQueues, model.TaskQueue{
+		Distro: "other",
+		Queue:  snapshot.TaskQueues[0].Queue,
+	})
+
+	report, err := Simulate(snapshot, SimulationOptions{Allocator: SimulationAllocatorDeficit})
+	assert.NoError(err)
+	assert.Equal(2, report.Total.TasksRun)
+	assert.Equal(0, report.Total.TasksNotRun)
+
+	_, err = Simulate(snapshot, SimulationOptions{Allocator: "magic"})
+	assert.Error(err)
+}
@@ -0,0 +1,94 @@
+package scheduler
+
+import (
+	"sort"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/task"
+)
+
+// taskGroupKey identifies the tasks of one run of a task group.
+type taskGroupKey struct {
+	group        string
+	buildVariant string
+	project      string
+	version      string
+}
+
+func newTaskGroupKey(t task.Task) taskGroupKey {
+	return taskGroupKey{
+		group:        t.TaskGroup,
+		buildVariant: t.BuildVariant,
+		project:      t.Project,
+		version:      t.Version,
+	}
+}
+
+// numHostsForQueue returns the number of hosts that the tasks in a queue
+// can keep busy at once. Every task outside of a task group can use a
+// host of its own, while each task group can use at most its max hosts.
+func numHostsForQueue(queue []model.TaskQueueItem) int {
+	groups := map[taskGroupKey]int{}
+	numHosts := 0
+	for _, item := range queue {
+		if item.Group == "" {
+			numHosts++
+			continue
+		}
+		maxHosts := item.GroupMaxHosts
+		if maxHosts < 1 {
+			maxHosts = 1
+		}
+		key := taskGroupKey{
+			group:        item.Group,
+			buildVariant: item.BuildVariant,
+			project:      item.Project,
+			version:      item.Version,
+		}
+		if groups[key] < maxHosts {
+			groups[key]++
+			numHosts++
+		}
+	}
+	return numHosts
+}
+
+// orderTaskGroups keeps the tasks of each task group together in a
+// distro's prioritized task queue, so that hosts can run them back to
+// back. Each group's tasks take the place of its highest priority task,
+// in the order they're listed in the group.
+func orderTaskGroups(tasks []task.Task) []task.Task {
+	groups := map[taskGroupKey][]task.Task{}
+	for _, t := range tasks {
+		if t.TaskGroup != "" {
+			key := newTaskGroupKey(t)
+			groups[key] = append(groups[key], t)
+		}
+	}
+	if len(groups) == 0 {
+		return tasks
+	}
+
+	ordered := make([]task.Task, 0, len(tasks))
+	for _, t := range tasks {
+		if t.TaskGroup == "" {
+			ordered = append(ordered, t)
+			continue
+		}
+		key := newTaskGroupKey(t)
+		group, ok := groups[key]
+		if !ok {
+			continue
+		}
+		sort.Stable(byTaskGroupOrder(group))
+		ordered = append(ordered, group...)
+		delete(groups, key)
+	}
+	return ordered
+}
+
+type byTaskGroupOrder []task.Task
+
+func (ts byTaskGroupOrder) Len() int           { return len(ts) }
+func (ts byTaskGroupOrder) Less(i, j int) bool { return ts[i].TaskGroupOrder < ts[j].TaskGroupOrder }
+func (ts byTaskGroupOrder) Swap(i, j int)      { ts[i], ts[j] = ts[j], ts[i] }
@@ -0,0 +1,54 @@
+package scheduler
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderTaskGroups(t *testing.T) {
+	assert := assert.New(t)
+
+	tasks := []task.Task{
+		{Id: "compile"},
+		{Id: "test_2", TaskGroup: "tests", TaskGroupOrder: 2, BuildVariant: "linux", Version: "v1"},
+		{Id: "lint"},
+		{Id: "other_test_1", TaskGroup: "tests", TaskGroupOrder: 1, BuildVariant: "linux", Version: "v2"},
+		{Id: "test_1", TaskGroup: "tests", TaskGroupOrder: 1, BuildVariant: "linux", Version: "v1"},
+		{Id: "test_3", TaskGroup: "tests", TaskGroupOrder: 3, BuildVariant: "linux", Version: "v1"},
+	}
+
+	ids := []string{}
+	for _, t := range orderTaskGroups(tasks) {
+		ids = append(ids, t.Id)
+	}
+	assert.Equal([]string{"compile", "test_1", "test_2", "test_3", "lint", "other_test_1"}, ids)
+
+	// queues without task groups are left alone
+	tasks = []task.Task{{Id: "b"}, {Id: "a"}}
+	assert.Equal(tasks, orderTaskGroups(tasks))
+}
+
+func TestNumHostsForQueue(t *testing.T) {
+	assert := assert.New(t)
+
+	queue := []model.TaskQueueItem{{Id: "compile"}, {Id: "lint"}}
+	for i := 0; i < 40; i++ {
+		queue = append(queue, model.TaskQueueItem{
+			Id: fmt.Sprintf("test_%d", i), Group: "tests", BuildVariant: "linux", Version: "v1", GroupMaxHosts: 1})
+	}
+	assert.Equal(3, numHostsForQueue(queue))
+
+	// each run of a group has its own hosts
+	queue = append(queue,
+		model.TaskQueueItem{Id: "other_1", Group: "tests", BuildVariant: "linux", Version: "v2", GroupMaxHosts: 2},
+		model.TaskQueueItem{Id: "other_2", Group: "tests", BuildVariant: "linux", Version: "v2", GroupMaxHosts: 2},
+		model.TaskQueueItem{Id: "other_3", Group: "tests", BuildVariant: "linux", Version: "v2", GroupMaxHosts: 2},
+	)
+	assert.Equal(5, numHostsForQueue(queue))
+
+	assert.Equal(0, numHostsForQueue(nil))
+}
@@ -5,6 +5,7 @@ import (
 	"sort"
 
 	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/model/distro"
 	"github.com/evergreen-ci/evergreen/model/task"
 	"github.com/mongodb/grip"
 	"github.com/mongodb/grip/message"
@@ -17,7 +18,7 @@ type TaskPrioritizer interface {
 	// Takes in a slice of tasks and the current MCI settings.
 	// Returns the slice of tasks, sorted in the order in which they should
 	// be run, as well as an error if appropriate.
-	PrioritizeTasks(distroId string, settings *evergreen.Settings, tasks []task.Task) (
+	PrioritizeTasks(d *distro.Distro, settings *evergreen.Settings, tasks []task.Task) (
 		[]task.Task, error)
 }
 
@@ -29,12 +30,22 @@ type CmpBasedTaskComparator struct {
 	setupFuncs     []sortSetupFunc
 	comparators    []taskPriorityCmp
 
+	// if set, the comparators are weighed against each other rather
+	// than consulted in order; see taskMoreImportantThan
+	weights []int
+
+	// if set, patch tasks are queued ahead of commit tasks
+	patchFirst bool
+
 	// caches for sorting
 	previousTasksCache map[string]task.Task
 
 	// cache the number of tasks that have failed in other buildvariants; tasks
 	// with the same revision, project, display name and requester
 	similarFailingCount map[string]int
+
+	// cache the rank of each task among the tasks of its project
+	fairShareRanks map[string]int
 }
 
 // CmpBasedTaskQueues represents the three types of queues that are created for merging together into one queue.
@@ -67,15 +78,20 @@ func NewCmpBasedTaskComparator() *CmpBasedTaskComparator {
 
 type CmpBasedTaskPrioritizer struct{}
 
-// PrioritizeTask prioritizes the tasks to run. First splits the tasks into slices based on
+// PrioritizeTask prioritizes the tasks to run, using the distro's
+// prioritization strategy. First splits the tasks into slices based on
 // whether they are part of patch versions or automatically created versions.
 // Then prioritizes each slice, and merges them.
 // Returns a full slice of the prioritized tasks, and an error if one occurs.
 func (prioritizer *CmpBasedTaskPrioritizer) PrioritizeTasks(
-	distroId string,
+	d *distro.Distro,
 	settings *evergreen.Settings, tasks []task.Task) ([]task.Task, error) {
 
-	comparator := NewCmpBasedTaskComparator()
+	distroId := d.Id
+	comparator, err := newCmpBasedTaskComparatorForDistro(d)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 	// split the tasks into repotracker tasks and patch tasks, then prioritize
 	// individually and merge
 	taskQueues := comparator.splitTasksByRequester(tasks)
@@ -85,6 +101,7 @@ func (prioritizer *CmpBasedTaskPrioritizer) PrioritizeTasks(
 		"distro":    distroId,
 		"runner":    RunnerName,
 		"operation": "prioritize tasks",
+		"strategy":  d.Prioritization.GetStrategy(),
 	})
 	for _, taskList := range [][]task.Task{taskQueues.RepotrackerTasks, taskQueues.PatchTasks, taskQueues.HighPriorityTasks} {
 
@@ -96,7 +113,7 @@ func (prioritizer *CmpBasedTaskPrioritizer) PrioritizeTasks(
 			"runner":    RunnerName,
 			"operation": "prioritize tasks",
 		})
-		err := comparator.setupForSortingTasks(distroId)
+		err = comparator.setupForSortingTasks(distroId)
 		if err != nil {
 			return nil, errors.Wrap(err, "Error running setup for sorting tasks")
 		}
@@ -165,10 +182,15 @@ func (self *CmpBasedTaskComparator) setupForSortingTasks(distroId string) error
 
 // Determine which of two tasks is more important, by running the tasks through
 // the comparator functions and returning the first definitive decision on which
-// is more important.
+// is more important. If the comparator has weights, the decisions of all of the
+// comparator functions are instead weighed against each other.
 func (self *CmpBasedTaskComparator) taskMoreImportantThan(task1,
 	task2 task.Task) (bool, error) {
 
+	if len(self.weights) > 0 {
+		return self.taskMoreImportantThanWeighted(task1, task2)
+	}
+
 	// run through the comparators, and return the first definitive decision on
 	// which task is more important
 	for _, cmp := range self.comparators {
@@ -193,6 +215,32 @@ func (self *CmpBasedTaskComparator) taskMoreImportantThan(task1,
 	return false, nil
 }
 
+// taskMoreImportantThanWeighted sums the decisions of the comparator
+// functions, each multiplied by its weight, and considers the first
+// task more important if the sum is positive.
+func (self *CmpBasedTaskComparator) taskMoreImportantThanWeighted(task1,
+	task2 task.Task) (bool, error) {
+
+	if len(self.weights) != len(self.comparators) {
+		return false, errors.Errorf("comparator has %d weights for %d comparators",
+			len(self.weights), len(self.comparators))
+	}
+
+	score := 0
+	for idx, cmp := range self.comparators {
+		ret, err := cmp(task1, task2, self)
+		if err != nil {
+			return false, errors.WithStack(err)
+		}
+		if ret < -1 || ret > 1 {
+			panic("Unexpected return value from task comparator")
+		}
+		score += ret * self.weights[idx]
+	}
+
+	return score > 0, nil
+}
+
 // Functions that ensure the CmdBasedTaskPrioritizer implements sort.Interface
 
 func (self *CmpBasedTaskComparator) Len() int {
@@ -213,8 +261,8 @@ func (self *CmpBasedTaskComparator) Swap(i, j int) {
 }
 
 // Split the tasks, based on the requester field.
-// Returns two slices - the tasks requested by the repotracker, and the tasks
-// requested in a patch.
+// Returns two slices - the tasks requested by the repotracker, including those
+// of periodic builds, and the tasks requested in a patch.
 func (self *CmpBasedTaskComparator) splitTasksByRequester(
 	allTasks []task.Task) *CmpBasedTaskQueues {
 
@@ -226,7 +274,8 @@ func (self *CmpBasedTaskComparator) splitTasksByRequester(
 		switch {
 		case task.Priority > evergreen.MaxTaskPriority:
 			priorityTasks = append(priorityTasks, task)
-		case task.Requester == evergreen.RepotrackerVersionRequester:
+		case task.Requester == evergreen.RepotrackerVersionRequester,
+			task.Requester == evergreen.AdHocVersionRequester:
 			repoTrackerTasks = append(repoTrackerTasks, task)
 		case evergreen.IsPatchRequester(task.Requester):
 			patchTasks = append(patchTasks, task)
@@ -268,6 +317,12 @@ func (self *CmpBasedTaskComparator) mergeTasks(settings *evergreen.Settings,
 
 	// add the high priority tasks to the start of the queue
 	mergedTasks = append(mergedTasks, tq.HighPriorityTasks...)
+
+	if self.patchFirst {
+		mergedTasks = append(mergedTasks, tq.PatchTasks...)
+		return append(mergedTasks, tq.RepotrackerTasks...)
+	}
+
 	for idx := 0; idx < len(tq.RepotrackerTasks)+len(tq.PatchTasks); idx++ {
 		if pIdx >= lenPatchTasks { // overruns patch tasks
 			mergedTasks = append(mergedTasks, tq.RepotrackerTasks[rIdx])
@@ -102,6 +102,60 @@ func byRuntime(t1, t2 task.Task, comp *CmpBasedTaskComparator) (int, error) {
 	return -1, nil
 }
 
+// byCreateTime orders tasks strictly by when they were created,
+// preferring the older task, regardless of project or requester.
+func byCreateTime(t1, t2 task.Task, _ *CmpBasedTaskComparator) (int, error) {
+	if t1.CreateTime.Before(t2.CreateTime) {
+		return 1, nil
+	}
+	if t2.CreateTime.Before(t1.CreateTime) {
+		return -1, nil
+	}
+
+	return 0, nil
+}
+
+// byShortestRuntime prefers the task that is expected to finish
+// sooner, which gets the most tasks through the queue in the least
+// time. Tasks without an expected duration are not compared.
+func byShortestRuntime(t1, t2 task.Task, _ *CmpBasedTaskComparator) (int, error) {
+	if t1.ExpectedDuration == 0 || t2.ExpectedDuration == 0 {
+		return 0, nil
+	}
+
+	if t1.ExpectedDuration < t2.ExpectedDuration {
+		return 1, nil
+	}
+	if t1.ExpectedDuration > t2.ExpectedDuration {
+		return -1, nil
+	}
+
+	return 0, nil
+}
+
+// byFairShare prefers the task that ranks higher among the tasks of
+// its own project, so that projects take turns at the front of the
+// queue.
+func byFairShare(t1, t2 task.Task, comparator *CmpBasedTaskComparator) (int, error) {
+	rankOne, ok := comparator.fairShareRanks[t1.Id]
+	if !ok {
+		return 0, errors.Errorf("No fair share rank available for task with id %v", t1.Id)
+	}
+	rankTwo, ok := comparator.fairShareRanks[t2.Id]
+	if !ok {
+		return 0, errors.Errorf("No fair share rank available for task with id %v", t2.Id)
+	}
+
+	if rankOne < rankTwo {
+		return 1, nil
+	}
+	if rankOne > rankTwo {
+		return -1, nil
+	}
+
+	return 0, nil
+}
+
 // byRecentlyFailing compares the results of the previous executions of each
 // Task, and considers one more important if its previous execution resulted in
 // failure.
@@ -36,6 +36,9 @@ func (self *DBTaskQueuePersister) PersistTaskQueue(distro string,
 			Project:             t.Project,
 			ExpectedDuration:    expectedTaskDuration,
 			Priority:            t.Priority,
+			Version:             t.Version,
+			Group:               t.TaskGroup,
+			GroupMaxHosts:       t.TaskGroupMaxHosts,
 		})
 
 		if err := t.SetExpectedDuration(expectedTaskDuration); err != nil {
@@ -103,6 +103,7 @@ db.json.ensureIndex({ "version_id" : 1 })
 
 //======testresults======//
 db.testresults.ensureIndex({ "task_id" : 1, "task_execution" : 1 })
+db.testresults.ensureIndex({ "project" : 1, "build_variant" : 1, "task_name" : 1, "test_file" : 1, "order" : -1, "task_execution" : -1 })
 
 //======project_aliases======//
 db.project_aliases.ensureIndex({ "project_id" : 1, "alias" : 1 })
@@ -1,7 +1,9 @@
 package service
 
 import (
+	"bytes"
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net"
@@ -15,8 +17,10 @@ import (
 	"github.com/evergreen-ci/evergreen/cloud"
 	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/artifact"
+	"github.com/evergreen-ci/evergreen/model/coverage"
 	"github.com/evergreen-ci/evergreen/model/event"
 	"github.com/evergreen-ci/evergreen/model/host"
+	"github.com/evergreen-ci/evergreen/model/role"
 	"github.com/evergreen-ci/evergreen/model/task"
 	"github.com/evergreen-ci/evergreen/model/version"
 	"github.com/evergreen-ci/evergreen/notify"
@@ -209,6 +213,68 @@ func (as *APIServer) checkProject(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// requirePermission takes in a request handler and returns a wrapped version which verifies that
+// the requester has the permission, on the request's project for project permissions. The project
+// is taken from the route, or else from the project field of the form or JSON body.
+func (as *APIServer) requirePermission(p role.Permission, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dbUser := GetUser(r)
+		if dbUser == nil {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		projectId, err := requestProjectId(r)
+		if err != nil {
+			as.LoggedError(w, r, http.StatusBadRequest, err)
+			return
+		}
+		var projectRef *model.ProjectRef
+		if projectId != "" {
+			projectRef, err = model.FindOneProjectRef(projectId)
+			if err != nil {
+				as.LoggedError(w, r, http.StatusInternalServerError, err)
+				return
+			}
+		}
+		if !hasPermission(&as.Settings, GetRoleStore(r), dbUser, p, projectRef) {
+			http.Error(w, fmt.Sprintf("Forbidden: requires permission '%s'", p), http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// requestProjectId returns the project the request is for. A JSON body is read
+// to find it and then restored, so the handler can still read it.
+func requestProjectId(r *http.Request) (string, error) {
+	if projectId := mux.Vars(r)["projectId"]; projectId != "" {
+		return projectId, nil
+	}
+	if r.Header.Get("Content-Type") == formMimeType {
+		return r.FormValue("project"), nil
+	}
+	if r.Body == nil {
+		return "", nil
+	}
+
+	body, err := ioutil.ReadAll(util.NewRequestReader(r))
+	if err != nil {
+		return "", errors.Wrap(err, "problem reading request body")
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if len(body) == 0 {
+		return "", nil
+	}
+	data := struct {
+		Project string `json:"project"`
+	}{}
+	if err = json.Unmarshal(body, &data); err != nil {
+		return "", errors.Wrap(err, "problem parsing request body")
+	}
+	return data.Project, nil
+}
+
 func (as *APIServer) checkHost(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		hostId := mux.Vars(r)["hostId"]
@@ -338,6 +404,136 @@ func (as *APIServer) AttachResults(w http.ResponseWriter, r *http.Request) {
 	as.WriteJSON(w, http.StatusOK, "test results successfully attached")
 }
 
+// AttachCoverage is the API Server hook for storing the line coverage
+// reported by a task in the coverage collection.
+func (as *APIServer) AttachCoverage(w http.ResponseWriter, r *http.Request) {
+	t := MustHaveTask(r)
+	files := []coverage.FileCoverage{}
+	err := util.ReadJSONInto(util.NewRequestReader(r), &files)
+	if err != nil {
+		as.LoggedError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	// enforce proper task metadata
+	for i := range files {
+		files[i].TaskID = t.Id
+		files[i].Execution = t.Execution
+		files[i].Version = t.Version
+		files[i].Project = t.Project
+		files[i].BuildVariant = t.BuildVariant
+		files[i].TaskName = t.DisplayName
+	}
+
+	if err := coverage.InsertMany(files); err != nil {
+		as.LoggedError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	as.WriteJSON(w, http.StatusOK, "coverage successfully attached")
+}
+
+// generateTasksAttempts is how many times the tasks a task generated are
+// merged into its version before giving up because other tasks in the
+// version keep changing its config.
+const generateTasksAttempts = 5
+
+// GenerateTasks is the API Server hook for adding the functions, tasks and
+// build variants a task generated to the task's version. A task only
+// generates tasks once, so restarting it doesn't try to add them again.
+func (as *APIServer) GenerateTasks(w http.ResponseWriter, r *http.Request) {
+	t := MustHaveTask(r)
+	files := []string{}
+	err := util.ReadJSONInto(util.NewRequestReader(r), &files)
+	if err != nil {
+		as.LoggedError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if t.GeneratedTasks {
+		as.WriteJSON(w, http.StatusOK, "tasks were already generated")
+		return
+	}
+
+	generated, err := model.ParseGeneratedProject(files)
+	if err != nil {
+		as.LoggedError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	for i := 0; i < generateTasksAttempts; i++ {
+		v, err := version.FindOne(version.ById(t.Version))
+		if err != nil {
+			as.LoggedError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		if v == nil {
+			as.LoggedError(w, r, http.StatusNotFound, errors.Errorf("version '%s' not found", t.Version))
+			return
+		}
+
+		// the config already has the tasks from an earlier attempt that
+		// failed before creating all of them
+		if previous := v.FindGeneratedTasks(t.Id); previous != nil {
+			if err = model.FinishGeneratedTasks(v, previous); err != nil {
+				as.LoggedError(w, r, http.StatusInternalServerError, err)
+				return
+			}
+			if err = t.SetGeneratedTasks(); err != nil {
+				as.LoggedError(w, r, http.StatusInternalServerError, err)
+				return
+			}
+			as.WriteJSON(w, http.StatusOK, "tasks successfully generated")
+			return
+		}
+
+		project, err := generated.NewProject(v)
+		if err != nil {
+			as.LoggedError(w, r, http.StatusBadRequest, err)
+			return
+		}
+		verrs, err := validator.CheckProjectSyntax(project)
+		if err != nil {
+			as.LoggedError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		catcher := grip.NewBasicCatcher()
+		for _, verr := range verrs {
+			if verr.Level == validator.Error {
+				catcher.Add(errors.New(verr.Message))
+			}
+		}
+		if catcher.HasErrors() {
+			as.LoggedError(w, r, http.StatusBadRequest,
+				errors.Wrap(catcher.Resolve(), "generated project is invalid"))
+			return
+		}
+
+		added, err := model.AddGeneratedTasks(v, project, t.Id)
+		if err != nil {
+			as.LoggedError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		if !added {
+			continue
+		}
+
+		if err = t.SetGeneratedTasks(); err != nil {
+			as.LoggedError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		grip.Info(message.Fields{
+			"message":   "generated tasks",
+			"task":      t.Id,
+			"version":   v.Id,
+			"generated": generated.String(),
+		})
+		as.WriteJSON(w, http.StatusOK, "tasks successfully generated")
+		return
+	}
+
+	as.LoggedError(w, r, http.StatusServiceUnavailable,
+		errors.Errorf("config for version '%s' changed too often to add generated tasks", t.Version))
+}
+
 // FetchProjectVars is an API hook for returning the project variables
 // associated with a task's project.
 func (as *APIServer) FetchProjectVars(w http.ResponseWriter, r *http.Request) {
@@ -347,12 +543,17 @@ func (as *APIServer) FetchProjectVars(w http.ResponseWriter, r *http.Request) {
 		as.LoggedError(w, r, http.StatusInternalServerError, err)
 		return
 	}
-	if projectVars == nil {
-		as.WriteJSON(w, http.StatusOK, apimodels.ExpansionVars{})
-		return
+
+	// the blob storage settings are defaults for the project's commands,
+	// which project variables of the same name override
+	vars := apimodels.ExpansionVars(as.Settings.BlobStorage.Expansions())
+	if projectVars != nil {
+		for k, v := range projectVars.Vars {
+			vars[k] = v
+		}
 	}
 
-	as.WriteJSON(w, http.StatusOK, projectVars.Vars)
+	as.WriteJSON(w, http.StatusOK, vars)
 }
 
 // AttachFiles updates file mappings for a task or build
@@ -698,7 +899,7 @@ func (as *APIServer) AttachRoutes(root *mux.Router) {
 	AttachRESTHandler(root, as)
 	// attaches /rest/v2 routes
 	APIV2Prefix := evergreen.APIRoutePrefix + "/" + evergreen.RestRoutePrefix
-	route.AttachHandler(root, as.queue, as.Settings.ApiUrl, APIV2Prefix, as.Settings.SuperUsers, []byte(as.Settings.Api.GithubWebhookSecret))
+	route.AttachHandler(root, as.queue, as.Settings.ApiUrl, APIV2Prefix, as.Settings.SuperUsers, as.Settings.RBAC, []byte(as.Settings.Api.GithubWebhookSecret))
 
 	r := root.PathPrefix("/api/2/").Subrouter()
 	r.HandleFunc("/", home)
@@ -724,7 +925,7 @@ func (as *APIServer) AttachRoutes(root *mux.Router) {
 
 	// Patches
 	patchPath := apiRootOld.PathPrefix("/patches").Subrouter()
-	patchPath.HandleFunc("/", requireUser(as.submitPatch, nil)).Methods("PUT")
+	patchPath.HandleFunc("/", requireUser(as.requirePermission(role.PatchSubmit, as.submitPatch), nil)).Methods("PUT")
 	patchPath.HandleFunc("/mine", requireUser(as.listPatches, nil)).Methods("GET")
 	patchPath.HandleFunc("/{patchId:\\w+}", requireUser(as.summarizePatch, nil)).Methods("GET")
 	patchPath.HandleFunc("/{patchId:\\w+}", requireUser(as.existingPatchRequest, nil)).Methods("POST")
@@ -763,6 +964,13 @@ func (as *APIServer) AttachRoutes(root *mux.Router) {
 	agentRouter := r.PathPrefix("/agent").Subrouter()
 	agentRouter.HandleFunc("/next_task", as.checkHost(as.NextTask)).Methods("GET")
 
+	// Routes for hosts that bootstrap themselves from their user data
+	r.HandleFunc("/hosts/bootstrap/register", as.registerHost).Methods("POST")
+	bootstrapRouter := r.PathPrefix("/hosts/{hostId}/bootstrap").Subrouter()
+	bootstrapRouter.HandleFunc("/logs", as.requireHostSecret(as.appendHostSetupLog)).Methods("POST")
+	bootstrapRouter.HandleFunc("/setup", as.requireHostSecret(as.endHostSetup)).Methods("POST")
+	bootstrapRouter.HandleFunc("/agent", as.requireHostSecret(as.startHostAgent)).Methods("POST")
+
 	taskRouter := r.PathPrefix("/task/{taskId}").Subrouter()
 
 	taskRouter.HandleFunc("/end", as.checkTask(true, as.checkHost(as.EndTask))).Methods("POST")
@@ -774,6 +982,8 @@ func (as *APIServer) AttachRoutes(root *mux.Router) {
 	taskRouter.HandleFunc("/heartbeat", as.checkTask(true, as.checkHost(as.Heartbeat))).Methods("POST")
 	taskRouter.HandleFunc("/results", as.checkTask(true, as.checkHost(as.AttachResults))).Methods("POST")
 	taskRouter.HandleFunc("/test_logs", as.checkTask(true, as.checkHost(as.AttachTestLog))).Methods("POST")
+	taskRouter.HandleFunc("/coverage", as.checkTask(true, as.checkHost(as.AttachCoverage))).Methods("POST")
+	taskRouter.HandleFunc("/generate", as.checkTask(true, as.checkHost(as.GenerateTasks))).Methods("POST")
 	taskRouter.HandleFunc("/files", as.checkTask(false, as.checkHost(as.AttachFiles))).Methods("POST")
 	taskRouter.HandleFunc("/system_info", as.checkTask(true, as.checkHost(as.TaskSystemInfo))).Methods("POST")
 	taskRouter.HandleFunc("/process_info", as.checkTask(true, as.checkHost(as.TaskProcessInfo))).Methods("POST")
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/alerts"
+	"github.com/evergreen-ci/evergreen/apimodels"
+	"github.com/evergreen-ci/evergreen/cloud"
+	"github.com/evergreen-ci/evergreen/hostutil"
+	"github.com/evergreen-ci/evergreen/model/event"
+	"github.com/evergreen-ci/evergreen/model/host"
+	"github.com/evergreen-ci/evergreen/notify"
+	"github.com/evergreen-ci/evergreen/taskrunner"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mongodb/grip"
+	"github.com/mongodb/grip/message"
+	"github.com/pkg/errors"
+	mgo "gopkg.in/mgo.v2"
+)
+
+// requireHostSecret ensures that a host bootstrapping itself sends its secret,
+// which checkHost only verifies when it is present.
+func (as *APIServer) requireHostSecret(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(evergreen.HostSecretHeader) == "" {
+			as.LoggedError(w, r, http.StatusUnauthorized, errors.New("missing host secret"))
+			return
+		}
+		as.checkHost(next)(w, r)
+	}
+}
+
+// registerHost exchanges the one-time token that a host was given in its user
+// data for its ID and secret, and sends back what the host needs to run its
+// setup script and start the agent. Everything that can fail is done before
+// the token is used up, so that the host can retry. The host may start before
+// it has been recorded with its token, so an unknown token is reported as not
+// found, which the host retries, rather than as a conflict.
+func (as *APIServer) registerHost(w http.ResponseWriter, r *http.Request) {
+	req := &apimodels.HostRegistrationRequest{}
+	if err := util.ReadJSONInto(util.NewRequestReader(r), req); err != nil {
+		as.LoggedError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if req.Token == "" {
+		as.LoggedError(w, r, http.StatusBadRequest, errors.New("missing bootstrap token"))
+		return
+	}
+
+	h, err := host.FindOne(host.ByBootstrapToken(req.Token))
+	if err != nil {
+		as.LoggedError(w, r, http.StatusInternalServerError, errors.Wrap(err, "error finding host"))
+		return
+	}
+	if h == nil {
+		as.LoggedError(w, r, http.StatusNotFound, errors.New("unknown bootstrap token"))
+		return
+	}
+
+	cloudManager, err := cloud.GetCloudManager(h.Provider, &as.Settings)
+	if err != nil {
+		as.LoggedError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	if err = cloudManager.OnUp(h); err != nil {
+		as.LoggedError(w, r, http.StatusInternalServerError,
+			errors.Wrapf(err, "error calling OnUp for host %s", h.Id))
+		return
+	}
+
+	// set the host's dns name, if it is not set
+	if h.Host == "" {
+		var dns string
+		dns, err = cloudManager.GetDNSName(h)
+		if err != nil {
+			as.LoggedError(w, r, http.StatusInternalServerError,
+				errors.Wrapf(err, "error checking DNS name for host %s", h.Id))
+			return
+		}
+		if err = h.SetDNSName(dns); err != nil {
+			as.LoggedError(w, r, http.StatusInternalServerError,
+				errors.Wrapf(err, "error setting DNS name for host %s", h.Id))
+			return
+		}
+	}
+
+	setup, err := util.NewExpansions(as.Settings.Expansions).ExpandString(h.Distro.Setup)
+	if err != nil {
+		as.LoggedError(w, r, http.StatusInternalServerError,
+			errors.Wrapf(err, "error expanding setup script for host %s", h.Id))
+		return
+	}
+
+	if err = h.Register(req.Token); err != nil {
+		if err == mgo.ErrNotFound {
+			as.LoggedError(w, r, http.StatusConflict,
+				errors.Errorf("host %s has already registered", h.Id))
+			return
+		}
+		as.LoggedError(w, r, http.StatusInternalServerError,
+			errors.Wrapf(err, "error registering host %s", h.Id))
+		return
+	}
+
+	grip.Info(message.Fields{
+		"message": "host registered",
+		"host":    h.Id,
+		"distro":  h.Distro.Id,
+	})
+
+	as.WriteJSON(w, http.StatusOK, apimodels.HostRegistrationResponse{
+		HostId:           h.Id,
+		Secret:           h.Secret,
+		Setup:            setup,
+		SetupAsSudo:      h.Distro.SetupAsSudo,
+		WorkingDirectory: h.Distro.WorkDir,
+		AgentURL:         hostutil.ClientURL(as.Settings.Ui.Url, &h.Distro),
+		AgentEnvironment: taskrunner.AgentEnvironment(&as.Settings),
+	})
+}
+
+// appendHostSetupLog records output from a host's setup script as it runs.
+func (as *APIServer) appendHostSetupLog(w http.ResponseWriter, r *http.Request) {
+	h := GetHost(r)
+
+	setupLog := &apimodels.HostSetupLog{}
+	if err := util.ReadJSONInto(util.NewRequestReader(r), setupLog); err != nil {
+		as.LoggedError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	event.LogHostSetupProgress(h.Id, setupLog.Logs)
+	as.WriteJSON(w, http.StatusOK, "setup logs appended")
+}
+
+// endHostSetup marks a host as provisioned if its setup script succeeded.
+func (as *APIServer) endHostSetup(w http.ResponseWriter, r *http.Request) {
+	h := GetHost(r)
+
+	result := &apimodels.HostSetupResult{}
+	if err := util.ReadJSONInto(util.NewRequestReader(r), result); err != nil {
+		as.LoggedError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if h.Status != evergreen.HostInitializing {
+		as.LoggedError(w, r, http.StatusConflict,
+			errors.Errorf("host %s is %s, not %s", h.Id, h.Status, evergreen.HostInitializing))
+		return
+	}
+
+	if !result.Success {
+		grip.Warning(message.WrapError(alerts.RunHostProvisionFailTriggers(h), message.Fields{
+			"operation": "running host provisioning alert trigger",
+			"host":      h.Id,
+		}))
+		event.LogProvisionFailed(h.Id, result.Logs)
+
+		if err := h.SetUnprovisioned(); err != nil {
+			as.LoggedError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		subject := fmt.Sprintf("%v Evergreen provisioning failure on %v",
+			notify.ProvisionFailurePreface, h.Distro.Id)
+		hostLink := fmt.Sprintf("%v/host/%v", as.Settings.Ui.Url, h.Id)
+		body := fmt.Sprintf("Provisioning failed on %v host -- %v: see %v",
+			h.Distro.Id, h.Id, hostLink)
+		if err := notify.NotifyAdmins(subject, body, &as.Settings); err != nil {
+			grip.Errorln("Error sending email:", err)
+		}
+
+		as.WriteJSON(w, http.StatusOK, fmt.Sprintf("Initializing host %v failed", h.Id))
+		return
+	}
+
+	if err := h.MarkAsProvisioned(); err != nil {
+		as.LoggedError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	grip.Info(message.Fields{
+		"message": "host provisioned from user data",
+		"host":    h.Id,
+		"distro":  h.Distro.Id,
+	})
+	as.WriteJSON(w, http.StatusOK, fmt.Sprintf("Host %v provisioned", h.Id))
+}
+
+// startHostAgent records that a host is starting the current agent. Hosts
+// that aren't running anymore are told to stop with a conflict.
+func (as *APIServer) startHostAgent(w http.ResponseWriter, r *http.Request) {
+	h := GetHost(r)
+
+	if h.Status != evergreen.HostRunning {
+		as.LoggedError(w, r, http.StatusConflict,
+			errors.Errorf("host %s is %s, and should not start an agent", h.Id, h.Status))
+		return
+	}
+
+	agentRevision, err := taskrunner.NewTaskRunner(&as.Settings).HostGateway.GetAgentRevision()
+	if err != nil {
+		as.LoggedError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err = h.SetAgentRevision(agentRevision); err != nil {
+		as.LoggedError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	if err = h.SetNeedsNewAgent(false); err != nil {
+		as.LoggedError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	event.LogHostAgentDeployed(h.Id)
+	as.WriteJSON(w, http.StatusOK, fmt.Sprintf("Agent starting on host %v", h.Id))
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/apimodels"
 	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/version"
@@ -21,6 +22,39 @@ const (
 	s3CopyRetryNumRetries   = 5
 )
 
+// s3CopyBucketOptions returns the options of the buckets that the API server
+// copies a task's file within. The server only sends signed requests to the
+// admin's blob storage, so requests for another provider or endpoint are
+// rejected rather than followed.
+func s3CopyBucketOptions(blob evergreen.BlobStorageConfig, req *apimodels.S3CopyRequest) (thirdparty.BucketOptions, error) {
+	provider := blob.Provider
+	if provider == "" {
+		provider = evergreen.BlobProviderS3
+	}
+
+	// files of the local provider are on the agent's host, so the agent
+	// copies them itself
+	if provider == evergreen.BlobProviderLocal || req.Provider == evergreen.BlobProviderLocal {
+		return thirdparty.BucketOptions{}, errors.New("the API server cannot copy files of the local provider")
+	}
+	if req.Provider != "" && req.Provider != provider {
+		return thirdparty.BucketOptions{}, errors.Errorf("the API server can only copy files of the '%s' provider", provider)
+	}
+	if req.Endpoint != "" && req.Endpoint != blob.Endpoint {
+		return thirdparty.BucketOptions{}, errors.Errorf("the API server cannot copy files at endpoint '%s'", req.Endpoint)
+	}
+
+	return thirdparty.BucketOptions{
+		Provider:  provider,
+		Endpoint:  blob.Endpoint,
+		PathStyle: blob.PathStyle,
+		Auth: &aws.Auth{
+			AccessKey: req.AwsKey,
+			SecretKey: req.AwsSecret,
+		},
+	}, nil
+}
+
 // Takes a request for a task's file to be copied from
 // one s3 location to another. Ensures that if the destination
 // file path already exists, no file copy is performed.
@@ -34,6 +68,24 @@ func (as *APIServer) s3copyPlugin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	bucketOpts, err := s3CopyBucketOptions(as.Settings.BlobStorage, s3CopyReq)
+	if err != nil {
+		as.LoggedError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	bucketOpts.Name = s3CopyReq.S3SourceBucket
+	source, err := thirdparty.NewBucket(bucketOpts)
+	if err != nil {
+		as.LoggedError(w, r, http.StatusBadRequest, errors.Wrap(err, "invalid source bucket"))
+		return
+	}
+	bucketOpts.Name = s3CopyReq.S3DestinationBucket
+	destination, err := thirdparty.NewBucket(bucketOpts)
+	if err != nil {
+		as.LoggedError(w, r, http.StatusBadRequest, errors.Wrap(err, "invalid destination bucket"))
+		return
+	}
+
 	// Get the version for this task, so we can check if it has
 	// any already-done pushes
 	v, err := version.FindOne(version.ById(task.Version))
@@ -76,20 +128,13 @@ func (as *APIServer) s3copyPlugin(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Now copy the file into the permanent location
-	auth := &aws.Auth{
-		AccessKey: s3CopyReq.AwsKey,
-		SecretKey: s3CopyReq.AwsSecret,
-	}
-
 	grip.Infof("performing S3 copy: '%s' => '%s'", copyFromLocation, copyToLocation)
 
 	_, err = util.Retry(func() (bool, error) {
-		err = errors.WithStack(thirdparty.S3CopyFile(auth,
-			s3CopyReq.S3SourceBucket,
-			s3CopyReq.S3SourcePath,
-			s3CopyReq.S3DestinationBucket,
-			s3CopyReq.S3DestinationPath,
-			string(s3.PublicRead),
+		err = errors.WithStack(thirdparty.CopyBlob(
+			source, s3CopyReq.S3SourcePath,
+			destination, s3CopyReq.S3DestinationPath,
+			thirdparty.PutOptions{Permissions: string(s3.PublicRead)},
 		))
 		if err != nil {
 			grip.Errorf("S3 copy failed for task %s, retrying: %+v", task.Id, err)
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/apimodels"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestS3CopyBucketOptions(t *testing.T) {
+	Convey("When choosing the buckets to copy a task's file within", t, func() {
+		req := &apimodels.S3CopyRequest{AwsKey: "key", AwsSecret: "secret"}
+		blob := evergreen.BlobStorageConfig{
+			Provider:  evergreen.BlobProviderS3Compatible,
+			Endpoint:  "https://minio.example.com",
+			PathStyle: true,
+		}
+
+		Convey("S3 should be used by default", func() {
+			opts, err := s3CopyBucketOptions(evergreen.BlobStorageConfig{}, req)
+			So(err, ShouldBeNil)
+			So(opts.Provider, ShouldEqual, evergreen.BlobProviderS3)
+			So(opts.Auth.AccessKey, ShouldEqual, "key")
+		})
+		Convey("the admin's blob storage should be used", func() {
+			req.Provider = evergreen.BlobProviderS3Compatible
+			opts, err := s3CopyBucketOptions(blob, req)
+			So(err, ShouldBeNil)
+			So(opts.Endpoint, ShouldEqual, blob.Endpoint)
+			So(opts.PathStyle, ShouldBeTrue)
+
+			req.Endpoint = blob.Endpoint
+			opts, err = s3CopyBucketOptions(blob, req)
+			So(err, ShouldBeNil)
+			So(opts.Endpoint, ShouldEqual, blob.Endpoint)
+		})
+		Convey("other endpoints and providers should be rejected", func() {
+			req.Endpoint = "http://169.254.169.254"
+			_, err := s3CopyBucketOptions(blob, req)
+			So(err, ShouldNotBeNil)
+
+			req.Endpoint = ""
+			req.Provider = evergreen.BlobProviderS3
+			_, err = s3CopyBucketOptions(blob, req)
+			So(err, ShouldNotBeNil)
+
+			req.Provider = evergreen.BlobProviderLocal
+			_, err = s3CopyBucketOptions(evergreen.BlobStorageConfig{Provider: evergreen.BlobProviderLocal}, req)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -193,27 +193,14 @@ func (as *APIServer) EndTask(w http.ResponseWriter, r *http.Request) {
 	// task cost calculations have no impact on task results, so do them in their own goroutine
 	go as.updateTaskCost(t, currentHost, finishTime)
 
-	if !evergreen.IsPatchRequester(t.Requester) {
-		if t.IsPartOfDisplay() {
-			parent := t.DisplayTask
-			if task.IsFinished(*parent) {
-				grip.Error(errors.Wrapf(alerts.RunTaskFailureTriggers(parent.Id),
-					"processing alert triggers for display task %s", parent.Id))
-			}
-		} else {
-			grip.Infoln("Processing alert triggers for task", t.Id)
-
-			grip.Error(errors.Wrapf(alerts.RunTaskFailureTriggers(t.Id),
-				"processing alert triggers for task %s", t.Id))
-		}
+	// a task that is being retried has not finished, so it neither
+	// alerts nor counts towards the expected duration
+	if updates.TaskRetried {
+		grip.Infof("Task %s failed and is being retried", t.Id)
+	} else {
+		as.processFinishedTask(t)
 	}
-	// TODO(EVG-223) process patch-specific triggers
 
-	// update the bookkeeping entry for the task
-	err = bookkeeping.UpdateExpectedDuration(t, t.TimeTaken)
-	if err != nil {
-		grip.Errorln("Error updating expected duration:", err)
-	}
 	taskRunnerInstance := taskrunner.NewTaskRunner(&as.Settings)
 	agentRevision, err := taskRunnerInstance.HostGateway.GetAgentRevision()
 	if err != nil {
@@ -259,6 +246,31 @@ func (as *APIServer) EndTask(w http.ResponseWriter, r *http.Request) {
 
 }
 
+// processFinishedTask runs the alert triggers of a task that finished and
+// updates the expected duration of the task.
+func (as *APIServer) processFinishedTask(t *task.Task) {
+	if !evergreen.IsPatchRequester(t.Requester) {
+		if t.IsPartOfDisplay() {
+			parent := t.DisplayTask
+			if task.IsFinished(*parent) {
+				grip.Error(errors.Wrapf(alerts.RunTaskFailureTriggers(parent.Id),
+					"processing alert triggers for display task %s", parent.Id))
+			}
+		} else {
+			grip.Infoln("Processing alert triggers for task", t.Id)
+
+			grip.Error(errors.Wrapf(alerts.RunTaskFailureTriggers(t.Id),
+				"processing alert triggers for task %s", t.Id))
+		}
+	}
+	// TODO(EVG-223) process patch-specific triggers
+
+	// update the bookkeeping entry for the task
+	if err := bookkeeping.UpdateExpectedDuration(t, t.TimeTaken); err != nil {
+		grip.Errorln("Error updating expected duration:", err)
+	}
+}
+
 // updateTaskCost determines a task's cost based on the host it ran on. Hosts that
 // are unable to calculate their own costs will not set a task's Cost field. Errors
 // are logged but not returned, since any number of API failures could happen and
@@ -290,9 +302,24 @@ func assignNextAvailableTask(taskQueue *model.TaskQueue, currentHost *host.Host)
 		return nil, errors.Errorf("Error host %v must have an unset running task field but has running task %v",
 			currentHost.Id, currentHost.RunningTask)
 	}
+	// give up the task groups the host claimed but isn't running
+	defer func() {
+		grip.Warning(message.WrapError(currentHost.ReleaseTaskGroups(), message.Fields{
+			"message": "problem releasing task groups",
+			"host":    currentHost.Id,
+		}))
+	}()
+
 	// only proceed if there are pending tasks left
 	for !taskQueue.IsEmpty() {
-		nextTaskId := taskQueue.NextTask().Id
+		queueItem, err := nextTaskQueueItem(taskQueue, currentHost)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if queueItem == nil {
+			return nil, nil
+		}
+		nextTaskId := queueItem.Id
 
 		nextTask, err := task.FindOne(task.ById(nextTaskId))
 		if err != nil {
@@ -352,11 +379,73 @@ func assignNextAvailableTask(taskQueue *model.TaskQueue, currentHost *host.Host)
 		if !ok {
 			continue
 		}
+		if err = currentHost.SetLastTaskGroup(nextTask); err != nil {
+			return nil, errors.WithStack(err)
+		}
 		return nextTask, nil
 	}
 	return nil, nil
 }
 
+// nextTaskQueueItem returns the next task in the queue that the host can
+// run, or nil if there isn't one. A host that last ran a task in a task
+// group runs the rest of the group's tasks first. Otherwise tasks in task
+// groups are skipped unless the host can claim the group, which it can't
+// while as many hosts as the group allows are running the group's tasks.
+func nextTaskQueueItem(taskQueue *model.TaskQueue, currentHost *host.Host) (*model.TaskQueueItem, error) {
+	type taskGroupKey struct{ group, buildVariant, project, version string }
+	full := map[taskGroupKey]bool{}
+	claim := func(item model.TaskQueueItem) (bool, error) {
+		key := taskGroupKey{item.Group, item.BuildVariant, item.Project, item.Version}
+		if full[key] {
+			return false, nil
+		}
+		claimed, err := currentHost.ClaimTaskGroup(&task.Task{
+			TaskGroup:    item.Group,
+			BuildVariant: item.BuildVariant,
+			Project:      item.Project,
+			Version:      item.Version,
+		}, item.GroupMaxHosts)
+		if err != nil {
+			return false, errors.Wrapf(err, "problem claiming task group '%s'", item.Group)
+		}
+		full[key] = !claimed
+		return claimed, nil
+	}
+
+	if currentHost.LastGroup != "" {
+		for i, item := range taskQueue.Queue {
+			if item.Group == currentHost.LastGroup &&
+				item.BuildVariant == currentHost.LastBuildVariant &&
+				item.Project == currentHost.LastProject &&
+				item.Version == currentHost.LastVersion {
+				claimed, err := claim(item)
+				if err != nil {
+					return nil, errors.WithStack(err)
+				}
+				if claimed {
+					return &taskQueue.Queue[i], nil
+				}
+				break
+			}
+		}
+	}
+
+	for i, item := range taskQueue.Queue {
+		if item.Group == "" {
+			return &taskQueue.Queue[i], nil
+		}
+		claimed, err := claim(item)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if claimed {
+			return &taskQueue.Queue[i], nil
+		}
+	}
+	return nil, nil
+}
+
 // NextTask retrieves the next task's id given the host name and host secret by retrieving the task queue
 // and popping the next task off the task queue.
 func (as *APIServer) NextTask(w http.ResponseWriter, r *http.Request) {
@@ -426,6 +515,8 @@ func (as *APIServer) NextTask(w http.ResponseWriter, r *http.Request) {
 		if t.Activated {
 			response.TaskId = t.Id
 			response.TaskSecret = t.Secret
+			response.TaskGroup = t.TaskGroup
+			response.Build = t.BuildId
 			as.WriteJSON(w, http.StatusOK, response)
 			return
 		}
@@ -485,6 +576,8 @@ func (as *APIServer) NextTask(w http.ResponseWriter, r *http.Request) {
 	}
 	response.TaskId = nextTask.Id
 	response.TaskSecret = nextTask.Secret
+	response.TaskGroup = nextTask.TaskGroup
+	response.Build = nextTask.BuildId
 	grip.Infof("assigned task %s to host %s", nextTask.Id, h.Id)
 	as.WriteJSON(w, http.StatusOK, response)
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/evergreen-ci/evergreen/auth"
 	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/patch"
+	"github.com/evergreen-ci/evergreen/model/role"
 	"github.com/evergreen-ci/evergreen/model/user"
 	"github.com/evergreen-ci/evergreen/plugin"
 	"github.com/evergreen-ci/evergreen/util"
@@ -50,6 +51,7 @@ type (
 	// custom types used to attach specific values to request contexts, to prevent collisions.
 	reqTaskKey           int
 	reqProjectContextKey int
+	reqRoleStoreKey      int
 )
 
 const (
@@ -57,6 +59,7 @@ const (
 	// These are private custom types to avoid key collisions.
 	RequestTask           reqTaskKey           = 0
 	RequestProjectContext reqProjectContextKey = 0
+	RequestRoleStore      reqRoleStoreKey      = 0
 )
 
 // MustHaveProjectContext gets the projectContext from the request,
@@ -107,13 +110,14 @@ func withPluginUser(next http.Handler) http.HandlerFunc {
 }
 
 // requireAdmin takes in a request handler and returns a wrapped version which verifies that requests are
-// authenticated and that the user is either a super user or is part of the project context's project's admins.
+// authenticated and that the user is either a super user, is part of the project context's project's admins
+// or has a role allowing them to change the project's settings.
 func (uis *UIServer) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// get the project context
 		projCtx := MustHaveProjectContext(r)
 		if dbUser := GetUser(r); dbUser != nil {
-			if uis.isSuperUser(dbUser) || isAdmin(dbUser, projCtx.ProjectRef) {
+			if uis.hasPermission(r, dbUser, role.ProjectSettings, projCtx.ProjectRef) {
 				next(w, r)
 				return
 			}
@@ -123,6 +127,64 @@ func (uis *UIServer) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// requirePermission takes in a request handler and returns a wrapped version which verifies that
+// the requester has the permission, on the project context's project for project permissions.
+// Requests without a user are redirected to the login page, and users without the permission
+// are forbidden.
+func (uis *UIServer) requirePermission(p role.Permission, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dbUser := GetUser(r)
+		if dbUser == nil {
+			uis.RedirectToLogin(w, r)
+			return
+		}
+
+		var projectRef *model.ProjectRef
+		if projCtx, err := GetProjectContext(r); err == nil {
+			projectRef = projCtx.ProjectRef
+		}
+		if !uis.hasPermission(r, dbUser, p, projectRef) {
+			http.Error(w, fmt.Sprintf("Forbidden: requires permission '%s'", p), http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// hasPermission returns true if the user has the permission, checked against the roles
+// loaded for the request. Errors looking up roles deny the permission.
+func (uis *UIServer) hasPermission(r *http.Request, u *user.DBUser, p role.Permission, project *model.ProjectRef) bool {
+	return hasPermission(&uis.Settings, GetRoleStore(r), u, p, project)
+}
+
+// canViewProject returns true if the project is public or the user may view it.
+func canViewProject(settings *evergreen.Settings, store auth.RoleStore, u *user.DBUser, project *model.ProjectRef) bool {
+	return !project.Private || hasPermission(settings, store, u, role.ProjectView, project)
+}
+
+// hasPermission returns true if the user has the permission under the settings, checked
+// against the roles in the store. Errors looking up roles deny the permission.
+func hasPermission(settings *evergreen.Settings, store auth.RoleStore, u *user.DBUser, p role.Permission, project *model.ProjectRef) bool {
+	if u == nil {
+		return false
+	}
+	authorizer := &auth.Authorizer{
+		SuperUsers: settings.SuperUsers,
+		Config:     settings.RBAC,
+		Store:      store,
+	}
+	allowed, err := authorizer.HasPermission(u, p, project)
+	if err != nil {
+		grip.Error(message.WrapError(err, message.Fields{
+			"message":    "problem checking permissions",
+			"user":       u.Id,
+			"permission": p,
+		}))
+		return false
+	}
+	return allowed
+}
+
 // requireUser takes a request handler and returns a wrapped version which verifies that requests
 // request are authenticated before proceeding. For a request which is not authenticated, it will
 // execute the onFail handler. If onFail is nil, a simple "unauthorized" error will be sent.
@@ -172,7 +234,7 @@ func (uis *UIServer) isSuperUser(u *user.DBUser) bool {
 		return false
 	}
 	if util.StringSliceContains(uis.Settings.SuperUsers, u.Id) ||
-		len(uis.Settings.SuperUsers) == 0 {
+		len(uis.Settings.SuperUsers) == 0 || u.IsGrantedSuperUser() {
 		return true
 	}
 
@@ -186,7 +248,7 @@ func isAdmin(u *user.DBUser, project *model.ProjectRef) bool {
 	if u == nil {
 		return false
 	}
-	return util.StringSliceContains(project.Admins, u.Id)
+	return util.StringSliceContains(project.Admins, u.Id) || u.IsGrantedProjectAdmin(project.Identifier)
 }
 
 // RedirectToLogin forces a redirect to the login page. The redirect param is set on the query
@@ -210,7 +272,8 @@ func (uis *UIServer) RedirectToLogin(w http.ResponseWriter, r *http.Request) {
 }
 
 // Loads all Task/Build/Version/Patch/Project metadata and attaches it to the request.
-// If the project is private but the user is not logged in, redirects to the login page.
+// If the project is private but the user is not logged in, redirects to the login page,
+// and users who can't view the project are forbidden.
 func (uis *UIServer) loadCtx(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		projCtx, err := uis.LoadProjectContext(w, r)
@@ -229,27 +292,32 @@ func (uis *UIServer) loadCtx(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
+		if projCtx.ProjectRef != nil && !canViewProject(&uis.Settings, GetRoleStore(r), GetUser(r), projCtx.ProjectRef) {
+			http.Error(w, fmt.Sprintf("Forbidden: requires permission '%s'", role.ProjectView), http.StatusForbidden)
+			return
+		}
+
 		r = setUIRequestContext(r, projCtx)
 
 		next(w, r)
 	}
 }
 
-// populateProjectRefs loads all project refs into the context. If includePrivate is true,
-// all available projects will be included, otherwise only public projects will be loaded.
-// Sets IsAdmin to true if the user id is located in a project's admin list.
-func (pc *projectContext) populateProjectRefs(includePrivate, isSuperUser bool, dbUser *user.DBUser) error {
+// populateProjectRefs loads all project refs into the context. Private projects are only
+// loaded if canView allows them. Sets IsAdmin to true if the user id is located in a
+// project's admin list.
+func (pc *projectContext) populateProjectRefs(canView func(*model.ProjectRef) bool, isSuperUser bool, dbUser *user.DBUser) error {
 	allProjs, err := model.FindAllTrackedProjectRefs()
 	if err != nil {
 		return err
 	}
 	pc.AllProjects = make([]UIProjectFields, 0, len(allProjs))
-	// User is not logged in, so only include public projects.
 	for _, p := range allProjs {
 		if !p.Enabled {
 			continue
 		}
-		if !p.Private || includePrivate {
+		// private projects are only included for users who can view them
+		if !p.Private || canView(&p) {
 			uiProj := UIProjectFields{
 				DisplayName: p.DisplayName,
 				Identifier:  p.Identifier,
@@ -259,13 +327,23 @@ func (pc *projectContext) populateProjectRefs(includePrivate, isSuperUser bool,
 			pc.AllProjects = append(pc.AllProjects, uiProj)
 		}
 
-		if includePrivate && (isSuperUser || isAdmin(dbUser, &p)) {
+		if dbUser != nil && (isSuperUser || isAdmin(dbUser, &p)) {
 			pc.IsAdmin = true
 		}
 	}
 	return nil
 }
 
+// hasProject returns true if the project is one of the context's projects.
+func (pc *projectContext) hasProject(identifier string) bool {
+	for _, p := range pc.AllProjects {
+		if p.Identifier == identifier {
+			return true
+		}
+	}
+	return false
+}
+
 // getRequestProjectId determines the projectId to associate with the request context,
 // in cases where it could not be inferred from a task/build/version/patch etc.
 // The projectId is determined using the following criteria in order of priority:
@@ -305,11 +383,19 @@ func (uis *UIServer) LoadProjectContext(rw http.ResponseWriter, r *http.Request)
 
 	pc := projectContext{AuthRedirect: uis.UserManager.IsRedirect()}
 	isSuperUser := (dbUser != nil) && auth.IsSuperUser(uis.Settings.SuperUsers, dbUser)
-	err := pc.populateProjectRefs(dbUser != nil, isSuperUser, dbUser)
+	store := GetRoleStore(r)
+	canView := func(p *model.ProjectRef) bool { return canViewProject(&uis.Settings, store, dbUser, p) }
+	err := pc.populateProjectRefs(canView, isSuperUser, dbUser)
 	if err != nil {
 		return pc, err
 	}
 
+	// Projects remembered in the cookie or configured as the default may be ones the
+	// user can't view, so only the project named by the request is used as is.
+	if vars["project_id"] == "" && !pc.hasProject(projectId) {
+		projectId = ""
+	}
+
 	// If we still don't have a default projectId, just use the first project in the list
 	// if there is one.
 	if len(projectId) == 0 && len(pc.AllProjects) > 0 {
@@ -345,9 +431,11 @@ func (uis *UIServer) LoadProjectContext(rw http.ResponseWriter, r *http.Request)
 }
 
 // UserMiddleware is middleware which checks for session tokens on the Request
-// and looks up and attaches a user for that token if one is found.
+// and looks up and attaches a user for that token if one is found. It also
+// attaches a role store that loads roles at most once for the request.
 func UserMiddleware(um auth.UserManager) func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
 	return func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		r = setRequestRoleStore(r, auth.NewCachedRoleStore(auth.DBRoleStore{}))
 		token := ""
 		var err error
 		// Grab token auth from cookies
@@ -372,15 +460,16 @@ func UserMiddleware(um auth.UserManager) func(rw http.ResponseWriter, r *http.Re
 		}
 
 		if len(token) > 0 {
-			dbUser, err := um.GetUserByToken(token)
+			tokenUser, err := um.GetUserByToken(token)
 			if err != nil {
 				grip.Infof("Error getting user %s: %+v", authDataName, err)
 			} else {
 				// Get the user's full details from the DB or create them if they don't exists
-				dbUser, err = model.GetOrCreateUser(dbUser.Username(), dbUser.DisplayName(), dbUser.Email())
+				dbUser, err := model.GetOrCreateUser(tokenUser.Username(), tokenUser.DisplayName(), tokenUser.Email())
 				if err != nil {
-					grip.Infof("Error looking up user %s: %+v", dbUser.Username(), err)
+					grip.Infof("Error looking up user %s: %+v", tokenUser.Username(), err)
 				} else {
+					recordGithubLogin(dbUser, tokenUser)
 					r = setRequestUser(r, dbUser)
 				}
 			}
@@ -400,6 +489,23 @@ func UserMiddleware(um auth.UserManager) func(rw http.ResponseWriter, r *http.Re
 	}
 }
 
+// recordGithubLogin records the GitHub login of the user if their identity
+// provider verified it.
+func recordGithubLogin(dbUser *user.DBUser, u auth.User) {
+	githubUser, ok := u.(auth.GithubUser)
+	if !ok {
+		return
+	}
+	login := githubUser.GetGithubLogin()
+	if login == "" || login == dbUser.GithubLogin {
+		return
+	}
+	grip.Warning(message.WrapError(dbUser.SetGithubLogin(login), message.Fields{
+		"message": "problem recording GitHub login",
+		"user":    dbUser.Id,
+	}))
+}
+
 // ForbiddenHandler logs a rejected request befure returning a 403 to the client
 func ForbiddenHandler(w http.ResponseWriter, r *http.Request) {
 	reason := csrf.FailureReason(r)
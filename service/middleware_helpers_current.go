@@ -36,6 +36,9 @@ func setRestContext(r *http.Request, c *model.Context) *http.Request {
 func setRequestUser(r *http.Request, u auth.User) *http.Request {
 	return r.WithContext(context.WithValue(r.Context(), evergreen.RequestUser, u))
 }
+func setRequestRoleStore(r *http.Request, s auth.RoleStore) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), RequestRoleStore, s))
+}
 
 // GetTask loads the task attached to a request.
 func GetTask(r *http.Request) *task.Task {
@@ -80,6 +83,15 @@ func GetUser(r *http.Request) *user.DBUser {
 	return nil
 }
 
+// GetRoleStore returns the role store attached to the request, or one that
+// reads the database directly if there is none.
+func GetRoleStore(r *http.Request) auth.RoleStore {
+	if rv := r.Context().Value(RequestRoleStore); rv != nil {
+		return rv.(auth.RoleStore)
+	}
+	return auth.DBRoleStore{}
+}
+
 // GetProjectContext fetches the projectContext associated with the request. Returns an error
 // if no projectContext has been loaded and attached to the request.
 func GetProjectContext(r *http.Request) (projectContext, error) {
@@ -43,6 +43,10 @@ func setRequestUser(r *http.Request, u auth.User) *http.Request {
 	context.Set(r, evergreen.RequestUser, u)
 	return r
 }
+func setRequestRoleStore(r *http.Request, s auth.RoleStore) *http.Request {
+	context.Set(r, RequestRoleStore, s)
+	return r
+}
 
 // GetTask loads the task attached to a request.
 func GetTask(r *http.Request) *task.Task {
@@ -85,6 +89,15 @@ func GetUser(r *http.Request) *user.DBUser {
 	return nil
 }
 
+// GetRoleStore returns the role store attached to the request, or one that
+// reads the database directly if there is none.
+func GetRoleStore(r *http.Request) auth.RoleStore {
+	if rv := context.Get(r, RequestRoleStore); rv != nil {
+		return rv.(auth.RoleStore)
+	}
+	return auth.DBRoleStore{}
+}
+
 // GetProjectContext fetches the projectContext associated with the request. Returns an error
 // if no projectContext has been loaded and attached to the request.
 func GetProjectContext(r *http.Request) (projectContext, error) {
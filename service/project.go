@@ -12,6 +12,7 @@ import (
 	"github.com/evergreen-ci/evergreen/alerts"
 	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/user"
+	"github.com/evergreen-ci/evergreen/thirdparty"
 	"github.com/evergreen-ci/evergreen/util"
 	"github.com/google/go-github/github"
 	"github.com/gorilla/mux"
@@ -144,12 +145,15 @@ func (uis *UIServer) modifyProject(w http.ResponseWriter, r *http.Request) {
 		Private            bool                    `json:"private"`
 		Owner              string                  `json:"owner_name"`
 		Repo               string                  `json:"repo_name"`
+		RepoKind           string                  `json:"repo_kind"`
+		RepoURL            string                  `json:"repo_url"`
 		Admins             []string                `json:"admins"`
 		AlertConfig        map[string][]struct {
 			Provider string                 `json:"provider"`
 			Settings map[string]interface{} `json:"settings"`
 		} `json:"alert_config"`
-		SetupGithubHook bool `json:"setup_github_hook"`
+		SetupGithubHook bool                    `json:"setup_github_hook"`
+		CommitQueue     model.CommitQueueParams `json:"commit_queue"`
 	}{}
 
 	if err = util.ReadJSONInto(util.NewRequestReader(r), &responseRef); err != nil {
@@ -173,6 +177,26 @@ func (uis *UIServer) modifyProject(w http.ResponseWriter, r *http.Request) {
 			errs = append(errs, fmt.Sprintf("task regex #%d is invalid", i+1))
 		}
 	}
+	if responseRef.RepoKind == "" {
+		responseRef.RepoKind = model.GithubRepoType
+	}
+	if !util.StringSliceContains(model.ValidRepoTypes, responseRef.RepoKind) {
+		errs = append(errs, fmt.Sprintf("invalid repo kind '%s'", responseRef.RepoKind))
+	}
+	if repoURL := strings.TrimSpace(responseRef.RepoURL); repoURL == "" {
+		if responseRef.RepoKind == model.GitRepoType {
+			errs = append(errs, "git projects must have a repo url")
+		}
+	} else if err := thirdparty.ValidateGitRemoteURL(repoURL); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if responseRef.CommitQueue.MergeMethod != "" &&
+		!util.StringSliceContains(model.ValidCommitQueueMergeMethods, responseRef.CommitQueue.MergeMethod) {
+		errs = append(errs, fmt.Sprintf("invalid commit queue merge method '%s'", responseRef.CommitQueue.MergeMethod))
+	}
+	if responseRef.CommitQueue.Enabled && responseRef.RepoKind != model.GithubRepoType {
+		errs = append(errs, "only github projects can have a commit queue")
+	}
 	if len(errs) > 0 {
 		errMsg := ""
 		for _, err := range errs {
@@ -192,7 +216,10 @@ func (uis *UIServer) modifyProject(w http.ResponseWriter, r *http.Request) {
 	projectRef.Owner = responseRef.Owner
 	projectRef.DeactivatePrevious = responseRef.DeactivatePrevious
 	projectRef.Repo = responseRef.Repo
+	projectRef.RepoKind = responseRef.RepoKind
+	projectRef.RepoURL = strings.TrimSpace(responseRef.RepoURL)
 	projectRef.Admins = responseRef.Admins
+	projectRef.CommitQueue = responseRef.CommitQueue
 	projectRef.Identifier = id
 
 	projectRef.Alerts = map[string][]model.AlertConfig{}
@@ -280,7 +307,7 @@ func (uis *UIServer) addProject(w http.ResponseWriter, r *http.Request) {
 		Identifier: id,
 		Enabled:    true,
 		Tracked:    true,
-		RepoKind:   "github",
+		RepoKind:   model.GithubRepoType,
 	}
 
 	err = newProject.Insert()
@@ -21,9 +21,11 @@ func (restapi restAPI) getProject(w http.ResponseWriter, r *http.Request) {
 }
 
 // getProjectsIds returns a JSON response of an array of active project Ids.
-// Users must use credentials to see private projects.
+// Users must use credentials and be allowed to view private projects to see them.
 func (restapi restAPI) getProjectIds(w http.ResponseWriter, r *http.Request) {
 	u := GetUser(r)
+	settings := restapi.GetSettings()
+	store := GetRoleStore(r)
 	refs, err := model.FindAllProjectRefs()
 	if err != nil {
 		restapi.WriteJSON(w, http.StatusNotFound, responseError{
@@ -32,8 +34,8 @@ func (restapi restAPI) getProjectIds(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	projects := []string{}
-	for _, r := range refs {
-		if r.Enabled && (!r.Private || u != nil) {
+	for i, r := range refs {
+		if r.Enabled && canViewProject(&settings, store, u, &refs[i]) {
 			projects = append(projects, r.Identifier)
 		}
 	}
@@ -52,6 +52,12 @@ func (ra *restAPI) loadCtx(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
+		settings := ra.GetSettings()
+		if ctx.ProjectRef != nil && !canViewProject(&settings, GetRoleStore(r), GetUser(r), ctx.ProjectRef) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+
 		r = setRestContext(r, &ctx)
 		next(w, r)
 	}
@@ -13,6 +13,7 @@ import (
 	"github.com/evergreen-ci/evergreen/db"
 	"github.com/evergreen-ci/evergreen/model"
 	"github.com/evergreen-ci/evergreen/model/admin"
+	"github.com/evergreen-ci/evergreen/model/role"
 	"github.com/evergreen-ci/evergreen/model/user"
 	"github.com/evergreen-ci/evergreen/plugin"
 	"github.com/evergreen-ci/evergreen/rest/route"
@@ -151,7 +152,7 @@ func (uis *UIServer) AttachRoutes(r *mux.Router) error {
 	// Task page (and related routes)
 	r.HandleFunc("/task/{task_id}", uis.loadCtx(uis.taskPage)).Methods("GET")
 	r.HandleFunc("/task/{task_id}/{execution}", uis.loadCtx(uis.taskPage)).Methods("GET")
-	r.HandleFunc("/tasks/{task_id}", requireLogin(uis.loadCtx(uis.taskModify))).Methods("PUT")
+	r.HandleFunc("/tasks/{task_id}", requireLogin(uis.loadCtx(uis.requirePermission(role.TaskRestart, uis.taskModify)))).Methods("PUT")
 	r.HandleFunc("/json/task_log/{task_id}", uis.loadCtx(uis.taskLog))
 	r.HandleFunc("/json/task_log/{task_id}/{execution}", uis.loadCtx(uis.taskLog))
 	r.HandleFunc("/task_log_raw/{task_id}/{execution}", uis.loadCtx(uis.taskLogRaw))
@@ -162,28 +163,28 @@ func (uis *UIServer) AttachRoutes(r *mux.Router) error {
 
 	// Build page
 	r.HandleFunc("/build/{build_id}", uis.loadCtx(uis.buildPage)).Methods("GET")
-	r.HandleFunc("/builds/{build_id}", requireLogin(uis.loadCtx(uis.modifyBuild))).Methods("PUT")
+	r.HandleFunc("/builds/{build_id}", requireLogin(uis.loadCtx(uis.requirePermission(role.TaskRestart, uis.modifyBuild)))).Methods("PUT")
 	r.HandleFunc("/json/build_history/{build_id}", uis.loadCtx(uis.buildHistory)).Methods("GET")
 
 	// Version page
 	r.HandleFunc("/version/{version_id}", uis.loadCtx(uis.versionPage)).Methods("GET")
-	r.HandleFunc("/version/{version_id}", requireLogin(uis.loadCtx(uis.modifyVersion))).Methods("PUT")
+	r.HandleFunc("/version/{version_id}", requireLogin(uis.loadCtx(uis.requirePermission(role.TaskRestart, uis.modifyVersion)))).Methods("PUT")
 	r.HandleFunc("/json/version_history/{version_id}", uis.loadCtx(uis.versionHistory))
 	r.HandleFunc("/version/{project_id}/{revision}", uis.loadCtx(uis.versionFind)).Methods("GET")
 
 	// Hosts
 	r.HandleFunc("/hosts", requireLogin(uis.loadCtx(uis.hostsPage))).Methods("GET")
-	r.HandleFunc("/hosts", requireLogin(uis.loadCtx(uis.modifyHosts))).Methods("PUT")
+	r.HandleFunc("/hosts", requireLogin(uis.loadCtx(uis.requirePermission(role.HostAdmin, uis.modifyHosts)))).Methods("PUT")
 	r.HandleFunc("/host/{host_id}", requireLogin(uis.loadCtx(uis.hostPage))).Methods("GET")
-	r.HandleFunc("/host/{host_id}", requireLogin(uis.loadCtx(uis.modifyHost))).Methods("PUT")
+	r.HandleFunc("/host/{host_id}", requireLogin(uis.loadCtx(uis.requirePermission(role.HostAdmin, uis.modifyHost)))).Methods("PUT")
 
 	// Distros
 	r.HandleFunc("/distros", requireLogin(uis.loadCtx(uis.distrosPage))).Methods("GET")
-	r.HandleFunc("/distros", uis.requireSuperUser(uis.loadCtx(uis.addDistro))).Methods("PUT")
+	r.HandleFunc("/distros", requireLogin(uis.loadCtx(uis.requirePermission(role.DistroAdmin, uis.addDistro)))).Methods("PUT")
 	r.HandleFunc("/distros/{distro_id}", requireLogin(uis.loadCtx(uis.getDistro))).Methods("GET")
-	r.HandleFunc("/distros/{distro_id}", uis.requireSuperUser(uis.loadCtx(uis.addDistro))).Methods("PUT")
-	r.HandleFunc("/distros/{distro_id}", uis.requireSuperUser(uis.loadCtx(uis.modifyDistro))).Methods("POST")
-	r.HandleFunc("/distros/{distro_id}", uis.requireSuperUser(uis.loadCtx(uis.removeDistro))).Methods("DELETE")
+	r.HandleFunc("/distros/{distro_id}", requireLogin(uis.loadCtx(uis.requirePermission(role.DistroAdmin, uis.addDistro)))).Methods("PUT")
+	r.HandleFunc("/distros/{distro_id}", requireLogin(uis.loadCtx(uis.requirePermission(role.DistroAdmin, uis.modifyDistro)))).Methods("POST")
+	r.HandleFunc("/distros/{distro_id}", requireLogin(uis.loadCtx(uis.requirePermission(role.DistroAdmin, uis.removeDistro)))).Methods("DELETE")
 
 	// Event Logs
 	r.HandleFunc("/event_log/{resource_type}/{resource_id:[\\w_\\-\\:\\.\\@]+}", uis.loadCtx(uis.fullEventLogs))
@@ -213,7 +214,7 @@ func (uis *UIServer) AttachRoutes(r *mux.Router) error {
 
 	// Patch pages
 	r.HandleFunc("/patch/{patch_id}", requireLogin(uis.loadCtx(uis.patchPage))).Methods("GET")
-	r.HandleFunc("/patch/{patch_id}", requireLogin(uis.loadCtx(uis.schedulePatch))).Methods("POST")
+	r.HandleFunc("/patch/{patch_id}", requireLogin(uis.loadCtx(uis.requirePermission(role.PatchSubmit, uis.schedulePatch)))).Methods("POST")
 	r.HandleFunc("/diff/{patch_id}/", requireLogin(uis.loadCtx(uis.diffPage)))
 	r.HandleFunc("/filediff/{patch_id}/", requireLogin(uis.loadCtx(uis.fileDiffPage)))
 	r.HandleFunc("/rawdiff/{patch_id}/", requireLogin(uis.loadCtx(uis.rawDiffPage)))
@@ -255,7 +256,7 @@ func (uis *UIServer) AttachRoutes(r *mux.Router) error {
 	AttachRESTHandler(r, uis)
 
 	// attaches /rest/v2 routes
-	route.AttachHandler(r, uis.queue, uis.Settings.Ui.Url, evergreen.RestRoutePrefix, uis.Settings.SuperUsers, []byte(uis.Settings.Api.GithubWebhookSecret))
+	route.AttachHandler(r, uis.queue, uis.Settings.Ui.Url, evergreen.RestRoutePrefix, uis.Settings.SuperUsers, uis.Settings.RBAC, []byte(uis.Settings.Api.GithubWebhookSecret))
 
 	// Static Path handlers
 	r.PathPrefix("/clients").Handler(http.StripPrefix("/clients", http.FileServer(http.Dir(filepath.Join(uis.Home, evergreen.ClientDirectory)))))
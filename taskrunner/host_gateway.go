@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"math/rand"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -233,20 +234,14 @@ func startAgentOnRemote(settings *evergreen.Settings, hostObj *host.Host, sshOpt
 		Background:     true,
 	}
 
-	if sumoEndpoint, ok := settings.Credentials["sumologic"]; ok {
-		startAgentCmd.EnvVars = append(startAgentCmd.EnvVars,
-			fmt.Sprintf("GRIP_SUMO_ENDPOINT='%s'", sumoEndpoint))
+	env := AgentEnvironment(settings)
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
 	}
-
-	if settings.Splunk.Populated() {
-		startAgentCmd.EnvVars = append(startAgentCmd.EnvVars,
-			fmt.Sprintf("GRIP_SPLUNK_SERVER_URL='%s'", settings.Splunk.ServerURL),
-			fmt.Sprintf("GRIP_SPLUNK_CLIENT_TOKEN='%s'", settings.Splunk.Token))
-
-		if settings.Splunk.Channel != "" {
-			startAgentCmd.EnvVars = append(startAgentCmd.EnvVars,
-				fmt.Sprintf("GRIP_SPLUNK_CHANNEL='%s'", settings.Splunk.Channel))
-		}
+	sort.Strings(keys)
+	for _, k := range keys {
+		startAgentCmd.EnvVars = append(startAgentCmd.EnvVars, fmt.Sprintf("%s='%s'", k, env[k]))
 	}
 
 	ctx, cancel := context.WithTimeout(context.TODO(), sshTimeout)
@@ -270,3 +265,24 @@ func startAgentOnRemote(settings *evergreen.Settings, hostObj *host.Host, sshOpt
 
 	return nil
 }
+
+// AgentEnvironment returns the environment variables that configure where the
+// agent sends its logs.
+func AgentEnvironment(settings *evergreen.Settings) map[string]string {
+	env := map[string]string{}
+
+	if sumoEndpoint, ok := settings.Credentials["sumologic"]; ok {
+		env["GRIP_SUMO_ENDPOINT"] = sumoEndpoint
+	}
+
+	if settings.Splunk.Populated() {
+		env["GRIP_SPLUNK_SERVER_URL"] = settings.Splunk.ServerURL
+		env["GRIP_SPLUNK_CLIENT_TOKEN"] = settings.Splunk.Token
+
+		if settings.Splunk.Channel != "" {
+			env["GRIP_SPLUNK_CHANNEL"] = settings.Splunk.Channel
+		}
+	}
+
+	return env
+}
@@ -0,0 +1,310 @@
+package thirdparty
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/goamz/goamz/aws"
+	"github.com/goamz/goamz/s3"
+	"github.com/pkg/errors"
+)
+
+// BlobInfo describes a blob stored in a Bucket.
+type BlobInfo struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// PutOptions are the optional settings for storing a blob. Backends
+// that have no use for a setting ignore it.
+type PutOptions struct {
+	ContentType string
+	Permissions string
+}
+
+// Bucket is a flat store of blobs addressed by slash separated keys, such
+// as an S3 bucket or a directory on the local filesystem.
+type Bucket interface {
+	// Put stores the size bytes read from r under the key, replacing
+	// any blob already stored there.
+	Put(key string, r io.Reader, size int64, opts PutOptions) error
+
+	// Get returns the contents of the blob stored under the key. It
+	// returns a BlobNotFoundError if there is none.
+	Get(key string) (io.ReadCloser, error)
+
+	// List returns every blob whose key starts with the prefix.
+	List(prefix string) ([]BlobInfo, error)
+
+	// URL returns the address of the blob stored under the key, for
+	// linking to it.
+	URL(key string) string
+}
+
+// BucketOptions describe the bucket to open with NewBucket.
+type BucketOptions struct {
+	// Provider is one of the evergreen.BlobProvider constants, and
+	// defaults to S3.
+	Provider string
+
+	// Name is the name of the bucket. The local provider stores it in
+	// a directory of that name under LocalRoot, or, if there is no
+	// LocalRoot or the name is an absolute path, in the directory Name.
+	Name      string
+	LocalRoot string
+
+	// Auth holds the credentials for the S3 providers.
+	Auth *aws.Auth
+
+	// Endpoint is the URL of the service for the s3-compatible
+	// provider, which addresses buckets by path if PathStyle is set, and
+	// by host name otherwise.
+	Endpoint  string
+	PathStyle bool
+}
+
+// NewBucket returns the Bucket described by the options.
+func NewBucket(opts BucketOptions) (Bucket, error) {
+	if opts.Name == "" {
+		return nil, errors.New("bucket name cannot be blank")
+	}
+
+	switch opts.Provider {
+	case "", evergreen.BlobProviderS3:
+		if opts.Auth == nil {
+			return nil, errors.New("s3 buckets require credentials")
+		}
+		return NewS3Bucket(opts.Auth, opts.Name), nil
+	case evergreen.BlobProviderS3Compatible:
+		if opts.Auth == nil {
+			return nil, errors.New("s3 buckets require credentials")
+		}
+		region, err := s3CompatibleRegion(opts.Endpoint, opts.PathStyle)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return &s3Bucket{auth: opts.Auth, region: region, name: opts.Name}, nil
+	case evergreen.BlobProviderLocal:
+		dir := opts.Name
+		if opts.LocalRoot != "" && !filepath.IsAbs(dir) {
+			dir = filepath.Join(opts.LocalRoot, dir)
+		}
+		return NewLocalBucket(dir), nil
+	default:
+		return nil, errors.Errorf("unknown blob storage provider '%s'", opts.Provider)
+	}
+}
+
+// PutFile stores the contents of a local file in the bucket. Errors
+// opening the file are returned as they are, so callers can check for
+// missing files with os.IsNotExist.
+func PutFile(b Bucket, localFilePath, key string, opts PutOptions) error {
+	fi, err := os.Stat(localFilePath)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(localFilePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return errors.Wrapf(b.Put(key, f, fi.Size(), opts), "problem putting %s to bucket", localFilePath)
+}
+
+// CopyBlob copies a blob between two buckets, or within one. Copies
+// between buckets of the same S3 service are done by the service;
+// others are downloaded and uploaded again.
+func CopyBlob(from Bucket, fromKey string, to Bucket, toKey string, opts PutOptions) error {
+	src, srcIsS3 := from.(*s3Bucket)
+	dst, dstIsS3 := to.(*s3Bucket)
+	if srcIsS3 && dstIsS3 && src.region.S3Endpoint == dst.region.S3Endpoint {
+		return errors.WithStack(dst.copyFrom(src.name, fromKey, toKey, opts.Permissions))
+	}
+
+	r, err := from.Get(fromKey)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer r.Close()
+
+	// buckets need the size of a blob up front, so stage it in a file
+	tmp, err := ioutil.TempFile("", "evergreen-blob")
+	if err != nil {
+		return errors.Wrap(err, "problem creating temporary file")
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+
+	size, err := io.Copy(tmp, r)
+	if err != nil {
+		return errors.Wrapf(err, "problem reading %s", fromKey)
+	}
+	if _, err = tmp.Seek(0, io.SeekStart); err != nil {
+		return errors.Wrapf(err, "problem reading %s", fromKey)
+	}
+	return errors.WithStack(to.Put(toKey, tmp, size, opts))
+}
+
+// BlobNotFoundError is returned by a Bucket's Get when no blob is
+// stored under the key.
+type BlobNotFoundError struct {
+	Key string
+}
+
+func (e BlobNotFoundError) Error() string {
+	return "blob not found: " + e.Key
+}
+
+// IsBlobNotFound returns true if the cause of err is a BlobNotFoundError.
+func IsBlobNotFound(err error) bool {
+	_, ok := errors.Cause(err).(BlobNotFoundError)
+	return ok
+}
+
+// s3Bucket is a Bucket that stores blobs in an S3 bucket.
+type s3Bucket struct {
+	auth   *aws.Auth
+	region aws.Region
+	name   string
+}
+
+// NewS3Bucket returns a Bucket backed by the named S3 bucket.
+func NewS3Bucket(auth *aws.Auth, name string) Bucket {
+	return &s3Bucket{
+		auth:   auth,
+		region: aws.USEast,
+		name:   name,
+	}
+}
+
+// s3CompatibleRegion returns a region for an S3-compatible service at
+// the endpoint.
+func s3CompatibleRegion(endpoint string, pathStyle bool) (aws.Region, error) {
+	u, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return aws.Region{}, errors.Errorf("invalid endpoint '%s'", endpoint)
+	}
+
+	region := aws.Region{
+		Name:       aws.USEast.Name,
+		S3Endpoint: u.String(),
+	}
+	if !pathStyle {
+		region.S3BucketEndpoint = u.Scheme + "://${bucket}." + u.Host + u.Path
+	}
+	return region, nil
+}
+
+func (b *s3Bucket) bucket(client *http.Client) *s3.Bucket {
+	return NewS3Session(b.auth, b.region, client).Bucket(b.name)
+}
+
+func (b *s3Bucket) Put(key string, r io.Reader, size int64, opts PutOptions) error {
+	client := util.GetHttpClient()
+	defer util.PutHttpClient(client)
+
+	contentType := opts.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	permissions := opts.Permissions
+	if permissions == "" {
+		permissions = string(s3.Private)
+	}
+
+	err := b.bucket(client).PutReader(key, r, size, contentType, s3.ACL(permissions), s3.Options{})
+	return errors.Wrapf(err, "problem putting %s to s3 bucket %s", key, b.name)
+}
+
+func (b *s3Bucket) Get(key string) (io.ReadCloser, error) {
+	client := util.GetHttpClient()
+
+	reader, err := b.bucket(client).GetReader(key)
+	if err != nil {
+		util.PutHttpClient(client)
+		if s3Err, ok := err.(*s3.Error); ok && s3Err.StatusCode == http.StatusNotFound {
+			return nil, BlobNotFoundError{Key: key}
+		}
+		return nil, errors.Wrapf(err, "problem getting %s from s3 bucket %s", key, b.name)
+	}
+	return &clientReadCloser{ReadCloser: reader, client: client}, nil
+}
+
+func (b *s3Bucket) List(prefix string) ([]BlobInfo, error) {
+	client := util.GetHttpClient()
+	defer util.PutHttpClient(client)
+	bucket := b.bucket(client)
+
+	out := []BlobInfo{}
+	marker := ""
+	for {
+		resp, err := bucket.List(prefix, "", marker, 1000)
+		if err != nil {
+			return nil, errors.Wrapf(err, "problem listing %s in s3 bucket %s", prefix, b.name)
+		}
+		for _, k := range resp.Contents {
+			modified, err := time.Parse(time.RFC3339Nano, k.LastModified)
+			if err != nil {
+				return nil, errors.Wrapf(err, "malformed modification time for %s", k.Key)
+			}
+			out = append(out, BlobInfo{
+				Key:          k.Key,
+				Size:         k.Size,
+				LastModified: modified,
+			})
+		}
+		if !resp.IsTruncated || len(resp.Contents) == 0 {
+			return out, nil
+		}
+		marker = resp.Contents[len(resp.Contents)-1].Key
+	}
+}
+
+func (b *s3Bucket) URL(key string) string {
+	key = cleanBlobKey(key)
+	if b.region.S3BucketEndpoint != "" {
+		return strings.Replace(b.region.S3BucketEndpoint, "${bucket}", b.name, -1) + "/" + key
+	}
+	return b.region.S3Endpoint + "/" + b.name + "/" + key
+}
+
+// copyFrom has the S3 service copy a blob from another bucket into this
+// one.
+func (b *s3Bucket) copyFrom(fromBucket, fromKey, toKey, permissionACL string) error {
+	if b.region.S3Endpoint == aws.USEast.S3Endpoint {
+		return S3CopyFile(b.auth, fromBucket, cleanBlobKey(fromKey), b.name, cleanBlobKey(toKey), permissionACL)
+	}
+	return s3CopyObject(b.auth, b.URL(toKey), fromBucket, cleanBlobKey(fromKey),
+		b.name, cleanBlobKey(toKey), permissionACL)
+}
+
+// clientReadCloser returns the pooled http client it reads through to
+// the pool once it is closed.
+type clientReadCloser struct {
+	io.ReadCloser
+	client *http.Client
+}
+
+func (r *clientReadCloser) Close() error {
+	defer util.PutHttpClient(r.client)
+	return r.ReadCloser.Close()
+}
+
+// cleanBlobKey strips the leading slashes that URL paths have but blob
+// keys don't.
+func cleanBlobKey(key string) string {
+	return strings.TrimLeft(key, "/")
+}
@@ -0,0 +1,124 @@
+package thirdparty
+
+import (
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// localBucket is a Bucket that stores each blob as a file under a
+// directory, with the key as the file's relative path.
+type localBucket struct {
+	dir string
+}
+
+// NewLocalBucket returns a Bucket that stores blobs under dir, which is
+// created by the first Put.
+func NewLocalBucket(dir string) Bucket {
+	return &localBucket{dir: dir}
+}
+
+// path returns the file for a key, refusing keys that would escape the
+// bucket's directory.
+func (b *localBucket) path(key string) (string, error) {
+	key = cleanBlobKey(key)
+	path := filepath.Join(b.dir, filepath.FromSlash(key))
+	if key == "" || !strings.HasPrefix(path, filepath.Clean(b.dir)+string(filepath.Separator)) {
+		return "", errors.Errorf("invalid blob key '%s'", key)
+	}
+	return path, nil
+}
+
+func (b *localBucket) Put(key string, r io.Reader, size int64, opts PutOptions) error {
+	path, err := b.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.Wrapf(err, "problem creating directory for %s", key)
+	}
+
+	// write to a temporary file first, so that readers never see a
+	// partially written blob
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".blob")
+	if err != nil {
+		return errors.Wrapf(err, "problem creating temporary file for %s", key)
+	}
+	n, err := io.Copy(tmp, r)
+	closeErr := tmp.Close()
+	if err == nil && n != size {
+		err = errors.Errorf("expected %d bytes but read %d", size, n)
+	}
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return errors.Wrapf(err, "problem writing %s", key)
+	}
+	return nil
+}
+
+func (b *localBucket) Get(key string) (io.ReadCloser, error) {
+	path, err := b.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, BlobNotFoundError{Key: key}
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem reading %s", key)
+	}
+	return f, nil
+}
+
+func (b *localBucket) List(prefix string) ([]BlobInfo, error) {
+	prefix = cleanBlobKey(prefix)
+	out := []BlobInfo{}
+	err := filepath.Walk(b.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || strings.HasPrefix(info.Name(), ".blob") {
+			return nil
+		}
+		rel, err := filepath.Rel(b.dir, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if strings.HasPrefix(key, prefix) {
+			out = append(out, BlobInfo{
+				Key:          key,
+				Size:         info.Size(),
+				LastModified: info.ModTime(),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem listing %s", prefix)
+	}
+	return out, nil
+}
+
+func (b *localBucket) URL(key string) string {
+	path, err := filepath.Abs(filepath.Join(b.dir, filepath.FromSlash(cleanBlobKey(key))))
+	if err != nil {
+		path = filepath.Join(b.dir, key)
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
@@ -0,0 +1,158 @@
+package thirdparty
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/goamz/goamz/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type LocalBucketSuite struct {
+	dir    string
+	bucket Bucket
+	suite.Suite
+}
+
+func TestLocalBucketSuite(t *testing.T) {
+	suite.Run(t, new(LocalBucketSuite))
+}
+
+func (s *LocalBucketSuite) SetupTest() {
+	var err error
+	s.dir, err = ioutil.TempDir("", "evergreen.thirdparty.bucket.test")
+	s.Require().NoError(err)
+	s.bucket = NewLocalBucket(s.dir)
+}
+
+func (s *LocalBucketSuite) TearDownTest() {
+	s.NoError(os.RemoveAll(s.dir))
+}
+
+func (s *LocalBucketSuite) put(key, data string) error {
+	return s.bucket.Put(key, bytes.NewBufferString(data), int64(len(data)), PutOptions{})
+}
+
+func (s *LocalBucketSuite) TestPutAndGet() {
+	s.NoError(s.put("a/b/c.txt", "hello"))
+	s.NoError(s.put("/a/b/c.txt", "replaced"))
+
+	r, err := s.bucket.Get("a/b/c.txt")
+	s.Require().NoError(err)
+	data, err := ioutil.ReadAll(r)
+	s.NoError(err)
+	s.NoError(r.Close())
+	s.Equal("replaced", string(data))
+
+	_, err = s.bucket.Get("a/b/missing.txt")
+	s.True(IsBlobNotFound(err))
+}
+
+func (s *LocalBucketSuite) TestPutRejectsShortReadsAndEscapes() {
+	s.Error(s.bucket.Put("short", bytes.NewBufferString("abc"), 10, PutOptions{}))
+	s.Error(s.put("../outside", "data"))
+	s.Error(s.put("", "data"))
+
+	blobs, err := s.bucket.List("")
+	s.NoError(err)
+	s.Len(blobs, 0)
+}
+
+func (s *LocalBucketSuite) TestList() {
+	blobs, err := s.bucket.List("cache/")
+	s.NoError(err)
+	s.Len(blobs, 0)
+
+	s.NoError(s.put("cache/go-linux-abc.tar.gz", "1"))
+	s.NoError(s.put("cache/go-linux-def.tar.gz", "22"))
+	s.NoError(s.put("cache/go-windows-abc.tar.gz", "333"))
+	s.NoError(s.put("other/go-linux-abc.tar.gz", "4444"))
+
+	blobs, err = s.bucket.List("cache/go-linux-")
+	s.NoError(err)
+	s.Require().Len(blobs, 2)
+	sizes := map[string]int64{}
+	for _, b := range blobs {
+		sizes[b.Key] = b.Size
+		s.False(b.LastModified.IsZero())
+	}
+	s.Equal(map[string]int64{
+		"cache/go-linux-abc.tar.gz": 1,
+		"cache/go-linux-def.tar.gz": 2,
+	}, sizes)
+
+	blobs, err = s.bucket.List("")
+	s.NoError(err)
+	s.Len(blobs, 4)
+}
+
+func (s *LocalBucketSuite) TestCopyBlobBetweenBuckets() {
+	s.NoError(s.put("staging/artifact.tgz", "contents"))
+
+	other := NewLocalBucket(filepath.Join(s.dir, "other"))
+	s.NoError(CopyBlob(s.bucket, "staging/artifact.tgz", other, "release/artifact.tgz", PutOptions{}))
+
+	r, err := other.Get("release/artifact.tgz")
+	s.Require().NoError(err)
+	data, err := ioutil.ReadAll(r)
+	s.NoError(err)
+	s.NoError(r.Close())
+	s.Equal("contents", string(data))
+
+	err = CopyBlob(s.bucket, "staging/missing.tgz", other, "release/missing.tgz", PutOptions{})
+	s.True(IsBlobNotFound(err))
+}
+
+func (s *LocalBucketSuite) TestURL() {
+	s.Equal("file://"+filepath.ToSlash(filepath.Join(s.dir, "a", "b.txt")), s.bucket.URL("/a/b.txt"))
+}
+
+func TestNewBucket(t *testing.T) {
+	assert := assert.New(t)
+	auth := &aws.Auth{AccessKey: "key", SecretKey: "secret"}
+
+	b, err := NewBucket(BucketOptions{Name: "mciuploads", Auth: auth})
+	assert.NoError(err)
+	assert.Equal("https://s3.amazonaws.com/mciuploads/a/b.tgz", b.URL("a/b.tgz"))
+
+	b, err = NewBucket(BucketOptions{
+		Provider:  evergreen.BlobProviderS3Compatible,
+		Name:      "mciuploads",
+		Auth:      auth,
+		Endpoint:  "http://minio.example.com:9000/",
+		PathStyle: true,
+	})
+	assert.NoError(err)
+	assert.Equal("http://minio.example.com:9000/mciuploads/a/b.tgz", b.URL("/a/b.tgz"))
+
+	b, err = NewBucket(BucketOptions{
+		Provider: evergreen.BlobProviderS3Compatible,
+		Name:     "mciuploads",
+		Auth:     auth,
+		Endpoint: "https://storage.example.com",
+	})
+	assert.NoError(err)
+	assert.Equal("https://mciuploads.storage.example.com/a/b.tgz", b.URL("a/b.tgz"))
+
+	b, err = NewBucket(BucketOptions{
+		Provider:  evergreen.BlobProviderLocal,
+		Name:      "mciuploads",
+		LocalRoot: "/srv/blobs",
+	})
+	assert.NoError(err)
+	assert.Equal("file:///srv/blobs/mciuploads/a/b.tgz", b.URL("a/b.tgz"))
+
+	_, err = NewBucket(BucketOptions{Provider: evergreen.BlobProviderS3Compatible, Name: "mciuploads", Auth: auth})
+	assert.Error(err)
+	_, err = NewBucket(BucketOptions{Name: "mciuploads"})
+	assert.Error(err)
+	_, err = NewBucket(BucketOptions{Provider: "ftp", Name: "mciuploads", Auth: auth})
+	assert.Error(err)
+	_, err = NewBucket(BucketOptions{Auth: auth})
+	assert.Error(err)
+}
@@ -0,0 +1,369 @@
+package thirdparty
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// the fields and records of the log format are separated with the
+	// ASCII unit and record separators, which do not appear in commit
+	// messages in practice
+	gitLogFieldSeparator  = "\x1f"
+	gitLogRecordSeparator = "\x1e"
+	gitLogFormat          = "--format=%H%x1f%an%x1f%ae%x1f%ct%x1f%B%x1e"
+)
+
+// GitCommit is a commit read from a git repository.
+type GitCommit struct {
+	SHA         string
+	AuthorName  string
+	AuthorEmail string
+	Message     string
+	CommitTime  time.Time
+}
+
+// GitMirror is a local bare copy of a single branch of a git remote. It
+// only needs the git binary and whatever credentials git itself uses to
+// reach the remote, so it works against any git server, not only GitHub.
+type GitMirror struct {
+	URL    string
+	Branch string
+	Dir    string
+}
+
+// NewGitMirror returns a mirror of the branch of the remote at url, kept
+// in dir. The mirror is created by the first call to Sync.
+func NewGitMirror(url, branch, dir string) *GitMirror {
+	return &GitMirror{
+		URL:    url,
+		Branch: branch,
+		Dir:    dir,
+	}
+}
+
+func (m *GitMirror) branchRef() string {
+	return "refs/heads/" + m.Branch
+}
+
+// Sync creates the mirror if it does not exist yet, and fetches the
+// branch from the remote. History rewritten on the remote is rewritten
+// in the mirror as well.
+func (m *GitMirror) Sync() error {
+	if m.URL == "" {
+		return errors.New("no remote url for git mirror")
+	}
+	if m.Branch == "" {
+		return errors.Errorf("no branch to mirror from %s", m.URL)
+	}
+	if err := checkGitArgument("remote url", m.URL); err != nil {
+		return err
+	}
+	if err := checkGitArgument("branch", m.Branch); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(filepath.Join(m.Dir, "HEAD")); os.IsNotExist(err) {
+		if err = os.MkdirAll(m.Dir, 0755); err != nil {
+			return errors.Wrapf(err, "problem creating git mirror directory %s", m.Dir)
+		}
+		if _, err = m.git("init", "--bare", "--quiet"); err != nil {
+			return errors.Wrapf(err, "problem creating git mirror of %s", m.URL)
+		}
+	}
+
+	refspec := fmt.Sprintf("+%s:%s", m.branchRef(), m.branchRef())
+	if _, err := m.git("fetch", "--quiet", "--prune", "--end-of-options", m.URL, refspec); err != nil {
+		return errors.Wrapf(err, "problem fetching branch %s from %s", m.Branch, m.URL)
+	}
+	return nil
+}
+
+// Head returns the commit at the tip of the mirrored branch.
+func (m *GitMirror) Head() (string, error) {
+	out, err := m.git("rev-parse", "--verify", "--end-of-options", m.branchRef()+"^{commit}")
+	if err != nil {
+		return "", errors.Wrapf(err, "problem finding the head of branch %s", m.Branch)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// HasCommit returns true if the revision is a commit in the mirror.
+func (m *GitMirror) HasCommit(revision string) bool {
+	_, err := m.git("cat-file", "-e", "--end-of-options", revision+"^{commit}")
+	return err == nil
+}
+
+// OnBranch returns true if the revision is part of the history of the
+// mirrored branch.
+func (m *GitMirror) OnBranch(revision string) bool {
+	_, err := m.git("merge-base", "--is-ancestor", "--end-of-options", revision, m.branchRef())
+	return err == nil
+}
+
+// MergeBase returns the most recent commit of the mirrored branch that
+// the revision is based on.
+func (m *GitMirror) MergeBase(revision string) (string, error) {
+	out, err := m.git("merge-base", "--end-of-options", revision, m.branchRef())
+	if err != nil {
+		return "", errors.Wrapf(err, "problem finding merge base of %s and %s", revision, m.Branch)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Log returns the commits of the mirrored branch, newest first. If
+// since is not empty only the commits made after it are returned. A
+// limit <= 0 returns every commit.
+func (m *GitMirror) Log(since string, limit int) ([]GitCommit, error) {
+	args := []string{"log", gitLogFormat}
+	if limit > 0 {
+		args = append(args, "-n", strconv.Itoa(limit))
+	}
+	args = append(args, "--end-of-options")
+	if since != "" {
+		args = append(args, since+".."+m.branchRef())
+	} else {
+		args = append(args, m.branchRef())
+	}
+	args = append(args, "--")
+
+	out, err := m.git(args...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem reading the history of branch %s", m.Branch)
+	}
+
+	commits := []GitCommit{}
+	for _, record := range strings.Split(string(out), gitLogRecordSeparator) {
+		record = strings.TrimLeft(record, "\n")
+		if record == "" {
+			continue
+		}
+		fields := strings.SplitN(record, gitLogFieldSeparator, 5)
+		if len(fields) != 5 {
+			return nil, errors.Errorf("malformed git log record '%s'", record)
+		}
+		seconds, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "malformed commit time for %s", fields[0])
+		}
+		commits = append(commits, GitCommit{
+			SHA:         fields[0],
+			AuthorName:  fields[1],
+			AuthorEmail: fields[2],
+			CommitTime:  time.Unix(seconds, 0),
+			Message:     strings.TrimRight(fields[4], "\n"),
+		})
+	}
+	return commits, nil
+}
+
+// ChangedFiles returns the paths of the files the revision modified,
+// relative to its first parent.
+func (m *GitMirror) ChangedFiles(revision string) ([]string, error) {
+	out, err := m.git("diff-tree", "-z", "-r", "-m", "--first-parent", "--root",
+		"--no-commit-id", "--name-only", "--end-of-options", revision)
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem finding the files changed by %s", revision)
+	}
+
+	files := []string{}
+	for _, f := range strings.Split(string(out), "\x00") {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+// ReadFile returns the contents of the file at path as at the revision.
+// It returns a FileNotFoundError if the file does not exist at that
+// revision.
+func (m *GitMirror) ReadFile(revision, path string) ([]byte, error) {
+	object := fmt.Sprintf("%s:%s", revision, strings.TrimPrefix(path, "/"))
+	if _, err := m.git("cat-file", "-e", "--end-of-options", object); err != nil {
+		return nil, FileNotFoundError{object}
+	}
+	out, err := m.git("cat-file", "blob", "--end-of-options", object)
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem reading %s", object)
+	}
+	return out, nil
+}
+
+// ValidateGitRemoteURL returns an error unless the url is an https or ssh
+// url, or an scp-like address such as git@github.com:owner/repo.git. Other
+// transports could read the server's own repositories or run commands on
+// it, so urls that come from users must be checked with this before they
+// are fetched.
+func ValidateGitRemoteURL(url string) error {
+	if err := checkGitArgument("remote url", url); err != nil {
+		return err
+	}
+	for _, prefix := range []string{"https://", "ssh://", "git@"} {
+		if strings.HasPrefix(url, prefix) {
+			if rest := strings.TrimPrefix(url, prefix); rest == "" || strings.HasPrefix(rest, "-") {
+				return errors.Errorf("invalid git remote url '%s'", url)
+			}
+			return nil
+		}
+	}
+	return errors.Errorf("git remote url '%s' must be an https, ssh or git@ url", url)
+}
+
+// checkGitArgument returns an error if the value is empty or could be
+// mistaken for an option by git.
+func checkGitArgument(name, value string) error {
+	if value == "" {
+		return errors.Errorf("no git %s", name)
+	}
+	if strings.HasPrefix(value, "-") {
+		return errors.Errorf("invalid git %s '%s'", name, value)
+	}
+	return nil
+}
+
+// ReadGitRemoteFile returns the contents of the file at path as at the ref
+// of the remote at url, which may be a branch, a tag or, if the server
+// allows fetching them, a commit. Only that ref is fetched, into a
+// temporary repository.
+func ReadGitRemoteFile(url, ref, path string) ([]byte, error) {
+	if err := checkGitArgument("remote url", url); err != nil {
+		return nil, err
+	}
+	if err := checkGitArgument("ref", ref); err != nil {
+		return nil, err
+	}
+
+	dir, err := ioutil.TempDir("", "evergreen-git-remote")
+	if err != nil {
+		return nil, errors.Wrap(err, "problem creating temporary git repository")
+	}
+	defer os.RemoveAll(dir)
+
+	m := NewGitMirror(url, ref, dir)
+	if _, err = m.git("init", "--bare", "--quiet"); err != nil {
+		return nil, errors.Wrapf(err, "problem creating temporary git repository for %s", url)
+	}
+	if _, err = m.git("fetch", "--quiet", "--depth", "1", "--end-of-options", url, ref); err != nil {
+		return nil, errors.Wrapf(err, "problem fetching %s from %s", ref, url)
+	}
+	return m.ReadFile("FETCH_HEAD", path)
+}
+
+// GitPatchCommit is a commit made by applying a patch to a branch.
+type GitPatchCommit struct {
+	// Base is the commit the patch applies to, which must still be the
+	// head of the branch.
+	Base        string
+	Patch       string
+	Message     string
+	AuthorName  string
+	AuthorEmail string
+}
+
+// PushGitPatch commits the patch on top of the branch of the remote at url
+// and pushes the commit, returning its SHA. The push only fast-forwards the
+// branch, so it fails if the branch moved on from the commit's base. If
+// authHeader is not empty it is sent as the Authorization header of the
+// requests git makes to the remote. Only the head of the branch is fetched,
+// into a temporary repository.
+func PushGitPatch(url, branch, authHeader string, commit GitPatchCommit) (string, error) {
+	if err := checkGitArgument("remote url", url); err != nil {
+		return "", err
+	}
+	if err := checkGitArgument("branch", branch); err != nil {
+		return "", err
+	}
+
+	dir, err := ioutil.TempDir("", "evergreen-git-push")
+	if err != nil {
+		return "", errors.Wrap(err, "problem creating temporary git repository")
+	}
+	defer os.RemoveAll(dir)
+
+	m := NewGitMirror(url, branch, dir)
+	if _, err = m.git("init", "--bare", "--quiet"); err != nil {
+		return "", errors.Wrapf(err, "problem creating temporary git repository for %s", url)
+	}
+	if authHeader != "" {
+		if _, err = m.git("config", "http.extraheader", "Authorization: "+authHeader); err != nil {
+			return "", errors.Wrap(err, "problem configuring git credentials")
+		}
+	}
+	if _, err = m.git("fetch", "--quiet", "--depth", "1", "--end-of-options", url, m.branchRef()); err != nil {
+		return "", errors.Wrapf(err, "problem fetching branch %s from %s", branch, url)
+	}
+	head, err := m.git("rev-parse", "--verify", "--end-of-options", "FETCH_HEAD^{commit}")
+	if err != nil {
+		return "", errors.Wrapf(err, "problem finding the head of branch %s", branch)
+	}
+	if strings.TrimSpace(string(head)) != commit.Base {
+		return "", errors.Errorf("branch %s is no longer at %s", branch, commit.Base)
+	}
+
+	patchFile := filepath.Join(dir, "evergreen.patch")
+	if err = ioutil.WriteFile(patchFile, []byte(commit.Patch), 0600); err != nil {
+		return "", errors.Wrap(err, "problem writing patch file")
+	}
+	if _, err = m.git("read-tree", "--end-of-options", "FETCH_HEAD"); err != nil {
+		return "", errors.Wrap(err, "problem reading the tree of the branch")
+	}
+	if _, err = m.git("apply", "--cached", "--end-of-options", patchFile); err != nil {
+		return "", errors.Wrap(err, "problem applying patch")
+	}
+	tree, err := m.git("write-tree")
+	if err != nil {
+		return "", errors.Wrap(err, "problem writing patched tree")
+	}
+
+	cmd := []string{"commit-tree", "-p", commit.Base, "-m", commit.Message, "--end-of-options", strings.TrimSpace(string(tree))}
+	sha, err := m.gitWithEnv([]string{
+		"GIT_AUTHOR_NAME=" + commit.AuthorName,
+		"GIT_AUTHOR_EMAIL=" + commit.AuthorEmail,
+		"GIT_COMMITTER_NAME=" + commit.AuthorName,
+		"GIT_COMMITTER_EMAIL=" + commit.AuthorEmail,
+	}, cmd...)
+	if err != nil {
+		return "", errors.Wrap(err, "problem committing patch")
+	}
+
+	revision := strings.TrimSpace(string(sha))
+	if _, err = m.git("push", "--quiet", "--end-of-options", url, revision+":"+m.branchRef()); err != nil {
+		return "", errors.Wrapf(err, "problem pushing to branch %s of %s", branch, url)
+	}
+	return revision, nil
+}
+
+// git runs a git command in the mirror and returns its standard output.
+// Git never prompts for credentials, since there is nobody to answer.
+// Callers put --end-of-options before arguments that come from users, so
+// that git can't read them as options.
+func (m *GitMirror) git(args ...string) ([]byte, error) {
+	return m.gitWithEnv(nil, args...)
+}
+
+// gitWithEnv runs a git command in the mirror with the extra environment
+// variables and returns its standard output.
+func (m *GitMirror) gitWithEnv(env []string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Dir = m.Dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_DIR="+m.Dir)
+	cmd.Env = append(cmd.Env, env...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err, "git %s: %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
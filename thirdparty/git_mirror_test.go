@@ -0,0 +1,250 @@
+package thirdparty
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type GitMirrorSuite struct {
+	remote string
+	dir    string
+	mirror *GitMirror
+	suite.Suite
+}
+
+func TestGitMirrorSuite(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	suite.Run(t, new(GitMirrorSuite))
+}
+
+func (s *GitMirrorSuite) SetupTest() {
+	var err error
+	s.dir, err = ioutil.TempDir("", "git-mirror-test")
+	s.Require().NoError(err)
+	s.remote = filepath.Join(s.dir, "remote")
+	s.Require().NoError(os.MkdirAll(s.remote, 0755))
+	s.run("init", "--quiet")
+	s.run("checkout", "--quiet", "-b", "master")
+
+	s.mirror = NewGitMirror("file://"+s.remote, "master", filepath.Join(s.dir, "mirror.git"))
+}
+
+func (s *GitMirrorSuite) TearDownTest() {
+	s.NoError(os.RemoveAll(s.dir))
+}
+
+func (s *GitMirrorSuite) run(args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = s.remote
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Alice", "GIT_AUTHOR_EMAIL=alice@example.com",
+		"GIT_COMMITTER_NAME=Alice", "GIT_COMMITTER_EMAIL=alice@example.com")
+	out, err := cmd.CombinedOutput()
+	s.Require().NoError(err, string(out))
+	return strings.TrimSpace(string(out))
+}
+
+func (s *GitMirrorSuite) commit(message string, files map[string]string) string {
+	for name, contents := range files {
+		path := filepath.Join(s.remote, name)
+		s.Require().NoError(os.MkdirAll(filepath.Dir(path), 0755))
+		s.Require().NoError(ioutil.WriteFile(path, []byte(contents), 0644))
+		s.run("add", name)
+	}
+	s.run("commit", "--quiet", "-m", message)
+	return s.run("rev-parse", "HEAD")
+}
+
+func (s *GitMirrorSuite) TestSyncRequiresRemoteAndBranch() {
+	s.Error(NewGitMirror("", "master", s.mirror.Dir).Sync())
+	s.Error(NewGitMirror(s.mirror.URL, "", s.mirror.Dir).Sync())
+	s.Error(NewGitMirror("file://"+filepath.Join(s.dir, "nope"), "master", s.mirror.Dir).Sync())
+}
+
+func (s *GitMirrorSuite) TestSyncRejectsOptions() {
+	marker := filepath.Join(s.dir, "ran")
+	s.Error(NewGitMirror("--upload-pack=touch "+marker, "master", s.mirror.Dir).Sync())
+	s.Error(NewGitMirror(s.mirror.URL, "--upload-pack=touch "+marker, s.mirror.Dir).Sync())
+	_, err := os.Stat(marker)
+	s.True(os.IsNotExist(err))
+}
+
+func (s *GitMirrorSuite) TestLogReturnsNewestFirst() {
+	first := s.commit("first", map[string]string{"a.txt": "a"})
+	second := s.commit("second\n\nwith a body", map[string]string{"b.txt": "b"})
+	s.Require().NoError(s.mirror.Sync())
+
+	head, err := s.mirror.Head()
+	s.NoError(err)
+	s.Equal(second, head)
+
+	commits, err := s.mirror.Log("", 0)
+	s.Require().NoError(err)
+	s.Require().Len(commits, 2)
+	s.Equal(second, commits[0].SHA)
+	s.Equal("second\n\nwith a body", commits[0].Message)
+	s.Equal("Alice", commits[0].AuthorName)
+	s.Equal("alice@example.com", commits[0].AuthorEmail)
+	s.False(commits[0].CommitTime.IsZero())
+	s.Equal(first, commits[1].SHA)
+
+	commits, err = s.mirror.Log("", 1)
+	s.NoError(err)
+	s.Len(commits, 1)
+
+	commits, err = s.mirror.Log(first, 0)
+	s.NoError(err)
+	s.Require().Len(commits, 1)
+	s.Equal(second, commits[0].SHA)
+}
+
+func (s *GitMirrorSuite) TestSyncFetchesNewCommits() {
+	first := s.commit("first", map[string]string{"a.txt": "a"})
+	s.Require().NoError(s.mirror.Sync())
+	s.True(s.mirror.HasCommit(first))
+
+	second := s.commit("second", map[string]string{"a.txt": "b"})
+	s.False(s.mirror.HasCommit(second))
+	s.Require().NoError(s.mirror.Sync())
+	s.True(s.mirror.HasCommit(second))
+	s.True(s.mirror.OnBranch(first))
+}
+
+func (s *GitMirrorSuite) TestRewrittenHistory() {
+	base := s.commit("base", map[string]string{"a.txt": "a"})
+	dropped := s.commit("dropped", map[string]string{"a.txt": "b"})
+	s.Require().NoError(s.mirror.Sync())
+
+	s.run("reset", "--quiet", "--hard", base)
+	s.commit("replacement", map[string]string{"a.txt": "c"})
+	s.Require().NoError(s.mirror.Sync())
+
+	s.True(s.mirror.HasCommit(dropped))
+	s.False(s.mirror.OnBranch(dropped))
+	mergeBase, err := s.mirror.MergeBase(dropped)
+	s.NoError(err)
+	s.Equal(base, mergeBase)
+
+	s.False(s.mirror.HasCommit("0000000000000000000000000000000000000000"))
+}
+
+func (s *GitMirrorSuite) TestChangedFiles() {
+	first := s.commit("first", map[string]string{"a.txt": "a", "dir/b.txt": "b"})
+	second := s.commit("second", map[string]string{"dir/b.txt": "c", "with space.txt": "d"})
+	s.Require().NoError(s.mirror.Sync())
+
+	files, err := s.mirror.ChangedFiles(first)
+	s.NoError(err)
+	s.Equal([]string{"a.txt", "dir/b.txt"}, files)
+
+	files, err = s.mirror.ChangedFiles(second)
+	s.NoError(err)
+	s.Equal([]string{"dir/b.txt", "with space.txt"}, files)
+}
+
+func (s *GitMirrorSuite) TestReadFile() {
+	first := s.commit("first", map[string]string{"evergreen.yml": "one"})
+	second := s.commit("second", map[string]string{"evergreen.yml": "two"})
+	s.Require().NoError(s.mirror.Sync())
+
+	data, err := s.mirror.ReadFile(first, "evergreen.yml")
+	s.NoError(err)
+	s.Equal("one", string(data))
+	data, err = s.mirror.ReadFile(second, "/evergreen.yml")
+	s.NoError(err)
+	s.Equal("two", string(data))
+
+	_, err = s.mirror.ReadFile(second, "missing.yml")
+	s.True(IsFileNotFound(err))
+}
+
+func (s *GitMirrorSuite) TestReadGitRemoteFile() {
+	s.commit("first", map[string]string{"etc/a.yml": "one"})
+	s.run("tag", "v1")
+	s.commit("second", map[string]string{"etc/a.yml": "two"})
+
+	data, err := ReadGitRemoteFile("file://"+s.remote, "master", "etc/a.yml")
+	s.NoError(err)
+	s.Equal("two", string(data))
+
+	data, err = ReadGitRemoteFile("file://"+s.remote, "v1", "etc/a.yml")
+	s.NoError(err)
+	s.Equal("one", string(data))
+
+	_, err = ReadGitRemoteFile("file://"+s.remote, "master", "etc/b.yml")
+	s.True(IsFileNotFound(err))
+	_, err = ReadGitRemoteFile("file://"+s.remote, "nope", "etc/a.yml")
+	s.Error(err)
+}
+
+func (s *GitMirrorSuite) TestReadGitRemoteFileRejectsOptions() {
+	s.commit("first", map[string]string{"etc/a.yml": "one"})
+	marker := filepath.Join(s.dir, "ran")
+
+	_, err := ReadGitRemoteFile("--upload-pack=touch "+marker, "master", "etc/a.yml")
+	s.Error(err)
+	_, err = ReadGitRemoteFile("file://"+s.remote, "--upload-pack=touch "+marker, "etc/a.yml")
+	s.Error(err)
+	_, err = os.Stat(marker)
+	s.True(os.IsNotExist(err))
+}
+
+func TestValidateGitRemoteURL(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.NoError(ValidateGitRemoteURL("https://github.com/evergreen-ci/evergreen.git"))
+	assert.NoError(ValidateGitRemoteURL("ssh://git@example.com/repo.git"))
+	assert.NoError(ValidateGitRemoteURL("git@github.com:evergreen-ci/evergreen.git"))
+
+	for _, url := range []string{"", "--upload-pack=touch /tmp/x", "file:///var/repo.git", "/var/repo.git",
+		"ext::sh -c touch% /tmp/x", "http://example.com/repo.git", "git://example.com/repo.git",
+		"ssh://-oProxyCommand=x/repo", "git@-oProxyCommand=x:repo", "https://"} {
+		assert.Error(ValidateGitRemoteURL(url), url)
+	}
+}
+
+func (s *GitMirrorSuite) TestPushGitPatch() {
+	base := s.commit("first", map[string]string{"a.txt": "a\n"})
+	s.commit("second", map[string]string{"b.txt": "b\n"})
+	s.run("checkout", "--quiet", "HEAD~1")
+	s.Require().NoError(ioutil.WriteFile(filepath.Join(s.remote, "a.txt"), []byte("a\nb\n"), 0644))
+	diff := s.run("diff") + "\n"
+	s.run("checkout", "--quiet", "--", "a.txt")
+	s.run("checkout", "--quiet", "--detach", "master")
+
+	commit := GitPatchCommit{
+		Base:        base,
+		Patch:       diff,
+		Message:     "patched",
+		AuthorName:  "Bob",
+		AuthorEmail: "bob@example.com",
+	}
+
+	// the branch moved on from the patch's base
+	_, err := PushGitPatch("file://"+s.remote, "master", "", commit)
+	s.Error(err)
+
+	commit.Base = s.run("rev-parse", "master")
+	sha, err := PushGitPatch("file://"+s.remote, "master", "", commit)
+	s.Require().NoError(err)
+	s.Equal(sha, s.run("rev-parse", "master"))
+	s.Equal("a\nb\n", s.run("show", "master:a.txt")+"\n")
+	s.Equal("Bob <bob@example.com>", s.run("log", "-1", "--format=%an <%ae>", "master"))
+	s.Equal("patched", s.run("log", "-1", "--format=%s", "master"))
+	s.Equal(commit.Base, s.run("rev-parse", "master~1"))
+
+	// patches that don't apply aren't pushed
+	commit.Base = sha
+	_, err = PushGitPatch("file://"+s.remote, "master", "", commit)
+	s.Error(err)
+	s.Equal(sha, s.run("rev-parse", "master"))
+}
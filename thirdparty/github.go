@@ -2,6 +2,7 @@ package thirdparty
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -146,6 +147,40 @@ func GetGithubFile(oauthToken, fileURL string) (githubFile *GithubFile, err erro
 	return
 }
 
+// GetGithubFileContents returns the decoded contents of the file at the
+// given path and revision of a repository.
+func GetGithubFileContents(oauthToken, owner, repo, path, revision string) ([]byte, error) {
+	githubFile, err := GetGithubFile(oauthToken, GetGithubFileURL(owner, repo, path, revision))
+	if err != nil {
+		return nil, err
+	}
+	contents, err := base64.StdEncoding.DecodeString(githubFile.Content)
+	if err != nil {
+		return nil, FileDecodeError{err.Error()}
+	}
+	return contents, nil
+}
+
+// ParseGithubRepoURL returns the owner and name of the GitHub repository
+// that a git remote URL, such as git@github.com:owner/repo.git or
+// https://github.com/owner/repo, refers to. It returns false if the URL
+// isn't a GitHub repository.
+func ParseGithubRepoURL(url string) (string, string, bool) {
+	var path string
+	for _, prefix := range []string{"git@github.com:", "https://github.com/", "http://github.com/",
+		"ssh://git@github.com/", "git://github.com/"} {
+		if strings.HasPrefix(url, prefix) {
+			path = strings.TrimPrefix(url, prefix)
+			break
+		}
+	}
+	parts := strings.Split(strings.TrimSuffix(strings.TrimSuffix(path, "/"), ".git"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
 func GetGitHubMergeBaseRevision(oauthToken, repoOwner, repo, baseRevision string, currentCommit *GithubCommit) (string, error) {
 	if currentCommit == nil {
 		return "", errors.New("no recent commit found")
@@ -136,6 +136,12 @@ func (u *GithubLoginUser) IsNil() bool {
 	return u == nil
 }
 
+// GetGithubLogin returns the user's login, which GitHub verified when
+// they logged in.
+func (u *GithubLoginUser) GetGithubLogin() string {
+	return u.Login
+}
+
 type GithubAuthParameters struct {
 	ClientId     string `json:"client_id"`
 	ClientSecret string `json:"client_secret"`
@@ -6,6 +6,7 @@ import (
 
 	"github.com/evergreen-ci/evergreen/testutil"
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
 )
 
 var repoKind = "github"
@@ -55,3 +56,29 @@ func TestCheckGithubAPILimit(t *testing.T) {
 		So(err, ShouldBeNil)
 	})
 }
+
+func TestParseGithubRepoURL(t *testing.T) {
+	assert := assert.New(t)
+
+	for url, expected := range map[string][2]string{
+		"git@github.com:evergreen-ci/evergreen.git":  {"evergreen-ci", "evergreen"},
+		"https://github.com/evergreen-ci/evergreen":  {"evergreen-ci", "evergreen"},
+		"https://github.com/evergreen-ci/sample.git": {"evergreen-ci", "sample"},
+		"ssh://git@github.com/mongodb/grip/":         {"mongodb", "grip"},
+	} {
+		owner, repo, ok := ParseGithubRepoURL(url)
+		assert.True(ok, url)
+		assert.Equal(expected[0], owner, url)
+		assert.Equal(expected[1], repo, url)
+	}
+
+	for _, url := range []string{
+		"git@gitlab.com:evergreen-ci/evergreen.git",
+		"https://github.com/evergreen-ci",
+		"https://github.com/evergreen-ci/evergreen/tree/master",
+		"file:///tmp/repo",
+	} {
+		_, _, ok := ParseGithubRepoURL(url)
+		assert.False(ok, url)
+	}
+}
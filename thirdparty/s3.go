@@ -11,7 +11,6 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
-	"os"
 	"runtime"
 	"sort"
 	"strings"
@@ -77,11 +76,17 @@ func (e CopyObjectError) Error() string {
 }
 
 func S3CopyFile(awsAuth *aws.Auth, fromS3Bucket, fromS3Path, toS3Bucket, toS3Path, permissionACL string) error {
+	destinationPath := fmt.Sprintf("http://%v.s3.amazonaws.com/%v",
+		toS3Bucket, toS3Path)
+	return s3CopyObject(awsAuth, destinationPath, fromS3Bucket, fromS3Path, toS3Bucket, toS3Path, permissionACL)
+}
+
+// s3CopyObject has the S3 service at destinationPath, the URL of the
+// destination object, copy an object into it.
+func s3CopyObject(awsAuth *aws.Auth, destinationPath, fromS3Bucket, fromS3Path, toS3Bucket, toS3Path, permissionACL string) error {
 	client := util.GetHttpClient()
 	defer util.PutHttpClient(client)
 
-	destinationPath := fmt.Sprintf("http://%v.s3.amazonaws.com/%v",
-		toS3Bucket, toS3Path)
 	req, err := http.NewRequest("PUT", destinationPath, nil)
 	if err != nil {
 		return errors.Wrapf(err, "PUT request on %v failed", destinationPath)
@@ -141,49 +146,40 @@ func S3CopyFile(awsAuth *aws.Auth, fromS3Bucket, fromS3Path, toS3Bucket, toS3Pat
 // PutS3File writes the specified file to an s3 bucket using the given permissions and content type.
 // The details of where to put the file are included in the s3URL
 func PutS3File(pushAuth *aws.Auth, localFilePath, s3URL, contentType, permissionACL string) error {
-	urlParsed, err := url.Parse(s3URL)
+	bucket, key, err := s3URLBucket(pushAuth, s3URL)
 	if err != nil {
-		return err
+		return errors.WithStack(err)
 	}
 
-	if urlParsed.Scheme != "s3" {
-		return errors.Errorf("Don't know how to use URL with scheme %v", urlParsed.Scheme)
-	}
-
-	fi, err := os.Stat(localFilePath)
-	if err != nil {
-		return err
-	}
+	return PutFile(bucket, localFilePath, key, PutOptions{
+		ContentType: contentType,
+		Permissions: permissionACL,
+	})
+}
 
-	localFileReader, err := os.Open(localFilePath)
+// GetS3File returns the contents of the file at the s3URL. The caller
+// must close it.
+func GetS3File(auth *aws.Auth, s3URL string) (io.ReadCloser, error) {
+	bucket, key, err := s3URLBucket(auth, s3URL)
 	if err != nil {
-		return err
+		return nil, errors.WithStack(err)
 	}
-	defer localFileReader.Close()
-
-	client := util.GetHttpClient()
-	defer util.PutHttpClient(client)
 
-	session := NewS3Session(pushAuth, aws.USEast, client)
-	bucket := session.Bucket(urlParsed.Host)
-	// options for the header
-	options := s3.Options{}
-	return errors.Wrapf(bucket.PutReader(urlParsed.Path, localFileReader, fi.Size(), contentType, s3.ACL(permissionACL), options),
-		"problem putting %s to bucket", localFilePath)
+	return bucket.Get(key)
 }
 
-func GetS3File(auth *aws.Auth, s3URL string) (io.ReadCloser, error) {
+// s3URLBucket returns the bucket and key of an s3://<bucket>/<key> URL.
+func s3URLBucket(auth *aws.Auth, s3URL string) (Bucket, string, error) {
 	urlParsed, err := url.Parse(s3URL)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	client := util.GetHttpClient()
-	defer util.PutHttpClient(client)
 
-	session := NewS3Session(auth, aws.USEast, client)
+	if urlParsed.Scheme != "s3" {
+		return nil, "", errors.Errorf("Don't know how to use URL with scheme %v", urlParsed.Scheme)
+	}
 
-	bucket := session.Bucket(urlParsed.Host)
-	return bucket.GetReader(urlParsed.Path)
+	return NewS3Bucket(auth, urlParsed.Host), urlParsed.Path, nil
 }
 
 //Taken from https://github.com/mitchellh/goamz/blob/master/s3/sign.go
@@ -0,0 +1,145 @@
+package thirdparty
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/evergreen-ci/evergreen/model/webhook"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/mongodb/grip"
+	"github.com/mongodb/grip/message"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	// WebhookSignatureHeader holds the hex encoded HMAC-SHA256 of the
+	// request body, keyed with the webhook's secret, prefixed with
+	// "sha256=".
+	WebhookSignatureHeader = "X-Evergreen-Signature"
+	// WebhookDeliveryHeader holds the id of the delivery, which is the
+	// same across retries so that receivers can discard duplicates.
+	WebhookDeliveryHeader = "X-Evergreen-Delivery"
+	// WebhookTriggerHeader holds the trigger that caused the delivery.
+	WebhookTriggerHeader = "X-Evergreen-Trigger"
+
+	webhookRetries    = 3
+	webhookRetrySleep = time.Second
+	webhookTimeout    = 10 * time.Second
+)
+
+// WebhookSender posts signed JSON payloads to a URL, retrying failed
+// deliveries with backoff, and records each delivery in the delivery
+// log.
+type WebhookSender struct {
+	URL    string
+	Secret string
+
+	// Retries and RetrySleep configure the backoff between attempts.
+	Retries    int
+	RetrySleep time.Duration
+
+	client *http.Client
+	log    func(*webhook.Delivery) error
+}
+
+// NewWebhookSender returns a sender for the URL that signs payloads
+// with the secret.
+func NewWebhookSender(url, secret string) *WebhookSender {
+	return &WebhookSender{
+		URL:        url,
+		Secret:     secret,
+		Retries:    webhookRetries,
+		RetrySleep: webhookRetrySleep,
+		client:     &http.Client{Timeout: webhookTimeout},
+		log:        (*webhook.Delivery).Insert,
+	}
+}
+
+// SignWebhookPayload returns the value of the signature header for a
+// request body.
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature checks the signature header of a request
+// body, for receivers of webhooks.
+func VerifyWebhookSignature(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(SignWebhookPayload(secret, body)), []byte(signature))
+}
+
+// Send posts the payload, retrying on connection errors, server errors
+// and rate limiting. Client errors other than rate limiting are not
+// retried, since the receiver will reject the payload again.
+func (s *WebhookSender) Send(payload *webhook.Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap(err, "problem marshalling webhook payload")
+	}
+
+	delivery := &webhook.Delivery{
+		Id:        bson.NewObjectId(),
+		URL:       s.URL,
+		Source:    payload.Source,
+		Trigger:   payload.Trigger,
+		CreatedAt: time.Now(),
+	}
+
+	_, err = util.Retry(func() (bool, error) {
+		delivery.Attempts++
+		return s.post(delivery, body)
+	}, s.Retries, s.RetrySleep)
+
+	delivery.Duration = time.Since(delivery.CreatedAt)
+	delivery.Delivered = err == nil
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+
+	grip.Warning(message.WrapError(s.log(delivery), message.Fields{
+		"message":  "problem recording webhook delivery",
+		"url":      s.URL,
+		"delivery": delivery.Id.Hex(),
+	}))
+
+	return errors.Wrapf(err, "problem delivering webhook to %s", s.URL)
+}
+
+func (s *WebhookSender) post(delivery *webhook.Delivery, body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookDeliveryHeader, delivery.Id.Hex())
+	req.Header.Set(WebhookTriggerHeader, delivery.Trigger)
+	if s.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(s.Secret, body))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	delivery.StatusCode = resp.StatusCode
+	switch {
+	case resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, errors.Errorf("webhook responded with status %d", resp.StatusCode)
+	default:
+		return false, errors.Errorf("webhook rejected delivery with status %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,116 @@
+package thirdparty
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/evergreen-ci/evergreen/model/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+type webhookReceiver struct {
+	mu        sync.Mutex
+	statuses  []int
+	bodies    [][]byte
+	headers   []http.Header
+	callCount int
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	body, _ := ioutil.ReadAll(req.Body)
+	r.bodies = append(r.bodies, body)
+	r.headers = append(r.headers, req.Header)
+
+	status := http.StatusOK
+	if r.callCount < len(r.statuses) {
+		status = r.statuses[r.callCount]
+	}
+	r.callCount++
+	w.WriteHeader(status)
+}
+
+func newTestWebhookSender(url string) (*WebhookSender, *[]webhook.Delivery) {
+	deliveries := &[]webhook.Delivery{}
+	sender := NewWebhookSender(url, "shh")
+	sender.Retries = 2
+	sender.RetrySleep = 0
+	sender.log = func(d *webhook.Delivery) error {
+		*deliveries = append(*deliveries, *d)
+		return nil
+	}
+	return sender, deliveries
+}
+
+func TestWebhookSenderSignsPayload(t *testing.T) {
+	assert := assert.New(t)
+	receiver := &webhookReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	sender, deliveries := newTestWebhookSender(server.URL)
+	assert.NoError(sender.Send(webhook.NewPayload(webhook.SourceAlerts, "task_failed")))
+
+	assert.Equal(1, receiver.callCount)
+	header := receiver.headers[0]
+	assert.Equal("application/json", header.Get("Content-Type"))
+	assert.Equal("task_failed", header.Get(WebhookTriggerHeader))
+	assert.True(VerifyWebhookSignature("shh", receiver.bodies[0], header.Get(WebhookSignatureHeader)))
+	assert.False(VerifyWebhookSignature("wrong", receiver.bodies[0], header.Get(WebhookSignatureHeader)))
+	assert.Contains(string(receiver.bodies[0]), `"schema_version":1`)
+
+	assert.Len(*deliveries, 1)
+	d := (*deliveries)[0]
+	assert.True(d.Delivered)
+	assert.Equal(1, d.Attempts)
+	assert.Equal(http.StatusOK, d.StatusCode)
+	assert.Equal(header.Get(WebhookDeliveryHeader), d.Id.Hex())
+	assert.Equal(server.URL, d.URL)
+}
+
+func TestWebhookSenderRetries(t *testing.T) {
+	assert := assert.New(t)
+	receiver := &webhookReceiver{statuses: []int{http.StatusBadGateway, http.StatusTooManyRequests}}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	sender, deliveries := newTestWebhookSender(server.URL)
+	assert.NoError(sender.Send(webhook.NewPayload(webhook.SourceNotify, "task_failure")))
+
+	// retries of a delivery carry the same delivery id
+	assert.Equal(3, receiver.callCount)
+	assert.Equal(receiver.headers[0].Get(WebhookDeliveryHeader), receiver.headers[2].Get(WebhookDeliveryHeader))
+	assert.Len(*deliveries, 1)
+	assert.True((*deliveries)[0].Delivered)
+	assert.Equal(3, (*deliveries)[0].Attempts)
+}
+
+func TestWebhookSenderFailures(t *testing.T) {
+	assert := assert.New(t)
+
+	// client errors are not retried
+	receiver := &webhookReceiver{statuses: []int{http.StatusBadRequest}}
+	server := httptest.NewServer(receiver)
+	sender, deliveries := newTestWebhookSender(server.URL)
+	assert.Error(sender.Send(webhook.NewPayload(webhook.SourceAlerts, "task_failed")))
+	assert.Equal(1, receiver.callCount)
+	assert.False((*deliveries)[0].Delivered)
+	assert.Equal(http.StatusBadRequest, (*deliveries)[0].StatusCode)
+	assert.NotEmpty((*deliveries)[0].Error)
+	server.Close()
+
+	// server errors are retried until the attempts run out
+	receiver = &webhookReceiver{statuses: []int{500, 500, 500, 500}}
+	server = httptest.NewServer(receiver)
+	defer server.Close()
+	sender, deliveries = newTestWebhookSender(server.URL)
+	assert.Error(sender.Send(webhook.NewPayload(webhook.SourceAlerts, "task_failed")))
+	assert.Equal(3, receiver.callCount)
+	assert.Equal(3, (*deliveries)[0].Attempts)
+	assert.False((*deliveries)[0].Delivered)
+}
@@ -0,0 +1,612 @@
+package units
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/auth"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/commitqueue"
+	"github.com/evergreen-ci/evergreen/model/patch"
+	"github.com/evergreen-ci/evergreen/model/role"
+	"github.com/evergreen-ci/evergreen/model/user"
+	"github.com/evergreen-ci/evergreen/thirdparty"
+	"github.com/evergreen-ci/evergreen/util"
+	"github.com/google/go-github/github"
+	"github.com/mongodb/amboy"
+	"github.com/mongodb/amboy/job"
+	"github.com/mongodb/amboy/registry"
+	"github.com/mongodb/grip"
+	"github.com/mongodb/grip/message"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	commitQueueJobName = "commit-queue"
+
+	// commitQueuePatchTimeout is how long the patch testing an item may
+	// go without being created before the item is considered failed.
+	commitQueuePatchTimeout = 15 * time.Minute
+)
+
+func init() {
+	registry.AddJobType(commitQueueJobName, func() amboy.Job { return makeCommitQueueJob() })
+}
+
+type commitQueueJob struct {
+	job.Base `bson:"job_base" json:"job_base" yaml:"job_base"`
+	env      evergreen.Environment
+
+	QueueID string `bson:"queue_id" json:"queue_id" yaml:"queue_id"`
+}
+
+func makeCommitQueueJob() *commitQueueJob {
+	return &commitQueueJob{
+		env: evergreen.GetEnvironment(),
+		Base: job.Base{
+			JobType: amboy.JobType{
+				Name:    commitQueueJobName,
+				Version: 0,
+				Format:  amboy.BSON,
+			},
+		},
+	}
+}
+
+// NewCommitQueueJob creates a job to advance the commit queue of the given
+// project. The item at the head of the queue is tested with a patch based
+// on the head of the project's branch, using the project's commit queue
+// alias. Once the patch finishes, pull requests that passed are merged if
+// they're still approved, patches that passed are pushed to the branch, and
+// items that failed are dequeued.
+func NewCommitQueueJob(queueID, id string) amboy.Job {
+	j := makeCommitQueueJob()
+	j.QueueID = queueID
+	j.SetID(fmt.Sprintf("%s:%s_%s", commitQueueJobName, queueID, id))
+	return j
+}
+
+func (j *commitQueueJob) Run() {
+	defer j.MarkComplete()
+
+	projectRef, err := model.FindOneProjectRef(j.QueueID)
+	if err != nil {
+		j.AddError(errors.Wrapf(err, "problem finding project '%s'", j.QueueID))
+		return
+	}
+	if projectRef == nil {
+		j.AddError(errors.Errorf("project '%s' does not exist", j.QueueID))
+		return
+	}
+	if !projectRef.Enabled || !projectRef.CommitQueue.Enabled {
+		return
+	}
+
+	cq, err := commitqueue.FindOneId(j.QueueID)
+	if err != nil {
+		j.AddError(err)
+		return
+	}
+	if cq == nil {
+		return
+	}
+	item, ok := cq.Next()
+	if !ok {
+		return
+	}
+
+	githubOauthToken, err := j.env.Settings().GetGithubOauthToken()
+	if err != nil {
+		j.AddError(err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if item.Version == "" {
+		j.AddError(j.startItem(ctx, cq, item, projectRef, githubOauthToken))
+		return
+	}
+	j.AddError(j.finishItem(ctx, cq, item, projectRef, githubOauthToken))
+}
+
+// startItem creates and finalizes the patch testing the item against the
+// current head of the project's branch.
+func (j *commitQueueJob) startItem(ctx context.Context, cq *commitqueue.CommitQueue, item commitqueue.CommitQueueItem,
+	projectRef *model.ProjectRef, githubOauthToken string) error {
+	aliases, err := model.FindProjectAliases(projectRef.Identifier, patch.CommitQueueAlias)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if len(aliases) == 0 {
+		return errors.Errorf("project '%s' has no '%s' alias to test its commit queue with",
+			projectRef.Identifier, patch.CommitQueueAlias)
+	}
+
+	branch, err := thirdparty.GetBranchEvent(githubOauthToken, projectRef.Owner, projectRef.Repo, projectRef.Branch)
+	if err != nil {
+		return errors.Wrapf(err, "problem getting the head of branch '%s'", projectRef.Branch)
+	}
+
+	var patchContent, description, revision string
+	switch item.Type {
+	case commitqueue.PRItemType:
+		var pr *github.PullRequest
+		pr, err = getCommitQueuePullRequest(ctx, projectRef, item, githubOauthToken)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if pr == nil {
+			return j.dequeue(cq, item, projectRef, "", githubStatusError, "pull request does not exist")
+		}
+		revision = pr.GetHead().GetSHA()
+		if pr.GetState() != "open" {
+			return j.dequeue(cq, item, projectRef, revision, githubStatusError, "pull request is not open")
+		}
+		if pr.GetBase().GetRef() != projectRef.Branch {
+			return j.dequeue(cq, item, projectRef, revision, githubStatusError,
+				fmt.Sprintf("pull request is not based on branch '%s'", projectRef.Branch))
+		}
+		var problem string
+		problem, err = checkCommitQueuePullRequest(ctx, projectRef, pr, item, githubOauthToken)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if problem != "" {
+			return j.dequeue(cq, item, projectRef, revision, githubStatusError, problem)
+		}
+
+		patchContent, err = fetchDiffByURL(pr.GetDiffURL())
+		if err != nil {
+			return errors.Wrapf(err, "problem fetching the diff of pull request #%s", item.Issue)
+		}
+		description = fmt.Sprintf("Commit queue merge of %s/%s#%s: %s",
+			projectRef.Owner, projectRef.Repo, item.Issue, pr.GetTitle())
+
+	case commitqueue.PatchItemType:
+		var patchDoc *patch.Patch
+		patchDoc, patchContent, err = getCommitQueuePatch(item, projectRef)
+		if err != nil {
+			return j.dequeue(cq, item, projectRef, "", githubStatusError, err.Error())
+		}
+		var problem string
+		problem, err = checkCommitQueuePatch(j.env.Settings(), projectRef, patchDoc)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if problem != "" {
+			return j.dequeue(cq, item, projectRef, "", githubStatusError, problem)
+		}
+		description = fmt.Sprintf("Commit queue merge of patch %s: %s", item.Issue, patchDoc.Description)
+
+	default:
+		return j.dequeue(cq, item, projectRef, "", githubStatusError,
+			fmt.Sprintf("unknown item type '%s'", item.Type))
+	}
+
+	intent, err := patch.NewCliIntent(item.Author, projectRef.Identifier, branch.Commit.SHA, "",
+		patchContent, description, true, nil, nil, patch.CommitQueueAlias)
+	if err != nil {
+		return j.dequeue(cq, item, projectRef, revision, githubStatusError, err.Error())
+	}
+
+	patchID := bson.NewObjectId()
+	started, err := cq.StartHead(item.Issue, patchID.Hex(), revision)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if !started {
+		// another job started testing the item first
+		return nil
+	}
+
+	if err = intent.Insert(); err != nil {
+		return errors.Wrap(err, "problem saving patch intent")
+	}
+	processor := NewPatchIntentProcessor(patchID, intent)
+	processor.Run()
+	if err = processor.Error(); err != nil {
+		return j.dequeue(cq, item, projectRef, revision, githubStatusError,
+			fmt.Sprintf("patch could not be created: %s", err.Error()))
+	}
+
+	grip.Info(message.Fields{
+		"message":  "started testing commit queue item",
+		"job":      j.ID(),
+		"project":  projectRef.Identifier,
+		"item":     item.Issue,
+		"type":     item.Type,
+		"patch_id": patchID.Hex(),
+		"base":     branch.Commit.SHA,
+	})
+	return nil
+}
+
+// finishItem merges or dequeues the item at the head of the queue once the
+// patch testing it is finished.
+func (j *commitQueueJob) finishItem(ctx context.Context, cq *commitqueue.CommitQueue, item commitqueue.CommitQueueItem,
+	projectRef *model.ProjectRef, githubOauthToken string) error {
+	if !bson.IsObjectIdHex(item.Version) {
+		return j.dequeue(cq, item, projectRef, item.Revision, githubStatusError,
+			fmt.Sprintf("invalid patch id '%s'", item.Version))
+	}
+	patchID := bson.ObjectIdHex(item.Version)
+	patchDoc, err := patch.FindOne(patch.ById(patchID))
+	if err != nil {
+		return errors.Wrapf(err, "problem finding patch '%s'", item.Version)
+	}
+	if patchDoc == nil {
+		// the patch may still be being created by another job
+		if time.Since(patchID.Time()) < commitQueuePatchTimeout {
+			return nil
+		}
+		return j.dequeue(cq, item, projectRef, item.Revision, githubStatusError, "patch was never created")
+	}
+
+	switch patchDoc.Status {
+	case evergreen.PatchFailed:
+		return j.dequeue(cq, item, projectRef, item.Revision, githubStatusFailure, "tests failed")
+	case evergreen.PatchSucceeded:
+	default:
+		return nil
+	}
+
+	// only merge what was tested: if the branch has moved on, test the
+	// pull request again on top of it
+	branch, err := thirdparty.GetBranchEvent(githubOauthToken, projectRef.Owner, projectRef.Repo, projectRef.Branch)
+	if err != nil {
+		return errors.Wrapf(err, "problem getting the head of branch '%s'", projectRef.Branch)
+	}
+	if branch.Commit.SHA != patchDoc.Githash {
+		grip.Info(message.Fields{
+			"message":  "branch changed while testing commit queue item, retesting",
+			"job":      j.ID(),
+			"project":  projectRef.Identifier,
+			"item":     item.Issue,
+			"patch_id": item.Version,
+			"tested":   patchDoc.Githash,
+			"head":     branch.Commit.SHA,
+		})
+		return errors.WithStack(cq.ResetHead(item.Issue))
+	}
+
+	if item.Type != commitqueue.PRItemType {
+		return j.pushItem(cq, item, projectRef, patchDoc, githubOauthToken)
+	}
+
+	// the pull request may have changed since it was tested
+	pr, err := getCommitQueuePullRequest(ctx, projectRef, item, githubOauthToken)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if pr == nil {
+		return j.dequeue(cq, item, projectRef, "", githubStatusError, "pull request does not exist")
+	}
+	if pr.GetState() != "open" {
+		return j.dequeue(cq, item, projectRef, item.Revision, githubStatusError, "pull request is not open")
+	}
+	problem, err := checkCommitQueuePullRequest(ctx, projectRef, pr, item, githubOauthToken)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if problem != "" {
+		return j.dequeue(cq, item, projectRef, item.Revision, githubStatusError, problem)
+	}
+
+	if err = mergeCommitQueuePullRequest(ctx, projectRef, item, githubOauthToken); err != nil {
+		if errResp, ok := errors.Cause(err).(*github.ErrorResponse); ok && errResp.Response != nil {
+			switch errResp.Response.StatusCode {
+			case http.StatusMethodNotAllowed, http.StatusConflict:
+				return j.dequeue(cq, item, projectRef, item.Revision, githubStatusError,
+					fmt.Sprintf("pull request could not be merged: %s", errResp.Message))
+			}
+		}
+		return errors.WithStack(err)
+	}
+
+	return j.dequeue(cq, item, projectRef, item.Revision, githubStatusSuccess, "merged")
+}
+
+// pushItem commits the patch item's changes, as tested by the patch, to the
+// project's branch as its author.
+func (j *commitQueueJob) pushItem(cq *commitqueue.CommitQueue, item commitqueue.CommitQueueItem,
+	projectRef *model.ProjectRef, tested *patch.Patch, githubOauthToken string) error {
+	patchDoc, patchContent, err := getCommitQueuePatch(item, projectRef)
+	if err != nil {
+		return j.dequeue(cq, item, projectRef, "", githubStatusError, err.Error())
+	}
+	// the author's permissions may have changed since the patch was tested
+	problem, err := checkCommitQueuePatch(j.env.Settings(), projectRef, patchDoc)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if problem != "" {
+		return j.dequeue(cq, item, projectRef, "", githubStatusError, problem)
+	}
+	author, err := user.FindOne(user.ById(patchDoc.Author))
+	if err != nil {
+		return errors.Wrapf(err, "problem finding user '%s'", patchDoc.Author)
+	}
+	if author == nil {
+		return j.dequeue(cq, item, projectRef, "", githubStatusError,
+			fmt.Sprintf("author '%s' of the patch does not exist", patchDoc.Author))
+	}
+
+	commitMessage := patchDoc.Description
+	if commitMessage == "" {
+		commitMessage = fmt.Sprintf("Commit queue merge of patch %s", item.Issue)
+	}
+	token := strings.TrimPrefix(githubOauthToken, "token ")
+	authHeader := "basic " + base64.StdEncoding.EncodeToString([]byte(token+":x-oauth-basic"))
+	url := fmt.Sprintf("https://github.com/%s/%s.git", projectRef.Owner, projectRef.Repo)
+	revision, err := thirdparty.PushGitPatch(url, projectRef.Branch, authHeader, thirdparty.GitPatchCommit{
+		Base:        tested.Githash,
+		Patch:       patchContent,
+		Message:     commitMessage,
+		AuthorName:  author.DisplayName(),
+		AuthorEmail: author.Email(),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "problem pushing patch '%s'", item.Issue)
+	}
+
+	grip.Info(message.Fields{
+		"message":  "pushed commit queue patch",
+		"job":      j.ID(),
+		"project":  projectRef.Identifier,
+		"item":     item.Issue,
+		"patch_id": item.Version,
+		"revision": revision,
+	})
+	return j.dequeue(cq, item, projectRef, "", githubStatusSuccess, "merged")
+}
+
+// dequeue removes the item from the queue and reports why through the
+// pull request's status.
+func (j *commitQueueJob) dequeue(cq *commitqueue.CommitQueue, item commitqueue.CommitQueueItem,
+	projectRef *model.ProjectRef, ref, state, description string) error {
+	if _, err := cq.Remove(item.Issue); err != nil {
+		return errors.WithStack(err)
+	}
+
+	grip.Info(message.Fields{
+		"message":  "removed item from commit queue",
+		"job":      j.ID(),
+		"project":  projectRef.Identifier,
+		"item":     item.Issue,
+		"type":     item.Type,
+		"patch_id": item.Version,
+		"state":    state,
+		"reason":   description,
+	})
+
+	if item.Type != commitqueue.PRItemType || ref == "" {
+		return nil
+	}
+	prNumber, err := strconv.Atoi(item.Issue)
+	if err != nil {
+		return errors.Wrapf(err, "invalid pull request number '%s'", item.Issue)
+	}
+
+	urlPath := fmt.Sprintf("/waterfall/%s", projectRef.Identifier)
+	if item.Version != "" {
+		urlPath = fmt.Sprintf("/version/%s", item.Version)
+	}
+	update := NewGithubStatusUpdateJobForCommitQueue(projectRef.Owner, projectRef.Repo, prNumber,
+		ref, state, description, urlPath)
+	return errors.Wrap(j.env.LocalQueue().Put(update), "problem queueing status update")
+}
+
+// getCommitQueuePullRequest returns the pull request of the item, or nil
+// if it does not exist.
+func getCommitQueuePullRequest(ctx context.Context, projectRef *model.ProjectRef, item commitqueue.CommitQueueItem,
+	githubOauthToken string) (*github.PullRequest, error) {
+	prNumber, err := strconv.Atoi(item.Issue)
+	if err != nil {
+		return nil, nil
+	}
+
+	httpClient, err := util.GetHttpClientForOauth2(githubOauthToken)
+	if err != nil {
+		return nil, err
+	}
+	defer util.PutHttpClientForOauth2(httpClient)
+	client := github.NewClient(httpClient)
+
+	pr, resp, err := client.PullRequests.Get(ctx, projectRef.Owner, projectRef.Repo, prNumber)
+	if resp != nil {
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "problem getting pull request #%d", prNumber)
+	}
+	return pr, nil
+}
+
+// checkCommitQueuePullRequest returns why the pull request can't be merged
+// for the item, or an empty string if it can. The pull request must have
+// been opened by the verified GitHub login of the user who added it to the
+// queue, and approved by someone who can write to the repository. The
+// latest review of everyone who can write to it counts, so none of them
+// may have requested changes.
+func checkCommitQueuePullRequest(ctx context.Context, projectRef *model.ProjectRef, pr *github.PullRequest,
+	item commitqueue.CommitQueueItem, githubOauthToken string) (string, error) {
+	enqueuer, err := user.FindOne(user.ById(item.Author))
+	if err != nil {
+		return "", errors.Wrapf(err, "problem finding user '%s'", item.Author)
+	}
+	if enqueuer == nil || enqueuer.GithubLogin == "" {
+		return fmt.Sprintf("'%s' has no verified GitHub login", item.Author), nil
+	}
+	// GitHub logins are case insensitive
+	author := pr.GetUser().GetLogin()
+	if !strings.EqualFold(author, enqueuer.GithubLogin) {
+		return fmt.Sprintf("pull request was opened by '%s', not '%s'", author, enqueuer.GithubLogin), nil
+	}
+
+	httpClient, err := util.GetHttpClientForOauth2(githubOauthToken)
+	if err != nil {
+		return "", err
+	}
+	defer util.PutHttpClientForOauth2(httpClient)
+	client := github.NewClient(httpClient)
+
+	latest := map[string]string{}
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		reviews, resp, err := client.PullRequests.ListReviews(ctx, projectRef.Owner, projectRef.Repo, pr.GetNumber(), opts)
+		if resp != nil {
+			defer resp.Body.Close()
+		}
+		if err != nil {
+			return "", errors.Wrapf(err, "problem listing reviews of pull request #%d", pr.GetNumber())
+		}
+		for _, review := range reviews {
+			switch review.GetState() {
+			case "APPROVED", "CHANGES_REQUESTED", "DISMISSED":
+				latest[review.GetUser().GetLogin()] = review.GetState()
+			}
+		}
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	approved := false
+	for reviewer, state := range latest {
+		if reviewer == author || state == "DISMISSED" {
+			continue
+		}
+		permission, resp, err := client.Repositories.GetPermissionLevel(ctx, projectRef.Owner, projectRef.Repo, reviewer)
+		if resp != nil {
+			defer resp.Body.Close()
+		}
+		if err != nil {
+			return "", errors.Wrapf(err, "problem getting the permissions of '%s'", reviewer)
+		}
+		switch permission.GetPermission() {
+		case "admin", "write":
+		default:
+			continue
+		}
+		if state == "CHANGES_REQUESTED" {
+			return fmt.Sprintf("'%s' requested changes to the pull request", reviewer), nil
+		}
+		approved = true
+	}
+	if !approved {
+		return "pull request has not been approved", nil
+	}
+	return "", nil
+}
+
+// mergeCommitQueuePullRequest merges the item's pull request, as long as
+// its head is still the commit that was tested.
+func mergeCommitQueuePullRequest(ctx context.Context, projectRef *model.ProjectRef, item commitqueue.CommitQueueItem,
+	githubOauthToken string) error {
+	prNumber, err := strconv.Atoi(item.Issue)
+	if err != nil {
+		return errors.Wrapf(err, "invalid pull request number '%s'", item.Issue)
+	}
+
+	httpClient, err := util.GetHttpClientForOauth2(githubOauthToken)
+	if err != nil {
+		return err
+	}
+	defer util.PutHttpClientForOauth2(httpClient)
+	client := github.NewClient(httpClient)
+
+	options := &github.PullRequestOptions{
+		SHA:         item.Revision,
+		MergeMethod: projectRef.CommitQueue.MergeMethod,
+	}
+	result, resp, err := client.PullRequests.Merge(ctx, projectRef.Owner, projectRef.Repo, prNumber, "", options)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		return errors.Wrapf(err, "problem merging pull request #%d", prNumber)
+	}
+	if !result.GetMerged() {
+		return errors.Errorf("pull request #%d was not merged: %s", prNumber, result.GetMessage())
+	}
+	return nil
+}
+
+// checkCommitQueuePatch returns why the patch may not be pushed, or an empty
+// string if it may. Patches skip the review a pull request gets and are
+// pushed with Evergreen's credentials, so only users who can change the
+// project's settings may merge them.
+func checkCommitQueuePatch(settings *evergreen.Settings, projectRef *model.ProjectRef, patchDoc *patch.Patch) (string, error) {
+	author, err := user.FindOne(user.ById(patchDoc.Author))
+	if err != nil {
+		return "", errors.Wrapf(err, "problem finding user '%s'", patchDoc.Author)
+	}
+	if author == nil {
+		return fmt.Sprintf("author '%s' of the patch does not exist", patchDoc.Author), nil
+	}
+	authorizer := &auth.Authorizer{
+		SuperUsers: settings.SuperUsers,
+		Config:     settings.RBAC,
+		Store:      auth.DBRoleStore{},
+	}
+	allowed, err := authorizer.HasPermission(author, role.ProjectSettings, projectRef)
+	if err != nil {
+		return "", errors.Wrapf(err, "problem checking the permissions of '%s'", author.Id)
+	}
+	if !allowed {
+		return fmt.Sprintf("'%s' can't change the settings of project '%s', so can't merge patches through the commit queue",
+			author.Id, projectRef.Identifier), nil
+	}
+	return "", nil
+}
+
+// getCommitQueuePatch returns the patch that was enqueued and its diff.
+// The patch must be for the project and belong to the user who enqueued it.
+func getCommitQueuePatch(item commitqueue.CommitQueueItem, projectRef *model.ProjectRef) (*patch.Patch, string, error) {
+	if !bson.IsObjectIdHex(item.Issue) {
+		return nil, "", errors.Errorf("invalid patch id '%s'", item.Issue)
+	}
+	patchDoc, err := patch.FindOne(patch.ById(bson.ObjectIdHex(item.Issue)))
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "problem finding patch '%s'", item.Issue)
+	}
+	if patchDoc == nil {
+		return nil, "", errors.Errorf("patch '%s' does not exist", item.Issue)
+	}
+	if patchDoc.Project != projectRef.Identifier {
+		return nil, "", errors.Errorf("patch '%s' is not for project '%s'", item.Issue, projectRef.Identifier)
+	}
+	if patchDoc.Author != item.Author {
+		return nil, "", errors.Errorf("patch '%s' does not belong to '%s'", item.Issue, item.Author)
+	}
+	if len(patchDoc.Patches) != 1 || patchDoc.Patches[0].ModuleName != "" {
+		return nil, "", errors.New("patches that change modules can't be merged")
+	}
+
+	reader, err := db.GetGridFile(patch.GridFSPrefix, patchDoc.Patches[0].PatchSet.PatchFileId)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "problem reading patch file")
+	}
+	defer reader.Close()
+	bytes, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "problem reading patch file")
+	}
+
+	return patchDoc, string(bytes), nil
+}
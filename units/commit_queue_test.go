@@ -0,0 +1,227 @@
+package units
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/evergreen-ci/evergreen/mock"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/commitqueue"
+	"github.com/evergreen-ci/evergreen/model/patch"
+	"github.com/evergreen-ci/evergreen/model/user"
+	"github.com/evergreen-ci/evergreen/testutil"
+	"github.com/google/go-github/github"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type CommitQueueUnitsSuite struct {
+	env        *mock.Environment
+	ctx        context.Context
+	cancel     context.CancelFunc
+	projectRef *model.ProjectRef
+
+	suite.Suite
+}
+
+func TestCommitQueueUnitsSuite(t *testing.T) {
+	suite.Run(t, new(CommitQueueUnitsSuite))
+}
+
+func (s *CommitQueueUnitsSuite) SetupTest() {
+	s.env = &mock.Environment{}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.Require().NoError(s.env.Configure(s.ctx, filepath.Join(evergreen.FindEvergreenHome(), testutil.TestDir, testutil.TestSettings)))
+	s.Require().NoError(s.env.Local.Start(s.ctx))
+
+	s.Require().NoError(db.ClearCollections(model.ProjectRefCollection, patch.Collection, commitqueue.Collection, user.Collection))
+
+	s.projectRef = &model.ProjectRef{
+		Owner:       "evergreen-ci",
+		Repo:        "evergreen",
+		Identifier:  "mci",
+		Enabled:     true,
+		Branch:      "master",
+		RepoKind:    "github",
+		CommitQueue: model.CommitQueueParams{Enabled: true, MergeMethod: model.CommitQueueMergeMethodSquash},
+	}
+	s.Require().NoError(s.projectRef.Insert())
+}
+
+func (s *CommitQueueUnitsSuite) TearDownTest() {
+	s.cancel()
+}
+
+func (s *CommitQueueUnitsSuite) makeJob() *commitQueueJob {
+	j := NewCommitQueueJob("mci", "1").(*commitQueueJob)
+	j.env = s.env
+	return j
+}
+
+// enqueueTested adds an item that is being tested by a patch with the
+// given status.
+func (s *CommitQueueUnitsSuite) enqueueTested(item commitqueue.CommitQueueItem, status string) *commitqueue.CommitQueue {
+	patchDoc := &patch.Patch{
+		Id:      bson.NewObjectId(),
+		Project: "mci",
+		Githash: "abc",
+		Status:  status,
+	}
+	s.Require().NoError(patchDoc.Insert())
+
+	_, err := commitqueue.Enqueue("mci", item)
+	s.Require().NoError(err)
+	cq, err := commitqueue.FindOneId("mci")
+	s.Require().NoError(err)
+	started, err := cq.StartHead(item.Issue, patchDoc.Id.Hex(), item.Revision)
+	s.Require().NoError(err)
+	s.Require().True(started)
+	return cq
+}
+
+func (s *CommitQueueUnitsSuite) TestRunSkipsDisabledQueue() {
+	s.projectRef.CommitQueue.Enabled = false
+	s.Require().NoError(s.projectRef.Upsert())
+	_, err := commitqueue.Enqueue("mci", commitqueue.CommitQueueItem{Issue: "12", Type: commitqueue.PRItemType})
+	s.Require().NoError(err)
+
+	j := s.makeJob()
+	j.Run()
+	s.NoError(j.Error())
+
+	cq, err := commitqueue.FindOneId("mci")
+	s.Require().NoError(err)
+	s.Require().Len(cq.Queue, 1)
+	s.Empty(cq.Queue[0].Version)
+}
+
+func (s *CommitQueueUnitsSuite) TestRunWithEmptyQueue() {
+	j := s.makeJob()
+	j.Run()
+	s.NoError(j.Error())
+}
+
+func (s *CommitQueueUnitsSuite) TestFailedPatchIsDequeued() {
+	cq := s.enqueueTested(commitqueue.CommitQueueItem{Issue: "12", Type: commitqueue.PRItemType, Revision: "def"}, evergreen.PatchFailed)
+	item, _ := cq.Next()
+
+	s.NoError(s.makeJob().finishItem(s.ctx, cq, item, s.projectRef, ""))
+
+	cq, err := commitqueue.FindOneId("mci")
+	s.Require().NoError(err)
+	s.Empty(cq.Queue)
+	// the failure is reported on the pull request
+	s.Equal(1, s.env.LocalQueue().Stats().Total)
+}
+
+func (s *CommitQueueUnitsSuite) TestGetCommitQueuePatchChecksOwnership() {
+	patchDoc := &patch.Patch{
+		Id:      bson.NewObjectId(),
+		Project: "mci",
+		Author:  "me",
+		Patches: []patch.ModulePatch{{}},
+	}
+	s.Require().NoError(patchDoc.Insert())
+	item := commitqueue.CommitQueueItem{Issue: patchDoc.Id.Hex(), Type: commitqueue.PatchItemType, Author: "you"}
+
+	_, _, err := getCommitQueuePatch(item, s.projectRef)
+	s.Error(err)
+	s.Contains(err.Error(), "does not belong")
+
+	item.Author = "me"
+	_, _, err = getCommitQueuePatch(item, &model.ProjectRef{Identifier: "other"})
+	s.Error(err)
+	s.Contains(err.Error(), "is not for project")
+
+	patchDoc.Id = bson.NewObjectId()
+	patchDoc.Patches = []patch.ModulePatch{{ModuleName: "module"}}
+	s.Require().NoError(patchDoc.Insert())
+	item.Issue = patchDoc.Id.Hex()
+	_, _, err = getCommitQueuePatch(item, s.projectRef)
+	s.Error(err)
+	s.Contains(err.Error(), "modules")
+}
+
+func (s *CommitQueueUnitsSuite) TestCheckPatchAuthorPermissions() {
+	s.env.Settings().SuperUsers = []string{"admin"}
+	me := &user.DBUser{Id: "me"}
+	s.Require().NoError(me.Insert())
+	patchDoc := &patch.Patch{Id: bson.NewObjectId(), Project: "mci", Author: "me"}
+
+	problem, err := checkCommitQueuePatch(s.env.Settings(), s.projectRef, patchDoc)
+	s.NoError(err)
+	s.Contains(problem, "can't change the settings")
+
+	s.projectRef.Admins = []string{"me"}
+	problem, err = checkCommitQueuePatch(s.env.Settings(), s.projectRef, patchDoc)
+	s.NoError(err)
+	s.Empty(problem)
+
+	patchDoc.Author = "nobody"
+	problem, err = checkCommitQueuePatch(s.env.Settings(), s.projectRef, patchDoc)
+	s.NoError(err)
+	s.Contains(problem, "does not exist")
+}
+
+func (s *CommitQueueUnitsSuite) TestCheckPullRequestAuthor() {
+	me := &user.DBUser{Id: "me"}
+	s.Require().NoError(me.Insert())
+	login := "me"
+	pr := &github.PullRequest{User: &github.User{Login: &login}}
+	item := commitqueue.CommitQueueItem{Issue: "12", Type: commitqueue.PRItemType, Author: "me"}
+
+	// a matching Evergreen username isn't a verified GitHub login
+	problem, err := checkCommitQueuePullRequest(s.ctx, s.projectRef, pr, item, "")
+	s.NoError(err)
+	s.Contains(problem, "no verified GitHub login")
+
+	s.Require().NoError(me.SetGithubLogin("me-on-github"))
+	problem, err = checkCommitQueuePullRequest(s.ctx, s.projectRef, pr, item, "")
+	s.NoError(err)
+	s.Contains(problem, "opened by 'me', not 'me-on-github'")
+}
+
+func (s *CommitQueueUnitsSuite) TestUnfinishedPatchStaysQueued() {
+	cq := s.enqueueTested(commitqueue.CommitQueueItem{Issue: "12", Type: commitqueue.PRItemType, Revision: "def"}, evergreen.PatchStarted)
+	item, _ := cq.Next()
+
+	s.NoError(s.makeJob().finishItem(s.ctx, cq, item, s.projectRef, ""))
+
+	cq, err := commitqueue.FindOneId("mci")
+	s.Require().NoError(err)
+	s.Require().Len(cq.Queue, 1)
+	s.Equal(item.Version, cq.Queue[0].Version)
+}
+
+func (s *CommitQueueUnitsSuite) TestMissingPatchWaits() {
+	_, err := commitqueue.Enqueue("mci", commitqueue.CommitQueueItem{Issue: "12", Type: commitqueue.PRItemType})
+	s.Require().NoError(err)
+	cq, err := commitqueue.FindOneId("mci")
+	s.Require().NoError(err)
+	started, err := cq.StartHead("12", bson.NewObjectId().Hex(), "def")
+	s.Require().NoError(err)
+	s.Require().True(started)
+	item, _ := cq.Next()
+
+	// the patch may still be being created by another job
+	s.NoError(s.makeJob().finishItem(s.ctx, cq, item, s.projectRef, ""))
+	cq, err = commitqueue.FindOneId("mci")
+	s.Require().NoError(err)
+	s.Len(cq.Queue, 1)
+}
+
+func (s *CommitQueueUnitsSuite) TestStartItemRequiresAlias() {
+	_, err := commitqueue.Enqueue("mci", commitqueue.CommitQueueItem{Issue: "12", Type: commitqueue.PRItemType})
+	s.Require().NoError(err)
+	cq, err := commitqueue.FindOneId("mci")
+	s.Require().NoError(err)
+	item, _ := cq.Next()
+
+	s.Require().NoError(db.Clear(model.ProjectAliasCollection))
+	err = s.makeJob().startItem(s.ctx, cq, item, s.projectRef, "")
+	s.Error(err)
+	s.Contains(err.Error(), patch.CommitQueueAlias)
+}
@@ -32,6 +32,9 @@ const (
 
 	githubUpdateTypeBuild            = "build"
 	githubUpdateTypePatchWithVersion = "patch-with-version"
+	githubUpdateTypeCommitQueue      = "commit-queue"
+
+	commitQueueStatusContext = "evergreen/commit-queue"
 )
 
 func init() {
@@ -72,6 +75,10 @@ type githubStatusUpdateJob struct {
 
 	FetchID    string `bson:"fetch_id" json:"fetch_id" yaml:"fetch_id"`
 	UpdateType string `bson:"update_type" json:"update_type" yaml:"update_type"`
+
+	// CommitQueueStatus is the complete status to send for commit queue
+	// updates, which aren't fetched from a build or patch.
+	CommitQueueStatus *githubStatus `bson:"commit_queue_status,omitempty" json:"commit_queue_status,omitempty" yaml:"commit_queue_status,omitempty"`
 }
 
 func makeGithubStatusUpdateJob() *githubStatusUpdateJob {
@@ -110,6 +117,29 @@ func NewGithubStatusUpdateJobForPatchWithVersion(version string) amboy.Job {
 	return job
 }
 
+// NewGithubStatusUpdateJobForCommitQueue creates a job to update github's API
+// with the result of testing and merging a pull request in a commit queue.
+// Status will be reported as 'evergreen/commit-queue'
+func NewGithubStatusUpdateJobForCommitQueue(owner, repo string, prNumber int, ref, state, description, urlPath string) amboy.Job {
+	job := makeGithubStatusUpdateJob()
+	job.FetchID = fmt.Sprintf("%s/%s#%d", owner, repo, prNumber)
+	job.UpdateType = githubUpdateTypeCommitQueue
+	job.CommitQueueStatus = &githubStatus{
+		Owner:       owner,
+		Repo:        repo,
+		PRNumber:    prNumber,
+		Ref:         ref,
+		URLPath:     urlPath,
+		Description: description,
+		Context:     commitQueueStatusContext,
+		State:       state,
+	}
+
+	job.SetID(fmt.Sprintf("%s:%s-%s-%s", githubStatusUpdateJobName, job.UpdateType, job.FetchID, time.Now().String()))
+
+	return job
+}
+
 func (j *githubStatusUpdateJob) sendStatusUpdate(status *githubStatus) error {
 	c := grip.NewCatcher()
 
@@ -162,6 +192,14 @@ func (j *githubStatusUpdateJob) sendStatusUpdate(status *githubStatus) error {
 }
 
 func (j *githubStatusUpdateJob) fetch(status *githubStatus) error {
+	if j.UpdateType == githubUpdateTypeCommitQueue {
+		if j.CommitQueueStatus == nil {
+			return errors.New("commit queue status is missing")
+		}
+		*status = *j.CommitQueueStatus
+		return nil
+	}
+
 	patchVersion := j.FetchID
 	if j.UpdateType == githubUpdateTypeBuild {
 		b, err := build.FindOne(build.ById(j.FetchID))
@@ -190,6 +190,27 @@ func (s *githubStatusUpdateSuite) TestForPatchCreated() {
 	s.Equal("pending", status.State)
 }
 
+func (s *githubStatusUpdateSuite) TestForCommitQueue() {
+	job, ok := NewGithubStatusUpdateJobForCommitQueue("evergreen-ci", "evergreen", 448,
+		"776f608b5b12cd27b8d931c8ee4ca0c13f857299", githubStatusFailure, "tests failed", "/version/abc").(*githubStatusUpdateJob)
+	s.Require().NotNil(job)
+	s.Require().True(ok)
+	s.Require().Equal(githubUpdateTypeCommitQueue, job.UpdateType)
+
+	status := githubStatus{}
+	s.NoError(job.fetch(&status))
+	s.True(status.Valid())
+
+	s.Equal("evergreen-ci", status.Owner)
+	s.Equal("evergreen", status.Repo)
+	s.Equal(448, status.PRNumber)
+	s.Equal("776f608b5b12cd27b8d931c8ee4ca0c13f857299", status.Ref)
+	s.Equal("/version/abc", status.URLPath)
+	s.Equal("tests failed", status.Description)
+	s.Equal("evergreen/commit-queue", status.Context)
+	s.Equal("failure", status.State)
+}
+
 func (s *githubStatusUpdateSuite) TestWithGithub() {
 	// We always skip this test b/c Github's API only lets the status of a
 	// ref be set 1000 times, and doesn't allow status removal (so runnning
@@ -0,0 +1,389 @@
+package util
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// Condition is a parsed boolean expression that decides whether a
+// command runs. Conditions are evaluated against a set of expansions,
+// and support the following grammar:
+//
+//	expr       := or
+//	or         := and ( "||" and )*
+//	and        := unary ( "&&" unary )*
+//	unary      := "!" unary | "(" expr ")" | comparison
+//	comparison := operand [ ( "==" | "!=" | "matches" ) operand ]
+//
+// Operands are bare words or single or double quoted strings, and may
+// contain expansions such as ${is_patch}. The right-hand side of
+// "matches" is a regular expression that must match the entire
+// left-hand side. An operand on its own is true unless it is empty,
+// "false", or "0". The bare words "failed" and "succeeded" on their own
+// report whether an earlier command of the task failed.
+type Condition struct {
+	expr string
+	root conditionNode
+}
+
+// ConditionContext holds the state a condition is evaluated against.
+type ConditionContext struct {
+	Expansions     *Expansions
+	PreviousFailed bool
+}
+
+// ParseCondition parses a condition expression, returning an error if
+// the expression is malformed.
+func ParseCondition(expr string) (*Condition, error) {
+	tokens, err := tokenizeCondition(expr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid condition '%s'", expr)
+	}
+	if len(tokens) == 0 {
+		return nil, errors.Errorf("invalid condition '%s': condition is empty", expr)
+	}
+
+	p := &conditionParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid condition '%s'", expr)
+	}
+	if !p.done() {
+		return nil, errors.Errorf("invalid condition '%s': unexpected '%s'", expr, p.peek().value)
+	}
+
+	return &Condition{expr: expr, root: root}, nil
+}
+
+// Evaluate returns the value of the condition in the given context.
+func (c *Condition) Evaluate(ctx *ConditionContext) (bool, error) {
+	evalCtx := *ctx
+	if evalCtx.Expansions == nil {
+		evalCtx.Expansions = NewExpansions(nil)
+	}
+
+	out, err := c.root.eval(&evalCtx)
+	if err != nil {
+		return false, errors.Wrapf(err, "problem evaluating condition '%s'", c.expr)
+	}
+	return out, nil
+}
+
+func (c *Condition) String() string { return c.expr }
+
+// EvaluateConditions returns true if the ifExpr condition is true and
+// the unlessExpr condition is false. Empty conditions are ignored.
+func EvaluateConditions(ifExpr, unlessExpr string, ctx *ConditionContext) (bool, error) {
+	if strings.TrimSpace(ifExpr) != "" {
+		cond, err := ParseCondition(ifExpr)
+		if err != nil {
+			return false, errors.WithStack(err)
+		}
+		ok, err := cond.Evaluate(ctx)
+		if err != nil || !ok {
+			return false, errors.WithStack(err)
+		}
+	}
+
+	if strings.TrimSpace(unlessExpr) != "" {
+		cond, err := ParseCondition(unlessExpr)
+		if err != nil {
+			return false, errors.WithStack(err)
+		}
+		ok, err := cond.Evaluate(ctx)
+		if err != nil || ok {
+			return false, errors.WithStack(err)
+		}
+	}
+
+	return true, nil
+}
+
+////////////////////////////////////////////////////////////////////////
+//
+// tokenizer
+
+type conditionTokenKind int
+
+const (
+	conditionWord conditionTokenKind = iota
+	conditionString
+	conditionOperator
+)
+
+type conditionToken struct {
+	kind  conditionTokenKind
+	value string
+}
+
+var conditionOperators = []string{"&&", "||", "==", "!=", "!", "(", ")"}
+
+func tokenizeCondition(expr string) ([]conditionToken, error) {
+	tokens := []conditionToken{}
+	runes := []rune(expr)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		if unicode.IsSpace(r) {
+			i++
+			continue
+		}
+
+		if r == '"' || r == '\'' {
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end == len(runes) {
+				return nil, errors.New("unterminated string")
+			}
+			tokens = append(tokens, conditionToken{kind: conditionString, value: string(runes[i+1 : end])})
+			i = end + 1
+			continue
+		}
+
+		if op := conditionOperatorAt(runes, i); op != "" {
+			tokens = append(tokens, conditionToken{kind: conditionOperator, value: op})
+			i += len(op)
+			continue
+		}
+
+		start := i
+		for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' {
+			if runes[i] == '$' && i+1 < len(runes) && runes[i+1] == '{' {
+				for i < len(runes) && runes[i] != '}' {
+					i++
+				}
+				if i == len(runes) {
+					return nil, errors.New("unterminated expansion")
+				}
+				i++
+				continue
+			}
+			if i > start {
+				if op := conditionOperatorAt(runes, i); op != "" && op != "!" {
+					break
+				}
+			}
+			i++
+		}
+		tokens = append(tokens, conditionToken{kind: conditionWord, value: string(runes[start:i])})
+	}
+
+	return tokens, nil
+}
+
+func conditionOperatorAt(runes []rune, i int) string {
+	for _, op := range conditionOperators {
+		if strings.HasPrefix(string(runes[i:]), op) {
+			return op
+		}
+	}
+	return ""
+}
+
+////////////////////////////////////////////////////////////////////////
+//
+// parser
+
+type conditionParser struct {
+	tokens []conditionToken
+	pos    int
+}
+
+func (p *conditionParser) done() bool { return p.pos >= len(p.tokens) }
+
+func (p *conditionParser) peek() conditionToken { return p.tokens[p.pos] }
+
+func (p *conditionParser) accept(op string) bool {
+	if !p.done() && p.peek().kind == conditionOperator && p.peek().value == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *conditionParser) parseOr() (conditionNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &conditionOr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseAnd() (conditionNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &conditionAnd{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseUnary() (conditionNode, error) {
+	if p.accept("!") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &conditionNot{operand: operand}, nil
+	}
+
+	if p.accept("(") {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, errors.New("missing ')'")
+		}
+		return inner, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *conditionParser) parseComparison() (conditionNode, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	var op string
+	switch {
+	case p.accept("=="):
+		op = "=="
+	case p.accept("!="):
+		op = "!="
+	case !p.done() && p.peek().kind == conditionWord && p.peek().value == "matches":
+		p.pos++
+		op = "matches"
+	default:
+		return &conditionValue{operand: left}, nil
+	}
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if op == "matches" && !strings.Contains(right.value, "${") {
+		if _, err = regexp.Compile(right.value); err != nil {
+			return nil, errors.Wrapf(err, "invalid regular expression '%s'", right.value)
+		}
+	}
+
+	return &conditionCompare{op: op, left: left, right: right}, nil
+}
+
+func (p *conditionParser) parseOperand() (conditionToken, error) {
+	if p.done() {
+		return conditionToken{}, errors.New("unexpected end of condition")
+	}
+	tok := p.peek()
+	if tok.kind == conditionOperator {
+		return conditionToken{}, errors.Errorf("unexpected '%s'", tok.value)
+	}
+	p.pos++
+	return tok, nil
+}
+
+////////////////////////////////////////////////////////////////////////
+//
+// evaluation
+
+type conditionNode interface {
+	eval(*ConditionContext) (bool, error)
+}
+
+type conditionOr struct{ left, right conditionNode }
+
+func (n *conditionOr) eval(ctx *ConditionContext) (bool, error) {
+	left, err := n.left.eval(ctx)
+	if err != nil || left {
+		return left, err
+	}
+	return n.right.eval(ctx)
+}
+
+type conditionAnd struct{ left, right conditionNode }
+
+func (n *conditionAnd) eval(ctx *ConditionContext) (bool, error) {
+	left, err := n.left.eval(ctx)
+	if err != nil || !left {
+		return false, err
+	}
+	return n.right.eval(ctx)
+}
+
+type conditionNot struct{ operand conditionNode }
+
+func (n *conditionNot) eval(ctx *ConditionContext) (bool, error) {
+	out, err := n.operand.eval(ctx)
+	return !out, err
+}
+
+type conditionValue struct{ operand conditionToken }
+
+func (n *conditionValue) eval(ctx *ConditionContext) (bool, error) {
+	if n.operand.kind == conditionWord {
+		switch n.operand.value {
+		case "failed":
+			return ctx.PreviousFailed, nil
+		case "succeeded":
+			return !ctx.PreviousFailed, nil
+		}
+	}
+
+	value, err := ctx.Expansions.ExpandString(n.operand.value)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	value = strings.TrimSpace(value)
+
+	return value != "" && value != "0" && !strings.EqualFold(value, "false"), nil
+}
+
+type conditionCompare struct {
+	op          string
+	left, right conditionToken
+}
+
+func (n *conditionCompare) eval(ctx *ConditionContext) (bool, error) {
+	left, err := ctx.Expansions.ExpandString(n.left.value)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	right, err := ctx.Expansions.ExpandString(n.right.value)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	switch n.op {
+	case "==":
+		return left == right, nil
+	case "!=":
+		return left != right, nil
+	default:
+		re, err := regexp.Compile("^(?:" + right + ")$")
+		if err != nil {
+			return false, errors.Wrapf(err, "invalid regular expression '%s'", right)
+		}
+		return re.MatchString(left), nil
+	}
+}
@@ -0,0 +1,132 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCondition(t *testing.T) {
+	assert := assert.New(t) // nolint
+
+	for _, expr := range []string{
+		"${is_patch}",
+		"${is_patch} == true",
+		"${is_patch}==true",
+		"${os} != 'windows'",
+		"${os} matches windows.*",
+		"${os} matches 'osx|macos'",
+		"!${is_patch}",
+		"failed || (${a} == b && !succeeded)",
+		`"${a|default}" == "default value"`,
+	} {
+		_, err := ParseCondition(expr)
+		assert.NoError(err, expr)
+	}
+
+	for _, expr := range []string{
+		"",
+		"   ",
+		"${is_patch} ==",
+		"== true",
+		"(${a} == b",
+		"${a} == b)",
+		"${a} && || ${b}",
+		"'unterminated",
+		"${unterminated == b",
+		"${os} matches 'windows(.*'",
+	} {
+		_, err := ParseCondition(expr)
+		assert.Error(err, expr)
+	}
+}
+
+func TestEvaluateCondition(t *testing.T) {
+	assert := assert.New(t) // nolint
+
+	ctx := &ConditionContext{
+		Expansions: NewExpansions(map[string]string{
+			"is_patch": "true",
+			"os":       "windows-64",
+			"empty":    "",
+			"zero":     "0",
+			"spaced":   "a b",
+		}),
+	}
+
+	for expr, expected := range map[string]bool{
+		"${is_patch}":                       true,
+		"${missing}":                        false,
+		"${empty}":                          false,
+		"${zero}":                           false,
+		"!${is_patch}":                      false,
+		"${is_patch} == true":               true,
+		"${is_patch} != true":               false,
+		"${os} matches windows.*":           true,
+		"${os} matches windows":             false,
+		"${os} matches 'linux.*|windows.*'": true,
+		"${spaced} == 'a b'":                true,
+		"${missing|fallback} == fallback":   true,
+		"${is_patch} && ${os} == linux":     false,
+		"${is_patch} && ${os} == linux || ${zero} == 0":   true,
+		"${is_patch} && (${os} == linux || ${zero} == 0)": true,
+		"!(${is_patch} && ${os} == linux)":                true,
+		"failed":                                          false,
+		"succeeded":                                       true,
+		"'failed'":                                        true,
+	} {
+		cond, err := ParseCondition(expr)
+		if !assert.NoError(err, expr) {
+			continue
+		}
+		out, err := cond.Evaluate(ctx)
+		assert.NoError(err, expr)
+		assert.Equal(expected, out, expr)
+	}
+
+	ctx.PreviousFailed = true
+	cond, err := ParseCondition("failed && !succeeded")
+	assert.NoError(err)
+	out, err := cond.Evaluate(ctx)
+	assert.NoError(err)
+	assert.True(out)
+
+	// patterns built from expansions are only checked when evaluated
+	ctx.Expansions.Put("pattern", "windows(")
+	cond, err = ParseCondition("${os} matches ${pattern}")
+	assert.NoError(err)
+	_, err = cond.Evaluate(ctx)
+	assert.Error(err)
+}
+
+func TestEvaluateConditions(t *testing.T) {
+	assert := assert.New(t) // nolint
+
+	ctx := &ConditionContext{Expansions: NewExpansions(map[string]string{"is_patch": "true"})}
+
+	out, err := EvaluateConditions("", "", ctx)
+	assert.NoError(err)
+	assert.True(out)
+
+	out, err = EvaluateConditions("${is_patch}", "", ctx)
+	assert.NoError(err)
+	assert.True(out)
+
+	out, err = EvaluateConditions("${is_patch}", "${is_patch}", ctx)
+	assert.NoError(err)
+	assert.False(out)
+
+	out, err = EvaluateConditions("", "!${is_patch}", ctx)
+	assert.NoError(err)
+	assert.True(out)
+
+	_, err = EvaluateConditions("${is_patch} ==", "", ctx)
+	assert.Error(err)
+
+	// a context without expansions is not modified
+	ctx = &ConditionContext{PreviousFailed: true}
+	out, err = EvaluateConditions("failed && !${is_patch}", "", ctx)
+	assert.NoError(err)
+	assert.True(out)
+	assert.Nil(ctx.Expansions)
+}
@@ -0,0 +1,182 @@
+package util
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// CronSchedule is a parsed standard five field cron specification:
+// minute, hour, day of month, month and day of week. Each field may be
+// a "*", a number, a range ("1-5"), a step ("*/15", "0-30/10") or a
+// comma separated list of those. The descriptors @yearly, @monthly,
+// @weekly, @daily (or @midnight) and @hourly are also accepted.
+type CronSchedule struct {
+	minute     uint64
+	hour       uint64
+	dayOfMonth uint64
+	month      uint64
+	dayOfWeek  uint64
+
+	// cron matches either day field when both are restricted, and
+	// both of them otherwise.
+	dayOfMonthStar bool
+	dayOfWeekStar  bool
+}
+
+type cronBounds struct {
+	name     string
+	min, max int
+}
+
+var (
+	cronMinuteBounds     = cronBounds{"minute", 0, 59}
+	cronHourBounds       = cronBounds{"hour", 0, 23}
+	cronDayOfMonthBounds = cronBounds{"day of month", 1, 31}
+	cronMonthBounds      = cronBounds{"month", 1, 12}
+	// 7 is accepted as another name for sunday
+	cronDayOfWeekBounds = cronBounds{"day of week", 0, 7}
+
+	cronDescriptors = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+)
+
+// cronSearchYears bounds the search for the next matching time, so that
+// specifications that can never match (e.g. "0 0 31 2 *") terminate.
+const cronSearchYears = 5
+
+// ParseCron parses a cron specification.
+func ParseCron(spec string) (*CronSchedule, error) {
+	spec = strings.TrimSpace(spec)
+	if expanded, ok := cronDescriptors[spec]; ok {
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, errors.Errorf("cron specification '%s' must have 5 fields, not %d", spec, len(fields))
+	}
+
+	s := &CronSchedule{
+		dayOfMonthStar: strings.HasPrefix(fields[2], "*"),
+		dayOfWeekStar:  strings.HasPrefix(fields[4], "*"),
+	}
+	var err error
+	if s.minute, err = parseCronField(fields[0], cronMinuteBounds); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if s.hour, err = parseCronField(fields[1], cronHourBounds); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if s.dayOfMonth, err = parseCronField(fields[2], cronDayOfMonthBounds); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if s.month, err = parseCronField(fields[3], cronMonthBounds); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if s.dayOfWeek, err = parseCronField(fields[4], cronDayOfWeekBounds); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if s.dayOfWeek&(1<<7) != 0 {
+		s.dayOfWeek |= 1
+	}
+
+	return s, nil
+}
+
+// parseCronField returns the set of values matched by one field of a
+// cron specification as a bit set.
+func parseCronField(field string, bounds cronBounds) (uint64, error) {
+	var set uint64
+	for _, item := range strings.Split(field, ",") {
+		rangeSpec, step := item, 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			var err error
+			rangeSpec = item[:i]
+			step, err = strconv.Atoi(item[i+1:])
+			if err != nil || step <= 0 {
+				return 0, errors.Errorf("invalid step in %s field '%s'", bounds.name, item)
+			}
+		}
+
+		var low, high int
+		switch {
+		case rangeSpec == "*":
+			low, high = bounds.min, bounds.max
+		case strings.Contains(rangeSpec, "-"):
+			parts := strings.SplitN(rangeSpec, "-", 2)
+			var err error
+			if low, err = strconv.Atoi(parts[0]); err != nil {
+				return 0, errors.Errorf("invalid range in %s field '%s'", bounds.name, item)
+			}
+			if high, err = strconv.Atoi(parts[1]); err != nil {
+				return 0, errors.Errorf("invalid range in %s field '%s'", bounds.name, item)
+			}
+		default:
+			var err error
+			if low, err = strconv.Atoi(rangeSpec); err != nil {
+				return 0, errors.Errorf("invalid value in %s field '%s'", bounds.name, item)
+			}
+			high = low
+			// "5/15" means every 15 starting at 5
+			if step > 1 {
+				high = bounds.max
+			}
+		}
+
+		if low < bounds.min || high > bounds.max || low > high {
+			return 0, errors.Errorf("%s field '%s' is outside of %d-%d", bounds.name, item, bounds.min, bounds.max)
+		}
+		for v := low; v <= high; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+// Next returns the first time strictly after t that matches the schedule,
+// in t's location. It returns the zero time if the schedule doesn't
+// match any time in the next few years.
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Year() + cronSearchYears
+
+	for t.Year() <= limit {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *CronSchedule) matchesDay(t time.Time) bool {
+	dom := s.dayOfMonth&(1<<uint(t.Day())) != 0
+	dow := s.dayOfWeek&(1<<uint(t.Weekday())) != 0
+	if s.dayOfMonthStar || s.dayOfWeekStar {
+		return dom && dow
+	}
+	return dom || dow
+}
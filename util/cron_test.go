@@ -0,0 +1,83 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCron(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, spec := range []string{
+		"0 3 * * *",
+		"*/15 * * * *",
+		"0,30 8-18 * * 1-5",
+		"5/10 * 1,15 * *",
+		"0 0 * * 7",
+		"@daily",
+		" @hourly ",
+	} {
+		_, err := ParseCron(spec)
+		assert.NoError(err, spec)
+	}
+
+	for _, spec := range []string{
+		"",
+		"0 3 * *",
+		"0 3 * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1-b * * * *",
+		"@fortnightly",
+	} {
+		_, err := ParseCron(spec)
+		assert.Error(err, spec)
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	assert := assert.New(t)
+	// a wednesday
+	start := time.Date(2018, time.May, 16, 10, 20, 30, 0, time.UTC)
+
+	for spec, expected := range map[string]time.Time{
+		"0 3 * * *":         time.Date(2018, time.May, 17, 3, 0, 0, 0, time.UTC),
+		"*/15 * * * *":      time.Date(2018, time.May, 16, 10, 30, 0, 0, time.UTC),
+		"20 10 * * *":       time.Date(2018, time.May, 17, 10, 20, 0, 0, time.UTC),
+		"0 0 1 * *":         time.Date(2018, time.June, 1, 0, 0, 0, 0, time.UTC),
+		"30 9 * * 1-5":      time.Date(2018, time.May, 17, 9, 30, 0, 0, time.UTC),
+		"0 12 * * 0":        time.Date(2018, time.May, 20, 12, 0, 0, 0, time.UTC),
+		"0 12 * * 7":        time.Date(2018, time.May, 20, 12, 0, 0, 0, time.UTC),
+		"0 0 1 1 *":         time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC),
+		"0 0 29 2 *":        time.Date(2020, time.February, 29, 0, 0, 0, 0, time.UTC),
+		"@hourly":           time.Date(2018, time.May, 16, 11, 0, 0, 0, time.UTC),
+		"0 0 31 2 *":        {},
+		"0 0 20 * 5":        time.Date(2018, time.May, 18, 0, 0, 0, 0, time.UTC),
+		"0 0 */10 * *":      time.Date(2018, time.May, 21, 0, 0, 0, 0, time.UTC),
+		"0,45 10,11 * 5 3":  time.Date(2018, time.May, 16, 10, 45, 0, 0, time.UTC),
+		"0 0 * 12 *":        time.Date(2018, time.December, 1, 0, 0, 0, 0, time.UTC),
+		"59 23 31 12 *":     time.Date(2018, time.December, 31, 23, 59, 0, 0, time.UTC),
+		"21 10 16 5 *":      time.Date(2018, time.May, 16, 10, 21, 0, 0, time.UTC),
+		"20-25/5 10 16 5 *": time.Date(2018, time.May, 16, 10, 25, 0, 0, time.UTC),
+	} {
+		s, err := ParseCron(spec)
+		if !assert.NoError(err, spec) {
+			continue
+		}
+		assert.Equal(expected, s.Next(start), spec)
+	}
+
+	// the next time is strictly after the given time
+	s, err := ParseCron("0 3 * * *")
+	assert.NoError(err)
+	at := time.Date(2018, time.May, 17, 3, 0, 0, 0, time.UTC)
+	assert.Equal(at.Add(24*time.Hour), s.Next(at))
+}
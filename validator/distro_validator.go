@@ -5,6 +5,7 @@ import (
 
 	"github.com/evergreen-ci/evergreen"
 	"github.com/evergreen-ci/evergreen/cloud"
+	"github.com/evergreen-ci/evergreen/hostutil"
 	"github.com/evergreen-ci/evergreen/model/distro"
 	"github.com/evergreen-ci/evergreen/util"
 	"github.com/mitchellh/mapstructure"
@@ -18,6 +19,10 @@ var distroSyntaxValidators = []distroValidator{
 	ensureValidSSHOptions,
 	ensureValidExpansions,
 	ensureStaticHostsAreNotSpawnable,
+	ensureValidPrioritization,
+	ensureValidProjectQuotas,
+	ensureValidBootstrapMethod,
+	ensureValidCapabilities,
 }
 
 // CheckDistro checks if the distro configuration syntax is valid. Returns
@@ -55,6 +60,153 @@ func ensureStaticHostsAreNotSpawnable(d *distro.Distro, s *evergreen.Settings) [
 	return nil
 }
 
+// ensureValidPrioritization checks that the distro selects a known task
+// prioritization strategy, and only sets weights for known factors.
+func ensureValidPrioritization(d *distro.Distro, s *evergreen.Settings) []ValidationError {
+	errs := []ValidationError{}
+
+	if d.Prioritization.Strategy != "" && !util.StringSliceContains(distro.ValidPrioritizationStrategies, d.Prioritization.Strategy) {
+		errs = append(errs, ValidationError{
+			Message: fmt.Sprintf("distro prioritization strategy '%s' is invalid, must be one of %v",
+				d.Prioritization.Strategy, distro.ValidPrioritizationStrategies),
+			Level: Error,
+		})
+	}
+
+	for factor, weight := range d.Prioritization.Weights {
+		if !util.StringSliceContains(distro.ValidPrioritizationFactors, factor) {
+			errs = append(errs, ValidationError{
+				Message: fmt.Sprintf("distro prioritization factor '%s' is invalid, must be one of %v",
+					factor, distro.ValidPrioritizationFactors),
+				Level: Error,
+			})
+		}
+		if weight < 0 {
+			errs = append(errs, ValidationError{
+				Message: fmt.Sprintf("distro prioritization weight for '%s' cannot be negative", factor),
+				Level:   Error,
+			})
+		}
+	}
+
+	return errs
+}
+
+// ensureValidBootstrapMethod checks that the distro uses a known bootstrap
+// method, and that only EC2 distros of non-Windows hosts bootstrap with user
+// data.
+func ensureValidBootstrapMethod(d *distro.Distro, s *evergreen.Settings) []ValidationError {
+	if d.BootstrapMethod == "" {
+		return nil
+	}
+
+	if !util.StringSliceContains(distro.ValidBootstrapMethods, d.BootstrapMethod) {
+		return []ValidationError{
+			{
+				Message: fmt.Sprintf("distro bootstrap method '%s' is invalid, must be one of %v",
+					d.BootstrapMethod, distro.ValidBootstrapMethods),
+				Level: Error,
+			},
+		}
+	}
+
+	if !d.BootstrapsWithUserData() {
+		return nil
+	}
+
+	errs := []ValidationError{}
+	if d.Provider != evergreen.ProviderNameEc2OnDemand && d.Provider != evergreen.ProviderNameEc2Spot {
+		errs = append(errs, ValidationError{
+			Message: fmt.Sprintf("distro with provider '%s' cannot bootstrap with user data", d.Provider),
+			Level:   Error,
+		})
+	}
+	if hostutil.IsWindows(d) {
+		errs = append(errs, ValidationError{
+			Message: fmt.Sprintf("distro with arch '%s' cannot bootstrap with user data", d.Arch),
+			Level:   Error,
+		})
+	}
+	if d.Teardown != "" {
+		errs = append(errs, ValidationError{
+			Message: "teardown script will not run on hosts bootstrapped with user data",
+			Level:   Warning,
+		})
+	}
+
+	return errs
+}
+
+// ensureValidCapabilities checks that the capabilities and cost that tasks
+// select the distro by are not negative.
+func ensureValidCapabilities(d *distro.Distro, s *evergreen.Settings) []ValidationError {
+	errs := []ValidationError{}
+	if d.Capabilities.CPUs < 0 || d.Capabilities.MemoryGB < 0 {
+		errs = append(errs, ValidationError{
+			Message: "distro CPU count and memory cannot be negative",
+			Level:   Error,
+		})
+	}
+	if d.HourlyCost < 0 {
+		errs = append(errs, ValidationError{
+			Message: fmt.Sprintf("distro hourly cost %v cannot be negative", d.HourlyCost),
+			Level:   Error,
+		})
+	}
+	return errs
+}
+
+// ensureValidProjectQuotas checks that each of the distro's project
+// quotas names a distinct project, and that its guaranteed share and
+// burst ceiling are consistent with each other and the pool size.
+func ensureValidProjectQuotas(d *distro.Distro, s *evergreen.Settings) []ValidationError {
+	errs := []ValidationError{}
+	seen := map[string]bool{}
+	totalGuaranteed := 0
+
+	for _, q := range d.ProjectQuotas {
+		if q.Project == "" {
+			errs = append(errs, ValidationError{
+				Message: "distro project quota must specify a project",
+				Level:   Error,
+			})
+			continue
+		}
+		if seen[q.Project] {
+			errs = append(errs, ValidationError{
+				Message: fmt.Sprintf("distro has more than one quota for project '%s'", q.Project),
+				Level:   Error,
+			})
+		}
+		seen[q.Project] = true
+
+		if q.Guaranteed < 0 || q.Ceiling < 0 {
+			errs = append(errs, ValidationError{
+				Message: fmt.Sprintf("distro quota for project '%s' cannot be negative", q.Project),
+				Level:   Error,
+			})
+		}
+		if q.Ceiling > 0 && q.Ceiling < q.Guaranteed {
+			errs = append(errs, ValidationError{
+				Message: fmt.Sprintf("distro quota for project '%s' has a ceiling of %d hosts, "+
+					"which is less than its guaranteed %d hosts", q.Project, q.Ceiling, q.Guaranteed),
+				Level: Error,
+			})
+		}
+		totalGuaranteed += q.Guaranteed
+	}
+
+	if d.PoolSize > 0 && totalGuaranteed > d.PoolSize {
+		errs = append(errs, ValidationError{
+			Message: fmt.Sprintf("distro guarantees %d hosts to projects, but its pool size is %d",
+				totalGuaranteed, d.PoolSize),
+			Level: Error,
+		})
+	}
+
+	return errs
+}
+
 // ensureHasRequiredFields check that the distro configuration has all the required fields
 func ensureHasRequiredFields(d *distro.Distro, s *evergreen.Settings) []ValidationError {
 	errs := []ValidationError{}
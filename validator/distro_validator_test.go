@@ -238,3 +238,129 @@ func TestEnsureValidSSHOptions(t *testing.T) {
 		})
 	})
 }
+
+func TestEnsureValidPrioritization(t *testing.T) {
+	Convey("When validating a distro's prioritization settings...", t, func() {
+		Convey("the default settings should be valid", func() {
+			d := &distro.Distro{}
+			So(ensureValidPrioritization(d, conf), ShouldBeEmpty)
+		})
+		Convey("a known strategy with known weights should be valid", func() {
+			d := &distro.Distro{
+				Prioritization: distro.PrioritizationSettings{
+					Strategy: distro.PrioritizationStrategyFairShare,
+					Weights:  map[string]int{distro.PrioritizationFactorFairShare: 16, distro.PrioritizationFactorAge: 0},
+				},
+			}
+			So(ensureValidPrioritization(d, conf), ShouldBeEmpty)
+		})
+		Convey("an unknown strategy, unknown factor, or negative weight should be an error", func() {
+			d := &distro.Distro{
+				Prioritization: distro.PrioritizationSettings{
+					Strategy: "lottery",
+					Weights:  map[string]int{"luck": 1, distro.PrioritizationFactorPriority: -1},
+				},
+			}
+			So(len(ensureValidPrioritization(d, conf)), ShouldEqual, 3)
+		})
+	})
+}
+
+func TestEnsureValidBootstrapMethod(t *testing.T) {
+	Convey("When validating a distro's bootstrap method...", t, func() {
+		Convey("the default and ssh methods should be valid", func() {
+			d := &distro.Distro{Provider: evergreen.ProviderNameStatic}
+			So(ensureValidBootstrapMethod(d, conf), ShouldBeEmpty)
+			d.BootstrapMethod = distro.BootstrapMethodSSH
+			So(ensureValidBootstrapMethod(d, conf), ShouldBeEmpty)
+		})
+		Convey("an unknown method should be an error", func() {
+			d := &distro.Distro{BootstrapMethod: "carrier-pigeon"}
+			So(len(ensureValidBootstrapMethod(d, conf)), ShouldEqual, 1)
+		})
+		Convey("user data should be valid for linux EC2 distros", func() {
+			d := &distro.Distro{
+				Provider:        evergreen.ProviderNameEc2Spot,
+				Arch:            "linux_amd64",
+				BootstrapMethod: distro.BootstrapMethodUserData,
+			}
+			So(ensureValidBootstrapMethod(d, conf), ShouldBeEmpty)
+		})
+		Convey("user data should be an error for other providers or windows", func() {
+			d := &distro.Distro{
+				Provider:        evergreen.ProviderNameStatic,
+				Arch:            "windows_amd64",
+				BootstrapMethod: distro.BootstrapMethodUserData,
+			}
+			errs := ensureValidBootstrapMethod(d, conf)
+			So(len(errs), ShouldEqual, 2)
+			So(errs[0].Level, ShouldEqual, Error)
+			So(errs[1].Level, ShouldEqual, Error)
+		})
+		Convey("user data with a teardown script should be a warning", func() {
+			d := &distro.Distro{
+				Provider:        evergreen.ProviderNameEc2OnDemand,
+				Arch:            "linux_amd64",
+				BootstrapMethod: distro.BootstrapMethodUserData,
+				Teardown:        "echo bye",
+			}
+			errs := ensureValidBootstrapMethod(d, conf)
+			So(len(errs), ShouldEqual, 1)
+			So(errs[0].Level, ShouldEqual, Warning)
+		})
+	})
+}
+
+func TestEnsureValidCapabilities(t *testing.T) {
+	Convey("When validating a distro's capabilities...", t, func() {
+		Convey("unset and positive capabilities should be valid", func() {
+			d := &distro.Distro{}
+			So(ensureValidCapabilities(d, conf), ShouldBeEmpty)
+			d.Capabilities = distro.Capabilities{OS: "linux", CPUs: 8, MemoryGB: 32, Tags: []string{"ssd"}}
+			d.HourlyCost = 0.4
+			So(ensureValidCapabilities(d, conf), ShouldBeEmpty)
+		})
+		Convey("negative capabilities and cost should be an error", func() {
+			d := &distro.Distro{
+				Capabilities: distro.Capabilities{CPUs: -2},
+				HourlyCost:   -1,
+			}
+			So(len(ensureValidCapabilities(d, conf)), ShouldEqual, 2)
+		})
+	})
+}
+
+func TestEnsureValidProjectQuotas(t *testing.T) {
+	Convey("When validating a distro's project quotas...", t, func() {
+		Convey("consistent quotas should be valid", func() {
+			d := &distro.Distro{
+				PoolSize: 10,
+				ProjectQuotas: []distro.ProjectQuota{
+					{Project: "big", Guaranteed: 4, Ceiling: 8},
+					{Project: "small", Guaranteed: 2},
+				},
+			}
+			So(ensureValidProjectQuotas(d, conf), ShouldBeEmpty)
+		})
+		Convey("missing or duplicate projects should be an error", func() {
+			d := &distro.Distro{
+				ProjectQuotas: []distro.ProjectQuota{
+					{Guaranteed: 1},
+					{Project: "big"},
+					{Project: "big"},
+				},
+			}
+			So(len(ensureValidProjectQuotas(d, conf)), ShouldEqual, 2)
+		})
+		Convey("a ceiling below the guaranteed share, or more guarantees than hosts, should be an error", func() {
+			d := &distro.Distro{
+				PoolSize: 4,
+				ProjectQuotas: []distro.ProjectQuota{
+					{Project: "big", Guaranteed: 4, Ceiling: 2},
+					{Project: "small", Guaranteed: 1},
+				},
+			}
+			So(len(ensureValidProjectQuotas(d, conf)), ShouldEqual, 2)
+		})
+	})
+}
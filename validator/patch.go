@@ -45,19 +45,45 @@ func GetPatchedProject(p *patch.Patch, githubOauthToken string) (*model.Project,
 		}
 	}
 
-	project := &model.Project{}
-
 	// if the patched config exists, use that as the project file bytes.
 	if p.PatchedConfig != "" {
 		projectFileBytes = []byte(p.PatchedConfig)
 	}
 
 	// apply remote configuration patch if needed
+	configPatched := false
 	if p.ConfigChanged(projectRef.RemotePath) && p.PatchedConfig == "" {
-		project, err = model.MakePatchedConfig(p, projectRef.RemotePath, string(projectFileBytes))
+		projectFileBytes, err = model.MakePatchedFile(p, projectRef.RemotePath, string(projectFileBytes))
 		if err != nil {
 			return nil, errors.Wrapf(err, "Could not patch remote configuration file")
 		}
+		configPatched = true
+	}
+
+	// included files are read as at the patch's base revision, with the
+	// patch applied to them too
+	read := func(module *model.Module, fileName string) ([]byte, error) {
+		if module != nil {
+			return model.ReadModuleFile(module, fileName, githubOauthToken)
+		}
+		data, err := thirdparty.GetGithubFileContents(githubOauthToken, projectRef.Owner, projectRef.Repo,
+			fileName, p.Githash)
+		if !p.ConfigChanged(fileName) {
+			return data, err
+		}
+		if err != nil && !thirdparty.IsFileNotFound(err) {
+			return nil, err
+		}
+		configPatched = true
+		return model.MakePatchedFile(p, fileName, string(data))
+	}
+
+	project := &model.Project{}
+	if err = model.LoadProjectWithIncludes(projectFileBytes, projectRef.Identifier, read, project); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if configPatched {
 		// overwrite project fields with the project ref to disallow tracking a
 		// different project or doing other crazy things via config patches
 		verrs, err := CheckProjectSyntax(project)
@@ -72,11 +98,6 @@ func GetPatchedProject(p *patch.Patch, githubOauthToken string) (*model.Project,
 			}
 			return nil, errors.New(message)
 		}
-	} else {
-		// configuration is not patched
-		if err = model.LoadProjectInto(projectFileBytes, projectRef.Identifier, project); err != nil {
-			return nil, errors.WithStack(err)
-		}
 	}
 	return project, nil
 }
@@ -53,6 +53,10 @@ var projectSyntaxValidators = []projectValidator{
 	checkAllDependenciesSpec,
 	validateProjectTaskNames,
 	validateProjectTaskIdsAndTags,
+	validateTaskRetryPolicies,
+	validatePeriodicBuilds,
+	validateTaskGroups,
+	validateDistroRequirements,
 }
 
 // Functions used to validate the semantics of a project configuration file.
@@ -280,17 +284,21 @@ func ensureHasNecessaryBVFields(project *model.Project) []ValidationError {
 			)
 		}
 		for _, task := range buildVariant.Tasks {
-			if len(task.Distros) == 0 {
-				hasTaskWithoutDistro = true
-				break
+			if len(task.Distros) != 0 || task.DistroRequires != nil {
+				continue
 			}
+			if pt := project.FindProjectTask(task.Name); pt != nil && pt.DistroRequires != nil {
+				continue
+			}
+			hasTaskWithoutDistro = true
+			break
 		}
-		if hasTaskWithoutDistro && len(buildVariant.RunOn) == 0 {
+		if hasTaskWithoutDistro && len(buildVariant.RunOn) == 0 && buildVariant.DistroRequires == nil {
 			errs = append(errs,
 				ValidationError{
 					Message: fmt.Sprintf("buildvariant '%v' in project '%v' "+
-						"must either specify run_on field or have every task "+
-						"specify a distro.",
+						"must either specify run_on or distro_requires field or "+
+						"have every task specify a distro or distro_requires.",
 						buildVariant.Name, project.Identifier),
 				},
 			)
@@ -327,6 +335,7 @@ func ensureHasNecessaryProjectFields(project *model.Project) []ValidationError {
 
 	if project.CommandType != "" {
 		if project.CommandType != model.SystemCommandType &&
+			project.CommandType != model.SetupCommandType &&
 			project.CommandType != model.TestCommandType {
 			errs = append(errs,
 				ValidationError{
@@ -496,6 +505,11 @@ func validateCommands(section string, project *model.Project,
 	errs := []ValidationError{}
 
 	for _, cmd := range commands {
+		if cmd.IsParallel() {
+			errs = append(errs, validateParallelCommands(section, project, cmd)...)
+			continue
+		}
+
 		commandName := fmt.Sprintf("'%v' command", cmd.Command)
 		_, err := command.Render(cmd, project.Functions)
 		if err != nil {
@@ -506,15 +520,59 @@ func validateCommands(section string, project *model.Project,
 		}
 		if cmd.Type != "" {
 			if cmd.Type != model.SystemCommandType &&
+				cmd.Type != model.SetupCommandType &&
 				cmd.Type != model.TestCommandType {
 				msg := fmt.Sprintf("%v section in '%v': invalid command type: '%v'", section, commandName, cmd.Type)
 				errs = append(errs, ValidationError{Message: msg})
 			}
 		}
+		for _, condition := range []string{cmd.If, cmd.Unless} {
+			if strings.TrimSpace(condition) == "" {
+				continue
+			}
+			if _, err = util.ParseCondition(condition); err != nil {
+				msg := fmt.Sprintf("%v section in %v: %v", section, commandName, err)
+				errs = append(errs, ValidationError{Message: msg})
+			}
+		}
 	}
 	return errs
 }
 
+// validateParallelCommands checks that a parallel block contains at
+// least one command, does not also name a command or function, and
+// does not contain nested parallel blocks.
+func validateParallelCommands(section string, project *model.Project,
+	cmd model.PluginCommandConf) []ValidationError {
+	errs := []ValidationError{}
+
+	if cmd.Command != "" || cmd.Function != "" {
+		errs = append(errs, ValidationError{Message: fmt.Sprintf("%v section: parallel block "+
+			"can not also specify a command or function", section)})
+	}
+	if len(cmd.Parallel.Commands) == 0 {
+		errs = append(errs, ValidationError{Message: fmt.Sprintf("%v section: parallel block "+
+			"must contain at least one command", section)})
+	}
+	for _, c := range cmd.Parallel.Commands {
+		if c.IsParallel() {
+			errs = append(errs, ValidationError{Message: fmt.Sprintf("%v section: parallel blocks "+
+				"can not be nested", section)})
+			return errs
+		}
+	}
+	for _, condition := range []string{cmd.If, cmd.Unless} {
+		if strings.TrimSpace(condition) == "" {
+			continue
+		}
+		if _, err := util.ParseCondition(condition); err != nil {
+			errs = append(errs, ValidationError{Message: fmt.Sprintf("%v section in parallel block: %v", section, err)})
+		}
+	}
+
+	return append(errs, validateCommands(section, project, cmd.Parallel.Commands)...)
+}
+
 // Ensures there any plugin commands referenced in a project's configuration
 // are specified in a valid format
 func validatePluginCommands(project *model.Project) []ValidationError {
@@ -576,6 +634,20 @@ func validatePluginCommands(project *model.Project) []ValidationError {
 	for _, task := range project.Tasks {
 		errs = append(errs, validateCommands("tasks", project, task.Commands)...)
 	}
+
+	// validate the setup and teardown sections of task groups
+	for _, tg := range project.TaskGroups {
+		for section, commands := range map[string]*model.YAMLCommandSet{
+			"setup_group":    tg.SetupGroup,
+			"teardown_group": tg.TeardownGroup,
+			"setup_task":     tg.SetupTask,
+			"teardown_task":  tg.TeardownTask,
+		} {
+			if commands != nil {
+				errs = append(errs, validateCommands(section, project, commands.List())...)
+			}
+		}
+	}
 	return errs
 }
 
@@ -626,6 +698,149 @@ func validateProjectTaskIdsAndTags(project *model.Project) []ValidationError {
 	return errs
 }
 
+// validateTaskRetryPolicies ensures that retry policies have a valid
+// number of attempts and only retry on known failure types.
+func validateTaskRetryPolicies(project *model.Project) []ValidationError {
+	errs := []ValidationError{}
+	validatePolicy := func(location string, policy *model.TaskRetryPolicy) {
+		if policy == nil {
+			return
+		}
+		if policy.Attempts < 1 || policy.Attempts > evergreen.MaxTaskExecution {
+			errs = append(errs, ValidationError{
+				Message: fmt.Sprintf("%s has invalid retry attempts %d: must be between 1 and %d",
+					location, policy.Attempts, evergreen.MaxTaskExecution)})
+		}
+		for _, failureType := range policy.On {
+			if !util.StringSliceContains(model.ValidRetryFailureTypes, failureType) {
+				errs = append(errs, ValidationError{
+					Message: fmt.Sprintf("%s has invalid retry failure type '%s': must be one of %v",
+						location, failureType, model.ValidRetryFailureTypes)})
+			}
+		}
+	}
+
+	for _, task := range project.Tasks {
+		validatePolicy(fmt.Sprintf("task '%s'", task.Name), task.Retry)
+	}
+	for _, bv := range project.BuildVariants {
+		for _, bvt := range bv.Tasks {
+			validatePolicy(fmt.Sprintf("task '%s' on variant '%s'", bvt.Name, bv.Name), bvt.Retry)
+		}
+	}
+	return errs
+}
+
+// validateDistroRequirements ensures that distro requirements are not
+// negative, and that variants and tasks don't both name their distros and
+// declare requirements for them.
+func validateDistroRequirements(project *model.Project) []ValidationError {
+	errs := []ValidationError{}
+	validateRequirements := func(location string, reqs *distro.Requirements) {
+		if reqs == nil {
+			return
+		}
+		if reqs.MinCPUs < 0 {
+			errs = append(errs, ValidationError{
+				Message: fmt.Sprintf("%s has invalid distro requirement min_cpus %d: must not be negative",
+					location, reqs.MinCPUs)})
+		}
+		if reqs.MinMemoryGB < 0 {
+			errs = append(errs, ValidationError{
+				Message: fmt.Sprintf("%s has invalid distro requirement min_memory_gb %d: must not be negative",
+					location, reqs.MinMemoryGB)})
+		}
+	}
+
+	for _, task := range project.Tasks {
+		validateRequirements(fmt.Sprintf("task '%s'", task.Name), task.DistroRequires)
+	}
+	for _, bv := range project.BuildVariants {
+		location := fmt.Sprintf("buildvariant '%s'", bv.Name)
+		validateRequirements(location, bv.DistroRequires)
+		if len(bv.RunOn) != 0 && bv.DistroRequires != nil {
+			errs = append(errs, ValidationError{
+				Message: fmt.Sprintf("%s cannot specify both run_on and distro_requires", location)})
+		}
+		for _, bvt := range bv.Tasks {
+			location = fmt.Sprintf("task '%s' on variant '%s'", bvt.Name, bv.Name)
+			validateRequirements(location, bvt.DistroRequires)
+			if len(bvt.Distros) != 0 && bvt.DistroRequires != nil {
+				errs = append(errs, ValidationError{
+					Message: fmt.Sprintf("%s cannot specify both distros and distro_requires", location)})
+			}
+		}
+	}
+	return errs
+}
+
+// validatePeriodicBuilds ensures that periodic build definitions have
+// valid cron specifications and unique ids that can be used as keys.
+func validatePeriodicBuilds(project *model.Project) []ValidationError {
+	errs := []ValidationError{}
+	ids := map[string]bool{}
+	for i, def := range project.PeriodicBuilds {
+		if def.ID == "" {
+			errs = append(errs, ValidationError{
+				Message: fmt.Sprintf("periodic build %d must have an id or an alias", i)})
+		} else if strings.ContainsAny(def.ID, ".$") {
+			errs = append(errs, ValidationError{
+				Message: fmt.Sprintf("periodic build id '%s' can't contain '.' or '$'", def.ID)})
+		} else if ids[def.ID] {
+			errs = append(errs, ValidationError{
+				Message: fmt.Sprintf("periodic build id '%s' is used more than once", def.ID)})
+		}
+		ids[def.ID] = true
+
+		if _, err := util.ParseCron(def.Cron); err != nil {
+			errs = append(errs, ValidationError{
+				Message: fmt.Sprintf("periodic build %d has an invalid cron specification: %s", i, err.Error())})
+		}
+	}
+	return errs
+}
+
+// Ensures that task groups have unique names that aren't also task names,
+// and that each group lists existing tasks only once.
+func validateTaskGroups(project *model.Project) []ValidationError {
+	errs := []ValidationError{}
+	names := map[string]bool{}
+	for i, tg := range project.TaskGroups {
+		if tg.Name == "" {
+			errs = append(errs, ValidationError{
+				Message: fmt.Sprintf("task group %d must have a name", i)})
+		} else if names[tg.Name] {
+			errs = append(errs, ValidationError{
+				Message: fmt.Sprintf("task group '%s' is defined more than once", tg.Name)})
+		} else if project.FindProjectTask(tg.Name) != nil {
+			errs = append(errs, ValidationError{
+				Message: fmt.Sprintf("task group '%s' has the same name as a task", tg.Name)})
+		}
+		names[tg.Name] = true
+
+		if tg.MaxHosts < 0 {
+			errs = append(errs, ValidationError{
+				Message: fmt.Sprintf("task group '%s' can't have a negative max_hosts", tg.Name)})
+		}
+		if len(tg.Tasks) == 0 {
+			errs = append(errs, ValidationError{
+				Message: fmt.Sprintf("task group '%s' must contain at least one task", tg.Name)})
+		}
+		tasks := map[string]bool{}
+		for _, t := range tg.Tasks {
+			if project.FindProjectTask(t) == nil {
+				errs = append(errs, ValidationError{
+					Message: fmt.Sprintf("task group '%s' contains non-existent task '%s'", tg.Name, t)})
+			} else if tasks[t] {
+				errs = append(errs, ValidationError{
+					Message: fmt.Sprintf("task group '%s' contains task '%s' more than once", tg.Name, t)})
+			}
+			tasks[t] = true
+		}
+	}
+	return errs
+}
+
 // Makes sure that the dependencies for the tasks have the correct fields,
 // and that the fields reference valid tasks.
 func verifyTaskRequirements(project *model.Project) []ValidationError {
@@ -791,6 +791,140 @@ func TestValidateProjectTaskIdsAndTags(t *testing.T) {
 	})
 }
 
+func TestValidateTaskRetryPolicies(t *testing.T) {
+	Convey("When validating a project", t, func() {
+		Convey("valid retry policies should not throw an error", func() {
+			project := &model.Project{
+				Tasks: []model.ProjectTask{
+					{Name: "compile", Retry: &model.TaskRetryPolicy{Attempts: 2}},
+					{Name: "test", Retry: &model.TaskRetryPolicy{Attempts: 3, On: []string{"setup", "test"}}},
+					{Name: "lint"},
+				},
+				BuildVariants: []model.BuildVariant{
+					{
+						Name:  "linux",
+						Tasks: []model.BuildVariantTask{{Name: "lint", Retry: &model.TaskRetryPolicy{Attempts: 1, On: []string{"system"}}}},
+					},
+				},
+			}
+			So(validateTaskRetryPolicies(project), ShouldResemble, []ValidationError{})
+		})
+		Convey("invalid attempts and failure types should throw an error", func() {
+			project := &model.Project{
+				Tasks: []model.ProjectTask{
+					{Name: "compile", Retry: &model.TaskRetryPolicy{Attempts: 0}},
+					{Name: "test", Retry: &model.TaskRetryPolicy{Attempts: 2, On: []string{"test", "flaky"}}},
+				},
+				BuildVariants: []model.BuildVariant{
+					{
+						Name:  "linux",
+						Tasks: []model.BuildVariantTask{{Name: "compile", Retry: &model.TaskRetryPolicy{Attempts: 100}}},
+					},
+				},
+			}
+			So(len(validateTaskRetryPolicies(project)), ShouldEqual, 3)
+		})
+	})
+}
+
+func TestValidatePeriodicBuilds(t *testing.T) {
+	Convey("When validating a project", t, func() {
+		Convey("valid periodic builds should not throw an error", func() {
+			project := &model.Project{
+				PeriodicBuilds: []model.PeriodicBuildDefinition{
+					{ID: "nightly", Cron: "0 3 * * *", Alias: "nightly"},
+					{ID: "soak", Cron: "@weekly"},
+				},
+			}
+			So(validatePeriodicBuilds(project), ShouldResemble, []ValidationError{})
+		})
+		Convey("invalid cron specifications and ids should throw an error", func() {
+			project := &model.Project{
+				PeriodicBuilds: []model.PeriodicBuildDefinition{
+					{ID: "nightly", Cron: "0 3 * *"},
+					{ID: "nightly", Cron: "0 3 * * *"},
+					{Cron: "0 3 * * *"},
+					{ID: "night.ly", Cron: "0 3 * * *"},
+				},
+			}
+			So(len(validatePeriodicBuilds(project)), ShouldEqual, 4)
+		})
+	})
+}
+
+func TestValidateTaskGroups(t *testing.T) {
+	Convey("When validating a project", t, func() {
+		tasks := []model.ProjectTask{{Name: "compile"}, {Name: "test"}}
+		Convey("valid task groups should not throw an error", func() {
+			project := &model.Project{
+				Tasks: tasks,
+				TaskGroups: []model.TaskGroup{
+					{Name: "build", MaxHosts: 2, Tasks: []string{"compile", "test"}},
+					{Name: "tests", Tasks: []string{"test"}},
+				},
+			}
+			So(validateTaskGroups(project), ShouldResemble, []ValidationError{})
+		})
+		Convey("invalid names and tasks should throw an error", func() {
+			project := &model.Project{
+				Tasks: tasks,
+				TaskGroups: []model.TaskGroup{
+					{Tasks: []string{"compile"}},
+					{Name: "compile", Tasks: []string{"compile"}},
+					{Name: "build", MaxHosts: -1, Tasks: []string{"compile", "compile", "lint"}},
+					{Name: "build", Tasks: []string{"test"}},
+					{Name: "empty"},
+				},
+			}
+			So(len(validateTaskGroups(project)), ShouldEqual, 7)
+		})
+	})
+}
+
+func TestValidateDistroRequirements(t *testing.T) {
+	Convey("When validating a project", t, func() {
+		Convey("valid distro requirements should not throw an error", func() {
+			project := &model.Project{
+				Tasks: []model.ProjectTask{
+					{Name: "compile", DistroRequires: &distro.Requirements{MinCPUs: 8, MinMemoryGB: 16}},
+					{Name: "test"},
+				},
+				BuildVariants: []model.BuildVariant{
+					{
+						Name:           "linux",
+						DistroRequires: &distro.Requirements{OS: "linux"},
+						Tasks:          []model.BuildVariantTask{{Name: "compile"}, {Name: "test", Distros: []string{"gpu"}}},
+					},
+					{
+						Name:  "windows",
+						RunOn: []string{"windows"},
+						Tasks: []model.BuildVariantTask{{Name: "test", DistroRequires: &distro.Requirements{OS: "windows"}}},
+					},
+				},
+			}
+			So(validateDistroRequirements(project), ShouldResemble, []ValidationError{})
+		})
+		Convey("negative requirements and requirements alongside distros should throw an error", func() {
+			project := &model.Project{
+				Tasks: []model.ProjectTask{
+					{Name: "compile", DistroRequires: &distro.Requirements{MinCPUs: -1}},
+				},
+				BuildVariants: []model.BuildVariant{
+					{
+						Name:           "linux",
+						RunOn:          []string{"linux"},
+						DistroRequires: &distro.Requirements{MinMemoryGB: -4},
+						Tasks: []model.BuildVariantTask{
+							{Name: "compile", Distros: []string{"rhel"}, DistroRequires: &distro.Requirements{OS: "linux"}},
+						},
+					},
+				},
+			}
+			So(len(validateDistroRequirements(project)), ShouldEqual, 4)
+		})
+	})
+}
+
 func TestCheckTaskCommands(t *testing.T) {
 	Convey("When validating a project", t, func() {
 		Convey("ensure tasks that do not have at least one command throw "+
@@ -901,6 +1035,58 @@ func TestEnsureReferentialIntegrity(t *testing.T) {
 
 func TestValidatePluginCommands(t *testing.T) {
 	Convey("When validating a project", t, func() {
+		Convey("an error should be thrown if a command has an invalid condition", func() {
+			project := &model.Project{
+				Tasks: []model.ProjectTask{
+					{
+						Name: "compile",
+						Commands: []model.PluginCommandConf{
+							{
+								Command: "shell.exec",
+								If:      "${is_patch} == true",
+								Unless:  "${os} matches windows.*",
+								Params:  map[string]interface{}{"script": "echo hi"},
+							},
+							{
+								Command: "shell.exec",
+								If:      "${is_patch} ==",
+								Unless:  "(failed",
+								Params:  map[string]interface{}{"script": "echo hi"},
+							},
+						},
+					},
+				},
+			}
+			So(len(validatePluginCommands(project)), ShouldEqual, 2)
+		})
+		Convey("parallel blocks should be validated, and may not be nested", func() {
+			shell := model.PluginCommandConf{
+				Command: "shell.exec",
+				Params:  map[string]interface{}{"script": "echo hi"},
+			}
+			project := &model.Project{
+				Tasks: []model.ProjectTask{
+					{
+						Name: "compile",
+						Commands: []model.PluginCommandConf{
+							{Parallel: &model.ParallelCommandBlock{Commands: []model.PluginCommandConf{shell, shell}}},
+						},
+					},
+				},
+			}
+			So(validatePluginCommands(project), ShouldBeEmpty)
+
+			project.Tasks[0].Commands = append(project.Tasks[0].Commands,
+				model.PluginCommandConf{Parallel: &model.ParallelCommandBlock{}},
+				model.PluginCommandConf{Parallel: &model.ParallelCommandBlock{Commands: []model.PluginCommandConf{
+					{Parallel: &model.ParallelCommandBlock{Commands: []model.PluginCommandConf{shell}}},
+				}}},
+				model.PluginCommandConf{Command: "shell.exec", Parallel: &model.ParallelCommandBlock{
+					Commands: []model.PluginCommandConf{shell},
+				}},
+			)
+			So(len(validatePluginCommands(project)), ShouldEqual, 3)
+		})
 		Convey("an error should be thrown if a referenced plugin for a task does not exist", func() {
 			project := &model.Project{
 				Tasks: []model.ProjectTask{
@@ -1446,5 +1632,31 @@ func TestEnsureHasNecessaryBVFields(t *testing.T) {
 			So(ensureHasNecessaryBVFields(project),
 				ShouldResemble, []ValidationError{})
 		})
+		Convey("no error should be thrown if the buildvariant or its tasks "+
+			"specify distro requirements instead of distros", func() {
+			project := &model.Project{
+				Identifier: "projectId",
+				Tasks: []model.ProjectTask{
+					{Name: "compile", DistroRequires: &distro.Requirements{OS: "linux"}},
+					{Name: "test"},
+				},
+				BuildVariants: []model.BuildVariant{
+					{
+						Name: "linux",
+						Tasks: []model.BuildVariantTask{
+							{Name: "compile"},
+							{Name: "test", DistroRequires: &distro.Requirements{MinCPUs: 4}},
+						},
+					},
+					{
+						Name:           "ubuntu",
+						DistroRequires: &distro.Requirements{Tags: []string{"ubuntu"}},
+						Tasks:          []model.BuildVariantTask{{Name: "test"}},
+					},
+				},
+			}
+			So(ensureHasNecessaryBVFields(project),
+				ShouldResemble, []ValidationError{})
+		})
 	})
 }